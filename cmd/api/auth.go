@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/supabase"
+	"github.com/magnani/black-belt-app/backend/internal/config"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newTokenVerifier monta o verificador dos JWTs do Supabase. Sem configuração
+// retorna nil: as rotas autenticadas respondem 401. O JWKS é lido ao subir;
+// se falhar sem o secret HS256 como alternativa, a API não sobe.
+func newTokenVerifier(cfg config.AuthConfig) (ports.TokenVerifier, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	opts := supabase.VerifierOptions{
+		Secret:   cfg.JWTSecret,
+		Audience: cfg.Audience,
+		Issuer:   cfg.Issuer(),
+	}
+	if source := cfg.JWKSSource(); source != "" {
+		jwks := supabase.NewJWKS(source, cfg.JWKSRefresh)
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := jwks.Load(ctx); err != nil {
+			if cfg.JWTSecret == "" {
+				return nil, fmt.Errorf("erro ao ler JWKS de %s: %w", source, err)
+			}
+			log.Printf("⚠️  Aviso: JWKS indisponível em %s (%v); tokens HS256 seguem valendo", source, err)
+		}
+		opts.JWKS = jwks
+	}
+
+	verifier, err := supabase.NewVerifier(opts)
+	if err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi/efitest"
+)
+
+// newEfiFakeHandler expõe o simulador do pagador no fake da Efí (só com EFI_FAKE).
+// Cada rota gera o webhook correspondente e o entrega em webhook, como a Efí faria:
+//
+//	POST /dev/efi/pay?txid=...                     paga a cobrança
+//	POST /dev/efi/refund?txid=...&id=...[&fail=1]  liquida (ou recusa) a devolução
+//	POST /dev/efi/approve?id=...                   aprova o PIX Automático
+func newEfiFakeHandler(fake *efitest.Fake, webhook http.HandlerFunc) http.Handler {
+	deliver := func(generate func(r *http.Request) ([]byte, error)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+				return
+			}
+			payload, err := generate(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			log.Printf("[EfiFake] Entregando webhook: %s", payload)
+
+			req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, "/api/webhooks/efi", bytes.NewReader(payload))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			webhook(w, req)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/dev/efi/pay", deliver(func(r *http.Request) ([]byte, error) {
+		return fake.Pay(r.URL.Query().Get("txid"))
+	}))
+	mux.Handle("/dev/efi/refund", deliver(func(r *http.Request) ([]byte, error) {
+		q := r.URL.Query()
+		return fake.Refund(q.Get("txid"), q.Get("id"), q.Get("fail") == "")
+	}))
+	mux.Handle("/dev/efi/approve", deliver(func(r *http.Request) ([]byte, error) {
+		return fake.ApproveRecurrence(r.URL.Query().Get("id"))
+	}))
+	return mux
+}
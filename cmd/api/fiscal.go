@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/nfse"
+	"github.com/magnani/black-belt-app/backend/internal/config"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newFiscalProvider monta o provider de NFS-e configurado. Sem configuração
+// retorna nil: os pagamentos seguem sem nota. Dados do prestador inválidos
+// impedem a API de subir.
+func newFiscalProvider(cfg config.NFSeConfig) (ports.FiscalProvider, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	prestador := nfse.Config{
+		CNPJ:                      cfg.CNPJ,
+		InscricaoMunicipal:        cfg.InscricaoMunicipal,
+		CodigoMunicipio:           cfg.CodigoMunicipio,
+		ItemListaServico:          cfg.ItemListaServico,
+		CodigoTributacaoMunicipio: cfg.CodigoTributacaoMunicipio,
+		AliquotaISS:               cfg.AliquotaISS,
+		OptanteSimplesNacional:    cfg.SimplesNacional,
+	}
+	switch cfg.Provider {
+	case "local":
+		return nfse.NewLocalProvider(prestador, cfg.LocalDir)
+	}
+	return nil, fmt.Errorf("provider de NFS-e desconhecido: %s", cfg.Provider)
+}
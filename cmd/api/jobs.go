@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// schedule executa job a cada interval até ctx ser cancelado. Erros são logados
+// e não interrompem o agendamento: a próxima execução tenta de novo.
+func schedule(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					log.Printf("[Jobs] Erro em %s: %v", name, err)
+				}
+			}
+		}
+	}()
+}
@@ -2,16 +2,34 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/magnani/black-belt-app/backend/internal/adapters/efi"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi/efitest"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/render"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/webhook"
 	"github.com/magnani/black-belt-app/backend/internal/config"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
 	"github.com/magnani/black-belt-app/backend/internal/handlers"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
 )
 
 func main() {
+	// Subcomando de migrations: api migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	// Subcomando da dead-letter de webhooks: api webhooks dead-letter|show|edit|replay
+	if len(os.Args) > 1 && os.Args[1] == "webhooks" {
+		os.Exit(runWebhooks(os.Args[2:]))
+	}
+
 	log.Println("🥋 Iniciando BlackBelt API...")
 
 	// Carrega configurações
@@ -23,16 +41,34 @@ func main() {
 	log.Printf("📦 Ambiente: %s", cfg.Env)
 	log.Printf("🔐 Efí Sandbox: %v", cfg.Efi.Sandbox)
 
-	// Inicializa o cliente Efí (comentado até ter certificado)
+	// Repositórios: PostgreSQL (confere ou aplica as migrations) ou memória.
+	// Os webhooks gravados no inbox são aplicados pelo router.
+	webhookRouter := service.NewWebhookRouter()
+	store, err := openStorage(cfg, webhookRouter.Process)
+	if err != nil {
+		log.Fatalf("❌ Erro no armazenamento: %v", err)
+	}
+	defer store.Close()
+
+	// Inicializa o cliente Efí (ou o fake em memória, com EFI_FAKE=true)
 	// Para desenvolvimento, podemos pular esta etapa
-	var efiClient *efi.Client
-	if _, err := os.Stat(cfg.Efi.CertificatePath); err == nil {
+	var pix ports.PixProvider
+	var efiFake *efitest.Fake
+	var healthReporters []ports.GatewayHealthReporter
+	if cfg.Efi.Fake {
+		efiFake = efitest.New()
+		pix = efiFake
+		log.Println("🧪 Efí fake em memória (EFI_FAKE=true): nenhuma chamada real à API")
+	} else if _, err := os.Stat(cfg.Efi.CertificatePath); err == nil {
 		// Certificado existe, inicializa o cliente
 		pixKey := os.Getenv("EFI_PIX_KEY") // Chave PIX do estabelecimento
-		efiClient, err = efi.NewClient(&cfg.Efi, pixKey)
+		efiClient, err := efi.NewClient(&cfg.Efi, pixKey)
 		if err != nil {
 			log.Printf("⚠️  Aviso: Erro ao inicializar cliente Efí: %v", err)
 		} else {
+			efiClient.SetWebhookSecret(cfg.Webhook.Secret)
+			pix = efiClient
+			healthReporters = append(healthReporters, efiClient)
 			log.Println("✅ Cliente Efí inicializado com sucesso")
 		}
 	} else {
@@ -40,19 +76,263 @@ func main() {
 		log.Println("   O cliente Efí não será inicializado")
 	}
 
+	// Checkout com modo degradado: cobranças são enfileiradas (no armazenamento)
+	// quando a Efí está fora e o worker as cria quando ela volta
+	var checkoutService *service.CheckoutService
+	if pix != nil {
+		checkoutService = service.NewCheckoutService(pix, store.ChargeQueue, service.CheckoutOptions{
+			StripeFallback: cfg.Stripe.Enabled(),
+		})
+	}
+
 	// Configura o router
 	mux := http.NewServeMux()
 
-	// Health check
-	mux.HandleFunc("/health", handlers.HealthCheck)
-	mux.HandleFunc("/api/health", handlers.HealthCheck)
+	// Health check (inclui estado dos circuit breakers)
+	healthHandler := handlers.NewHealthHandler(healthReporters...)
+	mux.Handle("/health", healthHandler)
+	mux.Handle("/api/health", healthHandler)
+
+	// Eventos de domínio: o dispatcher entrega o outbox (gravado na mesma
+	// transação das entidades) aos subscribers, em ordem por agregado
+	notifier := memory.NewNotifier()
+	eventMetrics := service.NewEventMetrics()
+	dispatcher := service.NewEventDispatcher(store.Outbox)
+	dispatcher.Subscribe("notificações", service.NotifyOnEvent(notifier), service.NotifiedEventTypes()...)
+	dispatcher.Subscribe("métricas", eventMetrics.Handle)
+	if cfg.OutboundWebhook.Enabled() {
+		publisher, err := webhook.NewPublisher(webhook.Config{
+			URL:     cfg.OutboundWebhook.URL,
+			Secret:  cfg.OutboundWebhook.Secret,
+			Timeout: cfg.OutboundWebhook.Timeout,
+		})
+		if err != nil {
+			log.Fatalf("❌ Erro no webhook de saída: %v", err)
+		}
+		dispatcher.Subscribe("webhooks", publisher.Publish)
+		log.Printf("📤 Webhooks de saída: %s", cfg.OutboundWebhook.URL)
+	}
+	go dispatcher.Run(context.Background(), 5*time.Second)
+
+	// Autenticação: JWT do Supabase; papel e academia vêm do perfil do usuário
+	verifier, err := newTokenVerifier(cfg.Auth)
+	if err != nil {
+		log.Fatalf("❌ Erro na autenticação: %v", err)
+	}
+	if verifier == nil {
+		log.Println("⚠️  Autenticação não configurada (SUPABASE_JWT_SECRET ou SUPABASE_URL): rotas autenticadas respondem 401")
+	}
+	auth := handlers.NewAuthenticator(verifier, store.Profiles)
+	ownerOnly := func(h http.HandlerFunc) http.Handler {
+		return auth.Authenticate(handlers.RequireRole(domain.RoleOwner)(h))
+	}
+
+	// Cadastro de academias (pelo dono): a assinatura em trial é criada na
+	// mesma transação. A consulta vale para qualquer membro da academia.
+	trialService := service.NewTrialService(store.Subscriptions, store.Plans, store.Campaigns)
+	trialService.SetAuditLog(store.Audit)
+	academyHandler := handlers.NewAcademyHandler(service.NewAcademyService(store.Academies, trialService, store.UnitOfWork))
+	mux.Handle("/api/academies", ownerOnly(academyHandler.Create))
+	mux.Handle("/api/academies/", auth.Authenticate(
+		handlers.RequireSameAcademy("/api/academies/")(http.HandlerFunc(academyHandler.Get))))
+	log.Println("🏫 Academias registradas: /api/academies, /api/academies/:id")
+
+	// Assinatura da academia autenticada (só o dono): consulta, checkout, conversão
+	// antecipada do trial e cancelamento.
+	// O Stripe ainda não tem adapter: POST /stripe responde 503.
+	subscriptionCheckout := service.NewSubscriptionCheckoutService(store.Subscriptions, store.Plans, store.Payments, checkoutService, nil)
+	subscriptionCheckout.SetAuditLog(store.Audit)
+	if checkoutService != nil {
+		checkoutService.OnCompleted(subscriptionCheckout)
+		go checkoutService.RunPendingWorker(context.Background(), time.Minute)
+	}
+	cancellationService := service.NewCancellationService(store.Subscriptions, pix, nil)
+	cancellationService.SetAuditLog(store.Audit)
+	subscriptionHandler := handlers.NewSubscriptionHandler(store.Subscriptions, subscriptionCheckout, cancellationService)
+	mux.Handle("/api/subscriptions/current", ownerOnly(subscriptionHandler.Current))
+	mux.Handle("/api/subscriptions/pix-auto", ownerOnly(subscriptionHandler.PixAuto))
+	mux.Handle("/api/subscriptions/stripe", ownerOnly(subscriptionHandler.Stripe))
+	mux.Handle("/api/subscriptions/pending-charges/", ownerOnly(subscriptionHandler.PendingCharge))
+	mux.Handle("/api/subscriptions/trial-conversion", ownerOnly(subscriptionHandler.ConvertTrial))
+	mux.Handle("/api/subscriptions/", ownerOnly(subscriptionHandler.Cancel))
+	mux.Handle("/api/subscriptions/cancellation/undo", ownerOnly(handlers.NewCancellationHandler(cancellationService).Undo))
+	log.Println("💳 Assinaturas registradas: /api/subscriptions/{current,pix-auto,stripe,pending-charges/:id,trial-conversion,cancellation/undo,:id}")
+
+	// Troca de plano com pró-rata (só o dono): prévia e confirmação
+	planChangeService := service.NewPlanChangeService(store.Subscriptions, store.Plans, store.Payments, pix, nil)
+	planChangeService.SetAuditLog(store.Audit)
+	planChangeHandler := handlers.NewPlanChangeHandler(planChangeService)
+	mux.Handle("/api/subscriptions/plan-change/preview", ownerOnly(planChangeHandler.Preview))
+	mux.Handle("/api/subscriptions/plan-change", ownerOnly(planChangeHandler.Change))
+	log.Println("🔁 Troca de plano registrada: /api/subscriptions/plan-change{,/preview}")
+
+	// Pausa e retomada (só o dono). O job inicia as pausas agendadas e retoma
+	// as vencidas, movendo o próximo vencimento da recorrência na Efí.
+	pauseService := service.NewPauseService(store.Subscriptions, pix, nil)
+	pauseService.SetAuditLog(store.Audit)
+	pauseHandler := handlers.NewPauseHandler(pauseService)
+	mux.Handle("/api/subscriptions/pause", ownerOnly(pauseHandler.Pause))
+	mux.Handle("/api/subscriptions/resume", ownerOnly(pauseHandler.Resume))
+	log.Println("⏸️  Pausas registradas: /api/subscriptions/{pause,resume}")
+	schedule(context.Background(), "pausas", time.Hour, func(ctx context.Context) error {
+		report, err := pauseService.Run(ctx)
+		if report != nil && report.Started+report.Resumed > 0 {
+			log.Printf("[Pause] %+v", *report)
+		}
+		return err
+	})
+
+	// Resgate de cupom (só o dono): desconto replicado na recorrência do gateway
+	discountService := service.NewDiscountService(store.Subscriptions, store.Plans, store.Coupons, store.UnitOfWork, pix, nil)
+	mux.Handle("/api/subscriptions/coupon", ownerOnly(handlers.NewCouponHandler(discountService).Redeem))
+	log.Println("🏷️  Cupons registrados: /api/subscriptions/coupon")
 
-	// Webhook Efí (só registra se o cliente foi inicializado)
-	if efiClient != nil {
-		webhookHandler := handlers.NewWebhookHandler(efiClient, cfg.Webhook.Secret)
-		webhookHandler.RegisterHandler("pix", handlers.HandlePixReceived)
+	// Entitlements: features e uso dos limites do plano (qualquer membro da
+	// academia). Alunos e professores são criados pelo app no Supabase, que
+	// confirma a vaga antes em POST /api/entitlements/{students,professors}.
+	entitlementsHandler := handlers.NewEntitlementsHandler(
+		service.NewEntitlementsService(store.Subscriptions, store.Plans, store.Usage))
+	mux.Handle("/api/entitlements", auth.Authenticate(http.HandlerFunc(entitlementsHandler.Get)))
+	for path, resource := range map[string]domain.Resource{
+		"/api/entitlements/students":   domain.ResourceStudents,
+		"/api/entitlements/professors": domain.ResourceProfessors,
+	} {
+		mux.Handle(path, auth.Authenticate(
+			entitlementsHandler.RequireCapacity(resource)(http.HandlerFunc(entitlementsHandler.Capacity))))
+	}
+	log.Println("🎟️  Entitlements registrados: /api/entitlements{,/students,/professors}")
+
+	// Faturas dos ciclos pagos (só o dono): emitidas a cada pagamento
+	// confirmado, com numeração sequencial por academia, e anuladas no estorno
+	invoiceService := service.NewInvoiceService(store.Invoices, store.Subscriptions, store.Plans,
+		store.Branding, render.NewInvoiceRenderer(), nil)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	mux.Handle("/api/invoices", ownerOnly(invoiceHandler.List))
+	mux.Handle("/api/invoices/", ownerOnly(invoiceHandler.Download))
+	log.Println("🧾 Faturas registradas: /api/invoices, /api/invoices/:id/download")
+
+	// NFS-e de cada pagamento confirmado, cancelada no estorno. O job reenvia
+	// as notas que a prefeitura recusou ou não respondeu, com o mesmo RPS.
+	paymentHandlers := []service.PaymentSucceededHandler{invoiceService}
+	refundHandlers := []service.PaymentRefundedHandler{invoiceService}
+	fiscalProvider, err := newFiscalProvider(cfg.NFSe)
+	if err != nil {
+		log.Fatalf("❌ Erro na NFS-e: %v", err)
+	}
+	if fiscalProvider != nil {
+		fiscalService := service.NewFiscalService(store.NFSe, fiscalProvider, store.FiscalData, cfg.NFSe.RPSSeries)
+		paymentHandlers = append(paymentHandlers, fiscalService)
+		refundHandlers = append(refundHandlers, fiscalService)
+		schedule(context.Background(), "reenvio de NFS-e", 15*time.Minute, func(ctx context.Context) error {
+			report, err := fiscalService.Run(ctx)
+			if report != nil && report.Issued+report.Failed > 0 {
+				log.Printf("[Fiscal] %+v", *report)
+			}
+			return err
+		})
+		log.Printf("🧾 NFS-e habilitada (provider %s, série %s)", cfg.NFSe.Provider, cfg.NFSe.RPSSeries)
+	} else {
+		log.Println("⚠️  NFS-e não configurada (NFSE_CNPJ): pagamentos seguem sem nota fiscal")
+	}
+
+	// Test clock do sandbox: avança o relógio da academia e roda a conciliação,
+	// a régua e os cancelamentos no tempo dela (fora do sandbox a rota responde 404)
+	testClockService := service.NewTestClockService(store.Subscriptions, store.Payments, store.Plans, pix, notifier)
+	testClockService.SetAuditLog(store.Audit)
+	testClockService.OnPaymentSucceeded(paymentHandlers...)
+	sandbox := cfg.Efi.Sandbox && !cfg.IsProduction()
+	mux.Handle("/api/sandbox/test-clock", ownerOnly(handlers.NewTestClockHandler(testClockService, sandbox).ServeHTTP))
+	if sandbox {
+		log.Println("🧪 Test clock registrado: /api/sandbox/test-clock")
+	}
+
+	// Audit log por academia: consulta e verificação da cadeia, extensão
+	// de trial pela equipe e métricas de eventos (só com ADMIN_API_TOKEN)
+	if cfg.Admin.Token != "" {
+		auditHandler := handlers.NewAuditAdminHandler(service.NewAuditService(store.Audit), cfg.Admin.Token)
+		mux.Handle("/api/admin/audit/", auditHandler)
+		log.Println("🛠️  Admin do audit log registrado: /api/admin/audit/")
+
+		trialHandler := handlers.NewTrialHandler(trialService, cfg.Admin.Token)
+		mux.HandleFunc("/api/admin/subscriptions/trial-extension", trialHandler.Extend)
+		log.Println("🛠️  Extensão de trial registrada: /api/admin/subscriptions/trial-extension")
+
+		eventsHandler := handlers.NewEventsAdminHandler(eventMetrics, cfg.Admin.Token)
+		mux.HandleFunc("/api/admin/events/metrics", eventsHandler.Metrics)
+		log.Println("🛠️  Métricas de eventos registradas: /api/admin/events/metrics")
+	}
+
+	// Webhook Efí (só registra se o cliente foi inicializado): grava no inbox,
+	// responde 200 e processa em background
+	if pix != nil {
+		refundService := service.NewRefundService(store.Payments, pix, nil)
+		refundService.SetAuditLog(store.Audit)
+		refundService.OnPaymentRefunded(refundHandlers...)
+		// Cobranças pagas convertem o trial ou renovam o período; falhas entram
+		// na régua de cobrança, que recupera a assinatura quando o PIX chega
+		dunningService := service.NewDunningService(store.Subscriptions, store.Payments, store.Plans, pix, notifier)
+		dunningService.OnPaymentSucceeded(paymentHandlers...)
+		billingService := service.NewBillingService(store.Subscriptions, store.Payments, planChangeService, dunningService)
+		billingService.SetAuditLog(store.Audit)
+		chargeHandler := handlers.HandleCharges(billingService, efi.ParseChargeNotifications)
+		webhookRouter.Handle("pix", chargeHandler)
+		webhookRouter.Handle("cobr", chargeHandler)
+		schedule(context.Background(), "régua de cobrança", time.Hour, func(ctx context.Context) error {
+			report, err := dunningService.Run(ctx)
+			if report != nil && report.Processed > 0 {
+				log.Printf("[Dunning] %+v", *report)
+			}
+			return err
+		})
+		// Cancelamentos agendados são efetivados no fim do período, com a
+		// autorização do PIX Automático revogada na Efí
+		schedule(context.Background(), "cancelamentos agendados", time.Hour, func(ctx context.Context) error {
+			report, err := cancellationService.Run(ctx)
+			if report != nil && report.Canceled+report.Failed > 0 {
+				log.Printf("[Cancellation] %+v", *report)
+			}
+			return err
+		})
+
+		webhookRouter.Handle("devolucao", handlers.HandleRefunds(refundService, efi.ParseRefundNotifications))
+
+		// Devoluções pedidas pelo dono (pagamentos da própria academia) ou pela
+		// equipe (qualquer academia, só com ADMIN_API_TOKEN)
+		refundHandler := handlers.NewRefundHandler(refundService, cfg.Admin.Token)
+		mux.Handle("/api/payments/", ownerOnly(refundHandler.Request))
+		mux.HandleFunc("/api/admin/payments/", refundHandler.AdminRequest)
+		log.Println("💸 Devoluções registradas: /api/payments/:id/refunds, /api/admin/payments/:id/refunds")
+
+		// Devoluções sem resposta definitiva da Efí são reenviadas (mesmo ID) até resolver
+		schedule(context.Background(), "conciliação de devoluções", 5*time.Minute, func(ctx context.Context) error {
+			n, err := refundService.ReconcilePending(ctx)
+			if n > 0 {
+				log.Printf("[Refund] %d devolução(ões) conciliada(s)", n)
+			}
+			return err
+		})
+
+		webhookInbox := service.NewWebhookInbox(store.Webhooks, service.WebhookInboxOptions{
+			RetryInterval: cfg.Webhook.RetryInterval,
+		})
+		go webhookInbox.Run(context.Background())
+
+		// Dead-letter: inspeção, edição e replay (só com ADMIN_API_TOKEN)
+		if cfg.Admin.Token != "" {
+			adminHandler := handlers.NewWebhookAdminHandler(webhookInbox, cfg.Admin.Token)
+			mux.Handle("/api/admin/webhooks/", adminHandler)
+			log.Println("🛠️  Admin de webhooks registrado: /api/admin/webhooks/")
+		}
+
+		webhookHandler := handlers.NewWebhookHandler(pix, cfg.Webhook.Secret, webhookInbox)
 		mux.HandleFunc("/api/webhooks/efi", webhookHandler.HandleEfiWebhook)
 		log.Println("📨 Webhook endpoint registrado: /api/webhooks/efi")
+
+		// Simulação do pagador no fake: gera o webhook e entrega no endpoint acima
+		if efiFake != nil {
+			mux.Handle("/dev/efi/", newEfiFakeHandler(efiFake, webhookHandler.HandleEfiWebhook))
+			log.Println("🧪 Simulador Efí: POST /dev/efi/pay?txid=, /dev/efi/refund?txid=&id=, /dev/efi/approve?id=")
+		}
 	}
 
 	// Inicia o servidor
@@ -60,7 +340,7 @@ func main() {
 	log.Printf("🚀 Servidor rodando em http://localhost%s", addr)
 	log.Printf("🏥 Health check: http://localhost%s/health", addr)
 
-	if err := http.ListenAndServe(addr, mux); err != nil {
+	if err := http.ListenAndServe(addr, handlers.RequestID(mux)); err != nil {
 		log.Fatalf("❌ Erro ao iniciar servidor: %v", err)
 	}
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/postgres"
+	"github.com/magnani/black-belt-app/backend/internal/config"
+)
+
+// migrateUsage descreve o subcomando migrate
+const migrateUsage = `uso: api migrate <comando>
+
+comandos:
+  up         aplica as migrations pendentes
+  down [N]   desfaz as últimas N migrations (padrão 1)
+  status     mostra a versão do banco e as migrations pendentes`
+
+// runMigrate executa o subcomando "api migrate" e devolve o exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	db, err := postgres.Open(ctx, cfg.URL)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := postgres.NewMigrator(db)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, v := range applied {
+			log.Printf("⬆️  Migration %d aplicada", v)
+		}
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			log.Println("✅ Banco já está em dia")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, v := range reverted {
+			log.Printf("⬇️  Migration %d desfeita", v)
+		}
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		log.Printf("📦 Versão do banco: %d (binário: %d)", status.Current, status.Latest)
+		if len(status.Pending) > 0 {
+			log.Printf("⏳ Pendentes: %v", status.Pending)
+		}
+		if len(status.Unknown) > 0 {
+			log.Printf("⚠️  Aplicadas por um binário mais novo: %v", status.Unknown)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
+
+// openDatabase conecta ao banco e garante que o schema corresponde ao binário:
+// aplica as migrations pendentes se migrateOnStart, e recusa subir se o banco
+// estiver à frente (ou, sem migrateOnStart, atrasado)
+func openDatabase(ctx context.Context, cfg config.DatabaseConfig) (*postgres.DB, error) {
+	db, err := postgres.Open(ctx, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := postgres.NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(ctx)
+		for _, v := range applied {
+			log.Printf("⬆️  Migration %d aplicada", v)
+		}
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+
+	if err := migrator.Check(ctx); err != nil {
+		db.Close()
+		if errors.Is(err, postgres.ErrPendingMigrations) {
+			return nil, fmt.Errorf("%w (rode \"api migrate up\" ou defina DATABASE_MIGRATE_ON_START=true)", err)
+		}
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/postgres"
+	"github.com/magnani/black-belt-app/backend/internal/config"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// storage agrupa os repositórios da API, no PostgreSQL ou em memória
+type storage struct {
+	Profiles      ports.ProfileService
+	Academies     ports.AcademyService
+	Subscriptions ports.SubscriptionService
+	Payments      ports.PaymentService
+	Plans         ports.PlanService
+	Webhooks      ports.WebhookService
+	Outbox        ports.Outbox
+	UnitOfWork    ports.UnitOfWork
+	Audit         ports.AuditLog
+	ChargeQueue   ports.ChargeQueue
+	Usage         ports.UsageCounter
+	Coupons       ports.CouponService
+	Campaigns     ports.TrialCampaignService
+	Invoices      ports.InvoiceService
+	Branding      ports.BrandingService
+	NFSe          ports.NFSeService
+	FiscalData    ports.FiscalDataService
+
+	close func()
+}
+
+// Close libera as conexões do armazenamento
+func (s *storage) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+// openStorage monta os repositórios do driver configurado. No PostgreSQL
+// confere (ou aplica) as migrations antes de subir; em memória, semeia os
+// planos Starter/Pro/Business. processWebhook aplica os webhooks do inbox.
+func openStorage(cfg *config.Config, processWebhook service.WebhookEventHandler) (*storage, error) {
+	switch cfg.Storage.Driver {
+	case config.StoragePostgres:
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		db, err := openDatabase(ctx, cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("✅ Banco de dados conectado e migrado")
+		webhooks := postgres.NewWebhookRepository(db, postgres.WebhookProcessor(processWebhook))
+		webhooks.SetBackoff(webhookBackoff(cfg.Webhook))
+		academies := postgres.NewAcademyRepository(db)
+		return &storage{
+			Profiles:      postgres.NewProfileRepository(db),
+			Academies:     academies,
+			Subscriptions: postgres.NewSubscriptionRepository(db),
+			Payments:      postgres.NewPaymentRepository(db),
+			Plans:         postgres.NewPlanRepository(db),
+			Webhooks:      webhooks,
+			Outbox:        postgres.NewOutbox(db),
+			UnitOfWork:    db,
+			Audit:         postgres.NewAuditLog(db),
+			ChargeQueue:   postgres.NewChargeQueue(db),
+			Usage:         postgres.NewUsageCounter(db),
+			Coupons:       postgres.NewCouponRepository(db),
+			Campaigns:     postgres.NewTrialCampaignRepository(db),
+			Invoices:      postgres.NewInvoiceRepository(db),
+			Branding:      academies,
+			NFSe:          postgres.NewNFSeRepository(db),
+			FiscalData:    academies,
+			close:         db.Close,
+		}, nil
+
+	case config.StorageMemory:
+		outbox := memory.NewOutbox()
+		plans := memory.NewPlanRepository(memory.DefaultPlans(domain.SystemClock{}.Now())...)
+		profiles := memory.NewProfileRepository()
+		academies := memory.NewAcademyRepository(profiles)
+		webhooks := memory.NewWebhookRepository(memory.WebhookProcessor(processWebhook))
+		webhooks.SetBackoff(webhookBackoff(cfg.Webhook))
+		log.Println("⚠️  Armazenamento em memória (só desenvolvimento): os dados são perdidos ao reiniciar e falhas no meio de uma transação não são desfeitas")
+		return &storage{
+			Profiles:      profiles,
+			Academies:     academies,
+			Subscriptions: memory.NewSubscriptionRepository(plans, outbox),
+			Payments:      memory.NewPaymentRepository(outbox),
+			Plans:         plans,
+			Webhooks:      webhooks,
+			Outbox:        outbox,
+			UnitOfWork:    memory.NewUnitOfWork(),
+			Audit:         memory.NewAuditLog(),
+			ChargeQueue:   memory.NewChargeQueue(),
+			Usage:         memory.NewUsageCounter(profiles, academies),
+			Coupons:       memory.NewCouponRepository(),
+			Campaigns:     memory.NewTrialCampaignRepository(),
+			Invoices:      memory.NewInvoiceRepository(),
+			Branding:      academies,
+			NFSe:          memory.NewNFSeRepository(),
+			FiscalData:    academies,
+		}, nil
+	}
+	return nil, fmt.Errorf("driver de armazenamento desconhecido: %s", cfg.Storage.Driver)
+}
+
+// webhookBackoff monta a política de retentativas dos webhooks
+func webhookBackoff(cfg config.WebhookConfig) domain.WebhookBackoff {
+	return domain.WebhookBackoff{
+		Base:       cfg.RetryBase,
+		Max:        cfg.RetryMax,
+		MaxRetries: cfg.MaxRetries,
+		Jitter:     cfg.RetryJitter,
+		Lease:      cfg.ProcessingLease,
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/postgres"
+	"github.com/magnani/black-belt-app/backend/internal/config"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// webhooksUsage descreve o subcomando webhooks
+const webhooksUsage = `uso: api webhooks <comando>
+
+comandos:
+  dead-letter [N]        lista os N eventos mais antigos da dead-letter (padrão 50)
+  show <id>              mostra um evento com payload
+  edit <id> <arquivo>    substitui o payload de um evento na dead-letter
+  replay <id>            devolve o evento à fila (a API em execução processa)`
+
+// runWebhooks executa o subcomando "api webhooks" e devolve o exit code.
+// Opera direto no banco: o replay deixa o evento pending e a varredura da
+// API em execução o processa.
+func runWebhooks(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, webhooksUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	db, err := postgres.Open(ctx, cfg.URL)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	inbox := service.NewWebhookInbox(postgres.NewWebhookRepository(db, nil), service.WebhookInboxOptions{})
+
+	switch {
+	case args[0] == "dead-letter":
+		limit := 50
+		if len(args) > 1 {
+			if limit, err = strconv.Atoi(args[1]); err != nil || limit <= 0 {
+				fmt.Fprintln(os.Stderr, webhooksUsage)
+				return 2
+			}
+		}
+		events, err := inbox.DeadLetters(ctx, limit, 0)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		for _, e := range events {
+			fmt.Printf("%s  %s/%s  %s  tentativas=%d  %s\n", e.ID, e.Gateway, e.EventType,
+				e.EventID, e.RetryCount, deref(e.ErrorMessage))
+		}
+		if len(events) == 0 {
+			log.Println("✅ Dead-letter vazia")
+		}
+
+	case args[0] == "show" && len(args) == 2:
+		event, err := inbox.Get(ctx, args[1])
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		return printJSON(event)
+
+	case args[0] == "edit" && len(args) == 3:
+		payload, err := os.ReadFile(args[2])
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		event, err := inbox.EditPayload(ctx, args[1], payload)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		log.Printf("✏️  Payload de %s/%s atualizado", event.Gateway, event.EventID)
+
+	case args[0] == "replay" && len(args) == 2:
+		event, err := inbox.Replay(ctx, args[1])
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		log.Printf("🔁 %s/%s de volta à fila (%s)", event.Gateway, event.EventID, event.Status)
+
+	default:
+		fmt.Fprintln(os.Stderr, webhooksUsage)
+		return 2
+	}
+	return 0
+}
+
+// printJSON imprime v indentado no stdout
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	return 0
+}
+
+// deref devolve o texto ou vazio
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -3,6 +3,7 @@ package efi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -14,6 +15,7 @@ type AccountsClient struct {
 	baseURL      string
 	httpClient   *http.Client
 	tokenManager *TokenManager
+	breaker      *CircuitBreaker // Circuit breaker da família "accounts" (opcional)
 }
 
 // accountsBaseURL define a URL base da API de abertura de contas
@@ -30,6 +32,7 @@ func (c *Client) accountsClient() *AccountsClient {
 		baseURL:      c.accountsBaseURL(),
 		httpClient:   c.httpClient,
 		tokenManager: c.tokenManager,
+		breaker:      c.breakers.get(EndpointFamilyAccounts),
 	}
 }
 
@@ -56,7 +59,24 @@ func (c *Client) GetAccountStatus(ctx context.Context, accountID string) (*Accou
 
 // doRequest executa uma requisição HTTP autenticada
 func (c *AccountsClient) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	return doAuthenticatedRequest(ctx, c.tokenManager, c.httpClient, c.baseURL, method, path, body)
+	ticket, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := doAuthenticatedRequest(ctx, c.tokenManager, c.httpClient, c.baseURL, method, path, body)
+	c.breaker.Done(ticket, isBreakerFailure(ctx, apiErrorStatus(err), err))
+
+	return respBody, gatewayError(ctx, apiErrorStatus(err), err)
+}
+
+// apiErrorStatus extrai o status HTTP de um *APIError (0 se não houver)
+func apiErrorStatus(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
 }
 
 // CreateAccount cria uma nova conta digital (API restrita - requer autorização especial).
@@ -198,6 +218,9 @@ func doAuthenticatedRequest(ctx context.Context, tokenManager *TokenManager, htt
 	if resp.StatusCode >= 400 {
 		var apiErr APIError
 		if json.Unmarshal(respBody, &apiErr) == nil {
+			if apiErr.Status == 0 {
+				apiErr.Status = resp.StatusCode
+			}
 			return nil, &apiErr
 		}
 		return nil, fmt.Errorf("erro da API: status %d - %s", resp.StatusCode, string(respBody))
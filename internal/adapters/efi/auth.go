@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
 )
 
 // TokenManager gerencia tokens OAuth2 com refresh automático
@@ -23,6 +25,9 @@ type TokenManager struct {
 	token       string
 	expiresAt   time.Time
 	refreshLead time.Duration // Tempo antes da expiração para fazer refresh
+
+	breaker *CircuitBreaker // Circuit breaker da família "auth" (opcional)
+	clock   domain.Clock
 }
 
 // NewTokenManager cria um novo gerenciador de tokens
@@ -33,6 +38,7 @@ func NewTokenManager(clientID, clientSecret, baseURL string, httpClient *http.Cl
 		baseURL:      baseURL,
 		httpClient:   httpClient,
 		refreshLead:  60 * time.Second, // Renova 1 minuto antes de expirar
+		clock:        domain.SystemClock{},
 	}
 }
 
@@ -40,7 +46,7 @@ func NewTokenManager(clientID, clientSecret, baseURL string, httpClient *http.Cl
 func (tm *TokenManager) GetToken() (string, error) {
 	tm.mu.RLock()
 	// Verifica se o token atual ainda é válido
-	if tm.token != "" && time.Now().Add(tm.refreshLead).Before(tm.expiresAt) {
+	if tm.token != "" && tm.clock.Now().Add(tm.refreshLead).Before(tm.expiresAt) {
 		token := tm.token
 		tm.mu.RUnlock()
 		return token, nil
@@ -57,7 +63,7 @@ func (tm *TokenManager) refresh() (string, error) {
 	defer tm.mu.Unlock()
 
 	// Double-check: outra goroutine pode ter renovado enquanto esperávamos o lock
-	if tm.token != "" && time.Now().Add(tm.refreshLead).Before(tm.expiresAt) {
+	if tm.token != "" && tm.clock.Now().Add(tm.refreshLead).Before(tm.expiresAt) {
 		return tm.token, nil
 	}
 
@@ -77,12 +83,18 @@ func (tm *TokenManager) refresh() (string, error) {
 	req.Header.Set("Authorization", "Basic "+credentials)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	// Faz a requisição
+	// Faz a requisição (protegida pelo circuit breaker de auth)
+	ticket, err := tm.breaker.Allow()
+	if err != nil {
+		return "", err
+	}
 	resp, err := tm.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("erro na requisição de auth: %w", err)
+		tm.breaker.Done(ticket, isBreakerFailure(req.Context(), 0, err))
+		return "", gatewayError(req.Context(), 0, fmt.Errorf("erro na requisição de auth: %w", err))
 	}
 	defer resp.Body.Close()
+	tm.breaker.Done(ticket, isBreakerFailure(req.Context(), resp.StatusCode, nil))
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -92,9 +104,9 @@ func (tm *TokenManager) refresh() (string, error) {
 	if resp.StatusCode != http.StatusOK {
 		var apiErr APIError
 		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Mensagem != "" {
-			return "", fmt.Errorf("erro de autenticação: %s", apiErr.Mensagem)
+			return "", gatewayError(req.Context(), resp.StatusCode, fmt.Errorf("erro de autenticação: %s", apiErr.Mensagem))
 		}
-		return "", fmt.Errorf("erro de autenticação: status %d - %s", resp.StatusCode, string(respBody))
+		return "", gatewayError(req.Context(), resp.StatusCode, fmt.Errorf("erro de autenticação: status %d - %s", resp.StatusCode, string(respBody)))
 	}
 
 	// Parse da resposta
@@ -105,7 +117,7 @@ func (tm *TokenManager) refresh() (string, error) {
 
 	// Atualiza o cache
 	tm.token = tokenResp.AccessToken
-	tm.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	tm.expiresAt = tm.clock.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
 
 	return tm.token, nil
 }
@@ -0,0 +1,329 @@
+package efi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// BreakerState representa o estado de um circuit breaker
+type BreakerState string
+
+const (
+	BreakerStateClosed   BreakerState = "closed"    // Chamadas passam normalmente
+	BreakerStateOpen     BreakerState = "open"      // Chamadas falham imediatamente
+	BreakerStateHalfOpen BreakerState = "half_open" // Sondagens limitadas para testar recuperação
+)
+
+// EndpointFamily agrupa endpoints da API Efí que compartilham um circuit breaker.
+// Uma falha em /v2/rec não deve bloquear cobranças em /v2/cob.
+type EndpointFamily string
+
+const (
+	EndpointFamilyAuth     EndpointFamily = "auth"     // /oauth/token
+	EndpointFamilyCob      EndpointFamily = "cob"      // /v2/cob
+	EndpointFamilyRec      EndpointFamily = "rec"      // /v2/rec (PIX Automático)
+	EndpointFamilyPix      EndpointFamily = "pix"      // /v2/pix (devoluções)
+	EndpointFamilyWebhook  EndpointFamily = "webhook"  // /v2/webhook
+	EndpointFamilySplit    EndpointFamily = "split"    // /v2/gn/split
+	EndpointFamilyAccounts EndpointFamily = "accounts" // API de abertura de contas
+	EndpointFamilyOther    EndpointFamily = "other"
+)
+
+// endpointFamilies lista todas as famílias com breaker dedicado
+var endpointFamilies = []EndpointFamily{
+	EndpointFamilyAuth,
+	EndpointFamilyCob,
+	EndpointFamilyRec,
+	EndpointFamilyPix,
+	EndpointFamilyWebhook,
+	EndpointFamilySplit,
+	EndpointFamilyAccounts,
+	EndpointFamilyOther,
+}
+
+// endpointFamilyForPath identifica a família de um path da API
+func endpointFamilyForPath(path string) EndpointFamily {
+	switch {
+	case strings.HasPrefix(path, "/v2/cob"):
+		return EndpointFamilyCob
+	case strings.HasPrefix(path, "/v2/rec"):
+		return EndpointFamilyRec
+	case strings.HasPrefix(path, "/v2/pix"):
+		return EndpointFamilyPix
+	case strings.HasPrefix(path, "/v2/webhook"):
+		return EndpointFamilyWebhook
+	case strings.HasPrefix(path, "/v2/gn/split"):
+		return EndpointFamilySplit
+	case strings.HasPrefix(path, "/v1/conta-simplificada"):
+		return EndpointFamilyAccounts
+	case strings.HasPrefix(path, "/oauth"):
+		return EndpointFamilyAuth
+	}
+	return EndpointFamilyOther
+}
+
+// halfOpenRetryAfter é o tempo sugerido de espera quando a sondagem já está em andamento
+const halfOpenRetryAfter = time.Second
+
+// BreakerConfig configura os circuit breakers do cliente
+type BreakerConfig struct {
+	FailureThreshold int           // Falhas consecutivas para abrir o circuito
+	OpenTimeout      time.Duration // Tempo aberto antes de permitir sondagem (half-open)
+	HalfOpenProbes   int           // Chamadas simultâneas permitidas em half-open
+}
+
+// DefaultBreakerConfig retorna a configuração padrão dos circuit breakers
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// withDefaults preenche campos zerados com os valores padrão
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	def := DefaultBreakerConfig()
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = def.FailureThreshold
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = def.OpenTimeout
+	}
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = def.HalfOpenProbes
+	}
+	return c
+}
+
+// CircuitBreaker protege uma família de endpoints contra indisponibilidade da Efí.
+// Após FailureThreshold falhas consecutivas o circuito abre e as chamadas falham
+// imediatamente com *CircuitOpenError. Passado o OpenTimeout, o circuito fica
+// half-open e permite sondagens: sucesso fecha o circuito, falha reabre.
+// Cada mudança de estado abre uma nova geração: o resultado de uma chamada
+// liberada em outra geração (ex: sucesso lento que termina com o circuito já
+// aberto) é descartado, e só uma sondagem do half-open fecha o circuito.
+// Um breaker nil sempre permite as chamadas.
+type CircuitBreaker struct {
+	family EndpointFamily
+	cfg    BreakerConfig
+	clock  domain.Clock
+
+	mu         sync.Mutex
+	state      BreakerState
+	generation uint64 // Incrementada a cada mudança de estado
+	failures   int
+	openedAt   time.Time
+	probes     int // Sondagens em andamento (half-open)
+}
+
+// BreakerTicket identifica uma chamada liberada por Allow, a ser entregue a Done
+type BreakerTicket struct {
+	generation uint64
+	probe      bool // Liberada como sondagem do half-open
+}
+
+// NewCircuitBreaker cria um circuit breaker fechado para uma família de endpoints
+func NewCircuitBreaker(family EndpointFamily, cfg BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		family: family,
+		cfg:    cfg.withDefaults(),
+		clock:  domain.SystemClock{},
+		state:  BreakerStateClosed,
+	}
+}
+
+// Allow verifica se uma chamada pode prosseguir.
+// Toda chamada permitida deve ser finalizada com Done, com o ticket devolvido aqui.
+func (b *CircuitBreaker) Allow() (BreakerTicket, error) {
+	if b == nil {
+		return BreakerTicket{}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+
+	if b.state == BreakerStateOpen {
+		retryAt := b.openedAt.Add(b.cfg.OpenTimeout)
+		if now.Before(retryAt) {
+			return BreakerTicket{}, &CircuitOpenError{Family: b.family, RetryIn: retryAt.Sub(now)}
+		}
+		b.transition(BreakerStateHalfOpen)
+		b.probes = 0
+	}
+
+	if b.state == BreakerStateHalfOpen {
+		if b.probes >= b.cfg.HalfOpenProbes {
+			return BreakerTicket{}, &CircuitOpenError{Family: b.family, RetryIn: halfOpenRetryAfter}
+		}
+		b.probes++
+		return BreakerTicket{generation: b.generation, probe: true}, nil
+	}
+
+	return BreakerTicket{generation: b.generation}, nil
+}
+
+// Done registra o resultado de uma chamada permitida por Allow. Resultados de
+// uma geração anterior não mudam o estado.
+func (b *CircuitBreaker) Done(ticket BreakerTicket, failed bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+
+	if ticket.probe {
+		b.probes--
+		if failed {
+			b.open()
+		} else {
+			b.transition(BreakerStateClosed)
+			b.failures = 0
+		}
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.open()
+	}
+}
+
+// open abre o circuito (com o lock)
+func (b *CircuitBreaker) open() {
+	b.transition(BreakerStateOpen)
+	b.openedAt = b.clock.Now()
+	b.probes = 0
+}
+
+// transition muda o estado e inicia uma nova geração (com o lock)
+func (b *CircuitBreaker) transition(state BreakerState) {
+	b.state = state
+	b.generation++
+}
+
+// SetClock troca o relógio usado no timeout do circuito aberto
+func (b *CircuitBreaker) SetClock(clock domain.Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clock
+}
+
+// State retorna o estado atual do breaker
+func (b *CircuitBreaker) State() BreakerState {
+	if b == nil {
+		return BreakerStateClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Status retorna um snapshot do breaker para o health check
+func (b *CircuitBreaker) Status() ports.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := ports.CircuitBreakerStatus{
+		Family:              string(b.family),
+		State:               string(b.state),
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != BreakerStateClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.cfg.OpenTimeout)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// breakerSet mantém um circuit breaker por família de endpoints
+type breakerSet struct {
+	breakers map[EndpointFamily]*CircuitBreaker
+}
+
+// newBreakerSet cria um breaker fechado para cada família conhecida
+func newBreakerSet(cfg BreakerConfig) *breakerSet {
+	set := &breakerSet{breakers: make(map[EndpointFamily]*CircuitBreaker, len(endpointFamilies))}
+	for _, family := range endpointFamilies {
+		set.breakers[family] = NewCircuitBreaker(family, cfg)
+	}
+	return set
+}
+
+// setClock troca o relógio de todos os breakers
+func (s *breakerSet) setClock(clock domain.Clock) {
+	for _, breaker := range s.breakers {
+		breaker.SetClock(clock)
+	}
+}
+
+// get retorna o breaker da família (nil se o set não foi configurado)
+func (s *breakerSet) get(family EndpointFamily) *CircuitBreaker {
+	if s == nil {
+		return nil
+	}
+	return s.breakers[family]
+}
+
+// forPath retorna o breaker responsável por um path da API
+func (s *breakerSet) forPath(path string) *CircuitBreaker {
+	return s.get(endpointFamilyForPath(path))
+}
+
+// statuses retorna o snapshot de todos os breakers, na ordem das famílias
+func (s *breakerSet) statuses() []ports.CircuitBreakerStatus {
+	if s == nil {
+		return nil
+	}
+	result := make([]ports.CircuitBreakerStatus, 0, len(endpointFamilies))
+	for _, family := range endpointFamilies {
+		result = append(result, s.breakers[family].Status())
+	}
+	return result
+}
+
+// isBreakerFailure indica se o resultado de uma chamada conta como falha do gateway.
+// Apenas erros de rede/timeout e respostas 5xx contam; erros 4xx mostram que a Efí
+// está respondendo, e cancelamentos do chamador não dizem nada sobre o gateway.
+func isBreakerFailure(ctx context.Context, status int, err error) bool {
+	if status >= 500 {
+		return true
+	}
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// gatewayError envolve em ports.ErrGatewayUnavailable os erros que contam como
+// falha do gateway: o serviço entra em modo degradado já nas primeiras falhas,
+// sem esperar o circuito abrir. O erro original continua acessível (errors.As).
+func gatewayError(ctx context.Context, status int, err error) error {
+	if err == nil || errors.Is(err, ports.ErrGatewayUnavailable) || !isBreakerFailure(ctx, status, err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ports.ErrGatewayUnavailable, err)
+}
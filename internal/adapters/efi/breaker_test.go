@@ -0,0 +1,254 @@
+package efi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC))
+	breaker := NewCircuitBreaker(EndpointFamilyCob, BreakerConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+		HalfOpenProbes:   1,
+	})
+	breaker.SetClock(clock)
+
+	// Falhas abaixo do limite mantêm o circuito fechado
+	for i := 0; i < 2; i++ {
+		ticket, err := breaker.Allow()
+		if err != nil {
+			t.Fatalf("Allow() error = %v, want nil", err)
+		}
+		breaker.Done(ticket, true)
+	}
+	if breaker.State() != BreakerStateClosed {
+		t.Fatalf("State = %v, want closed", breaker.State())
+	}
+
+	// A terceira falha abre o circuito
+	ticket, _ := breaker.Allow()
+	breaker.Done(ticket, true)
+	if breaker.State() != BreakerStateOpen {
+		t.Fatalf("State = %v, want open", breaker.State())
+	}
+
+	_, err := breaker.Allow()
+	if !IsCircuitOpen(err) {
+		t.Fatalf("Allow() error = %v, want CircuitOpenError", err)
+	}
+	if !errors.Is(err, ports.ErrGatewayUnavailable) {
+		t.Error("CircuitOpenError deveria envolver ports.ErrGatewayUnavailable")
+	}
+	var retryErr ports.RetryAfterError
+	if !errors.As(err, &retryErr) || retryErr.RetryAfter() != time.Minute {
+		t.Errorf("RetryAfter = %v, want 1m", retryErr)
+	}
+
+	// Após o timeout, apenas uma sondagem passa
+	clock.Advance(time.Minute)
+	probe, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("probe Allow() error = %v, want nil", err)
+	}
+	if breaker.State() != BreakerStateHalfOpen {
+		t.Fatalf("State = %v, want half_open", breaker.State())
+	}
+	if _, err := breaker.Allow(); !IsCircuitOpen(err) {
+		t.Fatalf("second probe Allow() error = %v, want CircuitOpenError", err)
+	}
+
+	// Sondagem com falha reabre o circuito
+	breaker.Done(probe, true)
+	if breaker.State() != BreakerStateOpen {
+		t.Fatalf("State = %v, want open", breaker.State())
+	}
+
+	// Sondagem com sucesso fecha o circuito
+	clock.Advance(time.Minute)
+	probe, _ = breaker.Allow()
+	breaker.Done(probe, false)
+	if breaker.State() != BreakerStateClosed {
+		t.Fatalf("State = %v, want closed", breaker.State())
+	}
+	if status := breaker.Status(); status.ConsecutiveFailures != 0 || status.OpenedAt != nil {
+		t.Errorf("Status() = %+v, want reset", status)
+	}
+}
+
+func TestCircuitBreaker_StaleResultsIgnored(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC))
+	breaker := NewCircuitBreaker(EndpointFamilyCob, BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		HalfOpenProbes:   1,
+	})
+	breaker.SetClock(clock)
+
+	// Chamada lenta liberada com o circuito fechado; outra falha e abre o circuito
+	slow, _ := breaker.Allow()
+	failing, _ := breaker.Allow()
+	breaker.Done(failing, true)
+	if breaker.State() != BreakerStateOpen {
+		t.Fatalf("State = %v, want open", breaker.State())
+	}
+
+	// O sucesso lento chega com o circuito aberto: não fecha
+	breaker.Done(slow, false)
+	if breaker.State() != BreakerStateOpen {
+		t.Fatalf("State após sucesso atrasado = %v, want open", breaker.State())
+	}
+
+	// Nem em half-open: só a sondagem decide
+	clock.Advance(time.Minute)
+	probe, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	breaker.Done(slow, false)
+	if breaker.State() != BreakerStateHalfOpen {
+		t.Fatalf("State após sucesso atrasado em half-open = %v, want half_open", breaker.State())
+	}
+	breaker.Done(probe, false)
+	if breaker.State() != BreakerStateClosed {
+		t.Fatalf("State após a sondagem = %v, want closed", breaker.State())
+	}
+
+	// Falha atrasada da geração anterior não reabre o circuito recém-fechado
+	breaker.Done(failing, true)
+	if breaker.State() != BreakerStateClosed {
+		t.Errorf("State após falha atrasada = %v, want closed", breaker.State())
+	}
+}
+
+func TestEndpointFamilyForPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want EndpointFamily
+	}{
+		{"/v2/cob/tx123", EndpointFamilyCob},
+		{"/v2/rec", EndpointFamilyRec},
+		{"/v2/pix/E123/devolucao/dev1", EndpointFamilyPix},
+		{"/v2/webhook/chave", EndpointFamilyWebhook},
+		{"/v2/gn/split/config", EndpointFamilySplit},
+		{"/v1/conta-simplificada", EndpointFamilyAccounts},
+		{"/v2/loc", EndpointFamilyOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := endpointFamilyForPath(tt.path); got != tt.want {
+				t.Errorf("endpointFamilyForPath(%v) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_BreakerOpensOnServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+			return
+		}
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	breakers := newBreakerSet(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	client := &Client{
+		baseURL:      server.URL,
+		httpClient:   server.Client(),
+		tokenManager: NewTokenManager("id", "secret", server.URL, server.Client()),
+		breakers:     breakers,
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetPixCharge(ctx, "tx123"); err == nil || IsCircuitOpen(err) {
+			t.Fatalf("GetPixCharge() error = %v, want server error", err)
+		}
+	}
+
+	_, err := client.GetPixCharge(ctx, "tx123")
+	if !IsCircuitOpen(err) {
+		t.Fatalf("GetPixCharge() error = %v, want CircuitOpenError", err)
+	}
+	if calls != 2 {
+		t.Errorf("server calls = %d, want 2 (circuito aberto não deve chamar a Efí)", calls)
+	}
+
+	// Outras famílias continuam disponíveis
+	if breakers.get(EndpointFamilyRec).State() != BreakerStateClosed {
+		t.Error("breaker de rec deveria continuar fechado")
+	}
+
+	degraded := false
+	for _, status := range client.BreakerStatus() {
+		if status.Family == string(EndpointFamilyCob) && status.State == string(BreakerStateOpen) {
+			degraded = true
+		}
+	}
+	if !degraded {
+		t.Error("BreakerStatus() deveria reportar cob aberto")
+	}
+}
+
+func TestClient_FirstFailuresDegradeCheckout(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"503", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}},
+		{"timeout", func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/oauth/token" {
+					w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+					return
+				}
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			httpClient := server.Client()
+			httpClient.Timeout = 50 * time.Millisecond
+			client := &Client{
+				baseURL:      server.URL,
+				httpClient:   httpClient,
+				tokenManager: NewTokenManager("id", "secret", server.URL, httpClient),
+				breakers:     newBreakerSet(BreakerConfig{FailureThreshold: 5, OpenTimeout: time.Minute}),
+			}
+
+			// Primeira falha, circuito ainda fechado: a cobrança vai para a fila
+			checkout := service.NewCheckoutService(client, memory.NewChargeQueue(), service.CheckoutOptions{})
+			result, err := checkout.CreatePixCharge(context.Background(), "academy-1", &ports.PixChargeRequest{Amount: 9700})
+			if err != nil {
+				t.Fatalf("CreatePixCharge() error = %v, want modo degradado", err)
+			}
+			if result.Status != service.CheckoutStatusQueued {
+				t.Errorf("Status = %v, want queued", result.Status)
+			}
+			if client.breakers.get(EndpointFamilyCob).State() != BreakerStateClosed {
+				t.Error("uma falha não deveria abrir o circuito")
+			}
+		})
+	}
+}
@@ -9,20 +9,25 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/pkcs12"
 
 	"github.com/magnani/black-belt-app/backend/internal/config"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
 	"github.com/magnani/black-belt-app/backend/internal/ports"
 )
 
-// Client implementa ports.PaymentProvider para a API Efí Bank
+// Client implementa ports.PixProvider para a API Efí Bank
 type Client struct {
-	baseURL      string
-	pixKey       string // Chave PIX do recebedor
-	httpClient   *http.Client
-	tokenManager *TokenManager
+	baseURL       string
+	pixKey        string // Chave PIX do recebedor
+	webhookSecret string // Secret HMAC dos webhooks (opcional)
+	httpClient    *http.Client
+	tokenManager  *TokenManager
+	breakers      *breakerSet // Circuit breakers por família de endpoints
+	clock         domain.Clock
 }
 
 // NewClient cria um novo cliente Efí com mTLS configurado
@@ -41,17 +46,40 @@ func NewClient(cfg *config.EfiConfig, pixKey string) (*Client, error) {
 		},
 	}
 
+	// Circuit breakers por família de endpoints
+	breakers := newBreakerSet(BreakerConfig{
+		FailureThreshold: cfg.BreakerFailureThreshold,
+		OpenTimeout:      cfg.BreakerOpenTimeout,
+		HalfOpenProbes:   cfg.BreakerHalfOpenProbes,
+	})
+
 	// Cria o gerenciador de tokens
 	tokenManager := NewTokenManager(cfg.ClientID, cfg.ClientSecret, cfg.PixURL, httpClient)
+	tokenManager.breaker = breakers.get(EndpointFamilyAuth)
 
 	return &Client{
 		baseURL:      cfg.PixURL,
 		pixKey:       pixKey,
 		httpClient:   httpClient,
 		tokenManager: tokenManager,
+		breakers:     breakers,
+		clock:        domain.SystemClock{},
 	}, nil
 }
 
+// SetWebhookSecret define o secret usado em ValidateWebhookSignature
+func (c *Client) SetWebhookSecret(secret string) {
+	c.webhookSecret = secret
+}
+
+// SetClock troca o relógio usado na validade do token, nas datas padrão e
+// nos circuit breakers
+func (c *Client) SetClock(clock domain.Clock) {
+	c.clock = clock
+	c.tokenManager.clock = clock
+	c.breakers.setClock(clock)
+}
+
 // loadCertificate carrega um certificado .p12 ou .pem para mTLS
 func loadCertificate(certPath, password string) (*tls.Config, error) {
 	certData, err := os.ReadFile(certPath)
@@ -76,12 +104,28 @@ func loadCertificate(certPath, password string) (*tls.Config, error) {
 	}, nil
 }
 
-// doRequest executa uma requisição HTTP autenticada
+// doRequest executa uma requisição HTTP autenticada, protegida pelo circuit breaker
+// da família do endpoint. Com o circuito aberto, falha imediatamente com *CircuitOpenError;
+// timeouts, erros de rede e respostas 5xx voltam envolvendo ports.ErrGatewayUnavailable.
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	breaker := c.breakers.forPath(path)
+	ticket, err := breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, status, err := c.execute(ctx, method, path, body)
+	breaker.Done(ticket, isBreakerFailure(ctx, status, err))
+
+	return respBody, gatewayError(ctx, status, err)
+}
+
+// execute executa a requisição e retorna o body e o status HTTP (0 se não houve resposta)
+func (c *Client) execute(ctx context.Context, method, path string, body interface{}) ([]byte, int, error) {
 	// Obtém token válido
 	token, err := c.tokenManager.GetToken()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// Prepara o body se houver
@@ -89,7 +133,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("erro ao serializar body: %w", err)
+			return nil, 0, fmt.Errorf("erro ao serializar body: %w", err)
 		}
 		reqBody = bytes.NewReader(jsonBody)
 	}
@@ -98,7 +142,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+		return nil, 0, fmt.Errorf("erro ao criar requisição: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -107,31 +151,37 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	// Executa
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro na requisição HTTP: %w", err)
+		return nil, 0, fmt.Errorf("erro na requisição HTTP: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao ler resposta: %w", err)
+		return nil, resp.StatusCode, fmt.Errorf("erro ao ler resposta: %w", err)
 	}
 
 	// Trata erros de autenticação
 	if resp.StatusCode == http.StatusUnauthorized {
 		c.tokenManager.Invalidate()
-		return nil, fmt.Errorf("token inválido ou expirado")
+		return nil, resp.StatusCode, fmt.Errorf("token inválido ou expirado")
 	}
 
 	// Trata erros da API
 	if resp.StatusCode >= 400 {
 		var apiErr APIError
 		if json.Unmarshal(respBody, &apiErr) == nil {
-			return nil, &apiErr
+			if apiErr.Status == 0 {
+				apiErr.Status = resp.StatusCode
+			}
+			return nil, resp.StatusCode, &apiErr
+		}
+		return nil, resp.StatusCode, &APIError{
+			Status: resp.StatusCode,
+			Detail: fmt.Sprintf("erro da API: status %d - %s", resp.StatusCode, string(respBody)),
 		}
-		return nil, fmt.Errorf("erro da API: status %d - %s", resp.StatusCode, string(respBody))
 	}
 
-	return respBody, nil
+	return respBody, resp.StatusCode, nil
 }
 
 // CreatePixCharge cria uma nova cobrança PIX imediata
@@ -228,22 +278,33 @@ func (c *Client) CancelPixCharge(ctx context.Context, txid string) error {
 	return nil
 }
 
-// RefundPix solicita devolução de um PIX recebido
-func (c *Client) RefundPix(ctx context.Context, e2eID string, amount int64) error {
-	// Gera um ID único para a devolução
-	refundID := fmt.Sprintf("dev%d", time.Now().UnixNano())
-	path := fmt.Sprintf("/v2/pix/%s/devolucao/%s", e2eID, refundID)
+// RefundPix solicita devolução (total ou parcial) de um PIX recebido.
+// O ID da devolução vai no path: repetir o PUT com o mesmo ID não devolve duas vezes.
+func (c *Client) RefundPix(ctx context.Context, req *ports.PixRefundRequest) (*ports.RefundResult, error) {
+	refundID := req.RefundID
+	if refundID == "" {
+		refundID = fmt.Sprintf("dev%d", c.clock.Now().UnixNano())
+	}
+	path := fmt.Sprintf("/v2/pix/%s/devolucao/%s", req.EndToEndID, refundID)
 
 	devReq := PixDevolucaoRequest{
-		Valor: fmt.Sprintf("%.2f", float64(amount)/100),
+		Valor:     fmt.Sprintf("%.2f", float64(req.Amount)/100),
+		Descricao: req.Reason,
 	}
 
-	_, err := c.doRequest(ctx, http.MethodPut, path, devReq)
+	respBody, err := c.doRequest(ctx, http.MethodPut, path, devReq)
 	if err != nil {
-		return fmt.Errorf("erro ao solicitar devolução: %w", err)
+		return nil, fmt.Errorf("erro ao solicitar devolução: %w", err)
 	}
 
-	return nil
+	var devolucao PixDevolucao
+	if err := json.Unmarshal(respBody, &devolucao); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar devolução: %w", err)
+	}
+	return &ports.RefundResult{
+		GatewayRefundID: devolucao.RTrId,
+		Status:          refundStatus(devolucao.Status),
+	}, nil
 }
 
 // RegisterWebhook registra a URL de webhook para uma chave PIX
@@ -260,34 +321,178 @@ func (c *Client) RegisterWebhook(ctx context.Context, pixKey string, webhookURL
 	return nil
 }
 
+// SetupRecurrence configura PIX Automático mensal para uma academia.
+// Na Efí a autorização e a recorrência compartilham o mesmo idRec.
+func (c *Client) SetupRecurrence(ctx context.Context, req *ports.PixRecurrenceSetupRequest) (*ports.PixRecurrenceSetupResponse, error) {
+	debtor := PixDevedor{Nome: req.CustomerName}
+	if len(req.CustomerCPF) == 14 {
+		debtor.CNPJ = req.CustomerCPF
+	} else {
+		debtor.CPF = req.CustomerCPF
+	}
+
+	object := req.Description
+	if object == "" {
+		object = "Assinatura BlackBelt"
+	}
+
+	periodicity := PeriodicityMonthly
+	if req.Interval == domain.BillingIntervalYearly {
+		periodicity = PeriodicityYearly
+	}
+
+	start := req.StartDate
+	if start.IsZero() {
+		start = c.clock.Now()
+	}
+
+	rec, err := c.CreateRecurrence(ctx, CreateRecurrenceRequest{
+		Contract:    req.AcademyID,
+		Debtor:      debtor,
+		Object:      object,
+		StartDate:   start.In(domain.BillingLocation).Format("2006-01-02"),
+		Periodicity: periodicity,
+		Amount:      fmt.Sprintf("%.2f", float64(req.Amount)/100),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.PixRecurrenceSetupResponse{
+		AuthorizationID: rec.ID,
+		RecurrenceID:    rec.ID,
+		Location:        rec.Location,
+		PixCode:         rec.QRCode,
+	}, nil
+}
+
+// UpdateRecurrenceAmount altera o valor das próximas cobranças de uma recorrência
+func (c *Client) UpdateRecurrenceAmount(ctx context.Context, recurrenceID string, amount int64) error {
+	_, err := c.UpdateRecurrence(ctx, recurrenceID, UpdateRecurrenceRequest{
+		Amount: fmt.Sprintf("%.2f", float64(amount)/100),
+	})
+	return err
+}
+
+// RescheduleRecurrence move o próximo vencimento de uma recorrência
+// (data em America/Sao_Paulo), sem mudar a situação dela
+func (c *Client) RescheduleRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error {
+	_, err := c.UpdateRecurrence(ctx, recurrenceID, UpdateRecurrenceRequest{
+		NextDueDate: nextDueDate.In(domain.BillingLocation).Format("2006-01-02"),
+	})
+	return err
+}
+
+// PauseRecurrence suspende as cobranças de uma recorrência aprovada
+func (c *Client) PauseRecurrence(ctx context.Context, recurrenceID string) error {
+	_, err := c.UpdateRecurrence(ctx, recurrenceID, UpdateRecurrenceRequest{
+		Status: string(RecurrenceStatusSuspended),
+	})
+	return err
+}
+
+// ResumeRecurrence volta uma recorrência suspensa para aprovada, com o próximo
+// vencimento em nextDueDate (data em America/Sao_Paulo; zero = mantém o calendário)
+func (c *Client) ResumeRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error {
+	req := UpdateRecurrenceRequest{Status: string(RecurrenceStatusApproved)}
+	if !nextDueDate.IsZero() {
+		req.NextDueDate = nextDueDate.In(domain.BillingLocation).Format("2006-01-02")
+	}
+	_, err := c.UpdateRecurrence(ctx, recurrenceID, req)
+	return err
+}
+
+// ValidateWebhookSignature valida a assinatura HMAC-SHA256 de um webhook.
+// Sem secret configurado a Efí autentica apenas via mTLS, então todo payload é aceito.
+func (c *Client) ValidateWebhookSignature(payload []byte, signature string) bool {
+	if c.webhookSecret == "" {
+		return true
+	}
+	return validHMACSignature(c.webhookSecret, payload, signature)
+}
+
 // ParseWebhookEvent processa o payload de um webhook e retorna o evento estruturado
-func (c *Client) ParseWebhookEvent(payload []byte, signature string) (*ports.WebhookEvent, error) {
-	// TODO: Implementar validação de assinatura quando Efí disponibilizar
-	// Por enquanto, apenas faz o parse do payload
+func (c *Client) ParseWebhookEvent(payload []byte) (*ports.IncomingWebhookEvent, error) {
+	return ParseWebhookEvent(payload)
+}
 
-	var webhookPayload PixWebhookPayload
+// ParseWebhookEvent converte o payload de um webhook da Efí no evento estruturado
+// (usado também por fakes que geram payloads no formato da Efí)
+func ParseWebhookEvent(payload []byte) (*ports.IncomingWebhookEvent, error) {
+	var webhookPayload WebhookEvent
 	if err := json.Unmarshal(payload, &webhookPayload); err != nil {
 		return nil, fmt.Errorf("erro ao decodificar webhook: %w", err)
 	}
 
-	// Converte para o formato genérico
-	event := &ports.WebhookEvent{
-		Type:      "pix",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Data:      make(map[string]interface{}),
+	event := &ports.IncomingWebhookEvent{
+		Gateway: "pix_auto",
+		Payload: json.RawMessage(payload),
 	}
 
-	if len(webhookPayload.Pix) > 0 {
-		pix := webhookPayload.Pix[0]
-		event.Data["txid"] = pix.TxID
-		event.Data["endToEndId"] = pix.EndToEndID
-		event.Data["valor"] = pix.Valor
-		event.Data["horario"] = pix.Horario
-		event.Data["pagador"] = pix.Pagador
+	// A Efí agrupa vários PIX na mesma notificação: o ID do evento cobre todos
+	// os e2eId do lote, senão um lote novo que começa com um PIX já visto
+	// seria descartado como reentrega
+	switch {
+	case hasRefunds(webhookPayload.Pix):
+		// A Efí reenvia o PIX com as devoluções; cada mudança de status é um evento
+		event.EventType = string(WebhookEventDevolucao)
+		ids := make([]string, 0, len(webhookPayload.Pix))
+		for _, pix := range webhookPayload.Pix {
+			id := pix.EndToEndID
+			for _, dev := range pix.Devolucoes {
+				id += ":" + dev.ID + "=" + dev.Status
+			}
+			ids = append(ids, id)
+		}
+		event.EventID = strings.Join(ids, ",")
+	case len(webhookPayload.Cobr) > 0:
+		// Cada mudança de status de uma cobrança do PIX Automático é um evento
+		event.EventType = string(WebhookEventCobr)
+		ids := make([]string, 0, len(webhookPayload.Cobr))
+		for _, cobr := range webhookPayload.Cobr {
+			ids = append(ids, cobr.TxID+"="+cobr.Status)
+		}
+		event.EventID = strings.Join(ids, ",")
+	case webhookPayload.Rec != nil:
+		// Uma mesma recorrência gera um evento por mudança de status
+		event.EventType = string(WebhookEventRecurrence)
+		event.EventID = webhookPayload.Rec.ID + ":" + string(webhookPayload.Rec.Status)
+	case len(webhookPayload.Pix) > 0:
+		event.EventType = string(WebhookEventPix)
+		ids := make([]string, 0, len(webhookPayload.Pix))
+		for _, pix := range webhookPayload.Pix {
+			ids = append(ids, pix.EndToEndID)
+		}
+		event.EventID = strings.Join(ids, ",")
+	default:
+		return nil, fmt.Errorf("webhook sem eventos pix, cobr ou rec")
 	}
 
 	return event, nil
 }
 
-// Garante que Client implementa PaymentProvider
-var _ ports.PaymentProvider = (*Client)(nil)
+// hasRefunds verifica se algum PIX do lote traz devoluções
+func hasRefunds(pixes []PixPayment) bool {
+	for _, pix := range pixes {
+		if len(pix.Devolucoes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GatewayName implementa ports.GatewayHealthReporter
+func (c *Client) GatewayName() string {
+	return "efi"
+}
+
+// BreakerStatus implementa ports.GatewayHealthReporter
+func (c *Client) BreakerStatus() []ports.CircuitBreakerStatus {
+	return c.breakers.statuses()
+}
+
+// Garante que Client implementa PixProvider e GatewayHealthReporter
+var (
+	_ ports.PixProvider           = (*Client)(nil)
+	_ ports.GatewayHealthReporter = (*Client)(nil)
+)
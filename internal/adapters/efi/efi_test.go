@@ -3,10 +3,17 @@ package efi
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
 )
 
 func TestValidateSplitConfig(t *testing.T) {
@@ -160,6 +167,30 @@ func TestIsNotFound(t *testing.T) {
 	}
 }
 
+func TestAPIError_GatewayRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"400 wrapped", fmt.Errorf("erro ao solicitar devolução: %w", &APIError{Status: 400}), true},
+		{"404", &APIError{Status: 404}, true},
+		{"408 timeout", &APIError{Status: 408}, false},
+		{"409 conflict", &APIError{Status: 409}, false},
+		{"500", &APIError{Status: 500}, false},
+		{"circuit open", &CircuitOpenError{Family: EndpointFamilyPix}, false},
+		{"network error", errors.New("erro na requisição HTTP: timeout"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, ports.ErrGatewayRejected); got != tt.want {
+				t.Errorf("errors.Is(ErrGatewayRejected) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractPixKeyFromURL(t *testing.T) {
 	tests := []struct {
 		path string
@@ -280,6 +311,199 @@ func TestWebhookHandler_HandleEfiWebhook(t *testing.T) {
 	})
 }
 
+// inboxFunc adapta uma função a ports.WebhookInbox
+type inboxFunc func(ctx context.Context, event *ports.IncomingWebhookEvent) (*domain.WebhookEvent, error)
+
+func (f inboxFunc) Receive(ctx context.Context, event *ports.IncomingWebhookEvent) (*domain.WebhookEvent, error) {
+	return f(ctx, event)
+}
+
+func TestWebhookHandler_Inbox(t *testing.T) {
+	body, _ := json.Marshal(WebhookEvent{Pix: []PixPayment{{EndToEndID: "E1", TxID: "tx1", Value: "10.00"}}})
+
+	tests := []struct {
+		name     string
+		storeErr error
+		wantCode int
+	}{
+		{"grava e confirma", nil, http.StatusOK},
+		{"falha ao gravar pede reenvio", errors.New("banco fora"), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.WebhookEvent
+			called := false
+
+			handler := NewWebhookHandler()
+			handler.SkipSignatureValidation = true
+			handler.OnPixPayment = func(ctx context.Context, pix PixPayment) error {
+				called = true
+				return nil
+			}
+			handler.Inbox = inboxFunc(func(ctx context.Context, event *ports.IncomingWebhookEvent) (*domain.WebhookEvent, error) {
+				if tt.storeErr != nil {
+					return nil, tt.storeErr
+				}
+				stored = domain.NewWebhookEvent(event.Gateway, event.EventID, event.EventType, event.Payload, nil, time.Now())
+				return stored, nil
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/efi", nil)
+			req.Body = &testReadCloser{data: body}
+			w := httptest.NewRecorder()
+			handler.HandleEfiWebhook(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if called {
+				t.Fatal("callback rodou durante o request")
+			}
+			if stored == nil {
+				return
+			}
+			if stored.EventID != "E1" {
+				t.Errorf("event_id = %q, want E1 (e2eid)", stored.EventID)
+			}
+			if err := handler.Process(context.Background(), stored); err != nil || !called {
+				t.Errorf("Process = %v, callback chamado = %v", err, called)
+			}
+		})
+	}
+}
+
+func TestParseRefundNotifications(t *testing.T) {
+	payload := []byte(`{"pix":[{"endToEndId":"E123","txid":"tx123","valor":"99.00","horario":"2026-06-01T10:00:00Z",
+		"devolucoes":[
+			{"id":"dev1","rtrId":"D111","valor":"30.00","horario":{"solicitacao":"2026-06-10T10:00:00Z"},"status":"DEVOLVIDO"},
+			{"id":"dev2","rtrId":"D222","valor":"9.5","status":"NAO_REALIZADO","motivo":"conta encerrada"},
+			{"id":"dev3","rtrId":"D333","valor":"10.00","status":"EM_PROCESSAMENTO"}
+		]}]}`)
+
+	refunds, err := ParseRefundNotifications(payload)
+	if err != nil {
+		t.Fatalf("ParseRefundNotifications() error = %v", err)
+	}
+	want := []struct {
+		id     string
+		amount int
+		status domain.RefundStatus
+	}{
+		{"dev1", 3000, domain.RefundStatusSucceeded},
+		{"dev2", 950, domain.RefundStatusFailed},
+		{"dev3", 1000, domain.RefundStatusPending},
+	}
+	if len(refunds) != len(want) {
+		t.Fatalf("devoluções = %d, want %d", len(refunds), len(want))
+	}
+	for i, w := range want {
+		r := refunds[i]
+		if r.RefundID != w.id || r.Amount != w.amount || r.Status != w.status || r.GatewayPaymentID != "tx123" {
+			t.Errorf("refunds[%d] = %+v, want %s %d %s", i, r, w.id, w.amount, w.status)
+		}
+	}
+	if refunds[1].FailureReason != "conta encerrada" {
+		t.Errorf("FailureReason = %q", refunds[1].FailureReason)
+	}
+
+	// Cada mudança de status das devoluções é um evento distinto
+	client := &Client{}
+	event, err := client.ParseWebhookEvent(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	if event.EventType != string(WebhookEventDevolucao) || event.EventID != "E123:dev1=DEVOLVIDO:dev2=NAO_REALIZADO:dev3=EM_PROCESSAMENTO" {
+		t.Errorf("evento = %s %s", event.EventType, event.EventID)
+	}
+}
+
+func TestParseChargeNotifications(t *testing.T) {
+	paid := []byte(`{"pix":[
+		{"endToEndId":"E1","txid":"tx1","valor":"199.00","horario":"2026-06-01T10:00:00Z","idRec":"RR1"},
+		{"endToEndId":"E2","txid":"tx2","valor":"99.00","horario":"2026-06-01T10:00:00Z",
+			"devolucoes":[{"id":"dev1","rtrId":"D1","valor":"10.00","status":"DEVOLVIDO"}]}]}`)
+	charges, err := ParseChargeNotifications(paid)
+	if err != nil {
+		t.Fatalf("ParseChargeNotifications() error = %v", err)
+	}
+	if len(charges) != 1 {
+		t.Fatalf("cobranças = %d, want 1 (PIX com devolução é ignorado)", len(charges))
+	}
+	if c := charges[0]; !c.Paid || c.SubscriptionRef != "RR1" || c.GatewayPaymentID != "tx1" || c.EndToEndID != "E1" || c.Amount != 19900 {
+		t.Errorf("charges[0] = %+v", c)
+	}
+
+	failed := []byte(`{"cobr":[
+		{"txid":"tx3","idRec":"RR1","status":"ATIVA","valor":{"original":"199.00"}},
+		{"txid":"tx4","idRec":"RR1","status":"NAO_REALIZADA","valor":{"original":"199.00"},
+			"rejeicao":{"codigo":"AM04","descricao":"saldo insuficiente"}}]}`)
+	charges, err = ParseChargeNotifications(failed)
+	if err != nil {
+		t.Fatalf("ParseChargeNotifications() error = %v", err)
+	}
+	if len(charges) != 1 {
+		t.Fatalf("cobranças = %d, want 1 (cobr em andamento é ignorada)", len(charges))
+	}
+	if c := charges[0]; c.Paid || c.GatewayPaymentID != "tx4" || c.FailureCode != "AM04" || c.FailureReason != "saldo insuficiente" || c.Amount != 19900 {
+		t.Errorf("charges[0] = %+v", c)
+	}
+
+	event, err := ParseWebhookEvent(failed)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	if event.EventType != string(WebhookEventCobr) || event.EventID != "tx3=ATIVA,tx4=NAO_REALIZADA" {
+		t.Errorf("evento = %s %s", event.EventType, event.EventID)
+	}
+}
+
+func TestParseWebhookEvent_Batch(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		wantType WebhookEventType
+		wantID   string
+	}{
+		{
+			name:     "PIX único",
+			payload:  `{"pix":[{"endToEndId":"E1","txid":"tx1","valor":"10.00"}]}`,
+			wantType: WebhookEventPix,
+			wantID:   "E1",
+		},
+		{
+			name:     "lote de PIX",
+			payload:  `{"pix":[{"endToEndId":"E1","txid":"tx1","valor":"10.00"},{"endToEndId":"E2","txid":"tx2","valor":"20.00"}]}`,
+			wantType: WebhookEventPix,
+			wantID:   "E1,E2",
+		},
+		{
+			name:     "lote de devoluções",
+			payload:  `{"pix":[{"endToEndId":"E1","devolucoes":[{"id":"d1","status":"DEVOLVIDO"}]},{"endToEndId":"E2","devolucoes":[{"id":"d2","status":"EM_PROCESSAMENTO"}]}]}`,
+			wantType: WebhookEventDevolucao,
+			wantID:   "E1:d1=DEVOLVIDO,E2:d2=EM_PROCESSAMENTO",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseWebhookEvent([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseWebhookEvent() error = %v", err)
+			}
+			if event.EventType != string(tt.wantType) || event.EventID != tt.wantID {
+				t.Errorf("evento = %s %s, want %s %s", event.EventType, event.EventID, tt.wantType, tt.wantID)
+			}
+		})
+	}
+
+	// Lote novo que começa com um PIX já recebido não colide com o anterior
+	first, _ := ParseWebhookEvent([]byte(`{"pix":[{"endToEndId":"E1"}]}`))
+	second, _ := ParseWebhookEvent([]byte(`{"pix":[{"endToEndId":"E1"},{"endToEndId":"E3"}]}`))
+	if first.EventID == second.EventID {
+		t.Errorf("lotes diferentes com o mesmo ID %s", first.EventID)
+	}
+}
+
 type testReadCloser struct {
 	data []byte
 	pos  int
@@ -335,3 +559,35 @@ func TestRecurrenceStatusConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenManager_RefreshesNearExpiry(t *testing.T) {
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "tok-" + strconv.Itoa(issued), ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	clock := domain.NewFakeClock(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	tm := NewTokenManager("id", "secret", server.URL, server.Client())
+	tm.clock = clock
+
+	steps := []struct {
+		advance time.Duration
+		want    string
+	}{
+		{0, "tok-1"},
+		{58 * time.Minute, "tok-1"}, // Ainda fora da janela de refresh
+		{time.Minute, "tok-2"},      // Faltando 1 minuto: renova
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		token, err := tm.GetToken()
+		if err != nil {
+			t.Fatalf("GetToken() error = %v", err)
+		}
+		if token != step.want {
+			t.Errorf("após %v: token = %q, want %q", step.advance, token, step.want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Package efitest fornece um PixProvider em memória que imita a Efí, para
+// desenvolvimento local e testes sem certificado nem acesso à API.
+//
+// O fake gera txids, códigos copia e cola e recorrências, e monta os webhooks
+// no formato da Efí (Pay, Refund, ApproveRecurrence) para alimentar o mesmo
+// endpoint que recebe os webhooks reais.
+package efitest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Status das cobranças na Efí
+const (
+	statusActive    = "ATIVA"
+	statusCompleted = "CONCLUIDA"
+	statusRemoved   = "REMOVIDA_PELO_USUARIO_RECEBEDOR"
+)
+
+// charge é uma cobrança PIX criada no fake
+type charge struct {
+	req        ports.PixChargeRequest
+	status     string
+	endToEndID string
+	devolucoes []efi.PixDevolucao
+}
+
+// Fake implementa ports.PixProvider em memória (thread-safe)
+type Fake struct {
+	mu          sync.Mutex
+	charges     map[string]*charge // por txid
+	byE2E       map[string]string  // endToEndId -> txid
+	recurrences map[string]*efi.RecurrenceEvent
+	clock       domain.Clock
+
+	// Err, se definido, é devolvido por todas as chamadas (ex: ports.ErrGatewayUnavailable)
+	Err error
+}
+
+// New cria o fake vazio
+func New() *Fake {
+	return &Fake{
+		charges:     make(map[string]*charge),
+		byE2E:       make(map[string]string),
+		recurrences: make(map[string]*efi.RecurrenceEvent),
+		clock:       domain.SystemClock{},
+	}
+}
+
+// SetClock troca o relógio usado nos horários dos webhooks
+func (f *Fake) SetClock(clock domain.Clock) {
+	f.clock = clock
+}
+
+// CreatePixCharge cria a cobrança e devolve um copia e cola fictício
+func (f *Fake) CreatePixCharge(ctx context.Context, req *ports.PixChargeRequest) (*ports.PixChargeResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	txid := req.TxID
+	if txid == "" {
+		txid = randomID(16)
+	}
+	f.charges[txid] = &charge{req: *req, status: statusActive}
+	f.charges[txid].req.TxID = txid
+	return f.response(txid), nil
+}
+
+// GetPixCharge consulta uma cobrança criada no fake
+func (f *Fake) GetPixCharge(ctx context.Context, txid string) (*ports.PixChargeResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.charges[txid]; !ok {
+		return nil, fmt.Errorf("cobrança %s não encontrada", txid)
+	}
+	return f.response(txid), nil
+}
+
+// CancelPixCharge remove uma cobrança ainda não paga
+func (f *Fake) CancelPixCharge(ctx context.Context, txid string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.charges[txid]
+	if !ok {
+		return fmt.Errorf("cobrança %s não encontrada", txid)
+	}
+	if c.status != statusActive {
+		return fmt.Errorf("cobrança %s não está ativa: %s", txid, c.status)
+	}
+	c.status = statusRemoved
+	return nil
+}
+
+// RefundPix registra a devolução em processamento; Refund gera o webhook de liquidação.
+// Repetir o mesmo RefundID não cria uma segunda devolução, como na Efí.
+func (f *Fake) RefundPix(ctx context.Context, req *ports.PixRefundRequest) (*ports.RefundResult, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.charges[f.byE2E[req.EndToEndID]]
+	if !ok {
+		return nil, fmt.Errorf("%w: pix %s não encontrado", ports.ErrGatewayRejected, req.EndToEndID)
+	}
+	for _, dev := range c.devolucoes {
+		if dev.ID == req.RefundID {
+			return &ports.RefundResult{GatewayRefundID: dev.RTrId, Status: domain.RefundStatusPending}, nil
+		}
+	}
+	dev := efi.PixDevolucao{
+		ID:     req.RefundID,
+		RTrId:  "D" + randomID(16),
+		Valor:  formatValor(req.Amount),
+		Status: "EM_PROCESSAMENTO",
+	}
+	c.devolucoes = append(c.devolucoes, dev)
+	return &ports.RefundResult{GatewayRefundID: dev.RTrId, Status: domain.RefundStatusPending}, nil
+}
+
+// SetupRecurrence cria a recorrência aguardando aprovação do pagador
+func (f *Fake) SetupRecurrence(ctx context.Context, req *ports.PixRecurrenceSetupRequest) (*ports.PixRecurrenceSetupResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := "RR" + randomID(14)
+	f.recurrences[id] = &efi.RecurrenceEvent{ID: id, Contract: req.AcademyID, Status: efi.RecurrenceStatusCreated}
+	return &ports.PixRecurrenceSetupResponse{
+		AuthorizationID: id,
+		RecurrenceID:    id,
+		Location:        "pix.example.com/qr/v2/rec/" + id,
+		PixCode:         fmt.Sprintf("00020101021226870014BR.GOV.BCB.PIX2565pix.example.com/qr/v2/rec/%s5204000053039865802BR5909BLACKBELT6009SAO PAULO62070503***6304FAKE", id),
+	}, nil
+}
+
+// CancelRecurrence cancela a recorrência
+func (f *Fake) CancelRecurrence(ctx context.Context, authorizationID string) error {
+	return f.setRecurrenceStatus(authorizationID, efi.RecurrenceStatusCancelled)
+}
+
+// UpdateRecurrenceAmount aceita a alteração (o fake não guarda valores de recorrência)
+func (f *Fake) UpdateRecurrenceAmount(ctx context.Context, recurrenceID string, amount int64) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.recurrences[recurrenceID]; !ok {
+		return fmt.Errorf("recorrência %s não encontrada", recurrenceID)
+	}
+	return nil
+}
+
+// RescheduleRecurrence aceita a alteração (o fake não guarda o calendário)
+func (f *Fake) RescheduleRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.recurrences[recurrenceID]; !ok {
+		return fmt.Errorf("recorrência %s não encontrada", recurrenceID)
+	}
+	return nil
+}
+
+// PauseRecurrence suspende a recorrência
+func (f *Fake) PauseRecurrence(ctx context.Context, recurrenceID string) error {
+	return f.setRecurrenceStatus(recurrenceID, efi.RecurrenceStatusSuspended)
+}
+
+// ResumeRecurrence reaprova a recorrência (o fake não guarda o calendário)
+func (f *Fake) ResumeRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error {
+	return f.setRecurrenceStatus(recurrenceID, efi.RecurrenceStatusApproved)
+}
+
+// RegisterWebhook não faz nada: os webhooks do fake são gerados por Pay e afins
+func (f *Fake) RegisterWebhook(ctx context.Context, pixKey string, webhookURL string) error {
+	return f.Err
+}
+
+// ValidateWebhookSignature aceita qualquer payload
+func (f *Fake) ValidateWebhookSignature(payload []byte, signature string) bool {
+	return true
+}
+
+// ParseWebhookEvent interpreta o payload como a Efí real
+func (f *Fake) ParseWebhookEvent(payload []byte) (*ports.IncomingWebhookEvent, error) {
+	return efi.ParseWebhookEvent(payload)
+}
+
+// Pay simula o pagamento da cobrança pelo pagador e devolve o webhook "pix"
+func (f *Fake) Pay(txid string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.charges[txid]
+	if !ok {
+		return nil, fmt.Errorf("cobrança %s não encontrada", txid)
+	}
+	if c.status != statusActive {
+		return nil, fmt.Errorf("cobrança %s não está ativa: %s", txid, c.status)
+	}
+	c.status = statusCompleted
+	c.endToEndID = "E" + randomID(15)
+	f.byE2E[c.endToEndID] = txid
+	return f.pixWebhook(c)
+}
+
+// Refund liquida a devolução (succeeded = DEVOLVIDO, senão NAO_REALIZADO) e
+// devolve o webhook com as devoluções do PIX
+func (f *Fake) Refund(txid, refundID string, succeeded bool) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.charges[txid]
+	if !ok {
+		return nil, fmt.Errorf("cobrança %s não encontrada", txid)
+	}
+	for i := range c.devolucoes {
+		if c.devolucoes[i].ID != refundID {
+			continue
+		}
+		if succeeded {
+			c.devolucoes[i].Status = "DEVOLVIDO"
+		} else {
+			c.devolucoes[i].Status = "NAO_REALIZADO"
+		}
+		return f.pixWebhook(c)
+	}
+	return nil, fmt.Errorf("devolução %s não encontrada no pix %s", refundID, txid)
+}
+
+// ApproveRecurrence simula a aprovação do PIX Automático no app do banco e
+// devolve o webhook "rec"
+func (f *Fake) ApproveRecurrence(recurrenceID string) ([]byte, error) {
+	if err := f.setRecurrenceStatus(recurrenceID, efi.RecurrenceStatusApproved); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rec := *f.recurrences[recurrenceID]
+	rec.Timestamp = f.clock.Now().Format(time.RFC3339)
+	return json.Marshal(efi.WebhookEvent{
+		Type:      efi.WebhookEventRecurrence,
+		Timestamp: rec.Timestamp,
+		Rec:       &rec,
+	})
+}
+
+// setRecurrenceStatus altera o status de uma recorrência existente
+func (f *Fake) setRecurrenceStatus(id string, status efi.RecurrenceStatus) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rec, ok := f.recurrences[id]
+	if !ok {
+		return fmt.Errorf("recorrência %s não encontrada", id)
+	}
+	rec.Status = status
+	return nil
+}
+
+// pixWebhook monta o webhook "pix" da cobrança (chamador segura o lock)
+func (f *Fake) pixWebhook(c *charge) ([]byte, error) {
+	now := f.clock.Now().Format(time.RFC3339)
+	return json.Marshal(efi.WebhookEvent{
+		Type:      efi.WebhookEventPix,
+		Timestamp: now,
+		Pix: []efi.PixPayment{{
+			EndToEndID:  c.endToEndID,
+			TxID:        c.req.TxID,
+			Value:       formatValor(c.req.Amount),
+			Payer:       efi.PixDevedor{Nome: c.req.PayerName},
+			PaymentTime: now,
+			Devolucoes:  append([]efi.PixDevolucao(nil), c.devolucoes...),
+		}},
+	})
+}
+
+// response monta a resposta de cobrança (chamador segura o lock)
+func (f *Fake) response(txid string) *ports.PixChargeResponse {
+	c := f.charges[txid]
+	return &ports.PixChargeResponse{
+		TxID:      txid,
+		Location:  "pix.example.com/qr/v2/" + txid,
+		PixCode:   fmt.Sprintf("00020101021226830014BR.GOV.BCB.PIX2561pix.example.com/qr/v2/%s5204000053039865802BR5909BLACKBELT6009SAO PAULO62070503***6304FAKE", txid),
+		ExpiresAt: f.clock.Now().Add(time.Duration(c.req.ExpiresIn) * time.Second).Format(time.RFC3339),
+	}
+}
+
+// formatValor converte centavos no formato de valor da Efí ("110.50")
+func formatValor(cents int64) string {
+	return fmt.Sprintf("%.2f", float64(cents)/100)
+}
+
+// randomID gera um identificador hexadecimal de n bytes
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Garante que Fake implementa ports.PixProvider
+var _ ports.PixProvider = (*Fake)(nil)
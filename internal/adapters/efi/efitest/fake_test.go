@@ -0,0 +1,54 @@
+package efitest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+func TestFake_PayAndRefund(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	charge, err := fake.CreatePixCharge(ctx, &ports.PixChargeRequest{Amount: 19900, ExpiresIn: 3600})
+	if err != nil {
+		t.Fatalf("CreatePixCharge: %v", err)
+	}
+	if charge.TxID == "" || charge.PixCode == "" {
+		t.Fatalf("cobrança sem txid ou copia e cola: %+v", charge)
+	}
+
+	payload, err := fake.Pay(charge.TxID)
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	event, err := fake.ParseWebhookEvent(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent: %v", err)
+	}
+	if event.EventType != string(efi.WebhookEventPix) || event.EventID == "" {
+		t.Errorf("evento = %s/%q, want pix com e2eid", event.EventType, event.EventID)
+	}
+	if _, err := fake.Pay(charge.TxID); err == nil {
+		t.Error("pagar duas vezes a mesma cobrança deveria falhar")
+	}
+
+	result, err := fake.RefundPix(ctx, &ports.PixRefundRequest{EndToEndID: event.EventID, RefundID: "r1", Amount: 5000})
+	if err != nil || result.Status != domain.RefundStatusPending {
+		t.Fatalf("RefundPix = %+v, %v; want pending", result, err)
+	}
+	payload, err = fake.Refund(charge.TxID, "r1", true)
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	notifications, err := efi.ParseRefundNotifications(payload)
+	if err != nil || len(notifications) != 1 {
+		t.Fatalf("ParseRefundNotifications = %v, %v; want 1", notifications, err)
+	}
+	if n := notifications[0]; n.Amount != 5000 || n.Status != domain.RefundStatusSucceeded {
+		t.Errorf("devolução = %+v, want 5000 succeeded", n)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/ports"
 )
 
 // Códigos de erro comuns da API Efí
@@ -50,6 +53,16 @@ var (
 	ErrServerError = errors.New("efi: erro do servidor")
 )
 
+// Is permite errors.Is(err, ports.ErrGatewayRejected) para recusas definitivas:
+// 4xx, exceto timeout (408) e conflito (409), em que a Efí pode ter processado a requisição
+func (e *APIError) Is(target error) bool {
+	if target != ports.ErrGatewayRejected {
+		return false
+	}
+	return e.Status >= 400 && e.Status < 500 &&
+		e.Status != http.StatusRequestTimeout && e.Status != http.StatusConflict
+}
+
 // IsNotFound retorna true se o erro indica que o recurso não foi encontrado
 func IsNotFound(err error) bool {
 	if errors.Is(err, ErrNotFound) {
@@ -178,6 +191,33 @@ func (e *RecurrenceStatusError) IsExpired() bool {
 	return e.Status == RecurrenceStatusExpired
 }
 
+// CircuitOpenError indica que o circuit breaker da família de endpoints está aberto.
+// Envolve ports.ErrGatewayUnavailable para que a camada de serviço entre em modo degradado.
+type CircuitOpenError struct {
+	Family  EndpointFamily
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("efi: circuito '%s' aberto, tente novamente em %s", e.Family, e.RetryIn.Round(time.Second))
+}
+
+// Unwrap permite errors.Is(err, ports.ErrGatewayUnavailable)
+func (e *CircuitOpenError) Unwrap() error {
+	return ports.ErrGatewayUnavailable
+}
+
+// RetryAfter implementa ports.RetryAfterError
+func (e *CircuitOpenError) RetryAfter() time.Duration {
+	return e.RetryIn
+}
+
+// IsCircuitOpen retorna true se o erro indica circuit breaker aberto
+func IsCircuitOpen(err error) bool {
+	var circuitErr *CircuitOpenError
+	return errors.As(err, &circuitErr)
+}
+
 // ValidationError representa um erro de validação com detalhes do campo
 type ValidationError struct {
 	Field   string
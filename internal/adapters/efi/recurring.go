@@ -30,11 +30,15 @@ func (c *Client) CreateRecurrence(ctx context.Context, req CreateRecurrenceReque
 		"devedor":       buildDebtorPayload(req.Debtor),
 		"objeto":        req.Object,
 		"dataInicial":   req.StartDate,
-		"dataFinal":     req.EndDate,
 		"periodicidade": req.Periodicity,
 		"valorRec":      req.Amount,
 	}
 
+	// Sem data final a recorrência vale por tempo indeterminado
+	if req.EndDate != "" {
+		payload["dataFinal"] = req.EndDate
+	}
+
 	if req.Description != "" {
 		payload["descricao"] = req.Description
 	}
@@ -108,6 +112,9 @@ func (c *Client) UpdateRecurrence(ctx context.Context, idRec string, req UpdateR
 	if req.EndDate != "" {
 		payload["dataFinal"] = req.EndDate
 	}
+	if req.NextDueDate != "" {
+		payload["proximoVencimento"] = req.NextDueDate
+	}
 	if req.Status != "" {
 		payload["status"] = req.Status
 	}
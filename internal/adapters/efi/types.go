@@ -92,18 +92,33 @@ type PixRecebido struct {
 	InfoPagador string `json:"infoPagador,omitempty"`
 }
 
+// Status de uma devolução de PIX
+const (
+	DevolucaoEmProcessamento = "EM_PROCESSAMENTO"
+	DevolucaoDevolvido       = "DEVOLVIDO"
+	DevolucaoNaoRealizado    = "NAO_REALIZADO"
+)
+
 // PixDevolucao representa uma devolução de PIX
 type PixDevolucao struct {
-	ID     string `json:"id"`
-	RTrId  string `json:"rtrId"` // ID de retorno
-	Valor  string `json:"valor"`
-	Status string `json:"status"` // EM_PROCESSAMENTO, DEVOLVIDO, NAO_REALIZADO
-	Motivo string `json:"motivo,omitempty"`
+	ID      string              `json:"id"`
+	RTrId   string              `json:"rtrId"` // ID de retorno
+	Valor   string              `json:"valor"`
+	Status  string              `json:"status"` // EM_PROCESSAMENTO, DEVOLVIDO, NAO_REALIZADO
+	Motivo  string              `json:"motivo,omitempty"`
+	Horario PixDevolucaoHorario `json:"horario"`
+}
+
+// PixDevolucaoHorario contém os horários de solicitação e liquidação da devolução
+type PixDevolucaoHorario struct {
+	Solicitacao string `json:"solicitacao,omitempty"`
+	Liquidacao  string `json:"liquidacao,omitempty"`
 }
 
 // PixDevolucaoRequest representa a requisição de devolução
 type PixDevolucaoRequest struct {
-	Valor string `json:"valor"` // Valor a devolver
+	Valor     string `json:"valor"`               // Valor a devolver
+	Descricao string `json:"descricao,omitempty"` // Mensagem ao pagador
 }
 
 // APIError representa um erro retornado pela API Efí
@@ -162,6 +177,7 @@ type RecurrenceStatus string
 const (
 	RecurrenceStatusCreated   RecurrenceStatus = "CRIADA"
 	RecurrenceStatusApproved  RecurrenceStatus = "APROVADA"
+	RecurrenceStatusSuspended RecurrenceStatus = "SUSPENSA"
 	RecurrenceStatusRejected  RecurrenceStatus = "REJEITADA"
 	RecurrenceStatusCancelled RecurrenceStatus = "CANCELADA"
 	RecurrenceStatusExpired   RecurrenceStatus = "EXPIRADA"
@@ -182,9 +198,10 @@ type CreateRecurrenceRequest struct {
 
 // UpdateRecurrenceRequest é a requisição para atualizar uma recorrência
 type UpdateRecurrenceRequest struct {
-	Amount  string `json:"valorRec,omitempty"`
-	EndDate string `json:"dataFinal,omitempty"`
-	Status  string `json:"status,omitempty"`
+	Amount      string `json:"valorRec,omitempty"`
+	EndDate     string `json:"dataFinal,omitempty"`
+	NextDueDate string `json:"proximoVencimento,omitempty"` // YYYY-MM-DD
+	Status      string `json:"status,omitempty"`
 }
 
 // Recurrence representa uma autorização de recorrência PIX
@@ -307,6 +324,8 @@ type WebhookEventType string
 const (
 	WebhookEventPix          WebhookEventType = "pix"
 	WebhookEventRecurrence   WebhookEventType = "rec"
+	WebhookEventDevolucao    WebhookEventType = "devolucao"
+	WebhookEventCobr         WebhookEventType = "cobr"
 	WebhookEventRecApproved  WebhookEventType = "rec_aprovada"
 	WebhookEventRecRejected  WebhookEventType = "rec_rejeitada"
 	WebhookEventRecCancelled WebhookEventType = "rec_cancelada"
@@ -314,20 +333,22 @@ const (
 
 // PixPayment representa um pagamento PIX para webhook
 type PixPayment struct {
-	EndToEndID   string     `json:"endToEndId"`
-	TxID         string     `json:"txid"`
-	Value        string     `json:"valor"`
-	Payer        PixDevedor `json:"pagador"`
-	PaymentTime  string     `json:"horario"`
-	Info         string     `json:"infoPagador,omitempty"`
-	RecurrenceID string     `json:"idRec,omitempty"` // Se veio de recorrência
+	EndToEndID   string         `json:"endToEndId"`
+	TxID         string         `json:"txid"`
+	Value        string         `json:"valor"`
+	Payer        PixDevedor     `json:"pagador"`
+	PaymentTime  string         `json:"horario"`
+	Info         string         `json:"infoPagador,omitempty"`
+	RecurrenceID string         `json:"idRec,omitempty"`      // Se veio de recorrência
+	Devolucoes   []PixDevolucao `json:"devolucoes,omitempty"` // Presente quando o webhook notifica devoluções
 }
 
 // WebhookEvent representa o payload recebido em um webhook da Efí
 // (compatível com a estrutura oficial)
 type WebhookEvent struct {
-	Type      WebhookEventType `json:"tipo"`
-	Timestamp string           `json:"timestamp"`
-	Pix       []PixPayment     `json:"pix,omitempty"`
-	Rec       *RecurrenceEvent `json:"rec,omitempty"`
+	Type      WebhookEventType       `json:"tipo"`
+	Timestamp string                 `json:"timestamp"`
+	Pix       []PixPayment           `json:"pix,omitempty"`
+	Rec       *RecurrenceEvent       `json:"rec,omitempty"`
+	Cobr      []RecurringChargeEvent `json:"cobr,omitempty"` // Cobranças do PIX Automático
 }
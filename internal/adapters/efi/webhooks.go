@@ -6,13 +6,21 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
 )
 
-// WebhookHandler processa webhooks recebidos da Efí Bank
+// WebhookHandler processa webhooks recebidos da Efí Bank.
+// Com Inbox configurado o webhook só é gravado no inbox antes do 200, e os
+// callbacks rodam depois, quando o inbox chama Process; sem Inbox eles rodam
+// durante o request e uma falha se perde.
 type WebhookHandler struct {
 	// OnPixPayment é chamado quando um pagamento PIX é recebido
 	OnPixPayment func(ctx context.Context, pix PixPayment) error
@@ -20,6 +28,9 @@ type WebhookHandler struct {
 	// OnRecurrenceUpdate é chamado quando o status de uma recorrência muda
 	OnRecurrenceUpdate func(ctx context.Context, event RecurrenceEvent) error
 
+	// OnRefund é chamado para cada devolução notificada junto de um PIX
+	OnRefund func(ctx context.Context, refund ports.RefundNotification) error
+
 	// OnError é chamado quando ocorre um erro durante o processamento
 	OnError func(ctx context.Context, err error)
 
@@ -28,6 +39,9 @@ type WebhookHandler struct {
 
 	// SkipSignatureValidation desabilita validação de assinatura (apenas para testes)
 	SkipSignatureValidation bool
+
+	// Inbox grava o webhook para processamento assíncrono (opcional)
+	Inbox ports.WebhookInbox
 }
 
 // NewWebhookHandler cria um novo handler de webhook
@@ -66,6 +80,24 @@ func (h *WebhookHandler) HandleEfiWebhook(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// Com inbox: grava e confirma; o processamento vem depois, via Process
+	if h.Inbox != nil {
+		incoming, err := ParseWebhookEvent(body)
+		if err != nil {
+			http.Error(w, "Invalid webhook", http.StatusBadRequest)
+			return
+		}
+		if _, err := h.Inbox.Receive(ctx, incoming); err != nil {
+			log.Printf("Erro ao armazenar webhook: %v", err)
+			// Sem 200 a Efí reenvia o webhook
+			http.Error(w, "Failed to store webhook", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"ok"}`))
+		return
+	}
+
 	// Parse do evento
 	var event WebhookEvent
 	if err := json.Unmarshal(body, &event); err != nil {
@@ -86,13 +118,28 @@ func (h *WebhookHandler) HandleEfiWebhook(w http.ResponseWriter, r *http.Request
 	w.Write([]byte(`{"status":"ok"}`))
 }
 
+// Process aplica aos callbacks um webhook gravado pelo Inbox
+// (registre-o no router do inbox para os tipos pix, rec e devolucao)
+func (h *WebhookHandler) Process(ctx context.Context, stored *domain.WebhookEvent) error {
+	var event WebhookEvent
+	if err := json.Unmarshal(stored.Payload, &event); err != nil {
+		return fmt.Errorf("erro ao decodificar webhook %s: %w", stored.EventID, err)
+	}
+	return h.processEvent(ctx, event)
+}
+
 // validateSignature valida a assinatura do webhook usando HMAC-SHA256
 func (h *WebhookHandler) validateSignature(body []byte, signature string) bool {
+	return validHMACSignature(h.WebhookSecret, body, signature)
+}
+
+// validHMACSignature compara a assinatura recebida com o HMAC-SHA256 (hex) do body
+func validHMACSignature(secret string, body []byte, signature string) bool {
 	if signature == "" {
 		return false
 	}
 
-	mac := hmac.New(sha256.New, []byte(h.WebhookSecret))
+	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write(body)
 	expectedSig := hex.EncodeToString(mac.Sum(nil))
 
@@ -101,8 +148,14 @@ func (h *WebhookHandler) validateSignature(body []byte, signature string) bool {
 
 // processEvent roteia o evento para o handler apropriado
 func (h *WebhookHandler) processEvent(ctx context.Context, event WebhookEvent) error {
-	// Processa pagamentos PIX
+	// Processa pagamentos PIX (ou as devoluções deles)
 	for _, pix := range event.Pix {
+		if len(pix.Devolucoes) > 0 {
+			if err := h.ProcessRefunds(ctx, pix); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := h.ProcessPixPayment(ctx, pix); err != nil {
 			return err
 		}
@@ -129,6 +182,22 @@ func (h *WebhookHandler) ProcessPixPayment(ctx context.Context, pix PixPayment)
 	return h.OnPixPayment(ctx, pix)
 }
 
+// ProcessRefunds processa as devoluções notificadas junto de um PIX
+func (h *WebhookHandler) ProcessRefunds(ctx context.Context, pix PixPayment) error {
+	log.Printf("Devoluções do PIX: e2e=%s txid=%s quantidade=%d", pix.EndToEndID, pix.TxID, len(pix.Devolucoes))
+
+	if h.OnRefund == nil {
+		return nil
+	}
+
+	for _, refund := range refundNotifications(pix) {
+		if err := h.OnRefund(ctx, refund); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ProcessRecurrenceUpdate processa uma notificação de mudança de status de recorrência
 func (h *WebhookHandler) ProcessRecurrenceUpdate(ctx context.Context, event RecurrenceEvent) error {
 	log.Printf("Recorrência atualizada: id=%s status=%s", event.ID, event.Status)
@@ -140,6 +209,135 @@ func (h *WebhookHandler) ProcessRecurrenceUpdate(ctx context.Context, event Recu
 	return h.OnRecurrenceUpdate(ctx, event)
 }
 
+// Status das cobranças do PIX Automático (webhook cobr)
+const (
+	CobrStatusConcluida    = "CONCLUIDA"
+	CobrStatusRejeitada    = "REJEITADA"
+	CobrStatusNaoRealizada = "NAO_REALIZADA"
+	CobrStatusExpirada     = "EXPIRADA"
+)
+
+// RecurringChargeEvent é a notificação de uma cobrança do PIX Automático.
+// A liquidação chega também como webhook pix (com idRec); a cobr interessa
+// quando a cobrança não foi realizada.
+type RecurringChargeEvent struct {
+	TxID         string `json:"txid"`
+	RecurrenceID string `json:"idRec"`
+	Status       string `json:"status"`
+	Valor        struct {
+		Original string `json:"original"`
+	} `json:"valor"`
+	Rejeicao *struct {
+		Codigo    string `json:"codigo"`
+		Descricao string `json:"descricao"`
+	} `json:"rejeicao,omitempty"`
+}
+
+// ParseChargeNotifications extrai o resultado das cobranças de um webhook da
+// Efí: cada PIX recebido (sem devoluções) é uma cobrança paga e cada cobr
+// rejeitada, não realizada ou expirada é uma falha
+func ParseChargeNotifications(payload []byte) ([]ports.ChargeNotification, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar webhook: %w", err)
+	}
+
+	var charges []ports.ChargeNotification
+	for _, pix := range event.Pix {
+		if len(pix.Devolucoes) > 0 || pix.TxID == "" {
+			continue
+		}
+		charges = append(charges, ports.ChargeNotification{
+			Gateway:          domain.PaymentGatewayPixAuto,
+			SubscriptionRef:  pix.RecurrenceID,
+			GatewayPaymentID: pix.TxID,
+			EndToEndID:       pix.EndToEndID,
+			Amount:           parseValor(pix.Value),
+			Paid:             true,
+		})
+	}
+	for _, cobr := range event.Cobr {
+		switch cobr.Status {
+		case CobrStatusRejeitada, CobrStatusNaoRealizada, CobrStatusExpirada:
+		default:
+			continue // Em andamento ou concluída: a liquidação vem pelo webhook pix
+		}
+		charge := ports.ChargeNotification{
+			Gateway:          domain.PaymentGatewayPixAuto,
+			SubscriptionRef:  cobr.RecurrenceID,
+			GatewayPaymentID: cobr.TxID,
+			Amount:           parseValor(cobr.Valor.Original),
+			FailureReason:    "cobrança " + strings.ToLower(cobr.Status),
+		}
+		if cobr.Rejeicao != nil {
+			charge.FailureCode = cobr.Rejeicao.Codigo
+			if cobr.Rejeicao.Descricao != "" {
+				charge.FailureReason = cobr.Rejeicao.Descricao
+			}
+		}
+		charges = append(charges, charge)
+	}
+	return charges, nil
+}
+
+// ParseRefundNotifications extrai as devoluções de um webhook PIX da Efí.
+// Um webhook sem devoluções resulta em lista vazia.
+func ParseRefundNotifications(payload []byte) ([]ports.RefundNotification, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar webhook: %w", err)
+	}
+
+	var refunds []ports.RefundNotification
+	for _, pix := range event.Pix {
+		refunds = append(refunds, refundNotifications(pix)...)
+	}
+	return refunds, nil
+}
+
+// refundNotifications converte as devoluções de um PIX para o formato das portas
+func refundNotifications(pix PixPayment) []ports.RefundNotification {
+	refunds := make([]ports.RefundNotification, 0, len(pix.Devolucoes))
+	for _, dev := range pix.Devolucoes {
+		refunds = append(refunds, ports.RefundNotification{
+			GatewayPaymentID: pix.TxID,
+			RefundID:         dev.ID,
+			GatewayRefundID:  dev.RTrId,
+			Amount:           parseValor(dev.Valor),
+			Status:           refundStatus(dev.Status),
+			FailureReason:    dev.Motivo,
+		})
+	}
+	return refunds
+}
+
+// refundStatus traduz o status de devolução da Efí
+func refundStatus(status string) domain.RefundStatus {
+	switch status {
+	case DevolucaoDevolvido:
+		return domain.RefundStatusSucceeded
+	case DevolucaoNaoRealizado:
+		return domain.RefundStatusFailed
+	default:
+		return domain.RefundStatusPending
+	}
+}
+
+// parseValor converte um valor da Efí ("110.50") em centavos; inválido vira 0
+func parseValor(valor string) int {
+	reais, centavos, _ := strings.Cut(valor, ".")
+	r, err := strconv.Atoi(reais)
+	if err != nil {
+		return 0
+	}
+	centavos = (centavos + "00")[:2]
+	c, err := strconv.Atoi(centavos)
+	if err != nil {
+		return 0
+	}
+	return r*100 + c
+}
+
 // WebhookConfig contém configuração de webhook registrado
 type WebhookConfig struct {
 	URL      string `json:"webhookUrl"`
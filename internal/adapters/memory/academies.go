@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// AcademyRepository implementa ports.AcademyService (e os dados de branding e
+// fiscais derivados da academia) em memória (thread-safe)
+type AcademyRepository struct {
+	mu        sync.RWMutex
+	academies map[string]*domain.Academy
+	profiles  *ProfileRepository
+}
+
+// NewAcademyRepository cria um repositório vazio. Com profiles, Create
+// vincula o perfil do dono à academia; com nil o vínculo fica só em OwnerID.
+func NewAcademyRepository(profiles *ProfileRepository) *AcademyRepository {
+	return &AcademyRepository{academies: make(map[string]*domain.Academy), profiles: profiles}
+}
+
+// Create grava uma cópia da academia; slug e código de convite são únicos
+func (r *AcademyRepository) Create(ctx context.Context, academy *domain.Academy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.academies {
+		if existing.Slug == academy.Slug || existing.InviteCode == academy.InviteCode {
+			return fmt.Errorf("academia %s: %w", academy.Slug, domain.ErrAlreadyExists)
+		}
+	}
+	id := newID()
+	if r.profiles != nil {
+		if err := r.profiles.linkAcademy(academy.OwnerID, id); err != nil {
+			return fmt.Errorf("perfil %s: %w", academy.OwnerID, err)
+		}
+	}
+	academy.ID = id
+	stored := *academy
+	r.academies[academy.ID] = &stored
+	return nil
+}
+
+// GetByID busca academia pelo ID
+func (r *AcademyRepository) GetByID(ctx context.Context, academyID string) (*domain.Academy, error) {
+	return r.find(func(a *domain.Academy) bool { return a.ID == academyID })
+}
+
+// GetBySlug busca academia pelo slug
+func (r *AcademyRepository) GetBySlug(ctx context.Context, slug string) (*domain.Academy, error) {
+	return r.find(func(a *domain.Academy) bool { return a.Slug == slug })
+}
+
+// GetByInviteCode busca academia pelo código de convite
+func (r *AcademyRepository) GetByInviteCode(ctx context.Context, inviteCode string) (*domain.Academy, error) {
+	return r.find(func(a *domain.Academy) bool { return a.InviteCode == inviteCode })
+}
+
+// GetBranding retorna nome, logo e cor da academia
+func (r *AcademyRepository) GetBranding(ctx context.Context, academyID string) (*domain.Branding, error) {
+	academy, err := r.GetByID(ctx, academyID)
+	if err != nil {
+		return nil, err
+	}
+	return academy.Branding(), nil
+}
+
+// GetFiscalParty retorna a academia como tomadora da NFS-e
+func (r *AcademyRepository) GetFiscalParty(ctx context.Context, academyID string) (*domain.FiscalParty, error) {
+	academy, err := r.GetByID(ctx, academyID)
+	if err != nil {
+		return nil, err
+	}
+	return academy.FiscalParty()
+}
+
+// find retorna uma cópia da primeira academia que satisfaz match
+func (r *AcademyRepository) find(match func(*domain.Academy) bool) (*domain.Academy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, academy := range r.academies {
+		if match(academy) {
+			copied := *academy
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// Garante que AcademyRepository implementa as interfaces
+var (
+	_ ports.AcademyService    = (*AcademyRepository)(nil)
+	_ ports.BrandingService   = (*AcademyRepository)(nil)
+	_ ports.FiscalDataService = (*AcademyRepository)(nil)
+)
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// AuditLog implementa ports.AuditLog em memória (thread-safe)
+type AuditLog struct {
+	mu      sync.Mutex
+	entries map[string][]*domain.AuditEntry // Por academia, em ordem de Seq
+}
+
+// NewAuditLog cria um audit log vazio
+func NewAuditLog() *AuditLog {
+	return &AuditLog{entries: make(map[string][]*domain.AuditEntry)}
+}
+
+// Append encadeia o registro após o último da academia e guarda uma cópia
+func (l *AuditLog) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var prev *domain.AuditEntry
+	if chain := l.entries[entry.AcademyID]; len(chain) > 0 {
+		prev = chain[len(chain)-1]
+	}
+	if entry.ID == "" {
+		entry.ID = newID()
+	}
+	entry.Chain(prev)
+
+	stored := *entry
+	l.entries[entry.AcademyID] = append(l.entries[entry.AcademyID], &stored)
+	return nil
+}
+
+// List lista cópias dos registros da academia com Seq > afterSeq
+func (l *AuditLog) List(ctx context.Context, academyID string, afterSeq int64, limit int) ([]*domain.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var result []*domain.AuditEntry
+	for _, entry := range l.entries[academyID] {
+		if entry.Seq <= afterSeq {
+			continue
+		}
+		copied := *entry
+		result = append(result, &copied)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Garante que AuditLog implementa ports.AuditLog
+var _ ports.AuditLog = (*AuditLog)(nil)
@@ -0,0 +1,101 @@
+// Package memory implementa os ports da aplicação em memória,
+// para desenvolvimento local e testes sem dependências externas.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// ChargeQueue implementa ports.ChargeQueue em memória (thread-safe)
+type ChargeQueue struct {
+	mu      sync.Mutex
+	charges map[string]*ports.PendingCharge
+}
+
+// NewChargeQueue cria uma fila de cobranças adiadas vazia
+func NewChargeQueue() *ChargeQueue {
+	return &ChargeQueue{charges: make(map[string]*ports.PendingCharge)}
+}
+
+// Enqueue adiciona uma cobrança à fila
+func (q *ChargeQueue) Enqueue(ctx context.Context, charge *ports.PendingCharge) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if charge.ID == "" {
+		charge.ID = newID()
+	}
+	stored := *charge
+	q.charges[charge.ID] = &stored
+	return nil
+}
+
+// Get busca uma cobrança adiada pelo ID
+func (q *ChargeQueue) Get(ctx context.Context, id string) (*ports.PendingCharge, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	charge, ok := q.charges[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	result := *charge
+	return &result, nil
+}
+
+// ListDue lista cobranças enfileiradas com tentativa vencida, das mais antigas para as mais novas
+func (q *ChargeQueue) ListDue(ctx context.Context, now time.Time, limit int) ([]*ports.PendingCharge, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var due []*ports.PendingCharge
+	for _, charge := range q.charges {
+		if charge.Status == ports.PendingChargeStatusQueued && !charge.NextAttemptAt.After(now) {
+			result := *charge
+			due = append(due, &result)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].EnqueuedAt.Before(due[j].EnqueuedAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// Update persiste alterações de uma cobrança existente
+func (q *ChargeQueue) Update(ctx context.Context, charge *ports.PendingCharge) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.charges[charge.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	stored := *charge
+	q.charges[charge.ID] = &stored
+	return nil
+}
+
+// newID gera um identificador aleatório no formato UUID v4
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// Garante que ChargeQueue implementa ports.ChargeQueue
+var _ ports.ChargeQueue = (*ChargeQueue)(nil)
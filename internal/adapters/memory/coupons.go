@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// CouponRepository implementa ports.CouponService em memória (thread-safe)
+type CouponRepository struct {
+	mu      sync.Mutex
+	coupons map[string]*domain.Coupon // por ID
+}
+
+// NewCouponRepository cria o repositório com os cupons informados
+func NewCouponRepository(coupons ...*domain.Coupon) *CouponRepository {
+	r := &CouponRepository{coupons: make(map[string]*domain.Coupon)}
+	for _, c := range coupons {
+		_ = r.Create(context.Background(), c)
+	}
+	return r
+}
+
+// Create cria um cupom (gera o ID se vazio)
+func (r *CouponRepository) Create(ctx context.Context, c *domain.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.coupons {
+		if existing.Code == c.Code {
+			return fmt.Errorf("cupom %s: %w", c.Code, domain.ErrAlreadyExists)
+		}
+	}
+	if c.ID == "" {
+		c.ID = newID()
+	}
+	r.coupons[c.ID] = copyCoupon(c)
+	return nil
+}
+
+// GetByCode busca cupom pelo código (já normalizado)
+func (r *CouponRepository) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.coupons {
+		if c.Code == code {
+			return copyCoupon(c), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// Redeem incrementa TimesRedeemed enquanto houver resgates disponíveis
+func (r *CouponRepository) Redeem(ctx context.Context, couponID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.coupons[couponID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if c.MaxRedemptions != nil && c.TimesRedeemed >= *c.MaxRedemptions {
+		return fmt.Errorf("%w: cupom %s esgotado", domain.ErrCouponInvalid, c.Code)
+	}
+	c.TimesRedeemed++
+	return nil
+}
+
+// Release devolve um resgate de Redeem
+func (r *CouponRepository) Release(ctx context.Context, couponID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.coupons[couponID]
+	if !ok || c.TimesRedeemed == 0 {
+		return domain.ErrNotFound
+	}
+	c.TimesRedeemed--
+	return nil
+}
+
+// copyCoupon copia o cupom e suas listas
+func copyCoupon(c *domain.Coupon) *domain.Coupon {
+	cp := *c
+	cp.PlanIDs = append([]string(nil), c.PlanIDs...)
+	return &cp
+}
+
+// Garante que CouponRepository implementa ports.CouponService
+var _ ports.CouponService = (*CouponRepository)(nil)
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// InvoiceRepository implementa ports.InvoiceService em memória (thread-safe)
+type InvoiceRepository struct {
+	mu       sync.Mutex
+	invoices map[string]*domain.Invoice // por ID
+	counters map[string]int64           // último número por academia
+}
+
+// NewInvoiceRepository cria o repositório de faturas
+func NewInvoiceRepository() *InvoiceRepository {
+	return &InvoiceRepository{
+		invoices: make(map[string]*domain.Invoice),
+		counters: make(map[string]int64),
+	}
+}
+
+// Create atribui o próximo número da academia e grava a fatura sob o mesmo
+// lock: uma fatura repetida do pagamento não consome número
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.invoices {
+		if existing.PaymentID == invoice.PaymentID {
+			return fmt.Errorf("fatura do pagamento %s: %w", invoice.PaymentID, domain.ErrAlreadyExists)
+		}
+	}
+	r.counters[invoice.AcademyID]++
+	invoice.Number = r.counters[invoice.AcademyID]
+	if invoice.ID == "" {
+		invoice.ID = newID()
+	}
+	r.invoices[invoice.ID] = copyInvoice(invoice)
+	return nil
+}
+
+// GetByID obtém uma fatura pelo ID
+func (r *InvoiceRepository) GetByID(ctx context.Context, invoiceID string) (*domain.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoice, ok := r.invoices[invoiceID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return copyInvoice(invoice), nil
+}
+
+// GetByPaymentID obtém a fatura de um pagamento
+func (r *InvoiceRepository) GetByPaymentID(ctx context.Context, paymentID string) (*domain.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, invoice := range r.invoices {
+		if invoice.PaymentID == paymentID {
+			return copyInvoice(invoice), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// ListByAcademy lista as faturas da academia, da mais recente para a mais antiga
+func (r *InvoiceRepository) ListByAcademy(ctx context.Context, academyID string) ([]*domain.Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoices := []*domain.Invoice{}
+	for _, invoice := range r.invoices {
+		if invoice.AcademyID == academyID {
+			invoices = append(invoices, copyInvoice(invoice))
+		}
+	}
+	sort.Slice(invoices, func(i, j int) bool { return invoices[i].Number > invoices[j].Number })
+	return invoices, nil
+}
+
+// Save grava o status da fatura (número e itens não mudam depois de emitida)
+func (r *InvoiceRepository) Save(ctx context.Context, invoice *domain.Invoice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.invoices[invoice.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.Status = invoice.Status
+	return nil
+}
+
+// copyInvoice copia a fatura e seus itens
+func copyInvoice(invoice *domain.Invoice) *domain.Invoice {
+	cp := *invoice
+	cp.Lines = append([]domain.InvoiceLine(nil), invoice.Lines...)
+	return &cp
+}
+
+// Garante que InvoiceRepository implementa ports.InvoiceService
+var _ ports.InvoiceService = (*InvoiceRepository)(nil)
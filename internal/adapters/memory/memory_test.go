@@ -0,0 +1,340 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+func TestPlanRepository_DefaultPlans(t *testing.T) {
+	ctx := context.Background()
+	plans := NewPlanRepository(DefaultPlans(time.Now())...)
+
+	active, err := plans.ListActive(ctx)
+	if err != nil {
+		t.Fatalf("ListActive: %v", err)
+	}
+	var slugs []string
+	for _, p := range active {
+		slugs = append(slugs, p.Slug)
+	}
+	if fmt.Sprint(slugs) != "[starter pro business]" {
+		t.Errorf("planos = %v, want [starter pro business] (por preço)", slugs)
+	}
+
+	pro, err := plans.GetBySlug(ctx, "pro")
+	if err != nil {
+		t.Fatalf("GetBySlug: %v", err)
+	}
+	pro.PriceMonthly = 1
+	if again, _ := plans.GetByID(ctx, pro.ID); again.PriceMonthly == 1 {
+		t.Error("alteração no plano devolvido vazou para o repositório")
+	}
+	if _, err := plans.GetBySlug(ctx, "enterprise"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetBySlug inexistente = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSubscriptionRepository(t *testing.T) {
+	ctx := context.Background()
+	clock := domain.NewFakeClock(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	outbox := NewOutbox()
+	plans := NewPlanRepository(DefaultPlans(clock.Now())...)
+	subs := NewSubscriptionRepository(plans, outbox)
+	subs.SetClock(clock)
+
+	pro, _ := plans.GetBySlug(ctx, "pro")
+	sub, err := subs.CreateTrial(ctx, "academy-1", pro.ID)
+	if err != nil {
+		t.Fatalf("CreateTrial: %v", err)
+	}
+	if sub.ID == "" || sub.Status != domain.SubscriptionStatusTrialing {
+		t.Fatalf("assinatura = %+v, want trialing com ID", sub)
+	}
+	if len(outbox.Messages()) == 0 {
+		t.Error("CreateTrial não gravou eventos no outbox")
+	}
+
+	if _, err := subs.CreateTrial(ctx, "academy-1", pro.ID); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("segunda assinatura da academia = %v, want ErrAlreadyExists", err)
+	}
+
+	// Alterações só valem depois de Save
+	sub.Status = domain.SubscriptionStatusCanceled
+	got, err := subs.GetByAcademy(ctx, "academy-1")
+	if err != nil {
+		t.Fatalf("GetByAcademy: %v", err)
+	}
+	if got.Status != domain.SubscriptionStatusTrialing {
+		t.Errorf("status = %s, want trialing (cópia alterada sem Save)", got.Status)
+	}
+
+	clock.Advance(time.Duration(domain.DefaultTrialDays+1) * 24 * time.Hour)
+	expired, err := subs.ExpireTrials(ctx)
+	if err != nil || expired != 1 {
+		t.Fatalf("ExpireTrials = %d, %v; want 1", expired, err)
+	}
+	if got, _ := subs.GetByID(ctx, sub.ID); got.Status != domain.SubscriptionStatusExpired {
+		t.Errorf("status = %s, want expired", got.Status)
+	}
+}
+
+func TestSubscriptionRepository_ConcurrentCreate(t *testing.T) {
+	ctx := context.Background()
+	plans := NewPlanRepository(DefaultPlans(time.Now())...)
+	subs := NewSubscriptionRepository(plans, NewOutbox())
+	pro, _ := plans.GetBySlug(ctx, "pro")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := subs.CreateTrial(ctx, "academy-1", pro.ID); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("%d assinaturas criadas para a mesma academia, want 1", created)
+	}
+}
+
+func TestSubscriptionRepository_StaleSave(t *testing.T) {
+	ctx := context.Background()
+	plans := NewPlanRepository(DefaultPlans(time.Now())...)
+	subs := NewSubscriptionRepository(plans, NewOutbox())
+	pro, _ := plans.GetBySlug(ctx, "pro")
+
+	created, err := subs.CreateTrial(ctx, "academy-1", pro.ID)
+	if err != nil || created.Version != 1 {
+		t.Fatalf("CreateTrial = v%d, %v; want v1", created.Version, err)
+	}
+
+	// Duas leituras da mesma versão: a segunda gravação perde
+	first, _ := subs.GetByID(ctx, created.ID)
+	second, _ := subs.GetByID(ctx, created.ID)
+	if err := subs.Save(ctx, first); err != nil || first.Version != 2 {
+		t.Fatalf("Save = v%d, %v; want v2", first.Version, err)
+	}
+	if err := subs.Save(ctx, second); !errors.Is(err, domain.ErrConcurrentModification) {
+		t.Errorf("Save desatualizado = %v, want ErrConcurrentModification", err)
+	}
+
+	// A cópia gravada continua utilizável em gravações seguidas
+	if err := subs.Save(ctx, first); err != nil || first.Version != 3 {
+		t.Errorf("segundo Save = v%d, %v; want v3", first.Version, err)
+	}
+}
+
+func TestPaymentRepository_ListByAcademy(t *testing.T) {
+	ctx := context.Background()
+	payments := NewPaymentRepository(NewOutbox())
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		p := domain.NewPaymentHistory("sub-1", "academy-1", 19900, domain.PaymentGatewayPixAuto, base.AddDate(0, i, 0))
+		if err := payments.RecordPayment(ctx, p); err != nil {
+			t.Fatalf("RecordPayment: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		limit, offset int
+		want          []time.Month
+	}{
+		{"todos", 0, 0, []time.Month{3, 2, 1}},
+		{"limit", 2, 0, []time.Month{3, 2}},
+		{"offset", 2, 2, []time.Month{1}},
+		{"além do fim", 2, 5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := payments.ListByAcademy(ctx, "academy-1", tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("ListByAcademy: %v", err)
+			}
+			var months []time.Month
+			for _, p := range list {
+				months = append(months, p.CreatedAt.Month())
+			}
+			if fmt.Sprint(months) != fmt.Sprint(tt.want) {
+				t.Errorf("meses = %v, want %v", months, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookRepository_StoreAndProcess(t *testing.T) {
+	ctx := context.Background()
+	clock := domain.NewFakeClock(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	calls := 0
+	webhooks := NewWebhookRepository(func(ctx context.Context, event *domain.WebhookEvent) error {
+		calls++
+		if calls == 1 {
+			return errors.New("falha temporária")
+		}
+		return nil
+	})
+	webhooks.SetClock(clock)
+
+	event := domain.NewWebhookEvent("pix_auto", "E123", "pix", []byte(`{}`), nil, clock.Now())
+	if err := webhooks.Store(ctx, event); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	duplicate := domain.NewWebhookEvent("pix_auto", "E123", "pix", []byte(`{}`), nil, clock.Now())
+	if err := webhooks.Store(ctx, duplicate); err != nil || duplicate.ID != event.ID {
+		t.Fatalf("Store duplicado = %v, ID %q; want ID %q", err, duplicate.ID, event.ID)
+	}
+
+	if err := webhooks.Process(ctx, "pix_auto", "E123"); err == nil {
+		t.Fatal("Process deveria devolver o erro do processador")
+	}
+	if n, _ := webhooks.RetryFailed(ctx, 10); n != 0 {
+		t.Errorf("RetryFailed antes do backoff = %d, want 0", n)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if n, err := webhooks.RetryFailed(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("RetryFailed = %d, %v; want 1", n, err)
+	}
+	if err := webhooks.Process(ctx, "pix_auto", "E123"); err != nil || calls != 2 {
+		t.Errorf("Process de evento já processado = %v, %d chamadas; want nil, 2", err, calls)
+	}
+	got, _ := webhooks.GetByEventID(ctx, "pix_auto", "E123")
+	if got.Status != domain.WebhookStatusProcessed || got.RetryCount != 1 {
+		t.Errorf("evento = %s (retries %d), want processed (1)", got.Status, got.RetryCount)
+	}
+}
+
+func TestUsageCounter(t *testing.T) {
+	ctx := context.Background()
+	profiles := NewProfileRepository(
+		&domain.Profile{ID: "owner-1", Role: domain.RoleOwner},
+		&domain.Profile{ID: "prof-1", Role: domain.RoleProfessor, AcademyID: "academy-x"},
+		&domain.Profile{ID: "aluno-1", Role: domain.RoleStudent},
+		&domain.Profile{ID: "aluno-2", Role: domain.RoleStudent},
+	)
+	academies := NewAcademyRepository(profiles)
+	academy := &domain.Academy{Name: "Academia", Slug: "academia", InviteCode: "CONVITE1", OwnerID: "owner-1"}
+	if err := academies.Create(ctx, academy); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	second := &domain.Academy{Name: "Outra", Slug: "outra", InviteCode: "CONVITE2", OwnerID: "owner-1"}
+	if err := academies.Create(ctx, second); !errors.Is(err, domain.ErrOwnerHasAcademy) {
+		t.Errorf("Create com dono já vinculado erro = %v, want ErrOwnerHasAcademy", err)
+	}
+	for _, id := range []string{"aluno-1", "aluno-2"} {
+		if err := profiles.linkAcademy(id, academy.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage := NewUsageCounter(profiles, academies)
+	want := map[domain.Resource]int{domain.ResourceStudents: 2, domain.ResourceProfessors: 0, domain.ResourceLocations: 1}
+	for resource, n := range want {
+		if got, err := usage.CountUsage(ctx, academy.ID, resource); err != nil || got != n {
+			t.Errorf("CountUsage(%s) = %d, %v, want %d", resource, got, err, n)
+		}
+	}
+	if got, _ := usage.CountUsage(ctx, "academy-x", domain.ResourceLocations); got != 0 {
+		t.Errorf("CountUsage(locations) de academia inexistente = %d, want 0", got)
+	}
+}
+
+func TestCouponRepository_RedeemLimit(t *testing.T) {
+	ctx := context.Background()
+	limit := 1
+	coupons := NewCouponRepository(&domain.Coupon{Code: "METADE", MaxRedemptions: &limit, IsActive: true})
+
+	coupon, err := coupons.GetByCode(ctx, "METADE")
+	if err != nil || coupon.ID == "" {
+		t.Fatalf("GetByCode = %+v, %v", coupon, err)
+	}
+	if err := coupons.Redeem(ctx, coupon.ID); err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if err := coupons.Redeem(ctx, coupon.ID); !errors.Is(err, domain.ErrCouponInvalid) {
+		t.Errorf("Redeem esgotado = %v, want ErrCouponInvalid", err)
+	}
+	if err := coupons.Release(ctx, coupon.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if got, _ := coupons.GetByCode(ctx, "METADE"); got.TimesRedeemed != 0 || coupon.TimesRedeemed != 0 {
+		t.Errorf("TimesRedeemed = %d, want 0", got.TimesRedeemed)
+	}
+}
+
+func TestInvoiceRepository_Numbering(t *testing.T) {
+	ctx := context.Background()
+	invoices := NewInvoiceRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = invoices.Create(ctx, &domain.Invoice{AcademyID: "academy-1", PaymentID: fmt.Sprintf("pay-%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	if err := invoices.Create(ctx, &domain.Invoice{AcademyID: "academy-1", PaymentID: "pay-0"}); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("Create repetido = %v, want ErrAlreadyExists", err)
+	}
+	other := &domain.Invoice{AcademyID: "academy-2", PaymentID: "pay-x"}
+	if err := invoices.Create(ctx, other); err != nil || other.Number != 1 {
+		t.Errorf("outra academia: Number = %d, %v, want 1", other.Number, err)
+	}
+
+	list, _ := invoices.ListByAcademy(ctx, "academy-1")
+	if len(list) != 10 {
+		t.Fatalf("faturas = %d, want 10", len(list))
+	}
+	for i, inv := range list {
+		if want := int64(10 - i); inv.Number != want {
+			t.Errorf("list[%d].Number = %d, want %d (sem lacunas, mais recente primeiro)", i, inv.Number, want)
+		}
+	}
+}
+
+func TestNFSeRepository_RPSNumbering(t *testing.T) {
+	ctx := context.Background()
+	notes := NewNFSeRepository()
+
+	for i := 1; i <= 3; i++ {
+		nfse := &domain.NFSe{PaymentID: fmt.Sprintf("pay-%d", i), RPSSeries: "A", Status: domain.NFSeStatusPending}
+		if err := notes.Create(ctx, nfse); err != nil || nfse.RPSNumber != int64(i) {
+			t.Fatalf("Create = %v, RPS %d, want %d", err, nfse.RPSNumber, i)
+		}
+	}
+	if err := notes.Create(ctx, &domain.NFSe{PaymentID: "pay-1", RPSSeries: "A"}); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("Create repetido = %v, want ErrAlreadyExists", err)
+	}
+
+	failed, _ := notes.GetByPaymentID(ctx, "pay-2")
+	failed.MarkFailed(errors.New("prefeitura fora do ar"), time.Now())
+	if err := notes.Save(ctx, failed); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	list, _ := notes.ListByStatus(ctx, domain.NFSeStatusFailed, 0)
+	if len(list) != 1 || list[0].RPSNumber != 2 {
+		t.Errorf("ListByStatus(failed) = %+v, want o RPS 2", list)
+	}
+
+	next := &domain.NFSe{PaymentID: "pay-4", RPSSeries: "A"}
+	if err := notes.Create(ctx, next); err != nil || next.RPSNumber != 4 {
+		t.Errorf("RPS depois do repetido = %d, %v, want 4 (sem lacunas)", next.RPSNumber, err)
+	}
+}
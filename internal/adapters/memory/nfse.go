@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// NFSeRepository implementa ports.NFSeService em memória (thread-safe)
+type NFSeRepository struct {
+	mu       sync.Mutex
+	notes    map[string]*domain.NFSe // por ID
+	counters map[string]int64        // último RPS por série
+}
+
+// NewNFSeRepository cria o repositório de NFS-e
+func NewNFSeRepository() *NFSeRepository {
+	return &NFSeRepository{
+		notes:    make(map[string]*domain.NFSe),
+		counters: make(map[string]int64),
+	}
+}
+
+// Create atribui o próximo RPS da série e grava a NFS-e sob o mesmo lock:
+// uma nota repetida do pagamento não consome número
+func (r *NFSeRepository) Create(ctx context.Context, nfse *domain.NFSe) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.notes {
+		if existing.PaymentID == nfse.PaymentID {
+			return fmt.Errorf("NFS-e do pagamento %s: %w", nfse.PaymentID, domain.ErrAlreadyExists)
+		}
+	}
+	r.counters[nfse.RPSSeries]++
+	nfse.RPSNumber = r.counters[nfse.RPSSeries]
+	if nfse.ID == "" {
+		nfse.ID = newID()
+	}
+	stored := *nfse
+	r.notes[nfse.ID] = &stored
+	return nil
+}
+
+// GetByPaymentID obtém a NFS-e de um pagamento
+func (r *NFSeRepository) GetByPaymentID(ctx context.Context, paymentID string) (*domain.NFSe, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, nfse := range r.notes {
+		if nfse.PaymentID == paymentID {
+			result := *nfse
+			return &result, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// ListByStatus lista as NFS-e em um status, na ordem dos RPS
+func (r *NFSeRepository) ListByStatus(ctx context.Context, status domain.NFSeStatus, limit int) ([]*domain.NFSe, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*domain.NFSe
+	for _, nfse := range r.notes {
+		if nfse.Status == status {
+			cp := *nfse
+			result = append(result, &cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RPSSeries != result[j].RPSSeries {
+			return result[i].RPSSeries < result[j].RPSSeries
+		}
+		return result[i].RPSNumber < result[j].RPSNumber
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// Save grava o resultado do envio ou o cancelamento da NFS-e
+func (r *NFSeRepository) Save(ctx context.Context, nfse *domain.NFSe) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.notes[nfse.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	stored := *nfse
+	r.notes[nfse.ID] = &stored
+	return nil
+}
+
+// Garante que NFSeRepository implementa ports.NFSeService
+var _ ports.NFSeService = (*NFSeRepository)(nil)
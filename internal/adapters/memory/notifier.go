@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Notifier implementa ports.Notifier registrando as notificações em memória e no log
+type Notifier struct {
+	mu   sync.Mutex
+	sent []ports.Notification
+}
+
+// NewNotifier cria um notifier em memória
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// Notify registra a notificação
+func (n *Notifier) Notify(ctx context.Context, notification *ports.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.sent = append(n.sent, *notification)
+	log.Printf("[Notifier] %s (%s) para academia %s", notification.Type, notification.Level, notification.AcademyID)
+	return nil
+}
+
+// Sent retorna uma cópia das notificações registradas
+func (n *Notifier) Sent() []ports.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	result := make([]ports.Notification, len(n.sent))
+	copy(result, n.sent)
+	return result
+}
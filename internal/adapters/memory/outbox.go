@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Outbox implementa ports.Outbox em memória (thread-safe)
+type Outbox struct {
+	mu       sync.Mutex
+	messages []*ports.OutboxMessage
+	sequence int64
+}
+
+// NewOutbox cria um outbox vazio
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+// Append grava eventos em ordem
+func (o *Outbox) Append(ctx context.Context, events ...domain.DomainEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, event := range events {
+		o.sequence++
+		o.messages = append(o.messages, &ports.OutboxMessage{
+			Sequence:      o.sequence,
+			Event:         event,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+	return nil
+}
+
+// ListPending lista mensagens não entregues em ordem de Sequence
+func (o *Outbox) ListPending(ctx context.Context, limit int) ([]*ports.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var result []*ports.OutboxMessage
+	for _, msg := range o.messages {
+		if msg.DispatchedAt != nil {
+			continue
+		}
+		copied := *msg
+		copied.DeliveredTo = append([]string(nil), msg.DeliveredTo...)
+		result = append(result, &copied)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// MarkDispatched marca a mensagem como entregue
+func (o *Outbox) MarkDispatched(ctx context.Context, sequence int64, at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg := o.find(sequence)
+	if msg == nil {
+		return domain.ErrNotFound
+	}
+	msg.DispatchedAt = &at
+	return nil
+}
+
+// MarkDelivered registra que o subscriber recebeu a mensagem
+func (o *Outbox) MarkDelivered(ctx context.Context, sequence int64, subscriber string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg := o.find(sequence)
+	if msg == nil {
+		return domain.ErrNotFound
+	}
+	for _, name := range msg.DeliveredTo {
+		if name == subscriber {
+			return nil
+		}
+	}
+	msg.DeliveredTo = append(msg.DeliveredTo, subscriber)
+	return nil
+}
+
+// MarkFailed registra a falha e agenda a próxima tentativa
+func (o *Outbox) MarkFailed(ctx context.Context, sequence int64, reason string, nextAttemptAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg := o.find(sequence)
+	if msg == nil {
+		return domain.ErrNotFound
+	}
+	msg.Attempts++
+	msg.LastError = &reason
+	msg.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+// Messages retorna uma cópia de todas as mensagens (entregues ou não)
+func (o *Outbox) Messages() []ports.OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := make([]ports.OutboxMessage, len(o.messages))
+	for i, msg := range o.messages {
+		result[i] = *msg
+	}
+	return result
+}
+
+// find busca uma mensagem pela sequência (chamador segura o lock)
+func (o *Outbox) find(sequence int64) *ports.OutboxMessage {
+	for _, msg := range o.messages {
+		if msg.Sequence == sequence {
+			return msg
+		}
+	}
+	return nil
+}
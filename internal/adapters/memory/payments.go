@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// PaymentRepository implementa ports.PaymentService em memória (thread-safe)
+type PaymentRepository struct {
+	mu       sync.Mutex
+	payments map[string]*domain.PaymentHistory
+	outbox   ports.Outbox
+}
+
+// NewPaymentRepository cria o repositório; eventos dos pagamentos vão para outbox
+func NewPaymentRepository(outbox ports.Outbox) *PaymentRepository {
+	return &PaymentRepository{
+		payments: make(map[string]*domain.PaymentHistory),
+		outbox:   outbox,
+	}
+}
+
+// RecordPayment grava um novo pagamento (gerando o ID) e os eventos no outbox
+func (r *PaymentRepository) RecordPayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if payment.ID == "" {
+		payment.ID = newID()
+	}
+	return r.store(ctx, payment, 1)
+}
+
+// UpdatePayment grava as alterações do pagamento e os eventos no outbox.
+// Falha com domain.ErrConcurrentModification se o pagamento mudou desde a leitura.
+func (r *PaymentRepository) UpdatePayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.payments[payment.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if existing.Version != payment.Version {
+		return domain.ErrConcurrentModification
+	}
+	return r.store(ctx, payment, payment.Version+1)
+}
+
+// GetByID busca pagamento pelo ID
+func (r *PaymentRepository) GetByID(ctx context.Context, paymentID string) (*domain.PaymentHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[paymentID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return clonePayment(payment), nil
+}
+
+// GetByGatewayPaymentID busca pagamento pelo ID do gateway (o mais recente, se houver vários)
+func (r *PaymentRepository) GetByGatewayPaymentID(ctx context.Context, gatewayPaymentID string) (*domain.PaymentHistory, error) {
+	payments := r.list(func(p *domain.PaymentHistory) bool {
+		return p.GatewayPaymentID != nil && *p.GatewayPaymentID == gatewayPaymentID
+	})
+	if len(payments) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return payments[len(payments)-1], nil
+}
+
+// ListByAcademy lista o histórico de pagamentos da academia, do mais recente ao mais antigo
+func (r *PaymentRepository) ListByAcademy(ctx context.Context, academyID string, limit, offset int) ([]*domain.PaymentHistory, error) {
+	payments := r.list(func(p *domain.PaymentHistory) bool { return p.AcademyID == academyID })
+	for i, j := 0, len(payments)-1; i < j; i, j = i+1, j-1 {
+		payments[i], payments[j] = payments[j], payments[i]
+	}
+
+	if offset >= len(payments) {
+		return nil, nil
+	}
+	payments = payments[offset:]
+	if limit > 0 && len(payments) > limit {
+		payments = payments[:limit]
+	}
+	return payments, nil
+}
+
+// ListBySubscription lista os pagamentos da assinatura em ordem de criação
+func (r *PaymentRepository) ListBySubscription(ctx context.Context, subscriptionID string) ([]*domain.PaymentHistory, error) {
+	return r.list(func(p *domain.PaymentHistory) bool { return p.SubscriptionID == subscriptionID }), nil
+}
+
+// ListWithPendingRefunds lista os pagamentos com devoluções pendentes solicitadas até requestedBefore
+func (r *PaymentRepository) ListWithPendingRefunds(ctx context.Context, requestedBefore time.Time) ([]*domain.PaymentHistory, error) {
+	return r.list(func(p *domain.PaymentHistory) bool {
+		for _, refund := range p.Refunds {
+			if refund.Status == domain.RefundStatusPending && !refund.RequestedAt.After(requestedBefore) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// store guarda uma cópia na versão informada e envia os eventos ao outbox
+// (chamador segura o lock)
+func (r *PaymentRepository) store(ctx context.Context, payment *domain.PaymentHistory, version int) error {
+	for _, refund := range payment.Refunds {
+		if refund.PaymentID == "" {
+			refund.PaymentID = payment.ID
+		}
+	}
+	if err := r.outbox.Append(ctx, payment.PullEvents()...); err != nil {
+		return err
+	}
+	payment.Version = version
+	r.payments[payment.ID] = clonePayment(payment)
+	return nil
+}
+
+// list devolve cópias dos pagamentos que satisfazem match, por data de criação
+func (r *PaymentRepository) list(match func(*domain.PaymentHistory) bool) []*domain.PaymentHistory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*domain.PaymentHistory
+	for _, payment := range r.payments {
+		if match(payment) {
+			result = append(result, clonePayment(payment))
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// clonePayment copia o pagamento e as devoluções
+func clonePayment(payment *domain.PaymentHistory) *domain.PaymentHistory {
+	c := *payment
+	c.Refunds = nil
+	for _, refund := range payment.Refunds {
+		copied := *refund
+		c.Refunds = append(c.Refunds, &copied)
+	}
+	return &c
+}
+
+// Garante que PaymentRepository implementa ports.PaymentService
+var _ ports.PaymentService = (*PaymentRepository)(nil)
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// PlanRepository implementa ports.PlanService em memória (thread-safe)
+type PlanRepository struct {
+	mu    sync.RWMutex
+	plans map[string]*domain.SubscriptionPlan
+}
+
+// NewPlanRepository cria o repositório com os planos informados
+// (DefaultPlans para os planos padrão da plataforma)
+func NewPlanRepository(plans ...*domain.SubscriptionPlan) *PlanRepository {
+	r := &PlanRepository{plans: make(map[string]*domain.SubscriptionPlan)}
+	for _, plan := range plans {
+		r.Put(plan)
+	}
+	return r
+}
+
+// DefaultPlans são os planos Starter, Pro e Business de docs/DATA_MODEL.md,
+// com IDs fixos para facilitar testes e chamadas manuais à API
+func DefaultPlans(now time.Time) []*domain.SubscriptionPlan {
+	intPtr := func(v int) *int { return &v }
+	plan := func(id, name, slug string, price int, students, professors *int, features ...domain.Feature) *domain.SubscriptionPlan {
+		return &domain.SubscriptionPlan{
+			ID:            id,
+			Name:          name,
+			Slug:          slug,
+			PriceMonthly:  price,
+			Currency:      "BRL",
+			MaxStudents:   students,
+			MaxProfessors: professors,
+			MaxLocations:  1,
+			Features:      features,
+			IsActive:      true,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+
+	base := []domain.Feature{domain.FeatureCheckin, domain.FeatureSchedule, domain.FeatureProfiles}
+	pro := append(append([]domain.Feature(nil), base...), domain.FeatureAnalytics, domain.FeatureStore)
+	business := append(append([]domain.Feature(nil), pro...),
+		domain.FeatureAPI, domain.FeatureMultiLocation, domain.FeaturePrioritySupport)
+
+	return []*domain.SubscriptionPlan{
+		plan("00000000-0000-4000-8000-000000000001", "Starter", "starter", 9900, intPtr(50), intPtr(2), base...),
+		plan("00000000-0000-4000-8000-000000000002", "Pro", "pro", 19900, intPtr(200), intPtr(5), pro...),
+		plan("00000000-0000-4000-8000-000000000003", "Business", "business", 39900, nil, nil, business...),
+	}
+}
+
+// Put cadastra ou substitui um plano (gera o ID se vazio)
+func (r *PlanRepository) Put(plan *domain.SubscriptionPlan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if plan.ID == "" {
+		plan.ID = newID()
+	}
+	stored := *plan
+	stored.Features = append(domain.FeatureSet(nil), plan.Features...)
+	r.plans[plan.ID] = &stored
+}
+
+// ListActive lista os planos ativos, do mais barato ao mais caro
+func (r *PlanRepository) ListActive(ctx context.Context) ([]*domain.SubscriptionPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.SubscriptionPlan
+	for _, plan := range r.plans {
+		if plan.IsActive {
+			result = append(result, clonePlan(plan))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PriceMonthly < result[j].PriceMonthly })
+	return result, nil
+}
+
+// GetBySlug busca plano pelo slug
+func (r *PlanRepository) GetBySlug(ctx context.Context, slug string) (*domain.SubscriptionPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, plan := range r.plans {
+		if plan.Slug == slug {
+			return clonePlan(plan), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// GetByID busca plano pelo ID
+func (r *PlanRepository) GetByID(ctx context.Context, id string) (*domain.SubscriptionPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plan, ok := r.plans[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return clonePlan(plan), nil
+}
+
+// clonePlan copia o plano sem compartilhar a lista de features
+func clonePlan(plan *domain.SubscriptionPlan) *domain.SubscriptionPlan {
+	c := *plan
+	c.Features = append(domain.FeatureSet(nil), plan.Features...)
+	return &c
+}
+
+// Garante que PlanRepository implementa ports.PlanService
+var _ ports.PlanService = (*PlanRepository)(nil)
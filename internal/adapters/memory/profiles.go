@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// ProfileRepository implementa ports.ProfileService em memória (thread-safe).
+// Em memória não há o signup do Supabase: os perfis entram por Put.
+type ProfileRepository struct {
+	mu       sync.RWMutex
+	profiles map[string]*domain.Profile
+}
+
+// NewProfileRepository cria o repositório com os perfis informados
+func NewProfileRepository(profiles ...*domain.Profile) *ProfileRepository {
+	r := &ProfileRepository{profiles: make(map[string]*domain.Profile)}
+	for _, profile := range profiles {
+		r.Put(profile)
+	}
+	return r
+}
+
+// Put grava (ou substitui) uma cópia do perfil
+func (r *ProfileRepository) Put(profile *domain.Profile) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *profile
+	r.profiles[profile.ID] = &stored
+}
+
+// GetByID busca o perfil do usuário
+func (r *ProfileRepository) GetByID(ctx context.Context, userID string) (*domain.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *profile
+	return &copied, nil
+}
+
+// linkAcademy vincula o perfil ainda sem academia à academia (criação da academia pelo dono)
+func (r *ProfileRepository) linkAcademy(userID, academyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if profile.AcademyID != "" {
+		return domain.ErrOwnerHasAcademy
+	}
+	profile.AcademyID = academyID
+	return nil
+}
+
+// countByRole conta os perfis da academia com o papel informado
+func (r *ProfileRepository) countByRole(academyID string, role domain.Role) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, profile := range r.profiles {
+		if profile.AcademyID == academyID && profile.Role == role {
+			n++
+		}
+	}
+	return n
+}
+
+// Garante que ProfileRepository implementa ports.ProfileService
+var _ ports.ProfileService = (*ProfileRepository)(nil)
@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// SubscriptionRepository implementa ports.SubscriptionService em memória (thread-safe).
+// Guarda cópias: alterações na assinatura devolvida só valem depois de Save.
+type SubscriptionRepository struct {
+	mu     sync.Mutex
+	subs   map[string]*domain.Subscription
+	plans  ports.PlanService
+	outbox ports.Outbox
+	clock  domain.Clock
+}
+
+// NewSubscriptionRepository cria o repositório; eventos das assinaturas vão para outbox
+func NewSubscriptionRepository(plans ports.PlanService, outbox ports.Outbox) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subs:   make(map[string]*domain.Subscription),
+		plans:  plans,
+		outbox: outbox,
+		clock:  domain.SystemClock{},
+	}
+}
+
+// SetClock troca o relógio usado nas regras de negócio (testes, sandbox)
+func (r *SubscriptionRepository) SetClock(clock domain.Clock) {
+	r.clock = clock
+}
+
+// CreateTrial cria a assinatura em trial da academia com o trial do plano
+func (r *SubscriptionRepository) CreateTrial(ctx context.Context, academyID, planID string) (*domain.Subscription, error) {
+	plan, err := r.plans.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	now := r.clock.Now()
+	terms, err := domain.ResolveTrialTerms(plan, nil, now)
+	if err != nil {
+		return nil, err
+	}
+	sub := domain.NewTrialSubscription(academyID, terms, now)
+	if err := r.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Activate ativa a assinatura com um período começando agora
+func (r *SubscriptionRepository) Activate(ctx context.Context, subscriptionID string, gateway domain.PaymentGateway) (*domain.Subscription, error) {
+	sub, err := r.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	now := r.clock.Now()
+	interval := sub.BillingInterval
+	if interval == "" {
+		interval = domain.BillingIntervalMonthly
+	}
+	end := domain.NextBillingDate(now, interval, now.In(domain.BillingLocation).Day())
+	if err := sub.Activate(gateway, now, end, domain.ActorSystem, now); err != nil {
+		return nil, err
+	}
+	if err := r.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Cancel cancela a assinatura (agora ou no fim do período)
+func (r *SubscriptionRepository) Cancel(ctx context.Context, subscriptionID string, reason string, atPeriodEnd bool) error {
+	sub, err := r.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if err := sub.Cancel(reason, atPeriodEnd, domain.ActorSystem, r.clock.Now()); err != nil {
+		return err
+	}
+	return r.Save(ctx, sub)
+}
+
+// ChangePlan troca o plano sem pró-rata (a cobrança da diferença é do PlanChangeService)
+func (r *SubscriptionRepository) ChangePlan(ctx context.Context, subscriptionID, newPlanID string) (*domain.Subscription, error) {
+	if _, err := r.plans.GetByID(ctx, newPlanID); err != nil {
+		return nil, err
+	}
+	sub, err := r.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	sub.PlanID = newPlanID
+	sub.UpdatedAt = r.clock.Now()
+	if err := r.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Create grava a assinatura (gerando o ID) e os eventos dela no outbox.
+// Como no banco, só existe uma assinatura por academia.
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.subs {
+		if existing.AcademyID == sub.AcademyID {
+			return domain.ErrAlreadyExists
+		}
+	}
+	if sub.ID == "" {
+		sub.ID = newID()
+	}
+	return r.store(ctx, sub, 1)
+}
+
+// GetByID obtém uma assinatura pelo ID
+func (r *SubscriptionRepository) GetByID(ctx context.Context, subscriptionID string) (*domain.Subscription, error) {
+	return r.find(func(s *domain.Subscription) bool { return s.ID == subscriptionID })
+}
+
+// GetByAcademy obtém a assinatura de uma academia
+func (r *SubscriptionRepository) GetByAcademy(ctx context.Context, academyID string) (*domain.Subscription, error) {
+	return r.find(func(s *domain.Subscription) bool { return s.AcademyID == academyID })
+}
+
+// GetByStripeSubscriptionID obtém por ID da subscription no Stripe
+func (r *SubscriptionRepository) GetByStripeSubscriptionID(ctx context.Context, stripeSubID string) (*domain.Subscription, error) {
+	return r.find(func(s *domain.Subscription) bool {
+		return s.StripeSubscriptionID != nil && *s.StripeSubscriptionID == stripeSubID
+	})
+}
+
+// GetByPixRecurrenceID obtém pelo idRec do PIX Automático
+func (r *SubscriptionRepository) GetByPixRecurrenceID(ctx context.Context, recurrenceID string) (*domain.Subscription, error) {
+	return r.find(func(s *domain.Subscription) bool {
+		return s.PixRecurrenceID != nil && *s.PixRecurrenceID == recurrenceID
+	})
+}
+
+// ListByStatus lista assinaturas em um status, das mais antigas às mais novas
+func (r *SubscriptionRepository) ListByStatus(ctx context.Context, status domain.SubscriptionStatus, limit int) ([]*domain.Subscription, error) {
+	return r.list(limit, func(s *domain.Subscription) bool { return s.Status == status }), nil
+}
+
+// ListCancellationsDue lista cancelamentos agendados cujo período terminou até
+// now e cancelamentos cuja recorrência o gateway ainda não encerrou
+func (r *SubscriptionRepository) ListCancellationsDue(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error) {
+	return r.list(limit, func(s *domain.Subscription) bool {
+		return s.GatewayCancelPending || s.CancelAtPeriodEnd &&
+			s.Status != domain.SubscriptionStatusCanceled && s.Status != domain.SubscriptionStatusPaused &&
+			s.CurrentPeriodEnd != nil && !s.CurrentPeriodEnd.After(now)
+	}), nil
+}
+
+// Save grava as alterações da assinatura e os eventos dela no outbox.
+// Falha com domain.ErrConcurrentModification se a assinatura mudou desde a leitura.
+func (r *SubscriptionRepository) Save(ctx context.Context, sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.subs[sub.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if existing.Version != sub.Version {
+		return domain.ErrConcurrentModification
+	}
+	return r.store(ctx, sub, sub.Version+1)
+}
+
+// ExpireTrials expira os trials vencidos
+func (r *SubscriptionRepository) ExpireTrials(ctx context.Context) (int, error) {
+	now := r.clock.Now()
+	subs := r.list(0, func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionStatusTrialing && s.TrialEndDate != nil && !s.TrialEndDate.After(now)
+	})
+
+	expired := 0
+	for _, sub := range subs {
+		if err := sub.Expire(domain.ActorSystem, now); err != nil {
+			return expired, err
+		}
+		if err := r.Save(ctx, sub); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// store guarda uma cópia na versão informada e envia os eventos ao outbox
+// (chamador segura o lock)
+func (r *SubscriptionRepository) store(ctx context.Context, sub *domain.Subscription, version int) error {
+	if err := r.outbox.Append(ctx, sub.PullEvents()...); err != nil {
+		return err
+	}
+	sub.Version = version
+	r.subs[sub.ID] = cloneSubscription(sub)
+	return nil
+}
+
+// find devolve uma cópia da primeira assinatura que satisfaz match
+func (r *SubscriptionRepository) find(match func(*domain.Subscription) bool) (*domain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sub := range r.subs {
+		if match(sub) {
+			return cloneSubscription(sub), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// list devolve cópias das assinaturas que satisfazem match, por data de criação
+func (r *SubscriptionRepository) list(limit int, match func(*domain.Subscription) bool) []*domain.Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*domain.Subscription
+	for _, sub := range r.subs {
+		if match(sub) {
+			result = append(result, cloneSubscription(sub))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// cloneSubscription copia a assinatura sem compartilhar slices nem structs aninhadas
+func cloneSubscription(sub *domain.Subscription) *domain.Subscription {
+	c := *sub
+	c.TrialExtensions = append([]domain.TrialExtension(nil), sub.TrialExtensions...)
+	c.Pauses = append([]domain.PauseWindow(nil), sub.Pauses...)
+	c.Transitions = append([]domain.SubscriptionTransition(nil), sub.Transitions...)
+	c.Metadata = append([]byte(nil), sub.Metadata...)
+	if sub.ScheduledPlanChange != nil {
+		change := *sub.ScheduledPlanChange
+		c.ScheduledPlanChange = &change
+	}
+	if sub.PendingPlanChange != nil {
+		pending := *sub.PendingPlanChange
+		c.PendingPlanChange = &pending
+	}
+	if sub.Discount != nil {
+		discount := *sub.Discount
+		c.Discount = &discount
+	}
+	return &c
+}
+
+// Garante que SubscriptionRepository implementa ports.SubscriptionService
+var _ ports.SubscriptionService = (*SubscriptionRepository)(nil)
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// TrialCampaignRepository implementa ports.TrialCampaignService em memória (thread-safe)
+type TrialCampaignRepository struct {
+	mu        sync.RWMutex
+	campaigns map[string]*domain.TrialCampaign // por código
+}
+
+// NewTrialCampaignRepository cria o repositório com as campanhas informadas
+func NewTrialCampaignRepository(campaigns ...*domain.TrialCampaign) *TrialCampaignRepository {
+	r := &TrialCampaignRepository{campaigns: make(map[string]*domain.TrialCampaign)}
+	for _, c := range campaigns {
+		if c.ID == "" {
+			c.ID = newID()
+		}
+		stored := *c
+		stored.PlanIDs = append([]string(nil), c.PlanIDs...)
+		r.campaigns[c.Code] = &stored
+	}
+	return r
+}
+
+// GetByCode busca campanha pelo código (já normalizado)
+func (r *TrialCampaignRepository) GetByCode(ctx context.Context, code string) (*domain.TrialCampaign, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.campaigns[code]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	result := *c
+	result.PlanIDs = append([]string(nil), c.PlanIDs...)
+	return &result, nil
+}
+
+// Garante que TrialCampaignRepository implementa ports.TrialCampaignService
+var _ ports.TrialCampaignService = (*TrialCampaignRepository)(nil)
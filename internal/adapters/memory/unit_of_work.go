@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// UnitOfWork implementa ports.UnitOfWork em memória: serializa as unidades de
+// trabalho, mas não desfaz gravações se fn falhar (não há transação). Por isso
+// o armazenamento em memória é só para desenvolvimento (STORAGE_DRIVER=memory
+// é recusado em produção): uma falha no meio de uma unidade, como o trial que
+// não pôde ser criado depois da academia, deixa as gravações anteriores. O
+// rollback é coberto pelos testes de integração do Postgres.
+type UnitOfWork struct {
+	mu sync.Mutex
+}
+
+// NewUnitOfWork cria a unit of work em memória
+func NewUnitOfWork() *UnitOfWork {
+	return &UnitOfWork{}
+}
+
+// uowKey marca o contexto de uma unidade em andamento (Do aninhado não trava)
+type uowKey struct{}
+
+// Do executa fn com exclusão mútua
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(uowKey{}) != nil {
+		return fn(ctx)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return fn(context.WithValue(ctx, uowKey{}, true))
+}
+
+// Garante que UnitOfWork implementa ports.UnitOfWork
+var _ ports.UnitOfWork = (*UnitOfWork)(nil)
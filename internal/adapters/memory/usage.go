@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// UsageCounter implementa ports.UsageCounter sobre os perfis em memória:
+// alunos e professores são perfis com o papel correspondente, e cada academia
+// cadastrada conta como uma unidade
+type UsageCounter struct {
+	profiles  *ProfileRepository
+	academies *AcademyRepository
+}
+
+// NewUsageCounter cria o contador de uso dos recursos limitados por plano
+func NewUsageCounter(profiles *ProfileRepository, academies *AcademyRepository) *UsageCounter {
+	return &UsageCounter{profiles: profiles, academies: academies}
+}
+
+// CountUsage retorna quantos itens do recurso a academia já possui
+func (c *UsageCounter) CountUsage(ctx context.Context, academyID string, resource domain.Resource) (int, error) {
+	switch resource {
+	case domain.ResourceStudents:
+		return c.profiles.countByRole(academyID, domain.RoleStudent), nil
+	case domain.ResourceProfessors:
+		return c.profiles.countByRole(academyID, domain.RoleProfessor), nil
+	case domain.ResourceLocations:
+		if _, err := c.academies.GetByID(ctx, academyID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		return 1, nil
+	}
+	return 0, fmt.Errorf("%w: recurso desconhecido: %s", domain.ErrValidation, resource)
+}
+
+// Garante que UsageCounter implementa ports.UsageCounter
+var _ ports.UsageCounter = (*UsageCounter)(nil)
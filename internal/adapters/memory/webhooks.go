@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// WebhookProcessor aplica um webhook armazenado às regras de negócio
+type WebhookProcessor func(ctx context.Context, event *domain.WebhookEvent) error
+
+// WebhookRepository implementa ports.WebhookService em memória (thread-safe)
+type WebhookRepository struct {
+	mu      sync.Mutex
+	events  map[webhookKey]*domain.WebhookEvent
+	process WebhookProcessor
+	clock   domain.Clock
+	backoff domain.WebhookBackoff
+	rand    func() float64
+}
+
+// webhookKey identifica o evento: o mesmo event_id pode vir de gateways diferentes
+type webhookKey struct {
+	gateway, eventID string
+}
+
+// NewWebhookRepository cria o repositório; process é chamado por Process e
+// RetryFailed (nil aceita o evento sem fazer nada). Se process devolver
+// ports.ErrWebhookSkipped o evento fica como skipped.
+func NewWebhookRepository(process WebhookProcessor) *WebhookRepository {
+	return &WebhookRepository{
+		events:  make(map[webhookKey]*domain.WebhookEvent),
+		process: process,
+		clock:   domain.SystemClock{},
+		backoff: domain.DefaultWebhookBackoff,
+		rand:    rand.Float64,
+	}
+}
+
+// SetClock troca o relógio usado no backoff dos retries (testes, sandbox)
+func (r *WebhookRepository) SetClock(clock domain.Clock) {
+	r.clock = clock
+}
+
+// SetBackoff troca o intervalo entre retentativas e o limite da dead-letter
+func (r *WebhookRepository) SetBackoff(backoff domain.WebhookBackoff) {
+	r.backoff = backoff
+}
+
+// Store armazena o evento; um gateway + event_id já recebido não é duplicado
+// e o evento volta com os dados do registro existente
+func (r *WebhookRepository) Store(ctx context.Context, event *domain.WebhookEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := webhookKey{event.Gateway, event.EventID}
+	if existing, ok := r.events[key]; ok {
+		*event = *existing
+		return nil
+	}
+	if event.ID == "" {
+		event.ID = newID()
+	}
+	stored := *event
+	r.events[key] = &stored
+	return nil
+}
+
+// Process processa o evento uma única vez: só eventos pendentes ou falhos são
+// processados, e uma falha fica registrada para RetryFailed
+func (r *WebhookRepository) Process(ctx context.Context, gateway, eventID string) error {
+	r.mu.Lock()
+	stored, ok := r.events[webhookKey{gateway, eventID}]
+	if !ok {
+		r.mu.Unlock()
+		return domain.ErrNotFound
+	}
+	if stored.Status != domain.WebhookStatusPending && stored.Status != domain.WebhookStatusFailed {
+		r.mu.Unlock()
+		return nil
+	}
+	stored.MarkProcessing(r.clock.Now())
+	event := *stored
+	r.mu.Unlock()
+
+	return r.run(ctx, &event)
+}
+
+// GetByEventID busca webhook pelo gateway e event_id
+func (r *WebhookRepository) GetByEventID(ctx context.Context, gateway, eventID string) (*domain.WebhookEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, ok := r.events[webhookKey{gateway, eventID}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	result := *event
+	return &result, nil
+}
+
+// GetByID busca webhook pelo ID interno
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*domain.WebhookEvent, error) {
+	events := r.list(0, 0, func(e *domain.WebhookEvent) bool { return e.ID == id })
+	if len(events) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return events[0], nil
+}
+
+// RetryFailed devolve às retentativas os eventos abandonados em processing
+// (lease vencido) e reserva até limit webhooks falhados com retry vencido
+func (r *WebhookRepository) RetryFailed(ctx context.Context, limit int) (int, error) {
+	now := r.clock.Now()
+
+	r.mu.Lock()
+	lease := r.backoff.LeaseDuration()
+	for _, event := range r.events {
+		if event.IsAbandoned(now, lease) {
+			_ = event.Abandon(now, r.backoff, r.rand())
+		}
+	}
+	var due []*domain.WebhookEvent
+	for _, event := range r.events {
+		if event.IsRetryDue(now) {
+			due = append(due, event)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRetryAt.Before(*due[j].NextRetryAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]domain.WebhookEvent, len(due))
+	for i, event := range due {
+		event.MarkProcessing(now)
+		claimed[i] = *event
+	}
+	r.mu.Unlock()
+
+	processed := 0
+	for i := range claimed {
+		if err := r.run(ctx, &claimed[i]); err != nil {
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// ListPending lista webhooks pendentes, dos mais antigos aos mais novos
+func (r *WebhookRepository) ListPending(ctx context.Context, limit int) ([]*domain.WebhookEvent, error) {
+	return r.ListByStatus(ctx, domain.WebhookStatusPending, limit, 0)
+}
+
+// ListByStatus lista webhooks em um status, dos mais antigos aos mais novos
+func (r *WebhookRepository) ListByStatus(ctx context.Context, status domain.WebhookStatus, limit, offset int) ([]*domain.WebhookEvent, error) {
+	return r.list(limit, offset, func(e *domain.WebhookEvent) bool { return e.Status == status }), nil
+}
+
+// Save grava o status, o payload e as retentativas do evento
+func (r *WebhookRepository) Save(ctx context.Context, event *domain.WebhookEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.save(event)
+}
+
+// run chama o processador fora do lock (o status processing impede que outro
+// worker pegue o mesmo evento) e grava o resultado
+func (r *WebhookRepository) run(ctx context.Context, event *domain.WebhookEvent) error {
+	var processErr error
+	if r.process != nil {
+		processErr = r.process(ctx, event)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case errors.Is(processErr, ports.ErrWebhookSkipped):
+		event.MarkSkipped()
+		processErr = nil
+	case processErr != nil:
+		event.Fail(processErr.Error(), r.clock.Now(), r.backoff, r.rand())
+	default:
+		event.MarkProcessed(r.clock.Now())
+	}
+	if err := r.save(event); err != nil {
+		return err
+	}
+	return processErr
+}
+
+// save substitui o registro do evento (chamador segura o lock)
+func (r *WebhookRepository) save(event *domain.WebhookEvent) error {
+	key := webhookKey{event.Gateway, event.EventID}
+	if _, ok := r.events[key]; !ok {
+		return domain.ErrNotFound
+	}
+	stored := *event
+	r.events[key] = &stored
+	return nil
+}
+
+// list devolve cópias dos eventos que satisfazem match, por recebimento
+func (r *WebhookRepository) list(limit, offset int, match func(*domain.WebhookEvent) bool) []*domain.WebhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*domain.WebhookEvent
+	for _, event := range r.events {
+		if match(event) {
+			copied := *event
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ReceivedAt.Before(result[j].ReceivedAt) })
+	if offset >= len(result) {
+		return nil
+	}
+	result = result[offset:]
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Garante que WebhookRepository implementa ports.WebhookService
+var _ ports.WebhookService = (*WebhookRepository)(nil)
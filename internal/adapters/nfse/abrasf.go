@@ -0,0 +1,311 @@
+// Package nfse implementa a emissão de NFS-e no padrão ABRASF 2.04.
+//
+// O pacote gera os XMLs de envio (GerarNfseEnvio e CancelarNfseEnvio) a partir
+// da configuração do prestador (a BlackBelt) e dos dados do RPS. Os webservices
+// municipais recebem esses XMLs assinados (XMLDSig com o certificado A1 do
+// prestador) em envelopes SOAP que variam por cidade; cada município é um
+// ports.FiscalProvider próprio que reaproveita os builders daqui.
+//
+// LocalProvider é o provider de desenvolvimento: grava os XMLs em disco e
+// devolve uma NFS-e fictícia, sem falar com nenhuma prefeitura.
+package nfse
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Namespace do schema ABRASF
+const Namespace = "http://www.abrasf.org.br/nfse.xsd"
+
+// maxDiscriminacao é o tamanho máximo da discriminação do serviço
+const maxDiscriminacao = 2000
+
+// Valores dos campos "sim/não" do schema (tsSimNao)
+const (
+	abrasfSim = 1
+	abrasfNao = 2
+)
+
+// Config são os dados fiscais do prestador
+type Config struct {
+	CNPJ                      string // Só dígitos
+	InscricaoMunicipal        string
+	CodigoMunicipio           string // IBGE, 7 dígitos
+	ItemListaServico          string // LC 116/2003, ex: "01.05" (licenciamento de software)
+	CodigoTributacaoMunicipio string // Opcional, depende do município
+	AliquotaISS               int    // Pontos-base: 200 = 2%
+	OptanteSimplesNacional    bool
+	IncentivoFiscal           bool
+}
+
+// Validate verifica a configuração do prestador
+func (c *Config) Validate() error {
+	if len(c.CNPJ) != 14 || strings.Trim(c.CNPJ, "0123456789") != "" {
+		return fmt.Errorf("CNPJ do prestador inválido: %q", c.CNPJ)
+	}
+	if c.InscricaoMunicipal == "" {
+		return fmt.Errorf("inscrição municipal do prestador é obrigatória")
+	}
+	if len(c.CodigoMunicipio) != 7 {
+		return fmt.Errorf("código IBGE do município deve ter 7 dígitos: %q", c.CodigoMunicipio)
+	}
+	if c.ItemListaServico == "" {
+		return fmt.Errorf("item da lista de serviço é obrigatório")
+	}
+	if c.AliquotaISS < 0 || c.AliquotaISS > 500 {
+		return fmt.Errorf("alíquota de ISS fora do intervalo legal (0 a 5%%): %d", c.AliquotaISS)
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────────
+// GerarNfseEnvio
+// ──────────────────────────────────────────────
+
+type gerarNfseEnvio struct {
+	XMLName xml.Name `xml:"GerarNfseEnvio"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Rps     rpsEnvio `xml:"Rps"`
+}
+
+type rpsEnvio struct {
+	InfDeclaracao infDeclaracaoPrestacaoServico `xml:"InfDeclaracaoPrestacaoServico"`
+}
+
+type infDeclaracaoPrestacaoServico struct {
+	ID                     string          `xml:"Id,attr"`
+	Rps                    rpsIdentificado `xml:"Rps"`
+	Competencia            string          `xml:"Competencia"`
+	Servico                servico         `xml:"Servico"`
+	Prestador              prestador       `xml:"Prestador"`
+	Tomador                tomador         `xml:"TomadorServico"`
+	OptanteSimplesNacional int             `xml:"OptanteSimplesNacional"`
+	IncentivoFiscal        int             `xml:"IncentivoFiscal"`
+}
+
+type rpsIdentificado struct {
+	Identificacao identificacaoRps `xml:"IdentificacaoRps"`
+	DataEmissao   string           `xml:"DataEmissao"`
+	Status        int              `xml:"Status"` // 1 = normal
+}
+
+type identificacaoRps struct {
+	Numero int64  `xml:"Numero"`
+	Serie  string `xml:"Serie"`
+	Tipo   int    `xml:"Tipo"` // 1 = RPS
+}
+
+type servico struct {
+	Valores                   valores `xml:"Valores"`
+	IssRetido                 int     `xml:"IssRetido"`
+	ItemListaServico          string  `xml:"ItemListaServico"`
+	CodigoTributacaoMunicipio string  `xml:"CodigoTributacaoMunicipio,omitempty"`
+	Discriminacao             string  `xml:"Discriminacao"`
+	CodigoMunicipio           string  `xml:"CodigoMunicipio"`
+	ExigibilidadeISS          int     `xml:"ExigibilidadeISS"` // 1 = exigível
+	MunicipioIncidencia       string  `xml:"MunicipioIncidencia"`
+}
+
+type valores struct {
+	ValorServicos string `xml:"ValorServicos"`
+	Aliquota      string `xml:"Aliquota,omitempty"`
+}
+
+type cpfCnpj struct {
+	Cpf  string `xml:"Cpf,omitempty"`
+	Cnpj string `xml:"Cnpj,omitempty"`
+}
+
+type prestador struct {
+	CpfCnpj            cpfCnpj `xml:"CpfCnpj"`
+	InscricaoMunicipal string  `xml:"InscricaoMunicipal"`
+}
+
+type tomador struct {
+	Identificacao identificacaoTomador `xml:"IdentificacaoTomador"`
+	RazaoSocial   string               `xml:"RazaoSocial"`
+	Endereco      *endereco            `xml:"Endereco,omitempty"`
+	Contato       *contato             `xml:"Contato,omitempty"`
+}
+
+type identificacaoTomador struct {
+	CpfCnpj cpfCnpj `xml:"CpfCnpj"`
+}
+
+type endereco struct {
+	Endereco        string `xml:"Endereco"`
+	Numero          string `xml:"Numero"`
+	Complemento     string `xml:"Complemento,omitempty"`
+	Bairro          string `xml:"Bairro"`
+	CodigoMunicipio string `xml:"CodigoMunicipio"`
+	Uf              string `xml:"Uf"`
+	Cep             string `xml:"Cep"`
+}
+
+type contato struct {
+	Email string `xml:"Email"`
+}
+
+// BuildGerarNfseEnvio gera o XML (sem assinatura) de envio de um RPS
+func BuildGerarNfseEnvio(cfg *Config, req *ports.NFSeIssueRequest) ([]byte, error) {
+	if err := req.Tomador.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("valor do serviço deve ser positivo: %d", req.Amount)
+	}
+	if req.RPSNumber <= 0 || req.RPSSeries == "" {
+		return nil, fmt.Errorf("RPS sem número ou série")
+	}
+	if strings.TrimSpace(req.Description) == "" {
+		return nil, fmt.Errorf("discriminação do serviço é obrigatória")
+	}
+
+	doc := gerarNfseEnvio{
+		Xmlns: Namespace,
+		Rps: rpsEnvio{InfDeclaracao: infDeclaracaoPrestacaoServico{
+			ID: fmt.Sprintf("rps%s%d", req.RPSSeries, req.RPSNumber),
+			Rps: rpsIdentificado{
+				Identificacao: identificacaoRps{Numero: req.RPSNumber, Serie: req.RPSSeries, Tipo: 1},
+				DataEmissao:   req.IssueDate.In(domain.BillingLocation).Format("2006-01-02"),
+				Status:        1,
+			},
+			Competencia: req.Competence.In(domain.BillingLocation).Format("2006-01-02"),
+			Servico: servico{
+				Valores:                   valores{ValorServicos: decimal(req.Amount)},
+				IssRetido:                 abrasfNao,
+				ItemListaServico:          cfg.ItemListaServico,
+				CodigoTributacaoMunicipio: cfg.CodigoTributacaoMunicipio,
+				Discriminacao:             truncateRunes(req.Description, maxDiscriminacao),
+				CodigoMunicipio:           cfg.CodigoMunicipio,
+				ExigibilidadeISS:          1,
+				MunicipioIncidencia:       cfg.CodigoMunicipio,
+			},
+			Prestador: prestador{
+				CpfCnpj:            cpfCnpj{Cnpj: cfg.CNPJ},
+				InscricaoMunicipal: cfg.InscricaoMunicipal,
+			},
+			Tomador:                newTomador(&req.Tomador),
+			OptanteSimplesNacional: simNao(cfg.OptanteSimplesNacional),
+			IncentivoFiscal:        simNao(cfg.IncentivoFiscal),
+		}},
+	}
+	// No Simples Nacional a alíquota vem da guia DAS e não é informada na nota
+	if !cfg.OptanteSimplesNacional {
+		doc.Rps.InfDeclaracao.Servico.Valores.Aliquota = decimal(cfg.AliquotaISS)
+	}
+	return marshal(doc)
+}
+
+// ──────────────────────────────────────────────
+// CancelarNfseEnvio
+// ──────────────────────────────────────────────
+
+type cancelarNfseEnvio struct {
+	XMLName xml.Name `xml:"CancelarNfseEnvio"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Pedido  pedido   `xml:"Pedido"`
+}
+
+type pedido struct {
+	Inf infPedidoCancelamento `xml:"InfPedidoCancelamento"`
+}
+
+type infPedidoCancelamento struct {
+	ID                 string            `xml:"Id,attr"`
+	IdentificacaoNfse  identificacaoNfse `xml:"IdentificacaoNfse"`
+	CodigoCancelamento int               `xml:"CodigoCancelamento"`
+}
+
+type identificacaoNfse struct {
+	Numero             string  `xml:"Numero"`
+	CpfCnpj            cpfCnpj `xml:"CpfCnpj"`
+	InscricaoMunicipal string  `xml:"InscricaoMunicipal"`
+	CodigoMunicipio    string  `xml:"CodigoMunicipio"`
+}
+
+// BuildCancelarNfseEnvio gera o XML (sem assinatura) do pedido de cancelamento
+func BuildCancelarNfseEnvio(cfg *Config, req *ports.NFSeCancelRequest) ([]byte, error) {
+	if req.Number == "" {
+		return nil, fmt.Errorf("número da NFS-e é obrigatório")
+	}
+	code := req.Code
+	if code == 0 {
+		code = domain.NFSeCancelServiceNotDone
+	}
+
+	return marshal(cancelarNfseEnvio{
+		Xmlns: Namespace,
+		Pedido: pedido{Inf: infPedidoCancelamento{
+			ID: "cancel" + req.Number,
+			IdentificacaoNfse: identificacaoNfse{
+				Numero:             req.Number,
+				CpfCnpj:            cpfCnpj{Cnpj: cfg.CNPJ},
+				InscricaoMunicipal: cfg.InscricaoMunicipal,
+				CodigoMunicipio:    cfg.CodigoMunicipio,
+			},
+			CodigoCancelamento: int(code),
+		}},
+	})
+}
+
+// newTomador converte o tomador do domínio para o schema
+func newTomador(p *domain.FiscalParty) tomador {
+	t := tomador{RazaoSocial: p.Name}
+	if p.IsCompany() {
+		t.Identificacao.CpfCnpj.Cnpj = p.Document
+	} else {
+		t.Identificacao.CpfCnpj.Cpf = p.Document
+	}
+	if a := p.Address; a != nil {
+		t.Endereco = &endereco{
+			Endereco:        a.Street,
+			Numero:          a.Number,
+			Complemento:     a.Complement,
+			Bairro:          a.District,
+			CodigoMunicipio: a.CityCode,
+			Uf:              a.State,
+			Cep:             strings.ReplaceAll(a.ZIP, "-", ""),
+		}
+	}
+	if p.Email != "" {
+		t.Contato = &contato{Email: p.Email}
+	}
+	return t
+}
+
+// marshal serializa com a declaração XML
+func marshal(v interface{}) ([]byte, error) {
+	out, err := xml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar XML: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// decimal formata centavos (ou pontos-base) com duas casas e ponto decimal
+func decimal(v int) string {
+	return fmt.Sprintf("%d.%02d", v/100, v%100)
+}
+
+// simNao converte para o tsSimNao do schema
+func simNao(b bool) int {
+	if b {
+		return abrasfSim
+	}
+	return abrasfNao
+}
+
+// truncateRunes limita o texto a n caracteres sem quebrar UTF-8
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
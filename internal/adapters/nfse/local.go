@@ -0,0 +1,90 @@
+package nfse
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// LocalProvider é um ports.FiscalProvider de desenvolvimento que grava os XMLs
+// em um diretório. O número da NFS-e é o próprio número do RPS, então reenviar
+// um RPS devolve a mesma nota, como numa prefeitura de verdade.
+type LocalProvider struct {
+	cfg Config
+	dir string
+	mu  sync.Mutex
+}
+
+// NewLocalProvider cria o provider local gravando em dir (criado se não existir)
+func NewLocalProvider(cfg Config, dir string) (*LocalProvider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de NFS-e: %w", err)
+	}
+	return &LocalProvider{cfg: cfg, dir: dir}, nil
+}
+
+// IssueNFSe gera o XML do RPS e o grava como NFS-e autorizada
+func (p *LocalProvider) IssueNFSe(ctx context.Context, req *ports.NFSeIssueRequest) (*ports.NFSeIssueResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	number := strconv.FormatInt(req.RPSNumber, 10)
+	path := p.path("nfse", number)
+
+	// RPS já convertido: devolve a nota existente
+	if data, err := os.ReadFile(path); err == nil {
+		return &ports.NFSeIssueResult{Number: number, VerificationCode: verificationCode(data), XML: data}, nil
+	}
+
+	data, err := BuildGerarNfseEnvio(&p.cfg, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("erro ao gravar NFS-e: %w", err)
+	}
+	return &ports.NFSeIssueResult{Number: number, VerificationCode: verificationCode(data), XML: data}, nil
+}
+
+// CancelNFSe grava o pedido de cancelamento ao lado da nota
+func (p *LocalProvider) CancelNFSe(ctx context.Context, req *ports.NFSeCancelRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := os.Stat(p.path("nfse", req.Number)); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("NFS-e %s: %w", req.Number, domain.ErrNotFound)
+	}
+
+	data, err := BuildCancelarNfseEnvio(&p.cfg, req)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.path("cancelamento", req.Number), data, 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar cancelamento: %w", err)
+	}
+	return nil
+}
+
+// path monta o caminho de um XML no diretório do provider
+func (p *LocalProvider) path(kind, number string) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%s-%s.xml", kind, number))
+}
+
+// verificationCode deriva um código de verificação estável do XML
+func verificationCode(data []byte) string {
+	sum := sha256.Sum256(data)
+	return strings.ToUpper(hex.EncodeToString(sum[:4]))
+}
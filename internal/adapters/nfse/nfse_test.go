@@ -0,0 +1,162 @@
+package nfse
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+func testConfig() Config {
+	return Config{
+		CNPJ:               "12345678000199",
+		InscricaoMunicipal: "1234567",
+		CodigoMunicipio:    "3550308",
+		ItemListaServico:   "01.05",
+		AliquotaISS:        200,
+	}
+}
+
+func testRequest() *ports.NFSeIssueRequest {
+	date := time.Date(2026, 5, 1, 9, 0, 0, 0, domain.BillingLocation)
+	return &ports.NFSeIssueRequest{
+		RPSNumber:   42,
+		RPSSeries:   "A",
+		IssueDate:   date,
+		Competence:  date,
+		Amount:      19900,
+		Description: "Licença de uso do software BlackBelt & cia",
+		Tomador: domain.FiscalParty{
+			Document: "98765432000155",
+			Name:     "Academia Força Total Ltda",
+			Email:    "financeiro@forca.com.br",
+			Address: &domain.FiscalAddress{
+				Street: "Rua das Flores", Number: "100", District: "Centro",
+				CityCode: "3550308", State: "SP", ZIP: "01001-000",
+			},
+		},
+	}
+}
+
+func TestBuildGerarNfseEnvio(t *testing.T) {
+	cfg := testConfig()
+	out, err := BuildGerarNfseEnvio(&cfg, testRequest())
+	if err != nil {
+		t.Fatalf("BuildGerarNfseEnvio() error = %v", err)
+	}
+
+	// Lê de volta pelo caminho do schema
+	var parsed struct {
+		Inf struct {
+			ID  string `xml:"Id,attr"`
+			Rps struct {
+				Numero      int64  `xml:"IdentificacaoRps>Numero"`
+				Serie       string `xml:"IdentificacaoRps>Serie"`
+				DataEmissao string `xml:"DataEmissao"`
+			} `xml:"Rps"`
+			Servico struct {
+				ValorServicos string `xml:"Valores>ValorServicos"`
+				Aliquota      string `xml:"Valores>Aliquota"`
+				Item          string `xml:"ItemListaServico"`
+				Discriminacao string `xml:"Discriminacao"`
+			} `xml:"Servico"`
+			PrestadorCnpj string `xml:"Prestador>CpfCnpj>Cnpj"`
+			TomadorCnpj   string `xml:"TomadorServico>IdentificacaoTomador>CpfCnpj>Cnpj"`
+			TomadorCep    string `xml:"TomadorServico>Endereco>Cep"`
+		} `xml:"Rps>InfDeclaracaoPrestacaoServico"`
+	}
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("XML inválido: %v\n%s", err, out)
+	}
+
+	inf := parsed.Inf
+	if inf.ID != "rpsA42" || inf.Rps.Numero != 42 || inf.Rps.Serie != "A" || inf.Rps.DataEmissao != "2026-05-01" {
+		t.Errorf("RPS = %+v (Id %s)", inf.Rps, inf.ID)
+	}
+	if inf.Servico.ValorServicos != "199.00" || inf.Servico.Aliquota != "2.00" || inf.Servico.Item != "01.05" {
+		t.Errorf("Servico = %+v", inf.Servico)
+	}
+	if inf.Servico.Discriminacao != "Licença de uso do software BlackBelt & cia" {
+		t.Errorf("Discriminacao = %q", inf.Servico.Discriminacao)
+	}
+	if inf.PrestadorCnpj != cfg.CNPJ || inf.TomadorCnpj != "98765432000155" || inf.TomadorCep != "01001000" {
+		t.Errorf("prestador = %s, tomador = %s, cep = %s", inf.PrestadorCnpj, inf.TomadorCnpj, inf.TomadorCep)
+	}
+	if !strings.Contains(string(out), `xmlns="`+Namespace+`"`) {
+		t.Error("namespace ABRASF ausente")
+	}
+}
+
+func TestBuildGerarNfseEnvio_Validation(t *testing.T) {
+	cfg := testConfig()
+
+	tests := []struct {
+		name   string
+		mutate func(r *ports.NFSeIssueRequest)
+	}{
+		{"invalid document", func(r *ports.NFSeIssueRequest) { r.Tomador.Document = "123" }},
+		{"formatted document", func(r *ports.NFSeIssueRequest) { r.Tomador.Document = "987.654.320-01" }},
+		{"zero amount", func(r *ports.NFSeIssueRequest) { r.Amount = 0 }},
+		{"missing rps", func(r *ports.NFSeIssueRequest) { r.RPSNumber = 0 }},
+		{"missing description", func(r *ports.NFSeIssueRequest) { r.Description = " " }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testRequest()
+			tt.mutate(req)
+			if _, err := BuildGerarNfseEnvio(&cfg, req); err == nil {
+				t.Error("esperava erro de validação")
+			}
+		})
+	}
+
+	// Simples Nacional não informa alíquota
+	cfg.OptanteSimplesNacional = true
+	out, err := BuildGerarNfseEnvio(&cfg, testRequest())
+	if err != nil || strings.Contains(string(out), "<Aliquota>") || !strings.Contains(string(out), "<OptanteSimplesNacional>1<") {
+		t.Errorf("Simples Nacional: err = %v\n%s", err, out)
+	}
+}
+
+func TestLocalProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	p, err := NewLocalProvider(testConfig(), dir)
+	if err != nil {
+		t.Fatalf("NewLocalProvider() error = %v", err)
+	}
+
+	first, err := p.IssueNFSe(ctx, testRequest())
+	if err != nil {
+		t.Fatalf("IssueNFSe() error = %v", err)
+	}
+	if first.Number != "42" || len(first.VerificationCode) != 8 {
+		t.Errorf("NFS-e = %s / %s", first.Number, first.VerificationCode)
+	}
+
+	// Reenvio do mesmo RPS devolve a mesma nota
+	again, err := p.IssueNFSe(ctx, testRequest())
+	if err != nil || again.Number != first.Number || again.VerificationCode != first.VerificationCode {
+		t.Errorf("reenvio = %+v, %v", again, err)
+	}
+
+	if err := p.CancelNFSe(ctx, &ports.NFSeCancelRequest{Number: "42", Code: domain.NFSeCancelServiceNotDone}); err != nil {
+		t.Fatalf("CancelNFSe() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "cancelamento-42.xml"))
+	if err != nil || !strings.Contains(string(data), "<CodigoCancelamento>2</CodigoCancelamento>") {
+		t.Errorf("pedido de cancelamento = %s, %v", data, err)
+	}
+
+	if err := p.CancelNFSe(ctx, &ports.NFSeCancelRequest{Number: "99"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("cancelar nota inexistente: error = %v, want ErrNotFound", err)
+	}
+}
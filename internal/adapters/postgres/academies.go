@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// academyColumns são as colunas lidas por scanAcademy, na ordem (texto
+// opcional NULL vira "")
+const academyColumns = `
+	id, name, slug, COALESCE(description, ''), owner_id, COALESCE(document, ''),
+	COALESCE(phone, ''), COALESCE(email, ''), COALESCE(website, ''),
+	COALESCE(address_street, ''), COALESCE(address_number, ''), COALESCE(address_complement, ''),
+	COALESCE(address_district, ''), COALESCE(address_city, ''), COALESCE(address_city_code, ''),
+	COALESCE(address_state, ''), COALESCE(address_zip, ''), COALESCE(address_country, ''),
+	COALESCE(logo_url, ''), COALESCE(cover_url, ''), COALESCE(primary_color, ''),
+	invite_code, created_at, updated_at`
+
+// AcademyRepository implementa ports.AcademyService (e BrandingService e
+// FiscalDataService) na tabela academies
+type AcademyRepository struct {
+	db *DB
+}
+
+// NewAcademyRepository cria o repositório de academias
+func NewAcademyRepository(db *DB) *AcademyRepository {
+	return &AcademyRepository{db: db}
+}
+
+// Create insere a academia e vincula o perfil do dono a ela, na mesma transação.
+// O vínculo só vale para um perfil ainda sem academia: duas criações
+// simultâneas do mesmo dono não deixam uma academia órfã (a segunda falha com
+// domain.ErrOwnerHasAcademy e é desfeita).
+func (r *AcademyRepository) Create(ctx context.Context, academy *domain.Academy) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		a := academy
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO academies (
+				name, slug, description, owner_id, document, phone, email, website,
+				address_street, address_number, address_complement, address_district,
+				address_city, address_city_code, address_state, address_zip, address_country,
+				logo_url, cover_url, primary_color, invite_code, created_at, updated_at)
+			VALUES (
+				$1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''),
+				NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''),
+				NULLIF($13, ''), NULLIF($14, ''), NULLIF($15, ''), NULLIF($16, ''), $17,
+				NULLIF($18, ''), NULLIF($19, ''), $20, $21, $22, $23)
+			RETURNING id`,
+			a.Name, a.Slug, a.Description, a.OwnerID, a.Document, a.Phone, a.Email, a.Website,
+			a.Address.Street, a.Address.Number, a.Address.Complement, a.Address.District,
+			a.Address.City, a.Address.CityCode, a.Address.State, a.Address.ZIP, a.Address.Country,
+			a.LogoURL, a.CoverURL, a.PrimaryColor, a.InviteCode, a.CreatedAt, a.UpdatedAt,
+		).Scan(&a.ID); err != nil {
+			return fmt.Errorf("erro ao criar academia: %w", uniqueViolation(err, "academia "+a.Slug))
+		}
+
+		tag, err := r.db.conn(ctx).Exec(ctx,
+			`UPDATE profiles SET academy_id = $1, updated_at = $2 WHERE id = $3 AND academy_id IS NULL`,
+			a.ID, a.CreatedAt, a.OwnerID)
+		if err != nil {
+			return fmt.Errorf("erro ao vincular o dono à academia: %w", err)
+		}
+		if tag.RowsAffected() > 0 {
+			return nil
+		}
+
+		var exists bool
+		if err := r.db.conn(ctx).QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM profiles WHERE id = $1)`, a.OwnerID,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("erro ao buscar perfil %s: %w", a.OwnerID, err)
+		}
+		if !exists {
+			return fmt.Errorf("perfil %s: %w", a.OwnerID, domain.ErrNotFound)
+		}
+		return fmt.Errorf("perfil %s: %w", a.OwnerID, domain.ErrOwnerHasAcademy)
+	})
+}
+
+// GetByID busca academia pelo ID
+func (r *AcademyRepository) GetByID(ctx context.Context, academyID string) (*domain.Academy, error) {
+	return r.getOne(ctx, "id", academyID)
+}
+
+// GetBySlug busca academia pelo slug
+func (r *AcademyRepository) GetBySlug(ctx context.Context, slug string) (*domain.Academy, error) {
+	return r.getOne(ctx, "slug", slug)
+}
+
+// GetByInviteCode busca academia pelo código de convite
+func (r *AcademyRepository) GetByInviteCode(ctx context.Context, inviteCode string) (*domain.Academy, error) {
+	return r.getOne(ctx, "invite_code", inviteCode)
+}
+
+// GetBranding retorna nome, logo e cor da academia
+func (r *AcademyRepository) GetBranding(ctx context.Context, academyID string) (*domain.Branding, error) {
+	academy, err := r.GetByID(ctx, academyID)
+	if err != nil {
+		return nil, err
+	}
+	return academy.Branding(), nil
+}
+
+// GetFiscalParty retorna a academia como tomadora da NFS-e
+func (r *AcademyRepository) GetFiscalParty(ctx context.Context, academyID string) (*domain.FiscalParty, error) {
+	academy, err := r.GetByID(ctx, academyID)
+	if err != nil {
+		return nil, err
+	}
+	return academy.FiscalParty()
+}
+
+// getOne busca uma academia pela coluna única informada
+func (r *AcademyRepository) getOne(ctx context.Context, column, value string) (*domain.Academy, error) {
+	academy, err := scanAcademy(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+academyColumns+` FROM academies WHERE `+column+` = $1`, value))
+	if err != nil {
+		return nil, notFound(err, "academia", value)
+	}
+	return academy, nil
+}
+
+// scanAcademy lê uma linha com academyColumns
+func scanAcademy(row pgx.Row) (*domain.Academy, error) {
+	var a domain.Academy
+	if err := row.Scan(
+		&a.ID, &a.Name, &a.Slug, &a.Description, &a.OwnerID, &a.Document,
+		&a.Phone, &a.Email, &a.Website,
+		&a.Address.Street, &a.Address.Number, &a.Address.Complement,
+		&a.Address.District, &a.Address.City, &a.Address.CityCode,
+		&a.Address.State, &a.Address.ZIP, &a.Address.Country,
+		&a.LogoURL, &a.CoverURL, &a.PrimaryColor,
+		&a.InviteCode, &a.CreatedAt, &a.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Garante que AcademyRepository implementa as interfaces
+var (
+	_ ports.AcademyService    = (*AcademyRepository)(nil)
+	_ ports.BrandingService   = (*AcademyRepository)(nil)
+	_ ports.FiscalDataService = (*AcademyRepository)(nil)
+)
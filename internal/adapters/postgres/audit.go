@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// auditColumns são as colunas lidas por scanAudit, na ordem
+const auditColumns = `
+	id, academy_id, seq, actor_type, actor_id, action, aggregate_type, aggregate_id,
+	diff, request_id, occurred_at, prev_hash, hash`
+
+// AuditLog implementa ports.AuditLog na tabela audit_log (append-only: um
+// trigger rejeita UPDATE e DELETE)
+type AuditLog struct {
+	db *DB
+}
+
+// NewAuditLog cria o audit log
+func NewAuditLog(db *DB) *AuditLog {
+	return &AuditLog{db: db}
+}
+
+// Append encadeia e grava o registro. Um advisory lock por academia (até o fim
+// da transação) serializa os appends, para dois registros não disputarem o mesmo Seq.
+func (l *AuditLog) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	return l.db.Do(ctx, func(ctx context.Context) error {
+		conn := l.db.conn(ctx)
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, "audit:"+entry.AcademyID); err != nil {
+			return fmt.Errorf("erro ao travar audit log: %w", err)
+		}
+
+		prev, err := scanAudit(conn.QueryRow(ctx, `
+			SELECT `+auditColumns+` FROM audit_log WHERE academy_id = $1
+			ORDER BY seq DESC LIMIT 1`, entry.AcademyID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			prev = nil
+		} else if err != nil {
+			return fmt.Errorf("erro ao buscar último registro de auditoria: %w", err)
+		}
+		entry.Chain(prev)
+
+		idArg := any(nil)
+		if entry.ID != "" {
+			idArg = entry.ID
+		}
+		var actorID *string
+		if entry.Actor.ID != "" {
+			actorID = &entry.Actor.ID
+		}
+		var requestID *string
+		if entry.RequestID != "" {
+			requestID = &entry.RequestID
+		}
+		if err := conn.QueryRow(ctx, `
+			INSERT INTO audit_log (id, academy_id, seq, actor_type, actor_id, action, aggregate_type, aggregate_id,
+				diff, request_id, occurred_at, prev_hash, hash)
+			VALUES (COALESCE($1, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			RETURNING id`,
+			idArg, entry.AcademyID, entry.Seq, entry.Actor.Type, actorID, string(entry.Action),
+			entry.AggregateType, entry.AggregateID, nullableBytes(entry.Diff), requestID,
+			entry.OccurredAt, entry.PrevHash, entry.Hash,
+		).Scan(&entry.ID); err != nil {
+			return fmt.Errorf("erro ao gravar registro de auditoria: %w", err)
+		}
+		return nil
+	})
+}
+
+// List lista os registros da academia com seq > afterSeq, em ordem de seq
+func (l *AuditLog) List(ctx context.Context, academyID string, afterSeq int64, limit int) ([]*domain.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE academy_id = $1 AND seq > $2 ORDER BY seq`
+	args := []any{academyID, afterSeq}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+
+	rows, err := l.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAudit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler registro de auditoria: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// scanAudit lê uma linha com auditColumns
+func scanAudit(row pgx.Row) (*domain.AuditEntry, error) {
+	var (
+		e                  domain.AuditEntry
+		action             string
+		actorID, requestID *string
+		diff               []byte
+	)
+	if err := row.Scan(
+		&e.ID, &e.AcademyID, &e.Seq, &e.Actor.Type, &actorID, &action, &e.AggregateType, &e.AggregateID,
+		&diff, &requestID, &e.OccurredAt, &e.PrevHash, &e.Hash,
+	); err != nil {
+		return nil, err
+	}
+	e.Action = domain.AuditAction(action)
+	if actorID != nil {
+		e.Actor.ID = *actorID
+	}
+	if requestID != nil {
+		e.RequestID = *requestID
+	}
+	if len(diff) > 0 {
+		e.Diff = diff
+	}
+	return &e, nil
+}
+
+// Garante que AuditLog implementa ports.AuditLog
+var _ ports.AuditLog = (*AuditLog)(nil)
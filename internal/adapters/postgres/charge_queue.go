@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// ChargeQueue implementa ports.ChargeQueue na tabela pending_charges.
+// A requisição original e o resultado ficam em JSONB.
+type ChargeQueue struct {
+	db *DB
+}
+
+// NewChargeQueue cria a fila durável de cobranças adiadas
+func NewChargeQueue(db *DB) *ChargeQueue {
+	return &ChargeQueue{db: db}
+}
+
+const pendingChargeColumns = `id, academy_id, kind, status, request, result, attempts, last_error, enqueued_at, next_attempt_at`
+
+// Enqueue adiciona uma cobrança à fila (gera o ID se vazio)
+func (q *ChargeQueue) Enqueue(ctx context.Context, charge *ports.PendingCharge) error {
+	request, result, err := pendingChargePayloads(charge)
+	if err != nil {
+		return err
+	}
+	var id *string
+	if charge.ID != "" {
+		id = &charge.ID
+	}
+	if err := q.db.conn(ctx).QueryRow(ctx, `
+		INSERT INTO pending_charges (id, academy_id, kind, status, request, result, attempts, last_error, enqueued_at, next_attempt_at)
+		VALUES (COALESCE($1, gen_random_uuid()), $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10)
+		RETURNING id`,
+		id, charge.AcademyID, string(charge.Kind), string(charge.Status), request, result,
+		charge.Attempts, charge.LastError, charge.EnqueuedAt, charge.NextAttemptAt,
+	).Scan(&charge.ID); err != nil {
+		return fmt.Errorf("erro ao enfileirar cobrança: %w", err)
+	}
+	return nil
+}
+
+// Get busca uma cobrança adiada pelo ID
+func (q *ChargeQueue) Get(ctx context.Context, id string) (*ports.PendingCharge, error) {
+	charge, err := scanPendingCharge(q.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+pendingChargeColumns+` FROM pending_charges WHERE id = $1`, id))
+	if err != nil {
+		return nil, notFound(err, "cobrança pendente", id)
+	}
+	return charge, nil
+}
+
+// ListDue lista cobranças enfileiradas com tentativa vencida, das mais antigas para as mais novas
+func (q *ChargeQueue) ListDue(ctx context.Context, now time.Time, limit int) ([]*ports.PendingCharge, error) {
+	query := `SELECT ` + pendingChargeColumns + ` FROM pending_charges
+		WHERE status = 'queued' AND next_attempt_at <= $1
+		ORDER BY enqueued_at`
+	args := []any{now}
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit)
+	}
+
+	rows, err := q.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar cobranças pendentes: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []*ports.PendingCharge
+	for rows.Next() {
+		charge, err := scanPendingCharge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler cobrança pendente: %w", err)
+		}
+		charges = append(charges, charge)
+	}
+	return charges, rows.Err()
+}
+
+// Update persiste status, tentativas e resultado de uma cobrança existente
+func (q *ChargeQueue) Update(ctx context.Context, charge *ports.PendingCharge) error {
+	_, result, err := pendingChargePayloads(charge)
+	if err != nil {
+		return err
+	}
+	tag, err := q.db.conn(ctx).Exec(ctx, `
+		UPDATE pending_charges
+		SET status = $2, result = $3, attempts = $4, last_error = NULLIF($5, ''), next_attempt_at = $6
+		WHERE id = $1`,
+		charge.ID, string(charge.Status), result, charge.Attempts, charge.LastError, charge.NextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar cobrança pendente %s: %w", charge.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("cobrança pendente %s: %w", charge.ID, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// pendingChargePayloads serializa a requisição e o resultado conforme o tipo
+func pendingChargePayloads(charge *ports.PendingCharge) (request, result []byte, err error) {
+	switch charge.Kind {
+	case ports.PendingChargeKindPixCharge:
+		if request, err = toJSON(charge.Charge); err != nil {
+			return nil, nil, err
+		}
+		result, err = toJSON(charge.ChargeResult)
+	case ports.PendingChargeKindPixRecurrence:
+		if request, err = toJSON(charge.Recurrence); err != nil {
+			return nil, nil, err
+		}
+		result, err = toJSON(charge.RecurrenceResult)
+	default:
+		return nil, nil, fmt.Errorf("tipo de cobrança desconhecido: %s", charge.Kind)
+	}
+	return request, result, err
+}
+
+// scanPendingCharge lê uma linha de pending_charges
+func scanPendingCharge(row pgx.Row) (*ports.PendingCharge, error) {
+	var (
+		c               ports.PendingCharge
+		kind, status    string
+		request, result []byte
+		lastError       *string
+	)
+	if err := row.Scan(&c.ID, &c.AcademyID, &kind, &status, &request, &result,
+		&c.Attempts, &lastError, &c.EnqueuedAt, &c.NextAttemptAt); err != nil {
+		return nil, err
+	}
+	c.Kind = ports.PendingChargeKind(kind)
+	c.Status = ports.PendingChargeStatus(status)
+	if lastError != nil {
+		c.LastError = *lastError
+	}
+
+	switch c.Kind {
+	case ports.PendingChargeKindPixCharge:
+		if err := fromJSON(request, &c.Charge); err != nil {
+			return nil, err
+		}
+		if err := fromJSON(result, &c.ChargeResult); err != nil {
+			return nil, err
+		}
+	case ports.PendingChargeKindPixRecurrence:
+		if err := fromJSON(request, &c.Recurrence); err != nil {
+			return nil, err
+		}
+		if err := fromJSON(result, &c.RecurrenceResult); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// Garante que ChargeQueue implementa ports.ChargeQueue
+var _ ports.ChargeQueue = (*ChargeQueue)(nil)
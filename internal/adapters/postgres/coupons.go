@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// couponColumns são as colunas lidas por scanCoupon, na ordem
+const couponColumns = `
+	id, code, type, percent_off, amount_off, duration, duration_cycles,
+	max_redemptions, times_redeemed, expires_at, plan_ids, is_active, stripe_coupon_id, created_at`
+
+// CouponRepository implementa ports.CouponService na tabela coupons
+type CouponRepository struct {
+	db *DB
+}
+
+// NewCouponRepository cria o repositório de cupons
+func NewCouponRepository(db *DB) *CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+// Create cria um cupom (gera o ID se vazio)
+func (r *CouponRepository) Create(ctx context.Context, c *domain.Coupon) error {
+	var id *string
+	if c.ID != "" {
+		id = &c.ID
+	}
+	planIDs := c.PlanIDs
+	if planIDs == nil {
+		planIDs = []string{}
+	}
+	err := r.db.conn(ctx).QueryRow(ctx, `
+		INSERT INTO coupons (id, code, type, percent_off, amount_off, duration, duration_cycles,
+			max_redemptions, times_redeemed, expires_at, plan_ids, is_active, stripe_coupon_id, created_at)
+		VALUES (COALESCE($1, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`,
+		id, c.Code, string(c.Type), c.PercentOff, c.AmountOff, string(c.Duration), c.DurationCycles,
+		c.MaxRedemptions, c.TimesRedeemed, c.ExpiresAt, planIDs, c.IsActive, c.StripeCouponID, c.CreatedAt,
+	).Scan(&c.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao criar cupom: %w", uniqueViolation(err, "cupom "+c.Code))
+	}
+	return nil
+}
+
+// GetByCode busca cupom pelo código (já normalizado)
+func (r *CouponRepository) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	coupon, err := scanCoupon(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+couponColumns+` FROM coupons WHERE code = $1`, code))
+	if err != nil {
+		return nil, notFound(err, "cupom", code)
+	}
+	return coupon, nil
+}
+
+// Redeem incrementa times_redeemed no próprio UPDATE, que só passa enquanto
+// houver resgates disponíveis: dois resgates simultâneos não furam o limite
+func (r *CouponRepository) Redeem(ctx context.Context, couponID string) error {
+	tag, err := r.db.conn(ctx).Exec(ctx, `
+		UPDATE coupons SET times_redeemed = times_redeemed + 1
+		WHERE id = $1 AND (max_redemptions IS NULL OR times_redeemed < max_redemptions)`, couponID)
+	if err != nil {
+		return fmt.Errorf("erro ao resgatar cupom: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missingOrExhausted(ctx, couponID)
+	}
+	return nil
+}
+
+// Release devolve um resgate de Redeem
+func (r *CouponRepository) Release(ctx context.Context, couponID string) error {
+	tag, err := r.db.conn(ctx).Exec(ctx, `
+		UPDATE coupons SET times_redeemed = times_redeemed - 1
+		WHERE id = $1 AND times_redeemed > 0`, couponID)
+	if err != nil {
+		return fmt.Errorf("erro ao devolver resgate do cupom: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("cupom %s sem resgates: %w", couponID, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// missingOrExhausted diferencia cupom inexistente de cupom esgotado
+func (r *CouponRepository) missingOrExhausted(ctx context.Context, couponID string) error {
+	var code string
+	if err := r.db.conn(ctx).QueryRow(ctx, `SELECT code FROM coupons WHERE id = $1`, couponID).Scan(&code); err != nil {
+		return notFound(err, "cupom", couponID)
+	}
+	return fmt.Errorf("%w: cupom %s esgotado", domain.ErrCouponInvalid, code)
+}
+
+// scanCoupon lê uma linha com couponColumns
+func scanCoupon(row pgx.Row) (*domain.Coupon, error) {
+	var (
+		c          domain.Coupon
+		couponType string
+		duration   string
+	)
+	if err := row.Scan(
+		&c.ID, &c.Code, &couponType, &c.PercentOff, &c.AmountOff, &duration, &c.DurationCycles,
+		&c.MaxRedemptions, &c.TimesRedeemed, &c.ExpiresAt, &c.PlanIDs, &c.IsActive, &c.StripeCouponID, &c.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	c.Type = domain.CouponType(couponType)
+	c.Duration = domain.CouponDuration(duration)
+	if len(c.PlanIDs) == 0 {
+		c.PlanIDs = nil
+	}
+	return &c, nil
+}
+
+// Garante que CouponRepository implementa ports.CouponService
+var _ ports.CouponService = (*CouponRepository)(nil)
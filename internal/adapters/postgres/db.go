@@ -0,0 +1,170 @@
+// Package postgres implementa as portas de persistência sobre PostgreSQL (pgx).
+//
+// Os repositórios seguem as tabelas de docs/DATA_MODEL.md. Campos opcionais do
+// domínio (*string, *time.Time) mapeiam para colunas NULL; estruturas aninhadas
+// da assinatura (pausas, desconto, histórico de transições) ficam em JSONB.
+//
+// Transações usam unit of work: DB.Do abre uma transação e a coloca no
+// contexto, e todo repositório chamado com esse contexto participa dela. Os
+// eventos de domínio vão para o outbox na mesma transação da entidade.
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// querier é a parte comum de *pgxpool.Pool e pgx.Tx usada pelos repositórios
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// txKey guarda a transação corrente no contexto
+type txKey struct{}
+
+// DB é o pool de conexões compartilhado pelos repositórios
+type DB struct {
+	pool  *pgxpool.Pool
+	clock domain.Clock
+}
+
+// Open conecta ao banco e verifica a conexão
+func Open(ctx context.Context, databaseURL string) (*DB, error) {
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar conexão com o banco: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("erro ao conectar ao banco: %w", err)
+	}
+	return NewDB(pool), nil
+}
+
+// NewDB usa um pool já configurado
+func NewDB(pool *pgxpool.Pool) *DB {
+	return &DB{pool: pool, clock: domain.SystemClock{}}
+}
+
+// SetClock troca o relógio usado nas regras de negócio dos repositórios (testes)
+func (db *DB) SetClock(clock domain.Clock) {
+	db.clock = clock
+}
+
+// Pool expõe o pool de conexões (migrations, health check)
+func (db *DB) Pool() *pgxpool.Pool {
+	return db.pool
+}
+
+// Close fecha o pool de conexões
+func (db *DB) Close() {
+	db.pool.Close()
+}
+
+// Do executa fn em uma transação (ports.UnitOfWork). Repositórios chamados com
+// o ctx recebido participam dela; um Do aninhado reaproveita a transação
+// externa. Erro ou panic em fn desfazem tudo.
+func (db *DB) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// conn devolve a transação do contexto ou, fora de uma, o pool
+func (db *DB) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db.pool
+}
+
+// notFound traduz pgx.ErrNoRows para domain.ErrNotFound
+func notFound(err error, entity, key string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s %s: %w", entity, key, domain.ErrNotFound)
+	}
+	return fmt.Errorf("erro ao buscar %s %s: %w", entity, key, err)
+}
+
+// uniqueViolation traduz a violação de UNIQUE (23505) para domain.ErrAlreadyExists
+func uniqueViolation(err error, entity string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%s: %w", entity, domain.ErrAlreadyExists)
+	}
+	return err
+}
+
+// versionConflict explica um UPDATE com versão que não afetou linhas: o
+// registro não existe (domain.ErrNotFound) ou mudou desde a leitura
+// (domain.ErrConcurrentModification)
+func (db *DB) versionConflict(ctx context.Context, table, entity, id string) error {
+	var exists bool
+	if err := db.conn(ctx).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("erro ao buscar %s %s: %w", entity, id, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s %s: %w", entity, id, domain.ErrNotFound)
+	}
+	return fmt.Errorf("%s %s: %w", entity, id, domain.ErrConcurrentModification)
+}
+
+// toJSON serializa um valor para uma coluna JSONB (nil vira NULL)
+func toJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar JSON: %w", err)
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// fromJSON desserializa uma coluna JSONB (NULL mantém o zero value)
+func fromJSON(data []byte, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("erro ao ler JSON: %w", err)
+	}
+	return nil
+}
+
+// Garante que DB implementa UnitOfWork
+var _ ports.UnitOfWork = (*DB)(nil)
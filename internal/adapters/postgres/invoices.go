@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// invoiceColumns são as colunas lidas por scanInvoice, na ordem
+const invoiceColumns = `
+	id, academy_id, subscription_id, payment_id, number, status, currency,
+	period_start, period_end, lines, total, issued_at, created_at`
+
+// InvoiceRepository implementa ports.InvoiceService nas tabelas invoices e
+// invoice_counters
+type InvoiceRepository struct {
+	db *DB
+}
+
+// NewInvoiceRepository cria o repositório de faturas
+func NewInvoiceRepository(db *DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// Create incrementa o contador da academia e insere a fatura na mesma
+// transação. O UPSERT trava a linha do contador até o commit: faturas
+// simultâneas da mesma academia recebem números seguidos, e uma inserção que
+// falha (fatura repetida do pagamento) devolve o número no rollback.
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		var number int64
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO invoice_counters (academy_id, last_number) VALUES ($1, 1)
+			ON CONFLICT (academy_id) DO UPDATE SET last_number = invoice_counters.last_number + 1
+			RETURNING last_number`, invoice.AcademyID,
+		).Scan(&number); err != nil {
+			return fmt.Errorf("erro ao numerar fatura: %w", err)
+		}
+
+		lines, err := toJSON(invoice.Lines)
+		if err != nil {
+			return err
+		}
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO invoices (academy_id, subscription_id, payment_id, number, status, currency,
+				period_start, period_end, lines, total, issued_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id`,
+			invoice.AcademyID, invoice.SubscriptionID, invoice.PaymentID, number, string(invoice.Status),
+			invoice.Currency, invoice.PeriodStart, invoice.PeriodEnd, lines, invoice.Total,
+			invoice.IssuedAt, invoice.CreatedAt,
+		).Scan(&invoice.ID); err != nil {
+			return fmt.Errorf("erro ao criar fatura: %w", uniqueViolation(err, "fatura do pagamento "+invoice.PaymentID))
+		}
+		invoice.Number = number
+		return nil
+	})
+}
+
+// GetByID obtém uma fatura pelo ID
+func (r *InvoiceRepository) GetByID(ctx context.Context, invoiceID string) (*domain.Invoice, error) {
+	invoice, err := scanInvoice(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, invoiceID))
+	if err != nil {
+		return nil, notFound(err, "fatura", invoiceID)
+	}
+	return invoice, nil
+}
+
+// GetByPaymentID obtém a fatura de um pagamento
+func (r *InvoiceRepository) GetByPaymentID(ctx context.Context, paymentID string) (*domain.Invoice, error) {
+	invoice, err := scanInvoice(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE payment_id = $1`, paymentID))
+	if err != nil {
+		return nil, notFound(err, "fatura do pagamento", paymentID)
+	}
+	return invoice, nil
+}
+
+// ListByAcademy lista as faturas da academia, da mais recente para a mais antiga
+func (r *InvoiceRepository) ListByAcademy(ctx context.Context, academyID string) ([]*domain.Invoice, error) {
+	rows, err := r.db.conn(ctx).Query(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE academy_id = $1 ORDER BY number DESC`, academyID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar faturas: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []*domain.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler fatura: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+// Save grava o status da fatura (número e itens não mudam depois de emitida)
+func (r *InvoiceRepository) Save(ctx context.Context, invoice *domain.Invoice) error {
+	tag, err := r.db.conn(ctx).Exec(ctx,
+		`UPDATE invoices SET status = $2 WHERE id = $1`, invoice.ID, string(invoice.Status))
+	if err != nil {
+		return fmt.Errorf("erro ao salvar fatura: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("fatura %s: %w", invoice.ID, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// scanInvoice lê uma linha com invoiceColumns
+func scanInvoice(row pgx.Row) (*domain.Invoice, error) {
+	var (
+		inv    domain.Invoice
+		status string
+		lines  []byte
+	)
+	if err := row.Scan(
+		&inv.ID, &inv.AcademyID, &inv.SubscriptionID, &inv.PaymentID, &inv.Number, &status, &inv.Currency,
+		&inv.PeriodStart, &inv.PeriodEnd, &lines, &inv.Total, &inv.IssuedAt, &inv.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	inv.Status = domain.InvoiceStatus(status)
+	if err := fromJSON(lines, &inv.Lines); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Garante que InvoiceRepository implementa ports.InvoiceService
+var _ ports.InvoiceService = (*InvoiceRepository)(nil)
@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationFiles são as migrations versionadas do schema (docs/DATA_MODEL.md),
+// no formato NNNNNN_nome.up.sql / NNNNNN_nome.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID identifica o advisory lock que serializa migrations
+// concorrentes (várias instâncias subindo ao mesmo tempo)
+const migrationLockID = 7_261_414_201
+
+var (
+	// ErrSchemaAhead indica que o banco tem migrations que este binário não conhece
+	// (deploy de uma versão antiga sobre um banco já migrado)
+	ErrSchemaAhead = errors.New("schema do banco está à frente do binário")
+
+	// ErrPendingMigrations indica que há migrations do binário ainda não aplicadas
+	ErrPendingMigrations = errors.New("há migrations pendentes")
+)
+
+// Migration é uma versão do schema com o SQL de ida e de volta
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus descreve o estado do banco em relação às migrations embutidas
+type MigrationStatus struct {
+	Current int64   // Última versão aplicada (0 = banco vazio)
+	Latest  int64   // Última versão embutida no binário
+	Pending []int64 // Versões embutidas ainda não aplicadas
+	Unknown []int64 // Versões aplicadas que o binário não conhece
+}
+
+// Migrator aplica e desfaz as migrations embutidas, registrando as versões
+// aplicadas em schema_migrations
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+}
+
+// NewMigrator cria o runner com as migrations embutidas no binário
+func NewMigrator(db *DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Migrations lista as migrations embutidas em ordem de versão
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Status compara as versões aplicadas com as embutidas
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	if err := m.ensureTable(ctx, m.db.conn(ctx)); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(ctx, m.db.conn(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatus(m.migrations, applied), nil
+}
+
+// Check falha com ErrSchemaAhead se o banco estiver à frente do binário e com
+// ErrPendingMigrations se houver migrations a aplicar
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return status.err()
+}
+
+// Up aplica as migrations pendentes, cada uma na própria transação. Recusa
+// rodar se o banco estiver à frente do binário.
+func (m *Migrator) Up(ctx context.Context) (applied []int64, err error) {
+	err = m.withLock(ctx, func(conn *pgx.Conn) error {
+		status, err := m.statusOn(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(status.Unknown) > 0 {
+			return status.err()
+		}
+
+		pending := make(map[int64]bool, len(status.Pending))
+		for _, v := range status.Pending {
+			pending[v] = true
+		}
+		for _, mig := range m.migrations {
+			if !pending[mig.Version] {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			applied = append(applied, mig.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down desfaz as últimas steps migrations aplicadas, da mais nova para a mais antiga
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []int64, err error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("número de migrations a desfazer deve ser positivo: %d", steps)
+	}
+	err = m.withLock(ctx, func(conn *pgx.Conn) error {
+		status, err := m.statusOn(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(status.Unknown) > 0 {
+			return status.err()
+		}
+
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		byVersion := make(map[int64]Migration, len(m.migrations))
+		for _, mig := range m.migrations {
+			byVersion[mig.Version] = mig
+		}
+		for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+			if err := m.apply(ctx, conn, byVersion[applied[i]], false); err != nil {
+				return err
+			}
+			reverted = append(reverted, applied[i])
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// apply executa o SQL de ida (up) ou de volta da migration e registra a versão
+func (m *Migrator) apply(ctx context.Context, conn *pgx.Conn, mig Migration, up bool) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação da migration %d: %w", mig.Version, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, direction := mig.Up, "up"
+	if !up {
+		sql, direction = mig.Down, "down"
+	}
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("erro na migration %d_%s (%s): %w", mig.Version, mig.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+			mig.Version, mig.Name, m.db.clock.Now())
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao registrar a migration %d: %w", mig.Version, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// withLock executa fn em uma conexão dedicada com o advisory lock de migrations
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	pooled, err := m.db.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao obter conexão para migrations: %w", err)
+	}
+	defer pooled.Release()
+	conn := pooled.Conn()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("erro ao obter lock de migrations: %w", err)
+	}
+	defer func() { _, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID) }()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// statusOn calcula o status na conexão que detém o lock
+func (m *Migrator) statusOn(ctx context.Context, conn *pgx.Conn) (*MigrationStatus, error) {
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatus(m.migrations, applied), nil
+}
+
+// ensureTable cria a tabela de controle se ainda não existir
+func (m *Migrator) ensureTable(ctx context.Context, q querier) error {
+	if _, err := q.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`); err != nil {
+		return fmt.Errorf("erro ao criar schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions lista as versões aplicadas em ordem crescente
+func appliedVersions(ctx context.Context, q querier) ([]int64, error) {
+	rows, err := q.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int64
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// migrationStatus compara as versões aplicadas (em ordem) com as embutidas
+func migrationStatus(migrations []Migration, applied []int64) *MigrationStatus {
+	status := &MigrationStatus{}
+	known := make(map[int64]bool, len(migrations))
+	for _, mig := range migrations {
+		known[mig.Version] = true
+		status.Latest = mig.Version
+	}
+
+	done := make(map[int64]bool, len(applied))
+	for _, v := range applied {
+		done[v] = true
+		if v > status.Current {
+			status.Current = v
+		}
+		if !known[v] {
+			status.Unknown = append(status.Unknown, v)
+		}
+	}
+	for _, mig := range migrations {
+		if !done[mig.Version] {
+			status.Pending = append(status.Pending, mig.Version)
+		}
+	}
+	return status
+}
+
+// err traduz o status no erro de Check (nil = banco em dia)
+func (s *MigrationStatus) err() error {
+	if len(s.Unknown) > 0 {
+		return fmt.Errorf("%w: versão %d aplicada, binário conhece até %d (desconhecidas: %v)",
+			ErrSchemaAhead, s.Current, s.Latest, s.Unknown)
+	}
+	if len(s.Pending) > 0 {
+		return fmt.Errorf("%w: %v", ErrPendingMigrations, s.Pending)
+	}
+	return nil
+}
+
+// loadMigrations lê os pares up/down de dir, exigindo os dois arquivos de cada versão
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		version, name, direction, err := parseMigrationName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("migration %d com nomes diferentes: %s e %s", version, mig.Name, name)
+		}
+		if direction == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s precisa de .up.sql e .down.sql", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseMigrationName separa "000001_init_schema.up.sql" em versão, nome e direção
+func parseMigrationName(file string) (version int64, name, direction string, err error) {
+	base := strings.TrimSuffix(file, ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("migration %s: esperado NNNNNN_nome.up.sql ou .down.sql", file)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	prefix, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("migration %s: esperado NNNNNN_nome.up.sql ou .down.sql", file)
+	}
+	version, err = strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %s: versão inválida %q", file, prefix)
+	}
+	return version, name, direction, nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("nenhuma migration embutida")
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("migration %d tem versão %d: versões devem ser sequenciais", i, mig.Version)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "ordena por versão",
+			files: fstest.MapFS{
+				"m/000002_b.up.sql":   {Data: []byte("B")},
+				"m/000002_b.down.sql": {Data: []byte("-B")},
+				"m/000001_a.up.sql":   {Data: []byte("A")},
+				"m/000001_a.down.sql": {Data: []byte("-A")},
+			},
+			versions: []int64{1, 2},
+		},
+		{
+			name: "sem down",
+			files: fstest.MapFS{
+				"m/000001_a.up.sql": {Data: []byte("A")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "nome fora do padrão",
+			files: fstest.MapFS{
+				"m/init.sql": {Data: []byte("A")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "nomes diferentes na mesma versão",
+			files: fstest.MapFS{
+				"m/000001_a.up.sql":   {Data: []byte("A")},
+				"m/000001_b.down.sql": {Data: []byte("-A")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files, "m")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			var versions []int64
+			for _, mig := range migrations {
+				versions = append(versions, mig.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("versões = %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	tests := []struct {
+		name    string
+		applied []int64
+		want    error
+	}{
+		{"banco vazio", nil, ErrPendingMigrations},
+		{"parcial", []int64{1, 2}, ErrPendingMigrations},
+		{"em dia", []int64{1, 2, 3}, nil},
+		{"banco à frente", []int64{1, 2, 3, 4}, ErrSchemaAhead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := migrationStatus(migrations, tt.applied).err()
+			if !errors.Is(err, tt.want) && !(err == nil && tt.want == nil) {
+				t.Errorf("erro = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	latest := m.Migrations()[len(m.Migrations())-1].Version
+
+	// testDB já aplicou tudo: Up não faz nada
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("Up em banco migrado = %v, %v", applied, err)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil || !reflect.DeepEqual(reverted, []int64{latest}) {
+		t.Fatalf("Down(1) = %v, %v; want [%d]", reverted, err, latest)
+	}
+	if err := m.Check(ctx); !errors.Is(err, ErrPendingMigrations) {
+		t.Errorf("Check após Down = %v, want ErrPendingMigrations", err)
+	}
+	if applied, err := m.Up(ctx); err != nil || !reflect.DeepEqual(applied, []int64{latest}) {
+		t.Fatalf("Up = %v, %v; want [%d]", applied, err, latest)
+	}
+
+	// Versão aplicada por um binário mais novo
+	if _, err := db.pool.Exec(ctx,
+		`INSERT INTO schema_migrations (version, name) VALUES ($1, 'futura')`, latest+1); err != nil {
+		t.Fatalf("inserir versão futura: %v", err)
+	}
+	if err := m.Check(ctx); !errors.Is(err, ErrSchemaAhead) {
+		t.Errorf("Check = %v, want ErrSchemaAhead", err)
+	}
+	if _, err := m.Up(ctx); !errors.Is(err, ErrSchemaAhead) {
+		t.Errorf("Up = %v, want ErrSchemaAhead", err)
+	}
+}
@@ -0,0 +1,15 @@
+-- profiles e auth.users pertencem ao Supabase/mobile e não são removidas;
+-- o CASCADE em academies remove só a FK profiles.academy_id
+
+DROP TABLE IF EXISTS webhook_events CASCADE;
+DROP TABLE IF EXISTS payment_history CASCADE;
+DROP TABLE IF EXISTS subscriptions CASCADE;
+DROP TABLE IF EXISTS subscription_plans CASCADE;
+DROP TABLE IF EXISTS academies CASCADE;
+
+DROP FUNCTION IF EXISTS update_updated_at();
+
+DROP TYPE IF EXISTS webhook_status;
+DROP TYPE IF EXISTS payment_status;
+DROP TYPE IF EXISTS payment_gateway;
+DROP TYPE IF EXISTS subscription_status;
@@ -0,0 +1,218 @@
+-- Base: perfis, academias, planos, assinaturas, pagamentos e webhooks
+
+CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+
+-- Fora do Supabase (Postgres local, CI) não existe auth.users: cria uma
+-- versão mínima para a FK de profiles. No Supabase isto não faz nada.
+DO $$
+BEGIN
+    IF to_regclass('auth.users') IS NULL THEN
+        CREATE SCHEMA IF NOT EXISTS auth;
+        CREATE TABLE auth.users (id UUID PRIMARY KEY);
+    END IF;
+END
+$$;
+
+-- Enums
+CREATE TYPE subscription_status AS ENUM ('trialing', 'active', 'past_due', 'canceled', 'expired', 'suspended', 'paused');
+CREATE TYPE payment_gateway AS ENUM ('pix_auto', 'stripe');
+CREATE TYPE payment_status AS ENUM ('pending', 'processing', 'succeeded', 'failed', 'partially_refunded', 'refunded');
+CREATE TYPE webhook_status AS ENUM ('pending', 'processing', 'processed', 'failed', 'skipped');
+
+-- 1. profiles (já existe no projeto mobile; criada só se faltar)
+CREATE TABLE IF NOT EXISTS profiles (
+    id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    full_name TEXT,
+    avatar_url TEXT,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'professor', 'student')),
+    belt_color TEXT,
+    belt_degrees INTEGER DEFAULT 0,
+    academy_id UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 2. academies
+CREATE TABLE academies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    slug TEXT UNIQUE NOT NULL,
+    description TEXT,
+    owner_id UUID NOT NULL REFERENCES profiles(id),
+    phone TEXT,
+    email TEXT,
+    website TEXT,
+    address_street TEXT,
+    address_city TEXT,
+    address_state TEXT,
+    address_zip TEXT,
+    address_country TEXT DEFAULT 'BR',
+    logo_url TEXT,
+    cover_url TEXT,
+    primary_color TEXT DEFAULT '#000000',
+    invite_code TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_academies_owner ON academies(owner_id);
+CREATE INDEX idx_academies_invite_code ON academies(invite_code);
+CREATE INDEX idx_academies_slug ON academies(slug);
+
+-- profiles.academy_id depende de academies (referência circular)
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'profiles_academy_id_fkey' AND conrelid = 'profiles'::regclass
+    ) THEN
+        ALTER TABLE profiles ADD CONSTRAINT profiles_academy_id_fkey
+            FOREIGN KEY (academy_id) REFERENCES academies(id);
+    END IF;
+END
+$$;
+
+-- 3. subscription_plans
+CREATE TABLE subscription_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    slug TEXT UNIQUE NOT NULL,
+    description TEXT,
+    price_monthly INTEGER NOT NULL,
+    price_yearly INTEGER,
+    currency TEXT NOT NULL DEFAULT 'BRL',
+    max_students INTEGER,
+    max_professors INTEGER,
+    max_locations INTEGER NOT NULL DEFAULT 1,
+    features JSONB NOT NULL DEFAULT '[]',
+    trial_days INTEGER,
+    dunning_policy JSONB,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    stripe_price_id_monthly TEXT,
+    stripe_price_id_yearly TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 4. subscriptions
+CREATE TABLE subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    academy_id UUID UNIQUE NOT NULL REFERENCES academies(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES subscription_plans(id),
+    status subscription_status NOT NULL DEFAULT 'trialing',
+    trial_start_date TIMESTAMPTZ DEFAULT NOW(),
+    trial_end_date TIMESTAMPTZ,
+    trial_campaign_code TEXT,
+    trial_extensions JSONB,
+    payment_gateway payment_gateway,
+    pix_authorization_id TEXT,
+    pix_recurrence_id TEXT,
+    pix_customer_cpf TEXT,
+    pix_customer_name TEXT,
+    stripe_customer_id TEXT,
+    stripe_subscription_id TEXT,
+    stripe_price_id TEXT,
+    billing_interval TEXT NOT NULL DEFAULT 'monthly',
+    billing_anchor_day INTEGER NOT NULL DEFAULT 0,
+    current_period_start TIMESTAMPTZ,
+    current_period_end TIMESTAMPTZ,
+    scheduled_plan_change JSONB,
+    pending_proration_amount INTEGER NOT NULL DEFAULT 0,
+    pauses JSONB,
+    discount JSONB,
+    canceled_at TIMESTAMPTZ,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    cancel_reason TEXT,
+    dunning_notices_sent INTEGER NOT NULL DEFAULT 0,
+    metadata JSONB,
+    transitions JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscriptions_academy ON subscriptions(academy_id);
+CREATE INDEX idx_subscriptions_status ON subscriptions(status);
+CREATE INDEX idx_subscriptions_trial_end ON subscriptions(trial_end_date);
+CREATE INDEX idx_subscriptions_period_end ON subscriptions(current_period_end);
+
+-- 5. payment_history
+CREATE TABLE payment_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    academy_id UUID NOT NULL REFERENCES academies(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    gross_amount INTEGER NOT NULL DEFAULT 0,
+    discount_amount INTEGER NOT NULL DEFAULT 0,
+    coupon_id TEXT,
+    currency TEXT NOT NULL DEFAULT 'BRL',
+    payment_gateway payment_gateway NOT NULL,
+    gateway_payment_id TEXT,
+    gateway_charge_id TEXT,
+    gateway_invoice_id TEXT,
+    end_to_end_id TEXT,
+    status payment_status NOT NULL DEFAULT 'pending',
+    refunded_amount INTEGER NOT NULL DEFAULT 0,
+    payment_method TEXT,
+    failure_reason TEXT,
+    failure_code TEXT,
+    period_start TIMESTAMPTZ,
+    period_end TIMESTAMPTZ,
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payment_history_subscription ON payment_history(subscription_id);
+CREATE INDEX idx_payment_history_academy ON payment_history(academy_id, created_at DESC);
+CREATE INDEX idx_payment_history_status ON payment_history(status);
+CREATE INDEX idx_payment_history_gateway_id ON payment_history(gateway_payment_id);
+CREATE INDEX idx_payment_history_created ON payment_history(created_at DESC);
+
+-- 6. webhook_events
+CREATE TABLE webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gateway TEXT NOT NULL,
+    event_id TEXT UNIQUE NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    headers JSONB,
+    status webhook_status NOT NULL DEFAULT 'pending',
+    processed_at TIMESTAMPTZ,
+    error_message TEXT,
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    next_retry_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_events_type ON webhook_events(event_type);
+CREATE INDEX idx_webhook_events_status ON webhook_events(status);
+CREATE INDEX idx_webhook_events_gateway ON webhook_events(gateway);
+CREATE INDEX idx_webhook_events_created ON webhook_events(created_at DESC);
+
+-- Auto-update updated_at
+CREATE OR REPLACE FUNCTION update_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_updated_at
+    BEFORE UPDATE ON academies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TRIGGER set_updated_at
+    BEFORE UPDATE ON subscriptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TRIGGER set_updated_at
+    BEFORE UPDATE ON subscription_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Planos padrão
+INSERT INTO subscription_plans (name, slug, price_monthly, max_students, max_professors, features) VALUES
+('Starter', 'starter', 9900, 50, 2, '["checkin", "schedule", "profiles"]'),
+('Pro', 'pro', 19900, 200, 5, '["checkin", "schedule", "profiles", "analytics", "store"]'),
+('Business', 'business', 39900, NULL, NULL, '["checkin", "schedule", "profiles", "analytics", "store", "api", "multi_location", "priority_support"]');
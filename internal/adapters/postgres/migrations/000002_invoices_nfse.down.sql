@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS nfse;
+DROP TABLE IF EXISTS rps_counters;
+DROP TYPE IF EXISTS nfse_status;
+
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_counters;
+DROP TYPE IF EXISTS invoice_status;
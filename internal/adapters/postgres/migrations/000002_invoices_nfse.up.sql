@@ -0,0 +1,62 @@
+-- Faturas (numeração por academia) e NFS-e
+
+CREATE TYPE invoice_status AS ENUM ('issued', 'void');
+
+CREATE TABLE invoice_counters (
+    academy_id UUID PRIMARY KEY REFERENCES academies(id) ON DELETE CASCADE,
+    last_number BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    academy_id UUID NOT NULL REFERENCES academies(id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id),
+    payment_id UUID UNIQUE NOT NULL REFERENCES payment_history(id),
+    number BIGINT NOT NULL,
+    status invoice_status NOT NULL DEFAULT 'issued',
+    currency TEXT NOT NULL DEFAULT 'BRL',
+    period_start TIMESTAMPTZ,
+    period_end TIMESTAMPTZ,
+    lines JSONB NOT NULL,
+    total INTEGER NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (academy_id, number)
+);
+
+CREATE INDEX idx_invoices_academy ON invoices(academy_id, number DESC);
+
+CREATE TYPE nfse_status AS ENUM ('pending', 'issued', 'failed', 'canceled');
+
+CREATE TABLE rps_counters (
+    series TEXT PRIMARY KEY,
+    last_number BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE nfse (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    academy_id UUID NOT NULL REFERENCES academies(id),
+    payment_id UUID UNIQUE NOT NULL REFERENCES payment_history(id),
+    status nfse_status NOT NULL DEFAULT 'pending',
+    rps_number BIGINT NOT NULL,
+    rps_series TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    competence TIMESTAMPTZ NOT NULL,
+    number TEXT,
+    verification_code TEXT,
+    xml TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    issued_at TIMESTAMPTZ,
+    canceled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (rps_series, rps_number)
+);
+
+CREATE INDEX idx_nfse_status ON nfse(status) WHERE status IN ('pending', 'failed');
+
+CREATE TRIGGER set_updated_at
+    BEFORE UPDATE ON nfse
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
@@ -0,0 +1,18 @@
+-- Eventos de domínio gravados na transação da entidade
+
+CREATE TABLE outbox (
+    sequence BIGSERIAL PRIMARY KEY,
+    event_id UUID UNIQUE NOT NULL,
+    event_type TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    academy_id UUID NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(sequence) WHERE dispatched_at IS NULL;
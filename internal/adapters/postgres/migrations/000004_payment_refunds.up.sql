@@ -0,0 +1,19 @@
+-- Devoluções totais e parciais
+
+CREATE TYPE refund_status AS ENUM ('pending', 'succeeded', 'failed');
+
+CREATE TABLE payment_refunds (
+    id TEXT PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payment_history(id) ON DELETE CASCADE,
+    academy_id UUID NOT NULL REFERENCES academies(id),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    status refund_status NOT NULL DEFAULT 'pending',
+    gateway_refund_id TEXT,
+    failure_reason TEXT,
+    requested_by TEXT NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_payment_refunds_payment ON payment_refunds(payment_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webhook_events_pending;
+ALTER TABLE webhook_events DROP CONSTRAINT webhook_events_gateway_event_id_key;
+ALTER TABLE webhook_events ADD CONSTRAINT webhook_events_event_id_key UNIQUE (event_id);
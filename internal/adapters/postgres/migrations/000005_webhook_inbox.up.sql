@@ -0,0 +1,7 @@
+-- Inbox de webhooks: o mesmo event_id pode existir em gateways diferentes
+
+ALTER TABLE webhook_events DROP CONSTRAINT webhook_events_event_id_key;
+ALTER TABLE webhook_events ADD CONSTRAINT webhook_events_gateway_event_id_key UNIQUE (gateway, event_id);
+
+-- Varredura de eventos pendentes (entregas não processadas antes de um restart)
+CREATE INDEX idx_webhook_events_pending ON webhook_events(received_at) WHERE status = 'pending';
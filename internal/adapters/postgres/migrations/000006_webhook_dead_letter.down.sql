@@ -0,0 +1,14 @@
+-- Enum não aceita DROP VALUE: recria o tipo sem 'dead_letter'
+DROP INDEX IF EXISTS idx_webhook_events_retry;
+DROP INDEX IF EXISTS idx_webhook_events_pending;
+
+UPDATE webhook_events SET status = 'failed', next_retry_at = NULL WHERE status = 'dead_letter';
+
+ALTER TYPE webhook_status RENAME TO webhook_status_old;
+CREATE TYPE webhook_status AS ENUM ('pending', 'processing', 'processed', 'failed', 'skipped');
+ALTER TABLE webhook_events ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE webhook_events ALTER COLUMN status TYPE webhook_status USING status::text::webhook_status;
+ALTER TABLE webhook_events ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE webhook_status_old;
+
+CREATE INDEX idx_webhook_events_pending ON webhook_events(received_at) WHERE status = 'pending';
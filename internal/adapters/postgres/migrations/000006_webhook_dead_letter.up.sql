@@ -0,0 +1,6 @@
+-- Dead-letter de webhooks: eventos que esgotaram as retentativas
+
+ALTER TYPE webhook_status ADD VALUE IF NOT EXISTS 'dead_letter';
+
+-- Reserva dos retries vencidos pelo worker (FOR UPDATE SKIP LOCKED)
+CREATE INDEX idx_webhook_events_retry ON webhook_events(next_retry_at) WHERE status = 'failed';
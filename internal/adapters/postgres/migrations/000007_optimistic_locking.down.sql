@@ -0,0 +1,2 @@
+ALTER TABLE payment_history DROP COLUMN version;
+ALTER TABLE subscriptions DROP COLUMN version;
@@ -0,0 +1,4 @@
+-- Controle de concorrência otimista: cada UPDATE compara e incrementa a versão
+
+ALTER TABLE subscriptions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE payment_history ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,30 @@
+-- Audit log append-only das ações sensíveis de cobrança. Os registros de cada
+-- academia formam uma cadeia de hashes (hash cobre o conteúdo e prev_hash).
+-- diff é JSON (não JSONB) para preservar o texto exato coberto pelo hash.
+
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    academy_id UUID NOT NULL,       -- Sem FK: o registro sobrevive à academia
+    seq BIGINT NOT NULL,
+    actor_type TEXT NOT NULL,       -- user | system | webhook
+    actor_id TEXT,
+    action TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    diff JSON,
+    request_id TEXT,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    UNIQUE (academy_id, seq)
+);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log é append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
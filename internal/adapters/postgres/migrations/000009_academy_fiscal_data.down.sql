@@ -0,0 +1,6 @@
+ALTER TABLE academies
+    DROP COLUMN address_city_code,
+    DROP COLUMN address_district,
+    DROP COLUMN address_complement,
+    DROP COLUMN address_number,
+    DROP COLUMN document;
@@ -0,0 +1,8 @@
+-- Dados fiscais da academia (tomadora da NFS-e): documento e endereço completo
+
+ALTER TABLE academies
+    ADD COLUMN document TEXT,           -- CNPJ ou CPF, só dígitos
+    ADD COLUMN address_number TEXT,
+    ADD COLUMN address_complement TEXT,
+    ADD COLUMN address_district TEXT,
+    ADD COLUMN address_city_code TEXT;  -- Código IBGE do município
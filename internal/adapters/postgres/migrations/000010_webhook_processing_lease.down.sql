@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_webhook_events_processing;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS processing_started_at;
@@ -0,0 +1,9 @@
+-- Lease do processamento de webhooks: evento em processing há mais que o
+-- lease (worker caiu, processo reiniciou) volta às retentativas
+
+ALTER TABLE webhook_events ADD COLUMN processing_started_at TIMESTAMPTZ;
+
+-- Eventos presos em processing antes desta migration são retomados na próxima rodada
+UPDATE webhook_events SET processing_started_at = received_at WHERE status = 'processing';
+
+CREATE INDEX idx_webhook_events_processing ON webhook_events(processing_started_at) WHERE status = 'processing';
@@ -0,0 +1,17 @@
+-- Cobranças adiadas no modo degradado (gateway indisponível): sobrevivem a
+-- reinícios até o worker conseguir criá-las na Efí
+
+CREATE TABLE pending_charges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    academy_id UUID NOT NULL REFERENCES academies(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('pix_charge', 'pix_recurrence')),
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'completed', 'failed')),
+    request JSONB NOT NULL,
+    result JSONB,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    enqueued_at TIMESTAMPTZ NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_pending_charges_due ON pending_charges(next_attempt_at) WHERE status = 'queued';
@@ -0,0 +1,20 @@
+-- Cupons promocionais: o limite de resgates é garantido pelo UPDATE de
+-- Redeem e pelo CHECK abaixo
+
+CREATE TABLE coupons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code TEXT NOT NULL UNIQUE CHECK (code = UPPER(code)),
+    type TEXT NOT NULL CHECK (type IN ('percent', 'fixed')),
+    percent_off INTEGER NOT NULL DEFAULT 0,
+    amount_off INTEGER NOT NULL DEFAULT 0,
+    duration TEXT NOT NULL CHECK (duration IN ('once', 'repeating', 'forever')),
+    duration_cycles INTEGER NOT NULL DEFAULT 0,
+    max_redemptions INTEGER,
+    times_redeemed INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    plan_ids TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    stripe_coupon_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (times_redeemed >= 0 AND (max_redemptions IS NULL OR times_redeemed <= max_redemptions))
+);
@@ -0,0 +1,11 @@
+-- Campanhas de trial: códigos que concedem um trial diferente do padrão do plano
+
+CREATE TABLE trial_campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code TEXT NOT NULL UNIQUE CHECK (code = UPPER(code)),
+    trial_days INTEGER NOT NULL CHECK (trial_days > 0),
+    plan_ids TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMPTZ,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
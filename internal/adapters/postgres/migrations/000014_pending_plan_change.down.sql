@@ -0,0 +1 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS pending_plan_change;
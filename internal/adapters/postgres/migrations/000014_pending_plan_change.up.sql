@@ -0,0 +1,3 @@
+-- Upgrade aguardando o pagamento da cobrança da diferença (ver domain.PendingPlanChange)
+
+ALTER TABLE subscriptions ADD COLUMN pending_plan_change JSONB;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_subscriptions_gateway_cancel_pending;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS gateway_cancel_pending;
@@ -0,0 +1,6 @@
+-- Assinatura cancelada cuja recorrência o gateway ainda não encerrou: o job
+-- de cancelamentos tenta de novo até conseguir
+
+ALTER TABLE subscriptions ADD COLUMN gateway_cancel_pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_subscriptions_gateway_cancel_pending ON subscriptions(id) WHERE gateway_cancel_pending;
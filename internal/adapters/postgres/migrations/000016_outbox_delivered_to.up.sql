@@ -0,0 +1,4 @@
+-- Subscribers que já receberam cada evento: numa retentativa, só os que
+-- falharam recebem o evento de novo
+
+ALTER TABLE outbox ADD COLUMN delivered_to TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// nfseColumns são as colunas lidas por scanNFSe, na ordem
+const nfseColumns = `
+	id, academy_id, payment_id, status, rps_number, rps_series, amount, description, competence,
+	number, verification_code, xml, attempts, error_message, issued_at, canceled_at, created_at, updated_at`
+
+// NFSeRepository implementa ports.NFSeService nas tabelas nfse e rps_counters
+type NFSeRepository struct {
+	db *DB
+}
+
+// NewNFSeRepository cria o repositório de NFS-e
+func NewNFSeRepository(db *DB) *NFSeRepository {
+	return &NFSeRepository{db: db}
+}
+
+// Create incrementa o contador da série e insere a NFS-e na mesma transação.
+// O UPSERT trava a linha da série até o commit: RPS simultâneos recebem
+// números seguidos, e uma nota repetida do pagamento devolve o número no rollback.
+func (r *NFSeRepository) Create(ctx context.Context, nfse *domain.NFSe) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		var number int64
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO rps_counters (series, last_number) VALUES ($1, 1)
+			ON CONFLICT (series) DO UPDATE SET last_number = rps_counters.last_number + 1
+			RETURNING last_number`, nfse.RPSSeries,
+		).Scan(&number); err != nil {
+			return fmt.Errorf("erro ao numerar RPS: %w", err)
+		}
+
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO nfse (academy_id, payment_id, status, rps_number, rps_series, amount, description,
+				competence, attempts, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id`,
+			nfse.AcademyID, nfse.PaymentID, string(nfse.Status), number, nfse.RPSSeries, nfse.Amount,
+			nfse.Description, nfse.Competence, nfse.Attempts, nfse.CreatedAt, nfse.UpdatedAt,
+		).Scan(&nfse.ID); err != nil {
+			return fmt.Errorf("erro ao criar NFS-e: %w", uniqueViolation(err, "NFS-e do pagamento "+nfse.PaymentID))
+		}
+		nfse.RPSNumber = number
+		return nil
+	})
+}
+
+// GetByPaymentID obtém a NFS-e de um pagamento
+func (r *NFSeRepository) GetByPaymentID(ctx context.Context, paymentID string) (*domain.NFSe, error) {
+	nfse, err := scanNFSe(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+nfseColumns+` FROM nfse WHERE payment_id = $1`, paymentID))
+	if err != nil {
+		return nil, notFound(err, "NFS-e do pagamento", paymentID)
+	}
+	return nfse, nil
+}
+
+// ListByStatus lista as NFS-e em um status, das mais antigas para as mais novas
+func (r *NFSeRepository) ListByStatus(ctx context.Context, status domain.NFSeStatus, limit int) ([]*domain.NFSe, error) {
+	query := `SELECT ` + nfseColumns + ` FROM nfse WHERE status = $1 ORDER BY rps_series, rps_number`
+	args := []any{string(status)}
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar NFS-e: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*domain.NFSe
+	for rows.Next() {
+		nfse, err := scanNFSe(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler NFS-e: %w", err)
+		}
+		notes = append(notes, nfse)
+	}
+	return notes, rows.Err()
+}
+
+// Save grava o resultado do envio ou o cancelamento da NFS-e (o RPS não muda)
+func (r *NFSeRepository) Save(ctx context.Context, nfse *domain.NFSe) error {
+	tag, err := r.db.conn(ctx).Exec(ctx, `
+		UPDATE nfse SET status = $2, number = $3, verification_code = $4, xml = NULLIF($5, ''),
+			attempts = $6, error_message = $7, issued_at = $8, canceled_at = $9
+		WHERE id = $1`,
+		nfse.ID, string(nfse.Status), nfse.Number, nfse.VerificationCode, nfse.XML,
+		nfse.Attempts, nfse.ErrorMessage, nfse.IssuedAt, nfse.CanceledAt)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar NFS-e: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("NFS-e %s: %w", nfse.ID, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// scanNFSe lê uma linha com nfseColumns
+func scanNFSe(row pgx.Row) (*domain.NFSe, error) {
+	var (
+		n      domain.NFSe
+		status string
+		xml    *string
+	)
+	if err := row.Scan(
+		&n.ID, &n.AcademyID, &n.PaymentID, &status, &n.RPSNumber, &n.RPSSeries, &n.Amount, &n.Description, &n.Competence,
+		&n.Number, &n.VerificationCode, &xml, &n.Attempts, &n.ErrorMessage, &n.IssuedAt, &n.CanceledAt, &n.CreatedAt, &n.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	n.Status = domain.NFSeStatus(status)
+	if xml != nil {
+		n.XML = *xml
+	}
+	return &n, nil
+}
+
+// Garante que NFSeRepository implementa ports.NFSeService
+var _ ports.NFSeService = (*NFSeRepository)(nil)
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Outbox implementa ports.Outbox na tabela outbox
+type Outbox struct {
+	db *DB
+}
+
+// NewOutbox cria o outbox
+func NewOutbox(db *DB) *Outbox {
+	return &Outbox{db: db}
+}
+
+// Append grava eventos na transação do contexto (ou em uma própria)
+func (o *Outbox) Append(ctx context.Context, events ...domain.DomainEvent) error {
+	for _, event := range events {
+		data, err := toJSON(event.Data)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			data = []byte("{}")
+		}
+		if _, err := o.db.conn(ctx).Exec(ctx, `
+			INSERT INTO outbox (event_id, event_type, aggregate_type, aggregate_id, academy_id, data, occurred_at, next_attempt_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`,
+			event.ID, string(event.Type), event.AggregateType, event.AggregateID, event.AcademyID, data, event.OccurredAt,
+		); err != nil {
+			return fmt.Errorf("erro ao gravar evento %s no outbox: %w", event.Type, err)
+		}
+	}
+	return nil
+}
+
+// ListPending lista mensagens não entregues em ordem de sequence
+func (o *Outbox) ListPending(ctx context.Context, limit int) ([]*ports.OutboxMessage, error) {
+	query := `
+		SELECT sequence, event_id, event_type, aggregate_type, aggregate_id, academy_id, data, occurred_at,
+		       attempts, next_attempt_at, last_error, dispatched_at, delivered_to
+		FROM outbox
+		WHERE dispatched_at IS NULL
+		ORDER BY sequence`
+	args := []any{}
+	if limit > 0 {
+		query += ` LIMIT $1`
+		args = append(args, limit)
+	}
+
+	rows, err := o.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*ports.OutboxMessage
+	for rows.Next() {
+		var (
+			m         ports.OutboxMessage
+			eventType string
+			data      []byte
+		)
+		if err := rows.Scan(
+			&m.Sequence, &m.Event.ID, &eventType, &m.Event.AggregateType, &m.Event.AggregateID, &m.Event.AcademyID,
+			&data, &m.Event.OccurredAt, &m.Attempts, &m.NextAttemptAt, &m.LastError, &m.DispatchedAt, &m.DeliveredTo,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao ler outbox: %w", err)
+		}
+		m.Event.Type = domain.EventType(eventType)
+		if err := fromJSON(data, &m.Event.Data); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
+// MarkDispatched marca a mensagem como entregue
+func (o *Outbox) MarkDispatched(ctx context.Context, sequence int64, at time.Time) error {
+	return o.update(ctx, sequence, `UPDATE outbox SET dispatched_at = $2 WHERE sequence = $1`, at)
+}
+
+// MarkDelivered registra que o subscriber recebeu a mensagem
+func (o *Outbox) MarkDelivered(ctx context.Context, sequence int64, subscriber string) error {
+	return o.update(ctx, sequence, `
+		UPDATE outbox SET delivered_to = CASE
+			WHEN $2::text = ANY(delivered_to) THEN delivered_to
+			ELSE array_append(delivered_to, $2::text)
+		END
+		WHERE sequence = $1`, subscriber)
+}
+
+// MarkFailed registra uma falha de entrega e agenda a próxima tentativa
+func (o *Outbox) MarkFailed(ctx context.Context, sequence int64, reason string, nextAttemptAt time.Time) error {
+	return o.update(ctx, sequence,
+		`UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE sequence = $1`,
+		reason, nextAttemptAt)
+}
+
+// update executa um UPDATE de uma mensagem, falhando se ela não existe
+func (o *Outbox) update(ctx context.Context, sequence int64, sql string, args ...any) error {
+	tag, err := o.db.conn(ctx).Exec(ctx, sql, append([]any{sequence}, args...)...)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar outbox: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("mensagem %d do outbox: %w", sequence, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// Garante que Outbox implementa ports.Outbox
+var _ ports.Outbox = (*Outbox)(nil)
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// paymentColumns são as colunas lidas por scanPayment, na ordem
+const paymentColumns = `
+	id, subscription_id, academy_id, amount, gross_amount, discount_amount, coupon_id, currency,
+	payment_gateway, gateway_payment_id, gateway_charge_id, gateway_invoice_id, end_to_end_id,
+	status, refunded_amount, payment_method, failure_reason, failure_code,
+	period_start, period_end, paid_at, created_at, version`
+
+// paymentWriteColumns são as colunas gravadas por paymentArgs, na ordem
+const paymentWriteColumns = `
+	subscription_id, academy_id, amount, gross_amount, discount_amount, coupon_id, currency,
+	payment_gateway, gateway_payment_id, gateway_charge_id, gateway_invoice_id, end_to_end_id,
+	status, refunded_amount, payment_method, failure_reason, failure_code,
+	period_start, period_end, paid_at, created_at`
+
+// refundColumns são as colunas de payment_refunds, na ordem de scanRefund
+const refundColumns = `
+	id, payment_id, academy_id, amount, reason, status, gateway_refund_id, failure_reason,
+	requested_by, requested_at, completed_at`
+
+// PaymentRepository implementa ports.PaymentService nas tabelas payment_history
+// e payment_refunds
+type PaymentRepository struct {
+	db     *DB
+	outbox *Outbox
+}
+
+// NewPaymentRepository cria o repositório de pagamentos
+func NewPaymentRepository(db *DB) *PaymentRepository {
+	return &PaymentRepository{db: db, outbox: NewOutbox(db)}
+}
+
+// RecordPayment insere o pagamento, as devoluções e os eventos no outbox
+func (r *PaymentRepository) RecordPayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		args := paymentArgs(payment)
+		idArg := any(nil)
+		if payment.ID != "" {
+			idArg = payment.ID
+		}
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO payment_history (id, `+paymentWriteColumns+`)
+			VALUES (COALESCE($1, gen_random_uuid()), `+placeholders(2, len(args))+`)
+			RETURNING id, version`,
+			append([]any{idArg}, args...)...,
+		).Scan(&payment.ID, &payment.Version); err != nil {
+			return fmt.Errorf("erro ao registrar pagamento: %w", err)
+		}
+		return r.saveChildren(ctx, payment)
+	})
+}
+
+// UpdatePayment grava as alterações do pagamento, as devoluções e os eventos no
+// outbox; domain.ErrConcurrentModification se o pagamento mudou desde a leitura
+func (r *PaymentRepository) UpdatePayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		args := paymentArgs(payment)
+		var version int
+		err := r.db.conn(ctx).QueryRow(ctx, `
+			UPDATE payment_history SET (`+paymentWriteColumns+`) = (`+placeholders(3, len(args))+`),
+				version = version + 1
+			WHERE id = $1 AND version = $2
+			RETURNING version`,
+			append([]any{payment.ID, payment.Version}, args...)...,
+		).Scan(&version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.db.versionConflict(ctx, "payment_history", "pagamento", payment.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar pagamento: %w", err)
+		}
+		if err := r.saveChildren(ctx, payment); err != nil {
+			return err
+		}
+		payment.Version = version
+		return nil
+	})
+}
+
+// GetByID busca pagamento pelo ID
+func (r *PaymentRepository) GetByID(ctx context.Context, paymentID string) (*domain.PaymentHistory, error) {
+	payment, err := scanPayment(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_history WHERE id = $1`, paymentID))
+	if err != nil {
+		return nil, notFound(err, "pagamento", paymentID)
+	}
+	if err := r.loadRefunds(ctx, []*domain.PaymentHistory{payment}); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetByGatewayPaymentID busca pagamento pelo ID do gateway
+func (r *PaymentRepository) GetByGatewayPaymentID(ctx context.Context, gatewayPaymentID string) (*domain.PaymentHistory, error) {
+	payment, err := scanPayment(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+paymentColumns+` FROM payment_history WHERE gateway_payment_id = $1
+		ORDER BY created_at DESC LIMIT 1`, gatewayPaymentID))
+	if err != nil {
+		return nil, notFound(err, "pagamento", gatewayPaymentID)
+	}
+	if err := r.loadRefunds(ctx, []*domain.PaymentHistory{payment}); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// ListByAcademy lista o histórico de pagamentos da academia, do mais recente ao mais antigo
+func (r *PaymentRepository) ListByAcademy(ctx context.Context, academyID string, limit, offset int) ([]*domain.PaymentHistory, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment_history WHERE academy_id = $1
+		ORDER BY created_at DESC OFFSET $2`
+	args := []any{academyID, offset}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+	return r.list(ctx, query, args...)
+}
+
+// ListBySubscription lista os pagamentos da assinatura em ordem de criação
+func (r *PaymentRepository) ListBySubscription(ctx context.Context, subscriptionID string) ([]*domain.PaymentHistory, error) {
+	return r.list(ctx,
+		`SELECT `+paymentColumns+` FROM payment_history WHERE subscription_id = $1 ORDER BY created_at`,
+		subscriptionID)
+}
+
+// ListWithPendingRefunds lista os pagamentos com devoluções pendentes solicitadas até requestedBefore
+func (r *PaymentRepository) ListWithPendingRefunds(ctx context.Context, requestedBefore time.Time) ([]*domain.PaymentHistory, error) {
+	return r.list(ctx, `SELECT `+paymentColumns+` FROM payment_history WHERE id IN (
+			SELECT payment_id FROM payment_refunds WHERE status = 'pending' AND requested_at <= $1)
+		ORDER BY created_at`, requestedBefore)
+}
+
+// list executa a consulta e carrega as devoluções dos pagamentos encontrados
+func (r *PaymentRepository) list(ctx context.Context, query string, args ...any) ([]*domain.PaymentHistory, error) {
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar pagamentos: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*domain.PaymentHistory
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler pagamento: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao listar pagamentos: %w", err)
+	}
+
+	if err := r.loadRefunds(ctx, payments); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// saveChildren grava as devoluções do pagamento e os eventos pendentes
+func (r *PaymentRepository) saveChildren(ctx context.Context, payment *domain.PaymentHistory) error {
+	for _, refund := range payment.Refunds {
+		if refund.PaymentID == "" {
+			refund.PaymentID = payment.ID
+		}
+		if _, err := r.db.conn(ctx).Exec(ctx, `
+			INSERT INTO payment_refunds (`+refundColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (id) DO UPDATE SET
+				amount = EXCLUDED.amount,
+				status = EXCLUDED.status,
+				gateway_refund_id = EXCLUDED.gateway_refund_id,
+				failure_reason = EXCLUDED.failure_reason,
+				completed_at = EXCLUDED.completed_at`,
+			refund.ID, refund.PaymentID, refund.AcademyID, refund.Amount, refund.Reason, string(refund.Status),
+			refund.GatewayRefundID, refund.FailureReason, refund.RequestedBy, refund.RequestedAt, refund.CompletedAt,
+		); err != nil {
+			return fmt.Errorf("erro ao gravar devolução %s: %w", refund.ID, err)
+		}
+	}
+	return r.outbox.Append(ctx, payment.PullEvents()...)
+}
+
+// loadRefunds preenche Refunds dos pagamentos com uma única consulta
+func (r *PaymentRepository) loadRefunds(ctx context.Context, payments []*domain.PaymentHistory) error {
+	if len(payments) == 0 {
+		return nil
+	}
+	byID := make(map[string]*domain.PaymentHistory, len(payments))
+	ids := make([]string, 0, len(payments))
+	for _, p := range payments {
+		byID[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+
+	rows, err := r.db.conn(ctx).Query(ctx,
+		`SELECT `+refundColumns+` FROM payment_refunds WHERE payment_id = ANY($1::uuid[]) ORDER BY requested_at`, ids)
+	if err != nil {
+		return fmt.Errorf("erro ao listar devoluções: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			refund domain.PaymentRefund
+			status string
+		)
+		if err := rows.Scan(
+			&refund.ID, &refund.PaymentID, &refund.AcademyID, &refund.Amount, &refund.Reason, &status,
+			&refund.GatewayRefundID, &refund.FailureReason, &refund.RequestedBy, &refund.RequestedAt, &refund.CompletedAt,
+		); err != nil {
+			return fmt.Errorf("erro ao ler devolução: %w", err)
+		}
+		refund.Status = domain.RefundStatus(status)
+		if p := byID[refund.PaymentID]; p != nil {
+			p.Refunds = append(p.Refunds, &refund)
+		}
+	}
+	return rows.Err()
+}
+
+// paymentArgs converte o pagamento nos parâmetros de paymentWriteColumns
+func paymentArgs(p *domain.PaymentHistory) []any {
+	currency := p.Currency
+	if currency == "" {
+		currency = "BRL"
+	}
+	return []any{
+		p.SubscriptionID, p.AcademyID, p.Amount, p.GrossAmount, p.DiscountAmount, p.CouponID, currency,
+		string(p.PaymentGateway), p.GatewayPaymentID, p.GatewayChargeID, p.GatewayInvoiceID, p.EndToEndID,
+		string(p.Status), p.RefundedAmount, p.PaymentMethod, p.FailureReason, p.FailureCode,
+		p.PeriodStart, p.PeriodEnd, p.PaidAt, p.CreatedAt,
+	}
+}
+
+// scanPayment lê uma linha com paymentColumns (sem as devoluções)
+func scanPayment(row pgx.Row) (*domain.PaymentHistory, error) {
+	var (
+		p               domain.PaymentHistory
+		gateway, status string
+	)
+	if err := row.Scan(
+		&p.ID, &p.SubscriptionID, &p.AcademyID, &p.Amount, &p.GrossAmount, &p.DiscountAmount, &p.CouponID, &p.Currency,
+		&gateway, &p.GatewayPaymentID, &p.GatewayChargeID, &p.GatewayInvoiceID, &p.EndToEndID,
+		&status, &p.RefundedAmount, &p.PaymentMethod, &p.FailureReason, &p.FailureCode,
+		&p.PeriodStart, &p.PeriodEnd, &p.PaidAt, &p.CreatedAt, &p.Version,
+	); err != nil {
+		return nil, err
+	}
+	p.PaymentGateway = domain.PaymentGateway(gateway)
+	p.Status = domain.PaymentStatus(status)
+	return &p, nil
+}
+
+// Garante que PaymentRepository implementa ports.PaymentService
+var _ ports.PaymentService = (*PaymentRepository)(nil)
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// planColumns são as colunas lidas por scanPlan, na ordem
+const planColumns = `
+	id, name, slug, description, price_monthly, price_yearly, currency,
+	max_students, max_professors, max_locations, features, trial_days, dunning_policy,
+	is_active, stripe_price_id_monthly, stripe_price_id_yearly, created_at, updated_at`
+
+// PlanRepository implementa ports.PlanService na tabela subscription_plans
+type PlanRepository struct {
+	db *DB
+}
+
+// NewPlanRepository cria o repositório de planos
+func NewPlanRepository(db *DB) *PlanRepository {
+	return &PlanRepository{db: db}
+}
+
+// ListActive lista os planos ativos, do mais barato ao mais caro
+func (r *PlanRepository) ListActive(ctx context.Context) ([]*domain.SubscriptionPlan, error) {
+	rows, err := r.db.conn(ctx).Query(ctx,
+		`SELECT `+planColumns+` FROM subscription_plans WHERE is_active ORDER BY price_monthly`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar planos: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*domain.SubscriptionPlan
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+// GetBySlug busca plano pelo slug
+func (r *PlanRepository) GetBySlug(ctx context.Context, slug string) (*domain.SubscriptionPlan, error) {
+	plan, err := scanPlan(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+planColumns+` FROM subscription_plans WHERE slug = $1`, slug))
+	if err != nil {
+		return nil, notFound(err, "plano", slug)
+	}
+	return plan, nil
+}
+
+// GetByID busca plano pelo ID
+func (r *PlanRepository) GetByID(ctx context.Context, id string) (*domain.SubscriptionPlan, error) {
+	plan, err := scanPlan(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+planColumns+` FROM subscription_plans WHERE id = $1`, id))
+	if err != nil {
+		return nil, notFound(err, "plano", id)
+	}
+	return plan, nil
+}
+
+// scanPlan lê uma linha com planColumns
+func scanPlan(row pgx.Row) (*domain.SubscriptionPlan, error) {
+	var (
+		p           domain.SubscriptionPlan
+		description *string
+		features    []byte
+		dunning     []byte
+	)
+	if err := row.Scan(
+		&p.ID, &p.Name, &p.Slug, &description, &p.PriceMonthly, &p.PriceYearly, &p.Currency,
+		&p.MaxStudents, &p.MaxProfessors, &p.MaxLocations, &features, &p.TrialDays, &dunning,
+		&p.IsActive, &p.StripePriceIDMonthly, &p.StripePriceIDYearly, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if description != nil {
+		p.Description = *description
+	}
+	if err := fromJSON(features, &p.Features); err != nil {
+		return nil, err
+	}
+	if err := fromJSON(dunning, &p.DunningPolicy); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Garante que PlanRepository implementa ports.PlanService
+var _ ports.PlanService = (*PlanRepository)(nil)
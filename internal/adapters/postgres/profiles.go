@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// ProfileRepository implementa ports.ProfileService na tabela profiles, mantida
+// pelo signup do app (Supabase Auth)
+type ProfileRepository struct {
+	db *DB
+}
+
+// NewProfileRepository cria o repositório de perfis
+func NewProfileRepository(db *DB) *ProfileRepository {
+	return &ProfileRepository{db: db}
+}
+
+// GetByID busca o perfil do usuário
+func (r *ProfileRepository) GetByID(ctx context.Context, userID string) (*domain.Profile, error) {
+	var (
+		p    domain.Profile
+		role string
+	)
+	err := r.db.conn(ctx).QueryRow(ctx, `
+		SELECT id, email, COALESCE(full_name, ''), role, COALESCE(academy_id::text, '')
+		FROM profiles WHERE id = $1`, userID,
+	).Scan(&p.ID, &p.Email, &p.FullName, &role, &p.AcademyID)
+	if err != nil {
+		return nil, notFound(err, "perfil", userID)
+	}
+	p.Role = domain.Role(role)
+	return &p, nil
+}
+
+// Garante que ProfileRepository implementa ports.ProfileService
+var _ ports.ProfileService = (*ProfileRepository)(nil)
@@ -0,0 +1,366 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// subscriptionColumns são as colunas lidas por scanSubscription, na ordem
+const subscriptionColumns = `
+	id, academy_id, plan_id, status,
+	trial_start_date, trial_end_date, trial_campaign_code, trial_extensions,
+	payment_gateway, pix_authorization_id, pix_recurrence_id, pix_customer_cpf, pix_customer_name,
+	stripe_customer_id, stripe_subscription_id, stripe_price_id,
+	billing_interval, billing_anchor_day, current_period_start, current_period_end,
+	scheduled_plan_change, pending_plan_change, pending_proration_amount, pauses, discount,
+	canceled_at, cancel_at_period_end, cancel_reason, gateway_cancel_pending, dunning_notices_sent,
+	metadata, transitions, created_at, updated_at, version`
+
+// SubscriptionRepository implementa ports.SubscriptionService na tabela subscriptions
+type SubscriptionRepository struct {
+	db     *DB
+	plans  *PlanRepository
+	outbox *Outbox
+}
+
+// NewSubscriptionRepository cria o repositório de assinaturas
+func NewSubscriptionRepository(db *DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db, plans: NewPlanRepository(db), outbox: NewOutbox(db)}
+}
+
+// CreateTrial cria a assinatura em trial da academia com o trial do plano
+func (r *SubscriptionRepository) CreateTrial(ctx context.Context, academyID, planID string) (*domain.Subscription, error) {
+	plan, err := r.plans.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	now := r.db.clock.Now()
+	terms, err := domain.ResolveTrialTerms(plan, nil, now)
+	if err != nil {
+		return nil, err
+	}
+	sub := domain.NewTrialSubscription(academyID, terms, now)
+	if err := r.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Activate ativa a assinatura com um período começando agora
+func (r *SubscriptionRepository) Activate(ctx context.Context, subscriptionID string, gateway domain.PaymentGateway) (*domain.Subscription, error) {
+	var sub *domain.Subscription
+	err := r.db.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if sub, err = r.GetByID(ctx, subscriptionID); err != nil {
+			return err
+		}
+		now := r.db.clock.Now()
+		interval := sub.BillingInterval
+		if interval == "" {
+			interval = domain.BillingIntervalMonthly
+		}
+		end := domain.NextBillingDate(now, interval, now.In(domain.BillingLocation).Day())
+		if err := sub.Activate(gateway, now, end, domain.ActorSystem, now); err != nil {
+			return err
+		}
+		return r.Save(ctx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Cancel cancela a assinatura (agora ou no fim do período)
+func (r *SubscriptionRepository) Cancel(ctx context.Context, subscriptionID string, reason string, atPeriodEnd bool) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		sub, err := r.GetByID(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if err := sub.Cancel(reason, atPeriodEnd, domain.ActorSystem, r.db.clock.Now()); err != nil {
+			return err
+		}
+		return r.Save(ctx, sub)
+	})
+}
+
+// ChangePlan troca o plano sem pró-rata (a cobrança da diferença é do PlanChangeService)
+func (r *SubscriptionRepository) ChangePlan(ctx context.Context, subscriptionID, newPlanID string) (*domain.Subscription, error) {
+	var sub *domain.Subscription
+	err := r.db.Do(ctx, func(ctx context.Context) error {
+		if _, err := r.plans.GetByID(ctx, newPlanID); err != nil {
+			return err
+		}
+		var err error
+		if sub, err = r.GetByID(ctx, subscriptionID); err != nil {
+			return err
+		}
+		sub.PlanID = newPlanID
+		sub.UpdatedAt = r.db.clock.Now()
+		return r.Save(ctx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Create insere a assinatura e grava os eventos dela no outbox
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		args, err := subscriptionArgs(sub)
+		if err != nil {
+			return err
+		}
+		idArg := any(nil)
+		if sub.ID != "" {
+			idArg = sub.ID
+		}
+		if err := r.db.conn(ctx).QueryRow(ctx, `
+			INSERT INTO subscriptions (id, `+subscriptionWriteColumns+`)
+			VALUES (COALESCE($1, gen_random_uuid()), `+placeholders(2, len(args))+`)
+			RETURNING id, version`,
+			append([]any{idArg}, args...)...,
+		).Scan(&sub.ID, &sub.Version); err != nil {
+			return fmt.Errorf("erro ao criar assinatura: %w", uniqueViolation(err, "assinatura da academia "+sub.AcademyID))
+		}
+		return r.outbox.Append(ctx, sub.PullEvents()...)
+	})
+}
+
+// GetByID obtém uma assinatura pelo ID
+func (r *SubscriptionRepository) GetByID(ctx context.Context, subscriptionID string) (*domain.Subscription, error) {
+	return r.getOne(ctx, "id = $1", subscriptionID)
+}
+
+// GetByAcademy obtém a assinatura de uma academia
+func (r *SubscriptionRepository) GetByAcademy(ctx context.Context, academyID string) (*domain.Subscription, error) {
+	return r.getOne(ctx, "academy_id = $1", academyID)
+}
+
+// GetByStripeSubscriptionID obtém por ID da subscription no Stripe
+func (r *SubscriptionRepository) GetByStripeSubscriptionID(ctx context.Context, stripeSubID string) (*domain.Subscription, error) {
+	return r.getOne(ctx, "stripe_subscription_id = $1", stripeSubID)
+}
+
+// GetByPixRecurrenceID obtém pelo idRec do PIX Automático
+func (r *SubscriptionRepository) GetByPixRecurrenceID(ctx context.Context, recurrenceID string) (*domain.Subscription, error) {
+	return r.getOne(ctx, "pix_recurrence_id = $1", recurrenceID)
+}
+
+// ListByStatus lista assinaturas em um status, das mais antigas às mais novas
+func (r *SubscriptionRepository) ListByStatus(ctx context.Context, status domain.SubscriptionStatus, limit int) ([]*domain.Subscription, error) {
+	return r.list(ctx, "status = $1 ORDER BY created_at", limit, string(status))
+}
+
+// ListCancellationsDue lista cancelamentos agendados cujo período terminou até
+// now e cancelamentos cuja recorrência o gateway ainda não encerrou
+func (r *SubscriptionRepository) ListCancellationsDue(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error) {
+	return r.list(ctx, `(cancel_at_period_end AND status NOT IN ('canceled', 'paused')
+		AND current_period_end <= $1) OR gateway_cancel_pending ORDER BY current_period_end`, limit, now)
+}
+
+// Save grava as alterações da assinatura e os eventos dela no outbox. O UPDATE
+// só vale para a versão lida: se outra escrita veio antes, falha com
+// domain.ErrConcurrentModification.
+func (r *SubscriptionRepository) Save(ctx context.Context, sub *domain.Subscription) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		args, err := subscriptionArgs(sub)
+		if err != nil {
+			return err
+		}
+		var version int
+		err = r.db.conn(ctx).QueryRow(ctx, `
+			UPDATE subscriptions SET (`+subscriptionWriteColumns+`) = (`+placeholders(3, len(args))+`),
+				version = version + 1
+			WHERE id = $1 AND version = $2
+			RETURNING version`,
+			append([]any{sub.ID, sub.Version}, args...)...,
+		).Scan(&version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.db.versionConflict(ctx, "subscriptions", "assinatura", sub.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		if err := r.outbox.Append(ctx, sub.PullEvents()...); err != nil {
+			return err
+		}
+		sub.Version = version
+		return nil
+	})
+}
+
+// ExpireTrials expira os trials vencidos; cada assinatura é gravada na própria transação
+func (r *SubscriptionRepository) ExpireTrials(ctx context.Context) (int, error) {
+	now := r.db.clock.Now()
+	subs, err := r.list(ctx, "status = 'trialing' AND trial_end_date <= $1 ORDER BY trial_end_date", 0, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, sub := range subs {
+		if err := sub.Expire(domain.ActorSystem, now); err != nil {
+			return expired, err
+		}
+		if err := r.Save(ctx, sub); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// getOne busca uma assinatura pelo filtro
+func (r *SubscriptionRepository) getOne(ctx context.Context, where string, arg any) (*domain.Subscription, error) {
+	sub, err := scanSubscription(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE `+where, arg))
+	if err != nil {
+		return nil, notFound(err, "assinatura", fmt.Sprint(arg))
+	}
+	return sub, nil
+}
+
+// list lista assinaturas pelo filtro (com ORDER BY); limit 0 = sem limite
+func (r *SubscriptionRepository) list(ctx context.Context, where string, limit int, arg any) ([]*domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE ` + where
+	args := []any{arg}
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar assinaturas: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler assinatura: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// subscriptionWriteColumns são as colunas gravadas por subscriptionArgs, na ordem
+const subscriptionWriteColumns = `
+	academy_id, plan_id, status,
+	trial_start_date, trial_end_date, trial_campaign_code, trial_extensions,
+	payment_gateway, pix_authorization_id, pix_recurrence_id, pix_customer_cpf, pix_customer_name,
+	stripe_customer_id, stripe_subscription_id, stripe_price_id,
+	billing_interval, billing_anchor_day, current_period_start, current_period_end,
+	scheduled_plan_change, pending_plan_change, pending_proration_amount, pauses, discount,
+	canceled_at, cancel_at_period_end, cancel_reason, gateway_cancel_pending, dunning_notices_sent,
+	metadata, transitions, created_at, updated_at`
+
+// subscriptionArgs converte a assinatura nos parâmetros de subscriptionWriteColumns
+func subscriptionArgs(s *domain.Subscription) ([]any, error) {
+	var jsonErr error
+	asJSON := func(v any) []byte {
+		data, err := toJSON(v)
+		if err != nil && jsonErr == nil {
+			jsonErr = err
+		}
+		return data
+	}
+
+	var gateway *string
+	if s.PaymentGateway != nil {
+		g := string(*s.PaymentGateway)
+		gateway = &g
+	}
+	interval := s.BillingInterval
+	if interval == "" {
+		interval = domain.BillingIntervalMonthly
+	}
+
+	args := []any{
+		s.AcademyID, s.PlanID, string(s.Status),
+		s.TrialStartDate, s.TrialEndDate, s.TrialCampaignCode, asJSON(s.TrialExtensions),
+		gateway, s.PixAuthorizationID, s.PixRecurrenceID, s.PixCustomerCPF, s.PixCustomerName,
+		s.StripeCustomerID, s.StripeSubscriptionID, s.StripePriceID,
+		string(interval), s.BillingAnchorDay, s.CurrentPeriodStart, s.CurrentPeriodEnd,
+		asJSON(s.ScheduledPlanChange), asJSON(s.PendingPlanChange), s.PendingProrationAmount, asJSON(s.Pauses), asJSON(s.Discount),
+		s.CanceledAt, s.CancelAtPeriodEnd, s.CancelReason, s.GatewayCancelPending, s.DunningNoticesSent,
+		nullableBytes(s.Metadata), asJSON(s.Transitions), s.CreatedAt, s.UpdatedAt,
+	}
+	return args, jsonErr
+}
+
+// scanSubscription lê uma linha com subscriptionColumns
+func scanSubscription(row pgx.Row) (*domain.Subscription, error) {
+	var (
+		s                                                      domain.Subscription
+		status, interval                                       string
+		gateway                                                *string
+		extensions, scheduled, pending, pauses, discount, meta []byte
+		transitions                                            []byte
+	)
+	if err := row.Scan(
+		&s.ID, &s.AcademyID, &s.PlanID, &status,
+		&s.TrialStartDate, &s.TrialEndDate, &s.TrialCampaignCode, &extensions,
+		&gateway, &s.PixAuthorizationID, &s.PixRecurrenceID, &s.PixCustomerCPF, &s.PixCustomerName,
+		&s.StripeCustomerID, &s.StripeSubscriptionID, &s.StripePriceID,
+		&interval, &s.BillingAnchorDay, &s.CurrentPeriodStart, &s.CurrentPeriodEnd,
+		&scheduled, &pending, &s.PendingProrationAmount, &pauses, &discount,
+		&s.CanceledAt, &s.CancelAtPeriodEnd, &s.CancelReason, &s.GatewayCancelPending, &s.DunningNoticesSent,
+		&meta, &transitions, &s.CreatedAt, &s.UpdatedAt, &s.Version,
+	); err != nil {
+		return nil, err
+	}
+
+	s.Status = domain.SubscriptionStatus(status)
+	s.BillingInterval = domain.BillingInterval(interval)
+	if gateway != nil {
+		g := domain.PaymentGateway(*gateway)
+		s.PaymentGateway = &g
+	}
+	if len(meta) > 0 {
+		s.Metadata = meta
+	}
+	for _, field := range []struct {
+		data []byte
+		dest any
+	}{
+		{extensions, &s.TrialExtensions},
+		{scheduled, &s.ScheduledPlanChange},
+		{pending, &s.PendingPlanChange},
+		{pauses, &s.Pauses},
+		{discount, &s.Discount},
+		{transitions, &s.Transitions},
+	} {
+		if err := fromJSON(field.data, field.dest); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// placeholders gera "$from, ..., $(from+n-1)"
+func placeholders(from, n int) string {
+	out := ""
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("$%d", from+i)
+	}
+	return out
+}
+
+// Garante que SubscriptionRepository implementa ports.SubscriptionService
+var _ ports.SubscriptionService = (*SubscriptionRepository)(nil)
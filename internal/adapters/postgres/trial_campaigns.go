@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// TrialCampaignRepository implementa ports.TrialCampaignService na tabela trial_campaigns
+type TrialCampaignRepository struct {
+	db *DB
+}
+
+// NewTrialCampaignRepository cria o repositório de campanhas de trial
+func NewTrialCampaignRepository(db *DB) *TrialCampaignRepository {
+	return &TrialCampaignRepository{db: db}
+}
+
+// GetByCode busca campanha pelo código (já normalizado)
+func (r *TrialCampaignRepository) GetByCode(ctx context.Context, code string) (*domain.TrialCampaign, error) {
+	var c domain.TrialCampaign
+	err := r.db.conn(ctx).QueryRow(ctx, `
+		SELECT id, code, trial_days, plan_ids, expires_at, is_active, created_at
+		FROM trial_campaigns WHERE code = $1`, code,
+	).Scan(&c.ID, &c.Code, &c.TrialDays, &c.PlanIDs, &c.ExpiresAt, &c.IsActive, &c.CreatedAt)
+	if err != nil {
+		return nil, notFound(err, "campanha de trial", code)
+	}
+	if len(c.PlanIDs) == 0 {
+		c.PlanIDs = nil
+	}
+	return &c, nil
+}
+
+// Garante que TrialCampaignRepository implementa ports.TrialCampaignService
+var _ ports.TrialCampaignService = (*TrialCampaignRepository)(nil)
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// UsageCounter implementa ports.UsageCounter sobre os perfis da academia:
+// alunos e professores são profiles com o papel correspondente. Cada academia
+// tem um único endereço, então conta como uma unidade.
+type UsageCounter struct {
+	db *DB
+}
+
+// NewUsageCounter cria o contador de uso dos recursos limitados por plano
+func NewUsageCounter(db *DB) *UsageCounter {
+	return &UsageCounter{db: db}
+}
+
+// CountUsage retorna quantos itens do recurso a academia já possui
+func (c *UsageCounter) CountUsage(ctx context.Context, academyID string, resource domain.Resource) (int, error) {
+	var query string
+	args := []any{academyID}
+	switch resource {
+	case domain.ResourceStudents:
+		query = `SELECT COUNT(*) FROM profiles WHERE academy_id = $1 AND role = $2`
+		args = append(args, string(domain.RoleStudent))
+	case domain.ResourceProfessors:
+		query = `SELECT COUNT(*) FROM profiles WHERE academy_id = $1 AND role = $2`
+		args = append(args, string(domain.RoleProfessor))
+	case domain.ResourceLocations:
+		query = `SELECT COUNT(*) FROM academies WHERE id = $1`
+	default:
+		return 0, fmt.Errorf("%w: recurso desconhecido: %s", domain.ErrValidation, resource)
+	}
+
+	var n int
+	if err := c.db.conn(ctx).QueryRow(ctx, query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("erro ao contar %s da academia %s: %w", resource, academyID, err)
+	}
+	return n, nil
+}
+
+// Garante que UsageCounter implementa ports.UsageCounter
+var _ ports.UsageCounter = (*UsageCounter)(nil)
@@ -0,0 +1,286 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// webhookColumns são as colunas lidas por scanWebhook, na ordem
+const webhookColumns = `
+	id, gateway, event_id, event_type, payload, headers,
+	status, processing_started_at, processed_at, error_message, retry_count, next_retry_at, received_at, created_at`
+
+// WebhookProcessor aplica um webhook armazenado às regras de negócio
+type WebhookProcessor func(ctx context.Context, event *domain.WebhookEvent) error
+
+// WebhookRepository implementa ports.WebhookService na tabela webhook_events
+type WebhookRepository struct {
+	db      *DB
+	process WebhookProcessor
+	backoff domain.WebhookBackoff
+	rand    func() float64
+}
+
+// NewWebhookRepository cria o repositório de webhooks; process é chamado por
+// Process e RetryFailed (nil aceita o evento sem fazer nada). Se process
+// devolver ports.ErrWebhookSkipped o evento fica como skipped.
+func NewWebhookRepository(db *DB, process WebhookProcessor) *WebhookRepository {
+	return &WebhookRepository{
+		db:      db,
+		process: process,
+		backoff: domain.DefaultWebhookBackoff,
+		rand:    rand.Float64,
+	}
+}
+
+// SetBackoff troca o intervalo entre retentativas e o limite da dead-letter
+func (r *WebhookRepository) SetBackoff(backoff domain.WebhookBackoff) {
+	r.backoff = backoff
+}
+
+// Store armazena o evento; um gateway + event_id já recebido não é duplicado
+// e o evento volta com os dados do registro existente
+func (r *WebhookRepository) Store(ctx context.Context, event *domain.WebhookEvent) error {
+	if _, err := r.db.conn(ctx).Exec(ctx, `
+		INSERT INTO webhook_events (gateway, event_id, event_type, payload, headers, status, retry_count, received_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (gateway, event_id) DO NOTHING`,
+		event.Gateway, event.EventID, event.EventType, []byte(event.Payload), nullableBytes(event.Headers),
+		string(event.Status), event.RetryCount, event.ReceivedAt, event.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("erro ao armazenar webhook %s: %w", event.EventID, err)
+	}
+
+	stored, err := r.GetByEventID(ctx, event.Gateway, event.EventID)
+	if err != nil {
+		return err
+	}
+	*event = *stored
+	return nil
+}
+
+// Process processa o evento uma única vez: só eventos pendentes ou falhos são
+// processados, e uma falha fica registrada para RetryFailed
+func (r *WebhookRepository) Process(ctx context.Context, gateway, eventID string) error {
+	var event *domain.WebhookEvent
+	err := r.db.Do(ctx, func(ctx context.Context) error {
+		var err error
+		event, err = r.getOne(ctx, "gateway = $1 AND event_id = $2 FOR UPDATE", gateway, eventID)
+		if err != nil {
+			return err
+		}
+		if event.Status != domain.WebhookStatusPending && event.Status != domain.WebhookStatusFailed {
+			event = nil
+			return nil
+		}
+		event.MarkProcessing(r.db.clock.Now())
+		return r.save(ctx, event)
+	})
+	if err != nil || event == nil {
+		return err
+	}
+	return r.run(ctx, event)
+}
+
+// GetByEventID busca webhook pelo gateway e event_id
+func (r *WebhookRepository) GetByEventID(ctx context.Context, gateway, eventID string) (*domain.WebhookEvent, error) {
+	return r.getOne(ctx, "gateway = $1 AND event_id = $2", gateway, eventID)
+}
+
+// GetByID busca webhook pelo ID interno
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*domain.WebhookEvent, error) {
+	return r.getOne(ctx, "id = $1", id)
+}
+
+// RetryFailed devolve às retentativas os eventos abandonados em processing
+// (lease vencido: worker caiu ou o processo reiniciou no meio) e reserva até
+// limit webhooks falhados com retry vencido (FOR UPDATE SKIP LOCKED: outra
+// instância rodando ao mesmo tempo pega os seguintes) e os reprocessa
+func (r *WebhookRepository) RetryFailed(ctx context.Context, limit int) (int, error) {
+	now := r.db.clock.Now()
+	if err := r.reclaimAbandoned(ctx, now); err != nil {
+		return 0, err
+	}
+
+	var claimed []*domain.WebhookEvent
+	err := r.db.Do(ctx, func(ctx context.Context) error {
+		var err error
+		claimed, err = r.list(ctx, `status = 'failed' AND next_retry_at <= $1
+			ORDER BY next_retry_at LIMIT $2 FOR UPDATE SKIP LOCKED`, now, limit)
+		if err != nil {
+			return err
+		}
+		for _, event := range claimed {
+			event.MarkProcessing(now)
+			if err := r.save(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, event := range claimed {
+		if err := r.run(ctx, event); err != nil {
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// reclaimAbandoned marca como falhos (com backoff) os eventos em processing
+// há mais que o lease
+func (r *WebhookRepository) reclaimAbandoned(ctx context.Context, now time.Time) error {
+	return r.db.Do(ctx, func(ctx context.Context) error {
+		abandoned, err := r.list(ctx, `status = 'processing' AND processing_started_at <= $1
+			ORDER BY processing_started_at FOR UPDATE SKIP LOCKED`, now.Add(-r.backoff.LeaseDuration()))
+		if err != nil {
+			return err
+		}
+		for _, event := range abandoned {
+			if err := event.Abandon(now, r.backoff, r.rand()); err != nil {
+				return err
+			}
+			if err := r.save(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListPending lista webhooks pendentes, dos mais antigos aos mais novos
+func (r *WebhookRepository) ListPending(ctx context.Context, limit int) ([]*domain.WebhookEvent, error) {
+	return r.ListByStatus(ctx, domain.WebhookStatusPending, limit, 0)
+}
+
+// ListByStatus lista webhooks em um status, dos mais antigos aos mais novos
+func (r *WebhookRepository) ListByStatus(ctx context.Context, status domain.WebhookStatus, limit, offset int) ([]*domain.WebhookEvent, error) {
+	where := "status = $1 ORDER BY received_at OFFSET $2"
+	if limit > 0 {
+		return r.list(ctx, where+" LIMIT $3", string(status), offset, limit)
+	}
+	return r.list(ctx, where, string(status), offset)
+}
+
+// Save grava o status, o payload e as retentativas do evento
+func (r *WebhookRepository) Save(ctx context.Context, event *domain.WebhookEvent) error {
+	return r.save(ctx, event)
+}
+
+// run chama o processador fora do lock (o status processing impede que outro
+// worker pegue o mesmo evento) e grava o resultado
+func (r *WebhookRepository) run(ctx context.Context, event *domain.WebhookEvent) error {
+	var processErr error
+	if r.process != nil {
+		processErr = r.process(ctx, event)
+	}
+
+	now := r.db.clock.Now()
+	switch {
+	case errors.Is(processErr, ports.ErrWebhookSkipped):
+		event.MarkSkipped()
+		processErr = nil
+	case processErr != nil:
+		event.Fail(processErr.Error(), now, r.backoff, r.rand())
+	default:
+		event.MarkProcessed(now)
+	}
+	if err := r.save(ctx, event); err != nil {
+		return err
+	}
+	return processErr
+}
+
+// save grava o estado de processamento do evento
+func (r *WebhookRepository) save(ctx context.Context, event *domain.WebhookEvent) error {
+	tag, err := r.db.conn(ctx).Exec(ctx, `
+		UPDATE webhook_events
+		SET status = $2, processed_at = $3, error_message = $4, retry_count = $5, next_retry_at = $6, payload = $7,
+			processing_started_at = $8
+		WHERE id = $1`,
+		event.ID, string(event.Status), event.ProcessedAt, event.ErrorMessage, event.RetryCount, event.NextRetryAt,
+		[]byte(event.Payload), event.ProcessingStartedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar webhook %s: %w", event.EventID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("webhook %s: %w", event.EventID, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// getOne busca um webhook pelo filtro
+func (r *WebhookRepository) getOne(ctx context.Context, where string, args ...any) (*domain.WebhookEvent, error) {
+	event, err := scanWebhook(r.db.conn(ctx).QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM webhook_events WHERE `+where, args...))
+	if err != nil {
+		return nil, notFound(err, "webhook", strings.TrimSpace(fmt.Sprintln(args...)))
+	}
+	return event, nil
+}
+
+// list lista webhooks pelo filtro (com ORDER BY)
+func (r *WebhookRepository) list(ctx context.Context, where string, args ...any) ([]*domain.WebhookEvent, error) {
+	rows, err := r.db.conn(ctx).Query(ctx, `SELECT `+webhookColumns+` FROM webhook_events WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.WebhookEvent
+	for rows.Next() {
+		event, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler webhook: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// scanWebhook lê uma linha com webhookColumns
+func scanWebhook(row pgx.Row) (*domain.WebhookEvent, error) {
+	var (
+		w                domain.WebhookEvent
+		payload, headers []byte
+		status           string
+	)
+	if err := row.Scan(
+		&w.ID, &w.Gateway, &w.EventID, &w.EventType, &payload, &headers,
+		&status, &w.ProcessingStartedAt, &w.ProcessedAt, &w.ErrorMessage, &w.RetryCount, &w.NextRetryAt, &w.ReceivedAt, &w.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	w.Payload = payload
+	if len(headers) > 0 {
+		w.Headers = headers
+	}
+	w.Status = domain.WebhookStatus(status)
+	return &w, nil
+}
+
+// nullableBytes grava um JSON vazio como NULL
+func nullableBytes(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// Garante que WebhookRepository implementa ports.WebhookService
+var _ ports.WebhookService = (*WebhookRepository)(nil)
@@ -0,0 +1,203 @@
+// Package render gera os documentos das faturas (HTML e PDF) com a identidade
+// visual da academia. Usa apenas a biblioteca padrão: o HTML sai de
+// html/template e o PDF é escrito diretamente (fontes base Helvetica/Courier
+// e logo embutido como JPEG).
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// DefaultPrimaryColor é usada quando a academia não definiu uma cor válida
+const DefaultPrimaryColor = "#000000"
+
+// maxLogoBytes limita o download do logo da academia
+const maxLogoBytes = 2 << 20
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// InvoiceRenderer implementa ports.InvoiceRenderer
+type InvoiceRenderer struct {
+	httpClient *http.Client
+}
+
+// NewInvoiceRenderer cria o renderizador de faturas
+func NewInvoiceRenderer() *InvoiceRenderer {
+	return &InvoiceRenderer{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// RenderHTML gera a fatura em HTML
+func (r *InvoiceRenderer) RenderHTML(ctx context.Context, invoice *domain.Invoice, branding *domain.Branding) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoiceTemplate.Execute(&buf, newInvoiceView(invoice, branding)); err != nil {
+		return nil, fmt.Errorf("erro ao gerar HTML da fatura: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF gera a fatura em PDF. Se o logo não puder ser baixado a fatura
+// sai sem ele: o documento não depende da disponibilidade do CDN da academia.
+func (r *InvoiceRenderer) RenderPDF(ctx context.Context, invoice *domain.Invoice, branding *domain.Branding) ([]byte, error) {
+	view := newInvoiceView(invoice, branding)
+
+	var logo *jpegImage
+	if view.LogoURL != "" {
+		img, err := r.fetchLogo(ctx, view.LogoURL)
+		if err != nil {
+			log.Printf("[Render] Logo da academia %s indisponível: %v", invoice.AcademyID, err)
+		} else {
+			logo = img
+		}
+	}
+	return renderInvoicePDF(view, logo)
+}
+
+// fetchLogo baixa o logo e o converte para JPEG
+func (r *InvoiceRenderer) fetchLogo(ctx context.Context, url string) (*jpegImage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoBytes))
+	if err != nil {
+		return nil, err
+	}
+	return toJPEG(data)
+}
+
+// invoiceView são os dados já formatados para os templates
+type invoiceView struct {
+	AcademyName  string
+	Document     string
+	Address      string
+	LogoURL      string
+	PrimaryColor string
+
+	Number   string
+	Status   domain.InvoiceStatus
+	IssuedAt string
+	Period   string
+	Lines    []lineView
+	Taxes    []lineView
+	Subtotal string
+	Total    string
+}
+
+type lineView struct {
+	Description string
+	Amount      string
+}
+
+// newInvoiceView formata a fatura para exibição (datas em America/Sao_Paulo)
+func newInvoiceView(invoice *domain.Invoice, branding *domain.Branding) *invoiceView {
+	if branding == nil {
+		branding = &domain.Branding{}
+	}
+
+	v := &invoiceView{
+		AcademyName:  branding.AcademyName,
+		Document:     branding.Document,
+		Address:      branding.Address,
+		LogoURL:      branding.LogoURL,
+		PrimaryColor: branding.PrimaryColor,
+		Number:       invoice.DisplayNumber(),
+		Status:       invoice.Status,
+		IssuedAt:     invoice.IssuedAt.In(domain.BillingLocation).Format("02/01/2006"),
+		Subtotal:     domain.FormatBRL(invoice.Subtotal()),
+		Total:        domain.FormatBRL(invoice.Total),
+	}
+	if !hexColor.MatchString(v.PrimaryColor) {
+		v.PrimaryColor = DefaultPrimaryColor
+	}
+	if !strings.HasPrefix(v.LogoURL, "https://") && !strings.HasPrefix(v.LogoURL, "http://") {
+		v.LogoURL = ""
+	}
+	if invoice.PeriodStart != nil && invoice.PeriodEnd != nil {
+		v.Period = invoice.PeriodStart.In(domain.BillingLocation).Format("02/01/2006") +
+			" a " + invoice.PeriodEnd.In(domain.BillingLocation).Format("02/01/2006")
+	}
+
+	for _, line := range invoice.Lines {
+		lv := lineView{Description: line.Description, Amount: domain.FormatBRL(line.Amount)}
+		if line.Included {
+			v.Taxes = append(v.Taxes, lv)
+		} else {
+			v.Lines = append(v.Lines, lv)
+		}
+	}
+	return v
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Fatura {{.Number}} - {{.AcademyName}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 0; }
+  header { background: {{.PrimaryColor}}; color: #fff; padding: 24px 40px; display: flex; align-items: center; gap: 16px; }
+  header img { max-height: 56px; }
+  header h1 { font-size: 20px; margin: 0; }
+  main { padding: 24px 40px; }
+  .meta td { padding: 2px 16px 2px 0; }
+  table.lines { width: 100%; border-collapse: collapse; margin-top: 24px; }
+  table.lines th { text-align: left; border-bottom: 2px solid {{.PrimaryColor}}; padding: 8px 0; }
+  table.lines td { border-bottom: 1px solid #eee; padding: 8px 0; }
+  .amount { text-align: right; white-space: nowrap; }
+  .total td { font-weight: bold; border-bottom: none; }
+  .taxes { margin-top: 24px; font-size: 12px; color: #666; }
+  .void { color: #c00; font-weight: bold; }
+</style>
+</head>
+<body>
+<header>
+  {{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{.AcademyName}}">{{end}}
+  <h1>{{.AcademyName}}</h1>
+</header>
+<main>
+  <h2>Fatura nº {{.Number}}</h2>
+  {{if eq .Status "void"}}<p class="void">FATURA ANULADA</p>{{end}}
+  <table class="meta">
+    {{if .Document}}<tr><td>CNPJ/CPF</td><td>{{.Document}}</td></tr>{{end}}
+    {{if .Address}}<tr><td>Endereço</td><td>{{.Address}}</td></tr>{{end}}
+    <tr><td>Emissão</td><td>{{.IssuedAt}}</td></tr>
+    {{if .Period}}<tr><td>Período</td><td>{{.Period}}</td></tr>{{end}}
+  </table>
+  <table class="lines">
+    <thead><tr><th>Descrição</th><th class="amount">Valor</th></tr></thead>
+    <tbody>
+    {{range .Lines}}<tr><td>{{.Description}}</td><td class="amount">{{.Amount}}</td></tr>
+    {{end}}{{if ne .Subtotal .Total}}<tr><td>Subtotal</td><td class="amount">{{.Subtotal}}</td></tr>
+    {{end}}<tr class="total"><td>Total</td><td class="amount">{{.Total}}</td></tr>
+    </tbody>
+  </table>
+  {{if .Taxes}}<div class="taxes">
+    <p>Tributos aproximados inclusos no valor (Lei 12.741/2012):</p>
+    <ul>{{range .Taxes}}<li>{{.Description}}: {{.Amount}}</li>{{end}}</ul>
+  </div>{{end}}
+</main>
+</body>
+</html>
+`))
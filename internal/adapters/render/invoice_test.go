@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+func testInvoice() *domain.Invoice {
+	start := time.Date(2026, 5, 1, 9, 0, 0, 0, domain.BillingLocation)
+	end := start.AddDate(0, 1, 0)
+	return &domain.Invoice{
+		ID:          "inv-1",
+		AcademyID:   "academy-1",
+		Number:      42,
+		Status:      domain.InvoiceStatusIssued,
+		PeriodStart: &start,
+		PeriodEnd:   &end,
+		Lines: []domain.InvoiceLine{
+			{Kind: domain.InvoiceLinePlan, Description: "Plano Pro (mensal)", Amount: 19900},
+			{Kind: domain.InvoiceLineDiscount, Description: "Desconto de cupom", Amount: -1990},
+			{Kind: domain.InvoiceLineTax, Description: "ISS aproximado (2%)", Amount: 358, Included: true},
+		},
+		Total:    17910,
+		IssuedAt: start,
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	r := NewInvoiceRenderer()
+	branding := &domain.Branding{
+		AcademyName:  "Gracie <Barra>",
+		LogoURL:      "javascript:alert(1)",
+		PrimaryColor: "#1a2b3c",
+	}
+
+	out, err := r.RenderHTML(context.Background(), testInvoice(), branding)
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	html := string(out)
+
+	for _, want := range []string{"Fatura nº 000042", "#1a2b3c", "R$ 199,00", "-R$ 19,90", "R$ 179,10", "01/05/2026 a 01/06/2026", "Gracie &lt;Barra&gt;", "Lei 12.741/2012"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML sem %q", want)
+		}
+	}
+	if strings.Contains(html, "javascript:") || strings.Contains(html, "<img") {
+		t.Error("logo com URL inválida não deveria ser renderizado")
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 20; y++ {
+			logo.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var logoPNG bytes.Buffer
+	if err := png.Encode(&logoPNG, logo); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/logo.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(logoPNG.Bytes())
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		logoURL  string
+		wantLogo bool
+	}{
+		{"with logo", srv.URL + "/logo.png", true},
+		{"logo unavailable", srv.URL + "/missing.png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewInvoiceRenderer()
+			out, err := r.RenderPDF(context.Background(), testInvoice(), &domain.Branding{AcademyName: "Academia Força", LogoURL: tt.logoURL, PrimaryColor: "#ffcc00"})
+			if err != nil {
+				t.Fatalf("RenderPDF() error = %v", err)
+			}
+
+			if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+				t.Fatal("cabeçalho/rodapé PDF inválidos")
+			}
+			if got := bytes.Contains(out, []byte("/DCTDecode")); got != tt.wantLogo {
+				t.Errorf("logo embutido = %v, want %v", got, tt.wantLogo)
+			}
+			// Texto em WinAnsi: "ç" vira 0xE7
+			if !bytes.Contains(out, []byte("(Academia For\xe7a)")) {
+				t.Error("nome da academia não codificado em WinAnsi")
+			}
+			checkXref(t, out)
+		})
+	}
+}
+
+// checkXref confere que cada entrada da tabela xref aponta para o objeto certo
+func checkXref(t *testing.T, pdf []byte) {
+	t.Helper()
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("startxref ausente")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d não aponta para a tabela xref", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("xref do objeto %d aponta para %q", i+1, pdf[off:off+10])
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Formatos aceitos para o logo
+	"image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+)
+
+// Página A4 em pontos
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	marginX      = 40.0
+	headerHeight = 90.0
+	logoMaxSize  = 60.0
+	maxDescChars = 72
+)
+
+// jpegImage é um logo pronto para ser embutido no PDF (filtro DCTDecode)
+type jpegImage struct {
+	data          []byte
+	width, height int
+}
+
+// toJPEG decodifica o logo (PNG, JPEG ou GIF) e o recodifica como JPEG sobre
+// fundo branco, já que o PDF embute JPEG sem precisar decodificar
+func toJPEG(data []byte) (*jpegImage, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("logo em formato não suportado: %w", err)
+	}
+
+	bounds := src.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, src, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return &jpegImage{data: buf.Bytes(), width: bounds.Dx(), height: bounds.Dy()}, nil
+}
+
+// renderInvoicePDF escreve a fatura como um PDF de uma página
+func renderInvoicePDF(v *invoiceView, logo *jpegImage) ([]byte, error) {
+	var c pdfContent
+	r, g, b := parseHexColor(v.PrimaryColor)
+
+	// Cabeçalho na cor da academia, com o texto em contraste
+	c.fillRect(0, pageHeight-headerHeight, pageWidth, headerHeight, r, g, b)
+	textColor := 1.0
+	if 0.299*r+0.587*g+0.114*b > 0.6 {
+		textColor = 0
+	}
+	nameX := marginX
+	if logo != nil {
+		w, h := fitBox(float64(logo.width), float64(logo.height), logoMaxSize)
+		c.image("Im1", marginX, pageHeight-headerHeight/2-h/2, w, h)
+		nameX += w + 12
+	}
+	c.text("F2", 18, nameX, pageHeight-headerHeight/2-6, textColor, v.AcademyName)
+
+	y := pageHeight - headerHeight - 40
+	c.text("F2", 16, marginX, y, 0, "Fatura nº "+v.Number)
+	if v.Status == "void" {
+		c.textRGB("F2", 12, pageWidth-marginX-110, y, 0.8, 0, 0, "FATURA ANULADA")
+	}
+
+	y -= 24
+	for _, meta := range [][2]string{
+		{"CNPJ/CPF", v.Document},
+		{"Endereço", v.Address},
+		{"Emissão", v.IssuedAt},
+		{"Período", v.Period},
+	} {
+		if meta[1] == "" {
+			continue
+		}
+		c.text("F1", 10, marginX, y, 0.4, meta[0])
+		c.text("F1", 10, marginX+70, y, 0, meta[1])
+		y -= 15
+	}
+
+	// Itens
+	y -= 20
+	c.text("F2", 10, marginX, y, 0, "Descrição")
+	c.textRight("F2", 10, pageWidth-marginX, y, 0, "Valor")
+	y -= 6
+	c.line(marginX, y, pageWidth-marginX, y, 1.5, r, g, b)
+	for _, line := range v.Lines {
+		y -= 18
+		c.text("F1", 10, marginX, y, 0, truncate(line.Description, maxDescChars))
+		c.textRight("F3", 10, pageWidth-marginX, y, 0, line.Amount)
+		y -= 6
+		c.line(marginX, y, pageWidth-marginX, y, 0.5, 0.9, 0.9, 0.9)
+	}
+	if v.Subtotal != v.Total {
+		y -= 18
+		c.text("F1", 10, marginX, y, 0, "Subtotal")
+		c.textRight("F3", 10, pageWidth-marginX, y, 0, v.Subtotal)
+	}
+	y -= 20
+	c.text("F2", 12, marginX, y, 0, "Total")
+	c.textRight("F3", 12, pageWidth-marginX, y, 0, v.Total)
+
+	// Tributos aproximados (Lei 12.741/2012)
+	if len(v.Taxes) > 0 {
+		y -= 36
+		c.text("F1", 8, marginX, y, 0.4, "Tributos aproximados inclusos no valor (Lei 12.741/2012):")
+		for _, tax := range v.Taxes {
+			y -= 12
+			c.text("F1", 8, marginX, y, 0.4, tax.Description+": "+tax.Amount)
+		}
+	}
+
+	c.text("F1", 8, marginX, 30, 0.6, "Documento gerado pela BlackBelt")
+
+	return writePDF(c.buf.Bytes(), logo), nil
+}
+
+// writePDF monta a estrutura do arquivo (objetos, xref e trailer)
+func writePDF(content []byte, logo *jpegImage) []byte {
+	resources := "/Font << /F1 5 0 R /F2 6 0 R /F3 7 0 R >>"
+	if logo != nil {
+		resources += " /XObject << /Im1 8 0 R >>"
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << %s >> /Contents 4 0 R >>",
+			num(pageWidth), num(pageHeight), resources),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+	if logo != nil {
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream",
+			logo.width, logo.height, len(logo.data), logo.data))
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfContent acumula os operadores do content stream da página
+type pdfContent struct {
+	buf bytes.Buffer
+}
+
+func (c *pdfContent) fillRect(x, y, w, h, r, g, b float64) {
+	fmt.Fprintf(&c.buf, "%s %s %s rg %s %s %s %s re f\n", num(r), num(g), num(b), num(x), num(y), num(w), num(h))
+}
+
+func (c *pdfContent) line(x1, y1, x2, y2, width, r, g, b float64) {
+	fmt.Fprintf(&c.buf, "%s %s %s RG %s w %s %s m %s %s l S\n", num(r), num(g), num(b), num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+func (c *pdfContent) image(name string, x, y, w, h float64) {
+	fmt.Fprintf(&c.buf, "q %s 0 0 %s %s %s cm /%s Do Q\n", num(w), num(h), num(x), num(y), name)
+}
+
+// text escreve em tons de cinza (0 = preto, 1 = branco)
+func (c *pdfContent) text(font string, size, x, y, gray float64, s string) {
+	c.textRGB(font, size, x, y, gray, gray, gray, s)
+}
+
+func (c *pdfContent) textRGB(font string, size, x, y, r, g, b float64, s string) {
+	fmt.Fprintf(&c.buf, "BT /%s %s Tf %s %s %s rg %s %s Td (%s) Tj ET\n",
+		font, num(size), num(r), num(g), num(b), num(x), num(y), pdfString(s))
+}
+
+// textRight alinha o texto à direita de x. A largura só é exata para a fonte
+// monoespaçada (F3, Courier: 0,6 em por caractere); é o que os valores usam.
+func (c *pdfContent) textRight(font string, size, x, y, gray float64, s string) {
+	width := 0.6 * size * float64(len(winAnsi(s)))
+	c.text(font, size, x-width, y, gray, s)
+}
+
+// fitBox reduz w×h proporcionalmente para caber em um quadrado de lado max
+func fitBox(w, h, max float64) (float64, float64) {
+	if w <= 0 || h <= 0 {
+		return max, max
+	}
+	scale := max / w
+	if h > w {
+		scale = max / h
+	}
+	return w * scale, h * scale
+}
+
+// parseHexColor converte "#RRGGBB" em componentes 0..1
+func parseHexColor(hex string) (float64, float64, float64) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return 0, 0, 0
+	}
+	return float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255
+}
+
+// truncate limita o texto a n caracteres
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// num formata um número para o content stream (sem notação científica)
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// pdfString codifica em WinAnsi e escapa o texto para uma string literal do PDF
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, c := range winAnsi(s) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// winAnsiExtra mapeia os caracteres fora do Latin-1 presentes no WinAnsiEncoding
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// winAnsi converte UTF-8 para WinAnsiEncoding (cobre o português); o resto vira '?'
+func winAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			out = append(out, byte(r))
+		case winAnsiExtra[r] != 0:
+			out = append(out, winAnsiExtra[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,263 @@
+package supabase
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+const (
+	// DefaultJWKSRefresh é o intervalo padrão de releitura do JWK Set
+	DefaultJWKSRefresh = 10 * time.Minute
+
+	// minJWKSReload limita as releituras fora do intervalo (kid desconhecido
+	// ou falha na última leitura): tokens com kid aleatório não viram uma
+	// requisição ao Supabase cada
+	minJWKSReload = 30 * time.Second
+
+	maxJWKSSize = 1 << 20
+)
+
+// publicKey é uma chave do JWK Set com o algoritmo que ela assina
+type publicKey struct {
+	alg string // RS256 | ES256
+	key crypto.PublicKey
+}
+
+// JWKS mantém em cache as chaves públicas de um JWK Set, lido de um arquivo
+// ou de uma URL (ex: https://<projeto>.supabase.co/auth/v1/.well-known/jwks.json).
+// O set é relido a cada intervalo de refresh e quando chega um kid
+// desconhecido (chave nova após uma rotação). Se a releitura falhar, as
+// chaves já carregadas continuam valendo.
+type JWKS struct {
+	source     string
+	httpClient *http.Client
+	refresh    time.Duration
+	clock      domain.Clock
+
+	mu         sync.RWMutex
+	keys       map[string]publicKey
+	nextLoad   time.Time // Próxima releitura pelo intervalo
+	lastReload time.Time // Última tentativa de leitura
+}
+
+// NewJWKS cria o cache do JWK Set em source (caminho de arquivo ou URL
+// http/https). refresh <= 0 usa DefaultJWKSRefresh. Nada é lido até o
+// primeiro token (ou até Load).
+func NewJWKS(source string, refresh time.Duration) *JWKS {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	return &JWKS{
+		source:     source,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		refresh:    refresh,
+		clock:      domain.SystemClock{},
+	}
+}
+
+// SetClock troca o relógio usado no intervalo de refresh (testes)
+func (j *JWKS) SetClock(clock domain.Clock) {
+	j.clock = clock
+}
+
+// Load lê o JWK Set agora. Usado ao subir a API para falhar cedo com uma
+// configuração errada.
+func (j *JWKS) Load(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.reload(ctx)
+}
+
+// key retorna a chave do kid, relendo o set se o cache venceu ou se o kid
+// ainda não é conhecido
+func (j *JWKS) key(ctx context.Context, kid string) (publicKey, error) {
+	if kid == "" {
+		return publicKey{}, fmt.Errorf("%w: token sem kid", ports.ErrInvalidToken)
+	}
+
+	now := j.clock.Now()
+	j.mu.RLock()
+	k, ok := j.keys[kid]
+	fresh := now.Before(j.nextLoad)
+	j.mu.RUnlock()
+	if ok && fresh {
+		return k, nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// Outra goroutine pode ter relido enquanto esperávamos o lock
+	if k, ok := j.keys[kid]; ok && now.Before(j.nextLoad) {
+		return k, nil
+	}
+	_, known := j.keys[kid]
+	if !now.Before(j.nextLoad) || (!known && now.Sub(j.lastReload) >= minJWKSReload) {
+		if err := j.reload(ctx); err != nil {
+			log.Printf("[Auth] Erro ao ler JWKS de %s: %v", j.source, err)
+			if len(j.keys) == 0 {
+				return publicKey{}, fmt.Errorf("JWKS indisponível: %w", err)
+			}
+		}
+	}
+	if k, ok := j.keys[kid]; ok {
+		return k, nil
+	}
+	return publicKey{}, fmt.Errorf("%w: kid %q desconhecido", ports.ErrInvalidToken, kid)
+}
+
+// reload lê e troca o set (com o lock de escrita). Em caso de erro as chaves
+// atuais são mantidas e a próxima tentativa espera minJWKSReload.
+func (j *JWKS) reload(ctx context.Context) error {
+	now := j.clock.Now()
+	j.lastReload = now
+
+	data, err := j.read(ctx)
+	if err == nil {
+		var keys map[string]publicKey
+		if keys, err = parseJWKS(data); err == nil {
+			j.keys = keys
+			j.nextLoad = now.Add(j.refresh)
+			return nil
+		}
+	}
+	j.nextLoad = now.Add(minJWKSReload)
+	return err
+}
+
+// read lê o conteúdo do JWK Set do arquivo ou da URL
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// jwk é uma chave de um JWK Set (RFC 7517); só os campos de RSA e EC
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS lê as chaves de assinatura RS256 e ES256 (P-256) do set. Chaves
+// de outros tipos ou usos são ignoradas; um set sem nenhuma utilizável é erro.
+func parseJWKS(data []byte) (map[string]publicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("JWKS malformado: %w", err)
+	}
+
+	keys := make(map[string]publicKey)
+	for _, k := range set.Keys {
+		if k.Kid == "" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		var (
+			key publicKey
+			err error
+		)
+		switch {
+		case k.Kty == "RSA" && (k.Alg == "" || k.Alg == "RS256"):
+			key, err = parseRSAKey(k)
+		case k.Kty == "EC" && k.Crv == "P-256" && (k.Alg == "" || k.Alg == "ES256"):
+			key, err = parseECKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("chave %s inválida: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS sem chaves RS256 ou ES256")
+	}
+	return keys, nil
+}
+
+// parseRSAKey monta a chave RSA a partir do módulo e do expoente
+func parseRSAKey(k jwk) (publicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return publicKey{}, err
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return publicKey{}, err
+	}
+	if n.BitLen() < 2048 || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return publicKey{}, fmt.Errorf("parâmetros RSA inválidos")
+	}
+	return publicKey{alg: "RS256", key: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+}
+
+// parseECKey monta a chave P-256, recusando pontos fora da curva
+func parseECKey(k jwk) (publicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return publicKey{}, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return publicKey{}, err
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return publicKey{}, fmt.Errorf("coordenadas P-256 com tamanho errado")
+	}
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return publicKey{}, fmt.Errorf("ponto fora da curva P-256")
+	}
+	return publicKey{alg: "ES256", key: &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}}, nil
+}
+
+// decodeBigInt decodifica um inteiro em base64url
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("inteiro base64url inválido")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
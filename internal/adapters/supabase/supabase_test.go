@@ -0,0 +1,318 @@
+package supabase
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+const testSecret = "super-secret-jwt-token-with-at-least-32-characters"
+
+var (
+	testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+)
+
+// claims padrão de um usuário logado, válidas em testNow
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":        "user-1",
+		"email":      "dono@academia.com",
+		"role":       "authenticated",
+		"aud":        "authenticated",
+		"iss":        "https://projeto.supabase.co/auth/v1",
+		"session_id": "sess-1",
+		"exp":        testNow.Add(time.Hour).Unix(),
+		"iat":        testNow.Unix(),
+	}
+}
+
+// sign monta o JWT com o algoritmo informado
+func sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	header := map[string]any{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, []byte(testSecret))
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case "RS256":
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// jwksJSON publica a chave RSA como kid rsaKid e a EC como "ec-1"
+func jwksJSON(t *testing.T, rsaKid string) []byte {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	coord := func(n *big.Int) string { return b64(n.FillBytes(make([]byte, 32))) }
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": rsaKid, "alg": "RS256", "use": "sig",
+			"n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "alg": "ES256",
+			"x": coord(ecKey.X), "y": coord(ecKey.Y)},
+		{"kty": "oct", "kid": "ignorada", "k": "c2VjcmV0"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func writeJWKS(t *testing.T, rsaKid string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, rsaKid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestVerifier(t *testing.T, opts VerifierOptions) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SetClock(domain.NewFakeClock(testNow))
+	if opts.JWKS != nil {
+		opts.JWKS.SetClock(domain.NewFakeClock(testNow))
+	}
+	return v
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	jwks := NewJWKS(writeJWKS(t, "rsa-1"), 0)
+	v := newTestVerifier(t, VerifierOptions{
+		Secret:   testSecret,
+		JWKS:     jwks,
+		Audience: DefaultAudience,
+		Issuer:   "https://projeto.supabase.co/auth/v1",
+	})
+
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+	unsigned := encodeSegment(t, map[string]any{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+	tampered := sign(t, "HS256", "", validClaims())
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"HS256", sign(t, "HS256", "", validClaims()), false},
+		{"RS256", sign(t, "RS256", "rsa-1", validClaims()), false},
+		{"ES256", sign(t, "ES256", "ec-1", validClaims()), false},
+		{"aud em lista", sign(t, "HS256", "", with("aud", []string{"outra", "authenticated"})), false},
+		{"expirado dentro da tolerância", sign(t, "HS256", "", with("exp", testNow.Add(-10*time.Second).Unix())), false},
+		{"expirado", sign(t, "HS256", "", with("exp", testNow.Add(-time.Minute).Unix())), true},
+		{"sem exp", sign(t, "HS256", "", with("exp", nil)), true},
+		{"sem sub", sign(t, "HS256", "", with("sub", nil)), true},
+		{"nbf no futuro", sign(t, "HS256", "", with("nbf", testNow.Add(time.Hour).Unix())), true},
+		{"audiência errada", sign(t, "HS256", "", with("aud", "anon")), true},
+		{"emissor errado", sign(t, "HS256", "", with("iss", "https://outro.supabase.co/auth/v1")), true},
+		{"assinatura adulterada", tampered, true},
+		{"alg none", unsigned, true},
+		{"kid desconhecido", sign(t, "RS256", "rsa-9", validClaims()), true},
+		{"RS256 com kid da chave EC", sign(t, "RS256", "ec-1", validClaims()), true},
+		{"sem kid", sign(t, "ES256", "", validClaims()), true},
+		{"formato inválido", "abc.def", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ports.ErrInvalidToken) {
+					t.Errorf("erro %v não embrulha ErrInvalidToken", err)
+				}
+				return
+			}
+			if claims.Subject != "user-1" || claims.Email != "dono@academia.com" || claims.SessionID != "sess-1" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifier_DisabledAlgorithms(t *testing.T) {
+	ctx := context.Background()
+
+	// Só HS256: tokens assimétricos são recusados
+	hsOnly := newTestVerifier(t, VerifierOptions{Secret: testSecret})
+	if _, err := hsOnly.Verify(ctx, sign(t, "RS256", "rsa-1", validClaims())); !errors.Is(err, ports.ErrInvalidToken) {
+		t.Errorf("RS256 sem JWKS: erro = %v", err)
+	}
+
+	// Só JWKS: um token HS256 (ex: assinado com a chave pública como secret) é recusado
+	jwksOnly := newTestVerifier(t, VerifierOptions{JWKS: NewJWKS(writeJWKS(t, "rsa-1"), 0)})
+	if _, err := jwksOnly.Verify(ctx, sign(t, "HS256", "rsa-1", validClaims())); !errors.Is(err, ports.ErrInvalidToken) {
+		t.Errorf("HS256 sem secret: erro = %v", err)
+	}
+
+	if _, err := NewVerifier(VerifierOptions{}); err == nil {
+		t.Error("NewVerifier sem secret nem JWKS deveria falhar")
+	}
+}
+
+func TestJWKS_RotationFromURL(t *testing.T) {
+	ctx := context.Background()
+	var (
+		mu       sync.Mutex
+		kid      = "rsa-1"
+		requests int
+		fail     bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if fail {
+			http.Error(w, "fora do ar", http.StatusBadGateway)
+			return
+		}
+		w.Write(jwksJSON(t, kid))
+	}))
+	defer server.Close()
+
+	clock := domain.NewFakeClock(testNow)
+	jwks := NewJWKS(server.URL, 10*time.Minute)
+	jwks.SetClock(clock)
+	v, _ := NewVerifier(VerifierOptions{JWKS: jwks})
+	v.SetClock(clock)
+	verify := func(kid string) error {
+		_, err := v.Verify(ctx, sign(t, "RS256", kid, validClaims()))
+		return err
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+
+	if err := verify("rsa-1"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := verify("rsa-1"); err != nil || count() != 1 {
+		t.Fatalf("cache: erro = %v, requisições = %d, want 1", err, count())
+	}
+
+	// Rotação: o Supabase passa a publicar a chave como rsa-2
+	mu.Lock()
+	kid = "rsa-2"
+	mu.Unlock()
+	if err := verify("rsa-2"); !errors.Is(err, ports.ErrInvalidToken) || count() != 1 {
+		t.Fatalf("kid novo logo após a leitura: erro = %v, requisições = %d (releitura limitada)", err, count())
+	}
+	clock.Advance(time.Minute)
+	if err := verify("rsa-2"); err != nil || count() != 2 {
+		t.Fatalf("kid novo: erro = %v, requisições = %d, want releitura", err, count())
+	}
+	if err := verify("rsa-1"); !errors.Is(err, ports.ErrInvalidToken) {
+		t.Errorf("kid removido na rotação: erro = %v", err)
+	}
+
+	// JWKS fora do ar: as chaves em cache continuam valendo após o refresh
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	clock.Advance(15 * time.Minute)
+	if err := verify("rsa-2"); err != nil {
+		t.Errorf("JWKS fora do ar com cache: erro = %v", err)
+	}
+
+	// Sem nenhuma chave carregada, o erro não é do token
+	empty := NewJWKS(server.URL, 0)
+	empty.SetClock(clock)
+	v, _ = NewVerifier(VerifierOptions{JWKS: empty})
+	v.SetClock(clock)
+	if err := verify("rsa-2"); err == nil || errors.Is(err, ports.ErrInvalidToken) {
+		t.Errorf("JWKS indisponível: erro = %v, want erro de infraestrutura", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"JSON inválido", `{"keys":`, true},
+		{"sem chaves utilizáveis", `{"keys":[{"kty":"oct","kid":"a","k":"c2VjcmV0"}]}`, true},
+		{"RSA curta", `{"keys":[{"kty":"RSA","kid":"a","n":"AQAB","e":"AQAB"}]}`, true},
+		{"EC fora da curva", `{"keys":[{"kty":"EC","kid":"a","crv":"P-256",` +
+			`"x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE","y":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE"}]}`, true},
+		{"chave de criptografia ignorada", `{"keys":[{"kty":"RSA","kid":"a","use":"enc","n":"AQAB","e":"AQAB"}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseJWKS([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("parseJWKS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	keys, err := parseJWKS(jwksJSON(t, "rsa-1"))
+	if err != nil || len(keys) != 2 || keys["rsa-1"].alg != "RS256" || keys["ec-1"].alg != "ES256" {
+		t.Errorf("parseJWKS() = %v, %v", keys, err)
+	}
+}
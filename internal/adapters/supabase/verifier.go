@@ -0,0 +1,231 @@
+// Package supabase implementa a verificação dos JWTs emitidos pelo Supabase Auth.
+//
+// Projetos com o JWT secret compartilhado assinam com HS256; projetos com
+// chaves assimétricas assinam com RS256 ou ES256 e publicam as chaves públicas
+// em um JWK Set, lido pelo JWKS (arquivo ou URL, com cache e rotação).
+package supabase
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// DefaultAudience é a audiência dos tokens de usuários logados no Supabase
+const DefaultAudience = "authenticated"
+
+// clockSkew tolera diferença de relógio entre o Supabase e a API
+const clockSkew = 30 * time.Second
+
+// VerifierOptions configura os algoritmos e as claims aceitas
+type VerifierOptions struct {
+	Secret   string // JWT secret do projeto (HS256); vazio desabilita HS256
+	JWKS     *JWKS  // Chaves públicas (RS256/ES256); nil desabilita
+	Audience string // Audiência exigida (vazio não confere)
+	Issuer   string // Emissor exigido, ex: https://<projeto>.supabase.co/auth/v1 (vazio não confere)
+}
+
+// Verifier implementa ports.TokenVerifier para os tokens do Supabase Auth
+type Verifier struct {
+	opts  VerifierOptions
+	clock domain.Clock
+}
+
+// NewVerifier cria o verificador; exige o secret, o JWKS ou os dois
+func NewVerifier(opts VerifierOptions) (*Verifier, error) {
+	if opts.Secret == "" && opts.JWKS == nil {
+		return nil, fmt.Errorf("informe o JWT secret ou o JWKS do Supabase")
+	}
+	return &Verifier{opts: opts, clock: domain.SystemClock{}}, nil
+}
+
+// SetClock troca o relógio usado na expiração (testes)
+func (v *Verifier) SetClock(clock domain.Clock) {
+	v.clock = clock
+}
+
+// jwtHeader é o cabeçalho do JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims são as claims lidas dos tokens do Supabase
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	Role      string   `json:"role"`
+	SessionID string   `json:"session_id"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// audience aceita "aud" como string ou lista (RFC 7519)
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("aud inválido")
+	}
+	*a = list
+	return nil
+}
+
+// Verify valida o token e retorna as claims. Erros de token embrulham
+// ports.ErrInvalidToken; um JWKS inacessível (sem chaves em cache) não.
+func (v *Verifier) Verify(ctx context.Context, token string) (*ports.TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: formato inválido", ports.ErrInvalidToken)
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: cabeçalho: %v", ports.ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: assinatura malformada", ports.ErrInvalidToken)
+	}
+	if err := v.verifySignature(ctx, header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ports.ErrInvalidToken, err)
+	}
+	return v.validateClaims(&claims)
+}
+
+// verifySignature confere a assinatura com o algoritmo do cabeçalho. O
+// algoritmo precisa estar habilitado e, no JWKS, ser o da chave do kid (um
+// token HS256 nunca é verificado com uma chave pública).
+func (v *Verifier) verifySignature(ctx context.Context, header jwtHeader, signed string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+
+	switch header.Alg {
+	case "HS256":
+		if v.opts.Secret == "" {
+			return fmt.Errorf("%w: HS256 não habilitado", ports.ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, []byte(v.opts.Secret))
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: assinatura não confere", ports.ErrInvalidToken)
+		}
+		return nil
+
+	case "RS256", "ES256":
+		if v.opts.JWKS == nil {
+			return fmt.Errorf("%w: %s não habilitado", ports.ErrInvalidToken, header.Alg)
+		}
+		key, err := v.opts.JWKS.key(ctx, header.Kid)
+		if err != nil {
+			return err
+		}
+		if key.alg != header.Alg {
+			return fmt.Errorf("%w: algoritmo %s não confere com a chave %s", ports.ErrInvalidToken, header.Alg, header.Kid)
+		}
+		if !verifyAsymmetric(key.key, digest[:], signature) {
+			return fmt.Errorf("%w: assinatura não confere", ports.ErrInvalidToken)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: algoritmo %q não suportado", ports.ErrInvalidToken, header.Alg)
+}
+
+// verifyAsymmetric confere uma assinatura RS256 (PKCS#1 v1.5) ou ES256 (r||s)
+func verifyAsymmetric(key crypto.PublicKey, digest, signature []byte) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(k, digest, r, s)
+	}
+	return false
+}
+
+// validateClaims exige sub e exp e confere validade, audiência e emissor
+func (v *Verifier) validateClaims(c *jwtClaims) (*ports.TokenClaims, error) {
+	now := v.clock.Now()
+	switch {
+	case c.Subject == "":
+		return nil, fmt.Errorf("%w: sem sub", ports.ErrInvalidToken)
+	case c.ExpiresAt == nil:
+		return nil, fmt.Errorf("%w: sem exp", ports.ErrInvalidToken)
+	}
+	expiresAt := unixTime(*c.ExpiresAt)
+	if !now.Before(expiresAt.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: expirado em %s", ports.ErrInvalidToken, expiresAt.Format(time.RFC3339))
+	}
+	if c.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*c.NotBefore)) {
+		return nil, fmt.Errorf("%w: ainda não válido", ports.ErrInvalidToken)
+	}
+	if v.opts.Issuer != "" && c.Issuer != v.opts.Issuer {
+		return nil, fmt.Errorf("%w: emissor %q", ports.ErrInvalidToken, c.Issuer)
+	}
+	if v.opts.Audience != "" && !c.Audience.contains(v.opts.Audience) {
+		return nil, fmt.Errorf("%w: audiência %v", ports.ErrInvalidToken, []string(c.Audience))
+	}
+
+	return &ports.TokenClaims{
+		Subject:   c.Subject,
+		Email:     c.Email,
+		Role:      c.Role,
+		SessionID: c.SessionID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodifica um segmento base64url com JSON
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("base64 inválido")
+	}
+	return json.Unmarshal(data, v)
+}
+
+// unixTime converte uma NumericDate (segundos, possivelmente fracionários)
+func unixTime(seconds float64) time.Time {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9))
+}
+
+// Garante que Verifier implementa ports.TokenVerifier
+var _ ports.TokenVerifier = (*Verifier)(nil)
@@ -0,0 +1,96 @@
+// Package webhook envia os eventos de domínio para um endpoint HTTP externo
+// (webhooks de saída), assinados com HMAC-SHA256
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Cabeçalhos enviados com cada evento
+const (
+	HeaderEventID   = "X-BlackBelt-Event-ID"
+	HeaderEventType = "X-BlackBelt-Event-Type"
+	HeaderSignature = "X-BlackBelt-Signature" // "sha256=<hex do HMAC do corpo>"
+)
+
+// defaultTimeout limita cada entrega quando Config.Timeout é zero
+const defaultTimeout = 10 * time.Second
+
+// Config configura o destino dos webhooks de saída
+type Config struct {
+	URL     string
+	Secret  string        // Chave do HMAC; vazio envia sem assinatura
+	Timeout time.Duration // Zero = defaultTimeout
+}
+
+// Publisher implementa ports.EventPublisher com um POST JSON por evento
+type Publisher struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewPublisher cria o publisher
+func NewPublisher(cfg Config) (*Publisher, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("URL do webhook de saída é obrigatória")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Publisher{cfg: cfg, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Publish envia o evento. Respostas fora de 2xx são erro, e o dispatcher
+// reentrega o evento com backoff.
+func (p *Publisher) Publish(ctx context.Context, event domain.DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento %s: %w", event.ID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição do webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID)
+	req.Header.Set(HeaderEventType, string(event.Type))
+	if p.cfg.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(p.cfg.Secret, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao enviar webhook %s: %w", event.ID, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s recusado pelo destino: status %d", event.ID, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign calcula o cabeçalho de assinatura do corpo, para o destino validar
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Garante que Publisher implementa ports.EventPublisher
+var _ ports.EventPublisher = (*Publisher)(nil)
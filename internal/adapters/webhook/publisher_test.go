@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+func testEvent() domain.DomainEvent {
+	return domain.DomainEvent{
+		ID:            "e1",
+		Type:          domain.EventSubscriptionCanceled,
+		AggregateType: domain.AggregateSubscription,
+		AggregateID:   "sub-1",
+		AcademyID:     "academy-1",
+		Data:          map[string]string{"reason": "preço"},
+		OccurredAt:    time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	var (
+		headers http.Header
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	publisher, err := NewPublisher(Config{URL: server.URL, Secret: "segredo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Publish(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if headers.Get(HeaderEventID) != "e1" || headers.Get(HeaderEventType) != string(domain.EventSubscriptionCanceled) {
+		t.Errorf("cabeçalhos = %v", headers)
+	}
+	if got, want := headers.Get(HeaderSignature), Sign("segredo", body); got != want {
+		t.Errorf("assinatura = %q, want %q", got, want)
+	}
+	var got domain.DomainEvent
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("corpo inválido: %v", err)
+	}
+	if got.ID != "e1" || got.AcademyID != "academy-1" || got.Data["reason"] != "preço" {
+		t.Errorf("evento = %+v", got)
+	}
+}
+
+func TestPublisher_RejectedIsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	publisher, _ := NewPublisher(Config{URL: server.URL})
+	if err := publisher.Publish(context.Background(), testEvent()); err == nil {
+		t.Fatal("esperava erro para status 503")
+	}
+}
+
+func TestNewPublisher_RequiresURL(t *testing.T) {
+	if _, err := NewPublisher(Config{}); err == nil {
+		t.Fatal("esperava erro sem URL")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,8 +21,29 @@ type Config struct {
 	// Efí Bank
 	Efi EfiConfig
 
+	// Stripe (fallback quando o PIX está indisponível)
+	Stripe StripeConfig
+
 	// Webhook
 	Webhook WebhookConfig
+
+	// Webhooks de saída (eventos de domínio enviados a um endpoint externo)
+	OutboundWebhook OutboundWebhookConfig
+
+	// NFS-e
+	NFSe NFSeConfig
+
+	// Banco de dados
+	Database DatabaseConfig
+
+	// Armazenamento dos repositórios (postgres ou memória)
+	Storage StorageConfig
+
+	// Endpoints administrativos
+	Admin AdminConfig
+
+	// Autenticação (JWT do Supabase Auth)
+	Auth AuthConfig
 }
 
 // EfiConfig armazena configurações específicas da Efí Bank
@@ -31,12 +54,135 @@ type EfiConfig struct {
 	CertificatePassword string
 	Sandbox             bool
 	PixURL              string
+	Fake                bool // Usa o fake em memória (efitest) em vez da API; só fora de produção
+
+	// Circuit breaker (zero = valor padrão do adaptador)
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenProbes   int
+}
+
+// StripeConfig armazena configurações do Stripe
+type StripeConfig struct {
+	SecretKey     string
+	WebhookSecret string
+}
+
+// Enabled indica se o Stripe está configurado
+func (c StripeConfig) Enabled() bool {
+	return c.SecretKey != ""
+}
+
+// NFSeConfig armazena o provider de NFS-e e os dados fiscais do prestador
+type NFSeConfig struct {
+	Provider  string // "local"
+	LocalDir  string
+	RPSSeries string
+
+	CNPJ                      string
+	InscricaoMunicipal        string
+	CodigoMunicipio           string
+	ItemListaServico          string
+	CodigoTributacaoMunicipio string
+	AliquotaISS               int // pontos-base
+	SimplesNacional           bool
+}
+
+// Enabled indica se a emissão de NFS-e está configurada
+func (c NFSeConfig) Enabled() bool {
+	return c.Provider != "" && c.CNPJ != ""
+}
+
+// DatabaseConfig armazena a conexão com o PostgreSQL e a política de migrations
+type DatabaseConfig struct {
+	URL            string
+	MigrateOnStart bool // Aplica as migrations pendentes ao subir a API
+}
+
+// Enabled indica se o banco está configurado
+func (c DatabaseConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// Drivers de armazenamento
+const (
+	StorageMemory   = "memory"   // Repositórios em memória, perdidos ao reiniciar (desenvolvimento)
+	StoragePostgres = "postgres" // Repositórios no PostgreSQL (DATABASE_URL)
+)
+
+// StorageConfig escolhe a implementação dos repositórios
+type StorageConfig struct {
+	Driver string // StorageMemory | StoragePostgres
 }
 
 // WebhookConfig armazena configurações de webhook
 type WebhookConfig struct {
 	URL    string
 	Secret string
+
+	// Retentativas de eventos falhos (backoff exponencial com jitter)
+	RetryBase     time.Duration
+	RetryMax      time.Duration
+	RetryJitter   float64 // Fração aleatória somada ao intervalo (0.2 = até 20%)
+	MaxRetries    int     // Falhas antes da dead-letter
+	RetryInterval time.Duration
+
+	// Evento em processing há mais que isso é tido como abandonado e retomado
+	ProcessingLease time.Duration
+}
+
+// OutboundWebhookConfig configura o envio dos eventos de domínio
+type OutboundWebhookConfig struct {
+	URL     string
+	Secret  string        // Chave do HMAC-SHA256 no cabeçalho X-BlackBelt-Signature
+	Timeout time.Duration // Limite de cada entrega
+}
+
+// Enabled indica se os webhooks de saída estão configurados
+func (c OutboundWebhookConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// AdminConfig protege os endpoints administrativos
+type AdminConfig struct {
+	Token string // Bearer token; vazio desabilita os endpoints
+}
+
+// AuthConfig configura a validação dos JWTs do Supabase Auth: HS256 com o
+// secret do projeto e/ou RS256/ES256 com as chaves públicas do JWKS
+type AuthConfig struct {
+	SupabaseURL string        // https://<projeto>.supabase.co: emissor exigido e URL padrão do JWKS
+	JWTSecret   string        // Secret compartilhado (HS256); vazio desabilita HS256
+	JWKSURL     string        // JWK Set; padrão <SupabaseURL>/auth/v1/.well-known/jwks.json
+	JWKSFile    string        // JWK Set em arquivo (tem prioridade sobre a URL)
+	JWKSRefresh time.Duration // Intervalo de releitura do JWK Set (rotação de chaves)
+	Audience    string        // Audiência exigida nos tokens
+}
+
+// Enabled indica se há como validar tokens
+func (c AuthConfig) Enabled() bool {
+	return c.JWTSecret != "" || c.JWKSSource() != ""
+}
+
+// JWKSSource é o arquivo ou a URL do JWK Set (vazio desabilita RS256/ES256)
+func (c AuthConfig) JWKSSource() string {
+	switch {
+	case c.JWKSFile != "":
+		return c.JWKSFile
+	case c.JWKSURL != "":
+		return c.JWKSURL
+	case c.SupabaseURL != "":
+		return strings.TrimRight(c.SupabaseURL, "/") + "/auth/v1/.well-known/jwks.json"
+	}
+	return ""
+}
+
+// Issuer é o emissor dos tokens do projeto (vazio não confere)
+func (c AuthConfig) Issuer() string {
+	if c.SupabaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(c.SupabaseURL, "/") + "/auth/v1"
 }
 
 // Load carrega as configurações do arquivo .env e variáveis de ambiente
@@ -55,12 +201,60 @@ func Load() (*Config, error) {
 			CertificatePassword: getEnv("EFI_CERTIFICATE_PASSWORD", ""),
 			Sandbox:             getEnvBool("EFI_SANDBOX", true),
 			PixURL:              getEnv("EFI_PIX_URL", "https://pix-h.api.efipay.com.br"),
+			Fake:                getEnvBool("EFI_FAKE", false),
+
+			BreakerFailureThreshold: getEnvInt("EFI_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerOpenTimeout:      getEnvDuration("EFI_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+			BreakerHalfOpenProbes:   getEnvInt("EFI_BREAKER_HALF_OPEN_PROBES", 1),
+		},
+		Stripe: StripeConfig{
+			SecretKey:     getEnv("STRIPE_SECRET_KEY", ""),
+			WebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
 		},
 		Webhook: WebhookConfig{
 			URL:    getEnv("WEBHOOK_URL", ""),
 			Secret: getEnv("WEBHOOK_SECRET", ""),
+
+			RetryBase:     getEnvDuration("WEBHOOK_RETRY_BASE", time.Minute),
+			RetryMax:      getEnvDuration("WEBHOOK_RETRY_MAX", time.Hour),
+			RetryJitter:   getEnvFloat("WEBHOOK_RETRY_JITTER", 0.2),
+			MaxRetries:    getEnvInt("WEBHOOK_MAX_RETRIES", 5),
+			RetryInterval: getEnvDuration("WEBHOOK_RETRY_INTERVAL", 30*time.Second),
+
+			ProcessingLease: getEnvDuration("WEBHOOK_PROCESSING_LEASE", 5*time.Minute),
+		},
+		OutboundWebhook: OutboundWebhookConfig{
+			URL:     getEnv("OUTBOUND_WEBHOOK_URL", ""),
+			Secret:  getEnv("OUTBOUND_WEBHOOK_SECRET", ""),
+			Timeout: getEnvDuration("OUTBOUND_WEBHOOK_TIMEOUT", 10*time.Second),
+		},
+		Admin: AdminConfig{
+			Token: getEnv("ADMIN_API_TOKEN", ""),
+		},
+		Auth: AuthConfig{
+			SupabaseURL: getEnv("SUPABASE_URL", ""),
+			JWTSecret:   getEnv("SUPABASE_JWT_SECRET", ""),
+			JWKSURL:     getEnv("SUPABASE_JWKS_URL", ""),
+			JWKSFile:    getEnv("SUPABASE_JWKS_FILE", ""),
+			JWKSRefresh: getEnvDuration("SUPABASE_JWKS_REFRESH", 10*time.Minute),
+			Audience:    getEnv("SUPABASE_JWT_AUDIENCE", "authenticated"),
 		},
+		NFSe: NFSeConfig{
+			Provider:  getEnv("NFSE_PROVIDER", "local"),
+			LocalDir:  getEnv("NFSE_LOCAL_DIR", "./var/nfse"),
+			RPSSeries: getEnv("NFSE_RPS_SERIES", "A"),
+
+			CNPJ:                      getEnv("NFSE_CNPJ", ""),
+			InscricaoMunicipal:        getEnv("NFSE_INSCRICAO_MUNICIPAL", ""),
+			CodigoMunicipio:           getEnv("NFSE_CODIGO_MUNICIPIO", ""),
+			ItemListaServico:          getEnv("NFSE_ITEM_LISTA_SERVICO", "01.05"),
+			CodigoTributacaoMunicipio: getEnv("NFSE_CODIGO_TRIBUTACAO_MUNICIPIO", ""),
+			AliquotaISS:               getEnvInt("NFSE_ALIQUOTA_ISS", 200),
+			SimplesNacional:           getEnvBool("NFSE_SIMPLES_NACIONAL", false),
+		},
+		Database: loadDatabase(),
 	}
+	cfg.Storage = StorageConfig{Driver: getEnv("STORAGE_DRIVER", defaultStorageDriver(cfg.Database))}
 
 	// Validação básica
 	if err := cfg.validate(); err != nil {
@@ -70,8 +264,60 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// LoadDatabase carrega só a configuração do banco (subcomando migrate, que
+// não precisa das credenciais dos gateways)
+func LoadDatabase() (DatabaseConfig, error) {
+	_ = godotenv.Load()
+
+	cfg := loadDatabase()
+	if !cfg.Enabled() {
+		return cfg, fmt.Errorf("DATABASE_URL é obrigatório")
+	}
+	return cfg, nil
+}
+
+// loadDatabase lê as variáveis do banco
+func loadDatabase() DatabaseConfig {
+	return DatabaseConfig{
+		URL:            getEnv("DATABASE_URL", ""),
+		MigrateOnStart: getEnvBool("DATABASE_MIGRATE_ON_START", false),
+	}
+}
+
+// defaultStorageDriver usa o PostgreSQL quando há banco configurado e a memória caso contrário
+func defaultStorageDriver(db DatabaseConfig) string {
+	if db.Enabled() {
+		return StoragePostgres
+	}
+	return StorageMemory
+}
+
 // validate verifica se as configurações obrigatórias estão presentes
 func (c *Config) validate() error {
+	switch c.Storage.Driver {
+	case StorageMemory:
+		if c.IsProduction() {
+			return fmt.Errorf("STORAGE_DRIVER=memory não é permitido em produção")
+		}
+	case StoragePostgres:
+		if !c.Database.Enabled() {
+			return fmt.Errorf("DATABASE_URL é obrigatório com STORAGE_DRIVER=postgres")
+		}
+	default:
+		return fmt.Errorf("STORAGE_DRIVER inválido: %q (use memory ou postgres)", c.Storage.Driver)
+	}
+
+	if c.IsProduction() && !c.Auth.Enabled() {
+		return fmt.Errorf("SUPABASE_JWT_SECRET, SUPABASE_URL ou SUPABASE_JWKS_URL/SUPABASE_JWKS_FILE é obrigatório em produção")
+	}
+
+	if c.Efi.Fake {
+		// O fake dispensa credenciais e certificado
+		if c.IsProduction() {
+			return fmt.Errorf("EFI_FAKE não é permitido em produção")
+		}
+		return nil
+	}
 	if c.Efi.ClientID == "" {
 		return fmt.Errorf("EFI_CLIENT_ID é obrigatório")
 	}
@@ -114,3 +360,42 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return parsed
 }
+
+// getEnvInt obtém uma variável de ambiente como int
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvFloat obtém uma variável de ambiente como float64
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvDuration obtém uma variável de ambiente como time.Duration (ex: "30s", "2m")
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
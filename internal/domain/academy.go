@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Padrões da academia (alinhados com os DEFAULTs da tabela academies)
+const (
+	DefaultAcademyCountry      = "BR"
+	DefaultAcademyPrimaryColor = "#000000"
+
+	InviteCodeLength = 8
+	maxSlugLength    = 60
+)
+
+// inviteCodeAlphabet evita caracteres confundíveis (0/O, 1/I/L) em códigos ditados
+const inviteCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	slugPattern     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// AcademyAddress é o endereço da academia. CityCode (IBGE) e District só são
+// exigidos para emitir NFS-e.
+type AcademyAddress struct {
+	Street     string `json:"street,omitempty"`
+	Number     string `json:"number,omitempty"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district,omitempty"`
+	City       string `json:"city,omitempty"`
+	CityCode   string `json:"city_code,omitempty"` // Código IBGE do município (7 dígitos)
+	State      string `json:"state,omitempty"`     // UF
+	ZIP        string `json:"zip,omitempty"`
+	Country    string `json:"country"`
+}
+
+// String formata o endereço em uma linha (para faturas)
+func (a AcademyAddress) String() string {
+	street := strings.TrimSpace(strings.Join(nonEmpty(a.Street, a.Number, a.Complement), ", "))
+	city := a.City
+	if a.State != "" {
+		city = strings.Join(nonEmpty(a.City, a.State), "/")
+	}
+	return strings.Join(nonEmpty(street, a.District, city, a.ZIP), " - ")
+}
+
+// Academy é uma academia cadastrada na plataforma
+// Alinhado com tabela SQL: public.academies
+type Academy struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"` // Nome para URL, único
+	Description string `json:"description,omitempty"`
+	OwnerID     string `json:"owner_id"` // profiles.id do dono
+
+	// Tomador da NFS-e: CNPJ (ou CPF) só com dígitos, opcional
+	Document string `json:"document,omitempty"`
+
+	// Contato
+	Phone   string `json:"phone,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Website string `json:"website,omitempty"`
+
+	Address AcademyAddress `json:"address"`
+
+	// Branding (faturas e app)
+	LogoURL      string `json:"logo_url,omitempty"`
+	CoverURL     string `json:"cover_url,omitempty"`
+	PrimaryColor string `json:"primary_color"` // "#RRGGBB"
+
+	// Código que alunos e professores usam para entrar na academia, único
+	InviteCode string `json:"invite_code"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewAcademy monta uma academia nova do dono: gera o slug a partir do nome
+// (se não informado) e o código de convite, aplica os padrões e valida
+func NewAcademy(academy Academy, ownerID string, now time.Time) (*Academy, error) {
+	a := academy
+	a.ID = ""
+	a.OwnerID = ownerID
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Slug == "" {
+		a.Slug = Slugify(a.Name)
+	}
+	if a.Address.Country == "" {
+		a.Address.Country = DefaultAcademyCountry
+	}
+	if a.PrimaryColor == "" {
+		a.PrimaryColor = DefaultAcademyPrimaryColor
+	}
+	if a.Document != "" {
+		document, err := NormalizeDocument(a.Document)
+		if err != nil {
+			return nil, err
+		}
+		a.Document = document
+	}
+	a.InviteCode = NewInviteCode()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Validate verifica os campos da academia
+func (a *Academy) Validate() error {
+	switch {
+	case a.Name == "":
+		return fmt.Errorf("%w: nome da academia é obrigatório", ErrValidation)
+	case a.OwnerID == "":
+		return fmt.Errorf("%w: academia sem dono", ErrValidation)
+	case !slugPattern.MatchString(a.Slug) || len(a.Slug) > maxSlugLength:
+		return fmt.Errorf("%w: slug inválido: %q (letras minúsculas, números e hífens)", ErrValidation, a.Slug)
+	case !hexColorPattern.MatchString(a.PrimaryColor):
+		return fmt.Errorf("%w: cor primária inválida: %q (use #RRGGBB)", ErrValidation, a.PrimaryColor)
+	case a.Address.State != "" && len(a.Address.State) != 2:
+		return fmt.Errorf("%w: UF inválida: %q", ErrValidation, a.Address.State)
+	case a.Address.CityCode != "" && (len(a.Address.CityCode) != 7 || strings.Trim(a.Address.CityCode, "0123456789") != ""):
+		return fmt.Errorf("%w: código IBGE do município inválido: %q", ErrValidation, a.Address.CityCode)
+	}
+	if a.Email != "" {
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			return fmt.Errorf("%w: e-mail da academia inválido: %q", ErrValidation, a.Email)
+		}
+	}
+	return nil
+}
+
+// RetryIdentifiers troca o código de convite e, a partir da segunda opção de
+// slug (suffix > 1), acrescenta "-N" ao slug base; usado quando o slug ou o
+// código já existem
+func (a *Academy) RetryIdentifiers(baseSlug string, suffix int) {
+	a.InviteCode = NewInviteCode()
+	if suffix > 1 {
+		tail := fmt.Sprintf("-%d", suffix)
+		base := baseSlug
+		if len(base)+len(tail) > maxSlugLength {
+			base = strings.TrimRight(base[:maxSlugLength-len(tail)], "-")
+		}
+		a.Slug = base + tail
+	}
+}
+
+// Branding retorna os dados visuais da academia para faturas
+func (a *Academy) Branding() *Branding {
+	return &Branding{
+		AcademyName:  a.Name,
+		Document:     a.Document,
+		Address:      a.Address.String(),
+		LogoURL:      a.LogoURL,
+		PrimaryColor: a.PrimaryColor,
+	}
+}
+
+// FiscalParty retorna a academia como tomadora da NFS-e. Sem documento não há
+// tomador; o endereço só vai para a nota se estiver completo.
+func (a *Academy) FiscalParty() (*FiscalParty, error) {
+	if a.Document == "" {
+		return nil, fmt.Errorf("academia %s sem CPF/CNPJ cadastrado", a.ID)
+	}
+	party := &FiscalParty{Document: a.Document, Name: a.Name, Email: a.Email}
+	addr := a.Address
+	if addr.Street != "" && addr.Number != "" && addr.District != "" && addr.CityCode != "" && addr.State != "" && addr.ZIP != "" {
+		party.Address = &FiscalAddress{
+			Street:     addr.Street,
+			Number:     addr.Number,
+			Complement: addr.Complement,
+			District:   addr.District,
+			CityCode:   addr.CityCode,
+			State:      addr.State,
+			ZIP:        strings.Map(keepDigits, addr.ZIP),
+		}
+	}
+	return party, party.Validate()
+}
+
+// Slugify gera o slug de um nome: sem acentos, minúsculo, palavras separadas
+// por hífen ("Academia Força & Honra" → "academia-forca-honra")
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue // Acento separado pela decomposição
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			hyphen = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// NewInviteCode gera um código de convite aleatório de InviteCodeLength caracteres
+func NewInviteCode() string {
+	b := make([]byte, InviteCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand indisponível: %v", err))
+	}
+	for i := range b {
+		b[i] = inviteCodeAlphabet[int(b[i])%len(inviteCodeAlphabet)]
+	}
+	return string(b)
+}
+
+// keepDigits é o filtro de strings.Map que mantém só dígitos
+func keepDigits(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+	return -1
+}
+
+// nonEmpty filtra os valores vazios
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"acentos e símbolos", "Academia Força & Honra", "academia-forca-honra"},
+		{"espaços nas pontas", "  Gracie Barra  ", "gracie-barra"},
+		{"números", "CT 10 Jiu-Jitsu", "ct-10-jiu-jitsu"},
+		{"cedilha e til", "Ação São João", "acao-sao-joao"},
+		{"sem letras", "!!! ---", ""},
+		{"limite de tamanho", strings.Repeat("abc ", 30), strings.TrimRight(strings.Repeat("abc-", 15), "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInviteCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code := NewInviteCode()
+		if len(code) != InviteCodeLength || strings.Trim(code, inviteCodeAlphabet) != "" {
+			t.Fatalf("código %q fora do alfabeto ou do tamanho", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 99 {
+		t.Errorf("%d códigos distintos em 100", len(seen))
+	}
+}
+
+func TestNewAcademy(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, BillingLocation)
+
+	tests := []struct {
+		name    string
+		academy Academy
+		owner   string
+		wantErr bool
+	}{
+		{"mínimo", Academy{Name: "Academia Gracie"}, "owner-1", false},
+		{"completo", Academy{
+			Name: "Gracie", Slug: "gracie-sp", Document: "11.222.333/0001-81", Email: "contato@gracie.com",
+			PrimaryColor: "#1A2b3C", Address: AcademyAddress{State: "SP", CityCode: "3550308"},
+		}, "owner-1", false},
+		{"sem nome", Academy{Name: "  "}, "owner-1", true},
+		{"sem dono", Academy{Name: "Gracie"}, "", true},
+		{"slug inválido", Academy{Name: "Gracie", Slug: "Gracie SP"}, "owner-1", true},
+		{"cor inválida", Academy{Name: "Gracie", PrimaryColor: "azul"}, "owner-1", true},
+		{"documento inválido", Academy{Name: "Gracie", Document: "111.111.111-11"}, "owner-1", true},
+		{"UF inválida", Academy{Name: "Gracie", Address: AcademyAddress{State: "SPX"}}, "owner-1", true},
+		{"código IBGE inválido", Academy{Name: "Gracie", Address: AcademyAddress{CityCode: "35503"}}, "owner-1", true},
+		{"e-mail inválido", Academy{Name: "Gracie", Email: "contato"}, "owner-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAcademy(tt.academy, tt.owner, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAcademy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if a.OwnerID != tt.owner || a.InviteCode == "" || !a.CreatedAt.Equal(now) {
+				t.Errorf("academia = %+v", a)
+			}
+			if a.Address.Country != DefaultAcademyCountry || a.PrimaryColor == "" {
+				t.Errorf("padrões não aplicados: país = %q, cor = %q", a.Address.Country, a.PrimaryColor)
+			}
+		})
+	}
+
+	a, _ := NewAcademy(Academy{Name: "Academia Gracie", Document: "11.222.333/0001-81"}, "owner-1", now)
+	if a.Slug != "academia-gracie" || a.Document != "11222333000181" || a.PrimaryColor != DefaultAcademyPrimaryColor {
+		t.Errorf("slug = %q, documento = %q, cor = %q", a.Slug, a.Document, a.PrimaryColor)
+	}
+}
+
+func TestAcademy_RetryIdentifiers(t *testing.T) {
+	a := &Academy{Slug: "academia-gracie", InviteCode: "AAAAAAAA"}
+
+	a.RetryIdentifiers("academia-gracie", 1)
+	if a.Slug != "academia-gracie" || a.InviteCode == "AAAAAAAA" {
+		t.Errorf("suffix 1: slug = %q, código = %q; want só o código trocado", a.Slug, a.InviteCode)
+	}
+	a.RetryIdentifiers("academia-gracie", 3)
+	if a.Slug != "academia-gracie-3" {
+		t.Errorf("slug = %q, want academia-gracie-3", a.Slug)
+	}
+
+	long := strings.Repeat("a", maxSlugLength)
+	a.RetryIdentifiers(long, 12)
+	if len(a.Slug) > maxSlugLength || !strings.HasSuffix(a.Slug, "-12") {
+		t.Errorf("slug longo = %q (%d)", a.Slug, len(a.Slug))
+	}
+}
+
+func TestAcademy_BrandingAndFiscalParty(t *testing.T) {
+	a := &Academy{
+		ID: "academy-1", Name: "Gracie", Email: "contato@gracie.com", LogoURL: "https://cdn/logo.png",
+		PrimaryColor: "#112233",
+		Address:      AcademyAddress{Street: "Rua A", Number: "10", City: "São Paulo", State: "SP", ZIP: "01000-000"},
+	}
+
+	b := a.Branding()
+	if b.AcademyName != "Gracie" || b.LogoURL != a.LogoURL || b.PrimaryColor != "#112233" {
+		t.Errorf("Branding() = %+v", b)
+	}
+	if b.Address != "Rua A, 10 - São Paulo/SP - 01000-000" {
+		t.Errorf("Branding().Address = %q", b.Address)
+	}
+
+	if _, err := a.FiscalParty(); err == nil {
+		t.Error("FiscalParty() sem documento deveria falhar")
+	}
+
+	a.Document = "11222333000181"
+	party, err := a.FiscalParty()
+	if err != nil {
+		t.Fatalf("FiscalParty() error = %v", err)
+	}
+	if party.Address != nil {
+		t.Errorf("endereço incompleto (sem bairro e IBGE) foi para a nota: %+v", party.Address)
+	}
+
+	a.Address.District, a.Address.CityCode = "Centro", "3550308"
+	party, err = a.FiscalParty()
+	if err != nil {
+		t.Fatalf("FiscalParty() error = %v", err)
+	}
+	if party.Address == nil || party.Address.ZIP != "01000000" || party.Address.CityCode != "3550308" {
+		t.Errorf("FiscalParty().Address = %+v", party.Address)
+	}
+}
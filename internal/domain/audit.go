@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AuditAction identifica uma ação sensível de cobrança registrada no audit log
+type AuditAction string
+
+const (
+	AuditSubscriptionCheckout     AuditAction = "subscription.checkout"
+	AuditSubscriptionCanceled     AuditAction = "subscription.cancel"
+	AuditSubscriptionCancelUndone AuditAction = "subscription.cancel_undo"
+	AuditSubscriptionPlanChanged  AuditAction = "subscription.plan_change"
+	AuditSubscriptionPaused       AuditAction = "subscription.pause"
+	AuditSubscriptionResumed      AuditAction = "subscription.resume"
+	AuditTrialExtended            AuditAction = "subscription.trial_extend"
+	AuditTrialConverted           AuditAction = "subscription.trial_convert"
+	AuditRefundRequested          AuditAction = "payment.refund_request"
+	AuditRefundSettled            AuditAction = "payment.refund_settle"
+)
+
+// Tipos de ator do audit log
+const (
+	AuditActorUser    = "user"    // Usuário autenticado (ID do JWT)
+	AuditActorSystem  = "system"  // Jobs agendados e regras automáticas
+	AuditActorWebhook = "webhook" // Evento recebido de um gateway
+	AuditActorAdmin   = "admin"   // Equipe com o token administrativo (ID informado na requisição)
+)
+
+// AuditActor identifica quem executou a ação
+type AuditActor struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"` // ID do usuário ou gateway/event_id do webhook
+}
+
+// AuditChange é o valor de um campo antes e depois da ação (JSON do campo; nulo se ausente)
+type AuditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditEntry é um registro imutável do audit log. Os registros de cada
+// academia formam uma cadeia: Hash cobre o conteúdo e o Hash anterior, então
+// alterar ou remover um registro quebra a verificação de todos os seguintes.
+type AuditEntry struct {
+	ID        string `json:"id"`
+	AcademyID string `json:"academy_id"`
+	Seq       int64  `json:"seq"` // Posição na cadeia da academia, a partir de 1
+
+	Actor         AuditActor  `json:"actor"`
+	Action        AuditAction `json:"action"`
+	AggregateType string      `json:"aggregate_type"` // AggregateSubscription | AggregatePayment
+	AggregateID   string      `json:"aggregate_id"`
+
+	// Diff campo a campo do agregado (JSON canônico, coberto pelo hash)
+	Diff json.RawMessage `json:"diff,omitempty"`
+
+	RequestID  string    `json:"request_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// NewAuditEntry monta um registro com o diff entre before e after (snapshots
+// JSON do agregado; before nil = criação). Ator e request ID vêm do contexto.
+func NewAuditEntry(ctx context.Context, action AuditAction, aggregateType, aggregateID, academyID string, before, after json.RawMessage, now time.Time) (*AuditEntry, error) {
+	diff, err := AuditDiff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditEntry{
+		AcademyID:     academyID,
+		Actor:         AuditActorFromContext(ctx),
+		Action:        action,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Diff:          diff,
+		RequestID:     RequestIDFromContext(ctx),
+		// Precisão do TIMESTAMPTZ: o hash precisa sobreviver à ida e volta do banco
+		OccurredAt: now.UTC().Truncate(time.Microsecond),
+	}, nil
+}
+
+// AuditDiff compara dois snapshots JSON campo a campo e devolve só os campos
+// alterados, em JSON com as chaves ordenadas (nil se nada mudou)
+func AuditDiff(before, after json.RawMessage) (json.RawMessage, error) {
+	var b, a map[string]json.RawMessage
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, fmt.Errorf("snapshot anterior inválido: %w", err)
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, fmt.Errorf("snapshot posterior inválido: %w", err)
+		}
+	}
+
+	changes := make(map[string]AuditChange)
+	for field, value := range a {
+		if old, ok := b[field]; !ok || !jsonEqual(old, value) {
+			changes[field] = AuditChange{Before: b[field], After: value}
+		}
+	}
+	for field, old := range b {
+		if _, ok := a[field]; !ok {
+			changes[field] = AuditChange{Before: old}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	// json.Marshal ordena as chaves do map: o diff é canônico
+	return json.Marshal(changes)
+}
+
+// Chain encadeia o registro após prev (nil = primeiro da academia),
+// preenchendo Seq, PrevHash e Hash
+func (e *AuditEntry) Chain(prev *AuditEntry) {
+	e.Seq = 1
+	e.PrevHash = ""
+	if prev != nil {
+		e.Seq = prev.Seq + 1
+		e.PrevHash = prev.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash calcula o SHA-256 do conteúdo do registro e do hash anterior.
+// O ID não entra: é atribuído pelo armazenamento.
+func (e *AuditEntry) ComputeHash() string {
+	content, _ := json.Marshal(struct {
+		AcademyID     string          `json:"academy_id"`
+		Seq           int64           `json:"seq"`
+		Actor         AuditActor      `json:"actor"`
+		Action        AuditAction     `json:"action"`
+		AggregateType string          `json:"aggregate_type"`
+		AggregateID   string          `json:"aggregate_id"`
+		Diff          json.RawMessage `json:"diff,omitempty"`
+		RequestID     string          `json:"request_id,omitempty"`
+		OccurredAt    string          `json:"occurred_at"`
+		PrevHash      string          `json:"prev_hash"`
+	}{
+		e.AcademyID, e.Seq, e.Actor, e.Action, e.AggregateType, e.AggregateID,
+		e.Diff, e.RequestID, e.OccurredAt.UTC().Format(time.RFC3339Nano), e.PrevHash,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain confere uma cadeia completa (Seq crescente a partir de 1)
+// e devolve o primeiro registro adulterado ou fora de sequência
+func VerifyAuditChain(entries []*AuditEntry) error {
+	sorted := append([]*AuditEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Seq < sorted[j].Seq })
+
+	var prev *AuditEntry
+	for _, e := range sorted {
+		switch {
+		case prev == nil && e.Seq != 1, prev != nil && e.Seq != prev.Seq+1:
+			return fmt.Errorf("%w: registro %d fora de sequência", ErrAuditChainBroken, e.Seq)
+		case prev == nil && e.PrevHash != "", prev != nil && e.PrevHash != prev.Hash:
+			return fmt.Errorf("%w: registro %d não aponta para o anterior", ErrAuditChainBroken, e.Seq)
+		case e.Hash != e.ComputeHash():
+			return fmt.Errorf("%w: conteúdo do registro %d alterado", ErrAuditChainBroken, e.Seq)
+		}
+		prev = e
+	}
+	return nil
+}
+
+// jsonEqual compara dois valores JSON ignorando formatação
+func jsonEqual(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+// Contexto da auditoria: quem age e em qual requisição. Handlers preenchem o
+// usuário e o request ID, o inbox de webhooks o gateway; sem nada, é o sistema.
+type (
+	auditActorKey struct{}
+	requestIDKey  struct{}
+)
+
+// WithAuditActor retorna um contexto com o ator das ações seguintes
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext retorna o ator do contexto (AuditActorSystem se não houver)
+func AuditActorFromContext(ctx context.Context) AuditActor {
+	if actor, ok := ctx.Value(auditActorKey{}).(AuditActor); ok {
+		return actor
+	}
+	return AuditActor{Type: AuditActorSystem}
+}
+
+// WithRequestID retorna um contexto com o ID da requisição (ou do evento) em curso
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext retorna o ID da requisição, ou vazio
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"sem mudança", `{"status":"active","plan_id":"p1"}`, `{"plan_id":"p1","status":"active"}`, ``},
+		{"campo alterado", `{"status":"active","plan_id":"p1"}`, `{"status":"canceled","plan_id":"p1"}`,
+			`{"status":{"before":"active","after":"canceled"}}`},
+		{"campo novo e removido", `{"a":1}`, `{"b":2}`,
+			`{"a":{"before":1,"after":null},"b":{"before":null,"after":2}}`},
+		{"criação", ``, `{"status":"trial"}`, `{"status":{"before":null,"after":"trial"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AuditDiff(json.RawMessage(tt.before), json.RawMessage(tt.after))
+			if err != nil {
+				t.Fatalf("AuditDiff() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("AuditDiff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// newAuditChain monta n registros encadeados da academia-1
+func newAuditChain(t *testing.T, n int) []*AuditEntry {
+	t.Helper()
+
+	ctx := WithRequestID(WithAuditActor(context.Background(), AuditActor{Type: AuditActorUser, ID: "user-1"}), "req-1")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	var entries []*AuditEntry
+	var prev *AuditEntry
+	for i := 0; i < n; i++ {
+		entry, err := NewAuditEntry(ctx, AuditSubscriptionCanceled, AggregateSubscription, "sub-1", "academy-1",
+			json.RawMessage(`{"status":"active"}`), json.RawMessage(`{"status":"canceled"}`), now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Chain(prev)
+		entries = append(entries, entry)
+		prev = entry
+	}
+	return entries
+}
+
+func TestAuditEntry_Chain(t *testing.T) {
+	entries := newAuditChain(t, 3)
+
+	first := entries[0]
+	if first.Seq != 1 || first.PrevHash != "" || first.Hash == "" {
+		t.Fatalf("primeiro registro: seq = %d, prev = %q, hash = %q", first.Seq, first.PrevHash, first.Hash)
+	}
+	if first.Actor.Type != AuditActorUser || first.Actor.ID != "user-1" || first.RequestID != "req-1" {
+		t.Errorf("ator/request do contexto não registrados: %+v, %q", first.Actor, first.RequestID)
+	}
+	if entries[2].Seq != 3 || entries[2].PrevHash != entries[1].Hash {
+		t.Errorf("terceiro registro não encadeado: seq = %d", entries[2].Seq)
+	}
+	if err := VerifyAuditChain(entries); err != nil {
+		t.Errorf("VerifyAuditChain() error = %v", err)
+	}
+	if err := VerifyAuditChain(nil); err != nil {
+		t.Errorf("cadeia vazia: error = %v", err)
+	}
+}
+
+func TestVerifyAuditChain_Tampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]*AuditEntry) []*AuditEntry
+	}{
+		{"diff alterado", func(e []*AuditEntry) []*AuditEntry {
+			e[1].Diff = json.RawMessage(`{"status":{"before":"active","after":"trial"}}`)
+			return e
+		}},
+		{"ator alterado", func(e []*AuditEntry) []*AuditEntry {
+			e[0].Actor.ID = "user-2"
+			return e
+		}},
+		{"registro removido", func(e []*AuditEntry) []*AuditEntry {
+			return append(e[:1], e[2:]...)
+		}},
+		{"hash recalculado", func(e []*AuditEntry) []*AuditEntry {
+			e[1].Action = AuditSubscriptionResumed
+			e[1].Hash = e[1].ComputeHash()
+			return e
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.tamper(newAuditChain(t, 3))
+			if err := VerifyAuditChain(entries); !errors.Is(err, ErrAuditChainBroken) {
+				t.Errorf("VerifyAuditChain() error = %v, want ErrAuditChainBroken", err)
+			}
+		})
+	}
+}
+
+func TestAuditActorFromContext_DefaultSystem(t *testing.T) {
+	if actor := AuditActorFromContext(context.Background()); actor.Type != AuditActorSystem {
+		t.Errorf("ator padrão = %+v, want system", actor)
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+// BillingInterval representa a periodicidade de cobrança de uma assinatura
+type BillingInterval string
+
+const (
+	BillingIntervalMonthly BillingInterval = "monthly"
+	BillingIntervalYearly  BillingInterval = "yearly"
+)
+
+// IsValid verifica se o intervalo é válido
+func (i BillingInterval) IsValid() bool {
+	return i == BillingIntervalMonthly || i == BillingIntervalYearly
+}
+
+// months retorna quantos meses o intervalo cobre
+func (i BillingInterval) months() int {
+	if i == BillingIntervalYearly {
+		return 12
+	}
+	return 1
+}
+
+// BillingLocation é o fuso usado em todos os cálculos de período.
+// As datas de cobrança seguem o calendário das academias, não o fuso do servidor.
+var BillingLocation = loadBillingLocation()
+
+// loadBillingLocation carrega America/Sao_Paulo, caindo para UTC-3 fixo se o
+// sistema não tiver a base tzdata (o Brasil não tem horário de verão desde 2019)
+func loadBillingLocation() *time.Location {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+	return loc
+}
+
+// NextBillingDate calcula o fim do período que começa em from.
+// O dia de cobrança é ancorado em anchorDay e limitado ao último dia do mês:
+// com âncora 31, 31/01 → 28/02 (ou 29/02) → 31/03, sem derivar para março.
+func NextBillingDate(from time.Time, interval BillingInterval, anchorDay int) time.Time {
+	local := from.In(BillingLocation)
+	if anchorDay < 1 || anchorDay > 31 {
+		anchorDay = local.Day()
+	}
+
+	// Primeiro dia do mês de destino (day=1 evita a normalização de AddDate)
+	target := time.Date(local.Year(), local.Month()+time.Month(interval.months()), 1,
+		local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), BillingLocation)
+
+	day := anchorDay
+	if last := daysInMonth(target.Year(), target.Month()); day > last {
+		day = last
+	}
+	return target.AddDate(0, 0, day-1)
+}
+
+// daysInMonth retorna o número de dias do mês
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
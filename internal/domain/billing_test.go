@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func spDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 10, 0, 0, 0, BillingLocation)
+}
+
+func TestNextBillingDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		interval BillingInterval
+		anchor   int
+		want     time.Time
+	}{
+		{"jan 31 clamps to feb 28", spDate(2026, 1, 31), BillingIntervalMonthly, 31, spDate(2026, 2, 28)},
+		{"feb 28 returns to anchor 31", spDate(2026, 2, 28), BillingIntervalMonthly, 31, spDate(2026, 3, 31)},
+		{"leap year feb 29", spDate(2028, 1, 31), BillingIntervalMonthly, 31, spDate(2028, 2, 29)},
+		{"anchor 30 in april", spDate(2026, 3, 30), BillingIntervalMonthly, 30, spDate(2026, 4, 30)},
+		{"december rolls year", spDate(2026, 12, 15), BillingIntervalMonthly, 15, spDate(2027, 1, 15)},
+		{"yearly from leap day", spDate(2028, 2, 29), BillingIntervalYearly, 29, spDate(2029, 2, 28)},
+		{"yearly back to leap day", spDate(2031, 2, 28), BillingIntervalYearly, 29, spDate(2032, 2, 29)},
+		{"invalid anchor uses from day", spDate(2026, 5, 10), BillingIntervalMonthly, 0, spDate(2026, 6, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextBillingDate(tt.from, tt.interval, tt.anchor)
+			if !got.Equal(tt.want) {
+				t.Errorf("NextBillingDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextBillingDate_UsesSaoPauloCalendar(t *testing.T) {
+	// 31/01 23:30 em São Paulo já é 01/02 em UTC
+	from := time.Date(2026, 2, 1, 2, 30, 0, 0, time.UTC)
+
+	got := NextBillingDate(from, BillingIntervalMonthly, 31).In(BillingLocation)
+	if got.Month() != time.February || got.Day() != 28 {
+		t.Errorf("NextBillingDate() = %v, want 28/02 (calendário de São Paulo)", got)
+	}
+}
+
+func TestSubscription_Renew(t *testing.T) {
+	start := spDate(2026, 1, 31)
+	sub := &Subscription{Status: SubscriptionStatusTrialing}
+	if err := sub.Activate(PaymentGatewayPixAuto, start, NextBillingDate(start, BillingIntervalMonthly, 31), ActorWebhook, start); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+
+	wantEnds := []time.Time{spDate(2026, 3, 31), spDate(2026, 4, 30), spDate(2026, 5, 31)}
+	for _, want := range wantEnds {
+		if err := sub.Renew(BillingIntervalMonthly, *sub.CurrentPeriodEnd); err != nil {
+			t.Fatalf("Renew() error = %v", err)
+		}
+		if !sub.CurrentPeriodEnd.Equal(want) {
+			t.Errorf("CurrentPeriodEnd = %v, want %v", sub.CurrentPeriodEnd, want)
+		}
+	}
+
+	// Mudança para anual mantém a âncora
+	if err := sub.Renew(BillingIntervalYearly, *sub.CurrentPeriodEnd); err != nil {
+		t.Fatalf("Renew(yearly) error = %v", err)
+	}
+	if want := spDate(2027, 5, 31); !sub.CurrentPeriodEnd.Equal(want) {
+		t.Errorf("CurrentPeriodEnd = %v, want %v", sub.CurrentPeriodEnd, want)
+	}
+	if sub.BillingInterval != BillingIntervalYearly {
+		t.Errorf("BillingInterval = %v, want yearly", sub.BillingInterval)
+	}
+}
+
+func TestSubscription_RenewStatuses(t *testing.T) {
+	end := spDate(2026, 3, 10)
+
+	pastDue := &Subscription{Status: SubscriptionStatusPastDue, BillingAnchorDay: 10, CurrentPeriodEnd: &end}
+	if err := pastDue.Renew(BillingIntervalMonthly, end); err != nil {
+		t.Fatalf("Renew() past_due error = %v", err)
+	}
+	if pastDue.Status != SubscriptionStatusActive {
+		t.Errorf("Status = %v, want active", pastDue.Status)
+	}
+
+	for _, status := range []SubscriptionStatus{SubscriptionStatusTrialing, SubscriptionStatusCanceled, SubscriptionStatusExpired} {
+		sub := &Subscription{Status: status, CurrentPeriodEnd: &end}
+		if err := sub.Renew(BillingIntervalMonthly, end); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Renew() de %s error = %v, want ErrInvalidTransition", status, err)
+		}
+	}
+
+	scheduled := &Subscription{Status: SubscriptionStatusActive, BillingAnchorDay: 10, CurrentPeriodEnd: &end, CancelAtPeriodEnd: true}
+	if err := scheduled.Renew(BillingIntervalMonthly, end); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Renew() com cancelamento agendado error = %v, want ErrInvalidTransition", err)
+	}
+	if !scheduled.CurrentPeriodEnd.Equal(end) {
+		t.Errorf("CurrentPeriodEnd = %v, want inalterado", scheduled.CurrentPeriodEnd)
+	}
+}
+
+func TestSubscriptionPlan_PriceFor(t *testing.T) {
+	plan := NewSubscriptionPlan("Pro", "pro", 19900, time.Date(2026, 1, 1, 0, 0, 0, 0, BillingLocation))
+
+	if price, err := plan.PriceFor(BillingIntervalMonthly); err != nil || price != 19900 {
+		t.Errorf("PriceFor(monthly) = %d, %v, want 19900", price, err)
+	}
+	if _, err := plan.PriceFor(BillingIntervalYearly); !errors.Is(err, ErrIntervalUnavailable) {
+		t.Errorf("PriceFor(yearly) error = %v, want ErrIntervalUnavailable", err)
+	}
+
+	yearly := 199000
+	plan.PriceYearly = &yearly
+	if price, err := plan.PriceFor(BillingIntervalYearly); err != nil || price != yearly {
+		t.Errorf("PriceFor(yearly) = %d, %v, want %d", price, err, yearly)
+	}
+}
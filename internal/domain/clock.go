@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock é a fonte de tempo do sistema. Os serviços leem a hora do Clock e a
+// passam para o domínio (os métodos das entidades recebem now), o que permite
+// testes determinísticos e o test clock do sandbox.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock é o relógio real
+type SystemClock struct{}
+
+// Now retorna a hora atual
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock é um relógio controlado manualmente (thread-safe)
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock cria um relógio parado em now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now retorna a hora atual do relógio
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set move o relógio para t
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance avança o relógio em d e retorna a nova hora
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CouponType define como o desconto é calculado
+type CouponType string
+
+const (
+	CouponTypePercent CouponType = "percent" // Percentual sobre o preço do plano
+	CouponTypeFixed   CouponType = "fixed"   // Valor fixo em centavos
+)
+
+// CouponDuration define por quantos ciclos o desconto vale
+type CouponDuration string
+
+const (
+	CouponDurationOnce      CouponDuration = "once"      // Apenas a próxima cobrança
+	CouponDurationRepeating CouponDuration = "repeating" // DurationCycles cobranças
+	CouponDurationForever   CouponDuration = "forever"   // Enquanto a assinatura existir
+)
+
+// Coupon representa um cupom promocional
+// Alinhado com tabela SQL: public.coupons
+type Coupon struct {
+	ID   string     `json:"id"`
+	Code string     `json:"code"` // Sempre em maiúsculas
+	Type CouponType `json:"type"`
+
+	PercentOff int `json:"percent_off,omitempty"` // 1-100 (CouponTypePercent)
+	AmountOff  int `json:"amount_off,omitempty"`  // Centavos (CouponTypeFixed)
+
+	Duration       CouponDuration `json:"duration"`
+	DurationCycles int            `json:"duration_cycles,omitempty"` // Apenas repeating
+
+	// Restrições
+	MaxRedemptions *int       `json:"max_redemptions,omitempty"` // NULL = ilimitado
+	TimesRedeemed  int        `json:"times_redeemed"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"` // Prazo para resgatar
+	PlanIDs        []string   `json:"plan_ids,omitempty"`   // Vazio = todos os planos
+	IsActive       bool       `json:"is_active"`
+
+	// Stripe integration
+	StripeCouponID *string `json:"stripe_coupon_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeCouponCode padroniza o código digitado pelo usuário
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate verifica se o cupom está bem configurado
+func (c *Coupon) Validate() error {
+	if c.Code == "" {
+		return fmt.Errorf("%w: código do cupom é obrigatório", ErrValidation)
+	}
+	switch c.Type {
+	case CouponTypePercent:
+		if c.PercentOff < 1 || c.PercentOff > 100 {
+			return fmt.Errorf("%w: percent_off deve estar entre 1 e 100", ErrValidation)
+		}
+	case CouponTypeFixed:
+		if c.AmountOff <= 0 {
+			return fmt.Errorf("%w: amount_off deve ser positivo", ErrValidation)
+		}
+	default:
+		return fmt.Errorf("%w: tipo de cupom inválido: %q", ErrValidation, c.Type)
+	}
+	switch c.Duration {
+	case CouponDurationOnce, CouponDurationForever:
+	case CouponDurationRepeating:
+		if c.DurationCycles < 1 {
+			return fmt.Errorf("%w: duration_cycles deve ser positivo para cupons repeating", ErrValidation)
+		}
+	default:
+		return fmt.Errorf("%w: duração de cupom inválida: %q", ErrValidation, c.Duration)
+	}
+	return nil
+}
+
+// CanRedeem verifica se o cupom pode ser aplicado a uma assinatura do plano
+func (c *Coupon) CanRedeem(planID string, now time.Time) error {
+	if !c.IsActive {
+		return fmt.Errorf("%w: cupom %s inativo", ErrCouponInvalid, c.Code)
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return fmt.Errorf("%w: cupom %s expirado", ErrCouponInvalid, c.Code)
+	}
+	if c.MaxRedemptions != nil && c.TimesRedeemed >= *c.MaxRedemptions {
+		return fmt.Errorf("%w: cupom %s esgotado", ErrCouponInvalid, c.Code)
+	}
+	if len(c.PlanIDs) > 0 {
+		for _, id := range c.PlanIDs {
+			if id == planID {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: cupom %s não vale para este plano", ErrCouponInvalid, c.Code)
+	}
+	return nil
+}
+
+// SubscriptionDiscount é o desconto de um cupom aplicado à assinatura.
+// Guarda uma cópia das regras para que mudanças no cupom não afetem quem já resgatou.
+type SubscriptionDiscount struct {
+	CouponID        string     `json:"coupon_id"`
+	Code            string     `json:"code"`
+	Type            CouponType `json:"type"`
+	PercentOff      int        `json:"percent_off,omitempty"`
+	AmountOff       int        `json:"amount_off,omitempty"`
+	CyclesRemaining *int       `json:"cycles_remaining,omitempty"` // nil = forever
+	AppliedAt       time.Time  `json:"applied_at"`
+}
+
+// NewSubscriptionDiscount cria o desconto de um cupom resgatado
+func NewSubscriptionDiscount(c *Coupon, now time.Time) *SubscriptionDiscount {
+	d := &SubscriptionDiscount{
+		CouponID:   c.ID,
+		Code:       c.Code,
+		Type:       c.Type,
+		PercentOff: c.PercentOff,
+		AmountOff:  c.AmountOff,
+		AppliedAt:  now,
+	}
+	switch c.Duration {
+	case CouponDurationOnce:
+		cycles := 1
+		d.CyclesRemaining = &cycles
+	case CouponDurationRepeating:
+		cycles := c.DurationCycles
+		d.CyclesRemaining = &cycles
+	}
+	return d
+}
+
+// Amount retorna o desconto (centavos) sobre price, nunca maior que o próprio price
+func (d *SubscriptionDiscount) Amount(price int) int {
+	if d == nil || price <= 0 {
+		return 0
+	}
+	discount := d.AmountOff
+	if d.Type == CouponTypePercent {
+		discount = price * d.PercentOff / 100
+	}
+	if discount > price {
+		discount = price
+	}
+	return discount
+}
+
+// ApplyCoupon aplica o desconto de um cupom (substitui um desconto anterior)
+func (s *Subscription) ApplyCoupon(c *Coupon, now time.Time) error {
+	switch s.Status {
+	case SubscriptionStatusTrialing, SubscriptionStatusActive:
+	default:
+		return fmt.Errorf("%w: assinatura em %s", ErrCouponInvalid, s.Status)
+	}
+	if err := c.CanRedeem(s.PlanID, now); err != nil {
+		return err
+	}
+	s.Discount = NewSubscriptionDiscount(c, now)
+	s.UpdatedAt = now
+	return nil
+}
+
+// NextChargeAmount retorna o valor da próxima cobrança recorrente: preço do
+// plano no intervalo atual, menos o desconto, mais a diferença pró-rata pendente
+func (s *Subscription) NextChargeAmount(plan *SubscriptionPlan) (gross, discount int, err error) {
+	interval := s.BillingInterval
+	if interval == "" {
+		interval = BillingIntervalMonthly
+	}
+	price, err := plan.PriceFor(interval)
+	if err != nil {
+		return 0, 0, err
+	}
+	return price + s.PendingProrationAmount, s.Discount.Amount(price), nil
+}
+
+// CompleteBillingCycle registra que uma cobrança recorrente foi paga: consome
+// um ciclo do desconto e zera a diferença pró-rata. Retorna true se o valor
+// das próximas cobranças mudou.
+func (s *Subscription) CompleteBillingCycle() bool {
+	changed := s.PendingProrationAmount != 0
+	s.PendingProrationAmount = 0
+
+	if s.Discount != nil && s.Discount.CyclesRemaining != nil {
+		remaining := *s.Discount.CyclesRemaining - 1
+		if remaining <= 0 {
+			s.Discount = nil
+			changed = true
+		} else {
+			s.Discount.CyclesRemaining = &remaining
+		}
+	}
+	return changed
+}
+
+// ApplyDiscount registra o desconto de um pagamento: Amount passa a ser o valor
+// líquido cobrado e GrossAmount o preço cheio
+func (p *PaymentHistory) ApplyDiscount(discount int, couponID string) {
+	if discount <= 0 {
+		return
+	}
+	if discount > p.GrossAmount {
+		discount = p.GrossAmount
+	}
+	p.DiscountAmount = discount
+	p.Amount = p.GrossAmount - discount
+	p.CouponID = &couponID
+}
+
+// RevenueSummary resume a receita confirmada de um conjunto de pagamentos (centavos)
+type RevenueSummary struct {
+	Gross     int `json:"gross"`
+	Discounts int `json:"discounts"`
+	Net       int `json:"net"`
+	Payments  int `json:"payments"`
+}
+
+// SummarizeRevenue soma bruto, descontos e líquido dos pagamentos confirmados
+func SummarizeRevenue(payments []*PaymentHistory) RevenueSummary {
+	var summary RevenueSummary
+	for _, p := range payments {
+		if !p.IsPaid() {
+			continue
+		}
+		summary.Gross += p.GrossAmount
+		summary.Discounts += p.DiscountAmount
+		summary.Net += p.Amount
+		summary.Payments++
+	}
+	return summary
+}
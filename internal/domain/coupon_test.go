@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoupon_CanRedeem(t *testing.T) {
+	now := spDate(2026, 5, 10)
+	expired := now.Add(-time.Hour)
+	one := 1
+
+	tests := []struct {
+		name    string
+		coupon  Coupon
+		planID  string
+		wantErr bool
+	}{
+		{"valid", Coupon{Code: "BEMVINDO", IsActive: true}, "pro", false},
+		{"inactive", Coupon{Code: "OFF"}, "pro", true},
+		{"expired", Coupon{Code: "NATAL", IsActive: true, ExpiresAt: &expired}, "pro", true},
+		{"exhausted", Coupon{Code: "UNICO", IsActive: true, MaxRedemptions: &one, TimesRedeemed: 1}, "pro", true},
+		{"other plan", Coupon{Code: "BIZ", IsActive: true, PlanIDs: []string{"business"}}, "pro", true},
+		{"allowed plan", Coupon{Code: "BIZ", IsActive: true, PlanIDs: []string{"business", "pro"}}, "pro", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.coupon.CanRedeem(tt.planID, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanRedeem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrCouponInvalid) {
+				t.Errorf("CanRedeem() error = %v, want ErrCouponInvalid", err)
+			}
+		})
+	}
+}
+
+func TestSubscriptionDiscount_Cycles(t *testing.T) {
+	plan := &SubscriptionPlan{ID: "pro", PriceMonthly: 19900}
+	now := spDate(2026, 5, 10)
+
+	tests := []struct {
+		name         string
+		coupon       Coupon
+		wantDiscount int
+		wantCycles   int // cobranças com desconto (-1 = forever)
+	}{
+		{"percent once", Coupon{Type: CouponTypePercent, PercentOff: 50, Duration: CouponDurationOnce}, 9950, 1},
+		{"fixed repeating", Coupon{Type: CouponTypeFixed, AmountOff: 5000, Duration: CouponDurationRepeating, DurationCycles: 3}, 5000, 3},
+		{"fixed above price", Coupon{Type: CouponTypeFixed, AmountOff: 50000, Duration: CouponDurationOnce}, 19900, 1},
+		{"percent forever", Coupon{Type: CouponTypePercent, PercentOff: 10, Duration: CouponDurationForever}, 1990, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.coupon.IsActive = true
+			sub := &Subscription{PlanID: plan.ID, Status: SubscriptionStatusActive, BillingInterval: BillingIntervalMonthly}
+			if err := sub.ApplyCoupon(&tt.coupon, now); err != nil {
+				t.Fatalf("ApplyCoupon() error = %v", err)
+			}
+
+			cycles := 0
+			for i := 0; i < 5; i++ {
+				gross, discount, err := sub.NextChargeAmount(plan)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gross != 19900 {
+					t.Fatalf("gross = %d, want 19900", gross)
+				}
+				if discount == 0 {
+					break
+				}
+				if discount != tt.wantDiscount {
+					t.Fatalf("discount = %d, want %d", discount, tt.wantDiscount)
+				}
+				cycles++
+				sub.CompleteBillingCycle()
+			}
+
+			want := tt.wantCycles
+			if want < 0 {
+				want = 5
+			}
+			if cycles != want {
+				t.Errorf("cobranças com desconto = %d, want %d", cycles, want)
+			}
+		})
+	}
+}
+
+func TestSummarizeRevenue(t *testing.T) {
+	now := spDate(2026, 5, 10)
+	paid := NewPaymentHistory("sub-1", "academy-1", 19900, PaymentGatewayPixAuto, now)
+	paid.ApplyDiscount(4975, "coupon-1")
+	paid.Succeed(now)
+
+	full := NewPaymentHistory("sub-2", "academy-2", 9900, PaymentGatewayStripe, now)
+	full.Succeed(now)
+
+	failed := NewPaymentHistory("sub-3", "academy-3", 9900, PaymentGatewayPixAuto, now)
+	failed.Fail("saldo insuficiente", "AM04", now)
+
+	got := SummarizeRevenue([]*PaymentHistory{paid, full, failed})
+	want := RevenueSummary{Gross: 29800, Discounts: 4975, Net: 24825, Payments: 2}
+	if got != want {
+		t.Errorf("SummarizeRevenue() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeDocument remove a pontuação de um CPF ou CNPJ e confere os
+// dígitos verificadores. Retorna só os dígitos (11 ou 14).
+func NormalizeDocument(document string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == '.' || r == '-' || r == '/' || r == ' ':
+			return -1
+		}
+		return 'x'
+	}, document)
+
+	switch {
+	case strings.ContainsRune(digits, 'x'):
+		return "", fmt.Errorf("%w: documento com caracteres inválidos: %q", ErrValidation, document)
+	case len(digits) == 11 && validCheckDigits(digits, 9, 10):
+		return digits, nil
+	case len(digits) == 14 && validCheckDigits(digits, 12, 13):
+		return digits, nil
+	}
+	return "", fmt.Errorf("%w: CPF ou CNPJ inválido: %q", ErrValidation, document)
+}
+
+// validCheckDigits confere os dois dígitos verificadores (módulo 11) de um
+// CPF (pesos 10..2 e 11..2) ou CNPJ (pesos 5..2,9..2 e 6..2,9..2). Sequências
+// de um único dígito repetido passam no cálculo mas não são documentos válidos.
+func validCheckDigits(digits string, first, second int) bool {
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+	for _, n := range []int{first, second} {
+		sum, weight := 0, n+1
+		if len(digits) == 14 {
+			weight = n - 7
+		}
+		for i := 0; i < n; i++ {
+			if len(digits) == 14 && weight < 2 {
+				weight = 9
+			}
+			sum += int(digits[i]-'0') * weight
+			weight--
+		}
+		check := sum % 11
+		if check < 2 {
+			check = 0
+		} else {
+			check = 11 - check
+		}
+		if int(digits[n]-'0') != check {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "testing"
+
+func TestNormalizeDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+		wantErr  bool
+	}{
+		{"CPF com pontuação", "529.982.247-25", "52998224725", false},
+		{"CPF só dígitos", "52998224725", "52998224725", false},
+		{"CNPJ com pontuação", "11.222.333/0001-81", "11222333000181", false},
+		{"CPF com dígito errado", "529.982.247-24", "", true},
+		{"CNPJ com dígito errado", "11.222.333/0001-80", "", true},
+		{"dígitos repetidos", "111.111.111-11", "", true},
+		{"tamanho errado", "1234567890", "", true},
+		{"letras", "529.982.247-2a", "", true},
+		{"vazio", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDocument(tt.document)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("NormalizeDocument(%q) = %q, %v; want %q, erro = %v", tt.document, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DunningFinalAction define o que acontece ao fim do grace period sem pagamento
+type DunningFinalAction string
+
+const (
+	DunningFinalActionCancel  DunningFinalAction = "cancel"  // Cancela a assinatura
+	DunningFinalActionSuspend DunningFinalAction = "suspend" // Suspende o acesso (recuperável com pagamento)
+)
+
+// DunningNotice é um aviso da régua de cobrança, enviado AfterDays após a primeira falha
+type DunningNotice struct {
+	AfterDays int    `json:"after_days"`
+	Level     string `json:"level"` // "reminder" | "warning" | "final"
+}
+
+// DunningPolicy configura a régua de cobrança de assinaturas past_due
+type DunningPolicy struct {
+	GraceDays   int                `json:"grace_days"`   // Dias após a primeira falha até a ação final
+	RetryDays   []int              `json:"retry_days"`   // Dias após a primeira falha em que uma nova cobrança PIX é gerada
+	Notices     []DunningNotice    `json:"notices"`      // Avisos escalonados (ordenados por AfterDays)
+	FinalAction DunningFinalAction `json:"final_action"` // Ação ao fim do grace period
+}
+
+// DefaultDunningPolicy retorna a régua padrão (7 dias de grace period)
+func DefaultDunningPolicy() DunningPolicy {
+	return DunningPolicy{
+		GraceDays: 7,
+		RetryDays: []int{1, 3, 5},
+		Notices: []DunningNotice{
+			{AfterDays: 0, Level: "reminder"},
+			{AfterDays: 3, Level: "warning"},
+			{AfterDays: 6, Level: "final"},
+		},
+		FinalAction: DunningFinalActionCancel,
+	}
+}
+
+// Validate verifica se a política é consistente
+func (p DunningPolicy) Validate() error {
+	if p.GraceDays < 0 {
+		return fmt.Errorf("%w: grace_days não pode ser negativo", ErrValidation)
+	}
+	if p.FinalAction != DunningFinalActionCancel && p.FinalAction != DunningFinalActionSuspend {
+		return fmt.Errorf("%w: final_action inválida: %q", ErrValidation, p.FinalAction)
+	}
+	for i, day := range p.RetryDays {
+		if day < 0 || day >= p.GraceDays || (i > 0 && day <= p.RetryDays[i-1]) {
+			return fmt.Errorf("%w: retry_days[%d] deve ser crescente e menor que grace_days", ErrValidation, i)
+		}
+	}
+	for i, notice := range p.Notices {
+		if notice.AfterDays < 0 || (i > 0 && notice.AfterDays < p.Notices[i-1].AfterDays) {
+			return fmt.Errorf("%w: notices[%d] deve estar em ordem crescente de after_days", ErrValidation, i)
+		}
+	}
+	return nil
+}
+
+// DunningState é o estado da régua derivado do histórico de pagamentos
+type DunningState struct {
+	StartedAt      time.Time // Primeira falha desde o último pagamento confirmado
+	FailedAttempts int       // Cobranças falhas desde StartedAt
+	Retries        int       // Cobranças criadas depois da primeira falha (retentativas)
+}
+
+// ComputeDunningState deriva o estado da régua a partir dos pagamentos da assinatura.
+// Retorna false se não há falha desde o último pagamento confirmado.
+func ComputeDunningState(payments []*PaymentHistory) (DunningState, bool) {
+	var lastPaid time.Time
+	for _, p := range payments {
+		if p.IsPaid() && p.CreatedAt.After(lastPaid) {
+			lastPaid = p.CreatedAt
+		}
+	}
+
+	var state DunningState
+	for _, p := range payments {
+		if p.Status == PaymentStatusFailed && p.CreatedAt.After(lastPaid) {
+			if state.StartedAt.IsZero() || p.CreatedAt.Before(state.StartedAt) {
+				state.StartedAt = p.CreatedAt
+			}
+			state.FailedAttempts++
+		}
+	}
+	if state.StartedAt.IsZero() {
+		return state, false
+	}
+
+	for _, p := range payments {
+		if p.CreatedAt.After(state.StartedAt) {
+			state.Retries++
+		}
+	}
+	return state, true
+}
+
+// GraceEndsAt retorna quando o grace period termina
+func (p DunningPolicy) GraceEndsAt(state DunningState) time.Time {
+	return state.StartedAt.AddDate(0, 0, p.GraceDays)
+}
+
+// RetryDue indica se a próxima retentativa já deve ser gerada
+func (p DunningPolicy) RetryDue(state DunningState, now time.Time) bool {
+	if state.Retries >= len(p.RetryDays) {
+		return false
+	}
+	return !now.Before(state.StartedAt.AddDate(0, 0, p.RetryDays[state.Retries]))
+}
+
+// DueNotice retorna o aviso mais escalonado já vencido que ainda não foi enviado.
+// sent é quantos avisos da régua já foram enviados; o retorno int é o novo total.
+func (p DunningPolicy) DueNotice(state DunningState, sent int, now time.Time) (DunningNotice, int, bool) {
+	due := -1
+	for i := sent; i < len(p.Notices); i++ {
+		if !now.Before(state.StartedAt.AddDate(0, 0, p.Notices[i].AfterDays)) {
+			due = i
+		}
+	}
+	if due < 0 {
+		return DunningNotice{}, sent, false
+	}
+	return p.Notices[due], due + 1, true
+}
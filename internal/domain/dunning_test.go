@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDunningPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  DunningPolicy
+		wantErr bool
+	}{
+		{"default", DefaultDunningPolicy(), false},
+		{"no retries", DunningPolicy{GraceDays: 3, FinalAction: DunningFinalActionSuspend}, false},
+		{"negative grace", DunningPolicy{GraceDays: -1, FinalAction: DunningFinalActionCancel}, true},
+		{"unknown action", DunningPolicy{GraceDays: 7, FinalAction: "delete"}, true},
+		{"retry after grace", DunningPolicy{GraceDays: 3, RetryDays: []int{1, 3}, FinalAction: DunningFinalActionCancel}, true},
+		{"retries out of order", DunningPolicy{GraceDays: 7, RetryDays: []int{3, 1}, FinalAction: DunningFinalActionCancel}, true},
+		{"notices out of order", DunningPolicy{GraceDays: 7, Notices: []DunningNotice{{AfterDays: 3}, {AfterDays: 1}}, FinalAction: DunningFinalActionCancel}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComputeDunningState(t *testing.T) {
+	base := spDate(2026, 3, 10)
+	payment := func(status PaymentStatus, day int) *PaymentHistory {
+		return &PaymentHistory{Status: status, CreatedAt: base.AddDate(0, 0, day)}
+	}
+
+	// Falha antiga já quitada é ignorada; a régua começa na falha do dia 30
+	payments := []*PaymentHistory{
+		payment(PaymentStatusFailed, 0),
+		payment(PaymentStatusSucceeded, 2),
+		payment(PaymentStatusFailed, 30),
+		payment(PaymentStatusPending, 31),
+		payment(PaymentStatusFailed, 33),
+	}
+
+	state, ok := ComputeDunningState(payments)
+	if !ok {
+		t.Fatal("ComputeDunningState() ok = false, want true")
+	}
+	if !state.StartedAt.Equal(base.AddDate(0, 0, 30)) || state.FailedAttempts != 2 || state.Retries != 2 {
+		t.Errorf("state = %+v, want início no dia 30, 2 falhas e 2 retentativas", state)
+	}
+
+	if _, ok := ComputeDunningState(payments[:2]); ok {
+		t.Error("ComputeDunningState() ok = true, want false após pagamento confirmado")
+	}
+}
+
+func TestDunningPolicy_DueNotice(t *testing.T) {
+	policy := DefaultDunningPolicy()
+	state := DunningState{StartedAt: spDate(2026, 3, 10)}
+	at := func(days int) time.Time { return state.StartedAt.AddDate(0, 0, days) }
+
+	tests := []struct {
+		name      string
+		sent      int
+		now       time.Time
+		wantLevel string
+		wantSent  int
+		wantDue   bool
+	}{
+		{"first reminder", 0, at(0), "reminder", 1, true},
+		{"already reminded", 1, at(1), "", 1, false},
+		{"warning", 1, at(3), "warning", 2, true},
+		{"skips to most escalated", 0, at(6), "final", 3, true},
+		{"all sent", 3, at(6), "", 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notice, sent, due := policy.DueNotice(state, tt.sent, tt.now)
+			if due != tt.wantDue || sent != tt.wantSent || notice.Level != tt.wantLevel {
+				t.Errorf("DueNotice() = %q, %d, %v, want %q, %d, %v", notice.Level, sent, due, tt.wantLevel, tt.wantSent, tt.wantDue)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import "fmt"
+
+// Feature é uma funcionalidade liberada por plano (alinhado com subscription_plans.features)
+type Feature string
+
+const (
+	FeatureCheckin         Feature = "checkin"          // Check-in de alunos
+	FeatureSchedule        Feature = "schedule"         // Grade de horários
+	FeatureProfiles        Feature = "profiles"         // Perfis de alunos e professores
+	FeatureAnalytics       Feature = "analytics"        // Relatórios e métricas
+	FeatureStore           Feature = "store"            // Loja da academia
+	FeatureAPI             Feature = "api"              // Acesso à API pública
+	FeatureMultiLocation   Feature = "multi_location"   // Mais de uma unidade
+	FeaturePrioritySupport Feature = "priority_support" // Suporte prioritário
+)
+
+// ValidFeatures lista todas as features conhecidas
+var ValidFeatures = []Feature{
+	FeatureCheckin,
+	FeatureSchedule,
+	FeatureProfiles,
+	FeatureAnalytics,
+	FeatureStore,
+	FeatureAPI,
+	FeatureMultiLocation,
+	FeaturePrioritySupport,
+}
+
+// IsValid verifica se a feature é conhecida
+func (f Feature) IsValid() bool {
+	for _, valid := range ValidFeatures {
+		if f == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureSet é a lista de features de um plano (JSON array de strings)
+type FeatureSet []Feature
+
+// Has verifica se a feature está no conjunto
+func (fs FeatureSet) Has(feature Feature) bool {
+	for _, f := range fs {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate rejeita features desconhecidas
+func (fs FeatureSet) Validate() error {
+	for _, f := range fs {
+		if !f.IsValid() {
+			return fmt.Errorf("%w: feature desconhecida: %q", ErrValidation, f)
+		}
+	}
+	return nil
+}
+
+// Resource é um recurso limitado por plano
+type Resource string
+
+const (
+	ResourceStudents   Resource = "students"
+	ResourceProfessors Resource = "professors"
+	ResourceLocations  Resource = "locations"
+)
+
+// ValidResources lista todos os recursos limitados
+var ValidResources = []Resource{ResourceStudents, ResourceProfessors, ResourceLocations}
+
+// HasFeature verifica se o plano inclui a feature
+func (p *SubscriptionPlan) HasFeature(feature Feature) bool {
+	return p.Features.Has(feature)
+}
+
+// Limit retorna o limite do plano para o recurso (nil = ilimitado).
+// Sem a feature multi_location o plano fica limitado a uma unidade.
+func (p *SubscriptionPlan) Limit(resource Resource) *int {
+	switch resource {
+	case ResourceStudents:
+		return p.MaxStudents
+	case ResourceProfessors:
+		return p.MaxProfessors
+	case ResourceLocations:
+		limit := p.MaxLocations
+		if limit < 1 || !p.HasFeature(FeatureMultiLocation) {
+			limit = 1
+		}
+		return &limit
+	}
+	zero := 0
+	return &zero
+}
+
+// HasAccess verifica se a assinatura libera o uso do plano.
+// past_due mantém o acesso durante o grace period; suspended, canceled e expired não.
+func (s *Subscription) HasAccess() bool {
+	switch s.Status {
+	case SubscriptionStatusTrialing, SubscriptionStatusActive, SubscriptionStatusPastDue:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSubscriptionPlan_FeaturesFromJSON(t *testing.T) {
+	var plan SubscriptionPlan
+	raw := `{"slug": "pro", "max_locations": 3, "features": ["checkin", "schedule", "analytics", "store"]}`
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		feature Feature
+		want    bool
+	}{
+		{FeatureCheckin, true},
+		{FeatureAnalytics, true},
+		{FeatureAPI, false},
+		{FeatureMultiLocation, false},
+	}
+	for _, tt := range tests {
+		if got := plan.HasFeature(tt.feature); got != tt.want {
+			t.Errorf("HasFeature(%s) = %v, want %v", tt.feature, got, tt.want)
+		}
+	}
+
+	// max_locations só vale com multi_location
+	if limit := plan.Limit(ResourceLocations); limit == nil || *limit != 1 {
+		t.Errorf("Limit(locations) = %v, want 1 sem multi_location", limit)
+	}
+	plan.Features = append(plan.Features, FeatureMultiLocation)
+	if limit := plan.Limit(ResourceLocations); limit == nil || *limit != 3 {
+		t.Errorf("Limit(locations) = %v, want 3", limit)
+	}
+	if plan.Limit(ResourceStudents) != nil {
+		t.Error("Limit(students) deveria ser ilimitado sem max_students")
+	}
+}
+
+func TestFeatureSet_Validate(t *testing.T) {
+	if err := (FeatureSet{FeatureCheckin, FeatureAPI}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (FeatureSet{FeatureCheckin, "teleport"}).Validate(); err == nil {
+		t.Error("Validate() deveria rejeitar feature desconhecida")
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "errors"
+
+// Erros de domínio compartilhados entre serviços e repositórios
+var (
+	// ErrValidation indica dados de entrada inválidos (campo obrigatório, valor fora do permitido)
+	ErrValidation = errors.New("dados inválidos")
+
+	// ErrNotFound indica que a entidade não foi encontrada
+	ErrNotFound = errors.New("registro não encontrado")
+
+	// ErrAlreadyExists indica um registro duplicado (ex: segunda assinatura da mesma academia)
+	ErrAlreadyExists = errors.New("registro já existe")
+
+	// ErrInvalidTransition indica uma mudança de status não permitida pela máquina de estados
+	ErrInvalidTransition = errors.New("transição de status inválida")
+
+	// ErrIntervalUnavailable indica que o plano não oferece o intervalo de cobrança pedido
+	ErrIntervalUnavailable = errors.New("intervalo de cobrança não disponível para o plano")
+
+	// ErrPlanChangeNotAllowed indica uma troca de plano que não pode ser feita no estado atual
+	ErrPlanChangeNotAllowed = errors.New("troca de plano não permitida")
+
+	// ErrSubscriptionInactive indica que a assinatura da academia não libera o uso do plano
+	ErrSubscriptionInactive = errors.New("assinatura inativa")
+
+	// ErrFeatureUnavailable indica uma feature que não faz parte do plano
+	ErrFeatureUnavailable = errors.New("feature não disponível no plano")
+
+	// ErrLimitReached indica que o limite do plano para o recurso foi atingido
+	ErrLimitReached = errors.New("limite do plano atingido")
+
+	// ErrCouponInvalid indica um cupom que não pode ser resgatado (expirado, esgotado, outro plano)
+	ErrCouponInvalid = errors.New("cupom inválido")
+
+	// ErrTrialCampaignInvalid indica um código de campanha de trial expirado, inativo ou de outro plano
+	ErrTrialCampaignInvalid = errors.New("campanha de trial inválida")
+
+	// ErrRefundNotAllowed indica uma devolução acima do valor disponível ou de pagamento não confirmado
+	ErrRefundNotAllowed = errors.New("devolução não permitida")
+
+	// ErrOwnerHasAcademy indica um dono cujo perfil já está vinculado a uma academia
+	ErrOwnerHasAcademy = errors.New("usuário já tem academia")
+
+	// ErrConcurrentModification indica que o registro foi alterado por outra
+	// escrita depois de lido; recarregue e reaplique a mudança
+	ErrConcurrentModification = errors.New("registro alterado concorrentemente")
+
+	// ErrAuditChainBroken indica um audit log cuja cadeia de hashes não confere
+	ErrAuditChainBroken = errors.New("cadeia do audit log corrompida")
+)
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// EventType identifica um evento de domínio
+type EventType string
+
+const (
+	EventTrialStarted          EventType = "subscription.trial_started"
+	EventSubscriptionActivated EventType = "subscription.activated"
+	EventSubscriptionPastDue   EventType = "subscription.past_due"
+	EventSubscriptionSuspended EventType = "subscription.suspended"
+	EventSubscriptionPaused    EventType = "subscription.paused"
+	EventSubscriptionResumed   EventType = "subscription.resumed"
+	EventSubscriptionCanceled  EventType = "subscription.canceled"
+	EventSubscriptionExpired   EventType = "subscription.expired"
+
+	EventPaymentSucceeded EventType = "payment.succeeded"
+	EventPaymentFailed    EventType = "payment.failed"
+	EventPaymentRefunded  EventType = "payment.refunded"
+)
+
+// Tipos de agregado que emitem eventos
+const (
+	AggregateSubscription = "subscription"
+	AggregatePayment      = "payment"
+)
+
+// DomainEvent é um fato ocorrido em uma entidade. Os eventos são coletados
+// pelos métodos das entidades e gravados no outbox junto com a entidade.
+type DomainEvent struct {
+	ID            string            `json:"id"` // Único: subscribers usam para deduplicar
+	Type          EventType         `json:"type"`
+	AggregateType string            `json:"aggregate_type"`
+	AggregateID   string            `json:"aggregate_id"`
+	AcademyID     string            `json:"academy_id"`
+	Data          map[string]string `json:"data,omitempty"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+}
+
+// eventRecorder acumula os eventos de uma entidade até serem persistidos
+type eventRecorder struct {
+	events []DomainEvent
+}
+
+// record registra um novo evento
+func (r *eventRecorder) record(eventType EventType, aggregateType, aggregateID, academyID string, at time.Time, data map[string]string) {
+	r.events = append(r.events, DomainEvent{
+		ID:            newEventID(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		AcademyID:     academyID,
+		Data:          data,
+		OccurredAt:    at,
+	})
+}
+
+// pull devolve os eventos pendentes e limpa a lista; aggregateID preenche
+// eventos registrados antes da entidade ter ID (ex: trial antes do INSERT)
+func (r *eventRecorder) pull(aggregateID string) []DomainEvent {
+	events := r.events
+	r.events = nil
+	for i := range events {
+		if events[i].AggregateID == "" {
+			events[i].AggregateID = aggregateID
+		}
+	}
+	return events
+}
+
+// PullEvents devolve e limpa os eventos pendentes da assinatura.
+// Repositórios chamam ao persistir e gravam no outbox na mesma transação.
+func (s *Subscription) PullEvents() []DomainEvent {
+	return s.events.pull(s.ID)
+}
+
+// PullEvents devolve e limpa os eventos pendentes do pagamento
+func (p *PaymentHistory) PullEvents() []DomainEvent {
+	return p.events.pull(p.ID)
+}
+
+// statusEvents mapeia o status de destino de uma transição para o evento
+var statusEvents = map[SubscriptionStatus]EventType{
+	SubscriptionStatusActive:    EventSubscriptionActivated,
+	SubscriptionStatusPastDue:   EventSubscriptionPastDue,
+	SubscriptionStatusSuspended: EventSubscriptionSuspended,
+	SubscriptionStatusPaused:    EventSubscriptionPaused,
+	SubscriptionStatusCanceled:  EventSubscriptionCanceled,
+	SubscriptionStatusExpired:   EventSubscriptionExpired,
+}
+
+// recordTransition registra o evento de uma mudança de status
+func (s *Subscription) recordTransition(t SubscriptionTransition) {
+	eventType, ok := statusEvents[t.To]
+	if !ok || t.From == t.To {
+		return
+	}
+	if t.From == SubscriptionStatusPaused && t.To == SubscriptionStatusActive {
+		eventType = EventSubscriptionResumed
+	}
+
+	data := map[string]string{"from": string(t.From), "to": string(t.To), "actor": t.Actor}
+	if t.Reason != "" {
+		data["reason"] = t.Reason
+	}
+	s.events.record(eventType, AggregateSubscription, s.ID, s.AcademyID, t.At, data)
+}
+
+// recordPayment registra um evento de pagamento
+func (p *PaymentHistory) recordPayment(eventType EventType, at time.Time, data map[string]string) {
+	if data == nil {
+		data = map[string]string{}
+	}
+	data["subscription_id"] = p.SubscriptionID
+	data["amount"] = strconv.Itoa(p.Amount)
+	data["gateway"] = string(p.PaymentGateway)
+	p.events.record(eventType, AggregatePayment, p.ID, p.AcademyID, at, data)
+}
+
+// newEventID gera um UUID v4 para o evento
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
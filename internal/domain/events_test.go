@@ -0,0 +1,78 @@
+package domain
+
+import "testing"
+
+func TestSubscription_Events(t *testing.T) {
+	sub := NewTrialSubscription("academy-1", TrialTerms{PlanID: "plan-1", Days: 20}, spDate(2026, 6, 1))
+	sub.ID = "sub-1"
+	start := spDate(2026, 6, 1)
+
+	if err := sub.Activate(PaymentGatewayPixAuto, start, start.AddDate(0, 1, 0), ActorWebhook, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.MarkPastDue("saldo insuficiente", ActorWebhook, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Cancel("inadimplência", false, "user-1", start); err != nil {
+		t.Fatal(err)
+	}
+
+	events := sub.PullEvents()
+	want := []EventType{EventTrialStarted, EventSubscriptionActivated, EventSubscriptionPastDue, EventSubscriptionCanceled}
+	if len(events) != len(want) {
+		t.Fatalf("eventos = %d, want %d", len(events), len(want))
+	}
+	seen := map[string]bool{}
+	for i, e := range events {
+		if e.Type != want[i] {
+			t.Errorf("events[%d].Type = %s, want %s", i, e.Type, want[i])
+		}
+		if e.AggregateType != AggregateSubscription || e.AggregateID != "sub-1" || e.AcademyID != "academy-1" {
+			t.Errorf("events[%d] = %+v, agregado incorreto", i, e)
+		}
+		if e.ID == "" || seen[e.ID] {
+			t.Errorf("events[%d].ID = %q, deveria ser único", i, e.ID)
+		}
+		seen[e.ID] = true
+	}
+	if events[3].Data["reason"] != "inadimplência" || events[3].Data["actor"] != "user-1" {
+		t.Errorf("Data do cancelamento = %v", events[3].Data)
+	}
+
+	if again := sub.PullEvents(); len(again) != 0 {
+		t.Errorf("PullEvents deveria limpar a lista, got %d", len(again))
+	}
+}
+
+func TestSubscription_ResumeEmitsResumed(t *testing.T) {
+	sub := &Subscription{ID: "sub-1", AcademyID: "academy-1", Status: SubscriptionStatusActive}
+	start := spDate(2026, 6, 1)
+	if err := sub.TransitionTo(SubscriptionStatusPaused, "férias", "user-1", start); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.TransitionTo(SubscriptionStatusActive, "", "user-1", start); err != nil {
+		t.Fatal(err)
+	}
+
+	events := sub.PullEvents()
+	if len(events) != 2 || events[0].Type != EventSubscriptionPaused || events[1].Type != EventSubscriptionResumed {
+		t.Errorf("eventos = %+v, want paused e resumed", events)
+	}
+}
+
+func TestPaymentHistory_Events(t *testing.T) {
+	payment := &PaymentHistory{ID: "payment-1", AcademyID: "academy-1", SubscriptionID: "sub-1", Amount: 9900, PaymentGateway: PaymentGatewayPixAuto}
+	now := spDate(2026, 6, 1)
+	payment.Succeed(now)
+	if err := payment.ConfirmRefund("dev1", "D123", 9900, now); err != nil {
+		t.Fatalf("ConfirmRefund() error = %v", err)
+	}
+
+	events := payment.PullEvents()
+	if len(events) != 2 || events[0].Type != EventPaymentSucceeded || events[1].Type != EventPaymentRefunded {
+		t.Fatalf("eventos = %+v, want succeeded e refunded", events)
+	}
+	if events[0].Data["amount"] != "9900" || events[0].Data["subscription_id"] != "sub-1" {
+		t.Errorf("Data = %v", events[0].Data)
+	}
+}
@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// InvoiceStatus representa o estado de uma fatura
+type InvoiceStatus string
+
+const (
+	InvoiceStatusIssued InvoiceStatus = "issued" // Emitida (pagamento confirmado)
+	InvoiceStatusVoid   InvoiceStatus = "void"   // Anulada (pagamento estornado); o número não é reaproveitado
+)
+
+// InvoiceLineKind identifica o tipo de um item da fatura
+type InvoiceLineKind string
+
+const (
+	InvoiceLinePlan      InvoiceLineKind = "plan"      // Mensalidade/anuidade do plano
+	InvoiceLineProration InvoiceLineKind = "proration" // Diferença pró-rata de troca de plano
+	InvoiceLineDiscount  InvoiceLineKind = "discount"  // Desconto de cupom (valor negativo)
+	InvoiceLineTax       InvoiceLineKind = "tax"       // Tributos
+)
+
+// InvoiceLine é um item da fatura (valores em centavos)
+type InvoiceLine struct {
+	Kind        InvoiceLineKind `json:"kind"`
+	Description string          `json:"description"`
+	Amount      int             `json:"amount"`
+
+	// Included marca valores já contidos no total (tributos aproximados da
+	// Lei 12.741/2012): aparecem na fatura mas não somam ao total
+	Included bool `json:"included,omitempty"`
+}
+
+// InvoiceTaxRate é um tributo informado na fatura, em pontos-base sobre o total
+type InvoiceTaxRate struct {
+	Name        string `json:"name"`         // ex: "ISS"
+	BasisPoints int    `json:"basis_points"` // 200 = 2%
+}
+
+// Invoice representa a fatura de um ciclo pago de uma academia.
+// Number é sequencial e sem lacunas por academia (atribuído ao persistir).
+type Invoice struct {
+	ID             string        `json:"id"`
+	AcademyID      string        `json:"academy_id"`
+	SubscriptionID string        `json:"subscription_id"`
+	PaymentID      string        `json:"payment_id"`
+	Number         int64         `json:"number"`
+	Status         InvoiceStatus `json:"status"`
+	Currency       string        `json:"currency"`
+
+	PeriodStart *time.Time `json:"period_start,omitempty"`
+	PeriodEnd   *time.Time `json:"period_end,omitempty"`
+
+	Lines []InvoiceLine `json:"lines"`
+	Total int           `json:"total"` // Soma dos itens não inclusos (= valor pago)
+
+	IssuedAt  time.Time `json:"issued_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DisplayNumber retorna o número formatado para exibição (ex: "000042")
+func (i *Invoice) DisplayNumber() string {
+	return fmt.Sprintf("%06d", i.Number)
+}
+
+// Subtotal soma os itens de plano e pró-rata, antes dos descontos
+func (i *Invoice) Subtotal() int {
+	total := 0
+	for _, line := range i.Lines {
+		if line.Kind == InvoiceLinePlan || line.Kind == InvoiceLineProration {
+			total += line.Amount
+		}
+	}
+	return total
+}
+
+// Void anula a fatura após o estorno do pagamento
+func (i *Invoice) Void() {
+	i.Status = InvoiceStatusVoid
+}
+
+// Branding são os dados da academia impressos na fatura
+type Branding struct {
+	AcademyName  string `json:"academy_name"`
+	Document     string `json:"document,omitempty"` // CNPJ/CPF
+	Address      string `json:"address,omitempty"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	PrimaryColor string `json:"primary_color,omitempty"` // "#RRGGBB"
+}
+
+// NewInvoice monta a fatura de um pagamento confirmado. O que o pagamento
+// tem acima do preço do plano é pró-rata somada ao ciclo; um pagamento abaixo
+// do preço cheio é a cobrança avulsa da diferença de um upgrade.
+func NewInvoice(payment *PaymentHistory, plan *SubscriptionPlan, interval BillingInterval, taxes []InvoiceTaxRate, now time.Time) (*Invoice, error) {
+	if !payment.IsPaid() {
+		return nil, fmt.Errorf("fatura exige pagamento confirmado (status %s)", payment.Status)
+	}
+	price, err := plan.PriceFor(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := now
+	if payment.PaidAt != nil {
+		issuedAt = *payment.PaidAt
+	}
+	currency := payment.Currency
+	if currency == "" {
+		currency = "BRL"
+	}
+
+	inv := &Invoice{
+		AcademyID:      payment.AcademyID,
+		SubscriptionID: payment.SubscriptionID,
+		PaymentID:      payment.ID,
+		Status:         InvoiceStatusIssued,
+		Currency:       currency,
+		PeriodStart:    payment.PeriodStart,
+		PeriodEnd:      payment.PeriodEnd,
+		IssuedAt:       issuedAt,
+		CreatedAt:      now,
+	}
+
+	gross := payment.GrossAmount
+	if gross == 0 {
+		gross = payment.Amount + payment.DiscountAmount
+	}
+	if gross >= price {
+		inv.addLine(InvoiceLinePlan, fmt.Sprintf("Plano %s (%s)", plan.Name, intervalLabel(interval)), price)
+		if gross > price {
+			inv.addLine(InvoiceLineProration, "Ajuste pró-rata de troca de plano", gross-price)
+		}
+	} else {
+		inv.addLine(InvoiceLineProration, fmt.Sprintf("Diferença pró-rata do upgrade para o plano %s", plan.Name), gross)
+	}
+
+	if payment.DiscountAmount > 0 {
+		inv.addLine(InvoiceLineDiscount, "Desconto de cupom", -payment.DiscountAmount)
+	}
+
+	if inv.Total != payment.Amount {
+		return nil, fmt.Errorf("total da fatura (%d) diferente do valor pago (%d)", inv.Total, payment.Amount)
+	}
+
+	for _, tax := range taxes {
+		if tax.BasisPoints <= 0 {
+			continue
+		}
+		inv.Lines = append(inv.Lines, InvoiceLine{
+			Kind:        InvoiceLineTax,
+			Description: fmt.Sprintf("%s aproximado (%s%%)", tax.Name, formatBasisPoints(tax.BasisPoints)),
+			Amount:      inv.Total * tax.BasisPoints / 10000,
+			Included:    true,
+		})
+	}
+	return inv, nil
+}
+
+// addLine adiciona um item que soma ao total
+func (i *Invoice) addLine(kind InvoiceLineKind, description string, amount int) {
+	i.Lines = append(i.Lines, InvoiceLine{Kind: kind, Description: description, Amount: amount})
+	i.Total += amount
+}
+
+// intervalLabel descreve o intervalo de cobrança em português
+func intervalLabel(interval BillingInterval) string {
+	if interval == BillingIntervalYearly {
+		return "anual"
+	}
+	return "mensal"
+}
+
+// formatBasisPoints formata pontos-base como percentual ("2", "2,5", "0,65")
+func formatBasisPoints(bp int) string {
+	whole, frac := bp/100, bp%100
+	switch {
+	case frac == 0:
+		return fmt.Sprintf("%d", whole)
+	case frac%10 == 0:
+		return fmt.Sprintf("%d,%d", whole, frac/10)
+	default:
+		return fmt.Sprintf("%d,%02d", whole, frac)
+	}
+}
+
+// FormatBRL formata centavos como moeda brasileira (ex: "R$ 1.234,56")
+func FormatBRL(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	reais := fmt.Sprintf("%d", cents/100)
+	for i := len(reais) - 3; i > 0; i -= 3 {
+		reais = reais[:i] + "." + reais[i:]
+	}
+	return fmt.Sprintf("%sR$ %s,%02d", sign, reais, cents%100)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import "testing"
+
+func TestNewInvoice(t *testing.T) {
+	yearly := 99000
+	plan := &SubscriptionPlan{ID: "pro", Slug: "pro", Name: "Pro", PriceMonthly: 19900, PriceYearly: &yearly}
+	couponID := "coupon-1"
+	paidAt := spDate(2026, 5, 10)
+
+	tests := []struct {
+		name      string
+		interval  BillingInterval
+		gross     int
+		discount  int
+		wantKinds []InvoiceLineKind
+		wantTotal int
+	}{
+		{"full cycle", BillingIntervalMonthly, 19900, 0, []InvoiceLineKind{InvoiceLinePlan}, 19900},
+		{"yearly", BillingIntervalYearly, 99000, 0, []InvoiceLineKind{InvoiceLinePlan}, 99000},
+		{"cycle with proration", BillingIntervalMonthly, 24900, 0, []InvoiceLineKind{InvoiceLinePlan, InvoiceLineProration}, 24900},
+		{"upgrade difference", BillingIntervalMonthly, 5000, 0, []InvoiceLineKind{InvoiceLineProration}, 5000},
+		{"coupon", BillingIntervalMonthly, 19900, 1990, []InvoiceLineKind{InvoiceLinePlan, InvoiceLineDiscount}, 17910},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := NewPaymentHistory("sub-1", "academy-1", tt.gross, PaymentGatewayPixAuto, paidAt)
+			payment.ID = "payment-1"
+			if tt.discount > 0 {
+				payment.ApplyDiscount(tt.discount, couponID)
+			}
+			payment.Succeed(paidAt)
+
+			inv, err := NewInvoice(payment, plan, tt.interval, nil, paidAt)
+			if err != nil {
+				t.Fatalf("NewInvoice() error = %v", err)
+			}
+			if inv.Total != tt.wantTotal || inv.Total != payment.Amount {
+				t.Errorf("Total = %d, want %d (pago %d)", inv.Total, tt.wantTotal, payment.Amount)
+			}
+			if len(inv.Lines) != len(tt.wantKinds) {
+				t.Fatalf("Lines = %+v, want kinds %v", inv.Lines, tt.wantKinds)
+			}
+			for i, kind := range tt.wantKinds {
+				if inv.Lines[i].Kind != kind {
+					t.Errorf("Lines[%d].Kind = %s, want %s", i, inv.Lines[i].Kind, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestNewInvoice_IncludedTaxes(t *testing.T) {
+	plan := &SubscriptionPlan{ID: "starter", Slug: "starter", Name: "Starter", PriceMonthly: 9900}
+	paidAt := spDate(2026, 5, 10)
+	payment := NewPaymentHistory("sub-1", "academy-1", 9900, PaymentGatewayPixAuto, paidAt)
+	payment.Succeed(paidAt)
+
+	inv, err := NewInvoice(payment, plan, BillingIntervalMonthly, []InvoiceTaxRate{{Name: "ISS", BasisPoints: 200}, {Name: "PIS/COFINS", BasisPoints: 365}}, paidAt)
+	if err != nil {
+		t.Fatalf("NewInvoice() error = %v", err)
+	}
+	if inv.Total != 9900 {
+		t.Errorf("Total = %d, want 9900 (tributos inclusos não somam)", inv.Total)
+	}
+	taxes := inv.Lines[1:]
+	if len(taxes) != 2 || !taxes[0].Included || taxes[0].Amount != 198 || taxes[1].Amount != 361 {
+		t.Errorf("tributos = %+v", taxes)
+	}
+	if taxes[1].Description != "PIS/COFINS aproximado (3,65%)" {
+		t.Errorf("Description = %q", taxes[1].Description)
+	}
+
+	pending := NewPaymentHistory("sub-1", "academy-1", 9900, PaymentGatewayPixAuto, paidAt)
+	if _, err := NewInvoice(pending, plan, BillingIntervalMonthly, nil, paidAt); err == nil {
+		t.Error("pagamento pendente não deveria gerar fatura")
+	}
+}
+
+func TestFormatBRL(t *testing.T) {
+	tests := []struct {
+		cents int
+		want  string
+	}{
+		{0, "R$ 0,00"},
+		{9900, "R$ 99,00"},
+		{123456, "R$ 1.234,56"},
+		{123456789, "R$ 1.234.567,89"},
+		{-1990, "-R$ 19,90"},
+	}
+	for _, tt := range tests {
+		if got := FormatBRL(tt.cents); got != tt.want {
+			t.Errorf("FormatBRL(%d) = %q, want %q", tt.cents, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NFSeStatus representa o estado de uma NFS-e
+type NFSeStatus string
+
+const (
+	NFSeStatusPending  NFSeStatus = "pending"  // RPS numerado, aguardando a prefeitura
+	NFSeStatusIssued   NFSeStatus = "issued"   // NFS-e emitida
+	NFSeStatusFailed   NFSeStatus = "failed"   // Rejeitada ou prefeitura fora do ar (reenviada com o mesmo RPS)
+	NFSeStatusCanceled NFSeStatus = "canceled" // Cancelada após o estorno do pagamento
+)
+
+// NFSeCancelCode é o código de cancelamento ABRASF
+type NFSeCancelCode int
+
+const (
+	NFSeCancelIssueError     NFSeCancelCode = 1 // Erro na emissão
+	NFSeCancelServiceNotDone NFSeCancelCode = 2 // Serviço não prestado (estorno)
+	NFSeCancelDuplicate      NFSeCancelCode = 4 // Duplicidade da nota
+)
+
+// FiscalAddress é o endereço fiscal de um tomador
+type FiscalAddress struct {
+	Street     string `json:"street"`
+	Number     string `json:"number"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district"`
+	CityCode   string `json:"city_code"` // Código IBGE do município (7 dígitos)
+	State      string `json:"state"`     // UF
+	ZIP        string `json:"zip"`
+}
+
+// FiscalParty identifica o tomador do serviço (a academia) na NFS-e
+type FiscalParty struct {
+	Document string         `json:"document"` // CPF (11) ou CNPJ (14), só dígitos
+	Name     string         `json:"name"`     // Razão social ou nome
+	Email    string         `json:"email,omitempty"`
+	Address  *FiscalAddress `json:"address,omitempty"`
+}
+
+// IsCompany indica se o tomador é pessoa jurídica
+func (p *FiscalParty) IsCompany() bool {
+	return len(p.Document) == 14
+}
+
+// Validate verifica os dados mínimos do tomador
+func (p *FiscalParty) Validate() error {
+	if len(p.Document) != 11 && len(p.Document) != 14 {
+		return fmt.Errorf("%w: documento do tomador deve ser CPF ou CNPJ: %q", ErrValidation, p.Document)
+	}
+	if strings.Trim(p.Document, "0123456789") != "" {
+		return fmt.Errorf("%w: documento do tomador deve conter apenas dígitos", ErrValidation)
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("%w: nome do tomador é obrigatório", ErrValidation)
+	}
+	return nil
+}
+
+// NFSe registra a nota fiscal de serviço de um pagamento.
+// O RPS (recibo provisório) é numerado antes do envio e não muda entre
+// retentativas: a prefeitura usa o número para evitar notas em duplicidade.
+type NFSe struct {
+	ID        string     `json:"id"`
+	AcademyID string     `json:"academy_id"`
+	PaymentID string     `json:"payment_id"`
+	Status    NFSeStatus `json:"status"`
+
+	RPSNumber int64  `json:"rps_number"`
+	RPSSeries string `json:"rps_series"`
+
+	Amount      int       `json:"amount"` // centavos
+	Description string    `json:"description"`
+	Competence  time.Time `json:"competence"`
+
+	// Preenchidos pela prefeitura
+	Number           *string `json:"number,omitempty"`
+	VerificationCode *string `json:"verification_code,omitempty"`
+	XML              string  `json:"-"` // XML enviado/autorizado
+
+	Attempts     int     `json:"attempts"`
+	ErrorMessage *string `json:"error_message,omitempty"`
+
+	IssuedAt   *time.Time `json:"issued_at,omitempty"`
+	CanceledAt *time.Time `json:"canceled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// NewNFSe cria a NFS-e pendente de um pagamento confirmado
+func NewNFSe(payment *PaymentHistory, series, description string, now time.Time) (*NFSe, error) {
+	if !payment.IsPaid() {
+		return nil, fmt.Errorf("NFS-e exige pagamento confirmado (status %s)", payment.Status)
+	}
+
+	competence := payment.CreatedAt
+	if payment.PaidAt != nil {
+		competence = *payment.PaidAt
+	}
+	return &NFSe{
+		AcademyID:   payment.AcademyID,
+		PaymentID:   payment.ID,
+		Status:      NFSeStatusPending,
+		RPSSeries:   series,
+		Amount:      payment.Amount,
+		Description: description,
+		Competence:  competence,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// MarkIssued registra a autorização da prefeitura
+func (n *NFSe) MarkIssued(number, verificationCode, xml string, at time.Time) {
+	n.Status = NFSeStatusIssued
+	n.Number = &number
+	n.VerificationCode = &verificationCode
+	n.XML = xml
+	n.ErrorMessage = nil
+	n.IssuedAt = &at
+	n.UpdatedAt = at
+}
+
+// MarkFailed registra uma tentativa de emissão que falhou
+func (n *NFSe) MarkFailed(err error, at time.Time) {
+	msg := err.Error()
+	n.Status = NFSeStatusFailed
+	n.ErrorMessage = &msg
+	n.UpdatedAt = at
+}
+
+// MarkCanceled registra o cancelamento da nota. Uma nota ainda não emitida
+// (pendente ou com falha) também é cancelada, para não ser reenviada.
+func (n *NFSe) MarkCanceled(at time.Time) error {
+	if n.Status == NFSeStatusCanceled {
+		return fmt.Errorf("%w: NFS-e já cancelada", ErrInvalidTransition)
+	}
+	n.Status = NFSeStatusCanceled
+	n.CanceledAt = &at
+	n.UpdatedAt = at
+	return nil
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxPauseDays limita a duração de uma pausa
+const MaxPauseDays = 90
+
+// PauseWindow é uma janela de pausa da assinatura (férias, reforma)
+type PauseWindow struct {
+	StartAt  time.Time `json:"start_at"`  // Início planejado
+	ResumeAt time.Time `json:"resume_at"` // Retomada planejada
+
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	ResumedAt    *time.Time `json:"resumed_at,omitempty"`
+	ExtendedDays int        `json:"extended_days,omitempty"` // Dias somados ao período na retomada
+
+	Reason string `json:"reason,omitempty"`
+	Actor  string `json:"actor"`
+}
+
+// IsOpen indica se a janela ainda não terminou (agendada ou em andamento)
+func (w *PauseWindow) IsOpen() bool {
+	return w.ResumedAt == nil
+}
+
+// CurrentPause retorna a janela de pausa agendada ou em andamento (nil se não houver)
+func (s *Subscription) CurrentPause() *PauseWindow {
+	if n := len(s.Pauses); n > 0 && s.Pauses[n-1].IsOpen() {
+		return &s.Pauses[n-1]
+	}
+	return nil
+}
+
+// SchedulePause agenda uma pausa de start até resume.
+// Se start já chegou a pausa começa imediatamente.
+func (s *Subscription) SchedulePause(start, resume time.Time, reason, actor string, now time.Time) error {
+	if s.Status != SubscriptionStatusActive {
+		return &TransitionError{From: s.Status, To: SubscriptionStatusPaused}
+	}
+	if s.CurrentPause() != nil {
+		return fmt.Errorf("%w: assinatura já tem uma pausa agendada", ErrInvalidTransition)
+	}
+	if start.Before(now) {
+		start = now
+	}
+	if !resume.After(start) {
+		return fmt.Errorf("%w: retomada deve ser depois do início da pausa", ErrValidation)
+	}
+	if calendarDaysBetween(start, resume) > MaxPauseDays {
+		return fmt.Errorf("%w: pausa limitada a %d dias", ErrValidation, MaxPauseDays)
+	}
+
+	s.Pauses = append(s.Pauses, PauseWindow{
+		StartAt:  start,
+		ResumeAt: resume,
+		Reason:   reason,
+		Actor:    actor,
+	})
+	s.UpdatedAt = now
+
+	if !start.After(now) {
+		return s.StartPause(actor, now)
+	}
+	return nil
+}
+
+// StartPause inicia a pausa agendada
+func (s *Subscription) StartPause(actor string, now time.Time) error {
+	pause := s.CurrentPause()
+	if pause == nil || pause.StartedAt != nil {
+		return fmt.Errorf("%w: nenhuma pausa agendada para iniciar", ErrInvalidTransition)
+	}
+	if err := s.TransitionTo(SubscriptionStatusPaused, pause.Reason, actor, now); err != nil {
+		return err
+	}
+	pause.StartedAt = &now
+	return nil
+}
+
+// CancelScheduledPause desiste de uma pausa que ainda não começou
+func (s *Subscription) CancelScheduledPause() error {
+	pause := s.CurrentPause()
+	if pause == nil || pause.StartedAt != nil {
+		return fmt.Errorf("%w: nenhuma pausa agendada para cancelar", ErrInvalidTransition)
+	}
+	s.Pauses = s.Pauses[:len(s.Pauses)-1]
+	return nil
+}
+
+// Resume encerra a pausa e estende o período pelos dias pausados,
+// para que a academia não pague pelo tempo em que ficou fechada
+func (s *Subscription) Resume(actor string, now time.Time) error {
+	pause := s.CurrentPause()
+	if s.Status != SubscriptionStatusPaused || pause == nil || pause.StartedAt == nil {
+		return &TransitionError{From: s.Status, To: SubscriptionStatusActive}
+	}
+	if err := s.TransitionTo(SubscriptionStatusActive, "fim da pausa", actor, now); err != nil {
+		return err
+	}
+
+	days := calendarDaysBetween(*pause.StartedAt, now)
+	if end := s.ResumedPeriodEnd(now); end != nil && days > 0 {
+		s.CurrentPeriodEnd = end
+		s.BillingAnchorDay = end.Day()
+	}
+	pause.ResumedAt = &now
+	pause.ExtendedDays = days
+	return nil
+}
+
+// ResumedPeriodEnd retorna o fim do período se a pausa em andamento for
+// retomada em now (estendido pelos dias pausados), sem alterar a assinatura.
+// É o próximo vencimento a combinar com o gateway antes de Resume.
+func (s *Subscription) ResumedPeriodEnd(now time.Time) *time.Time {
+	if s.CurrentPeriodEnd == nil {
+		return nil
+	}
+	end := s.CurrentPeriodEnd.In(BillingLocation)
+	if pause := s.CurrentPause(); pause != nil && pause.StartedAt != nil {
+		end = end.AddDate(0, 0, calendarDaysBetween(*pause.StartedAt, now))
+	}
+	return &end
+}
+
+// calendarDaysBetween conta os dias de calendário (São Paulo) entre a e b
+func calendarDaysBetween(a, b time.Time) int {
+	a, b = a.In(BillingLocation), b.In(BillingLocation)
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
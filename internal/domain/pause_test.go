@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSubscription_PauseResume(t *testing.T) {
+	start := spDate(2026, 7, 1)
+	end := spDate(2026, 8, 1)
+
+	sub := &Subscription{Status: SubscriptionStatusActive, CurrentPeriodStart: &start, CurrentPeriodEnd: &end, BillingAnchorDay: 1}
+
+	// Pausa agendada para as férias de julho
+	now := spDate(2026, 7, 5)
+	if err := sub.SchedulePause(spDate(2026, 7, 10), spDate(2026, 7, 25), "férias", "user-1", now); err != nil {
+		t.Fatalf("SchedulePause() error = %v", err)
+	}
+	if sub.Status != SubscriptionStatusActive || sub.CurrentPause() == nil {
+		t.Fatal("pausa futura deveria ficar apenas agendada")
+	}
+	if err := sub.SchedulePause(spDate(2026, 7, 12), spDate(2026, 7, 20), "", "user-1", now); err == nil {
+		t.Error("segunda pausa aberta deveria ser rejeitada")
+	}
+
+	if err := sub.StartPause(ActorSystem, spDate(2026, 7, 10)); err != nil {
+		t.Fatalf("StartPause() error = %v", err)
+	}
+	if sub.Status != SubscriptionStatusPaused || sub.HasAccess() {
+		t.Errorf("Status = %v, HasAccess = %v, want paused sem acesso", sub.Status, sub.HasAccess())
+	}
+
+	// Retomada antecipada: 12 dias pausados estendem o período
+	if err := sub.Resume("user-1", spDate(2026, 7, 22)); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if sub.Status != SubscriptionStatusActive {
+		t.Errorf("Status = %v, want active", sub.Status)
+	}
+	if want := spDate(2026, 8, 13); !sub.CurrentPeriodEnd.Equal(want) || sub.BillingAnchorDay != 13 {
+		t.Errorf("CurrentPeriodEnd = %v, anchor = %d, want %v e 13", sub.CurrentPeriodEnd, sub.BillingAnchorDay, want)
+	}
+	if sub.CurrentPause() != nil || sub.Pauses[0].ExtendedDays != 12 {
+		t.Errorf("pausa deveria estar encerrada com 12 dias, got %+v", sub.Pauses[0])
+	}
+}
+
+func TestSubscription_SchedulePauseValidation(t *testing.T) {
+	now := spDate(2026, 7, 1)
+
+	tests := []struct {
+		name      string
+		status    SubscriptionStatus
+		start     int // dias a partir de now
+		resume    int
+		wantErr   bool
+		wantState SubscriptionStatus
+	}{
+		{"immediate", SubscriptionStatusActive, 0, 30, false, SubscriptionStatusPaused},
+		{"start in past starts now", SubscriptionStatusActive, -3, 10, false, SubscriptionStatusPaused},
+		{"resume before start", SubscriptionStatusActive, 10, 5, true, SubscriptionStatusActive},
+		{"too long", SubscriptionStatusActive, 0, MaxPauseDays + 1, true, SubscriptionStatusActive},
+		{"past due", SubscriptionStatusPastDue, 0, 10, true, SubscriptionStatusPastDue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{Status: tt.status}
+			err := sub.SchedulePause(now.AddDate(0, 0, tt.start), now.AddDate(0, 0, tt.resume), "", "user-1", now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sub.Status != tt.wantState {
+				t.Errorf("Status = %v, want %v", sub.Status, tt.wantState)
+			}
+		})
+	}
+
+	sub := &Subscription{Status: SubscriptionStatusTrialing}
+	if err := sub.SchedulePause(now, now.AddDate(0, 0, 5), "", "user-1", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("pausa no trial: error = %v, want ErrInvalidTransition", err)
+	}
+}
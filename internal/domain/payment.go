@@ -6,11 +6,12 @@ import "time"
 type PaymentStatus string
 
 const (
-	PaymentStatusPending    PaymentStatus = "pending"
-	PaymentStatusProcessing PaymentStatus = "processing"
-	PaymentStatusSucceeded  PaymentStatus = "succeeded"
-	PaymentStatusFailed     PaymentStatus = "failed"
-	PaymentStatusRefunded   PaymentStatus = "refunded"
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusProcessing        PaymentStatus = "processing"
+	PaymentStatusSucceeded         PaymentStatus = "succeeded"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded" // Derivado das devoluções liquidadas
+	PaymentStatusRefunded          PaymentStatus = "refunded"
 )
 
 // ValidPaymentStatuses lista todos os status válidos
@@ -19,6 +20,7 @@ var ValidPaymentStatuses = []PaymentStatus{
 	PaymentStatusProcessing,
 	PaymentStatusSucceeded,
 	PaymentStatusFailed,
+	PaymentStatusPartiallyRefunded,
 	PaymentStatusRefunded,
 }
 
@@ -39,19 +41,27 @@ type PaymentHistory struct {
 	SubscriptionID string `json:"subscription_id"`
 	AcademyID      string `json:"academy_id"` // CHANGED: era UserID
 
-	// Amount (centavos)
-	Amount   int    `json:"amount"`
-	Currency string `json:"currency"` // default "BRL"
+	// Amount (centavos): Amount é o valor líquido cobrado, GrossAmount o preço cheio
+	Amount         int     `json:"amount"`
+	GrossAmount    int     `json:"gross_amount"`
+	DiscountAmount int     `json:"discount_amount"`
+	CouponID       *string `json:"coupon_id,omitempty"`
+	Currency       string  `json:"currency"` // default "BRL"
 
 	// Gateway info
 	PaymentGateway   PaymentGateway `json:"payment_gateway"`
 	GatewayPaymentID *string        `json:"gateway_payment_id,omitempty"` // PIX txid ou Stripe payment_intent_id
 	GatewayChargeID  *string        `json:"gateway_charge_id,omitempty"`  // ID da cobrança recorrente
 	GatewayInvoiceID *string        `json:"gateway_invoice_id,omitempty"` // Stripe invoice_id
+	EndToEndID       *string        `json:"end_to_end_id,omitempty"`      // PIX: e2eId da liquidação (exigido na devolução)
 
 	// Status
 	Status PaymentStatus `json:"status"`
 
+	// Devoluções: RefundedAmount soma as liquidadas e define refunded/partially_refunded
+	RefundedAmount int              `json:"refunded_amount"`
+	Refunds        []*PaymentRefund `json:"refunds,omitempty"`
+
 	// Details
 	PaymentMethod *string `json:"payment_method,omitempty"` // "pix" | "card"
 	FailureReason *string `json:"failure_reason,omitempty"`
@@ -64,6 +74,12 @@ type PaymentHistory struct {
 	// Timestamps
 	PaidAt    *time.Time `json:"paid_at,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
+
+	// Version é incrementada a cada gravação (ver Subscription.Version)
+	Version int `json:"version"`
+
+	// Eventos de domínio ainda não gravados no outbox
+	events eventRecorder
 }
 
 // IsPaid verifica se o pagamento foi confirmado
@@ -77,15 +93,16 @@ func (p *PaymentHistory) AmountInReais() float64 {
 }
 
 // NewPaymentHistory cria um novo registro de pagamento pendente
-func NewPaymentHistory(subscriptionID, academyID string, amountInCents int, gateway PaymentGateway) *PaymentHistory {
+func NewPaymentHistory(subscriptionID, academyID string, amountInCents int, gateway PaymentGateway, now time.Time) *PaymentHistory {
 	return &PaymentHistory{
 		SubscriptionID: subscriptionID,
 		AcademyID:      academyID,
 		Amount:         amountInCents,
+		GrossAmount:    amountInCents,
 		Currency:       "BRL",
 		PaymentGateway: gateway,
 		Status:         PaymentStatusPending,
-		CreatedAt:      time.Now(),
+		CreatedAt:      now,
 	}
 }
 
@@ -95,20 +112,16 @@ func (p *PaymentHistory) MarkProcessing() {
 }
 
 // Succeed marca o pagamento como bem-sucedido
-func (p *PaymentHistory) Succeed() {
-	now := time.Now()
+func (p *PaymentHistory) Succeed(now time.Time) {
 	p.Status = PaymentStatusSucceeded
 	p.PaidAt = &now
+	p.recordPayment(EventPaymentSucceeded, now, nil)
 }
 
 // Fail marca o pagamento como falho
-func (p *PaymentHistory) Fail(reason, code string) {
+func (p *PaymentHistory) Fail(reason, code string, now time.Time) {
 	p.Status = PaymentStatusFailed
 	p.FailureReason = &reason
 	p.FailureCode = &code
-}
-
-// Refund marca o pagamento como reembolsado
-func (p *PaymentHistory) Refund() {
-	p.Status = PaymentStatusRefunded
+	p.recordPayment(EventPaymentFailed, now, map[string]string{"reason": reason, "code": code})
 }
@@ -2,7 +2,7 @@
 package domain
 
 import (
-	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -25,7 +25,13 @@ type SubscriptionPlan struct {
 	MaxLocations  int  `json:"max_locations"`
 
 	// Features (JSON array)
-	Features json.RawMessage `json:"features"`
+	Features FeatureSet `json:"features"`
+
+	// Trial (nil = DefaultTrialDays)
+	TrialDays *int `json:"trial_days,omitempty"`
+
+	// Dunning (nil = DefaultDunningPolicy)
+	DunningPolicy *DunningPolicy `json:"dunning_policy,omitempty"`
 
 	// Status
 	IsActive bool `json:"is_active"`
@@ -57,21 +63,42 @@ func (p *SubscriptionPlan) HasYearlyOption() bool {
 	return p.PriceYearly != nil
 }
 
+// PriceFor retorna o preço (centavos) do plano para o intervalo de cobrança
+func (p *SubscriptionPlan) PriceFor(interval BillingInterval) (int, error) {
+	switch interval {
+	case BillingIntervalMonthly:
+		return p.PriceMonthly, nil
+	case BillingIntervalYearly:
+		if p.PriceYearly == nil {
+			return 0, fmt.Errorf("%w: %s %s", ErrIntervalUnavailable, p.Slug, interval)
+		}
+		return *p.PriceYearly, nil
+	}
+	return 0, fmt.Errorf("%w: %s %s", ErrIntervalUnavailable, p.Slug, interval)
+}
+
+// EffectiveDunningPolicy retorna a régua de cobrança do plano ou a padrão
+func (p *SubscriptionPlan) EffectiveDunningPolicy() DunningPolicy {
+	if p.DunningPolicy != nil {
+		return *p.DunningPolicy
+	}
+	return DefaultDunningPolicy()
+}
+
 // IsUnlimitedStudents verifica se o plano tem alunos ilimitados
 func (p *SubscriptionPlan) IsUnlimitedStudents() bool {
 	return p.MaxStudents == nil
 }
 
-// NewSubscriptionPlan cria um novo plano com valores padrão
-func NewSubscriptionPlan(name, slug string, priceMonthlyInCents int) *SubscriptionPlan {
-	now := time.Now()
+// NewSubscriptionPlan cria um novo plano com valores padrão, criado em now
+func NewSubscriptionPlan(name, slug string, priceMonthlyInCents int, now time.Time) *SubscriptionPlan {
 	return &SubscriptionPlan{
 		Name:         name,
 		Slug:         slug,
 		PriceMonthly: priceMonthlyInCents,
 		Currency:     "BRL",
 		MaxLocations: 1,
-		Features:     json.RawMessage("[]"),
+		Features:     FeatureSet{},
 		IsActive:     true,
 		CreatedAt:    now,
 		UpdatedAt:    now,
@@ -0,0 +1,29 @@
+package domain
+
+// Role é o papel do usuário na academia (profiles.role)
+type Role string
+
+const (
+	RoleOwner     Role = "owner"     // Dono: cadastro da academia e cobrança
+	RoleProfessor Role = "professor" // Professor da academia
+	RoleStudent   Role = "student"   // Aluno da academia
+)
+
+// IsValid indica se o papel é conhecido
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleOwner, RoleProfessor, RoleStudent:
+		return true
+	}
+	return false
+}
+
+// Profile é o perfil do usuário do Supabase Auth
+// Alinhado com tabela SQL: public.profiles
+type Profile struct {
+	ID        string `json:"id"` // auth.users.id (sub do JWT)
+	Email     string `json:"email"`
+	FullName  string `json:"full_name,omitempty"`
+	Role      Role   `json:"role"`
+	AcademyID string `json:"academy_id,omitempty"` // Vazio até entrar (ou criar) uma academia
+}
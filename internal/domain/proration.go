@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlanChangeTiming indica quando uma troca de plano entra em vigor
+type PlanChangeTiming string
+
+const (
+	PlanChangeImmediate   PlanChangeTiming = "immediate"  // Upgrade: vale agora, diferença cobrada pró-rata
+	PlanChangeAtPeriodEnd PlanChangeTiming = "period_end" // Downgrade: vale na próxima renovação, sem crédito
+)
+
+// ScheduledPlanChange é uma troca de plano agendada para o fim do período
+type ScheduledPlanChange struct {
+	PlanID      string          `json:"plan_id"`
+	Interval    BillingInterval `json:"interval"`
+	EffectiveAt time.Time       `json:"effective_at"`
+}
+
+// PendingPlanChange é um upgrade que só entra em vigor quando a cobrança da
+// diferença é paga; se ela falhar ou vencer, o plano atual continua
+type PendingPlanChange struct {
+	Quote     PlanChangeQuote `json:"quote"`
+	TxID      string          `json:"txid"`       // Cobrança PIX da diferença
+	ExpiresAt time.Time       `json:"expires_at"` // Vencimento da cobrança da diferença
+}
+
+// PlanChangeQuote detalha os valores de uma troca de plano antes da confirmação (centavos)
+type PlanChangeQuote struct {
+	FromPlanID   string           `json:"from_plan_id"`
+	ToPlanID     string           `json:"to_plan_id"`
+	FromInterval BillingInterval  `json:"from_interval"`
+	ToInterval   BillingInterval  `json:"to_interval"`
+	Timing       PlanChangeTiming `json:"timing"`
+	EffectiveAt  time.Time        `json:"effective_at"`
+
+	// Período coberto pela cobrança pró-rata
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+
+	Credit        int `json:"credit"`         // Parte não utilizada do plano atual
+	ProratedPrice int `json:"prorated_price"` // Preço do novo plano no restante do período
+	AmountDue     int `json:"amount_due"`     // ProratedPrice - Credit (nunca negativo)
+	NextAmount    int `json:"next_amount"`    // Valor das próximas renovações
+}
+
+// QuotePlanChange calcula a troca da assinatura de from para to no intervalo informado.
+//
+// Regras:
+//   - trialing: troca imediata, sem cobrança (nada foi pago ainda); o fim do
+//     trial é mantido, sem reiniciar nem adotar o trial do novo plano;
+//   - active, novo valor maior no restante do período: upgrade imediato, cobra
+//     a diferença entre o pró-rata do novo plano e o crédito do atual;
+//   - active, valor igual ou menor: downgrade agendado para o fim do período,
+//     sem crédito (o plano atual já pago vale até lá).
+//
+// Mudança de intervalo em upgrade inicia um novo período em now.
+func QuotePlanChange(sub *Subscription, from, to *SubscriptionPlan, interval BillingInterval, now time.Time) (*PlanChangeQuote, error) {
+	if interval == "" {
+		interval = sub.BillingInterval
+	}
+	if interval == "" {
+		interval = BillingIntervalMonthly
+	}
+	if !interval.IsValid() {
+		return nil, fmt.Errorf("%w: intervalo de cobrança inválido: %q", ErrValidation, interval)
+	}
+	if !to.IsActive {
+		return nil, fmt.Errorf("%w: plano %s não está disponível", ErrPlanChangeNotAllowed, to.Slug)
+	}
+
+	currentInterval := sub.BillingInterval
+	if currentInterval == "" {
+		currentInterval = BillingIntervalMonthly
+	}
+	if from.ID == to.ID && currentInterval == interval {
+		return nil, fmt.Errorf("%w: assinatura já está no plano %s", ErrPlanChangeNotAllowed, to.Slug)
+	}
+
+	newPrice, err := to.PriceFor(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &PlanChangeQuote{
+		FromPlanID:   from.ID,
+		ToPlanID:     to.ID,
+		FromInterval: currentInterval,
+		ToInterval:   interval,
+		Timing:       PlanChangeImmediate,
+		EffectiveAt:  now,
+		NextAmount:   newPrice,
+	}
+
+	switch sub.Status {
+	case SubscriptionStatusTrialing:
+		return quote, nil
+	case SubscriptionStatusActive:
+	default:
+		return nil, fmt.Errorf("%w: assinatura em %s", ErrPlanChangeNotAllowed, sub.Status)
+	}
+
+	if sub.CurrentPeriodStart == nil || sub.CurrentPeriodEnd == nil {
+		return nil, fmt.Errorf("assinatura sem período atual")
+	}
+	oldPrice, err := from.PriceFor(currentInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := *sub.CurrentPeriodStart, *sub.CurrentPeriodEnd
+	quote.Credit = prorate(oldPrice, start, end, now)
+
+	if interval == currentInterval {
+		quote.PeriodStart, quote.PeriodEnd = now, end
+		quote.ProratedPrice = prorate(newPrice, start, end, now)
+	} else {
+		// Novo intervalo: o novo plano é cobrado por um período inteiro a partir de agora
+		quote.PeriodStart = now
+		quote.PeriodEnd = NextBillingDate(now, interval, now.In(BillingLocation).Day())
+		quote.ProratedPrice = newPrice
+	}
+
+	if quote.ProratedPrice > quote.Credit {
+		quote.AmountDue = quote.ProratedPrice - quote.Credit
+		return quote, nil
+	}
+
+	// Downgrade: nada a cobrar nem creditar, vale na renovação
+	quote.Timing = PlanChangeAtPeriodEnd
+	quote.EffectiveAt = end
+	quote.PeriodStart, quote.PeriodEnd = end, NextBillingDate(end, interval, sub.BillingAnchorDay)
+	quote.Credit, quote.ProratedPrice = 0, 0
+	return quote, nil
+}
+
+// prorate retorna a fração de price correspondente ao tempo restante de [start, end) em now
+func prorate(price int, start, end, now time.Time) int {
+	total := end.Sub(start)
+	if total <= 0 {
+		return 0
+	}
+	remaining := end.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	if remaining > total {
+		remaining = total
+	}
+	return int(int64(price) * int64(remaining/time.Second) / int64(total/time.Second))
+}
+
+// ApplyPlanChange aplica uma troca imediata calculada por QuotePlanChange
+func (s *Subscription) ApplyPlanChange(quote *PlanChangeQuote, now time.Time) error {
+	if quote.Timing != PlanChangeImmediate {
+		return fmt.Errorf("troca agendada deve usar SchedulePlanChange")
+	}
+
+	s.PlanID = quote.ToPlanID
+	s.ScheduledPlanChange = nil
+	if quote.ToInterval != s.BillingInterval && s.Status == SubscriptionStatusActive {
+		start, end := quote.PeriodStart, quote.PeriodEnd
+		s.CurrentPeriodStart = &start
+		s.CurrentPeriodEnd = &end
+		s.BillingAnchorDay = start.In(BillingLocation).Day()
+	}
+	s.BillingInterval = quote.ToInterval
+	s.UpdatedAt = now
+	return nil
+}
+
+// SchedulePlanChange agenda a troca para a próxima renovação (substitui agendamentos anteriores)
+func (s *Subscription) SchedulePlanChange(quote *PlanChangeQuote, now time.Time) error {
+	if quote.Timing != PlanChangeAtPeriodEnd {
+		return fmt.Errorf("troca imediata deve usar ApplyPlanChange")
+	}
+
+	s.ScheduledPlanChange = &ScheduledPlanChange{
+		PlanID:      quote.ToPlanID,
+		Interval:    quote.ToInterval,
+		EffectiveAt: quote.EffectiveAt,
+	}
+	s.UpdatedAt = now
+	return nil
+}
+
+// AwaitingPlanChange indica se em now há um upgrade aguardando o pagamento da diferença
+func (s *Subscription) AwaitingPlanChange(now time.Time) bool {
+	return s.PendingPlanChange != nil && now.Before(s.PendingPlanChange.ExpiresAt)
+}
+
+// IsPendingPlanChangeCharge indica se txid é a cobrança da diferença do upgrade pendente
+func (s *Subscription) IsPendingPlanChangeCharge(txid string) bool {
+	return s.PendingPlanChange != nil && txid != "" && s.PendingPlanChange.TxID == txid
+}
+
+// HoldPlanChange guarda um upgrade até o pagamento da cobrança txid da
+// diferença, que vence em expiresAt. Um upgrade pendente vencido é substituído.
+func (s *Subscription) HoldPlanChange(quote *PlanChangeQuote, txid string, expiresAt, now time.Time) error {
+	if quote.Timing != PlanChangeImmediate {
+		return fmt.Errorf("troca agendada deve usar SchedulePlanChange")
+	}
+	if s.AwaitingPlanChange(now) {
+		return fmt.Errorf("%w: upgrade aguardando pagamento da diferença", ErrPlanChangeNotAllowed)
+	}
+
+	s.PendingPlanChange = &PendingPlanChange{Quote: *quote, TxID: txid, ExpiresAt: expiresAt}
+	s.UpdatedAt = now
+	return nil
+}
+
+// CompletePendingPlanChange aplica o upgrade cuja diferença foi paga na
+// cobrança txid. Retorna false se a cobrança não é a do upgrade pendente.
+func (s *Subscription) CompletePendingPlanChange(txid string, now time.Time) (bool, error) {
+	if !s.IsPendingPlanChangeCharge(txid) {
+		return false, nil
+	}
+	quote := s.PendingPlanChange.Quote
+	if err := s.ApplyPlanChange(&quote, now); err != nil {
+		return false, err
+	}
+	s.PendingPlanChange = nil
+	return true, nil
+}
+
+// DropPendingPlanChange descarta o upgrade cuja cobrança txid falhou ou
+// venceu. Retorna false se a cobrança não é a do upgrade pendente.
+func (s *Subscription) DropPendingPlanChange(txid string, now time.Time) bool {
+	if !s.IsPendingPlanChangeCharge(txid) {
+		return false
+	}
+	s.PendingPlanChange = nil
+	s.UpdatedAt = now
+	return true
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuotePlanChange(t *testing.T) {
+	starter := &SubscriptionPlan{ID: "starter", Slug: "starter", PriceMonthly: 9900, IsActive: true}
+	pro := &SubscriptionPlan{ID: "pro", Slug: "pro", PriceMonthly: 19900, IsActive: true}
+	yearly := 199000
+	proYearly := &SubscriptionPlan{ID: "pro", Slug: "pro", PriceMonthly: 19900, PriceYearly: &yearly, IsActive: true}
+
+	// Período de 30 dias (01/04 → 01/05), troca no meio (16/04)
+	start, end := spDate(2026, 4, 1), spDate(2026, 5, 1)
+	now := spDate(2026, 4, 16)
+	active := func() *Subscription {
+		return &Subscription{
+			Status:             SubscriptionStatusActive,
+			BillingInterval:    BillingIntervalMonthly,
+			BillingAnchorDay:   1,
+			CurrentPeriodStart: &start,
+			CurrentPeriodEnd:   &end,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sub        *Subscription
+		from, to   *SubscriptionPlan
+		interval   BillingInterval
+		wantTiming PlanChangeTiming
+		wantCredit int
+		wantDue    int
+		wantErr    error
+	}{
+		{"upgrade mid period", active(), starter, pro, "", PlanChangeImmediate, 4950, 5000, nil},
+		{"downgrade at period end", active(), pro, starter, "", PlanChangeAtPeriodEnd, 0, 0, nil},
+		{"upgrade to yearly starts new period", active(), starter, proYearly, BillingIntervalYearly, PlanChangeImmediate, 4950, 199000 - 4950, nil},
+		{"trial changes without charge", &Subscription{Status: SubscriptionStatusTrialing}, starter, pro, "", PlanChangeImmediate, 0, 0, nil},
+		{"same plan", active(), pro, pro, "", "", 0, 0, ErrPlanChangeNotAllowed},
+		{"past due", &Subscription{Status: SubscriptionStatusPastDue}, starter, pro, "", "", 0, 0, ErrPlanChangeNotAllowed},
+		{"yearly unavailable", active(), starter, pro, BillingIntervalYearly, "", 0, 0, ErrIntervalUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := QuotePlanChange(tt.sub, tt.from, tt.to, tt.interval, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("QuotePlanChange() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("QuotePlanChange() error = %v", err)
+			}
+			if quote.Timing != tt.wantTiming || quote.Credit != tt.wantCredit || quote.AmountDue != tt.wantDue {
+				t.Errorf("quote = %s credit %d due %d, want %s credit %d due %d",
+					quote.Timing, quote.Credit, quote.AmountDue, tt.wantTiming, tt.wantCredit, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestSubscription_ScheduledPlanChangeAppliesOnRenew(t *testing.T) {
+	starter := &SubscriptionPlan{ID: "starter", Slug: "starter", PriceMonthly: 9900, IsActive: true}
+	pro := &SubscriptionPlan{ID: "pro", Slug: "pro", PriceMonthly: 19900, IsActive: true}
+
+	start, end := spDate(2026, 4, 1), spDate(2026, 5, 1)
+	sub := &Subscription{
+		PlanID:             pro.ID,
+		Status:             SubscriptionStatusActive,
+		BillingInterval:    BillingIntervalMonthly,
+		BillingAnchorDay:   1,
+		CurrentPeriodStart: &start,
+		CurrentPeriodEnd:   &end,
+	}
+
+	now := spDate(2026, 4, 10)
+	quote, err := QuotePlanChange(sub, pro, starter, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SchedulePlanChange(quote, now); err != nil {
+		t.Fatal(err)
+	}
+	if sub.PlanID != pro.ID {
+		t.Fatalf("PlanID = %s, want pro até a renovação", sub.PlanID)
+	}
+
+	if err := sub.Renew(BillingIntervalMonthly, quote.EffectiveAt); err != nil {
+		t.Fatal(err)
+	}
+	if sub.PlanID != starter.ID || sub.ScheduledPlanChange != nil {
+		t.Errorf("PlanID = %s, Scheduled = %+v, want starter aplicado", sub.PlanID, sub.ScheduledPlanChange)
+	}
+}
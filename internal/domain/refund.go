@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RefundStatus representa o estado de uma devolução
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"   // Solicitada ao gateway, aguardando liquidação
+	RefundStatusSucceeded RefundStatus = "succeeded" // Valor devolvido ao pagador
+	RefundStatusFailed    RefundStatus = "failed"    // Não realizada; o valor volta a ser reembolsável
+)
+
+// PaymentRefund registra uma devolução (total ou parcial) de um pagamento
+// Alinhado com tabela SQL: public.payment_refunds
+type PaymentRefund struct {
+	ID        string `json:"id"` // Enviado ao gateway como ID da devolução (Efí: até 35 alfanuméricos)
+	PaymentID string `json:"payment_id"`
+	AcademyID string `json:"academy_id"`
+
+	Amount int          `json:"amount"` // centavos
+	Reason string       `json:"reason"`
+	Status RefundStatus `json:"status"`
+
+	GatewayRefundID *string `json:"gateway_refund_id,omitempty"` // Efí: rtrId (e2e da devolução); Stripe: re_xxx
+	FailureReason   *string `json:"failure_reason,omitempty"`
+
+	RequestedBy string     `json:"requested_by"` // User ID, ActorSystem ou ActorWebhook
+	RequestedAt time.Time  `json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// IsRefundable indica se o pagamento aceita novas devoluções
+func (p *PaymentHistory) IsRefundable() bool {
+	return p.Status == PaymentStatusSucceeded || p.Status == PaymentStatusPartiallyRefunded
+}
+
+// RefundableAmount é o valor que ainda pode ser devolvido: devoluções
+// pendentes reservam o valor até o gateway confirmar ou recusar
+func (p *PaymentHistory) RefundableAmount() int {
+	reserved := 0
+	for _, r := range p.Refunds {
+		if r.Status != RefundStatusFailed {
+			reserved += r.Amount
+		}
+	}
+	return p.Amount - reserved
+}
+
+// RequestRefund registra uma devolução pendente de amount centavos.
+// O ID gerado deve ser enviado ao gateway para conciliar o webhook.
+func (p *PaymentHistory) RequestRefund(amount int, reason, requestedBy string, now time.Time) (*PaymentRefund, error) {
+	if !p.IsRefundable() {
+		return nil, fmt.Errorf("%w: pagamento com status %s", ErrRefundNotAllowed, p.Status)
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: valor deve ser positivo", ErrRefundNotAllowed)
+	}
+	if available := p.RefundableAmount(); amount > available {
+		return nil, fmt.Errorf("%w: valor %d excede o disponível (%d)", ErrRefundNotAllowed, amount, available)
+	}
+
+	refund := &PaymentRefund{
+		ID:          newRefundID(),
+		PaymentID:   p.ID,
+		AcademyID:   p.AcademyID,
+		Amount:      amount,
+		Reason:      reason,
+		Status:      RefundStatusPending,
+		RequestedBy: requestedBy,
+		RequestedAt: now,
+	}
+	p.Refunds = append(p.Refunds, refund)
+	return refund, nil
+}
+
+// ConfirmRefund registra a devolução liquidada pelo gateway. O gateway é a
+// fonte da verdade: o valor informado prevalece, uma devolução marcada como
+// falha volta a valer e uma devolução desconhecida (feita no painel do
+// gateway ou por MED) é criada. Confirmações repetidas são ignoradas.
+func (p *PaymentHistory) ConfirmRefund(refundID, gatewayRefundID string, amount int, now time.Time) error {
+	refund := p.findRefund(refundID)
+	if refund != nil && refund.Status == RefundStatusSucceeded {
+		return nil
+	}
+	if p.Status != PaymentStatusRefunded && !p.IsRefundable() {
+		return fmt.Errorf("%w: pagamento com status %s", ErrRefundNotAllowed, p.Status)
+	}
+
+	if amount <= 0 {
+		if refund == nil {
+			return fmt.Errorf("%w: devolução %s sem valor", ErrRefundNotAllowed, refundID)
+		}
+		amount = refund.Amount
+	}
+	if total := p.refundedTotal() + amount; total > p.Amount {
+		return fmt.Errorf("%w: devoluções somariam %d, acima do pagamento (%d)", ErrRefundNotAllowed, total, p.Amount)
+	}
+
+	if refund == nil {
+		refund = &PaymentRefund{
+			ID:          refundID,
+			PaymentID:   p.ID,
+			AcademyID:   p.AcademyID,
+			Reason:      "Devolução registrada pelo gateway",
+			RequestedBy: ActorWebhook,
+			RequestedAt: now,
+		}
+		p.Refunds = append(p.Refunds, refund)
+	}
+	refund.Amount = amount
+	refund.Status = RefundStatusSucceeded
+	refund.FailureReason = nil
+	refund.CompletedAt = &now
+	if gatewayRefundID != "" {
+		refund.GatewayRefundID = &gatewayRefundID
+	}
+
+	p.applyRefunds()
+	p.recordPayment(EventPaymentRefunded, now, map[string]string{
+		"refund_id":       refund.ID,
+		"refund_amount":   strconv.Itoa(refund.Amount),
+		"refunded_amount": strconv.Itoa(p.RefundedAmount),
+		"status":          string(p.Status),
+	})
+	return nil
+}
+
+// FailRefund registra uma devolução recusada pelo gateway, liberando o valor
+// para uma nova tentativa. Devoluções desconhecidas ou já resolvidas são ignoradas.
+func (p *PaymentHistory) FailRefund(refundID, reason string, now time.Time) {
+	refund := p.findRefund(refundID)
+	if refund == nil || refund.Status != RefundStatusPending {
+		return
+	}
+	refund.Status = RefundStatusFailed
+	refund.FailureReason = &reason
+	refund.CompletedAt = &now
+}
+
+// findRefund busca uma devolução do pagamento pelo ID
+func (p *PaymentHistory) findRefund(refundID string) *PaymentRefund {
+	for _, r := range p.Refunds {
+		if r.ID == refundID {
+			return r
+		}
+	}
+	return nil
+}
+
+// refundedTotal soma as devoluções liquidadas
+func (p *PaymentHistory) refundedTotal() int {
+	total := 0
+	for _, r := range p.Refunds {
+		if r.Status == RefundStatusSucceeded {
+			total += r.Amount
+		}
+	}
+	return total
+}
+
+// applyRefunds deriva RefundedAmount e o status do pagamento das devoluções liquidadas
+func (p *PaymentHistory) applyRefunds() {
+	p.RefundedAmount = p.refundedTotal()
+	switch {
+	case p.RefundedAmount >= p.Amount:
+		p.Status = PaymentStatusRefunded
+	case p.RefundedAmount > 0:
+		p.Status = PaymentStatusPartiallyRefunded
+	}
+}
+
+// newRefundID gera o ID da devolução: alfanumérico, aceito pela Efí e pela Stripe
+func newRefundID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return "dev" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func paidPayment(amount int) *PaymentHistory {
+	p := &PaymentHistory{ID: "payment-1", AcademyID: "academy-1", Amount: amount, PaymentGateway: PaymentGatewayPixAuto}
+	p.Succeed(spDate(2026, 6, 1))
+	return p
+}
+
+func TestPaymentHistory_PartialRefunds(t *testing.T) {
+	now := spDate(2026, 6, 10)
+	p := paidPayment(9900)
+
+	first, err := p.RequestRefund(3000, "Cobrança em duplicidade", "user-1", now)
+	if err != nil {
+		t.Fatalf("RequestRefund() error = %v", err)
+	}
+	if len(first.ID) > 35 || first.Status != RefundStatusPending {
+		t.Errorf("refund = %+v", first)
+	}
+	// Pendente reserva o valor, mas o status só muda com a liquidação
+	if p.RefundableAmount() != 6900 || p.Status != PaymentStatusSucceeded {
+		t.Errorf("refundable = %d, status = %s", p.RefundableAmount(), p.Status)
+	}
+	if _, err := p.RequestRefund(7000, "", "user-1", now); !errors.Is(err, ErrRefundNotAllowed) {
+		t.Errorf("RequestRefund(acima do disponível) error = %v, want ErrRefundNotAllowed", err)
+	}
+
+	if err := p.ConfirmRefund(first.ID, "D1", 0, now); err != nil {
+		t.Fatalf("ConfirmRefund() error = %v", err)
+	}
+	if p.Status != PaymentStatusPartiallyRefunded || p.RefundedAmount != 3000 {
+		t.Errorf("status = %s, refunded = %d, want partially_refunded 3000", p.Status, p.RefundedAmount)
+	}
+
+	second, err := p.RequestRefund(6900, "Cancelamento", "user-1", now)
+	if err != nil {
+		t.Fatalf("RequestRefund(restante) error = %v", err)
+	}
+	if err := p.ConfirmRefund(second.ID, "D2", 6900, now); err != nil {
+		t.Fatalf("ConfirmRefund() error = %v", err)
+	}
+	if p.Status != PaymentStatusRefunded || p.RefundedAmount != 9900 || p.RefundableAmount() != 0 {
+		t.Errorf("status = %s, refunded = %d, want refunded 9900", p.Status, p.RefundedAmount)
+	}
+
+	// Webhook repetido não gera outro evento
+	if err := p.ConfirmRefund(second.ID, "D2", 6900, now); err != nil {
+		t.Fatalf("ConfirmRefund(repetido) error = %v", err)
+	}
+	events := p.PullEvents()
+	if len(events) != 3 || events[2].Data["refunded_amount"] != "9900" || events[2].Data["status"] != "refunded" {
+		t.Errorf("eventos = %+v", events)
+	}
+}
+
+func TestPaymentHistory_ConfirmRefund(t *testing.T) {
+	now := spDate(2026, 6, 10)
+
+	tests := []struct {
+		name       string
+		setup      func(p *PaymentHistory) string // Devolve o ID notificado pelo gateway
+		amount     int
+		wantErr    bool
+		wantStatus PaymentStatus
+		wantTotal  int
+	}{
+		{
+			name:       "devolução feita no painel do gateway",
+			setup:      func(p *PaymentHistory) string { return "devPainel" },
+			amount:     1000,
+			wantStatus: PaymentStatusPartiallyRefunded,
+			wantTotal:  1000,
+		},
+		{
+			name: "gateway liquida devolução marcada como falha",
+			setup: func(p *PaymentHistory) string {
+				r, _ := p.RequestRefund(9900, "", "user-1", now)
+				p.FailRefund(r.ID, "timeout", now)
+				return r.ID
+			},
+			wantStatus: PaymentStatusRefunded,
+			wantTotal:  9900,
+		},
+		{
+			name: "valor do gateway prevalece",
+			setup: func(p *PaymentHistory) string {
+				r, _ := p.RequestRefund(5000, "", "user-1", now)
+				return r.ID
+			},
+			amount:     4000,
+			wantStatus: PaymentStatusPartiallyRefunded,
+			wantTotal:  4000,
+		},
+		{
+			name:       "acima do valor pago",
+			setup:      func(p *PaymentHistory) string { return "devPainel" },
+			amount:     9901,
+			wantErr:    true,
+			wantStatus: PaymentStatusSucceeded,
+		},
+		{
+			name:       "desconhecida sem valor",
+			setup:      func(p *PaymentHistory) string { return "devPainel" },
+			wantErr:    true,
+			wantStatus: PaymentStatusSucceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := paidPayment(9900)
+			id := tt.setup(p)
+
+			err := p.ConfirmRefund(id, "D1", tt.amount, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConfirmRefund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p.Status != tt.wantStatus || p.RefundedAmount != tt.wantTotal {
+				t.Errorf("status = %s, refunded = %d, want %s %d", p.Status, p.RefundedAmount, tt.wantStatus, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestPaymentHistory_RequestRefundNotPaid(t *testing.T) {
+	p := &PaymentHistory{ID: "payment-1", Amount: 9900, Status: PaymentStatusPending}
+	if _, err := p.RequestRefund(100, "", "user-1", spDate(2026, 6, 1)); !errors.Is(err, ErrRefundNotAllowed) {
+		t.Errorf("RequestRefund() error = %v, want ErrRefundNotAllowed", err)
+	}
+}
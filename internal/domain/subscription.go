@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -9,11 +10,13 @@ import (
 type SubscriptionStatus string
 
 const (
-	SubscriptionStatusTrialing SubscriptionStatus = "trialing"  // Em período de trial
-	SubscriptionStatusActive   SubscriptionStatus = "active"    // Pagamento em dia
-	SubscriptionStatusPastDue  SubscriptionStatus = "past_due"  // Pagamento atrasado (grace period)
-	SubscriptionStatusCanceled SubscriptionStatus = "canceled"  // Cancelada
-	SubscriptionStatusExpired  SubscriptionStatus = "expired"   // Trial expirado sem conversão
+	SubscriptionStatusTrialing  SubscriptionStatus = "trialing"  // Em período de trial
+	SubscriptionStatusActive    SubscriptionStatus = "active"    // Pagamento em dia
+	SubscriptionStatusPastDue   SubscriptionStatus = "past_due"  // Pagamento atrasado (grace period)
+	SubscriptionStatusCanceled  SubscriptionStatus = "canceled"  // Cancelada
+	SubscriptionStatusExpired   SubscriptionStatus = "expired"   // Trial expirado sem conversão
+	SubscriptionStatusSuspended SubscriptionStatus = "suspended" // Acesso suspenso após o grace period (recuperável com pagamento)
+	SubscriptionStatusPaused    SubscriptionStatus = "paused"    // Pausada pela academia (férias, reforma), sem cobranças
 )
 
 // ValidSubscriptionStatuses lista todos os status válidos
@@ -23,6 +26,8 @@ var ValidSubscriptionStatuses = []SubscriptionStatus{
 	SubscriptionStatusPastDue,
 	SubscriptionStatusCanceled,
 	SubscriptionStatusExpired,
+	SubscriptionStatusSuspended,
+	SubscriptionStatusPaused,
 }
 
 // IsValid verifica se o status é válido
@@ -55,6 +60,9 @@ type Subscription struct {
 	TrialStartDate *time.Time `json:"trial_start_date,omitempty"`
 	TrialEndDate   *time.Time `json:"trial_end_date,omitempty"`
 
+	TrialCampaignCode *string          `json:"trial_campaign_code,omitempty"` // Campanha que definiu o trial
+	TrialExtensions   []TrialExtension `json:"trial_extensions,omitempty"`    // Extensões concedidas por admins
+
 	// Gateway info
 	PaymentGateway *PaymentGateway `json:"payment_gateway,omitempty"`
 
@@ -70,20 +78,54 @@ type Subscription struct {
 	StripePriceID        *string `json:"stripe_price_id,omitempty"`
 
 	// Billing period
-	CurrentPeriodStart *time.Time `json:"current_period_start,omitempty"`
-	CurrentPeriodEnd   *time.Time `json:"current_period_end,omitempty"`
+	BillingInterval    BillingInterval `json:"billing_interval"`
+	BillingAnchorDay   int             `json:"billing_anchor_day"` // Dia do mês da cobrança (1-31, limitado ao fim do mês)
+	CurrentPeriodStart *time.Time      `json:"current_period_start,omitempty"`
+	CurrentPeriodEnd   *time.Time      `json:"current_period_end,omitempty"`
+
+	// Troca de plano agendada para a próxima renovação (downgrade)
+	ScheduledPlanChange *ScheduledPlanChange `json:"scheduled_plan_change,omitempty"`
+
+	// Upgrade aguardando o pagamento da cobrança da diferença
+	PendingPlanChange *PendingPlanChange `json:"pending_plan_change,omitempty"`
+
+	// Diferença pró-rata somada à próxima cobrança do PIX Automático (centavos)
+	PendingProrationAmount int `json:"pending_proration_amount,omitempty"`
+
+	// Pausas (agendada/em andamento é a última sem resumed_at)
+	Pauses []PauseWindow `json:"pauses,omitempty"`
+
+	// Desconto de cupom aplicado às próximas cobranças
+	Discount *SubscriptionDiscount `json:"discount,omitempty"`
 
 	// Cancellation
 	CanceledAt        *time.Time `json:"canceled_at,omitempty"`
 	CancelAtPeriodEnd bool       `json:"cancel_at_period_end"`
 	CancelReason      *string    `json:"cancel_reason,omitempty"`
 
+	// Cancelada, mas a recorrência ainda não foi encerrada no gateway: o job de
+	// cancelamentos tenta de novo até conseguir
+	GatewayCancelPending bool `json:"gateway_cancel_pending,omitempty"`
+
+	// Dunning (cobrança de inadimplentes)
+	DunningNoticesSent int `json:"dunning_notices_sent"` // Avisos da régua já enviados no ciclo atual
+
 	// Metadata
 	Metadata json.RawMessage `json:"metadata,omitempty"`
 
+	// Histórico de transições de status
+	Transitions []SubscriptionTransition `json:"transitions,omitempty"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Version é incrementada a cada gravação; Save de uma cópia desatualizada
+	// falha com ErrConcurrentModification
+	Version int `json:"version"`
+
+	// Eventos de domínio ainda não gravados no outbox
+	events eventRecorder
 }
 
 // IsActive verifica se a assinatura está ativa ou em período de teste
@@ -92,11 +134,11 @@ func (s *Subscription) IsActive() bool {
 }
 
 // IsInTrial verifica se está no período de teste
-func (s *Subscription) IsInTrial() bool {
+func (s *Subscription) IsInTrial(now time.Time) bool {
 	if s.TrialEndDate == nil {
 		return false
 	}
-	return s.Status == SubscriptionStatusTrialing && time.Now().Before(*s.TrialEndDate)
+	return s.Status == SubscriptionStatusTrialing && now.Before(*s.TrialEndDate)
 }
 
 // IsPastDue verifica se o pagamento está atrasado
@@ -105,11 +147,11 @@ func (s *Subscription) IsPastDue() bool {
 }
 
 // DaysUntilExpiration retorna dias até expiração do período atual
-func (s *Subscription) DaysUntilExpiration() int {
+func (s *Subscription) DaysUntilExpiration(now time.Time) int {
 	if s.CurrentPeriodEnd == nil || s.CurrentPeriodEnd.IsZero() {
 		return 0
 	}
-	duration := time.Until(*s.CurrentPeriodEnd)
+	duration := s.CurrentPeriodEnd.Sub(now)
 	if duration < 0 {
 		return 0
 	}
@@ -117,66 +159,188 @@ func (s *Subscription) DaysUntilExpiration() int {
 }
 
 // DaysUntilTrialEnd retorna dias até o fim do trial
-func (s *Subscription) DaysUntilTrialEnd() int {
+func (s *Subscription) DaysUntilTrialEnd(now time.Time) int {
 	if s.TrialEndDate == nil {
 		return 0
 	}
-	duration := time.Until(*s.TrialEndDate)
+	duration := s.TrialEndDate.Sub(now)
 	if duration < 0 {
 		return 0
 	}
 	return int(duration.Hours() / 24)
 }
 
-// NewTrialSubscription cria uma nova assinatura em trial para uma academia
-func NewTrialSubscription(academyID, planID string, trialDays int) *Subscription {
-	now := time.Now()
-	trialEnd := now.AddDate(0, 0, trialDays)
-	periodEnd := now.AddDate(0, 1, 0)
-	return &Subscription{
+// NewTrialSubscription cria uma nova assinatura em trial para uma academia.
+// Durante o trial o período atual é o próprio trial: a primeira cobrança
+// acontece no fim dele (ou antes, em uma conversão antecipada).
+// terms.Start vazio começa o trial em now.
+func NewTrialSubscription(academyID string, terms TrialTerms, now time.Time) *Subscription {
+	start := terms.Start
+	if start.IsZero() {
+		start = now
+	}
+	now = start.In(BillingLocation)
+	trialEnd := now.AddDate(0, 0, terms.Days)
+	sub := &Subscription{
 		AcademyID:          academyID,
-		PlanID:             planID,
+		PlanID:             terms.PlanID,
 		Status:             SubscriptionStatusTrialing,
 		TrialStartDate:     &now,
 		TrialEndDate:       &trialEnd,
+		BillingInterval:    BillingIntervalMonthly,
+		BillingAnchorDay:   trialEnd.Day(),
 		CurrentPeriodStart: &now,
-		CurrentPeriodEnd:   &periodEnd,
+		CurrentPeriodEnd:   &trialEnd,
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
+	if terms.CampaignCode != "" {
+		code := terms.CampaignCode
+		sub.TrialCampaignCode = &code
+	}
+	sub.events.record(EventTrialStarted, AggregateSubscription, "", academyID, now, map[string]string{
+		"plan_id":   terms.PlanID,
+		"trial_end": trialEnd.Format(time.RFC3339),
+	})
+	return sub
 }
 
 // Activate ativa a assinatura definindo o período e gateway
-func (s *Subscription) Activate(gateway PaymentGateway, periodStart, periodEnd time.Time) {
-	s.Status = SubscriptionStatusActive
+func (s *Subscription) Activate(gateway PaymentGateway, periodStart, periodEnd time.Time, actor string, now time.Time) error {
+	if err := s.TransitionTo(SubscriptionStatusActive, "pagamento confirmado", actor, now); err != nil {
+		return err
+	}
 	s.PaymentGateway = &gateway
 	s.CurrentPeriodStart = &periodStart
 	s.CurrentPeriodEnd = &periodEnd
-	s.UpdatedAt = time.Now()
+	if s.BillingInterval == "" {
+		s.BillingInterval = BillingIntervalMonthly
+	}
+	s.BillingAnchorDay = periodStart.In(BillingLocation).Day()
+	return nil
+}
+
+// Renew avança o período de cobrança após o pagamento da renovação.
+// O novo período começa no fim do anterior e termina no dia-âncora do próximo
+// mês (ou ano), calculado em America/Sao_Paulo. Uma assinatura past_due ou
+// suspended que paga a renovação volta para active. Uma troca de plano agendada
+// entra em vigor no novo período (o intervalo agendado prevalece). Uma
+// assinatura com cancelamento agendado não renova: o período termina com
+// CompleteCancel.
+func (s *Subscription) Renew(interval BillingInterval, now time.Time) error {
+	if s.Status != SubscriptionStatusActive && s.Status != SubscriptionStatusPastDue && s.Status != SubscriptionStatusSuspended {
+		return &TransitionError{From: s.Status, To: SubscriptionStatusActive}
+	}
+	if s.CancelAtPeriodEnd {
+		return fmt.Errorf("%w: cancelamento agendado para o fim do período", ErrInvalidTransition)
+	}
+	if !interval.IsValid() {
+		return fmt.Errorf("%w: intervalo de cobrança inválido: %q", ErrValidation, interval)
+	}
+	if s.CurrentPeriodEnd == nil {
+		return fmt.Errorf("assinatura sem período atual para renovar")
+	}
+
+	if s.BillingAnchorDay == 0 {
+		anchorFrom := s.CurrentPeriodEnd
+		if s.CurrentPeriodStart != nil {
+			anchorFrom = s.CurrentPeriodStart
+		}
+		s.BillingAnchorDay = anchorFrom.In(BillingLocation).Day()
+	}
+
+	if s.Status != SubscriptionStatusActive {
+		if err := s.TransitionTo(SubscriptionStatusActive, "renovação paga", ActorSystem, now); err != nil {
+			return err
+		}
+		s.DunningNoticesSent = 0
+	}
+
+	if s.ScheduledPlanChange != nil {
+		s.PlanID = s.ScheduledPlanChange.PlanID
+		interval = s.ScheduledPlanChange.Interval
+		s.ScheduledPlanChange = nil
+	}
+
+	start := s.CurrentPeriodEnd.In(BillingLocation)
+	end := NextBillingDate(start, interval, s.BillingAnchorDay)
+	s.BillingInterval = interval
+	s.CurrentPeriodStart = &start
+	s.CurrentPeriodEnd = &end
+	s.UpdatedAt = now
+	return nil
 }
 
 // MarkPastDue marca a assinatura como pagamento atrasado
-func (s *Subscription) MarkPastDue() {
-	s.Status = SubscriptionStatusPastDue
-	s.UpdatedAt = time.Now()
+func (s *Subscription) MarkPastDue(reason, actor string, now time.Time) error {
+	return s.TransitionTo(SubscriptionStatusPastDue, reason, actor, now)
 }
 
-// Cancel cancela a assinatura
-func (s *Subscription) Cancel(reason string, atPeriodEnd bool) {
-	now := time.Now()
+// Cancel cancela a assinatura.
+// Com atPeriodEnd o status não muda agora, mas o cancelamento precisa ser possível.
+func (s *Subscription) Cancel(reason string, atPeriodEnd bool, actor string, now time.Time) error {
 	if atPeriodEnd {
+		if !CanTransition(s.Status, SubscriptionStatusCanceled) {
+			return &TransitionError{From: s.Status, To: SubscriptionStatusCanceled}
+		}
 		s.CancelAtPeriodEnd = true
 		s.CancelReason = &reason
-	} else {
-		s.Status = SubscriptionStatusCanceled
-		s.CanceledAt = &now
-		s.CancelReason = &reason
+		s.UpdatedAt = now
+		return nil
+	}
+
+	if err := s.TransitionTo(SubscriptionStatusCanceled, reason, actor, now); err != nil {
+		return err
+	}
+	canceledAt := s.UpdatedAt
+	s.CanceledAt = &canceledAt
+	s.CancelAtPeriodEnd = false
+	s.CancelReason = &reason
+	return nil
+}
+
+// UndoCancel desiste de um cancelamento agendado para o fim do período.
+// Só é possível enquanto o período ainda não terminou.
+func (s *Subscription) UndoCancel(now time.Time) error {
+	if !s.CancelAtPeriodEnd || s.Status == SubscriptionStatusCanceled {
+		return fmt.Errorf("%w: assinatura sem cancelamento agendado", ErrInvalidTransition)
 	}
+	if s.CurrentPeriodEnd != nil && !now.Before(*s.CurrentPeriodEnd) {
+		return fmt.Errorf("%w: período encerrado: cancelamento não pode mais ser desfeito", ErrInvalidTransition)
+	}
+	s.CancelAtPeriodEnd = false
+	s.CancelReason = nil
 	s.UpdatedAt = now
+	return nil
+}
+
+// CancellationDue indica se o cancelamento agendado já deve ser efetivado.
+// Uma assinatura pausada tem o período congelado e só cancela após retomar.
+func (s *Subscription) CancellationDue(now time.Time) bool {
+	if !s.CancelAtPeriodEnd || s.CurrentPeriodEnd == nil {
+		return false
+	}
+	if s.Status == SubscriptionStatusCanceled || s.Status == SubscriptionStatusPaused {
+		return false
+	}
+	return !now.Before(*s.CurrentPeriodEnd)
+}
+
+// CompleteCancel efetiva um cancelamento agendado para o fim do período
+func (s *Subscription) CompleteCancel(actor string, now time.Time) error {
+	reason := "cancelamento agendado para o fim do período"
+	if s.CancelReason != nil {
+		reason = *s.CancelReason
+	}
+	return s.Cancel(reason, false, actor, now)
+}
+
+// Suspend suspende o acesso após o fim do grace period sem pagamento
+func (s *Subscription) Suspend(reason, actor string, now time.Time) error {
+	return s.TransitionTo(SubscriptionStatusSuspended, reason, actor, now)
 }
 
 // Expire marca a assinatura como expirada (trial sem conversão)
-func (s *Subscription) Expire() {
-	s.Status = SubscriptionStatusExpired
-	s.UpdatedAt = time.Now()
+func (s *Subscription) Expire(actor string, now time.Time) error {
+	return s.TransitionTo(SubscriptionStatusExpired, "trial expirado sem conversão", actor, now)
 }
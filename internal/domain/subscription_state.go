@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Atores padrão registrados no histórico de transições
+const (
+	ActorSystem  = "system"  // Jobs agendados e regras automáticas
+	ActorWebhook = "webhook" // Eventos recebidos de gateways de pagamento
+)
+
+// subscriptionTransitions define as transições de status permitidas.
+// canceled é terminal: um webhook atrasado não pode reativar uma assinatura cancelada.
+// Não há active → active: a renovação muda o período (Renew), não o status, e
+// um webhook duplicado de pagamento não reinicia o período de uma assinatura ativa.
+var subscriptionTransitions = map[SubscriptionStatus][]SubscriptionStatus{
+	SubscriptionStatusTrialing: {
+		SubscriptionStatusActive,   // Conversão (pagamento confirmado)
+		SubscriptionStatusPastDue,  // Primeira cobrança falhou ao fim do trial
+		SubscriptionStatusCanceled, // Cancelamento durante o trial
+		SubscriptionStatusExpired,  // Trial terminou sem conversão
+	},
+	SubscriptionStatusActive: {
+		SubscriptionStatusPastDue,  // Cobrança da renovação falhou
+		SubscriptionStatusCanceled, // Cancelamento
+		SubscriptionStatusPaused,   // Início de uma pausa
+	},
+	SubscriptionStatusPastDue: {
+		SubscriptionStatusActive,    // Pagamento em atraso recebido
+		SubscriptionStatusCanceled,  // Fim do grace period ou cancelamento
+		SubscriptionStatusSuspended, // Fim do grace period (política "suspend")
+	},
+	SubscriptionStatusSuspended: {
+		SubscriptionStatusActive,   // Pagamento em atraso recebido
+		SubscriptionStatusCanceled, // Encerramento definitivo
+	},
+	SubscriptionStatusPaused: {
+		SubscriptionStatusActive,   // Retomada (automática ou antecipada)
+		SubscriptionStatusCanceled, // Cancelamento durante a pausa
+	},
+	SubscriptionStatusExpired: {
+		SubscriptionStatusActive,   // Conversão tardia após o trial expirar
+		SubscriptionStatusCanceled, // Encerramento definitivo
+	},
+	SubscriptionStatusCanceled: {},
+}
+
+// CanTransition verifica se a transição from → to é permitida
+func CanTransition(from, to SubscriptionStatus) bool {
+	for _, allowed := range subscriptionTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions retorna os status para os quais é possível transitar a partir de from
+func AllowedTransitions(from SubscriptionStatus) []SubscriptionStatus {
+	allowed := subscriptionTransitions[from]
+	result := make([]SubscriptionStatus, len(allowed))
+	copy(result, allowed)
+	return result
+}
+
+// TransitionError detalha uma transição de status rejeitada
+type TransitionError struct {
+	From SubscriptionStatus
+	To   SubscriptionStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("transição de assinatura inválida: %s → %s", e.From, e.To)
+}
+
+// Unwrap permite errors.Is(err, ErrInvalidTransition)
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// SubscriptionTransition registra uma mudança de status da assinatura
+type SubscriptionTransition struct {
+	From   SubscriptionStatus `json:"from"`
+	To     SubscriptionStatus `json:"to"`
+	Reason string             `json:"reason,omitempty"`
+	Actor  string             `json:"actor"` // user id, ActorSystem ou ActorWebhook
+	At     time.Time          `json:"at"`
+}
+
+// TransitionTo muda o status validando a tabela de transições e registra no histórico
+func (s *Subscription) TransitionTo(to SubscriptionStatus, reason, actor string, now time.Time) error {
+	if !CanTransition(s.Status, to) {
+		return &TransitionError{From: s.Status, To: to}
+	}
+
+	s.Transitions = append(s.Transitions, SubscriptionTransition{
+		From:   s.Status,
+		To:     to,
+		Reason: reason,
+		Actor:  actor,
+		At:     now,
+	})
+	s.Status = to
+	s.UpdatedAt = now
+	s.recordTransition(s.Transitions[len(s.Transitions)-1])
+	return nil
+}
@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[[2]SubscriptionStatus]bool{
+		{SubscriptionStatusTrialing, SubscriptionStatusActive}:    true,
+		{SubscriptionStatusTrialing, SubscriptionStatusPastDue}:   true,
+		{SubscriptionStatusTrialing, SubscriptionStatusCanceled}:  true,
+		{SubscriptionStatusTrialing, SubscriptionStatusExpired}:   true,
+		{SubscriptionStatusActive, SubscriptionStatusPastDue}:     true,
+		{SubscriptionStatusActive, SubscriptionStatusCanceled}:    true,
+		{SubscriptionStatusActive, SubscriptionStatusPaused}:      true,
+		{SubscriptionStatusPastDue, SubscriptionStatusActive}:     true,
+		{SubscriptionStatusPastDue, SubscriptionStatusCanceled}:   true,
+		{SubscriptionStatusPastDue, SubscriptionStatusSuspended}:  true,
+		{SubscriptionStatusSuspended, SubscriptionStatusActive}:   true,
+		{SubscriptionStatusSuspended, SubscriptionStatusCanceled}: true,
+		{SubscriptionStatusPaused, SubscriptionStatusActive}:      true,
+		{SubscriptionStatusPaused, SubscriptionStatusCanceled}:    true,
+		{SubscriptionStatusExpired, SubscriptionStatusActive}:     true,
+		{SubscriptionStatusExpired, SubscriptionStatusCanceled}:   true,
+	}
+
+	// Todas as combinações from × to são verificadas
+	for _, from := range ValidSubscriptionStatuses {
+		for _, to := range ValidSubscriptionStatuses {
+			want := allowed[[2]SubscriptionStatus{from, to}]
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				if got := CanTransition(from, to); got != want {
+					t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+				}
+
+				sub := &Subscription{Status: from}
+				err := sub.TransitionTo(to, "teste", ActorSystem, time.Now())
+				if want {
+					if err != nil {
+						t.Fatalf("TransitionTo() error = %v, want nil", err)
+					}
+					if sub.Status != to || len(sub.Transitions) != 1 {
+						t.Errorf("Status = %v, Transitions = %d, want %v and 1", sub.Status, len(sub.Transitions), to)
+					}
+					return
+				}
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Fatalf("TransitionTo() error = %v, want ErrInvalidTransition", err)
+				}
+				if sub.Status != from || len(sub.Transitions) != 0 {
+					t.Errorf("transição rejeitada não deveria alterar a assinatura")
+				}
+			})
+		}
+	}
+}
+
+func TestSubscription_Methods(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name    string
+		from    SubscriptionStatus
+		apply   func(s *Subscription) error
+		want    SubscriptionStatus
+		wantErr bool
+	}{
+		{
+			name:  "activate trial",
+			from:  SubscriptionStatusTrialing,
+			apply: func(s *Subscription) error { return s.Activate(PaymentGatewayPixAuto, start, end, ActorWebhook, start) },
+			want:  SubscriptionStatusActive,
+		},
+		{
+			name:    "activate canceled (webhook atrasado)",
+			from:    SubscriptionStatusCanceled,
+			apply:   func(s *Subscription) error { return s.Activate(PaymentGatewayPixAuto, start, end, ActorWebhook, start) },
+			want:    SubscriptionStatusCanceled,
+			wantErr: true,
+		},
+		{
+			name:  "past due active",
+			from:  SubscriptionStatusActive,
+			apply: func(s *Subscription) error { return s.MarkPastDue("cobrança recusada", ActorWebhook, start) },
+			want:  SubscriptionStatusPastDue,
+		},
+		{
+			name:    "past due expired",
+			from:    SubscriptionStatusExpired,
+			apply:   func(s *Subscription) error { return s.MarkPastDue("cobrança recusada", ActorWebhook, start) },
+			want:    SubscriptionStatusExpired,
+			wantErr: true,
+		},
+		{
+			name:  "cancel active now",
+			from:  SubscriptionStatusActive,
+			apply: func(s *Subscription) error { return s.Cancel("pedido do dono", false, "user-1", start) },
+			want:  SubscriptionStatusCanceled,
+		},
+		{
+			name:  "cancel active at period end",
+			from:  SubscriptionStatusActive,
+			apply: func(s *Subscription) error { return s.Cancel("pedido do dono", true, "user-1", start) },
+			want:  SubscriptionStatusActive,
+		},
+		{
+			name:    "cancel canceled",
+			from:    SubscriptionStatusCanceled,
+			apply:   func(s *Subscription) error { return s.Cancel("de novo", true, "user-1", start) },
+			want:    SubscriptionStatusCanceled,
+			wantErr: true,
+		},
+		{
+			name:  "expire trial",
+			from:  SubscriptionStatusTrialing,
+			apply: func(s *Subscription) error { return s.Expire(ActorSystem, start) },
+			want:  SubscriptionStatusExpired,
+		},
+		{
+			name:    "expire active",
+			from:    SubscriptionStatusActive,
+			apply:   func(s *Subscription) error { return s.Expire(ActorSystem, start) },
+			want:    SubscriptionStatusActive,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{Status: tt.from}
+			err := tt.apply(sub)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sub.Status != tt.want {
+				t.Errorf("Status = %v, want %v", sub.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscription_TransitionHistory(t *testing.T) {
+	start := time.Now()
+	sub := NewTrialSubscription("academy-1", TrialTerms{PlanID: "plan-1", Days: 20}, start)
+
+	if err := sub.Activate(PaymentGatewayPixAuto, start, start.AddDate(0, 1, 0), ActorWebhook, start); err != nil {
+		t.Fatal(err)
+	}
+	// Webhook duplicado não reinicia o período de uma assinatura já ativa
+	periodEnd := *sub.CurrentPeriodEnd
+	later := start.AddDate(0, 0, 10)
+	if err := sub.Activate(PaymentGatewayPixAuto, later, later.AddDate(0, 1, 0), ActorWebhook, later); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Activate() em assinatura ativa error = %v, want ErrInvalidTransition", err)
+	}
+	if !sub.CurrentPeriodEnd.Equal(periodEnd) {
+		t.Errorf("CurrentPeriodEnd = %v, want %v (inalterado)", sub.CurrentPeriodEnd, periodEnd)
+	}
+	if err := sub.MarkPastDue("saldo insuficiente", ActorWebhook, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Cancel("inadimplência", false, "user-1", start); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SubscriptionTransition{
+		{From: SubscriptionStatusTrialing, To: SubscriptionStatusActive, Actor: ActorWebhook},
+		{From: SubscriptionStatusActive, To: SubscriptionStatusPastDue, Reason: "saldo insuficiente", Actor: ActorWebhook},
+		{From: SubscriptionStatusPastDue, To: SubscriptionStatusCanceled, Reason: "inadimplência", Actor: "user-1"},
+	}
+	if len(sub.Transitions) != len(want) {
+		t.Fatalf("Transitions = %d, want %d", len(sub.Transitions), len(want))
+	}
+	for i, w := range want {
+		got := sub.Transitions[i]
+		if got.From != w.From || got.To != w.To || got.Actor != w.Actor {
+			t.Errorf("Transitions[%d] = %+v, want %+v", i, got, w)
+		}
+		if w.Reason != "" && got.Reason != w.Reason {
+			t.Errorf("Transitions[%d].Reason = %q, want %q", i, got.Reason, w.Reason)
+		}
+		if got.At.IsZero() {
+			t.Errorf("Transitions[%d].At não deveria ser zero", i)
+		}
+	}
+	if sub.CanceledAt == nil {
+		t.Error("CanceledAt deveria ser preenchido")
+	}
+}
+
+func TestSubscription_PeriodEndCancellation(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name    string
+		status  SubscriptionStatus
+		now     time.Time
+		wantDue bool
+		undoErr bool
+	}{
+		{"before period end", SubscriptionStatusActive, end.Add(-time.Hour), false, false},
+		{"at period end", SubscriptionStatusActive, end, true, true},
+		{"past due after period end", SubscriptionStatusPastDue, end.AddDate(0, 0, 2), true, true},
+		{"paused keeps period", SubscriptionStatusPaused, end.AddDate(0, 0, 2), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{Status: tt.status, CurrentPeriodStart: &start, CurrentPeriodEnd: &end}
+			if err := sub.Cancel("fechando", true, "user-1", start); err != nil {
+				t.Fatal(err)
+			}
+			if got := sub.CancellationDue(tt.now); got != tt.wantDue {
+				t.Errorf("CancellationDue() = %v, want %v", got, tt.wantDue)
+			}
+			if err := sub.UndoCancel(tt.now); (err != nil) != tt.undoErr {
+				t.Errorf("UndoCancel() error = %v, wantErr %v", err, tt.undoErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultTrialDays é o trial de planos sem configuração própria
+	DefaultTrialDays = 20
+
+	// MaxTrialExtensionDays limita o total de dias concedidos por extensões de trial
+	MaxTrialExtensionDays = 60
+)
+
+// TrialCampaign é uma campanha que concede um trial diferente do padrão do plano
+// Alinhado com tabela SQL: public.trial_campaigns
+type TrialCampaign struct {
+	ID        string     `json:"id"`
+	Code      string     `json:"code"` // Sempre em maiúsculas
+	TrialDays int        `json:"trial_days"`
+	PlanIDs   []string   `json:"plan_ids,omitempty"`   // Vazio = todos os planos
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Prazo para usar o código
+	IsActive  bool       `json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// AppliesTo verifica se a campanha vale para o plano em now
+func (c *TrialCampaign) AppliesTo(planID string, now time.Time) bool {
+	if !c.IsActive || c.TrialDays < 1 {
+		return false
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return false
+	}
+	if len(c.PlanIDs) == 0 {
+		return true
+	}
+	for _, id := range c.PlanIDs {
+		if id == planID {
+			return true
+		}
+	}
+	return false
+}
+
+// TrialTerms são as condições de trial de uma nova assinatura
+type TrialTerms struct {
+	PlanID       string
+	Days         int
+	CampaignCode string    // Vazio = trial padrão do plano
+	Start        time.Time // Zero = agora
+}
+
+// ResolveTrialTerms escolhe a duração do trial: campanha válida para o plano,
+// senão o trial do plano, senão DefaultTrialDays
+func ResolveTrialTerms(plan *SubscriptionPlan, campaign *TrialCampaign, now time.Time) (TrialTerms, error) {
+	terms := TrialTerms{PlanID: plan.ID, Days: DefaultTrialDays, Start: now}
+	if plan.TrialDays != nil {
+		terms.Days = *plan.TrialDays
+	}
+
+	if campaign != nil {
+		if !campaign.AppliesTo(plan.ID, now) {
+			return TrialTerms{}, fmt.Errorf("%w: campanha %s não vale para o plano %s", ErrTrialCampaignInvalid, campaign.Code, plan.Slug)
+		}
+		terms.Days = campaign.TrialDays
+		terms.CampaignCode = campaign.Code
+	}
+	return terms, nil
+}
+
+// TrialExtension registra uma extensão de trial concedida por um admin (auditoria)
+type TrialExtension struct {
+	Days        int       `json:"days"`
+	PreviousEnd time.Time `json:"previous_end"`
+	NewEnd      time.Time `json:"new_end"`
+	Reason      string    `json:"reason"`
+	Actor       string    `json:"actor"`
+	At          time.Time `json:"at"`
+}
+
+// ExtendTrial adia o fim do trial em days dias.
+// O total de extensões é limitado a MaxTrialExtensionDays.
+func (s *Subscription) ExtendTrial(days int, reason, actor string, now time.Time) error {
+	if s.Status != SubscriptionStatusTrialing || s.TrialEndDate == nil {
+		return fmt.Errorf("%w: apenas assinaturas em trial podem ser estendidas (status %s)", ErrInvalidTransition, s.Status)
+	}
+	if days < 1 {
+		return fmt.Errorf("%w: extensão deve ter pelo menos 1 dia", ErrValidation)
+	}
+	if reason == "" {
+		return fmt.Errorf("%w: motivo da extensão é obrigatório", ErrValidation)
+	}
+
+	extended := 0
+	for _, ext := range s.TrialExtensions {
+		extended += ext.Days
+	}
+	if extended+days > MaxTrialExtensionDays {
+		return fmt.Errorf("%w: extensões de trial limitadas a %d dias (já concedidos: %d)", ErrValidation, MaxTrialExtensionDays, extended)
+	}
+
+	previous := *s.TrialEndDate
+	newEnd := previous.AddDate(0, 0, days)
+	s.TrialExtensions = append(s.TrialExtensions, TrialExtension{
+		Days:        days,
+		PreviousEnd: previous,
+		NewEnd:      newEnd,
+		Reason:      reason,
+		Actor:       actor,
+		At:          now,
+	})
+	s.TrialEndDate = &newEnd
+	s.CurrentPeriodEnd = &newEnd
+	s.UpdatedAt = now
+	return nil
+}
+
+// ConvertTrial ativa a assinatura quando a primeira cobrança é paga, também
+// depois que o trial expirou (conversão tardia). O primeiro período começa na
+// conversão (âncora = dia da conversão); se ela acontece antes do fim do trial
+// (conversão antecipada), o trial termina ali.
+func (s *Subscription) ConvertTrial(gateway PaymentGateway, now time.Time, actor string) error {
+	if s.Status != SubscriptionStatusTrialing && s.Status != SubscriptionStatusExpired {
+		return &TransitionError{From: s.Status, To: SubscriptionStatusActive}
+	}
+
+	interval := s.BillingInterval
+	if interval == "" {
+		interval = BillingIntervalMonthly
+	}
+	start := now.In(BillingLocation)
+	end := NextBillingDate(start, interval, start.Day())
+
+	if s.TrialEndDate != nil && start.Before(*s.TrialEndDate) {
+		trialEnd := start
+		s.TrialEndDate = &trialEnd
+	}
+	return s.Activate(gateway, start, end, actor, now)
+}
+
+// FirstChargeDate retorna quando a primeira cobrança deve acontecer:
+// agora em conversões antecipadas (ou trial já encerrado), senão no fim do trial
+func (s *Subscription) FirstChargeDate(early bool, now time.Time) time.Time {
+	if early || s.TrialEndDate == nil || !now.Before(*s.TrialEndDate) {
+		return now
+	}
+	return *s.TrialEndDate
+}
+
+// StartCheckout escolhe plano, intervalo e gateway da primeira cobrança de uma
+// assinatura ainda não paga (trial ou trial expirado). Os IDs do gateway são
+// preenchidos pelo serviço; a ativação vem com a confirmação do pagamento.
+// Um checkout iniciado de novo substitui o anterior.
+func (s *Subscription) StartCheckout(planID string, interval BillingInterval, gateway PaymentGateway, now time.Time) error {
+	if s.Status != SubscriptionStatusTrialing && s.Status != SubscriptionStatusExpired {
+		return &TransitionError{From: s.Status, To: SubscriptionStatusActive}
+	}
+	if !interval.IsValid() {
+		return fmt.Errorf("%w: %q", ErrIntervalUnavailable, interval)
+	}
+
+	s.PlanID = planID
+	s.BillingInterval = interval
+	s.PaymentGateway = &gateway
+	s.PixAuthorizationID, s.PixRecurrenceID = nil, nil
+	s.StripeSubscriptionID, s.StripePriceID = nil, nil
+	s.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveTrialTerms(t *testing.T) {
+	now := spDate(2026, 6, 1)
+	yesterday := now.AddDate(0, 0, -1)
+	thirty := 30
+	plain := &SubscriptionPlan{ID: "starter", Slug: "starter"}
+	custom := &SubscriptionPlan{ID: "pro", Slug: "pro", TrialDays: &thirty}
+
+	tests := []struct {
+		name     string
+		plan     *SubscriptionPlan
+		campaign *TrialCampaign
+		wantDays int
+		wantErr  error
+	}{
+		{"default", plain, nil, DefaultTrialDays, nil},
+		{"plan trial", custom, nil, 30, nil},
+		{"campaign overrides plan", custom, &TrialCampaign{Code: "VERAO", TrialDays: 45, IsActive: true}, 45, nil},
+		{"campaign for other plan", plain, &TrialCampaign{Code: "PRO45", TrialDays: 45, IsActive: true, PlanIDs: []string{"pro"}}, 0, ErrTrialCampaignInvalid},
+		{"expired campaign", plain, &TrialCampaign{Code: "VELHA", TrialDays: 45, IsActive: true, ExpiresAt: &yesterday}, 0, ErrTrialCampaignInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms, err := ResolveTrialTerms(tt.plan, tt.campaign, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || terms.Days != tt.wantDays {
+				t.Fatalf("ResolveTrialTerms() = %d, %v, want %d", terms.Days, err, tt.wantDays)
+			}
+
+			// O período do trial termina junto com o trial
+			sub := NewTrialSubscription("academy-1", terms, now)
+			if !sub.CurrentPeriodEnd.Equal(*sub.TrialEndDate) || !sub.TrialEndDate.Equal(now.AddDate(0, 0, tt.wantDays)) {
+				t.Errorf("TrialEndDate = %v, CurrentPeriodEnd = %v", sub.TrialEndDate, sub.CurrentPeriodEnd)
+			}
+		})
+	}
+}
+
+func TestSubscription_ExtendTrial(t *testing.T) {
+	now := spDate(2026, 6, 1)
+	sub := NewTrialSubscription("academy-1", TrialTerms{PlanID: "pro", Days: 20, Start: now}, now)
+
+	if err := sub.ExtendTrial(10, "onboarding atrasado", "admin-1", now); err != nil {
+		t.Fatalf("ExtendTrial() error = %v", err)
+	}
+	if want := now.AddDate(0, 0, 30); !sub.TrialEndDate.Equal(want) {
+		t.Errorf("TrialEndDate = %v, want %v", sub.TrialEndDate, want)
+	}
+	if len(sub.TrialExtensions) != 1 || sub.TrialExtensions[0].Actor != "admin-1" {
+		t.Errorf("TrialExtensions = %+v, want 1 registro do admin-1", sub.TrialExtensions)
+	}
+
+	if err := sub.ExtendTrial(MaxTrialExtensionDays, "de novo", "admin-1", now); err == nil {
+		t.Error("ExtendTrial() deveria respeitar MaxTrialExtensionDays")
+	}
+	if err := sub.ExtendTrial(5, "", "admin-1", now); err == nil {
+		t.Error("ExtendTrial() deveria exigir motivo")
+	}
+
+	sub.Status = SubscriptionStatusActive
+	if err := sub.ExtendTrial(5, "cortesia", "admin-1", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("ExtendTrial() em active error = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestSubscription_ConvertTrialEarly(t *testing.T) {
+	start := spDate(2026, 6, 1)
+	sub := NewTrialSubscription("academy-1", TrialTerms{PlanID: "pro", Days: 20, Start: start}, start)
+
+	converted := spDate(2026, 6, 8)
+	if err := sub.ConvertTrial(PaymentGatewayPixAuto, converted, ActorWebhook); err != nil {
+		t.Fatalf("ConvertTrial() error = %v", err)
+	}
+	if sub.Status != SubscriptionStatusActive || !sub.TrialEndDate.Equal(converted) {
+		t.Errorf("Status = %v, TrialEndDate = %v, want active e trial encerrado em %v", sub.Status, sub.TrialEndDate, converted)
+	}
+	if !sub.CurrentPeriodStart.Equal(converted) || !sub.CurrentPeriodEnd.Equal(spDate(2026, 7, 8)) || sub.BillingAnchorDay != 8 {
+		t.Errorf("período = %v → %v (âncora %d), want 08/06 → 08/07", sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.BillingAnchorDay)
+	}
+}
+
+func TestSubscription_ConvertTrialLate(t *testing.T) {
+	start := spDate(2026, 6, 1)
+	sub := NewTrialSubscription("academy-1", TrialTerms{PlanID: "pro", Days: 14, Start: start}, start)
+	if err := sub.Expire(ActorSystem, *sub.TrialEndDate); err != nil {
+		t.Fatal(err)
+	}
+
+	converted := spDate(2026, 6, 20)
+	if err := sub.ConvertTrial(PaymentGatewayPixAuto, converted, ActorWebhook); err != nil {
+		t.Fatalf("ConvertTrial() error = %v", err)
+	}
+	if sub.Status != SubscriptionStatusActive || !sub.TrialEndDate.Equal(spDate(2026, 6, 15)) {
+		t.Errorf("Status = %v, TrialEndDate = %v, want active e fim do trial mantido", sub.Status, sub.TrialEndDate)
+	}
+	if !sub.CurrentPeriodStart.Equal(converted) || !sub.CurrentPeriodEnd.Equal(spDate(2026, 7, 20)) {
+		t.Errorf("período = %v → %v, want 20/06 → 20/07", sub.CurrentPeriodStart, sub.CurrentPeriodEnd)
+	}
+
+	sub.Status = SubscriptionStatusCanceled
+	if err := sub.ConvertTrial(PaymentGatewayPixAuto, converted, ActorWebhook); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("ConvertTrial() cancelada error = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestQuotePlanChange_TrialKeepsEndDate(t *testing.T) {
+	start := spDate(2026, 6, 1)
+	thirty := 30
+	starter := &SubscriptionPlan{ID: "starter", Slug: "starter", PriceMonthly: 9900, IsActive: true}
+	pro := &SubscriptionPlan{ID: "pro", Slug: "pro", PriceMonthly: 19900, IsActive: true, TrialDays: &thirty}
+	sub := NewTrialSubscription("academy-1", TrialTerms{PlanID: starter.ID, Days: 20, Start: start}, start)
+	trialEnd := *sub.TrialEndDate
+
+	quote, err := QuotePlanChange(sub, starter, pro, "", spDate(2026, 6, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.ApplyPlanChange(quote, start); err != nil {
+		t.Fatal(err)
+	}
+	if sub.PlanID != pro.ID || !sub.TrialEndDate.Equal(trialEnd) {
+		t.Errorf("PlanID = %s, TrialEndDate = %v, want pro mantendo %v", sub.PlanID, sub.TrialEndDate, trialEnd)
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -14,6 +15,7 @@ const (
 	WebhookStatusProcessed  WebhookStatus = "processed"
 	WebhookStatusFailed     WebhookStatus = "failed"
 	WebhookStatusSkipped    WebhookStatus = "skipped"
+	WebhookStatusDeadLetter WebhookStatus = "dead_letter" // Retentativas esgotadas; só sai por replay manual
 )
 
 // ValidWebhookStatuses lista todos os status válidos
@@ -23,6 +25,7 @@ var ValidWebhookStatuses = []WebhookStatus{
 	WebhookStatusProcessed,
 	WebhookStatusFailed,
 	WebhookStatusSkipped,
+	WebhookStatusDeadLetter,
 }
 
 // IsValid verifica se o status é válido
@@ -38,6 +41,50 @@ func (s WebhookStatus) IsValid() bool {
 // MaxWebhookRetries define o número máximo de tentativas
 const MaxWebhookRetries = 5
 
+// DefaultWebhookLease é o tempo em processing depois do qual o evento é tido
+// como abandonado (worker que caiu ou processo reiniciado no meio)
+const DefaultWebhookLease = 5 * time.Minute
+
+// WebhookBackoff define o intervalo entre retentativas de um webhook falho:
+// Base dobra a cada falha até Max, e Jitter espalha as retentativas (0.2 =
+// até 20% a mais) para que eventos que falharam juntos não voltem juntos
+type WebhookBackoff struct {
+	Base       time.Duration
+	Max        time.Duration
+	MaxRetries int           // Falhas depois das quais o evento vai para a dead-letter
+	Jitter     float64       // Fração aleatória somada ao intervalo (0 = sem jitter)
+	Lease      time.Duration // Tempo máximo em processing antes de o evento ser retomado (0 = DefaultWebhookLease)
+}
+
+// DefaultWebhookBackoff é 1min, 2min, 4min, 8min, 16min e dead-letter na sexta falha
+var DefaultWebhookBackoff = WebhookBackoff{
+	Base:       time.Minute,
+	Max:        time.Hour,
+	MaxRetries: MaxWebhookRetries,
+	Lease:      DefaultWebhookLease,
+}
+
+// LeaseDuration devolve o lease do processamento, com o padrão se não configurado
+func (b WebhookBackoff) LeaseDuration() time.Duration {
+	if b.Lease <= 0 {
+		return DefaultWebhookLease
+	}
+	return b.Lease
+}
+
+// Delay calcula o intervalo até a retentativa depois da falha número retry (1 = primeira).
+// rnd é um valor aleatório em [0, 1) que escala o jitter.
+func (b WebhookBackoff) Delay(retry int, rnd float64) time.Duration {
+	delay := b.Base
+	for i := 1; i < retry && (b.Max <= 0 || delay < b.Max); i++ {
+		delay *= 2
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay + time.Duration(float64(delay)*b.Jitter*rnd)
+}
+
 // WebhookEvent representa um evento de webhook recebido para auditoria
 // Alinhado com tabela SQL: public.webhook_events
 type WebhookEvent struct {
@@ -55,11 +102,12 @@ type WebhookEvent struct {
 	Headers json.RawMessage `json:"headers,omitempty"` // Request headers (para validação)
 
 	// Processing
-	Status       WebhookStatus `json:"status"`
-	ProcessedAt  *time.Time    `json:"processed_at,omitempty"`
-	ErrorMessage *string       `json:"error_message,omitempty"`
-	RetryCount   int           `json:"retry_count"`
-	NextRetryAt  *time.Time    `json:"next_retry_at,omitempty"`
+	Status              WebhookStatus `json:"status"`
+	ProcessingStartedAt *time.Time    `json:"processing_started_at,omitempty"` // Início do processamento em curso (lease)
+	ProcessedAt         *time.Time    `json:"processed_at,omitempty"`
+	ErrorMessage        *string       `json:"error_message,omitempty"`
+	RetryCount          int           `json:"retry_count"`
+	NextRetryAt         *time.Time    `json:"next_retry_at,omitempty"`
 
 	// Timestamps
 	ReceivedAt time.Time `json:"received_at"`
@@ -67,8 +115,7 @@ type WebhookEvent struct {
 }
 
 // NewWebhookEvent cria um novo evento de webhook pendente
-func NewWebhookEvent(gateway, eventID, eventType string, payload, headers json.RawMessage) *WebhookEvent {
-	now := time.Now()
+func NewWebhookEvent(gateway, eventID, eventType string, payload, headers json.RawMessage, now time.Time) *WebhookEvent {
 	return &WebhookEvent{
 		Gateway:    gateway,
 		EventID:    eventID,
@@ -82,46 +129,99 @@ func NewWebhookEvent(gateway, eventID, eventType string, payload, headers json.R
 	}
 }
 
-// MarkProcessing marca o webhook como em processamento
-func (w *WebhookEvent) MarkProcessing() {
+// MarkProcessing marca o webhook como em processamento a partir de now
+func (w *WebhookEvent) MarkProcessing(now time.Time) {
 	w.Status = WebhookStatusProcessing
+	w.ProcessingStartedAt = &now
+}
+
+// IsAbandoned verifica se o evento está em processing há mais que lease: o
+// worker que o reservou caiu (ou o processo reiniciou) antes de gravar o resultado
+func (w *WebhookEvent) IsAbandoned(now time.Time, lease time.Duration) bool {
+	if w.Status != WebhookStatusProcessing || w.ProcessingStartedAt == nil {
+		return false
+	}
+	return !now.Before(w.ProcessingStartedAt.Add(lease))
+}
+
+// Abandon devolve um evento abandonado às retentativas: conta como uma falha
+// (um evento que derruba o worker acaba na dead-letter em vez de voltar sempre)
+func (w *WebhookEvent) Abandon(now time.Time, backoff WebhookBackoff, rnd float64) error {
+	if !w.IsAbandoned(now, backoff.LeaseDuration()) {
+		return fmt.Errorf("%w: webhook %s não está abandonado", ErrInvalidTransition, w.Status)
+	}
+	w.Fail(fmt.Sprintf("processamento abandonado (iniciado em %s)", w.ProcessingStartedAt.Format(time.RFC3339)), now, backoff, rnd)
+	return nil
 }
 
 // MarkProcessed marca o webhook como processado com sucesso
-func (w *WebhookEvent) MarkProcessed() {
-	now := time.Now()
+func (w *WebhookEvent) MarkProcessed(now time.Time) {
 	w.Status = WebhookStatusProcessed
+	w.ProcessingStartedAt = nil
 	w.ProcessedAt = &now
 }
 
-// MarkFailed marca o webhook como falho e agenda retry com backoff exponencial
-func (w *WebhookEvent) MarkFailed(errMsg string) {
-	w.Status = WebhookStatusFailed
+// MarkFailed marca o webhook como falho e agenda retry com DefaultWebhookBackoff
+func (w *WebhookEvent) MarkFailed(errMsg string, now time.Time) {
+	w.Fail(errMsg, now, DefaultWebhookBackoff, 0)
+}
+
+// Fail registra a falha e agenda o retry com o backoff informado (rnd em [0, 1)
+// escala o jitter). Esgotadas as retentativas, o evento vai para a dead-letter.
+func (w *WebhookEvent) Fail(errMsg string, now time.Time, backoff WebhookBackoff, rnd float64) {
 	w.ErrorMessage = &errMsg
+	w.ProcessingStartedAt = nil
 	w.RetryCount++
 
-	if w.RetryCount <= MaxWebhookRetries {
-		// Backoff exponencial: 1min, 2min, 4min, 8min, 16min
-		backoff := time.Duration(1<<uint(w.RetryCount-1)) * time.Minute
-		nextRetry := time.Now().Add(backoff)
-		w.NextRetryAt = &nextRetry
+	if w.RetryCount > backoff.MaxRetries {
+		w.Status = WebhookStatusDeadLetter
+		w.NextRetryAt = nil
+		return
+	}
+	w.Status = WebhookStatusFailed
+	nextRetry := now.Add(backoff.Delay(w.RetryCount, rnd))
+	w.NextRetryAt = &nextRetry
+}
+
+// EditPayload corrige o payload de um evento na dead-letter antes do replay
+func (w *WebhookEvent) EditPayload(payload json.RawMessage) error {
+	if w.Status != WebhookStatusDeadLetter {
+		return fmt.Errorf("%w: só eventos na dead-letter podem ser editados (status %s)", ErrInvalidTransition, w.Status)
+	}
+	if !json.Valid(payload) {
+		return fmt.Errorf("%w: payload não é JSON válido", ErrValidation)
+	}
+	w.Payload = payload
+	return nil
+}
+
+// Replay devolve à fila um evento na dead-letter (ou falho), zerando as retentativas
+func (w *WebhookEvent) Replay() error {
+	if w.Status != WebhookStatusDeadLetter && w.Status != WebhookStatusFailed {
+		return fmt.Errorf("%w: replay de webhook %s", ErrInvalidTransition, w.Status)
 	}
+	w.Status = WebhookStatusPending
+	w.RetryCount = 0
+	w.NextRetryAt = nil
+	w.ErrorMessage = nil
+	return nil
 }
 
 // MarkSkipped marca o webhook como pulado (evento duplicado ou irrelevante)
 func (w *WebhookEvent) MarkSkipped() {
 	w.Status = WebhookStatusSkipped
+	w.ProcessingStartedAt = nil
 }
 
 // CanRetry verifica se o webhook pode ser retentado
 func (w *WebhookEvent) CanRetry() bool {
-	return w.Status == WebhookStatusFailed && w.RetryCount <= MaxWebhookRetries
+	return w.Status == WebhookStatusFailed && w.NextRetryAt != nil
 }
 
 // IsRetryDue verifica se já é hora de retentar
-func (w *WebhookEvent) IsRetryDue() bool {
+func (w *WebhookEvent) IsRetryDue(now time.Time) bool {
 	if !w.CanRetry() || w.NextRetryAt == nil {
 		return false
 	}
-	return time.Now().After(*w.NextRetryAt)
+	return !now.Before(*w.NextRetryAt)
 }
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoff_Delay(t *testing.T) {
+	backoff := WebhookBackoff{Base: time.Minute, Max: 10 * time.Minute, MaxRetries: 5, Jitter: 0.5}
+
+	tests := []struct {
+		name  string
+		retry int
+		rnd   float64
+		want  time.Duration
+	}{
+		{"primeira falha", 1, 0, time.Minute},
+		{"dobra", 3, 0, 4 * time.Minute},
+		{"limitado ao máximo", 6, 0, 10 * time.Minute},
+		{"jitter", 2, 0.5, 2*time.Minute + 30*time.Second},
+		{"jitter sobre o máximo", 10, 0.99, 10*time.Minute + time.Duration(float64(10*time.Minute)*0.5*0.99)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoff.Delay(tt.retry, tt.rnd); got != tt.want {
+				t.Errorf("Delay(%d, %v) = %v, want %v", tt.retry, tt.rnd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookEvent_FailToDeadLetter(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	backoff := WebhookBackoff{Base: time.Minute, Max: time.Hour, MaxRetries: 2}
+	event := NewWebhookEvent("pix_auto", "E1", "pix", json.RawMessage(`{}`), nil, now)
+
+	event.Fail("timeout", now, backoff, 0)
+	if event.Status != WebhookStatusFailed || !event.NextRetryAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("primeira falha: status = %s, next = %v", event.Status, event.NextRetryAt)
+	}
+	if event.IsRetryDue(now) || !event.IsRetryDue(now.Add(time.Minute)) {
+		t.Error("retry deveria vencer só depois do backoff")
+	}
+
+	event.Fail("timeout", now, backoff, 0)
+	if event.Status != WebhookStatusFailed || !event.NextRetryAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("segunda falha: status = %s, next = %v", event.Status, event.NextRetryAt)
+	}
+
+	event.Fail("timeout", now, backoff, 0)
+	if event.Status != WebhookStatusDeadLetter || event.NextRetryAt != nil || event.CanRetry() {
+		t.Fatalf("esgotado: status = %s, next = %v", event.Status, event.NextRetryAt)
+	}
+	if event.RetryCount != 3 || event.ErrorMessage == nil || *event.ErrorMessage != "timeout" {
+		t.Errorf("retry_count = %d, error = %v", event.RetryCount, event.ErrorMessage)
+	}
+}
+
+func TestWebhookEvent_EditAndReplay(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   WebhookStatus
+		editErr  bool
+		replayOK bool
+	}{
+		{"dead-letter", WebhookStatusDeadLetter, false, true},
+		{"falho", WebhookStatusFailed, true, true},
+		{"pendente", WebhookStatusPending, true, false},
+		{"processado", WebhookStatusProcessed, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := NewWebhookEvent("pix_auto", "E1", "pix", json.RawMessage(`{}`), nil, now)
+			event.Status = tt.status
+			event.RetryCount = 6
+
+			err := event.EditPayload(json.RawMessage(`{"pix":[]}`))
+			if (err != nil) != tt.editErr {
+				t.Errorf("EditPayload() error = %v, want erro %v", err, tt.editErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("EditPayload() error = %v, want ErrInvalidTransition", err)
+			}
+
+			err = event.Replay()
+			if tt.replayOK {
+				if err != nil || event.Status != WebhookStatusPending || event.RetryCount != 0 {
+					t.Errorf("Replay() = %v, status %s, retries %d", err, event.Status, event.RetryCount)
+				}
+			} else if !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("Replay() error = %v, want ErrInvalidTransition", err)
+			}
+		})
+	}
+
+	event := NewWebhookEvent("pix_auto", "E1", "pix", nil, nil, now)
+	event.Status = WebhookStatusDeadLetter
+	if err := event.EditPayload(json.RawMessage(`{"pix":`)); err == nil {
+		t.Error("EditPayload(JSON inválido) deveria falhar")
+	}
+}
+
+func TestWebhookEvent_Abandon(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	backoff := WebhookBackoff{Base: time.Minute, Max: time.Hour, MaxRetries: 5, Lease: 5 * time.Minute}
+	event := NewWebhookEvent("pix_auto", "E1", "pix", json.RawMessage(`{}`), nil, now)
+	event.MarkProcessing(now)
+
+	if event.IsAbandoned(now.Add(4*time.Minute), backoff.Lease) {
+		t.Error("evento dentro do lease não está abandonado")
+	}
+	if err := event.Abandon(now.Add(4*time.Minute), backoff, 0); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Abandon dentro do lease error = %v, want ErrInvalidTransition", err)
+	}
+
+	later := now.Add(5 * time.Minute)
+	if err := event.Abandon(later, backoff, 0); err != nil {
+		t.Fatalf("Abandon: %v", err)
+	}
+	if event.Status != WebhookStatusFailed || event.RetryCount != 1 || event.ProcessingStartedAt != nil {
+		t.Errorf("após Abandon: status = %s, retry_count = %d, started = %v", event.Status, event.RetryCount, event.ProcessingStartedAt)
+	}
+	if !event.IsRetryDue(later.Add(time.Minute)) {
+		t.Error("evento abandonado deveria voltar às retentativas com backoff")
+	}
+}
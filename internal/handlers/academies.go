@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// maxAcademyNameLength limita o nome da academia
+const maxAcademyNameLength = 120
+
+// AcademyHandler expõe o cadastro e a consulta de academias
+type AcademyHandler struct {
+	academies *service.AcademyService
+}
+
+// NewAcademyHandler cria o handler de academias
+func NewAcademyHandler(academies *service.AcademyService) *AcademyHandler {
+	return &AcademyHandler{academies: academies}
+}
+
+// Create cadastra a academia do usuário autenticado, que vira o dono, e
+// inicia o trial
+// Endpoint: POST /api/academies
+func (h *AcademyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	ownerID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+	if academyID, ok := AcademyIDFromContext(r.Context()); ok {
+		writeError(w, fmt.Errorf("%w: usuário já vinculado à academia %s", domain.ErrAlreadyExists, academyID))
+		return
+	}
+
+	var req service.CreateAcademyRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	if errs := validateAcademy(&req); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	created, err := h.academies.Create(r.Context(), ownerID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// Get retorna a academia do usuário autenticado
+// Endpoint: GET /api/academies/{id}
+func (h *AcademyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/academies/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	// Outra academia não descobre se o ID existe: 404 nos dois casos
+	if id != academyID {
+		writeError(w, domain.ErrNotFound)
+		return
+	}
+	academy, err := h.academies.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, academy)
+}
+
+// validateAcademy valida os campos do cadastro que o app mostra ao lado de
+// cada campo; as demais regras ficam em domain.Academy.Validate
+func validateAcademy(req *service.CreateAcademyRequest) validationErrors {
+	errs := validationErrors{}
+	req.Name = strings.TrimSpace(req.Name)
+	switch {
+	case req.Name == "":
+		errs["name"] = "obrigatório"
+	case len(req.Name) > maxAcademyNameLength:
+		errs["name"] = "muito longo"
+	case req.Slug == "" && domain.Slugify(req.Name) == "":
+		errs["name"] = "deve conter letras ou números"
+	}
+	if req.Slug != "" && domain.Slugify(req.Slug) != req.Slug {
+		errs["slug"] = "use letras minúsculas, números e hífens"
+	}
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			errs["email"] = "e-mail inválido"
+		}
+	}
+	if req.Document != "" {
+		if _, err := domain.NormalizeDocument(req.Document); err != nil {
+			errs["document"] = "CPF ou CNPJ inválido"
+		}
+	}
+	return errs
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// newAcademyMux monta o handler de academias sobre repositórios em memória
+func newAcademyMux(t *testing.T) (*http.ServeMux, *memory.SubscriptionRepository) {
+	t.Helper()
+	plans := memory.NewPlanRepository(memory.DefaultPlans(time.Now())...)
+	subs := memory.NewSubscriptionRepository(plans, memory.NewOutbox())
+	academies := service.NewAcademyService(memory.NewAcademyRepository(nil),
+		service.NewTrialService(subs, plans, nil), memory.NewUnitOfWork())
+
+	handler := NewAcademyHandler(academies)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/academies", handler.Create)
+	mux.HandleFunc("/api/academies/", handler.Get)
+	return mux, subs
+}
+
+// doAcademy executa a requisição como userID, já vinculado a academyID (vazios = sem vínculo)
+func doAcademy(t *testing.T, mux *http.ServeMux, method, path, body, userID, academyID string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	ctx := req.Context()
+	if userID != "" {
+		ctx = WithUserID(ctx, userID)
+	}
+	if academyID != "" {
+		ctx = WithAcademyID(ctx, academyID)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req.WithContext(ctx))
+
+	var decoded map[string]interface{}
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("resposta JSON inválida: %v (%s)", err, rec.Body)
+		}
+	}
+	return rec, decoded
+}
+
+func TestAcademyHandler_Create(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		userID    string
+		academyID string
+		want      int
+		fields    []string
+	}{
+		{"sem autenticação", http.MethodPost, `{"name":"Gracie"}`, "", "", http.StatusUnauthorized, nil},
+		{"método errado", http.MethodGet, ``, "user-1", "", http.StatusMethodNotAllowed, nil},
+		{"já tem academia", http.MethodPost, `{"name":"Gracie"}`, "user-1", "academy-1", http.StatusConflict, nil},
+		{"campo desconhecido", http.MethodPost, `{"nome":"Gracie"}`, "user-1", "", http.StatusBadRequest, nil},
+		{"campos inválidos", http.MethodPost,
+			`{"name":" ","slug":"Gracie SP","email":"x","document":"111.111.111-11"}`,
+			"user-1", "", http.StatusBadRequest, []string{"name", "slug", "email", "document"}},
+		{"cor inválida", http.MethodPost, `{"name":"Gracie","primary_color":"azul"}`, "user-1", "", http.StatusBadRequest, nil},
+		{"plano inexistente", http.MethodPost, `{"name":"Gracie","plan":"gold"}`, "user-1", "", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newAcademyMux(t)
+			rec, body := doAcademy(t, mux, tt.method, "/api/academies", tt.body, tt.userID, tt.academyID)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body)
+			}
+			fields, _ := body["fields"].(map[string]interface{})
+			if len(fields) != len(tt.fields) {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+			for _, field := range tt.fields {
+				if _, ok := fields[field]; !ok {
+					t.Errorf("fields sem %q: %v", field, fields)
+				}
+			}
+		})
+	}
+}
+
+func TestAcademyHandler_CreateAndGet(t *testing.T) {
+	mux, subs := newAcademyMux(t)
+
+	rec, body := doAcademy(t, mux, http.MethodPost, "/api/academies",
+		`{"name":"Academia Força & Honra","address":{"city":"São Paulo","state":"SP"},"primary_color":"#C0FFEE"}`, "user-1", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+	academy, _ := body["academy"].(map[string]interface{})
+	subscription, _ := body["subscription"].(map[string]interface{})
+	academyID, _ := academy["id"].(string)
+	if academy["slug"] != "academia-forca-honra" || academy["owner_id"] != "user-1" || academy["invite_code"] == "" {
+		t.Errorf("academia = %v", academy)
+	}
+	if subscription["status"] != "trialing" || subscription["academy_id"] != academyID {
+		t.Errorf("assinatura = %v", subscription)
+	}
+	if sub, err := subs.GetByAcademy(context.Background(), academyID); err != nil || sub.PlanID != starterPlanID {
+		t.Errorf("trial no Starter não persistido: %+v, %v", sub, err)
+	}
+
+	rec, body = doAcademy(t, mux, http.MethodGet, "/api/academies/"+academyID, "", "user-1", academyID)
+	if rec.Code != http.StatusOK || body["id"] != academyID || body["primary_color"] != "#C0FFEE" {
+		t.Fatalf("GET: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if address, _ := body["address"].(map[string]interface{}); address["country"] != "BR" {
+		t.Errorf("address = %v", body["address"])
+	}
+
+	// Outra academia (ou sem autenticação) não vê a academia
+	if rec, _ := doAcademy(t, mux, http.MethodGet, "/api/academies/"+academyID, "", "user-2", "academy-2"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET de outra academia: status = %d, want 404", rec.Code)
+	}
+	if rec, _ := doAcademy(t, mux, http.MethodGet, "/api/academies/"+academyID, "", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET sem autenticação: status = %d, want 401", rec.Code)
+	}
+}
@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// adminAuthorized compara o Bearer token da requisição com o token
+// administrativo em tempo constante
+func adminAuthorized(r *http.Request, token string) bool {
+	got, ok := bearerToken(r)
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
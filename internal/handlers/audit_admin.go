@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// AuditAdminHandler expõe o audit log de cada academia para consulta e
+// verificação da cadeia. Exige o token administrativo; sem token configurado
+// as rotas respondem 404.
+type AuditAdminHandler struct {
+	audit *service.AuditService
+	token string
+}
+
+// NewAuditAdminHandler cria o handler administrativo do audit log
+func NewAuditAdminHandler(audit *service.AuditService, token string) *AuditAdminHandler {
+	return &AuditAdminHandler{audit: audit, token: token}
+}
+
+// ServeHTTP roteia as consultas ao audit log
+// Endpoints:
+//
+//	GET /api/admin/audit/{academy_id}?after=&limit=
+//	GET /api/admin/audit/{academy_id}/verify
+func (h *AuditAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !adminAuthorized(r, h.token) {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/audit"), "/")
+	academyID, action, _ := strings.Cut(path, "/")
+	if academyID == "" || (action != "" && action != "verify") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if action == "verify" {
+		result, err := h.audit.Verify(r.Context(), academyID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	h.list(w, r, academyID)
+}
+
+// list lista os registros da academia a partir de ?after= (seq), em ordem
+func (h *AuditAdminHandler) list(w http.ResponseWriter, r *http.Request, academyID string) {
+	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if after < 0 {
+		after = 0
+	}
+
+	entries, err := h.audit.List(r.Context(), academyID, after, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
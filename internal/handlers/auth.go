@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Authenticator autentica as requisições pelo JWT do Supabase
+// (Authorization: Bearer) e carrega do perfil o papel e a academia do usuário
+type Authenticator struct {
+	verifier ports.TokenVerifier
+	profiles ports.ProfileService
+}
+
+// NewAuthenticator cria o middleware de autenticação. Sem verifier (auth não
+// configurada) toda requisição autenticada é recusada.
+func NewAuthenticator(verifier ports.TokenVerifier, profiles ports.ProfileService) *Authenticator {
+	return &Authenticator{verifier: verifier, profiles: profiles}
+}
+
+// Authenticate valida o token e coloca no contexto o usuário (também ator do
+// audit log), o papel e, se houver, a academia. Token ausente ou inválido
+// responde 401; usuário sem perfil, 403.
+func (a *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok || a.verifier == nil {
+			unauthorized(w)
+			return
+		}
+
+		claims, err := a.verifier.Verify(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, ports.ErrInvalidToken) {
+				log.Printf("[Auth] Token recusado (%s %s, %s): %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+				unauthorized(w)
+				return
+			}
+			log.Printf("[Auth] Erro ao verificar token: %v", err)
+			http.Error(w, "Autenticação indisponível", http.StatusServiceUnavailable)
+			return
+		}
+
+		profile, err := a.profiles.GetByID(r.Context(), claims.Subject)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			log.Printf("[Auth] Usuário %s sem perfil", claims.Subject)
+			http.Error(w, "Perfil não encontrado", http.StatusForbidden)
+			return
+		case err != nil:
+			log.Printf("[Auth] Erro ao carregar perfil de %s: %v", claims.Subject, err)
+			http.Error(w, "Erro ao carregar perfil", http.StatusInternalServerError)
+			return
+		case !profile.Role.IsValid():
+			log.Printf("[Auth] Usuário %s com papel desconhecido: %q", claims.Subject, profile.Role)
+			http.Error(w, "Acesso negado", http.StatusForbidden)
+			return
+		}
+
+		ctx := WithRole(WithUserID(r.Context(), profile.ID), profile.Role)
+		if profile.AcademyID != "" {
+			ctx = WithAcademyID(ctx, profile.AcademyID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireRole só deixa passar usuários autenticados com um dos papéis
+// Uso: auth.Authenticate(RequireRole(domain.RoleOwner)(handler))
+func RequireRole(roles ...domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := RoleFromContext(r.Context())
+			if !ok {
+				unauthorized(w)
+				return
+			}
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			userID, _ := UserIDFromContext(r.Context())
+			log.Printf("[Auth] Acesso negado a %s %s: usuário %s é %s", r.Method, r.URL.Path, userID, role)
+			http.Error(w, "Acesso negado", http.StatusForbidden)
+		})
+	}
+}
+
+// RequireSameAcademy só deixa passar quando a academia do caminho (primeiro
+// segmento depois de prefix, ex: "/api/academies/") é a do usuário. Outra
+// academia não descobre se o ID existe: 404 nos dois casos.
+func RequireSameAcademy(prefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := UserIDFromContext(r.Context()); !ok {
+				unauthorized(w)
+				return
+			}
+			id, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
+			academyID, ok := AcademyIDFromContext(r.Context())
+			if !ok || id != academyID {
+				writeError(w, domain.ErrNotFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken extrai o token do header Authorization (esquema Bearer)
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	return token, ok && strings.EqualFold(scheme, "Bearer") && token != ""
+}
+
+// unauthorized responde 401 pedindo um Bearer token
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="blackbelt"`)
+	http.Error(w, "Não autenticado", http.StatusUnauthorized)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// fakeVerifier aceita tokens "ok:<sub>"; "down" simula o JWKS fora do ar
+type fakeVerifier struct{}
+
+func (fakeVerifier) Verify(ctx context.Context, token string) (*ports.TokenClaims, error) {
+	if token == "down" {
+		return nil, errors.New("JWKS indisponível")
+	}
+	sub, ok := strings.CutPrefix(token, "ok:")
+	if !ok {
+		return nil, fmt.Errorf("%w: assinatura não confere", ports.ErrInvalidToken)
+	}
+	return &ports.TokenClaims{Subject: sub}, nil
+}
+
+// whoami responde o usuário, o papel e a academia do contexto
+var whoami = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserIDFromContext(r.Context())
+	role, _ := RoleFromContext(r.Context())
+	academyID, _ := AcademyIDFromContext(r.Context())
+	actor := domain.AuditActorFromContext(r.Context())
+	fmt.Fprintf(w, "%s|%s|%s|%s", userID, role, academyID, actor.ID)
+})
+
+func TestAuthenticator(t *testing.T) {
+	profiles := memory.NewProfileRepository(
+		&domain.Profile{ID: "owner-1", Role: domain.RoleOwner, AcademyID: "academy-1"},
+		&domain.Profile{ID: "novo", Role: domain.RoleOwner},
+		&domain.Profile{ID: "aluno-1", Role: domain.RoleStudent, AcademyID: "academy-1"},
+		&domain.Profile{ID: "estranho", Role: "admin"},
+	)
+	auth := NewAuthenticator(fakeVerifier{}, profiles)
+
+	mux := http.NewServeMux()
+	mux.Handle("/me", auth.Authenticate(whoami))
+	mux.Handle("/owner", auth.Authenticate(RequireRole(domain.RoleOwner)(whoami)))
+	mux.Handle("/api/academies/", auth.Authenticate(RequireSameAcademy("/api/academies/")(whoami)))
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+		body   string
+	}{
+		{"sem header", "/me", "", http.StatusUnauthorized, ""},
+		{"outro esquema", "/me", "Basic b2s6b3duZXItMQ==", http.StatusUnauthorized, ""},
+		{"token inválido", "/me", "Bearer forjado", http.StatusUnauthorized, ""},
+		{"JWKS fora do ar", "/me", "Bearer down", http.StatusServiceUnavailable, ""},
+		{"sem perfil", "/me", "Bearer ok:fantasma", http.StatusForbidden, ""},
+		{"papel desconhecido", "/me", "Bearer ok:estranho", http.StatusForbidden, ""},
+		{"dono", "/me", "Bearer ok:owner-1", http.StatusOK, "owner-1|owner|academy-1|owner-1"},
+		{"esquema minúsculo", "/me", "bearer ok:owner-1", http.StatusOK, "owner-1|owner|academy-1|owner-1"},
+		{"dono sem academia", "/me", "Bearer ok:novo", http.StatusOK, "novo|owner||novo"},
+		{"RequireRole: dono", "/owner", "Bearer ok:owner-1", http.StatusOK, "owner-1|owner|academy-1|owner-1"},
+		{"RequireRole: aluno", "/owner", "Bearer ok:aluno-1", http.StatusForbidden, ""},
+		{"mesma academia", "/api/academies/academy-1", "Bearer ok:aluno-1", http.StatusOK, "aluno-1|student|academy-1|aluno-1"},
+		{"subcaminho da mesma academia", "/api/academies/academy-1/members", "Bearer ok:aluno-1", http.StatusOK, "aluno-1|student|academy-1|aluno-1"},
+		{"outra academia", "/api/academies/academy-2", "Bearer ok:owner-1", http.StatusNotFound, ""},
+		{"usuário sem academia", "/api/academies/academy-1", "Bearer ok:novo", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 sem WWW-Authenticate")
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("contexto = %q, want %q", rec.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_NotConfigured(t *testing.T) {
+	handler := NewAuthenticator(nil, memory.NewProfileRepository()).Authenticate(whoami)
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer ok:owner-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("sem verificador: status = %d, want 401", rec.Code)
+	}
+
+	// RequireRole fora do Authenticate não deixa passar
+	rec = httptest.NewRecorder()
+	RequireRole(domain.RoleOwner)(whoami).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/me", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("RequireRole sem autenticação: status = %d, want 401", rec.Code)
+	}
+}
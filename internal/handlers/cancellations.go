@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// CancellationHandler expõe a reversão de cancelamentos agendados
+type CancellationHandler struct {
+	cancellations *service.CancellationService
+}
+
+// NewCancellationHandler cria o handler de cancelamentos
+func NewCancellationHandler(cancellations *service.CancellationService) *CancellationHandler {
+	return &CancellationHandler{cancellations: cancellations}
+}
+
+// Undo desiste do cancelamento agendado para o fim do período
+// Endpoint: POST /api/subscriptions/cancellation/undo
+func (h *CancellationHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	sub, err := h.cancellations.UndoCancel(r.Context(), academyID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
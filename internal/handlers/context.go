@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// contextKey evita colisões com chaves de contexto de outros pacotes
+type contextKey string
+
+const (
+	academyIDKey contextKey = "academy_id"
+	userIDKey    contextKey = "user_id"
+	roleKey      contextKey = "role"
+)
+
+// WithAcademyID retorna um contexto com a academia autenticada
+func WithAcademyID(ctx context.Context, academyID string) context.Context {
+	return context.WithValue(ctx, academyIDKey, academyID)
+}
+
+// AcademyIDFromContext retorna a academia autenticada (preenchida pelo middleware de auth)
+func AcademyIDFromContext(ctx context.Context) (string, bool) {
+	academyID, ok := ctx.Value(academyIDKey).(string)
+	return academyID, ok && academyID != ""
+}
+
+// WithUserID retorna um contexto com o usuário autenticado, que passa a ser
+// também o ator do audit log
+func WithUserID(ctx context.Context, userID string) context.Context {
+	ctx = domain.WithAuditActor(ctx, domain.AuditActor{Type: domain.AuditActorUser, ID: userID})
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext retorna o usuário autenticado (preenchido pelo middleware de auth)
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	return userID, ok && userID != ""
+}
+
+// WithRole retorna um contexto com o papel do usuário autenticado
+func WithRole(ctx context.Context, role domain.Role) context.Context {
+	return context.WithValue(ctx, roleKey, role)
+}
+
+// RoleFromContext retorna o papel do usuário autenticado (preenchido pelo middleware de auth)
+func RoleFromContext(ctx context.Context) (domain.Role, bool) {
+	role, ok := ctx.Value(roleKey).(domain.Role)
+	return role, ok && role != ""
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// CouponHandler expõe o resgate de cupons
+type CouponHandler struct {
+	discounts *service.DiscountService
+}
+
+// NewCouponHandler cria o handler de cupons
+func NewCouponHandler(discounts *service.DiscountService) *CouponHandler {
+	return &CouponHandler{discounts: discounts}
+}
+
+// Redeem aplica um cupom à assinatura da academia autenticada
+// Endpoint: POST /api/subscriptions/coupon
+func (h *CouponHandler) Redeem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		writeValidationErrors(w, validationErrors{"code": "obrigatório"})
+		return
+	}
+
+	sub, err := h.discounts.RedeemCoupon(r.Context(), academyID, req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// EntitlementsHandler expõe os entitlements da academia e middlewares de enforcement
+type EntitlementsHandler struct {
+	entitlements *service.EntitlementsService
+}
+
+// NewEntitlementsHandler cria o handler de entitlements
+func NewEntitlementsHandler(entitlements *service.EntitlementsService) *EntitlementsHandler {
+	return &EntitlementsHandler{entitlements: entitlements}
+}
+
+// Get retorna features e uso dos limites da academia autenticada (usado pelo app)
+// Endpoint: GET /api/entitlements
+func (h *EntitlementsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	ent, err := h.entitlements.Get(r.Context(), academyID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ent)
+}
+
+// Capacity confirma que a academia pode cadastrar mais um item do recurso: o
+// app chama antes de criar o aluno ou professor no Supabase. O limite é
+// aplicado pelo RequireCapacity montado na rota; chegando aqui, há vaga.
+// Endpoint: POST /api/entitlements/{students,professors}
+func (h *EntitlementsHandler) Capacity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequireFeature bloqueia a rota se o plano da academia não inclui a feature
+func (h *EntitlementsHandler) RequireFeature(feature domain.Feature) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			academyID, ok := AcademyIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Não autenticado", http.StatusUnauthorized)
+				return
+			}
+			if err := h.entitlements.CheckFeature(r.Context(), academyID, feature); err != nil {
+				writeError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireCapacity bloqueia criações (POST) quando o limite do recurso foi atingido
+func (h *EntitlementsHandler) RequireCapacity(resource domain.Resource) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+			academyID, ok := AcademyIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Não autenticado", http.StatusUnauthorized)
+				return
+			}
+			if err := h.entitlements.CheckCapacity(r.Context(), academyID, resource, 1); err != nil {
+				writeError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
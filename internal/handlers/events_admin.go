@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// EventsAdminHandler expõe os contadores de eventos de domínio entregues.
+// Exige o token administrativo; sem token configurado responde 404.
+type EventsAdminHandler struct {
+	metrics *service.EventMetrics
+	token   string
+}
+
+// NewEventsAdminHandler cria o handler administrativo dos eventos
+func NewEventsAdminHandler(metrics *service.EventMetrics, token string) *EventsAdminHandler {
+	return &EventsAdminHandler{metrics: metrics, token: token}
+}
+
+// Metrics devolve quantos eventos de cada tipo foram entregues desde a subida
+// Endpoint: GET /api/admin/events/metrics
+func (h *EventsAdminHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if h.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !adminAuthorized(r, h.token) {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.metrics.Snapshot())
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// HealthHandler expõe o estado da API e dos circuit breakers dos gateways
+type HealthHandler struct {
+	reporters []ports.GatewayHealthReporter
+}
+
+// NewHealthHandler cria um health check que inclui o estado dos gateways informados
+func NewHealthHandler(reporters ...ports.GatewayHealthReporter) *HealthHandler {
+	return &HealthHandler{reporters: reporters}
+}
+
+// healthResponse é o corpo retornado pelo health check
+type healthResponse struct {
+	Status   string                                  `json:"status"` // "healthy" | "degraded"
+	Service  string                                  `json:"service"`
+	Gateways map[string][]ports.CircuitBreakerStatus `json:"gateways,omitempty"`
+}
+
+// ServeHTTP responde o health check.
+// Circuitos abertos deixam a API "degraded", mas o status HTTP continua 200:
+// a API segue atendendo (modo degradado) e não deve ser reiniciada pelo orquestrador.
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{
+		Status:  "healthy",
+		Service: "blackbelt-api",
+	}
+
+	if len(h.reporters) > 0 {
+		resp.Gateways = make(map[string][]ports.CircuitBreakerStatus, len(h.reporters))
+		for _, reporter := range h.reporters {
+			statuses := reporter.BreakerStatus()
+			resp.Gateways[reporter.GatewayName()] = statuses
+			for _, status := range statuses {
+				if status.State != "closed" {
+					resp.Status = "degraded"
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HealthCheck endpoint para verificar se o servidor está funcionando
+func HealthCheck(w http.ResponseWriter, r *http.Request) {
+	NewHealthHandler().ServeHTTP(w, r)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// InvoiceHandler expõe as faturas da academia
+type InvoiceHandler struct {
+	invoices *service.InvoiceService
+}
+
+// NewInvoiceHandler cria o handler de faturas
+func NewInvoiceHandler(invoices *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoices: invoices}
+}
+
+// List lista as faturas da academia autenticada
+// Endpoint: GET /api/invoices
+func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	invoices, err := h.invoices.List(r.Context(), academyID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, invoices)
+}
+
+// Download baixa uma fatura em PDF (padrão) ou HTML
+// Endpoint: GET /api/invoices/{id}/download?format=pdf|html
+func (h *InvoiceHandler) Download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	invoiceID, found := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/invoices/"), "/download")
+	if !found || invoiceID == "" || strings.Contains(invoiceID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	format := service.InvoiceFormat(r.URL.Query().Get("format"))
+	doc, err := h.invoices.Download(r.Context(), academyID, invoiceID, format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(doc.Content)))
+	if format != service.InvoiceFormatHTML {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+doc.Filename+`"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc.Content)
+}
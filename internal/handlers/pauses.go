@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// PauseHandler expõe pausa e retomada de assinaturas
+type PauseHandler struct {
+	pauses *service.PauseService
+}
+
+// NewPauseHandler cria o handler de pausas
+func NewPauseHandler(pauses *service.PauseService) *PauseHandler {
+	return &PauseHandler{pauses: pauses}
+}
+
+// Pause agenda a pausa da assinatura da academia autenticada
+// Endpoint: POST /api/subscriptions/pause
+func (h *PauseHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+	actor, _ := UserIDFromContext(r.Context())
+
+	var req service.PauseRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	errs := validationErrors{}
+	if req.ResumeAt.IsZero() {
+		errs["resume_at"] = "obrigatório"
+	}
+	if len(req.Reason) > maxCancelReasonLength {
+		errs["reason"] = "motivo muito longo"
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	sub, err := h.pauses.Pause(r.Context(), academyID, &req, actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// Resume retoma a assinatura antes da data planejada
+// Endpoint: POST /api/subscriptions/resume
+func (h *PauseHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+	actor, _ := UserIDFromContext(r.Context())
+
+	sub, err := h.pauses.Resume(r.Context(), academyID, actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
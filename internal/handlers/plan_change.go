@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// PlanChangeHandler expõe a troca de plano com pró-rata
+type PlanChangeHandler struct {
+	planChanges *service.PlanChangeService
+}
+
+// NewPlanChangeHandler cria o handler de troca de plano
+func NewPlanChangeHandler(planChanges *service.PlanChangeService) *PlanChangeHandler {
+	return &PlanChangeHandler{planChanges: planChanges}
+}
+
+// Preview mostra os valores da troca antes da confirmação
+// Endpoint: GET /api/subscriptions/plan-change/preview?plan=pro&interval=monthly
+func (h *PlanChangeHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	quote, err := h.planChanges.Preview(r.Context(), academyID, &service.PlanChangeRequest{
+		PlanSlug: query.Get("plan"),
+		Interval: domain.BillingInterval(query.Get("interval")),
+		Billing:  service.ProrationBilling(query.Get("billing")),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, quote)
+}
+
+// Change confirma a troca de plano
+// Endpoint: POST /api/subscriptions/plan-change
+func (h *PlanChangeHandler) Change(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.PlanChangeRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+
+	result, err := h.planChanges.ChangePlan(r.Context(), academyID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// RefundHandler expõe a solicitação de devoluções: pelo dono, nos pagamentos
+// da própria academia, e pela equipe, com o token administrativo
+type RefundHandler struct {
+	refunds *service.RefundService
+	token   string
+}
+
+// NewRefundHandler cria o handler de devoluções (token vazio desabilita a rota administrativa)
+func NewRefundHandler(refunds *service.RefundService, token string) *RefundHandler {
+	return &RefundHandler{refunds: refunds, token: token}
+}
+
+// refundRequest é o corpo da devolução. Actor só vale na rota administrativa
+// (o token é compartilhado); na do dono o ator é o usuário autenticado.
+type refundRequest struct {
+	Amount int    `json:"amount"` // Centavos
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+// Request devolve parte ou todo um pagamento da academia autenticada
+// Endpoint: POST /api/payments/{id}/refunds
+func (h *RefundHandler) Request(w http.ResponseWriter, r *http.Request) {
+	paymentID, ok := refundPaymentID(r.URL.Path, "/api/payments/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+	actor, _ := UserIDFromContext(r.Context())
+
+	var req refundRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	if errs := validateRefund(&req, false); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	// Pagamento de outra academia responde 404, como um ID inexistente
+	refund, err := h.refunds.RequestByID(r.Context(), academyID, paymentID, req.Amount, req.Reason, actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+// AdminRequest devolve parte ou todo um pagamento de qualquer academia,
+// registrando o ator informado. Sem token configurado a rota responde 404.
+// Endpoint: POST /api/admin/payments/{id}/refunds
+func (h *RefundHandler) AdminRequest(w http.ResponseWriter, r *http.Request) {
+	if h.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !adminAuthorized(r, h.token) {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+	paymentID, ok := refundPaymentID(r.URL.Path, "/api/admin/payments/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req refundRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	if errs := validateRefund(&req, true); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	ctx := domain.WithAuditActor(r.Context(), domain.AuditActor{Type: domain.AuditActorAdmin, ID: req.Actor})
+	refund, err := h.refunds.RequestByID(ctx, "", paymentID, req.Amount, req.Reason, req.Actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+// refundPaymentID extrai o ID do pagamento de {prefix}{id}/refunds
+func refundPaymentID(path, prefix string) (string, bool) {
+	id, found := strings.CutSuffix(strings.TrimPrefix(path, prefix), "/refunds")
+	if !found || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+// validateRefund normaliza e valida o corpo da devolução
+func validateRefund(req *refundRequest, admin bool) validationErrors {
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.Actor = strings.TrimSpace(req.Actor)
+	errs := validationErrors{}
+	if req.Amount <= 0 {
+		errs["amount"] = "deve ser positivo"
+	}
+	if len(req.Reason) > maxCancelReasonLength {
+		errs["reason"] = "motivo muito longo"
+	}
+	if admin && req.Actor == "" {
+		errs["actor"] = "obrigatório"
+	}
+	return errs
+}
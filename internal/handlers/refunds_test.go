@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi/efitest"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+func TestRefundHandler(t *testing.T) {
+	ctx := context.Background()
+	pix := efitest.New()
+	charge, err := pix.CreatePixCharge(ctx, &ports.PixChargeRequest{Amount: 9900})
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := pix.Pay(charge.TxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event efi.WebhookEvent
+	if err := json.Unmarshal(webhook, &event); err != nil {
+		t.Fatal(err)
+	}
+
+	payments := memory.NewPaymentRepository(memory.NewOutbox())
+	payment := domain.NewPaymentHistory("sub-1", "academy-1", 9900, domain.PaymentGatewayPixAuto, time.Now())
+	payment.GatewayPaymentID, payment.EndToEndID = &charge.TxID, &event.Pix[0].EndToEndID
+	payment.Succeed(time.Now())
+	if err := payments.RecordPayment(ctx, payment); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewRefundHandler(service.NewRefundService(payments, pix, nil), "admin-token")
+
+	owner := func(academyID, path, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req = req.WithContext(WithUserID(WithAcademyID(req.Context(), academyID), "user-1"))
+		rec := httptest.NewRecorder()
+		handler.Request(rec, req)
+		return rec.Code
+	}
+	admin := func(token, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/payments/"+payment.ID+"/refunds", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.AdminRequest(rec, req)
+		return rec.Code
+	}
+
+	path := "/api/payments/" + payment.ID + "/refunds"
+	if code := owner("academy-1", "/api/payments/"+payment.ID, `{"amount":100}`); code != http.StatusNotFound {
+		t.Errorf("rota sem /refunds = %d, want 404", code)
+	}
+	if code := owner("academy-1", path, `{"amount":0}`); code != http.StatusBadRequest {
+		t.Errorf("valor zero = %d, want 400", code)
+	}
+	if code := owner("academy-2", path, `{"amount":4000}`); code != http.StatusNotFound {
+		t.Errorf("pagamento de outra academia = %d, want 404", code)
+	}
+	if code := owner("academy-1", path, `{"amount":4000,"reason":"aula cancelada"}`); code != http.StatusCreated {
+		t.Fatalf("devolução do dono = %d, want 201", code)
+	}
+	if code := owner("academy-1", path, `{"amount":9900}`); code != http.StatusUnprocessableEntity {
+		t.Errorf("acima do devolvível = %d, want 422", code)
+	}
+
+	if code := admin("", `{"amount":5900,"actor":"suporte@blackbelt"}`); code != http.StatusUnauthorized {
+		t.Errorf("admin sem token = %d, want 401", code)
+	}
+	if code := admin("admin-token", `{"amount":5900}`); code != http.StatusBadRequest {
+		t.Errorf("admin sem ator = %d, want 400", code)
+	}
+	if code := admin("admin-token", `{"amount":5900,"actor":"suporte@blackbelt"}`); code != http.StatusCreated {
+		t.Fatalf("devolução da equipe = %d, want 201", code)
+	}
+
+	got, _ := payments.GetByID(ctx, payment.ID)
+	if len(got.Refunds) != 2 || got.RefundableAmount() != 0 || got.Refunds[1].RequestedBy != "suporte@blackbelt" {
+		t.Errorf("refunds = %+v, refundable = %d", got.Refunds, got.RefundableAmount())
+	}
+}
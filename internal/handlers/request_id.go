@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// maxRequestIDLength limita o X-Request-ID aceito do cliente
+const maxRequestIDLength = 128
+
+// RequestID propaga o X-Request-ID da requisição (ou gera um) para a resposta
+// e para o contexto, de onde o audit log o lê
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(domain.WithRequestID(r.Context(), id)))
+	})
+}
+
+// newRequestID gera um ID aleatório de 16 bytes em hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// writeJSON escreve uma resposta JSON com o status informado
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError traduz erros de domínio/gateway para status HTTP. Erros não
+// mapeados (repositório, gateway, bug) viram 500 com corpo genérico: a
+// mensagem interna só vai para o log.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrPlanChangeNotAllowed),
+		errors.Is(err, domain.ErrAlreadyExists), errors.Is(err, domain.ErrConcurrentModification),
+		errors.Is(err, domain.ErrOwnerHasAcademy):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrIntervalUnavailable), errors.Is(err, domain.ErrCouponInvalid),
+		errors.Is(err, domain.ErrTrialCampaignInvalid), errors.Is(err, domain.ErrRefundNotAllowed):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrSubscriptionInactive):
+		status = http.StatusPaymentRequired
+	case errors.Is(err, domain.ErrFeatureUnavailable), errors.Is(err, domain.ErrLimitReached):
+		status = http.StatusForbidden
+	case errors.Is(err, ports.ErrGatewayUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+
+	message := err.Error()
+	switch {
+	case status == http.StatusServiceUnavailable:
+		log.Printf("[HTTP] %v", err)
+		message = ports.ErrGatewayUnavailable.Error()
+	case status >= http.StatusInternalServerError:
+		log.Printf("[HTTP] %v", err)
+		message = "erro interno"
+	}
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// maxRequestBody limita o corpo JSON das requisições da API
+const maxRequestBody = 1 << 16
+
+// validationErrors mapeia campo → problema de um corpo de requisição inválido
+type validationErrors map[string]string
+
+// writeValidationErrors responde 400 com os problemas de cada campo
+func writeValidationErrors(w http.ResponseWriter, errs validationErrors) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":  "requisição inválida",
+		"fields": errs,
+	})
+}
+
+// decodeJSON lê o corpo JSON em dst, respondendo 400 se ele for inválido.
+// Corpo vazio é aceito quando allowEmpty (campos ficam com o valor zero).
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, allowEmpty bool) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(dst)
+	if err == nil || (allowEmpty && errors.Is(err, io.EOF)) {
+		return true
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": "JSON inválido: " + err.Error()})
+	return false
+}
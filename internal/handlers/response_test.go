@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{"validação", fmt.Errorf("%w: slug inválido", domain.ErrValidation), http.StatusBadRequest, "dados inválidos: slug inválido"},
+		{"não encontrado", fmt.Errorf("assinatura: %w", domain.ErrNotFound), http.StatusNotFound, "assinatura: registro não encontrado"},
+		{"transição", &domain.TransitionError{From: domain.SubscriptionStatusCanceled, To: domain.SubscriptionStatusActive}, http.StatusConflict, ""},
+		{"gateway fora", fmt.Errorf("erro ao configurar PIX Automático: %w", ports.ErrGatewayUnavailable), http.StatusServiceUnavailable, "gateway de pagamento indisponível"},
+		{"erro interno", errors.New("pq: connection refused on 10.0.0.5"), http.StatusInternalServerError, "erro interno"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeError(rec, tt.err)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("corpo: %v", err)
+			}
+			if tt.wantMessage != "" && body["error"] != tt.wantMessage {
+				t.Errorf("error = %q, want %q", body["error"], tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// Limites dos campos de texto livre
+const (
+	maxPayerNameLength    = 140
+	maxCancelReasonLength = 500
+)
+
+// SubscriptionHandler expõe a assinatura da academia autenticada: consulta,
+// início da cobrança recorrente (PIX Automático ou Stripe) e cancelamento
+type SubscriptionHandler struct {
+	subscriptions ports.SubscriptionService
+	checkout      *service.SubscriptionCheckoutService
+	cancellations *service.CancellationService
+}
+
+// NewSubscriptionHandler cria o handler de assinaturas
+func NewSubscriptionHandler(
+	subscriptions ports.SubscriptionService,
+	checkout *service.SubscriptionCheckoutService,
+	cancellations *service.CancellationService,
+) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptions: subscriptions,
+		checkout:      checkout,
+		cancellations: cancellations,
+	}
+}
+
+// Current retorna a assinatura da academia autenticada
+// Endpoint: GET /api/subscriptions/current
+func (h *SubscriptionHandler) Current(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	sub, err := h.subscriptions.GetByAcademy(r.Context(), academyID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// PixAuto cria a autorização de PIX Automático e retorna o copia e cola
+// (conteúdo do QR Code) para o dono autorizar no app do banco
+// Endpoint: POST /api/subscriptions/pix-auto
+func (h *SubscriptionHandler) PixAuto(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.PixAutoCheckoutRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	errs := validatePlanChoice(req.PlanID, req.Interval)
+	if _, err := domain.NormalizeDocument(req.CPF); err != nil {
+		errs["cpf"] = "CPF ou CNPJ inválido"
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if msg := validateName(req.Name); msg != "" {
+		errs["name"] = msg
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	checkout, err := h.checkout.StartPixAuto(r.Context(), academyID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if checkout.Status == service.CheckoutStatusQueued {
+		// Efí indisponível: o app consulta /api/subscriptions/pending-charges/{id}
+		w.Header().Set("Retry-After", strconv.Itoa(checkout.RetryAfterSeconds))
+		writeJSON(w, http.StatusAccepted, checkout)
+		return
+	}
+	writeJSON(w, http.StatusCreated, checkout)
+}
+
+// PendingCharge consulta um checkout de PIX Automático adiado (Efí indisponível):
+// queued até o worker criar a recorrência, completed com o copia e cola
+// Endpoint: GET /api/subscriptions/pending-charges/{id}
+func (h *SubscriptionHandler) PendingCharge(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/subscriptions/pending-charges/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	pending, err := h.checkout.GetPendingCheckout(r.Context(), academyID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// Stripe cria a subscription no Stripe e retorna o client_secret para o app
+// confirmar o cartão
+// Endpoint: POST /api/subscriptions/stripe
+func (h *SubscriptionHandler) Stripe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.StripeCheckoutRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	errs := validatePlanChoice(req.PlanID, req.Interval)
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		errs["email"] = "e-mail inválido"
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if msg := validateName(req.Name); msg != "" {
+		errs["name"] = msg
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	checkout, err := h.checkout.StartStripe(r.Context(), academyID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, checkout)
+}
+
+// ConvertTrial encerra o trial agora: a assinatura fica ativa e o primeiro
+// ciclo é cobrado por PIX imediato (exige o PIX Automático configurado)
+// Endpoint: POST /api/subscriptions/trial-conversion
+func (h *SubscriptionHandler) ConvertTrial(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+	actor, _ := UserIDFromContext(r.Context())
+
+	conversion, err := h.checkout.ConvertTrialNow(r.Context(), academyID, actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, conversion)
+}
+
+// cancelRequest é o corpo (opcional) do cancelamento
+type cancelRequest struct {
+	Reason    string `json:"reason"`
+	Immediate bool   `json:"immediate"` // Encerra agora em vez de no fim do período pago
+}
+
+// Cancel cancela a assinatura da academia, por padrão no fim do período pago
+// Endpoint: DELETE /api/subscriptions/{id}
+func (h *SubscriptionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/subscriptions/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+	actor, _ := UserIDFromContext(r.Context())
+
+	var req cancelRequest
+	if !decodeJSON(w, r, &req, true) {
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) > maxCancelReasonLength {
+		writeValidationErrors(w, validationErrors{"reason": "motivo muito longo"})
+		return
+	}
+
+	// Outra academia não descobre se o ID existe: 404 nos dois casos
+	current, err := h.subscriptions.GetByAcademy(r.Context(), academyID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if current.ID != id {
+		writeError(w, domain.ErrNotFound)
+		return
+	}
+
+	sub, err := h.cancellations.Cancel(r.Context(), academyID, req.Reason, !req.Immediate, actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// validatePlanChoice valida plano e intervalo de cobrança (vazio = mensal)
+func validatePlanChoice(planID string, interval domain.BillingInterval) validationErrors {
+	errs := validationErrors{}
+	if strings.TrimSpace(planID) == "" {
+		errs["plan_id"] = "obrigatório"
+	}
+	if interval != "" && !interval.IsValid() {
+		errs["interval"] = "deve ser monthly ou yearly"
+	}
+	return errs
+}
+
+// validateName valida o nome do pagador, retornando o problema ou vazio
+func validateName(name string) string {
+	switch {
+	case name == "":
+		return "obrigatório"
+	case len(name) > maxPayerNameLength:
+		return "muito longo"
+	}
+	return ""
+}
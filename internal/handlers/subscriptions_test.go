@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/efi/efitest"
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+const (
+	starterPlanID = "00000000-0000-4000-8000-000000000001"
+	proPlanID     = "00000000-0000-4000-8000-000000000002"
+)
+
+// fakeStripe cria customers e subscriptions com IDs fixos
+type fakeStripe struct {
+	ports.StripeProvider
+	priceID string
+}
+
+func (f *fakeStripe) CreateCustomer(ctx context.Context, academyID, email, name string) (string, error) {
+	return "cus_1", nil
+}
+
+func (f *fakeStripe) CreateSubscription(ctx context.Context, customerID, priceID string) (string, string, error) {
+	f.priceID = priceID
+	return "sub_stripe_1", "pi_1_secret", nil
+}
+
+// subscriptionFixture monta o handler sobre repositórios em memória, o fake da
+// Efí e uma assinatura em trial da academia-1 no plano Starter
+type subscriptionFixture struct {
+	mux  *http.ServeMux
+	subs *memory.SubscriptionRepository
+	sub  *domain.Subscription
+}
+
+func newSubscriptionFixture(t *testing.T, stripe ports.StripeProvider) *subscriptionFixture {
+	t.Helper()
+
+	now := time.Now()
+	plans := memory.NewPlanRepository(memory.DefaultPlans(now)...)
+	pro, _ := plans.GetByID(context.Background(), proPlanID)
+	priceID := "price_pro_monthly"
+	pro.StripePriceIDMonthly = &priceID
+	plans.Put(pro)
+
+	subs := memory.NewSubscriptionRepository(plans, memory.NewOutbox())
+	sub, err := subs.CreateTrial(context.Background(), "academy-1", starterPlanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pix := efitest.New()
+	handler := NewSubscriptionHandler(subs,
+		service.NewSubscriptionCheckoutService(subs, plans, memory.NewPaymentRepository(memory.NewOutbox()),
+			service.NewCheckoutService(pix, memory.NewChargeQueue(), service.CheckoutOptions{}), stripe),
+		service.NewCancellationService(subs, pix, stripe))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/subscriptions/current", handler.Current)
+	mux.HandleFunc("/api/subscriptions/pix-auto", handler.PixAuto)
+	mux.HandleFunc("/api/subscriptions/stripe", handler.Stripe)
+	mux.HandleFunc("/api/subscriptions/pending-charges/", handler.PendingCharge)
+	mux.HandleFunc("/api/subscriptions/", handler.Cancel)
+	return &subscriptionFixture{mux: mux, subs: subs, sub: sub}
+}
+
+// do executa a requisição autenticada como academy (vazio = sem autenticação)
+func (f *subscriptionFixture) do(t *testing.T, method, path, body, academyID string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if academyID != "" {
+		ctx := WithUserID(WithAcademyID(req.Context(), academyID), "user-1")
+		req = req.WithContext(ctx)
+	}
+	rec := httptest.NewRecorder()
+	f.mux.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("resposta JSON inválida: %v (%s)", err, rec.Body)
+		}
+	}
+	return rec, decoded
+}
+
+func TestSubscriptionHandler_PixAuto(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		academyID string
+		want      int
+		fields    []string // Campos esperados em "fields" nos erros de validação
+	}{
+		{"sem autenticação", http.MethodPost, `{}`, "", http.StatusUnauthorized, nil},
+		{"método errado", http.MethodGet, ``, "academy-1", http.StatusMethodNotAllowed, nil},
+		{"JSON malformado", http.MethodPost, `{"plan_id":`, "academy-1", http.StatusBadRequest, nil},
+		{"campo desconhecido", http.MethodPost, `{"plano":"pro"}`, "academy-1", http.StatusBadRequest, nil},
+		{"campos obrigatórios", http.MethodPost, `{}`, "academy-1", http.StatusBadRequest, []string{"plan_id", "cpf", "name"}},
+		{"CPF e intervalo inválidos", http.MethodPost,
+			`{"plan_id":"` + proPlanID + `","cpf":"111.111.111-11","name":"Dono","interval":"weekly"}`,
+			"academy-1", http.StatusBadRequest, []string{"cpf", "interval"}},
+		{"plano inexistente", http.MethodPost,
+			`{"plan_id":"plano-x","cpf":"529.982.247-25","name":"Dono"}`, "academy-1", http.StatusNotFound, nil},
+		{"plano sem opção anual", http.MethodPost,
+			`{"plan_id":"` + proPlanID + `","cpf":"529.982.247-25","name":"Dono","interval":"yearly"}`,
+			"academy-1", http.StatusUnprocessableEntity, nil},
+		{"academia sem assinatura", http.MethodPost,
+			`{"plan_id":"` + proPlanID + `","cpf":"529.982.247-25","name":"Dono"}`, "academy-2", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSubscriptionFixture(t, nil)
+			rec, body := f.do(t, tt.method, "/api/subscriptions/pix-auto", tt.body, tt.academyID)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body)
+			}
+			if tt.fields == nil {
+				return
+			}
+			fields, _ := body["fields"].(map[string]interface{})
+			if len(fields) != len(tt.fields) {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+			for _, field := range tt.fields {
+				if _, ok := fields[field]; !ok {
+					t.Errorf("fields sem %q: %v", field, fields)
+				}
+			}
+		})
+	}
+}
+
+func TestSubscriptionHandler_PixAutoCreated(t *testing.T) {
+	f := newSubscriptionFixture(t, nil)
+
+	rec, body := f.do(t, http.MethodPost, "/api/subscriptions/pix-auto",
+		`{"plan_id":"`+proPlanID+`","cpf":"529.982.247-25","name":"Dono da Academia"}`, "academy-1")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (%s)", rec.Code, rec.Body)
+	}
+	if code, _ := body["pix_copia_e_cola"].(string); !strings.HasPrefix(code, "000201") {
+		t.Errorf("pix_copia_e_cola = %q", code)
+	}
+	if body["authorization_id"] == "" || body["amount"] != float64(19900) {
+		t.Errorf("authorization_id = %v, amount = %v", body["authorization_id"], body["amount"])
+	}
+
+	sub, err := f.subs.GetByAcademy(context.Background(), "academy-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Status != domain.SubscriptionStatusTrialing || sub.PlanID != proPlanID {
+		t.Errorf("assinatura: status = %s, plano = %s", sub.Status, sub.PlanID)
+	}
+	if sub.PaymentGateway == nil || *sub.PaymentGateway != domain.PaymentGatewayPixAuto ||
+		sub.PixAuthorizationID == nil || sub.PixCustomerCPF == nil || *sub.PixCustomerCPF != "52998224725" {
+		t.Errorf("dados do PIX Automático não gravados: %+v", sub)
+	}
+	if first, _ := time.Parse(time.RFC3339, body["first_charge_at"].(string)); !first.Equal(*sub.TrialEndDate) {
+		t.Errorf("first_charge_at = %v, want fim do trial %v", first, sub.TrialEndDate)
+	}
+}
+
+func TestSubscriptionHandler_Stripe(t *testing.T) {
+	body := `{"plan_id":"` + proPlanID + `","email":"dono@academia.com","name":"Dono"}`
+
+	f := newSubscriptionFixture(t, nil)
+	if rec, _ := f.do(t, http.MethodPost, "/api/subscriptions/stripe", body, "academy-1"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("sem Stripe configurado: status = %d, want 503", rec.Code)
+	}
+
+	stripe := &fakeStripe{}
+	f = newSubscriptionFixture(t, stripe)
+	rec, resp := f.do(t, http.MethodPost, "/api/subscriptions/stripe", `{"plan_id":"`+proPlanID+`","email":"x"}`, "academy-1")
+	if fields, _ := resp["fields"].(map[string]interface{}); rec.Code != http.StatusBadRequest || fields["email"] == nil || fields["name"] == nil {
+		t.Errorf("validação: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	rec, resp = f.do(t, http.MethodPost, "/api/subscriptions/stripe", body, "academy-1")
+	if rec.Code != http.StatusCreated || resp["client_secret"] != "pi_1_secret" {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if stripe.priceID != "price_pro_monthly" {
+		t.Errorf("price = %q, want price_pro_monthly", stripe.priceID)
+	}
+	sub, _ := f.subs.GetByAcademy(context.Background(), "academy-1")
+	if sub.StripeSubscriptionID == nil || *sub.StripeSubscriptionID != "sub_stripe_1" || sub.StripeCustomerID == nil {
+		t.Errorf("dados do Stripe não gravados: %+v", sub)
+	}
+}
+
+func TestSubscriptionHandler_CancelAndCurrent(t *testing.T) {
+	f := newSubscriptionFixture(t, nil)
+
+	rec, body := f.do(t, http.MethodGet, "/api/subscriptions/current", "", "academy-1")
+	if rec.Code != http.StatusOK || body["id"] != f.sub.ID || body["status"] != "trialing" {
+		t.Fatalf("current: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec, _ := f.do(t, http.MethodGet, "/api/subscriptions/current", "", "academy-2"); rec.Code != http.StatusNotFound {
+		t.Errorf("current sem assinatura: status = %d, want 404", rec.Code)
+	}
+
+	// ID de outra assinatura (ou inexistente) não é cancelado
+	if rec, _ := f.do(t, http.MethodDelete, "/api/subscriptions/outra", "", "academy-1"); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE outro ID: status = %d, want 404", rec.Code)
+	}
+	if rec, _ := f.do(t, http.MethodPost, "/api/subscriptions/"+f.sub.ID, "", "academy-1"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST no ID: status = %d, want 405", rec.Code)
+	}
+
+	rec, body = f.do(t, http.MethodDelete, "/api/subscriptions/"+f.sub.ID, `{"reason":"muito caro"}`, "academy-1")
+	if rec.Code != http.StatusOK || body["cancel_at_period_end"] != true || body["status"] != "trialing" {
+		t.Fatalf("DELETE: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// immediate encerra agora; depois disso não há o que cancelar
+	rec, body = f.do(t, http.MethodDelete, "/api/subscriptions/"+f.sub.ID, `{"immediate":true}`, "academy-1")
+	if rec.Code != http.StatusOK || body["status"] != "canceled" {
+		t.Fatalf("DELETE imediato: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec, _ := f.do(t, http.MethodDelete, "/api/subscriptions/"+f.sub.ID, "", "academy-1"); rec.Code != http.StatusConflict {
+		t.Errorf("DELETE de assinatura cancelada: status = %d, want 409", rec.Code)
+	}
+}
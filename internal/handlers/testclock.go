@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// TestClockHandler expõe o test clock do sandbox. Fora do sandbox as rotas
+// respondem 404, como se não existissem.
+type TestClockHandler struct {
+	clocks  *service.TestClockService
+	sandbox bool
+}
+
+// NewTestClockHandler cria o handler do test clock
+func NewTestClockHandler(clocks *service.TestClockService, sandbox bool) *TestClockHandler {
+	return &TestClockHandler{clocks: clocks, sandbox: sandbox}
+}
+
+// advanceRequest é o corpo de POST /api/sandbox/test-clock
+type advanceRequest struct {
+	Days  int `json:"days"`
+	Hours int `json:"hours"`
+}
+
+// ServeHTTP consulta (GET) ou avança (POST) o relógio da academia autenticada
+// Endpoint: GET|POST /api/sandbox/test-clock
+func (h *TestClockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.sandbox {
+		http.NotFound(w, r)
+		return
+	}
+	academyID, ok := AcademyIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"academy_id": academyID,
+			"now":        h.clocks.Now(academyID),
+		})
+	case http.MethodPost:
+		var req advanceRequest
+		if !decodeJSON(w, r, &req, false) {
+			return
+		}
+		errs := validationErrors{}
+		if req.Days < 0 {
+			errs["days"] = "não pode ser negativo"
+		}
+		if req.Hours < 0 {
+			errs["hours"] = "não pode ser negativo"
+		}
+		if req.Days == 0 && req.Hours == 0 && len(errs) == 0 {
+			errs["days"] = "informe days ou hours"
+		}
+		if len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		d := time.Duration(req.Days)*24*time.Hour + time.Duration(req.Hours)*time.Hour
+		result, err := h.clocks.Advance(r.Context(), academyID, d)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
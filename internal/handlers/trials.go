@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// TrialHandler expõe operações administrativas de trial. Exige o token
+// administrativo; sem token configurado as rotas respondem 404.
+type TrialHandler struct {
+	trials *service.TrialService
+	token  string
+}
+
+// NewTrialHandler cria o handler administrativo de trials
+func NewTrialHandler(trials *service.TrialService, token string) *TrialHandler {
+	return &TrialHandler{trials: trials, token: token}
+}
+
+// trialExtensionRequest é o corpo da extensão de trial. Actor identifica quem
+// da equipe concedeu a extensão (o token administrativo é compartilhado).
+type trialExtensionRequest struct {
+	SubscriptionID string `json:"subscription_id"`
+	Days           int    `json:"days"`
+	Reason         string `json:"reason"`
+	Actor          string `json:"actor"`
+}
+
+// Extend estende o trial de uma assinatura, registrando o ator informado
+// Endpoint: POST /api/admin/subscriptions/trial-extension
+func (h *TrialHandler) Extend(w http.ResponseWriter, r *http.Request) {
+	if h.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !adminAuthorized(r, h.token) {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req trialExtensionRequest
+	if !decodeJSON(w, r, &req, false) {
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.Actor = strings.TrimSpace(req.Actor)
+	errs := validationErrors{}
+	if strings.TrimSpace(req.SubscriptionID) == "" {
+		errs["subscription_id"] = "obrigatório"
+	}
+	if req.Days < 1 {
+		errs["days"] = "deve ser positivo"
+	}
+	if req.Reason == "" {
+		errs["reason"] = "obrigatório"
+	} else if len(req.Reason) > maxCancelReasonLength {
+		errs["reason"] = "motivo muito longo"
+	}
+	if req.Actor == "" {
+		errs["actor"] = "obrigatório"
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	ctx := domain.WithAuditActor(r.Context(), domain.AuditActor{Type: domain.AuditActorAdmin, ID: req.Actor})
+	sub, err := h.trials.ExtendTrial(ctx, req.SubscriptionID, req.Days, req.Reason, req.Actor)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
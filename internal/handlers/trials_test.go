@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+func TestTrialHandler_Extend(t *testing.T) {
+	ctx := context.Background()
+	plans := memory.NewPlanRepository(memory.DefaultPlans(time.Now())...)
+	subs := memory.NewSubscriptionRepository(plans, memory.NewOutbox())
+	sub, err := subs.CreateTrial(ctx, "academy-1", starterPlanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewTrialHandler(service.NewTrialService(subs, plans, nil), "admin-token")
+
+	extend := func(token, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/subscriptions/trial-extension", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.Extend(rec, req)
+		return rec.Code
+	}
+
+	body := `{"subscription_id":"` + sub.ID + `","days":7,"reason":"onboarding atrasado","actor":"suporte@blackbelt"}`
+	if code := extend("", body); code != http.StatusUnauthorized {
+		t.Errorf("sem token = %d, want 401", code)
+	}
+	if code := extend("admin-token", `{"subscription_id":"`+sub.ID+`","days":7}`); code != http.StatusBadRequest {
+		t.Errorf("sem motivo e ator = %d, want 400", code)
+	}
+	if code := extend("admin-token", body); code != http.StatusOK {
+		t.Fatalf("extensão = %d, want 200", code)
+	}
+	got, _ := subs.GetByID(ctx, sub.ID)
+	if len(got.TrialExtensions) != 1 || got.TrialExtensions[0].Actor != "suporte@blackbelt" {
+		t.Errorf("extensões = %+v, want uma do suporte@blackbelt", got.TrialExtensions)
+	}
+
+	// Sem ADMIN_API_TOKEN a rota não existe
+	handler = NewTrialHandler(handler.trials, "")
+	if code := extend("", body); code != http.StatusNotFound {
+		t.Errorf("sem token configurado = %d, want 404", code)
+	}
+}
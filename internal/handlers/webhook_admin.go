@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/magnani/black-belt-app/backend/internal/service"
+)
+
+// maxWebhookPayload limita o payload editado pela administração
+const maxWebhookPayload = 1 << 20
+
+// WebhookAdminHandler expõe a dead-letter de webhooks para inspeção, edição e
+// replay. Exige o token administrativo; sem token configurado as rotas
+// respondem 404, como se não existissem.
+type WebhookAdminHandler struct {
+	inbox *service.WebhookInbox
+	token string
+}
+
+// NewWebhookAdminHandler cria o handler administrativo de webhooks
+func NewWebhookAdminHandler(inbox *service.WebhookInbox, token string) *WebhookAdminHandler {
+	return &WebhookAdminHandler{inbox: inbox, token: token}
+}
+
+// ServeHTTP roteia as operações da dead-letter
+// Endpoints:
+//
+//	GET  /api/admin/webhooks/dead-letter?limit=&offset=
+//	GET  /api/admin/webhooks/{id}
+//	PUT  /api/admin/webhooks/{id}/payload
+//	POST /api/admin/webhooks/{id}/replay
+func (h *WebhookAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !adminAuthorized(r, h.token) {
+		http.Error(w, "Não autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/webhooks"), "/")
+	id, action, _ := strings.Cut(path, "/")
+	switch {
+	case id == "dead-letter" && action == "" && r.Method == http.MethodGet:
+		h.list(w, r)
+	case id == "" || id == "dead-letter" || strings.Contains(action, "/"):
+		http.NotFound(w, r)
+	case action == "" && r.Method == http.MethodGet:
+		event, err := h.inbox.Get(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, event)
+	case action == "payload" && r.Method == http.MethodPut:
+		h.editPayload(w, r, id)
+	case action == "replay" && r.Method == http.MethodPost:
+		event, err := h.inbox.Replay(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, event)
+	case action == "" || action == "payload" || action == "replay":
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// list lista a dead-letter, dos eventos mais antigos aos mais novos
+func (h *WebhookAdminHandler) list(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, err := h.inbox.DeadLetters(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// editPayload substitui o payload de um evento na dead-letter pelo corpo da requisição
+func (h *WebhookAdminHandler) editPayload(w http.ResponseWriter, r *http.Request, id string) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(w, "Erro ao ler requisição", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.inbox.EditPayload(r.Context(), id, json.RawMessage(payload))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, event)
+}
@@ -2,39 +2,35 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"log"
 	"net/http"
 
+	"github.com/magnani/black-belt-app/backend/internal/domain"
 	"github.com/magnani/black-belt-app/backend/internal/ports"
+	"github.com/magnani/black-belt-app/backend/internal/service"
 )
 
-// WebhookHandler gerencia webhooks recebidos de provedores de pagamento
+// WebhookHandler gerencia webhooks recebidos de provedores de pagamento.
+// O webhook é só validado e gravado no inbox; o processamento é assíncrono.
 type WebhookHandler struct {
-	paymentProvider ports.PaymentProvider
+	paymentProvider ports.PixProvider
 	webhookSecret   string
-	eventHandlers   map[string]WebhookEventHandler
+	inbox           ports.WebhookInbox
 }
 
-// WebhookEventHandler é uma função que processa um tipo específico de evento
-type WebhookEventHandler func(event *ports.WebhookEvent) error
-
 // NewWebhookHandler cria um novo handler de webhooks
-func NewWebhookHandler(provider ports.PaymentProvider, secret string) *WebhookHandler {
+func NewWebhookHandler(provider ports.PixProvider, secret string, inbox ports.WebhookInbox) *WebhookHandler {
 	return &WebhookHandler{
 		paymentProvider: provider,
 		webhookSecret:   secret,
-		eventHandlers:   make(map[string]WebhookEventHandler),
+		inbox:           inbox,
 	}
 }
 
-// RegisterHandler registra um handler para um tipo de evento
-func (wh *WebhookHandler) RegisterHandler(eventType string, handler WebhookEventHandler) {
-	wh.eventHandlers[eventType] = handler
-}
-
-// HandleEfiWebhook processa webhooks da Efí Bank
+// HandleEfiWebhook recebe webhooks da Efí Bank
 // Endpoint: POST /api/webhooks/efi
 func (wh *WebhookHandler) HandleEfiWebhook(w http.ResponseWriter, r *http.Request) {
 	// Apenas POST é permitido
@@ -58,57 +54,79 @@ func (wh *WebhookHandler) HandleEfiWebhook(w http.ResponseWriter, r *http.Reques
 	// Obtém a assinatura do header (se existir)
 	signature := r.Header.Get("X-Webhook-Signature")
 
-	// Valida e parseia o webhook
-	event, err := wh.paymentProvider.ParseWebhookEvent(body, signature)
+	// Valida a assinatura
+	if !wh.paymentProvider.ValidateWebhookSignature(body, signature) {
+		log.Printf("[Webhook] Assinatura inválida")
+		http.Error(w, "Assinatura inválida", http.StatusUnauthorized)
+		return
+	}
+
+	// Parseia o webhook (o event_id vem do e2eid / idRec)
+	event, err := wh.paymentProvider.ParseWebhookEvent(body)
 	if err != nil {
 		log.Printf("[Webhook] Erro ao processar: %v", err)
 		http.Error(w, "Erro ao processar webhook", http.StatusBadRequest)
 		return
 	}
+	event.Signature = signature
+	event.Headers = webhookHeaders(r)
 
-	// Roteia para o handler apropriado
-	if handler, ok := wh.eventHandlers[event.Type]; ok {
-		if err := handler(event); err != nil {
-			log.Printf("[Webhook] Erro no handler '%s': %v", event.Type, err)
-			// Retornamos 200 mesmo assim para evitar retentativas
-			// O erro já foi logado e pode ser tratado posteriormente
-		}
-	} else {
-		log.Printf("[Webhook] Tipo de evento não tratado: %s", event.Type)
+	// Grava antes de confirmar: se falhar, o gateway reenvia
+	stored, err := wh.inbox.Receive(r.Context(), event)
+	if err != nil {
+		log.Printf("[Webhook] Erro ao armazenar %s/%s: %v", event.Gateway, event.EventID, err)
+		http.Error(w, "Erro ao armazenar webhook", http.StatusServiceUnavailable)
+		return
 	}
 
 	// Retorna 200 OK para confirmar recebimento
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "received"})
+	json.NewEncoder(w).Encode(map[string]string{"status": "received", "event_id": stored.EventID})
 }
 
-// HandlePixReceived é um exemplo de handler para pagamentos PIX recebidos
-func HandlePixReceived(event *ports.WebhookEvent) error {
-	txid, ok := event.Data["txid"].(string)
-	if !ok {
-		log.Printf("[PIX] Evento sem txid válido")
-		return nil
+// webhookHeaders guarda os headers úteis para auditoria (sem credenciais)
+func webhookHeaders(r *http.Request) json.RawMessage {
+	headers := map[string]string{}
+	for _, name := range []string{"Content-Type", "User-Agent", "X-Webhook-Signature", "X-Forwarded-For"} {
+		if v := r.Header.Get(name); v != "" {
+			headers[name] = v
+		}
 	}
+	data, _ := json.Marshal(headers)
+	return data
+}
 
-	valor, _ := event.Data["valor"].(string)
-	e2eID, _ := event.Data["endToEndId"].(string)
-
-	log.Printf("[PIX] Pagamento recebido! TxID: %s, Valor: R$ %s, E2E: %s", txid, valor, e2eID)
-
-	// TODO: Implementar lógica de negócio
-	// 1. Buscar a cobrança pelo txid
-	// 2. Atualizar status do pagamento no banco
-	// 3. Ativar/renovar assinatura do usuário
-	// 4. Enviar confirmação por email/push
-
-	return nil
+// HandleCharges cria o handler das cobranças notificadas pelo gateway: pagas
+// convertem o trial ou renovam a assinatura, falhas entram na régua de cobrança.
+// parse extrai as cobranças do payload (ex: efi.ParseChargeNotifications).
+func HandleCharges(billing *service.BillingService, parse func(payload []byte) ([]ports.ChargeNotification, error)) service.WebhookEventHandler {
+	return func(ctx context.Context, event *domain.WebhookEvent) error {
+		notifications, err := parse(event.Payload)
+		if err != nil {
+			return err
+		}
+		for i := range notifications {
+			if err := billing.HandleCharge(ctx, &notifications[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 }
 
-// HealthCheck endpoint para verificar se o servidor está funcionando
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-		"service": "blackbelt-api",
-	})
+// HandleRefunds cria o handler das devoluções notificadas pelo gateway.
+// parse extrai as devoluções do payload (ex: efi.ParseRefundNotifications).
+func HandleRefunds(refunds *service.RefundService, parse func(payload []byte) ([]ports.RefundNotification, error)) service.WebhookEventHandler {
+	return func(ctx context.Context, event *domain.WebhookEvent) error {
+		notifications, err := parse(event.Payload)
+		if err != nil {
+			return err
+		}
+		for i := range notifications {
+			if err := refunds.HandleRefundNotification(ctx, &notifications[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 }
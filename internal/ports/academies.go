@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// AcademyService define o acesso às academias. Os repositórios também
+// implementam BrandingService e FiscalDataService a partir da academia.
+type AcademyService interface {
+	// Create grava uma academia nova (ID preenchido) e vincula o perfil do
+	// dono a ela. Slug ou código de convite repetido retorna domain.ErrAlreadyExists.
+	Create(ctx context.Context, academy *domain.Academy) error
+
+	// GetByID busca academia pelo ID
+	GetByID(ctx context.Context, academyID string) (*domain.Academy, error)
+
+	// GetBySlug busca academia pelo slug
+	GetBySlug(ctx context.Context, slug string) (*domain.Academy, error)
+
+	// GetByInviteCode busca academia pelo código de convite
+	GetByInviteCode(ctx context.Context, inviteCode string) (*domain.Academy, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// AuditLog guarda os registros de auditoria, append-only e encadeados por
+// academia (ver domain.AuditEntry). Não existe alteração nem remoção.
+type AuditLog interface {
+	// Append encadeia o registro após o último da academia (entry.Chain) e o
+	// grava; appends simultâneos da mesma academia são serializados
+	Append(ctx context.Context, entry *domain.AuditEntry) error
+
+	// List lista os registros da academia com Seq > afterSeq, em ordem de Seq;
+	// limit 0 = todos
+	List(ctx context.Context, academyID string, afterSeq int64, limit int) ([]*domain.AuditEntry, error)
+}
@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// ErrInvalidToken indica um token ausente, malformado, com assinatura inválida
+// ou expirado
+var ErrInvalidToken = errors.New("token inválido")
+
+// TokenClaims são as claims de um JWT do Supabase já verificado
+type TokenClaims struct {
+	Subject   string // auth.users.id
+	Email     string
+	Role      string // Papel do Postgres no Supabase ("authenticated"), não o da academia
+	SessionID string
+	ExpiresAt time.Time
+}
+
+// TokenVerifier verifica tokens de acesso
+type TokenVerifier interface {
+	// Verify valida assinatura, expiração, audiência e emissor do token.
+	// Qualquer falha retorna um erro que embrulha ErrInvalidToken.
+	Verify(ctx context.Context, token string) (*TokenClaims, error)
+}
+
+// ProfileService define o acesso aos perfis dos usuários
+type ProfileService interface {
+	// GetByID busca o perfil do usuário (domain.ErrNotFound se não existir)
+	GetByID(ctx context.Context, userID string) (*domain.Profile, error)
+}
@@ -0,0 +1,47 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// OutboxMessage é um evento de domínio gravado no outbox aguardando entrega
+type OutboxMessage struct {
+	Sequence      int64 // Ordem de gravação (define a ordem de entrega por agregado)
+	Event         domain.DomainEvent
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	DispatchedAt  *time.Time
+	DeliveredTo   []string // Subscribers que já receberam o evento (pulados nas retentativas)
+}
+
+// Outbox armazena os eventos de domínio até o dispatcher entregá-los.
+// Os repositórios chamam Append dentro da transação que persiste a entidade:
+// o evento existe se, e somente se, a mudança de estado foi gravada.
+type Outbox interface {
+	// Append grava eventos (normalmente o resultado de entity.PullEvents())
+	Append(ctx context.Context, events ...domain.DomainEvent) error
+
+	// ListPending lista mensagens não entregues em ordem de Sequence,
+	// incluindo as que aguardam retentativa (o dispatcher respeita NextAttemptAt)
+	ListPending(ctx context.Context, limit int) ([]*OutboxMessage, error)
+
+	// MarkDispatched marca a mensagem como entregue a todos os subscribers
+	MarkDispatched(ctx context.Context, sequence int64, at time.Time) error
+
+	// MarkDelivered registra que o subscriber recebeu a mensagem (idempotente)
+	MarkDelivered(ctx context.Context, sequence int64, subscriber string) error
+
+	// MarkFailed registra uma falha de entrega e agenda a próxima tentativa
+	MarkFailed(ctx context.Context, sequence int64, reason string, nextAttemptAt time.Time) error
+}
+
+// EventPublisher envia eventos de domínio para fora da aplicação (webhooks
+// de saída). Pode receber o mesmo evento mais de uma vez: o destino
+// deduplica pelo ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.DomainEvent) error
+}
@@ -0,0 +1,65 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// NFSeIssueRequest é o RPS enviado à prefeitura. Os dados do prestador
+// (CNPJ, inscrição municipal, item da lista de serviço) são configuração do provider.
+type NFSeIssueRequest struct {
+	RPSNumber   int64
+	RPSSeries   string
+	IssueDate   time.Time
+	Competence  time.Time
+	Amount      int // centavos
+	Description string
+	Tomador     domain.FiscalParty
+}
+
+// NFSeIssueResult é a NFS-e autorizada pela prefeitura
+type NFSeIssueResult struct {
+	Number           string
+	VerificationCode string
+	XML              []byte
+}
+
+// NFSeCancelRequest pede o cancelamento de uma NFS-e emitida
+type NFSeCancelRequest struct {
+	Number string
+	Code   domain.NFSeCancelCode
+}
+
+// FiscalProvider emite e cancela NFS-e no webservice do município
+type FiscalProvider interface {
+	// IssueNFSe envia o RPS e retorna a NFS-e gerada. Reenviar o mesmo RPS
+	// não pode gerar uma segunda nota.
+	IssueNFSe(ctx context.Context, req *NFSeIssueRequest) (*NFSeIssueResult, error)
+
+	// CancelNFSe cancela uma NFS-e emitida
+	CancelNFSe(ctx context.Context, req *NFSeCancelRequest) error
+}
+
+// NFSeService persiste as NFS-e
+type NFSeService interface {
+	// Create persiste a NFS-e atribuindo o próximo número de RPS da série
+	// (sequencial, na mesma transação do INSERT)
+	Create(ctx context.Context, nfse *domain.NFSe) error
+
+	// GetByPaymentID obtém a NFS-e de um pagamento (domain.ErrNotFound se não houver)
+	GetByPaymentID(ctx context.Context, paymentID string) (*domain.NFSe, error)
+
+	// ListByStatus lista NFS-e em um status (retentativas)
+	ListByStatus(ctx context.Context, status domain.NFSeStatus, limit int) ([]*domain.NFSe, error)
+
+	// Save persiste as alterações de uma NFS-e existente
+	Save(ctx context.Context, nfse *domain.NFSe) error
+}
+
+// FiscalDataService fornece os dados fiscais das academias (tomadores)
+type FiscalDataService interface {
+	// GetFiscalParty obtém documento, razão social e endereço fiscal da academia
+	GetFiscalParty(ctx context.Context, academyID string) (*domain.FiscalParty, error)
+}
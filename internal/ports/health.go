@@ -0,0 +1,21 @@
+package ports
+
+import "time"
+
+// CircuitBreakerStatus descreve o estado de um circuit breaker de gateway
+type CircuitBreakerStatus struct {
+	Family              string     `json:"family"`               // Família de endpoints (ex: "cob", "rec")
+	State               string     `json:"state"`                // "closed" | "open" | "half_open"
+	ConsecutiveFailures int        `json:"consecutive_failures"` // Falhas consecutivas registradas
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`  // Quando o circuito abriu
+	RetryAt             *time.Time `json:"retry_at,omitempty"`   // Quando a próxima sondagem será permitida
+}
+
+// GatewayHealthReporter é implementado por adaptadores que expõem o estado dos seus circuit breakers
+type GatewayHealthReporter interface {
+	// GatewayName retorna o nome do gateway (ex: "efi")
+	GatewayName() string
+
+	// BreakerStatus retorna o estado de cada circuit breaker do gateway
+	BreakerStatus() []CircuitBreakerStatus
+}
@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// InvoiceService persiste as faturas das academias
+type InvoiceService interface {
+	// Create persiste a fatura atribuindo o próximo número da academia na mesma
+	// transação (contador por academia com lock de linha: sem lacunas nem repetição)
+	Create(ctx context.Context, invoice *domain.Invoice) error
+
+	// GetByID obtém uma fatura pelo ID
+	GetByID(ctx context.Context, invoiceID string) (*domain.Invoice, error)
+
+	// GetByPaymentID obtém a fatura de um pagamento (domain.ErrNotFound se não houver)
+	GetByPaymentID(ctx context.Context, paymentID string) (*domain.Invoice, error)
+
+	// ListByAcademy lista as faturas de uma academia, da mais recente para a mais antiga
+	ListByAcademy(ctx context.Context, academyID string) ([]*domain.Invoice, error)
+
+	// Save persiste alterações de status de uma fatura existente
+	Save(ctx context.Context, invoice *domain.Invoice) error
+}
+
+// BrandingService fornece os dados visuais da academia para documentos
+type BrandingService interface {
+	// GetBranding obtém nome, logo e cor da academia
+	GetBranding(ctx context.Context, academyID string) (*domain.Branding, error)
+}
+
+// InvoiceRenderer gera os documentos das faturas
+type InvoiceRenderer interface {
+	// RenderHTML gera a fatura em HTML
+	RenderHTML(ctx context.Context, invoice *domain.Invoice, branding *domain.Branding) ([]byte, error)
+
+	// RenderPDF gera a fatura em PDF
+	RenderPDF(ctx context.Context, invoice *domain.Invoice, branding *domain.Branding) ([]byte, error)
+}
@@ -0,0 +1,32 @@
+package ports
+
+import "context"
+
+// NotificationType identifica o tipo de notificação enviada ao dono da academia
+type NotificationType string
+
+const (
+	NotificationPaymentFailed    NotificationType = "payment_failed"    // Cobrança recusada
+	NotificationDunningReminder  NotificationType = "dunning_reminder"  // Lembrete/alerta durante o grace period
+	NotificationDunningRetry     NotificationType = "dunning_retry"     // Nova cobrança PIX gerada para pagamento
+	NotificationDunningFinal     NotificationType = "dunning_final"     // Assinatura cancelada/suspensa por inadimplência
+	NotificationPaymentRecovered NotificationType = "payment_recovered" // Pagamento em atraso recebido
+
+	NotificationTrialStarted         NotificationType = "trial_started"         // Boas-vindas ao trial
+	NotificationSubscriptionCanceled NotificationType = "subscription_canceled" // Assinatura cancelada
+	NotificationPaymentRefunded      NotificationType = "payment_refunded"      // Pagamento estornado
+)
+
+// Notification representa uma notificação (email/push) para uma academia
+type Notification struct {
+	AcademyID string
+	Type      NotificationType
+	Level     string            // Nível de escalonamento (ex: "reminder", "warning", "final")
+	Data      map[string]string // Dados para o template (valor, pix copia e cola, datas)
+}
+
+// Notifier envia notificações para os donos das academias
+type Notifier interface {
+	// Notify envia uma notificação
+	Notify(ctx context.Context, notification *Notification) error
+}
@@ -5,10 +5,31 @@ package ports
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/magnani/black-belt-app/backend/internal/domain"
 )
 
+// ErrGatewayUnavailable indica que o gateway de pagamento está indisponível
+// (ex: circuit breaker aberto). A operação pode ser retentada mais tarde.
+var ErrGatewayUnavailable = errors.New("gateway de pagamento indisponível")
+
+// ErrGatewayRejected indica que o gateway recusou a requisição de forma definitiva
+// (4xx): nada foi processado. Timeouts, 5xx e ErrGatewayUnavailable não têm
+// resposta definitiva e não envolvem este erro.
+var ErrGatewayRejected = errors.New("requisição recusada pelo gateway de pagamento")
+
+// ErrWebhookSkipped é devolvido por quem processa um webhook quando o evento
+// não interessa à aplicação: o evento fica como skipped, sem retry
+var ErrWebhookSkipped = errors.New("webhook ignorado")
+
+// RetryAfterError é implementado por erros que sabem quando a operação pode ser retentada
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
 // ──────────────────────────────────────────────
 // PIX (Efí Bank) types
 // ──────────────────────────────────────────────
@@ -37,9 +58,12 @@ type PixChargeResponse struct {
 // PixRecurrenceSetupRequest configura PIX Automático recorrente
 type PixRecurrenceSetupRequest struct {
 	AcademyID    string
+	PlanID       string // Plano escolhido (conclui o checkout de uma configuração adiada)
 	CustomerCPF  string
 	CustomerName string
-	Amount       int64  // Valor em centavos
+	Amount       int64                  // Valor em centavos
+	Interval     domain.BillingInterval // Periodicidade (padrão: mensal)
+	StartDate    time.Time              // Primeira cobrança (zero = hoje; fim do trial se não houver conversão antecipada)
 	Description  string
 }
 
@@ -47,6 +71,65 @@ type PixRecurrenceSetupRequest struct {
 type PixRecurrenceSetupResponse struct {
 	AuthorizationID string // ID da autorização
 	RecurrenceID    string // ID da recorrência configurada
+	Location        string // Location do payload da autorização
+	PixCode         string // Copia e cola (conteúdo do QR Code) que o pagador autoriza no app do banco
+}
+
+// PixRefundRequest solicita a devolução (total ou parcial) de um PIX recebido
+type PixRefundRequest struct {
+	EndToEndID string // e2eId do PIX recebido
+	RefundID   string // ID da devolução (o mesmo ID não gera uma segunda devolução)
+	Amount     int64  // Valor em centavos
+	Reason     string // Descrição enviada ao pagador (opcional)
+}
+
+// RefundResult é a resposta do gateway a uma solicitação de devolução
+type RefundResult struct {
+	GatewayRefundID string              // rtrId (PIX) ou re_xxx (Stripe)
+	Status          domain.RefundStatus // pending até a liquidação, salvo devolução imediata
+}
+
+// ──────────────────────────────────────────────
+// Degraded mode types
+// ──────────────────────────────────────────────
+
+// PendingChargeKind identifica a operação de cobrança adiada
+type PendingChargeKind string
+
+const (
+	PendingChargeKindPixCharge     PendingChargeKind = "pix_charge"     // Cobrança PIX imediata
+	PendingChargeKindPixRecurrence PendingChargeKind = "pix_recurrence" // Configuração de PIX Automático
+)
+
+// PendingChargeStatus representa o estado de uma cobrança adiada
+type PendingChargeStatus string
+
+const (
+	PendingChargeStatusQueued    PendingChargeStatus = "queued"
+	PendingChargeStatusCompleted PendingChargeStatus = "completed"
+	PendingChargeStatusFailed    PendingChargeStatus = "failed"
+)
+
+// PendingCharge representa uma cobrança enfileirada enquanto o gateway está indisponível
+type PendingCharge struct {
+	ID        string
+	AcademyID string
+	Kind      PendingChargeKind
+	Status    PendingChargeStatus
+
+	// Requisição original (apenas uma é preenchida, conforme Kind)
+	Charge     *PixChargeRequest
+	Recurrence *PixRecurrenceSetupRequest
+
+	// Resultado após o processamento
+	ChargeResult     *PixChargeResponse
+	RecurrenceResult *PixRecurrenceSetupResponse
+
+	// Controle de tentativas
+	Attempts      int
+	LastError     string
+	EnqueuedAt    time.Time
+	NextAttemptAt time.Time
 }
 
 // ──────────────────────────────────────────────
@@ -63,6 +146,29 @@ type IncomingWebhookEvent struct {
 	Signature string          // Assinatura para validação
 }
 
+// RefundNotification é a situação de uma devolução informada por webhook
+type RefundNotification struct {
+	GatewayPaymentID string              // txid (PIX) ou payment_intent (Stripe) do pagamento devolvido
+	RefundID         string              // ID da devolução (o enviado na solicitação, ou criado no painel do gateway)
+	GatewayRefundID  string              // rtrId (PIX) ou re_xxx (Stripe)
+	Amount           int                 // Valor devolvido em centavos
+	Status           domain.RefundStatus // Situação informada pelo gateway
+	FailureReason    string              // Motivo quando a devolução não foi realizada
+}
+
+// ChargeNotification é o resultado de uma cobrança informado por webhook:
+// cobrança recorrente (PIX Automático, Stripe) ou PIX avulso de retentativa
+type ChargeNotification struct {
+	Gateway          domain.PaymentGateway
+	SubscriptionRef  string // idRec (PIX Automático) ou sub_xxx (Stripe); vazio em cobranças avulsas
+	GatewayPaymentID string // txid (PIX) ou payment_intent (Stripe)
+	EndToEndID       string // e2eId da liquidação PIX
+	Amount           int    // Valor em centavos
+	Paid             bool   // false: cobrança não realizada
+	FailureReason    string
+	FailureCode      string // Ex: AM04 (saldo insuficiente)
+}
+
 // ──────────────────────────────────────────────
 // Provider interfaces
 // ──────────────────────────────────────────────
@@ -78,8 +184,8 @@ type PixProvider interface {
 	// CancelPixCharge cancela uma cobrança PIX pendente
 	CancelPixCharge(ctx context.Context, txid string) error
 
-	// RefundPix solicita devolução de um PIX recebido
-	RefundPix(ctx context.Context, e2eID string, amount int64) error
+	// RefundPix solicita devolução (total ou parcial) de um PIX recebido
+	RefundPix(ctx context.Context, req *PixRefundRequest) (*RefundResult, error)
 
 	// SetupRecurrence configura PIX Automático recorrente
 	SetupRecurrence(ctx context.Context, req *PixRecurrenceSetupRequest) (*PixRecurrenceSetupResponse, error)
@@ -87,6 +193,19 @@ type PixProvider interface {
 	// CancelRecurrence cancela PIX Automático
 	CancelRecurrence(ctx context.Context, authorizationID string) error
 
+	// UpdateRecurrenceAmount altera o valor das próximas cobranças do PIX Automático
+	UpdateRecurrenceAmount(ctx context.Context, recurrenceID string, amount int64) error
+
+	// RescheduleRecurrence move o próximo vencimento do PIX Automático para nextDueDate
+	RescheduleRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error
+
+	// PauseRecurrence suspende as cobranças do PIX Automático sem revogar a autorização
+	PauseRecurrence(ctx context.Context, recurrenceID string) error
+
+	// ResumeRecurrence retoma as cobranças de uma recorrência pausada, movendo
+	// o próximo vencimento para nextDueDate (zero = mantém o calendário)
+	ResumeRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error
+
 	// RegisterWebhook registra a URL de webhook para receber notificações PIX
 	RegisterWebhook(ctx context.Context, pixKey string, webhookURL string) error
 
@@ -106,13 +225,51 @@ type StripeProvider interface {
 	CreateSubscription(ctx context.Context, customerID, priceID string) (subscriptionID string, clientSecret string, err error)
 
 	// CancelSubscription cancela uma subscription no Stripe
+	// (uma subscription já cancelada não é erro)
 	CancelSubscription(ctx context.Context, subscriptionID string, atPeriodEnd bool) error
 
+	// ReactivateSubscription desfaz um cancelamento agendado (cancel_at_period_end=false)
+	ReactivateSubscription(ctx context.Context, subscriptionID string) error
+
+	// UpdateSubscriptionPrice troca o price da subscription (prorate: Stripe cobra a diferença agora)
+	UpdateSubscriptionPrice(ctx context.Context, subscriptionID, priceID string, prorate bool) error
+
+	// ApplyCoupon aplica um cupom do Stripe à subscription
+	ApplyCoupon(ctx context.Context, subscriptionID, couponID string) error
+
+	// PauseSubscription pausa a cobrança da subscription até resumeAt (pause_collection)
+	PauseSubscription(ctx context.Context, subscriptionID string, resumeAt time.Time) error
+
+	// ResumeSubscription retoma a cobrança de uma subscription pausada
+	ResumeSubscription(ctx context.Context, subscriptionID string) error
+
+	// RefundPayment devolve amount centavos de um payment_intent (refundID é a idempotency key)
+	RefundPayment(ctx context.Context, paymentIntentID, refundID string, amount int64) (*RefundResult, error)
+
 	// ValidateWebhookSignature valida a assinatura de um webhook Stripe
 	ValidateWebhookSignature(payload []byte, signature string) bool
 
 	// ParseWebhookEvent processa o payload de um webhook Stripe
 	ParseWebhookEvent(payload []byte) (*IncomingWebhookEvent, error)
+
+	// ParseChargeNotifications extrai o resultado das cobranças de um webhook
+	// (invoice.paid / invoice.payment_failed)
+	ParseChargeNotifications(payload []byte) ([]ChargeNotification, error)
+}
+
+// ChargeQueue armazena cobranças adiadas durante o modo degradado
+type ChargeQueue interface {
+	// Enqueue adiciona uma cobrança à fila (gera o ID se vazio)
+	Enqueue(ctx context.Context, charge *PendingCharge) error
+
+	// Get busca uma cobrança adiada pelo ID
+	Get(ctx context.Context, id string) (*PendingCharge, error)
+
+	// ListDue lista cobranças enfileiradas cuja próxima tentativa já venceu
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*PendingCharge, error)
+
+	// Update persiste alterações de status/tentativas de uma cobrança
+	Update(ctx context.Context, charge *PendingCharge) error
 }
 
 // ──────────────────────────────────────────────
@@ -133,21 +290,52 @@ type SubscriptionService interface {
 	// ChangePlan altera o plano de uma assinatura
 	ChangePlan(ctx context.Context, subscriptionID, newPlanID string) (*domain.Subscription, error)
 
+	// Create persiste uma nova assinatura (gera o ID se vazio) e grava
+	// sub.PullEvents() no Outbox na mesma transação; domain.ErrAlreadyExists
+	// se a academia já tem assinatura
+	Create(ctx context.Context, sub *domain.Subscription) error
+
+	// GetByID obtém uma assinatura pelo ID
+	GetByID(ctx context.Context, subscriptionID string) (*domain.Subscription, error)
+
 	// GetByAcademy obtém a assinatura de uma academia
 	GetByAcademy(ctx context.Context, academyID string) (*domain.Subscription, error)
 
+	// ListByStatus lista assinaturas em um status (jobs periódicos)
+	ListByStatus(ctx context.Context, status domain.SubscriptionStatus, limit int) ([]*domain.Subscription, error)
+
+	// ListCancellationsDue lista assinaturas com cancel_at_period_end cujo período terminou até now
+	// e as canceladas com gateway_cancel_pending (recorrência ainda não encerrada no gateway)
+	ListCancellationsDue(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error)
+
+	// Save persiste as alterações de uma assinatura existente e grava
+	// sub.PullEvents() no Outbox na mesma transação. Compara sub.Version com a
+	// gravada (domain.ErrConcurrentModification se outra escrita veio antes) e
+	// a incrementa.
+	Save(ctx context.Context, sub *domain.Subscription) error
+
 	// GetByStripeSubscriptionID obtém por ID da subscription no Stripe
 	GetByStripeSubscriptionID(ctx context.Context, stripeSubID string) (*domain.Subscription, error)
 
+	// GetByPixRecurrenceID obtém pelo idRec do PIX Automático
+	GetByPixRecurrenceID(ctx context.Context, recurrenceID string) (*domain.Subscription, error)
+
 	// ExpireTrials expira trials vencidos (job periódico)
 	ExpireTrials(ctx context.Context) (int, error)
 }
 
 // PaymentService define operações de pagamento
 type PaymentService interface {
-	// RecordPayment registra um pagamento no histórico
+	// RecordPayment registra um pagamento no histórico (eventos vão para o Outbox na mesma transação)
 	RecordPayment(ctx context.Context, payment *domain.PaymentHistory) error
 
+	// UpdatePayment persiste mudanças de status de um pagamento existente (idem RecordPayment);
+	// como SubscriptionService.Save, falha com domain.ErrConcurrentModification se a versão mudou
+	UpdatePayment(ctx context.Context, payment *domain.PaymentHistory) error
+
+	// GetByID busca pagamento pelo ID
+	GetByID(ctx context.Context, paymentID string) (*domain.PaymentHistory, error)
+
 	// GetByGatewayPaymentID busca pagamento pelo ID do gateway
 	GetByGatewayPaymentID(ctx context.Context, gatewayPaymentID string) (*domain.PaymentHistory, error)
 
@@ -156,6 +344,9 @@ type PaymentService interface {
 
 	// ListBySubscription lista pagamentos de uma assinatura
 	ListBySubscription(ctx context.Context, subscriptionID string) ([]*domain.PaymentHistory, error)
+
+	// ListWithPendingRefunds lista pagamentos com devoluções pendentes solicitadas até requestedBefore
+	ListWithPendingRefunds(ctx context.Context, requestedBefore time.Time) ([]*domain.PaymentHistory, error)
 }
 
 // PlanService define operações de planos
@@ -170,20 +361,62 @@ type PlanService interface {
 	GetByID(ctx context.Context, id string) (*domain.SubscriptionPlan, error)
 }
 
-// WebhookService define operações de webhook (auditoria e processamento)
+// CouponService define operações de cupons
+type CouponService interface {
+	// Create cria um cupom
+	Create(ctx context.Context, coupon *domain.Coupon) error
+
+	// GetByCode busca cupom pelo código (já normalizado)
+	GetByCode(ctx context.Context, code string) (*domain.Coupon, error)
+
+	// Redeem incrementa TimesRedeemed de forma atômica, falhando com
+	// domain.ErrCouponInvalid se MaxRedemptions já foi atingido
+	Redeem(ctx context.Context, couponID string) error
+
+	// Release devolve um resgate de Redeem (aplicação desfeita)
+	Release(ctx context.Context, couponID string) error
+}
+
+// TrialCampaignService define operações de campanhas de trial
+type TrialCampaignService interface {
+	// GetByCode busca campanha pelo código (já normalizado)
+	GetByCode(ctx context.Context, code string) (*domain.TrialCampaign, error)
+}
+
+// WebhookService define operações de webhook (auditoria e processamento).
+// Um evento é identificado pelo par gateway + event_id.
 type WebhookService interface {
-	// Store armazena um evento de webhook recebido
+	// Store armazena um evento de webhook recebido; uma reentrega do mesmo
+	// gateway + event_id não é duplicada e o evento volta com o registro existente
 	Store(ctx context.Context, event *domain.WebhookEvent) error
 
 	// Process processa um evento de webhook (idempotente)
-	Process(ctx context.Context, eventID string) error
+	Process(ctx context.Context, gateway, eventID string) error
 
-	// GetByEventID busca webhook pelo event_id do gateway
-	GetByEventID(ctx context.Context, eventID string) (*domain.WebhookEvent, error)
+	// GetByEventID busca webhook pelo gateway e event_id
+	GetByEventID(ctx context.Context, gateway, eventID string) (*domain.WebhookEvent, error)
 
-	// RetryFailed retenta webhooks falhados que estão prontos para retry
-	RetryFailed(ctx context.Context) (processed int, err error)
+	// RetryFailed retenta até limit webhooks falhados com retry vencido. Os eventos
+	// são reservados antes do processamento: workers concorrentes não pegam o mesmo.
+	RetryFailed(ctx context.Context, limit int) (processed int, err error)
 
 	// ListPending lista webhooks pendentes de processamento
 	ListPending(ctx context.Context, limit int) ([]*domain.WebhookEvent, error)
+
+	// GetByID busca webhook pelo ID interno
+	GetByID(ctx context.Context, id string) (*domain.WebhookEvent, error)
+
+	// ListByStatus lista webhooks em um status, dos mais antigos aos mais novos
+	ListByStatus(ctx context.Context, status domain.WebhookStatus, limit, offset int) ([]*domain.WebhookEvent, error)
+
+	// Save grava o status, o payload e as retentativas do evento (edição e replay manuais)
+	Save(ctx context.Context, event *domain.WebhookEvent) error
+}
+
+// WebhookInbox recebe webhooks já validados: grava o evento antes de
+// confirmar o recebimento ao gateway e o processa depois, fora do request
+type WebhookInbox interface {
+	// Receive armazena o evento (deduplicando reentregas) e agenda o processamento.
+	// Só depois de Receive sem erro o webhook pode ser confirmado ao gateway.
+	Receive(ctx context.Context, event *IncomingWebhookEvent) (*domain.WebhookEvent, error)
 }
@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// UnitOfWork agrupa operações de vários repositórios em uma transação.
+// Repositórios chamados com o ctx recebido por fn participam dela; se fn
+// devolver erro, nada é gravado.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
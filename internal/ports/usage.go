@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// UsageCounter conta o uso atual dos recursos limitados por plano
+type UsageCounter interface {
+	// CountUsage retorna quantos itens do recurso a academia já possui
+	CountUsage(ctx context.Context, academyID string, resource domain.Resource) (int, error)
+}
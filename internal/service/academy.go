@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// DefaultTrialPlanSlug é o plano do trial quando o cadastro não escolhe outro
+const DefaultTrialPlanSlug = "starter"
+
+// maxAcademyCreateAttempts limita as tentativas com slug ou código de convite repetido
+const maxAcademyCreateAttempts = 10
+
+// CreateAcademyRequest são os dados do cadastro de uma academia
+type CreateAcademyRequest struct {
+	Name         string                `json:"name"`
+	Slug         string                `json:"slug,omitempty"` // Opcional: gerado a partir do nome
+	Description  string                `json:"description,omitempty"`
+	Document     string                `json:"document,omitempty"` // CNPJ ou CPF, para a NFS-e
+	Phone        string                `json:"phone,omitempty"`
+	Email        string                `json:"email,omitempty"`
+	Website      string                `json:"website,omitempty"`
+	Address      domain.AcademyAddress `json:"address"`
+	LogoURL      string                `json:"logo_url,omitempty"`
+	CoverURL     string                `json:"cover_url,omitempty"`
+	PrimaryColor string                `json:"primary_color,omitempty"`
+
+	PlanSlug  string `json:"plan,omitempty"`       // Plano do trial (padrão: starter)
+	TrialCode string `json:"trial_code,omitempty"` // Campanha de trial, opcional
+}
+
+// AcademyCreated é a academia recém-criada com a assinatura em trial
+type AcademyCreated struct {
+	Academy      *domain.Academy      `json:"academy"`
+	Subscription *domain.Subscription `json:"subscription"`
+}
+
+// AcademyService cadastra academias. A assinatura em trial é criada na mesma
+// transação que a academia (antes era o trigger handle_new_academy do banco):
+// academia sem assinatura não existe.
+type AcademyService struct {
+	academies ports.AcademyService
+	trials    *TrialService
+	uow       ports.UnitOfWork
+
+	clocked
+}
+
+// NewAcademyService cria o serviço de academias
+func NewAcademyService(academies ports.AcademyService, trials *TrialService, uow ports.UnitOfWork) *AcademyService {
+	return &AcademyService{
+		academies: academies,
+		trials:    trials,
+		uow:       uow,
+	}
+}
+
+// Create cadastra a academia do dono e inicia o trial. Slug gerado que já
+// existe ganha um sufixo ("-2", "-3"...); slug escolhido pelo dono e já em uso
+// retorna domain.ErrAlreadyExists.
+func (s *AcademyService) Create(ctx context.Context, ownerID string, req *CreateAcademyRequest) (*AcademyCreated, error) {
+	academy, err := domain.NewAcademy(domain.Academy{
+		Name:         req.Name,
+		Slug:         req.Slug,
+		Description:  req.Description,
+		Document:     req.Document,
+		Phone:        req.Phone,
+		Email:        req.Email,
+		Website:      req.Website,
+		Address:      req.Address,
+		LogoURL:      req.LogoURL,
+		CoverURL:     req.CoverURL,
+		PrimaryColor: req.PrimaryColor,
+	}, ownerID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	planSlug := req.PlanSlug
+	if planSlug == "" {
+		planSlug = DefaultTrialPlanSlug
+	}
+
+	baseSlug, suffix := academy.Slug, 1
+	for attempt := 1; ; attempt++ {
+		var sub *domain.Subscription
+		err := s.uow.Do(ctx, func(ctx context.Context) error {
+			if err := s.academies.Create(ctx, academy); err != nil {
+				return err
+			}
+			var err error
+			sub, err = s.trials.StartTrial(ctx, academy.ID, planSlug, req.TrialCode)
+			return err
+		})
+		if err == nil {
+			log.Printf("[Academy] Academia %s (%s) criada com trial no plano %s", academy.ID, academy.Slug, planSlug)
+			return &AcademyCreated{Academy: academy, Subscription: sub}, nil
+		}
+		if !errors.Is(err, domain.ErrAlreadyExists) || attempt == maxAcademyCreateAttempts {
+			return nil, err
+		}
+
+		// A transação foi desfeita: descobre se o repetido foi o slug ou o código
+		if _, lookupErr := s.academies.GetBySlug(ctx, academy.Slug); lookupErr == nil {
+			if req.Slug != "" {
+				return nil, fmt.Errorf("%w: slug %s já está em uso", domain.ErrAlreadyExists, academy.Slug)
+			}
+			suffix++
+		}
+		academy.ID = ""
+		academy.RetryIdentifiers(baseSlug, suffix)
+	}
+}
+
+// Get busca academia pelo ID
+func (s *AcademyService) Get(ctx context.Context, academyID string) (*domain.Academy, error) {
+	return s.academies.GetByID(ctx, academyID)
+}
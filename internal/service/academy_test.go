@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+func newTestAcademyService(t *testing.T) (*AcademyService, *fakeSubscriptions, time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, domain.BillingLocation)
+	starter := domain.NewSubscriptionPlan("Starter", "starter", 9900, now)
+	starter.ID = "plan-starter"
+	pro := domain.NewSubscriptionPlan("Pro", "pro", 19900, now)
+	pro.ID = "plan-pro"
+
+	subs := newFakeSubscriptions()
+	trials := NewTrialService(subs, newFakePlans(starter, pro), nil)
+	trials.SetClock(domain.NewFakeClock(now))
+	svc := NewAcademyService(memory.NewAcademyRepository(nil), trials, memory.NewUnitOfWork())
+	svc.SetClock(domain.NewFakeClock(now))
+	return svc, subs, now
+}
+
+func TestAcademyService_CreateStartsTrial(t *testing.T) {
+	ctx := context.Background()
+	svc, subs, now := newTestAcademyService(t)
+
+	created, err := svc.Create(ctx, "owner-1", &CreateAcademyRequest{Name: "Academia Gracie"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	academy, sub := created.Academy, created.Subscription
+	if academy.ID == "" || academy.Slug != "academia-gracie" || academy.OwnerID != "owner-1" {
+		t.Errorf("academia = %+v", academy)
+	}
+	if sub.AcademyID != academy.ID || sub.PlanID != "plan-starter" || sub.Status != domain.SubscriptionStatusTrialing {
+		t.Errorf("assinatura = %+v, want trial Starter da academia", sub)
+	}
+	if !sub.TrialEndDate.Equal(now.AddDate(0, 0, domain.DefaultTrialDays)) {
+		t.Errorf("TrialEndDate = %v", sub.TrialEndDate)
+	}
+	if _, err := subs.GetByAcademy(ctx, academy.ID); err != nil {
+		t.Errorf("assinatura não persistida: %v", err)
+	}
+
+	got, err := svc.Get(ctx, academy.ID)
+	if err != nil || got.InviteCode != academy.InviteCode {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	created, err = svc.Create(ctx, "owner-2", &CreateAcademyRequest{Name: "Outra", PlanSlug: "pro"})
+	if err != nil || created.Subscription.PlanID != "plan-pro" {
+		t.Errorf("Create() com plano pro = %+v, %v", created, err)
+	}
+}
+
+func TestAcademyService_CreateSlugCollision(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestAcademyService(t)
+
+	want := []string{"academia-gracie", "academia-gracie-2", "academia-gracie-3"}
+	for i, slug := range want {
+		created, err := svc.Create(ctx, "owner", &CreateAcademyRequest{Name: "Academia Gracie"})
+		if err != nil {
+			t.Fatalf("Create() #%d error = %v", i+1, err)
+		}
+		if created.Academy.Slug != slug {
+			t.Errorf("Create() #%d slug = %q, want %q", i+1, created.Academy.Slug, slug)
+		}
+	}
+
+	// Slug escolhido pelo dono não ganha sufixo
+	_, err := svc.Create(ctx, "owner", &CreateAcademyRequest{Name: "Gracie", Slug: "academia-gracie"})
+	if !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("Create() com slug em uso error = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func TestAcademyService_CreateInvalid(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestAcademyService(t)
+
+	tests := []struct {
+		name    string
+		req     *CreateAcademyRequest
+		wantErr error
+	}{
+		{"sem nome", &CreateAcademyRequest{}, nil},
+		{"plano inexistente", &CreateAcademyRequest{Name: "Gracie", PlanSlug: "gold"}, domain.ErrNotFound},
+		{"campanha sem serviço de campanhas", &CreateAcademyRequest{Name: "Gracie", TrialCode: "BF"}, domain.ErrTrialCampaignInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Create(ctx, "owner-1", tt.req)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
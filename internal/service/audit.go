@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// auditIgnoredFields ficam fora dos snapshots: o histórico de transições já
+// está na assinatura e o horário está no registro
+var auditIgnoredFields = []string{"transitions", "updated_at"}
+
+// audited é embutido nos serviços que registram ações no audit log. Sem
+// audit log configurado (SetAuditLog) nada é registrado.
+type audited struct {
+	auditLog ports.AuditLog
+}
+
+// SetAuditLog liga o serviço ao audit log
+func (a *audited) SetAuditLog(auditLog ports.AuditLog) {
+	a.auditLog = auditLog
+}
+
+// audit registra a ação sobre a assinatura ou o pagamento (before é o
+// snapshot de antes da mudança). A ação já foi gravada: uma falha aqui é
+// logada e não a desfaz.
+func (a *audited) audit(ctx context.Context, action domain.AuditAction, before json.RawMessage, aggregate any, now time.Time) {
+	if a.auditLog == nil {
+		return
+	}
+
+	var aggregateType, aggregateID, academyID string
+	switch v := aggregate.(type) {
+	case *domain.Subscription:
+		aggregateType, aggregateID, academyID = domain.AggregateSubscription, v.ID, v.AcademyID
+	case *domain.PaymentHistory:
+		aggregateType, aggregateID, academyID = domain.AggregatePayment, v.ID, v.AcademyID
+	default:
+		log.Printf("[Audit] Agregado não auditável: %T", aggregate)
+		return
+	}
+
+	entry, err := domain.NewAuditEntry(ctx, action, aggregateType, aggregateID, academyID, before, snapshot(aggregate), now)
+	if err == nil {
+		err = a.auditLog.Append(ctx, entry)
+	}
+	if err != nil {
+		log.Printf("[Audit] Erro ao registrar %s em %s %s: %v", action, aggregateType, aggregateID, err)
+	}
+}
+
+// snapshot serializa o agregado para o diff do audit log
+func snapshot(aggregate any) json.RawMessage {
+	data, err := json.Marshal(aggregate)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data
+	}
+	for _, name := range auditIgnoredFields {
+		delete(fields, name)
+	}
+	data, _ = json.Marshal(fields)
+	return data
+}
+
+// AuditVerification é o resultado da verificação da cadeia de uma academia
+type AuditVerification struct {
+	AcademyID string `json:"academy_id"`
+	Entries   int    `json:"entries"`
+	LastHash  string `json:"last_hash,omitempty"`
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error,omitempty"`
+}
+
+// AuditService consulta e verifica o audit log (administração)
+type AuditService struct {
+	auditLog ports.AuditLog
+}
+
+// NewAuditService cria o serviço de consulta do audit log
+func NewAuditService(auditLog ports.AuditLog) *AuditService {
+	return &AuditService{auditLog: auditLog}
+}
+
+// List lista os registros da academia após afterSeq, em ordem
+func (s *AuditService) List(ctx context.Context, academyID string, afterSeq int64, limit int) ([]*domain.AuditEntry, error) {
+	return s.auditLog.List(ctx, academyID, afterSeq, limit)
+}
+
+// Verify recalcula a cadeia inteira da academia. Uma cadeia adulterada não é
+// erro da chamada: vem em Valid/Error.
+func (s *AuditService) Verify(ctx context.Context, academyID string) (*AuditVerification, error) {
+	entries, err := s.auditLog.List(ctx, academyID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar audit log: %w", err)
+	}
+
+	result := &AuditVerification{AcademyID: academyID, Entries: len(entries), Valid: true}
+	if len(entries) > 0 {
+		result.LastHash = entries[len(entries)-1].Hash
+	}
+	if err := domain.VerifyAuditChain(entries); err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+		log.Printf("[Audit] Cadeia da academia %s não confere: %v", academyID, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+func TestCancellationService_AuditLog(t *testing.T) {
+	svc, f := newCancellationFixture(t)
+	sub, clock := f.sub, f.clock
+	auditLog := memory.NewAuditLog()
+	svc.SetAuditLog(auditLog)
+
+	ctx := domain.WithAuditActor(context.Background(), domain.AuditActor{Type: domain.AuditActorUser, ID: "user-1"})
+	ctx = domain.WithRequestID(ctx, "req-1")
+	if _, err := svc.Cancel(ctx, "academy-1", "vai fechar", true, "user-1"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	clock.Set(*sub.CurrentPeriodEnd)
+	if _, err := svc.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	audits := NewAuditService(auditLog)
+	entries, err := audits.List(context.Background(), "academy-1", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("registros = %d, want 2 (agendamento e cancelamento pelo job)", len(entries))
+	}
+
+	scheduled := entries[0]
+	if scheduled.Actor != (domain.AuditActor{Type: domain.AuditActorUser, ID: "user-1"}) || scheduled.RequestID != "req-1" {
+		t.Errorf("agendamento: ator = %+v, request = %q", scheduled.Actor, scheduled.RequestID)
+	}
+	if scheduled.Action != domain.AuditSubscriptionCanceled || scheduled.AggregateID != "sub-1" {
+		t.Errorf("agendamento: action = %s, aggregate = %s", scheduled.Action, scheduled.AggregateID)
+	}
+	var diff map[string]domain.AuditChange
+	if err := json.Unmarshal(scheduled.Diff, &diff); err != nil {
+		t.Fatal(err)
+	}
+	if change, ok := diff["cancel_at_period_end"]; !ok || string(change.After) != "true" {
+		t.Errorf("diff sem cancel_at_period_end: %s", scheduled.Diff)
+	}
+	if _, ok := diff["status"]; ok {
+		t.Errorf("status não muda no agendamento: %s", scheduled.Diff)
+	}
+
+	if entries[1].Actor.Type != domain.AuditActorSystem || entries[1].PrevHash != scheduled.Hash {
+		t.Errorf("cancelamento pelo job: ator = %+v, encadeado = %v", entries[1].Actor, entries[1].PrevHash == scheduled.Hash)
+	}
+
+	result, err := audits.Verify(context.Background(), "academy-1")
+	if err != nil || !result.Valid || result.Entries != 2 || result.LastHash != entries[1].Hash {
+		t.Errorf("Verify() = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// renewalLeadTime é quanto antes do fim do período uma cobrança recorrente paga
+// ainda renova a assinatura. Um pagamento reprocessado depois da renovação cai
+// fora da janela (o período já avançou) e não renova de novo.
+const renewalLeadTime = 3 * 24 * time.Hour
+
+// BillingService concilia com a assinatura as cobranças informadas pelos
+// gateways: a primeira cobrança paga converte o trial (ou o trial expirado),
+// as seguintes renovam o período e fecham o ciclo (pró-rata e cupom). O
+// pagamento é confirmado pelo DunningService, que recupera assinaturas
+// past_due e chama os handlers de fatura e nota; falhas entram na régua.
+type BillingService struct {
+	subscriptions ports.SubscriptionService
+	payments      ports.PaymentService
+	planChanges   *PlanChangeService
+	dunning       *DunningService
+
+	clocked
+	audited
+}
+
+// NewBillingService cria o serviço de conciliação de cobranças
+func NewBillingService(
+	subscriptions ports.SubscriptionService,
+	payments ports.PaymentService,
+	planChanges *PlanChangeService,
+	dunning *DunningService,
+) *BillingService {
+	return &BillingService{
+		subscriptions: subscriptions,
+		payments:      payments,
+		planChanges:   planChanges,
+		dunning:       dunning,
+	}
+}
+
+// HandleCharge aplica o resultado de uma cobrança. É idempotente: um pagamento
+// já confirmado (ou uma falha já registrada) não muda de novo. Cobranças sem
+// assinatura conhecida (PIX avulso de outra origem) são ignoradas.
+func (s *BillingService) HandleCharge(ctx context.Context, n *ports.ChargeNotification) error {
+	payment, err := s.payments.GetByGatewayPaymentID(ctx, n.GatewayPaymentID)
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrNotFound):
+		payment = nil
+	default:
+		return fmt.Errorf("erro ao buscar pagamento %s: %w", n.GatewayPaymentID, err)
+	}
+
+	var sub *domain.Subscription
+	if payment != nil {
+		sub, err = s.subscriptions.GetByID(ctx, payment.SubscriptionID)
+	} else {
+		sub, err = s.subscriptionFor(ctx, n)
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		log.Printf("[Billing] Cobrança %s sem assinatura conhecida, ignorada", n.GatewayPaymentID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+
+	if n.Paid {
+		return s.handlePaid(ctx, sub, payment, n)
+	}
+	return s.handleFailed(ctx, sub, payment, n)
+}
+
+// handlePaid avança a assinatura (conversão ou renovação), registra o
+// pagamento e o confirma pelo dunning. Cada passo é idempotente, então um
+// reprocessamento depois de uma falha no meio completa o que faltou.
+func (s *BillingService) handlePaid(ctx context.Context, sub *domain.Subscription, payment *domain.PaymentHistory, n *ports.ChargeNotification) error {
+	if payment != nil && payment.Status == domain.PaymentStatusSucceeded {
+		return nil
+	}
+
+	// O desconto da cobrança é lido antes de advance consumir o ciclo do cupom
+	discount, couponID := s.chargeDiscount(ctx, sub)
+	if sub.IsPendingPlanChangeCharge(n.GatewayPaymentID) {
+		// Diferença de upgrade paga: o upgrade pendente passa a valer
+		if _, err := s.planChanges.HandleDifferencePaid(ctx, sub.ID, n.GatewayPaymentID); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if sub, err = s.advance(ctx, sub.ID, n.Gateway, isRecurringCharge(n, payment)); err != nil {
+			return err
+		}
+	}
+
+	if payment == nil {
+		payment = s.newPayment(sub, n, discount, couponID)
+		payment.PeriodStart = sub.CurrentPeriodStart
+		payment.PeriodEnd = sub.CurrentPeriodEnd
+		if err := s.payments.RecordPayment(ctx, payment); err != nil {
+			return fmt.Errorf("erro ao registrar pagamento: %w", err)
+		}
+	}
+	if n.EndToEndID != "" && payment.EndToEndID == nil {
+		e2e := n.EndToEndID
+		payment.EndToEndID = &e2e
+	}
+	return s.dunning.HandlePaymentSucceeded(ctx, payment)
+}
+
+// advance converte o trial ou renova o período pago e fecha o ciclo (pró-rata
+// e cupom) na mesma gravação. Assinaturas past_due e suspended são renovadas
+// pelo dunning ao confirmar o pagamento; uma assinatura já renovada por outra
+// entrega está fora da janela de renovação e não muda. Só a cobrança
+// recorrente renova: uma cobrança avulsa paga perto do fim do período (a
+// diferença de um upgrade) não avança o período nem fecha o ciclo. Com cancelamento
+// agendado não há renovação: vencido o período, o cancelamento é efetivado e
+// a recorrência encerrada no gateway.
+func (s *BillingService) advance(ctx context.Context, subscriptionID string, gateway domain.PaymentGateway, recurring bool) (*domain.Subscription, error) {
+	var (
+		sub      *domain.Subscription
+		before   json.RawMessage
+		action   domain.AuditAction
+		canceled bool
+	)
+	err := retryOnConflict(ctx, "assinatura "+subscriptionID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByID(ctx, subscriptionID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		before = snapshot(sub)
+
+		switch {
+		case sub.Status == domain.SubscriptionStatusTrialing || sub.Status == domain.SubscriptionStatusExpired:
+			if err := sub.ConvertTrial(gateway, s.now(), domain.ActorWebhook); err != nil {
+				return err
+			}
+			action = domain.AuditTrialConverted
+
+		case sub.Status == domain.SubscriptionStatusActive && sub.CancelAtPeriodEnd:
+			if !sub.CancellationDue(s.now()) {
+				return nil
+			}
+			if err := sub.CompleteCancel(domain.ActorWebhook, s.now()); err != nil {
+				return err
+			}
+			if err := s.subscriptions.Save(ctx, sub); err != nil {
+				return fmt.Errorf("erro ao salvar assinatura: %w", err)
+			}
+			action, canceled = domain.AuditSubscriptionCanceled, true
+			return nil
+
+		case sub.Status == domain.SubscriptionStatusActive && recurring && sub.CurrentPeriodEnd != nil &&
+			!s.now().Before(sub.CurrentPeriodEnd.Add(-renewalLeadTime)):
+			interval := sub.BillingInterval
+			if interval == "" {
+				interval = domain.BillingIntervalMonthly
+			}
+			if err := sub.Renew(interval, s.now()); err != nil {
+				return err
+			}
+
+		default:
+			return nil
+		}
+		return s.planChanges.HandleRenewalPaid(ctx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if action != "" {
+		s.audit(ctx, action, before, sub, s.now())
+	}
+	if canceled {
+		s.dunning.cancelRecurrence(ctx, sub)
+	}
+	return sub, nil
+}
+
+// handleFailed registra a falha e coloca a assinatura na régua de cobrança.
+// Uma assinatura que já não cobra (expirada, cancelada) só tem a falha
+// registrada, assim como a cobrança avulsa de uma assinatura em dia (a
+// diferença de um upgrade, que é descartado).
+func (s *BillingService) handleFailed(ctx context.Context, sub *domain.Subscription, payment *domain.PaymentHistory, n *ports.ChargeNotification) error {
+	if payment != nil && payment.Status == domain.PaymentStatusFailed {
+		return nil
+	}
+	if payment == nil {
+		discount, couponID := s.chargeDiscount(ctx, sub)
+		payment = s.newPayment(sub, n, discount, couponID)
+		if err := s.payments.RecordPayment(ctx, payment); err != nil {
+			return fmt.Errorf("erro ao registrar pagamento: %w", err)
+		}
+	}
+
+	reason := n.FailureReason
+	if reason == "" {
+		reason = "cobrança não realizada"
+	}
+	if sub.IsPendingPlanChangeCharge(n.GatewayPaymentID) {
+		if _, err := s.planChanges.HandleDifferenceFailed(ctx, sub.ID, n.GatewayPaymentID); err != nil {
+			return err
+		}
+	}
+	if !domain.CanTransition(sub.Status, domain.SubscriptionStatusPastDue) && !sub.IsPastDue() &&
+		sub.Status != domain.SubscriptionStatusSuspended ||
+		sub.Status == domain.SubscriptionStatusActive && !isRecurringCharge(n, payment) {
+		return updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+			p.Fail(reason, n.FailureCode, s.now())
+			return nil
+		})
+	}
+	return s.dunning.HandlePaymentFailed(ctx, payment, reason, n.FailureCode)
+}
+
+// isRecurringCharge indica se a cobrança é da recorrência da assinatura (idRec
+// do PIX Automático ou subscription do Stripe), e não uma cobrança avulsa
+func isRecurringCharge(n *ports.ChargeNotification, payment *domain.PaymentHistory) bool {
+	return n.SubscriptionRef != "" || payment != nil && payment.GatewayChargeID != nil
+}
+
+// subscriptionFor encontra a assinatura da cobrança recorrente
+func (s *BillingService) subscriptionFor(ctx context.Context, n *ports.ChargeNotification) (*domain.Subscription, error) {
+	if n.SubscriptionRef == "" {
+		return nil, domain.ErrNotFound
+	}
+	if n.Gateway == domain.PaymentGatewayStripe {
+		return s.subscriptions.GetByStripeSubscriptionID(ctx, n.SubscriptionRef)
+	}
+	return s.subscriptions.GetByPixRecurrenceID(ctx, n.SubscriptionRef)
+}
+
+// chargeDiscount retorna o desconto de cupom incluído na cobrança recorrente
+// de sub (zero sem cupom). Sem o plano, o pagamento fica sem o detalhamento.
+func (s *BillingService) chargeDiscount(ctx context.Context, sub *domain.Subscription) (int, string) {
+	if sub.Discount == nil {
+		return 0, ""
+	}
+	plan, err := s.planChanges.plans.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		log.Printf("[Billing] Erro ao buscar plano %s para o desconto da cobrança: %v", sub.PlanID, err)
+		return 0, ""
+	}
+	_, discount, err := sub.NextChargeAmount(plan)
+	if err != nil {
+		return 0, ""
+	}
+	return discount, sub.Discount.CouponID
+}
+
+// newPayment monta o pagamento pendente de uma cobrança informada pelo gateway.
+// O gateway informa o valor líquido; com desconto, o bruto é reconstituído.
+func (s *BillingService) newPayment(sub *domain.Subscription, n *ports.ChargeNotification, discount int, couponID string) *domain.PaymentHistory {
+	payment := domain.NewPaymentHistory(sub.ID, sub.AcademyID, n.Amount+discount, n.Gateway, s.now())
+	payment.ApplyDiscount(discount, couponID)
+	gatewayPaymentID := n.GatewayPaymentID
+	payment.GatewayPaymentID = &gatewayPaymentID
+	if n.SubscriptionRef != "" {
+		ref := n.SubscriptionRef
+		payment.GatewayChargeID = &ref
+	}
+	if n.EndToEndID != "" {
+		e2e := n.EndToEndID
+		payment.EndToEndID = &e2e
+	}
+	return payment
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newBillingFixture cria a assinatura Pro em trial com recorrência PIX
+// Automático (idRec rec-1), com o relógio no fim do trial
+func newBillingFixture(t *testing.T) (*BillingService, *serviceFixture) {
+	t.Helper()
+	f := newTrialFixture(t, "plan-pro", time.Date(2026, 6, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.clock.Set(f.start.AddDate(0, 0, 14))
+	return f.billing(), f
+}
+
+func paidCharge(txid string) *ports.ChargeNotification {
+	return &ports.ChargeNotification{
+		Gateway:          domain.PaymentGatewayPixAuto,
+		SubscriptionRef:  "rec-1",
+		GatewayPaymentID: txid,
+		EndToEndID:       "E" + txid,
+		Amount:           19900,
+		Paid:             true,
+	}
+}
+
+func TestBillingService_ConvertAndRenew(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newBillingFixture(t)
+	sub, payments, clock := f.sub, f.payments, f.clock
+
+	if err := svc.HandleCharge(ctx, paidCharge("tx-1")); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusActive {
+		t.Fatalf("Status = %v, want active", sub.Status)
+	}
+	periodEnd := *sub.CurrentPeriodEnd
+	if len(payments.payments) != 1 || payments.payments[0].Status != domain.PaymentStatusSucceeded {
+		t.Fatalf("pagamentos = %+v, want 1 confirmado", payments.payments)
+	}
+
+	// Reentrega do mesmo PIX não renova de novo
+	if err := svc.HandleCharge(ctx, paidCharge("tx-1")); err != nil {
+		t.Fatalf("HandleCharge() reentrega error = %v", err)
+	}
+	if !sub.CurrentPeriodEnd.Equal(periodEnd) || len(payments.payments) != 1 {
+		t.Errorf("reentrega alterou a assinatura: fim = %v, pagamentos = %d", sub.CurrentPeriodEnd, len(payments.payments))
+	}
+
+	// A cobrança da renovação, paga perto do fim do período, avança o período
+	clock.Set(periodEnd.Add(-24 * time.Hour))
+	if err := svc.HandleCharge(ctx, paidCharge("tx-2")); err != nil {
+		t.Fatalf("HandleCharge() renovação error = %v", err)
+	}
+	if !sub.CurrentPeriodStart.Equal(periodEnd) {
+		t.Errorf("CurrentPeriodStart = %v, want %v", sub.CurrentPeriodStart, periodEnd)
+	}
+	if p := payments.payments[1]; !p.PeriodStart.Equal(periodEnd) || p.Status != domain.PaymentStatusSucceeded {
+		t.Errorf("pagamento da renovação = %+v", p)
+	}
+}
+
+func TestBillingService_FailureThenLatePix(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newBillingFixture(t)
+	sub, payments, clock := f.sub, f.payments, f.clock
+
+	if err := svc.HandleCharge(ctx, paidCharge("tx-1")); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	periodEnd := *sub.CurrentPeriodEnd
+	clock.Set(periodEnd)
+
+	failed := &ports.ChargeNotification{
+		Gateway:          domain.PaymentGatewayPixAuto,
+		SubscriptionRef:  "rec-1",
+		GatewayPaymentID: "tx-2",
+		Amount:           19900,
+		FailureReason:    "saldo insuficiente",
+		FailureCode:      "AM04",
+	}
+	if err := svc.HandleCharge(ctx, failed); err != nil {
+		t.Fatalf("HandleCharge() falha error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusPastDue {
+		t.Fatalf("Status = %v, want past_due", sub.Status)
+	}
+	if p := payments.payments[1]; p.Status != domain.PaymentStatusFailed {
+		t.Errorf("pagamento = %v, want failed", p.Status)
+	}
+
+	// O PIX atrasado recupera a assinatura e paga o período em aberto
+	clock.Set(periodEnd.AddDate(0, 0, 2))
+	if err := svc.HandleCharge(ctx, paidCharge("tx-3")); err != nil {
+		t.Fatalf("HandleCharge() PIX atrasado error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusActive || !sub.CurrentPeriodStart.Equal(periodEnd) {
+		t.Errorf("Status = %v, início = %v, want active a partir de %v", sub.Status, sub.CurrentPeriodStart, periodEnd)
+	}
+
+	// Cobrança de quem não é assinante é ignorada
+	other := paidCharge("tx-4")
+	other.SubscriptionRef = ""
+	if err := svc.HandleCharge(ctx, other); err != nil || len(payments.payments) != 3 {
+		t.Errorf("HandleCharge() avulsa error = %v, pagamentos = %d", err, len(payments.payments))
+	}
+}
+
+func TestBillingService_UpgradeDifferenceDoesNotRenew(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newBillingFixture(t)
+	sub, payments, clock := f.sub, f.payments, f.clock
+	sub.PlanID = "plan-starter"
+
+	if err := svc.HandleCharge(ctx, paidCharge("tx-1")); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	periodStart, periodEnd := *sub.CurrentPeriodStart, *sub.CurrentPeriodEnd
+
+	// Upgrade com a diferença cobrada agora, dentro da janela de renovação
+	clock.Set(periodEnd.Add(-48 * time.Hour))
+	result, err := f.planChanges().ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"})
+	if err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+	sub.PendingProrationAmount = 3000
+
+	difference := &ports.ChargeNotification{
+		Gateway:          domain.PaymentGatewayPixAuto,
+		GatewayPaymentID: result.Charge.TxID,
+		EndToEndID:       "E" + result.Charge.TxID,
+		Amount:           result.Quote.AmountDue,
+		Paid:             true,
+	}
+	if err := svc.HandleCharge(ctx, difference); err != nil {
+		t.Fatalf("HandleCharge() diferença error = %v", err)
+	}
+	if !sub.CurrentPeriodStart.Equal(periodStart) || !sub.CurrentPeriodEnd.Equal(periodEnd) {
+		t.Errorf("período = %v a %v, want %v a %v (a diferença não renova)", sub.CurrentPeriodStart, sub.CurrentPeriodEnd, periodStart, periodEnd)
+	}
+	if sub.PendingProrationAmount != 3000 {
+		t.Errorf("PendingProrationAmount = %d, want 3000 (o ciclo não fecha)", sub.PendingProrationAmount)
+	}
+	if sub.PlanID != "plan-pro" {
+		t.Errorf("PlanID = %s, want plan-pro (upgrade aplicado com a diferença paga)", sub.PlanID)
+	}
+	if p := payments.payments[1]; p.Status != domain.PaymentStatusSucceeded {
+		t.Errorf("pagamento da diferença = %v, want succeeded", p.Status)
+	}
+}
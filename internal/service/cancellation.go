@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// CancellationReport resume uma execução do job de cancelamentos agendados
+type CancellationReport struct {
+	Canceled int // Assinaturas canceladas no fim do período
+	Failed   int // Assinaturas cujo gateway falhou (encerradas de novo na próxima execução)
+}
+
+// CancellationService cancela assinaturas, agora ou no fim do período,
+// e encerra as cobranças no gateway
+type CancellationService struct {
+	subscriptions ports.SubscriptionService
+	pix           ports.PixProvider
+	stripe        ports.StripeProvider
+
+	clocked
+	audited
+}
+
+// NewCancellationService cria o serviço de cancelamento (stripe pode ser nil)
+func NewCancellationService(subscriptions ports.SubscriptionService, pix ports.PixProvider, stripe ports.StripeProvider) *CancellationService {
+	return &CancellationService{
+		subscriptions: subscriptions,
+		pix:           pix,
+		stripe:        stripe,
+	}
+}
+
+// Cancel cancela a assinatura da academia. Com atPeriodEnd o acesso continua
+// até o fim do período pago, as cobranças são suspensas no gateway (a
+// renovação não acontece) e o job Run efetiva o cancelamento. A mudança é
+// gravada antes de chegar ao gateway: um agendamento que o gateway recusa é
+// desfeito; um cancelamento imediato continua valendo, com as cobranças
+// marcadas para o job encerrar (GatewayCancelPending).
+func (s *CancellationService) Cancel(ctx context.Context, academyID, reason string, atPeriodEnd bool, actor string) (*domain.Subscription, error) {
+	var (
+		sub    *domain.Subscription
+		before json.RawMessage
+	)
+	now := s.now()
+	err := retryOnConflict(ctx, "assinatura da academia "+academyID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByAcademy(ctx, academyID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		before = snapshot(sub)
+		if err := sub.Cancel(reason, atPeriodEnd, actor, now); err != nil {
+			return err
+		}
+		sub.GatewayCancelPending = !atPeriodEnd
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if atPeriodEnd {
+		if err := s.scheduleGateway(ctx, sub); err != nil {
+			s.revertSchedule(ctx, sub.ID, false, nil)
+			return nil, err
+		}
+	} else if err := s.finishGateway(ctx, sub); err != nil {
+		log.Printf("[Cancellation] Cobranças da assinatura %s ficam para o job: %v", sub.ID, err)
+	}
+	s.audit(ctx, domain.AuditSubscriptionCanceled, before, sub, now)
+	return sub, nil
+}
+
+// UndoCancel desiste do cancelamento agendado antes do fim do período. A
+// desistência é gravada antes de retomar as cobranças no gateway; se ele
+// recusar, o cancelamento volta a ficar agendado.
+func (s *CancellationService) UndoCancel(ctx context.Context, academyID string) (*domain.Subscription, error) {
+	var (
+		sub    *domain.Subscription
+		before json.RawMessage
+		reason *string
+	)
+	now := s.now()
+	err := retryOnConflict(ctx, "assinatura da academia "+academyID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByAcademy(ctx, academyID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		before = snapshot(sub)
+		reason = sub.CancelReason
+		if err := sub.UndoCancel(now); err != nil {
+			return err
+		}
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.unscheduleGateway(ctx, sub); err != nil {
+		s.revertSchedule(ctx, sub.ID, true, reason)
+		return nil, err
+	}
+	s.audit(ctx, domain.AuditSubscriptionCancelUndone, before, sub, now)
+	return sub, nil
+}
+
+// Run efetiva os cancelamentos cujo período terminou (job periódico). O
+// cancelamento é gravado antes de encerrar a recorrência no gateway; se o
+// gateway falhar, a assinatura fica com GatewayCancelPending e o encerramento
+// é tentado de novo nas próximas execuções.
+func (s *CancellationService) Run(ctx context.Context) (*CancellationReport, error) {
+	now := s.now()
+	subs, err := s.subscriptions.ListCancellationsDue(ctx, now, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar cancelamentos agendados: %w", err)
+	}
+
+	report := &CancellationReport{}
+	for _, listed := range subs {
+		sub := listed
+		if !listed.GatewayCancelPending {
+			if !listed.CancellationDue(now) {
+				continue
+			}
+			var (
+				before json.RawMessage
+				due    bool
+			)
+			err := retryOnConflict(ctx, "assinatura "+listed.ID, func() error {
+				var err error
+				sub, err = s.subscriptions.GetByID(ctx, listed.ID)
+				if err != nil {
+					return fmt.Errorf("erro ao buscar assinatura: %w", err)
+				}
+				// Desfeito ou pausado desde a listagem
+				if due = sub.CancellationDue(now); !due {
+					return nil
+				}
+				before = snapshot(sub)
+				if err := sub.CompleteCancel(domain.ActorSystem, now); err != nil {
+					return err
+				}
+				sub.GatewayCancelPending = true
+				if err := s.subscriptions.Save(ctx, sub); err != nil {
+					return fmt.Errorf("erro ao salvar assinatura: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				log.Printf("[Cancellation] Erro ao cancelar assinatura %s: %v", listed.ID, err)
+				report.Failed++
+				continue
+			}
+			if !due {
+				continue
+			}
+			s.audit(ctx, domain.AuditSubscriptionCanceled, before, sub, now)
+			report.Canceled++
+		}
+
+		if err := s.finishGateway(ctx, sub); err != nil {
+			log.Printf("[Cancellation] Erro ao encerrar cobranças da assinatura %s: %v", sub.ID, err)
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// finishGateway encerra no gateway as cobranças de uma assinatura cancelada e
+// limpa GatewayCancelPending. Se o gateway falhar, a marcação fica para o job.
+func (s *CancellationService) finishGateway(ctx context.Context, sub *domain.Subscription) error {
+	if err := s.cancelGateway(ctx, sub); err != nil {
+		return err
+	}
+	return retryOnConflict(ctx, "assinatura "+sub.ID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, sub.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		if current.GatewayCancelPending {
+			current.GatewayCancelPending = false
+			if err := s.subscriptions.Save(ctx, current); err != nil {
+				return fmt.Errorf("erro ao salvar assinatura: %w", err)
+			}
+		}
+		*sub = *current
+		return nil
+	})
+}
+
+// revertSchedule desfaz um agendamento de cancelamento (scheduled false) ou
+// uma desistência (scheduled true) gravados que o gateway recusou. Se a
+// assinatura mudou nesse meio tempo, a mudança prevalece.
+func (s *CancellationService) revertSchedule(ctx context.Context, subscriptionID string, scheduled bool, reason *string) {
+	err := retryOnConflict(ctx, "assinatura "+subscriptionID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if current.Status == domain.SubscriptionStatusCanceled || current.CancelAtPeriodEnd == scheduled {
+			return nil
+		}
+		if scheduled {
+			cancelReason := ""
+			if reason != nil {
+				cancelReason = *reason
+			}
+			err = current.Cancel(cancelReason, true, domain.ActorSystem, s.now())
+		} else {
+			err = current.UndoCancel(s.now())
+		}
+		if err != nil {
+			return err
+		}
+		return s.subscriptions.Save(ctx, current)
+	})
+	if err != nil {
+		log.Printf("[Cancellation] Erro ao desfazer mudança no cancelamento da assinatura %s: %v", subscriptionID, err)
+	}
+}
+
+// scheduleGateway suspende a renovação no gateway até o fim do período. A
+// recorrência PIX é pausada (não revogada) para que o cancelamento ainda possa
+// ser desfeito; uma assinatura pausada já está sem cobranças.
+func (s *CancellationService) scheduleGateway(ctx context.Context, sub *domain.Subscription) error {
+	if sub.PaymentGateway == nil {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if sub.PixRecurrenceID != nil && sub.Status != domain.SubscriptionStatusPaused {
+			if s.pix == nil {
+				return fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+			}
+			if err := s.pix.PauseRecurrence(ctx, *sub.PixRecurrenceID); err != nil {
+				return fmt.Errorf("erro ao suspender PIX Automático: %w", err)
+			}
+		}
+	case domain.PaymentGatewayStripe:
+		// O Stripe renova sozinho no fim do período: o agendamento precisa estar lá também
+		if s.stripe != nil && sub.StripeSubscriptionID != nil {
+			if err := s.stripe.CancelSubscription(ctx, *sub.StripeSubscriptionID, true); err != nil {
+				return fmt.Errorf("erro ao agendar cancelamento no Stripe: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// unscheduleGateway desfaz scheduleGateway: a recorrência PIX volta a cobrar
+// no fim do período atual
+func (s *CancellationService) unscheduleGateway(ctx context.Context, sub *domain.Subscription) error {
+	if sub.PaymentGateway == nil {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if sub.PixRecurrenceID != nil && sub.Status != domain.SubscriptionStatusPaused && sub.CurrentPeriodEnd != nil {
+			if s.pix == nil {
+				return fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+			}
+			if err := s.pix.ResumeRecurrence(ctx, *sub.PixRecurrenceID, *sub.CurrentPeriodEnd); err != nil {
+				return fmt.Errorf("erro ao retomar PIX Automático: %w", err)
+			}
+		}
+	case domain.PaymentGatewayStripe:
+		if s.stripe != nil && sub.StripeSubscriptionID != nil {
+			if err := s.stripe.ReactivateSubscription(ctx, *sub.StripeSubscriptionID); err != nil {
+				return fmt.Errorf("erro ao reativar subscription no Stripe: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// cancelGateway encerra as cobranças da assinatura no gateway
+func (s *CancellationService) cancelGateway(ctx context.Context, sub *domain.Subscription) error {
+	if sub.PaymentGateway == nil {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if sub.PixAuthorizationID != nil {
+			if s.pix == nil {
+				return fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+			}
+			if err := s.pix.CancelRecurrence(ctx, *sub.PixAuthorizationID); err != nil {
+				return fmt.Errorf("erro ao cancelar PIX Automático: %w", err)
+			}
+		}
+	case domain.PaymentGatewayStripe:
+		if s.stripe != nil && sub.StripeSubscriptionID != nil {
+			if err := s.stripe.CancelSubscription(ctx, *sub.StripeSubscriptionID, false); err != nil {
+				return fmt.Errorf("erro ao cancelar subscription no Stripe: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// newCancellationFixture cria a assinatura PIX Automático ativa de 1/jul a
+// 1/ago, com o relógio no dia 11/jul
+func newCancellationFixture(t *testing.T) (*CancellationService, *serviceFixture) {
+	t.Helper()
+	f := newServiceFixture(t, "plan-starter", time.Date(2026, 7, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.clock.Set(f.start.AddDate(0, 0, 10))
+	svc := NewCancellationService(f.subs, f.pix, nil)
+	svc.SetClock(f.clock)
+	return svc, f
+}
+
+func TestCancellationService_PeriodEnd(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newCancellationFixture(t)
+	sub, pix, clock := f.sub, f.pix, f.clock
+
+	if _, err := svc.Cancel(ctx, "academy-1", "vai fechar", true, "user-1"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusActive || !sub.CancelAtPeriodEnd || len(pix.canceled) != 0 {
+		t.Fatal("cancelamento agendado não deveria revogar a autorização agora")
+	}
+	if len(pix.paused) != 1 || pix.paused[0] != "rec-1" {
+		t.Errorf("recorrência suspensa = %v, want [rec-1]", pix.paused)
+	}
+
+	// Antes do fim do período o job não faz nada
+	report, err := svc.Run(ctx)
+	if err != nil || report.Canceled != 0 {
+		t.Fatalf("Run() = %+v, %v, want nada cancelado", report, err)
+	}
+
+	clock.Set(*sub.CurrentPeriodEnd)
+	report, err = svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Canceled != 1 || sub.Status != domain.SubscriptionStatusCanceled || sub.CancelAtPeriodEnd {
+		t.Fatalf("report = %+v, status = %v, cancel_at_period_end = %v", report, sub.Status, sub.CancelAtPeriodEnd)
+	}
+	if len(pix.canceled) != 1 || pix.canceled[0] != "rec-1" {
+		t.Errorf("recorrência cancelada = %v, want [rec-1]", pix.canceled)
+	}
+	if sub.CancelReason == nil || *sub.CancelReason != "vai fechar" {
+		t.Errorf("CancelReason = %v, want motivo original", sub.CancelReason)
+	}
+}
+
+func TestCancellationService_ScheduleGatewayFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newCancellationFixture(t)
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs}
+	svc.subscriptions = subs
+	f.pix.err = &unavailableError{}
+
+	if _, err := svc.Cancel(ctx, "academy-1", "", true, "user-1"); err == nil {
+		t.Fatal("Cancel() deveria falhar sem suspender a recorrência")
+	}
+	if stored, _ := subs.GetByID(ctx, "sub-1"); stored.CancelAtPeriodEnd {
+		t.Error("agendamento não deveria ser gravado com a recorrência ativa")
+	}
+}
+
+func TestCancellationService_GatewayFailureRetries(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newCancellationFixture(t)
+	sub, pix, clock := f.sub, f.pix, f.clock
+
+	if _, err := svc.Cancel(ctx, "academy-1", "", true, "user-1"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	clock.Set(*sub.CurrentPeriodEnd)
+	pix.err = &unavailableError{}
+
+	// O cancelamento vale mesmo com o gateway fora; o encerramento fica pendente
+	report, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Canceled != 1 || report.Failed != 1 || sub.Status != domain.SubscriptionStatusCanceled || !sub.GatewayCancelPending {
+		t.Fatalf("report = %+v, status = %v, pendente = %v; want canceled com o gateway pendente",
+			report, sub.Status, sub.GatewayCancelPending)
+	}
+
+	pix.err = nil
+	pix.canceled = nil
+	if report, _ = svc.Run(ctx); report.Failed != 0 || sub.GatewayCancelPending {
+		t.Errorf("report = %+v, pendente = %v, want recorrência encerrada na nova tentativa", report, sub.GatewayCancelPending)
+	}
+	if len(pix.canceled) != 1 || pix.canceled[0] != "rec-1" {
+		t.Errorf("recorrência cancelada = %v, want [rec-1]", pix.canceled)
+	}
+	if report, _ = svc.Run(ctx); len(pix.canceled) != 1 {
+		t.Errorf("recorrência encerrada de novo: %v", pix.canceled)
+	}
+}
+
+func TestCancellationService_SavedBeforeGateway(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newCancellationFixture(t)
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+	svc.subscriptions = subs
+	stored := func() *domain.Subscription {
+		sub, _ := subs.GetByID(ctx, "sub-1")
+		return sub
+	}
+
+	// Conflito na gravação: recarrega e agenda
+	if _, err := svc.Cancel(ctx, "academy-1", "vai fechar", true, "user-1"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if !stored().CancelAtPeriodEnd {
+		t.Fatal("agendamento não gravado")
+	}
+
+	// A Efí recusa a retomada: o cancelamento volta a ficar agendado, com o motivo
+	f.pix.err = &unavailableError{}
+	if _, err := svc.UndoCancel(ctx, "academy-1"); err == nil {
+		t.Fatal("UndoCancel() deveria falhar sem retomar a recorrência")
+	}
+	if sub := stored(); !sub.CancelAtPeriodEnd || sub.CancelReason == nil || *sub.CancelReason != "vai fechar" {
+		t.Errorf("cancel_at_period_end = %v, motivo = %v; want agendamento restaurado", sub.CancelAtPeriodEnd, sub.CancelReason)
+	}
+
+	// Cancelamento imediato com a Efí fora: vale agora e o job encerra depois
+	sub, err := svc.Cancel(ctx, "academy-1", "", false, "user-1")
+	if err != nil {
+		t.Fatalf("Cancel() imediato error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusCanceled || !stored().GatewayCancelPending {
+		t.Fatalf("status = %v, pendente = %v; want canceled com o gateway pendente", sub.Status, stored().GatewayCancelPending)
+	}
+	f.pix.err = nil
+	if report, err := svc.Run(ctx); err != nil || report.Failed != 0 || stored().GatewayCancelPending {
+		t.Errorf("Run() = %+v, %v, pendente = %v; want recorrência encerrada", report, err, stored().GatewayCancelPending)
+	}
+}
+
+func TestCancellationService_Undo(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newCancellationFixture(t)
+	sub, pix, clock := f.sub, f.pix, f.clock
+
+	if _, err := svc.Cancel(ctx, "academy-1", "", true, "user-1"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if _, err := svc.UndoCancel(ctx, "academy-1"); err != nil {
+		t.Fatalf("UndoCancel() error = %v", err)
+	}
+	if sub.CancelAtPeriodEnd || sub.CancelReason != nil {
+		t.Fatal("UndoCancel() deveria limpar o agendamento")
+	}
+	if len(pix.resumed) != 1 || !pix.nextDueDate.Equal(*sub.CurrentPeriodEnd) {
+		t.Errorf("recorrência retomada = %v a partir de %v, want [rec-1] em %v", pix.resumed, pix.nextDueDate, sub.CurrentPeriodEnd)
+	}
+
+	clock.Set(*sub.CurrentPeriodEnd)
+	if report, _ := svc.Run(ctx); report.Canceled != 0 || len(pix.canceled) != 0 {
+		t.Errorf("cancelamento desfeito não deveria ser efetivado: report = %+v", report)
+	}
+	if _, err := svc.UndoCancel(ctx, "academy-1"); err == nil {
+		t.Error("UndoCancel() sem agendamento deveria falhar")
+	}
+}
+
+func TestBillingService_ChargeCompletesScheduledCancellation(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newCancellationFixture(t)
+	sub := f.sub
+	if _, err := svc.Cancel(ctx, "academy-1", "vai fechar", true, "user-1"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	// Uma cobrança que ainda chega no fim do período não renova
+	periodEnd := *sub.CurrentPeriodEnd
+	f.clock.Set(periodEnd)
+	if err := f.billing().HandleCharge(ctx, paidCharge("txid-late")); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusCanceled || !sub.CurrentPeriodEnd.Equal(periodEnd) {
+		t.Fatalf("Status = %v, CurrentPeriodEnd = %v, want canceled sem renovar", sub.Status, sub.CurrentPeriodEnd)
+	}
+	if len(f.pix.canceled) != 1 || f.pix.canceled[0] != "rec-1" {
+		t.Errorf("recorrência cancelada = %v, want [rec-1]", f.pix.canceled)
+	}
+}
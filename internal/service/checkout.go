@@ -0,0 +1,242 @@
+// Package service contém a lógica de negócio da aplicação,
+// orquestrando entidades de domínio e adaptadores através dos ports
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// CheckoutStatus indica o resultado de uma tentativa de checkout
+type CheckoutStatus string
+
+const (
+	CheckoutStatusCreated CheckoutStatus = "created" // Cobrança criada no gateway
+	CheckoutStatusQueued  CheckoutStatus = "queued"  // Gateway indisponível, cobrança enfileirada
+)
+
+// Valores padrão do modo degradado
+const (
+	defaultCheckoutRetryAfter = 30 * time.Second
+	maxPendingChargeAttempts  = 10
+)
+
+// CheckoutResult é o resultado de uma operação de checkout.
+// Em modo degradado (Status == queued) o app deve tentar novamente após RetryAfter,
+// consultando PendingChargeID, ou oferecer o Stripe se StripeAvailable.
+type CheckoutResult struct {
+	Status CheckoutStatus
+
+	Charge     *ports.PixChargeResponse
+	Recurrence *ports.PixRecurrenceSetupResponse
+
+	PendingChargeID string
+	RetryAfter      time.Duration
+	StripeAvailable bool
+}
+
+// CheckoutOptions configura o CheckoutService
+type CheckoutOptions struct {
+	StripeFallback    bool          // Oferece o Stripe quando o PIX está indisponível
+	DefaultRetryAfter time.Duration // Usado quando o erro não informa o tempo de espera
+}
+
+// PendingChargeHandler reage a uma cobrança adiada que o worker conseguiu
+// criar no gateway (ex: vincular a recorrência à assinatura)
+type PendingChargeHandler interface {
+	HandlePendingChargeCompleted(ctx context.Context, pending *ports.PendingCharge) error
+}
+
+// CheckoutService cria cobranças PIX com degradação graciosa:
+// quando o gateway está indisponível, a cobrança é enfileirada para depois
+// em vez de bloquear o checkout até o timeout.
+type CheckoutService struct {
+	pix         ports.PixProvider
+	queue       ports.ChargeQueue
+	opts        CheckoutOptions
+	onCompleted []PendingChargeHandler
+
+	clocked
+}
+
+// NewCheckoutService cria um novo serviço de checkout
+func NewCheckoutService(pix ports.PixProvider, queue ports.ChargeQueue, opts CheckoutOptions) *CheckoutService {
+	if opts.DefaultRetryAfter <= 0 {
+		opts.DefaultRetryAfter = defaultCheckoutRetryAfter
+	}
+	return &CheckoutService{
+		pix:   pix,
+		queue: queue,
+		opts:  opts,
+	}
+}
+
+// OnCompleted registra handlers chamados quando uma cobrança adiada é criada
+// no gateway. Falhas deles são logadas e não voltam a cobrança para a fila.
+func (s *CheckoutService) OnCompleted(handlers ...PendingChargeHandler) {
+	s.onCompleted = append(s.onCompleted, handlers...)
+}
+
+// CreatePixCharge cria uma cobrança PIX imediata, enfileirando-a se o gateway estiver indisponível
+func (s *CheckoutService) CreatePixCharge(ctx context.Context, academyID string, req *ports.PixChargeRequest) (*CheckoutResult, error) {
+	resp, err := s.pix.CreatePixCharge(ctx, req)
+	if err == nil {
+		return &CheckoutResult{Status: CheckoutStatusCreated, Charge: resp}, nil
+	}
+	if !errors.Is(err, ports.ErrGatewayUnavailable) {
+		return nil, err
+	}
+
+	return s.enqueue(ctx, &ports.PendingCharge{
+		AcademyID: academyID,
+		Kind:      ports.PendingChargeKindPixCharge,
+		Charge:    req,
+	}, err)
+}
+
+// SetupRecurrence configura PIX Automático, enfileirando a configuração se o gateway estiver indisponível
+func (s *CheckoutService) SetupRecurrence(ctx context.Context, req *ports.PixRecurrenceSetupRequest) (*CheckoutResult, error) {
+	resp, err := s.pix.SetupRecurrence(ctx, req)
+	if err == nil {
+		return &CheckoutResult{Status: CheckoutStatusCreated, Recurrence: resp}, nil
+	}
+	if !errors.Is(err, ports.ErrGatewayUnavailable) {
+		return nil, err
+	}
+
+	return s.enqueue(ctx, &ports.PendingCharge{
+		AcademyID:  req.AcademyID,
+		Kind:       ports.PendingChargeKindPixRecurrence,
+		Recurrence: req,
+	}, err)
+}
+
+// enqueue coloca a cobrança na fila e monta a resposta de modo degradado
+func (s *CheckoutService) enqueue(ctx context.Context, pending *ports.PendingCharge, cause error) (*CheckoutResult, error) {
+	now := s.now()
+	retryAfter := s.retryAfter(cause)
+
+	pending.Status = ports.PendingChargeStatusQueued
+	pending.LastError = cause.Error()
+	pending.EnqueuedAt = now
+	pending.NextAttemptAt = now.Add(retryAfter)
+
+	if err := s.queue.Enqueue(ctx, pending); err != nil {
+		return nil, fmt.Errorf("erro ao enfileirar cobrança: %w", err)
+	}
+
+	log.Printf("[Checkout] Gateway indisponível, %s da academia %s enfileirada (%s): %v",
+		pending.Kind, pending.AcademyID, pending.ID, cause)
+
+	return &CheckoutResult{
+		Status:          CheckoutStatusQueued,
+		PendingChargeID: pending.ID,
+		RetryAfter:      retryAfter,
+		StripeAvailable: s.opts.StripeFallback,
+	}, nil
+}
+
+// retryAfter extrai o tempo de espera sugerido pelo erro (ex: circuit breaker aberto)
+func (s *CheckoutService) retryAfter(err error) time.Duration {
+	var retryErr ports.RetryAfterError
+	if errors.As(err, &retryErr) && retryErr.RetryAfter() > 0 {
+		return retryErr.RetryAfter()
+	}
+	return s.opts.DefaultRetryAfter
+}
+
+// GetPendingCharge consulta uma cobrança enfileirada (usado pelo app ao retentar)
+func (s *CheckoutService) GetPendingCharge(ctx context.Context, id string) (*ports.PendingCharge, error) {
+	return s.queue.Get(ctx, id)
+}
+
+// ProcessPending tenta criar no gateway as cobranças enfileiradas que já venceram.
+// Para no primeiro erro de indisponibilidade para não martelar um gateway que continua fora.
+func (s *CheckoutService) ProcessPending(ctx context.Context, limit int) (int, error) {
+	due, err := s.queue.ListDue(ctx, s.now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar cobranças pendentes: %w", err)
+	}
+
+	processed := 0
+	for _, pending := range due {
+		err := s.attempt(ctx, pending)
+		pending.Attempts++
+
+		switch {
+		case err == nil:
+			pending.Status = ports.PendingChargeStatusCompleted
+			pending.LastError = ""
+			processed++
+		case errors.Is(err, ports.ErrGatewayUnavailable) && pending.Attempts < maxPendingChargeAttempts:
+			pending.LastError = err.Error()
+			pending.NextAttemptAt = s.now().Add(s.retryAfter(err))
+		default:
+			pending.Status = ports.PendingChargeStatusFailed
+			pending.LastError = err.Error()
+			log.Printf("[Checkout] Cobrança pendente %s falhou: %v", pending.ID, err)
+		}
+
+		if updateErr := s.queue.Update(ctx, pending); updateErr != nil {
+			return processed, fmt.Errorf("erro ao atualizar cobrança pendente %s: %w", pending.ID, updateErr)
+		}
+		if err == nil {
+			for _, h := range s.onCompleted {
+				if err := h.HandlePendingChargeCompleted(ctx, pending); err != nil {
+					log.Printf("[Checkout] Erro pós-criação da cobrança pendente %s: %v", pending.ID, err)
+				}
+			}
+		}
+
+		if err != nil && errors.Is(err, ports.ErrGatewayUnavailable) {
+			break
+		}
+	}
+
+	return processed, nil
+}
+
+// attempt executa a operação original de uma cobrança enfileirada
+func (s *CheckoutService) attempt(ctx context.Context, pending *ports.PendingCharge) error {
+	switch pending.Kind {
+	case ports.PendingChargeKindPixCharge:
+		resp, err := s.pix.CreatePixCharge(ctx, pending.Charge)
+		if err != nil {
+			return err
+		}
+		pending.ChargeResult = resp
+	case ports.PendingChargeKindPixRecurrence:
+		resp, err := s.pix.SetupRecurrence(ctx, pending.Recurrence)
+		if err != nil {
+			return err
+		}
+		pending.RecurrenceResult = resp
+	default:
+		return fmt.Errorf("tipo de cobrança desconhecido: %s", pending.Kind)
+	}
+	return nil
+}
+
+// RunPendingWorker processa a fila periodicamente até o contexto ser cancelado
+func (s *CheckoutService) RunPendingWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := s.ProcessPending(ctx, 50); err != nil {
+				log.Printf("[Checkout] Erro ao processar fila: %v", err)
+			} else if n > 0 {
+				log.Printf("[Checkout] %d cobrança(s) pendente(s) criada(s)", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// unavailableError simula o erro de circuit breaker aberto de um adaptador
+type unavailableError struct{ wait time.Duration }
+
+func (e *unavailableError) Error() string             { return "circuito aberto" }
+func (e *unavailableError) Unwrap() error             { return ports.ErrGatewayUnavailable }
+func (e *unavailableError) RetryAfter() time.Duration { return e.wait }
+
+// fakePix é um PixProvider controlável para testes
+type fakePix struct {
+	ports.PixProvider
+	err              error
+	calls            int
+	canceled         []string
+	paused           []string
+	resumed          []string
+	nextDueDate      time.Time   // Próximo vencimento do último ResumeRecurrence
+	rescheduled      []time.Time // Vencimentos passados a RescheduleRecurrence
+	recurrenceAmount int64
+}
+
+func (f *fakePix) CreatePixCharge(ctx context.Context, req *ports.PixChargeRequest) (*ports.PixChargeResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	txid := req.TxID
+	if txid == "" {
+		txid = fmt.Sprintf("txid-%d", f.calls)
+	}
+	return &ports.PixChargeResponse{TxID: txid, PixCode: "00020101..."}, nil
+}
+
+func (f *fakePix) SetupRecurrence(ctx context.Context, req *ports.PixRecurrenceSetupRequest) (*ports.PixRecurrenceSetupResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ports.PixRecurrenceSetupResponse{AuthorizationID: "rec-1", RecurrenceID: "rec-1"}, nil
+}
+
+func (f *fakePix) UpdateRecurrenceAmount(ctx context.Context, recurrenceID string, amount int64) error {
+	f.recurrenceAmount = amount
+	return f.err
+}
+
+func (f *fakePix) CancelRecurrence(ctx context.Context, authorizationID string) error {
+	f.canceled = append(f.canceled, authorizationID)
+	return f.err
+}
+
+func (f *fakePix) RescheduleRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error {
+	f.rescheduled = append(f.rescheduled, nextDueDate)
+	return nil
+}
+
+func (f *fakePix) PauseRecurrence(ctx context.Context, recurrenceID string) error {
+	f.paused = append(f.paused, recurrenceID)
+	return f.err
+}
+
+func (f *fakePix) ResumeRecurrence(ctx context.Context, recurrenceID string, nextDueDate time.Time) error {
+	f.resumed = append(f.resumed, recurrenceID)
+	f.nextDueDate = nextDueDate
+	return f.err
+}
+
+func TestCheckoutService_DegradedMode(t *testing.T) {
+	ctx := context.Background()
+	pix := &fakePix{err: &unavailableError{wait: 45 * time.Second}}
+	queue := memory.NewChargeQueue()
+	svc := NewCheckoutService(pix, queue, CheckoutOptions{StripeFallback: true})
+
+	clock := domain.NewFakeClock(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	svc.SetClock(clock)
+
+	result, err := svc.SetupRecurrence(ctx, &ports.PixRecurrenceSetupRequest{AcademyID: "academy-1", Amount: 9700})
+	if err != nil {
+		t.Fatalf("SetupRecurrence() error = %v", err)
+	}
+	if result.Status != CheckoutStatusQueued {
+		t.Fatalf("Status = %v, want queued", result.Status)
+	}
+	if result.RetryAfter != 45*time.Second {
+		t.Errorf("RetryAfter = %v, want 45s", result.RetryAfter)
+	}
+	if !result.StripeAvailable {
+		t.Error("StripeAvailable deveria ser true")
+	}
+
+	// Antes do retry, nada é processado
+	if n, _ := svc.ProcessPending(ctx, 10); n != 0 {
+		t.Errorf("ProcessPending() = %d, want 0", n)
+	}
+
+	// Gateway volta: a cobrança é criada e o resultado fica disponível para o app
+	pix.err = nil
+	clock.Advance(time.Minute)
+	n, err := svc.ProcessPending(ctx, 10)
+	if err != nil || n != 1 {
+		t.Fatalf("ProcessPending() = %d, %v, want 1, nil", n, err)
+	}
+
+	pending, err := svc.GetPendingCharge(ctx, result.PendingChargeID)
+	if err != nil {
+		t.Fatalf("GetPendingCharge() error = %v", err)
+	}
+	if pending.Status != ports.PendingChargeStatusCompleted || pending.RecurrenceResult == nil {
+		t.Errorf("pending = %+v, want completed with result", pending)
+	}
+}
+
+func TestCheckoutService_NonAvailabilityErrorsPropagate(t *testing.T) {
+	pix := &fakePix{err: fmt.Errorf("valor inválido")}
+	svc := NewCheckoutService(pix, memory.NewChargeQueue(), CheckoutOptions{})
+
+	_, err := svc.CreatePixCharge(context.Background(), "academy-1", &ports.PixChargeRequest{Amount: 100})
+	if err == nil || errors.Is(err, ports.ErrGatewayUnavailable) {
+		t.Fatalf("CreatePixCharge() error = %v, want validation error", err)
+	}
+}
+
+func TestCheckoutService_StillUnavailableReschedules(t *testing.T) {
+	ctx := context.Background()
+	pix := &fakePix{err: &unavailableError{}}
+	svc := NewCheckoutService(pix, memory.NewChargeQueue(), CheckoutOptions{DefaultRetryAfter: time.Minute})
+
+	clock := domain.NewFakeClock(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	svc.SetClock(clock)
+
+	for i := 0; i < 2; i++ {
+		svc.CreatePixCharge(ctx, "academy-1", &ports.PixChargeRequest{Amount: 100})
+	}
+	pix.calls = 0
+
+	clock.Advance(time.Minute)
+	if n, err := svc.ProcessPending(ctx, 10); n != 0 || err != nil {
+		t.Fatalf("ProcessPending() = %d, %v, want 0, nil", n, err)
+	}
+	if pix.calls != 1 {
+		t.Errorf("gateway calls = %d, want 1 (deve parar no primeiro erro de indisponibilidade)", pix.calls)
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// clocked é embutido nos serviços que leem a hora. O relógio padrão é o do
+// sistema; testes e o test clock do sandbox trocam por SetClock.
+type clocked struct {
+	clock domain.Clock
+}
+
+// SetClock troca o relógio do serviço
+func (c *clocked) SetClock(clock domain.Clock) {
+	c.clock = clock
+}
+
+// now retorna a hora do relógio configurado
+func (c *clocked) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// maxConflictRetries limita as recargas de uma transição que colidiu com outra escrita
+const maxConflictRetries = 3
+
+// retryOnConflict executa apply de novo enquanto a gravação colidir com uma
+// escrita concorrente (domain.ErrConcurrentModification). apply deve recarregar
+// a entidade a cada chamada: a transição é reavaliada sobre o estado atual e
+// pode deixar de se aplicar (ex: webhook de pagamento de uma assinatura que o
+// usuário cancelou no meio do caminho).
+func retryOnConflict(ctx context.Context, what string, apply func() error) error {
+	err := apply()
+	for attempt := 1; attempt <= maxConflictRetries && errors.Is(err, domain.ErrConcurrentModification); attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("[Concurrency] Conflito de versão em %s, recarregando (tentativa %d)", what, attempt)
+		err = apply()
+	}
+	return err
+}
+
+// updatePayment aplica mutate ao pagamento e grava. Se outra escrita veio antes,
+// recarrega o pagamento pelo ID do gateway e reaplica; ao final *payment é a
+// versão gravada. Pagamentos sem ID do gateway não são recarregados.
+func updatePayment(ctx context.Context, payments ports.PaymentService, payment *domain.PaymentHistory, mutate func(*domain.PaymentHistory) error) error {
+	if payment.GatewayPaymentID == nil {
+		if err := mutate(payment); err != nil {
+			return err
+		}
+		return payments.UpdatePayment(ctx, payment)
+	}
+
+	current := payment
+	err := retryOnConflict(ctx, "pagamento "+payment.ID, func() error {
+		if current == nil {
+			fresh, err := payments.GetByGatewayPaymentID(ctx, *payment.GatewayPaymentID)
+			if err != nil {
+				return err
+			}
+			current = fresh
+		}
+		if err := mutate(current); err != nil {
+			return err
+		}
+		if err := payments.UpdatePayment(ctx, current); err != nil {
+			current = nil
+			return err
+		}
+		return nil
+	})
+	if err == nil && current != payment {
+		*payment = *current
+	}
+	return err
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// racingSubscriptions executa race (uma escrita concorrente) antes da primeira gravação
+type racingSubscriptions struct {
+	*memory.SubscriptionRepository
+	race  func(ctx context.Context, sub *domain.Subscription)
+	saves int
+}
+
+func (r *racingSubscriptions) Save(ctx context.Context, sub *domain.Subscription) error {
+	r.saves++
+	if race := r.race; race != nil {
+		r.race = nil
+		current, err := r.SubscriptionRepository.GetByID(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+		race(ctx, current)
+		if err := r.SubscriptionRepository.Save(ctx, current); err != nil {
+			return err
+		}
+	}
+	return r.SubscriptionRepository.Save(ctx, sub)
+}
+
+func TestBillingService_ConvertRetriesOnConflict(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		race       func(ctx context.Context, sub *domain.Subscription)
+		wantStatus domain.SubscriptionStatus
+		wantSaves  int
+	}{
+		{
+			name:       "sem concorrência",
+			wantStatus: domain.SubscriptionStatusActive,
+			wantSaves:  1,
+		},
+		{
+			name: "escrita compatível é preservada",
+			race: func(ctx context.Context, sub *domain.Subscription) {
+				_ = sub.ExtendTrial(5, "suporte", "admin-1", now)
+			},
+			wantStatus: domain.SubscriptionStatusActive,
+			wantSaves:  2,
+		},
+		{
+			// O pagamento fica registrado, mas a assinatura recarregada não é ativada
+			name: "cancelamento vence a ativação",
+			race: func(ctx context.Context, sub *domain.Subscription) {
+				_ = sub.Cancel("desistiu", false, "user-1", now)
+			},
+			wantStatus: domain.SubscriptionStatusCanceled,
+			wantSaves:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			plans := memory.NewPlanRepository(memory.DefaultPlans(now)...)
+			repo := memory.NewSubscriptionRepository(plans, memory.NewOutbox())
+			pro, _ := plans.GetBySlug(ctx, "pro")
+			sub, err := repo.CreateTrial(ctx, "academy-1", pro.ID)
+			if err != nil {
+				t.Fatalf("CreateTrial: %v", err)
+			}
+			recurrenceID := "rec-1"
+			sub.PixRecurrenceID = &recurrenceID
+			if err := repo.Save(ctx, sub); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			subs := &racingSubscriptions{SubscriptionRepository: repo, race: tt.race}
+			payments := memory.NewPaymentRepository(memory.NewOutbox())
+			billing := NewBillingService(subs, payments,
+				NewPlanChangeService(subs, plans, payments, nil, nil),
+				NewDunningService(subs, payments, plans, nil, memory.NewNotifier()))
+			billing.SetClock(domain.NewFakeClock(now))
+
+			err = billing.HandleCharge(ctx, &ports.ChargeNotification{
+				Gateway: domain.PaymentGatewayPixAuto, SubscriptionRef: recurrenceID,
+				GatewayPaymentID: "tx-1", Amount: pro.PriceMonthly, Paid: true,
+			})
+			if err != nil {
+				t.Fatalf("HandleCharge() error = %v", err)
+			}
+			got, _ := repo.GetByID(ctx, sub.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if subs.saves != tt.wantSaves {
+				t.Errorf("Save chamado %d vezes, want %d", subs.saves, tt.wantSaves)
+			}
+			if tt.race != nil && tt.wantStatus == domain.SubscriptionStatusActive && len(got.TrialExtensions) != 1 {
+				t.Errorf("extensão concorrente perdida: %+v", got.TrialExtensions)
+			}
+		})
+	}
+}
+
+func TestRefundService_NotificationRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	payments := memory.NewPaymentRepository(memory.NewOutbox())
+
+	payment := domain.NewPaymentHistory("sub-1", "academy-1", 9900, domain.PaymentGatewayPixAuto, now)
+	txid := "txid-1"
+	payment.GatewayPaymentID = &txid
+	payment.Succeed(now)
+	first, _ := payment.RequestRefund(3000, "", "user-1", now)
+	second, _ := payment.RequestRefund(2000, "", "user-1", now)
+	if err := payments.RecordPayment(ctx, payment); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+
+	// Webhooks das duas devoluções concorrendo: a segunda gravação colide e recarrega
+	racing := &racingPayments{PaymentRepository: payments, race: func(ctx context.Context) {
+		p, _ := payments.GetByGatewayPaymentID(ctx, txid)
+		_ = p.ConfirmRefund(second.ID, "D2", 2000, now)
+		_ = payments.UpdatePayment(ctx, p)
+	}}
+	refunds := NewRefundService(racing, nil, nil)
+	refunds.SetClock(domain.NewFakeClock(now))
+
+	err := refunds.HandleRefundNotification(ctx, &ports.RefundNotification{
+		GatewayPaymentID: txid, RefundID: first.ID, GatewayRefundID: "D1", Amount: 3000,
+		Status: domain.RefundStatusSucceeded,
+	})
+	if err != nil {
+		t.Fatalf("HandleRefundNotification: %v", err)
+	}
+	got, _ := payments.GetByGatewayPaymentID(ctx, txid)
+	if got.RefundedAmount != 5000 || got.Version != 3 {
+		t.Errorf("refunded = %d, versão %d; want 5000 e v3 (as duas devoluções)", got.RefundedAmount, got.Version)
+	}
+}
+
+// racingPayments executa race antes da primeira gravação
+type racingPayments struct {
+	*memory.PaymentRepository
+	race func(ctx context.Context)
+}
+
+func (r *racingPayments) UpdatePayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race(ctx)
+	}
+	return r.PaymentRepository.UpdatePayment(ctx, payment)
+}
+
+func TestRefundService_RejectionRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	payments := memory.NewPaymentRepository(memory.NewOutbox())
+
+	payment := domain.NewPaymentHistory("sub-1", "academy-1", 9900, domain.PaymentGatewayPixAuto, now)
+	txid, e2e := "txid-1", "E123"
+	payment.GatewayPaymentID, payment.EndToEndID = &txid, &e2e
+	payment.Succeed(now)
+	if err := payments.RecordPayment(ctx, payment); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+
+	// Outra escrita no pagamento enquanto a Efí recusa: a gravação da falha colide e recarrega
+	racing := &racingPayments{PaymentRepository: payments}
+	pix := &armedRefundPix{fakeRefundPix: &fakeRefundPix{err: ports.ErrGatewayRejected}, arm: func() {
+		racing.race = func(ctx context.Context) {
+			p, _ := payments.GetByGatewayPaymentID(ctx, txid)
+			_ = payments.UpdatePayment(ctx, p)
+		}
+	}}
+	refunds := NewRefundService(racing, pix, nil)
+	refunds.SetClock(domain.NewFakeClock(now))
+
+	if _, err := refunds.Request(ctx, payment, 9900, "", "user-1"); err == nil {
+		t.Fatal("Request() error = nil, want recusa do gateway")
+	}
+	got, _ := payments.GetByGatewayPaymentID(ctx, txid)
+	if len(got.Refunds) != 1 || got.Refunds[0].Status != domain.RefundStatusFailed || got.RefundableAmount() != 9900 {
+		t.Errorf("refunds = %+v, refundable = %d; want devolução falha gravada", got.Refunds, got.RefundableAmount())
+	}
+}
+
+func TestRefundService_RequestRechecksRefundableOnConflict(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	payments := memory.NewPaymentRepository(memory.NewOutbox())
+
+	payment := domain.NewPaymentHistory("sub-1", "academy-1", 9900, domain.PaymentGatewayPixAuto, now)
+	txid, e2e := "txid-1", "E123"
+	payment.GatewayPaymentID, payment.EndToEndID = &txid, &e2e
+	payment.Succeed(now)
+	if err := payments.RecordPayment(ctx, payment); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+
+	// Outra devolução de 6000 é gravada entre a leitura e a gravação desta:
+	// recarregado, o pagamento só tem 3900 devolvíveis
+	racing := &racingPayments{PaymentRepository: payments, race: func(ctx context.Context) {
+		p, _ := payments.GetByGatewayPaymentID(ctx, txid)
+		if _, err := p.RequestRefund(6000, "", "user-2", now); err != nil {
+			t.Fatal(err)
+		}
+		_ = payments.UpdatePayment(ctx, p)
+	}}
+	pix := &fakeRefundPix{status: domain.RefundStatusPending}
+	refunds := NewRefundService(racing, pix, nil)
+	refunds.SetClock(domain.NewFakeClock(now))
+
+	if _, err := refunds.Request(ctx, payment, 5000, "", "user-1"); !errors.Is(err, domain.ErrRefundNotAllowed) {
+		t.Fatalf("Request() error = %v, want ErrRefundNotAllowed", err)
+	}
+	if len(pix.requests) != 0 {
+		t.Errorf("devoluções pedidas à Efí = %d, want 0", len(pix.requests))
+	}
+	got, _ := payments.GetByGatewayPaymentID(ctx, txid)
+	if len(got.Refunds) != 1 || got.RefundableAmount() != 3900 {
+		t.Errorf("refunds = %+v, refundable = %d; want só a devolução concorrente", got.Refunds, got.RefundableAmount())
+	}
+}
+
+// armedRefundPix chama arm antes de pedir a devolução à Efí
+type armedRefundPix struct {
+	*fakeRefundPix
+	arm func()
+}
+
+func (p *armedRefundPix) RefundPix(ctx context.Context, req *ports.PixRefundRequest) (*ports.RefundResult, error) {
+	p.arm()
+	return p.fakeRefundPix.RefundPix(ctx, req)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// DiscountService resgata cupons e replica o desconto no gateway da assinatura
+type DiscountService struct {
+	subscriptions ports.SubscriptionService
+	plans         ports.PlanService
+	coupons       ports.CouponService
+	uow           ports.UnitOfWork
+	pix           ports.PixProvider
+	stripe        ports.StripeProvider
+
+	clocked
+}
+
+// NewDiscountService cria o serviço de descontos (stripe pode ser nil)
+func NewDiscountService(
+	subscriptions ports.SubscriptionService,
+	plans ports.PlanService,
+	coupons ports.CouponService,
+	uow ports.UnitOfWork,
+	pix ports.PixProvider,
+	stripe ports.StripeProvider,
+) *DiscountService {
+	return &DiscountService{
+		subscriptions: subscriptions,
+		plans:         plans,
+		coupons:       coupons,
+		uow:           uow,
+		pix:           pix,
+		stripe:        stripe,
+	}
+}
+
+// RedeemCoupon aplica um cupom à assinatura da academia.
+// Em trial o desconto só é guardado: a primeira cobrança já sai com o valor líquido.
+// O resgate e o desconto são gravados na mesma transação (recarregando a
+// assinatura a cada conflito de versão) antes de chegar ao gateway; se o
+// gateway recusar, o desconto é retirado e o resgate devolvido.
+func (s *DiscountService) RedeemCoupon(ctx context.Context, academyID, code string) (*domain.Subscription, error) {
+	var (
+		sub      *domain.Subscription
+		previous *domain.SubscriptionDiscount
+		plan     *domain.SubscriptionPlan
+		coupon   *domain.Coupon
+	)
+	err := retryOnConflict(ctx, "assinatura da academia "+academyID, func() error {
+		return s.uow.Do(ctx, func(ctx context.Context) error {
+			var err error
+			coupon, err = s.coupons.GetByCode(ctx, domain.NormalizeCouponCode(code))
+			if err != nil {
+				return fmt.Errorf("erro ao buscar cupom: %w", err)
+			}
+			sub, err = s.subscriptions.GetByAcademy(ctx, academyID)
+			if err != nil {
+				return fmt.Errorf("erro ao buscar assinatura: %w", err)
+			}
+			plan, err = s.plans.GetByID(ctx, sub.PlanID)
+			if err != nil {
+				return fmt.Errorf("erro ao buscar plano: %w", err)
+			}
+			if err := s.checkGateway(sub, coupon); err != nil {
+				return err
+			}
+
+			previous = sub.Discount
+			if err := sub.ApplyCoupon(coupon, s.now()); err != nil {
+				return err
+			}
+			// Redeem garante o limite de resgates sob concorrência
+			if err := s.coupons.Redeem(ctx, coupon.ID); err != nil {
+				return err
+			}
+			// Se a gravação falhar, o rollback desfaz o resgate
+			if err := s.subscriptions.Save(ctx, sub); err != nil {
+				return fmt.Errorf("erro ao salvar assinatura: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.syncGateway(ctx, sub, plan, coupon); err != nil {
+		s.revert(ctx, sub, previous)
+		s.release(ctx, coupon)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// revert retira o desconto gravado que o gateway recusou, restaurando o
+// desconto anterior. Se outro cupom foi aplicado nesse meio tempo, ele prevalece.
+func (s *DiscountService) revert(ctx context.Context, applied *domain.Subscription, previous *domain.SubscriptionDiscount) {
+	err := retryOnConflict(ctx, "assinatura "+applied.ID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, applied.ID)
+		if err != nil {
+			return err
+		}
+		if current.Discount == nil || current.Discount.CouponID != applied.Discount.CouponID ||
+			!current.Discount.AppliedAt.Equal(applied.Discount.AppliedAt) {
+			return nil
+		}
+		current.Discount = previous
+		current.UpdatedAt = s.now()
+		return s.subscriptions.Save(ctx, current)
+	})
+	if err != nil {
+		log.Printf("[Discount] Erro ao desfazer cupom da assinatura %s: %v", applied.ID, err)
+	}
+}
+
+// release devolve o resgate de um cupom cuja aplicação foi desfeita
+func (s *DiscountService) release(ctx context.Context, coupon *domain.Coupon) {
+	if err := s.coupons.Release(ctx, coupon.ID); err != nil {
+		log.Printf("[Discount] Erro ao devolver resgate do cupom %s: %v", coupon.Code, err)
+	}
+}
+
+// checkGateway recusa, antes de gravar, cupons que o gateway da assinatura não aceita
+func (s *DiscountService) checkGateway(sub *domain.Subscription, coupon *domain.Coupon) error {
+	if sub.PaymentGateway == nil || *sub.PaymentGateway != domain.PaymentGatewayStripe {
+		return nil
+	}
+	if s.stripe == nil || sub.StripeSubscriptionID == nil {
+		return fmt.Errorf("assinatura Stripe sem subscription configurada")
+	}
+	if coupon.StripeCouponID == nil {
+		return fmt.Errorf("%w: cupom %s não está disponível para cartão", domain.ErrCouponInvalid, coupon.Code)
+	}
+	return nil
+}
+
+// syncGateway atualiza o valor cobrado pelo gateway da assinatura
+func (s *DiscountService) syncGateway(ctx context.Context, sub *domain.Subscription, plan *domain.SubscriptionPlan, coupon *domain.Coupon) error {
+	if sub.PaymentGateway == nil {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayStripe:
+		if err := s.stripe.ApplyCoupon(ctx, *sub.StripeSubscriptionID, *coupon.StripeCouponID); err != nil {
+			return fmt.Errorf("erro ao aplicar cupom no Stripe: %w", err)
+		}
+	case domain.PaymentGatewayPixAuto:
+		if sub.PixRecurrenceID == nil {
+			return nil
+		}
+		gross, discount, err := sub.NextChargeAmount(plan)
+		if err != nil {
+			return err
+		}
+		if err := s.pix.UpdateRecurrenceAmount(ctx, *sub.PixRecurrenceID, int64(gross-discount)); err != nil {
+			return fmt.Errorf("erro ao atualizar valor da recorrência: %w", err)
+		}
+	}
+	return nil
+}
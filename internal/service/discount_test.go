@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// newDiscountFixture cria a assinatura Pro ativa desde 1/mai e o cupom
+// METADE (50% por um ciclo) com um único resgate
+func newDiscountFixture(t *testing.T) (*serviceFixture, *domain.Coupon) {
+	t.Helper()
+	f := newServiceFixture(t, "plan-pro", time.Date(2026, 5, 1, 9, 0, 0, 0, domain.BillingLocation))
+	limit := 1
+	coupon := &domain.Coupon{
+		ID:             "coupon-1",
+		Code:           "METADE",
+		Type:           domain.CouponTypePercent,
+		PercentOff:     50,
+		Duration:       domain.CouponDurationOnce,
+		MaxRedemptions: &limit,
+		IsActive:       true,
+	}
+	f.coupons.Create(context.Background(), coupon)
+	return f, coupon
+}
+
+func TestDiscountService_RedeemCoupon(t *testing.T) {
+	ctx := context.Background()
+	f, coupon := newDiscountFixture(t)
+	svc := f.discounts()
+
+	if _, err := svc.RedeemCoupon(ctx, "academy-1", " metade "); err != nil {
+		t.Fatalf("RedeemCoupon() error = %v", err)
+	}
+	if f.pix.recurrenceAmount != 9950 || coupon.TimesRedeemed != 1 {
+		t.Errorf("recorrência = %d, resgates = %d, want 9950 e 1", f.pix.recurrenceAmount, coupon.TimesRedeemed)
+	}
+
+	// A cobrança com desconto é registrada com bruto, desconto e cupom
+	f.clock.Set(*f.sub.CurrentPeriodEnd)
+	charge := paidCharge("tx-1")
+	charge.Amount = 9950
+	if err := f.billing().HandleCharge(ctx, charge); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	payment := f.payments.payments[0]
+	if payment.Amount != 9950 || payment.GrossAmount != 19900 || payment.DiscountAmount != 9950 ||
+		payment.CouponID == nil || *payment.CouponID != coupon.ID {
+		t.Errorf("pagamento = %d (bruto %d, desconto %d, cupom %v), want 9950 (19900, 9950, coupon-1)",
+			payment.Amount, payment.GrossAmount, payment.DiscountAmount, payment.CouponID)
+	}
+
+	// Cupom once: depois da cobrança paga o valor volta ao preço cheio
+	if f.pix.recurrenceAmount != 19900 || f.sub.Discount != nil {
+		t.Errorf("recorrência = %d, desconto = %+v, want 19900 sem desconto", f.pix.recurrenceAmount, f.sub.Discount)
+	}
+
+	// Limite de resgates atingido
+	if _, err := svc.RedeemCoupon(ctx, "academy-1", "METADE"); !errors.Is(err, domain.ErrCouponInvalid) {
+		t.Errorf("RedeemCoupon() error = %v, want ErrCouponInvalid", err)
+	}
+}
+
+func TestDiscountService_RedeemCouponGatewayFailure(t *testing.T) {
+	ctx := context.Background()
+	f, coupon := newDiscountFixture(t)
+	svc := f.discounts()
+
+	// Gateway recusa: o desconto é retirado e o resgate devolvido
+	f.pix.err = errors.New("recorrência não encontrada")
+	if _, err := svc.RedeemCoupon(ctx, "academy-1", "METADE"); err == nil {
+		t.Fatal("RedeemCoupon() error = nil, want erro do gateway")
+	}
+	if f.sub.Discount != nil || coupon.TimesRedeemed != 0 {
+		t.Errorf("desconto = %+v, resgates = %d, want nenhum", f.sub.Discount, coupon.TimesRedeemed)
+	}
+
+	// Conflito de versão: a assinatura é recarregada e o cupom resgatado uma vez
+	f.pix.err = nil
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+	svc.subscriptions = subs
+	if _, err := svc.RedeemCoupon(ctx, "academy-1", "METADE"); err != nil {
+		t.Fatalf("RedeemCoupon() error = %v", err)
+	}
+	stored, _ := subs.GetByID(ctx, "sub-1")
+	if stored.Discount == nil || coupon.TimesRedeemed != 1 {
+		t.Errorf("desconto = %+v, resgates = %d, want METADE e 1", stored.Discount, coupon.TimesRedeemed)
+	}
+}
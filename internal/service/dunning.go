@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// dunningChargeExpiry é a validade das cobranças PIX geradas nas retentativas
+const dunningChargeExpiry = 24 * time.Hour
+
+// DunningReport resume uma execução da régua de cobrança
+type DunningReport struct {
+	Processed int // Assinaturas past_due avaliadas
+	Notices   int // Avisos enviados
+	Retries   int // Cobranças de retentativa geradas
+	Canceled  int // Assinaturas canceladas ao fim do grace period
+	Suspended int // Assinaturas suspensas ao fim do grace period
+}
+
+// PaymentSucceededHandler reage a um pagamento confirmado (fatura, nota fiscal)
+type PaymentSucceededHandler interface {
+	HandlePaymentSucceeded(ctx context.Context, payment *domain.PaymentHistory) error
+}
+
+// DunningService conduz assinaturas past_due pela régua de cobrança do plano:
+// avisos escalonados, retentativas via PIX imediato e ação final ao fim do
+// grace period. O estado é derivado do PaymentHistory das falhas.
+type DunningService struct {
+	subscriptions ports.SubscriptionService
+	payments      ports.PaymentService
+	plans         ports.PlanService
+	pix           ports.PixProvider
+	notifier      ports.Notifier
+	onSucceeded   []PaymentSucceededHandler
+
+	clocked
+}
+
+// NewDunningService cria o serviço de dunning
+func NewDunningService(
+	subscriptions ports.SubscriptionService,
+	payments ports.PaymentService,
+	plans ports.PlanService,
+	pix ports.PixProvider,
+	notifier ports.Notifier,
+) *DunningService {
+	return &DunningService{
+		subscriptions: subscriptions,
+		payments:      payments,
+		plans:         plans,
+		pix:           pix,
+		notifier:      notifier,
+	}
+}
+
+// OnPaymentSucceeded registra handlers chamados a cada pagamento confirmado.
+// Falhas deles são logadas e não desfazem a confirmação do pagamento.
+func (s *DunningService) OnPaymentSucceeded(handlers ...PaymentSucceededHandler) {
+	s.onSucceeded = append(s.onSucceeded, handlers...)
+}
+
+// HandlePaymentFailed registra a falha de uma cobrança e coloca a assinatura em past_due.
+// Escritas concorrentes no pagamento ou na assinatura são recarregadas e reaplicadas.
+func (s *DunningService) HandlePaymentFailed(ctx context.Context, payment *domain.PaymentHistory, reason, code string) error {
+	err := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+		p.Fail(reason, code, s.now())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar pagamento: %w", err)
+	}
+
+	var sub *domain.Subscription
+	changed := false
+	err = retryOnConflict(ctx, "assinatura "+payment.SubscriptionID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByID(ctx, payment.SubscriptionID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+
+		// Falhas de retentativas não mudam o status (já está past_due)
+		if sub.Status == domain.SubscriptionStatusPastDue || sub.Status == domain.SubscriptionStatusSuspended {
+			return nil
+		}
+
+		if err := sub.MarkPastDue(reason, domain.ActorWebhook, s.now()); err != nil {
+			return err
+		}
+		sub.DunningNoticesSent = 0
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		changed = true
+		return nil
+	})
+	if err != nil || !changed {
+		return err
+	}
+
+	s.notify(ctx, &ports.Notification{
+		AcademyID: sub.AcademyID,
+		Type:      ports.NotificationPaymentFailed,
+		Data:      map[string]string{"reason": reason},
+	})
+	return nil
+}
+
+// HandlePaymentSucceeded confirma um pagamento e recupera assinaturas past_due/suspended.
+// Um PIX atrasado paga o período em aberto: a assinatura volta para active e renova.
+func (s *DunningService) HandlePaymentSucceeded(ctx context.Context, payment *domain.PaymentHistory) error {
+	err := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+		p.Succeed(s.now())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar pagamento: %w", err)
+	}
+	for _, h := range s.onSucceeded {
+		if err := h.HandlePaymentSucceeded(ctx, payment); err != nil {
+			log.Printf("[Dunning] Erro pós-pagamento %s: %v", payment.ID, err)
+		}
+	}
+
+	var sub *domain.Subscription
+	changed := false
+	err = retryOnConflict(ctx, "assinatura "+payment.SubscriptionID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByID(ctx, payment.SubscriptionID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		// Com cancelamento agendado não há renovação: o job de cancelamentos a encerra
+		if sub.Status != domain.SubscriptionStatusPastDue && sub.Status != domain.SubscriptionStatusSuspended ||
+			sub.CancelAtPeriodEnd {
+			return nil
+		}
+
+		interval := sub.BillingInterval
+		if interval == "" {
+			interval = domain.BillingIntervalMonthly
+		}
+		if err := sub.Renew(interval, s.now()); err != nil {
+			return err
+		}
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		changed = true
+		return nil
+	})
+	if err != nil || !changed {
+		return err
+	}
+
+	s.notify(ctx, &ports.Notification{
+		AcademyID: sub.AcademyID,
+		Type:      ports.NotificationPaymentRecovered,
+	})
+	return nil
+}
+
+// Run avalia todas as assinaturas past_due (job periódico)
+func (s *DunningService) Run(ctx context.Context) (*DunningReport, error) {
+	subs, err := s.subscriptions.ListByStatus(ctx, domain.SubscriptionStatusPastDue, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar assinaturas past_due: %w", err)
+	}
+
+	report := &DunningReport{}
+	for _, sub := range subs {
+		if err := s.process(ctx, sub, report); err != nil {
+			log.Printf("[Dunning] Erro na assinatura %s: %v", sub.ID, err)
+			continue
+		}
+		report.Processed++
+	}
+	return report, nil
+}
+
+// process aplica a régua a uma assinatura past_due
+func (s *DunningService) process(ctx context.Context, sub *domain.Subscription, report *DunningReport) error {
+	plan, err := s.plans.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+	policy := plan.EffectiveDunningPolicy()
+
+	payments, err := s.payments.ListBySubscription(ctx, sub.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao listar pagamentos: %w", err)
+	}
+
+	state, ok := domain.ComputeDunningState(payments)
+	if !ok {
+		// past_due sem falha registrada: a régua conta a partir do fim do período
+		if sub.CurrentPeriodEnd == nil {
+			return nil
+		}
+		state = domain.DunningState{StartedAt: *sub.CurrentPeriodEnd}
+	}
+
+	now := s.now()
+
+	// Fim do grace period: ação final
+	if !now.Before(policy.GraceEndsAt(state)) {
+		return s.applyFinalAction(ctx, sub, policy, report)
+	}
+
+	// Aviso escalonado: a contagem é gravada antes do envio, para que um conflito
+	// com um webhook ou renovação não reenvie o aviso na próxima execução
+	notice, due, err := s.recordNotice(ctx, sub, policy, state, now)
+	if err != nil {
+		return err
+	}
+	if due {
+		s.notify(ctx, &ports.Notification{
+			AcademyID: sub.AcademyID,
+			Type:      ports.NotificationDunningReminder,
+			Level:     notice.Level,
+			Data: map[string]string{
+				"grace_ends_at": policy.GraceEndsAt(state).In(domain.BillingLocation).Format("02/01/2006"),
+			},
+		})
+		report.Notices++
+	}
+
+	// Retentativa via PIX imediato (PIX Automático não permite recobrar a mesma parcela)
+	if s.pix != nil && policy.RetryDue(state, now) && isPixAuto(sub) {
+		if err := s.retryCharge(ctx, sub, plan, state); err != nil {
+			if !errors.Is(err, ports.ErrGatewayUnavailable) {
+				return err
+			}
+			log.Printf("[Dunning] Gateway indisponível, retentativa da assinatura %s adiada", sub.ID)
+		} else {
+			report.Retries++
+		}
+	}
+	return nil
+}
+
+// recordNotice grava na assinatura o aviso mais escalonado já vencido. A cada
+// conflito de versão a assinatura é recarregada e o aviso recalculado: se ela
+// saiu de past_due ou outra execução já gravou o aviso, não há o que enviar.
+func (s *DunningService) recordNotice(ctx context.Context, sub *domain.Subscription, policy domain.DunningPolicy, state domain.DunningState, now time.Time) (domain.DunningNotice, bool, error) {
+	var notice domain.DunningNotice
+	due := false
+	current := sub
+	err := retryOnConflict(ctx, "assinatura "+sub.ID, func() error {
+		if current == nil {
+			fresh, err := s.subscriptions.GetByID(ctx, sub.ID)
+			if err != nil {
+				return fmt.Errorf("erro ao buscar assinatura: %w", err)
+			}
+			current = fresh
+		}
+		var sent int
+		notice, sent, due = policy.DueNotice(state, current.DunningNoticesSent, now)
+		if !due || current.Status != domain.SubscriptionStatusPastDue {
+			due = false
+			return nil
+		}
+		previous := current.DunningNoticesSent
+		current.DunningNoticesSent = sent
+		if err := s.subscriptions.Save(ctx, current); err != nil {
+			current.DunningNoticesSent = previous
+			current, due = nil, false
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	if err == nil && due && current != sub {
+		*sub = *current
+	}
+	return notice, due, err
+}
+
+// retryCharge gera uma cobrança PIX para o período em aberto e avisa a academia
+func (s *DunningService) retryCharge(ctx context.Context, sub *domain.Subscription, plan *domain.SubscriptionPlan, state domain.DunningState) error {
+	gross, discount, err := sub.NextChargeAmount(plan)
+	if err != nil {
+		return err
+	}
+	amount := gross - discount
+
+	req := &ports.PixChargeRequest{
+		Amount:      int64(amount),
+		Description: fmt.Sprintf("BlackBelt %s - mensalidade em atraso", plan.Name),
+		ExpiresIn:   int(dunningChargeExpiry.Seconds()),
+	}
+	if sub.PixCustomerCPF != nil {
+		req.PayerDocument = *sub.PixCustomerCPF
+	}
+	if sub.PixCustomerName != nil {
+		req.PayerName = *sub.PixCustomerName
+	}
+
+	charge, err := s.pix.CreatePixCharge(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	payment := domain.NewPaymentHistory(sub.ID, sub.AcademyID, gross, domain.PaymentGatewayPixAuto, s.now())
+	if sub.Discount != nil {
+		payment.ApplyDiscount(discount, sub.Discount.CouponID)
+	}
+	method := "pix"
+	payment.GatewayPaymentID = &charge.TxID
+	payment.PaymentMethod = &method
+	payment.PeriodStart = sub.CurrentPeriodStart
+	payment.PeriodEnd = sub.CurrentPeriodEnd
+	if err := s.payments.RecordPayment(ctx, payment); err != nil {
+		return fmt.Errorf("erro ao registrar retentativa: %w", err)
+	}
+
+	s.notify(ctx, &ports.Notification{
+		AcademyID: sub.AcademyID,
+		Type:      ports.NotificationDunningRetry,
+		Data: map[string]string{
+			"amount":   strconv.Itoa(amount),
+			"pix_code": charge.PixCode,
+			"attempt":  strconv.Itoa(state.Retries + 1),
+		},
+	})
+	return nil
+}
+
+// applyFinalAction cancela ou suspende a assinatura ao fim do grace period.
+// A assinatura é recarregada a cada conflito de versão (um PIX atrasado pode
+// tê-la recuperado) e a recorrência só é encerrada no gateway depois de gravar.
+func (s *DunningService) applyFinalAction(ctx context.Context, sub *domain.Subscription, policy domain.DunningPolicy, report *DunningReport) error {
+	const reason = "inadimplência: grace period encerrado"
+
+	changed := false
+	err := retryOnConflict(ctx, "assinatura "+sub.ID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, sub.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		if current.Status != domain.SubscriptionStatusPastDue {
+			return nil
+		}
+
+		if policy.FinalAction == domain.DunningFinalActionSuspend {
+			err = current.Suspend(reason, domain.ActorSystem, s.now())
+		} else {
+			err = current.Cancel(reason, false, domain.ActorSystem, s.now())
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.subscriptions.Save(ctx, current); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		sub, changed = current, true
+		return nil
+	})
+	if err != nil || !changed {
+		return err
+	}
+
+	if policy.FinalAction == domain.DunningFinalActionSuspend {
+		report.Suspended++
+	} else {
+		s.cancelRecurrence(ctx, sub)
+		report.Canceled++
+	}
+
+	s.notify(ctx, &ports.Notification{
+		AcademyID: sub.AcademyID,
+		Type:      ports.NotificationDunningFinal,
+		Level:     string(policy.FinalAction),
+	})
+	return nil
+}
+
+// cancelRecurrence encerra a recorrência PIX da assinatura cancelada para não
+// haver novas cobranças. A falha só é registrada: a assinatura já está cancelada.
+func (s *DunningService) cancelRecurrence(ctx context.Context, sub *domain.Subscription) {
+	if s.pix == nil || sub.PixAuthorizationID == nil {
+		return
+	}
+	if err := s.pix.CancelRecurrence(ctx, *sub.PixAuthorizationID); err != nil {
+		log.Printf("[Dunning] Erro ao cancelar recorrência da assinatura %s: %v", sub.ID, err)
+	}
+}
+
+// notify envia uma notificação sem interromper o fluxo em caso de erro
+func (s *DunningService) notify(ctx context.Context, n *ports.Notification) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		log.Printf("[Dunning] Erro ao notificar academia %s (%s): %v", n.AcademyID, n.Type, err)
+	}
+}
+
+// isPixAuto verifica se a assinatura é cobrada via PIX Automático
+func isPixAuto(sub *domain.Subscription) bool {
+	return sub.PaymentGateway != nil && *sub.PaymentGateway == domain.PaymentGatewayPixAuto
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newDunningFixture cria a assinatura Pro ativa de 10/mar a 10/abr, com o
+// relógio no fim do período e a régua policy no plano
+func newDunningFixture(t *testing.T, policy *domain.DunningPolicy) *serviceFixture {
+	t.Helper()
+	f := newServiceFixture(t, "plan-pro", time.Date(2026, 3, 10, 9, 0, 0, 0, domain.BillingLocation))
+	f.plans.plans["plan-pro"].DunningPolicy = policy
+	f.clock.Set(*f.sub.CurrentPeriodEnd)
+	return f
+}
+
+// failRenewal registra a cobrança da renovação e a falha reportada pelo gateway
+func failRenewal(t *testing.T, svc *DunningService, sub *domain.Subscription, payments *fakePayments, at time.Time) {
+	t.Helper()
+	payment := domain.NewPaymentHistory(sub.ID, sub.AcademyID, 19900, domain.PaymentGatewayPixAuto, at)
+	payments.RecordPayment(context.Background(), payment)
+	if err := svc.HandlePaymentFailed(context.Background(), payment, "saldo insuficiente", "AM04"); err != nil {
+		t.Fatalf("HandlePaymentFailed() error = %v", err)
+	}
+}
+
+func TestDunningService_CancelAfterGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	f := newDunningFixture(t, nil)
+	svc, sub, clock := f.dunning(), f.sub, f.clock
+
+	failRenewal(t, svc, sub, f.payments, clock.Now())
+	if sub.Status != domain.SubscriptionStatusPastDue {
+		t.Fatalf("Status = %v, want past_due", sub.Status)
+	}
+
+	// Régua padrão: dia 0 lembrete, 1/3/5 retentativas, 3 alerta, 6 aviso final, 7 cancela
+	steps := []struct {
+		day         int
+		wantNotices int
+		wantRetries int
+	}{
+		{day: 0, wantNotices: 1, wantRetries: 0},
+		{day: 1, wantNotices: 0, wantRetries: 1},
+		{day: 2, wantNotices: 0, wantRetries: 0},
+		{day: 3, wantNotices: 1, wantRetries: 1},
+		{day: 5, wantNotices: 0, wantRetries: 1},
+		{day: 6, wantNotices: 1, wantRetries: 0},
+	}
+	start := clock.Now()
+	for _, step := range steps {
+		clock.Set(start.AddDate(0, 0, step.day))
+		report, err := svc.Run(ctx)
+		if err != nil {
+			t.Fatalf("dia %d: Run() error = %v", step.day, err)
+		}
+		if report.Notices != step.wantNotices || report.Retries != step.wantRetries {
+			t.Errorf("dia %d: report = %+v, want %d avisos e %d retentativas", step.day, report, step.wantNotices, step.wantRetries)
+		}
+	}
+	if sub.Status != domain.SubscriptionStatusPastDue {
+		t.Fatalf("Status = %v, want past_due durante o grace period", sub.Status)
+	}
+
+	clock.Set(start.AddDate(0, 0, 7))
+	report, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Canceled != 1 || sub.Status != domain.SubscriptionStatusCanceled {
+		t.Fatalf("report = %+v, Status = %v, want canceled", report, sub.Status)
+	}
+	if len(f.pix.canceled) != 1 || f.pix.canceled[0] != "rec-1" {
+		t.Errorf("CancelRecurrence chamado com %v, want [rec-1]", f.pix.canceled)
+	}
+
+	sent := f.notifier.Sent()
+	if last := sent[len(sent)-1]; last.Type != ports.NotificationDunningFinal {
+		t.Errorf("última notificação = %v, want dunning_final", last.Type)
+	}
+}
+
+func TestDunningService_SuspendAndRecover(t *testing.T) {
+	ctx := context.Background()
+	policy := &domain.DunningPolicy{GraceDays: 3, FinalAction: domain.DunningFinalActionSuspend}
+	f := newDunningFixture(t, policy)
+	svc, sub, clock := f.dunning(), f.sub, f.clock
+
+	failRenewal(t, svc, sub, f.payments, clock.Now())
+
+	clock.Set(clock.Now().AddDate(0, 0, 3))
+	report, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Suspended != 1 || sub.Status != domain.SubscriptionStatusSuspended {
+		t.Fatalf("report = %+v, Status = %v, want suspended", report, sub.Status)
+	}
+	if len(f.pix.canceled) != 0 {
+		t.Errorf("suspensão não deveria cancelar a recorrência")
+	}
+
+	// PIX atrasado recebido: volta para active e o período avança
+	periodEnd := *sub.CurrentPeriodEnd
+	late := domain.NewPaymentHistory(sub.ID, sub.AcademyID, 19900, domain.PaymentGatewayPixAuto, clock.Now())
+	f.payments.RecordPayment(ctx, late)
+	if err := svc.HandlePaymentSucceeded(ctx, late); err != nil {
+		t.Fatalf("HandlePaymentSucceeded() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusActive {
+		t.Fatalf("Status = %v, want active", sub.Status)
+	}
+	if !sub.CurrentPeriodEnd.After(periodEnd) {
+		t.Errorf("CurrentPeriodEnd = %v, want depois de %v", sub.CurrentPeriodEnd, periodEnd)
+	}
+
+	sent := f.notifier.Sent()
+	if last := sent[len(sent)-1]; last.Type != ports.NotificationPaymentRecovered {
+		t.Errorf("última notificação = %v, want payment_recovered", last.Type)
+	}
+}
+
+func TestDunningService_RetryPostponedWhenGatewayUnavailable(t *testing.T) {
+	f := newDunningFixture(t, nil)
+	svc, sub, clock := f.dunning(), f.sub, f.clock
+	failRenewal(t, svc, sub, f.payments, clock.Now())
+
+	f.pix.err = &unavailableError{}
+	clock.Set(clock.Now().AddDate(0, 0, 1))
+	report, err := svc.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Retries != 0 || report.Processed != 1 {
+		t.Errorf("report = %+v, want retentativa adiada sem erro", report)
+	}
+	if len(f.payments.payments) != 1 {
+		t.Errorf("payments = %d, want 1 (nenhuma retentativa registrada)", len(f.payments.payments))
+	}
+}
+
+func TestDunningService_FinalActionReloadsOnConflict(t *testing.T) {
+	ctx := context.Background()
+	f := newDunningFixture(t, &domain.DunningPolicy{GraceDays: 3})
+	svc := f.dunning()
+	failRenewal(t, svc, f.sub, f.payments, f.clock.Now())
+
+	// Outra escrita chega antes: a primeira gravação colide e a assinatura é recarregada
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+	svc.subscriptions = subs
+	f.clock.Set(f.clock.Now().AddDate(0, 0, 3))
+	report, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	stored, _ := subs.GetByID(ctx, "sub-1")
+	if report.Canceled != 1 || stored.Status != domain.SubscriptionStatusCanceled {
+		t.Fatalf("report = %+v, Status = %v, want canceled", report, stored.Status)
+	}
+	if len(f.pix.canceled) != 1 {
+		t.Errorf("CancelRecurrence chamado %d vezes, want 1 (só depois de gravar)", len(f.pix.canceled))
+	}
+
+	// Gravação que nunca passa não encerra a recorrência no gateway
+	f = newDunningFixture(t, &domain.DunningPolicy{GraceDays: 3})
+	svc = f.dunning()
+	failRenewal(t, svc, f.sub, f.payments, f.clock.Now())
+	svc.subscriptions = &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: maxConflictRetries + 1}
+	f.clock.Set(f.clock.Now().AddDate(0, 0, 3))
+	if report, _ := svc.Run(ctx); report.Canceled != 0 || len(f.pix.canceled) != 0 {
+		t.Errorf("report = %+v, canceladas no gateway = %v, want nenhuma", report, f.pix.canceled)
+	}
+}
+
+func TestDunningService_NoticeRecordedBeforeSending(t *testing.T) {
+	ctx := context.Background()
+	f := newDunningFixture(t, nil)
+	svc := f.dunning()
+	failRenewal(t, svc, f.sub, f.payments, f.clock.Now())
+	failed := len(f.notifier.Sent())
+
+	// Conflito na gravação do aviso: a assinatura é recarregada e o aviso sai uma vez
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+	svc.subscriptions = subs
+	report, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	stored, _ := subs.GetByID(ctx, "sub-1")
+	if report.Notices != 1 || stored.DunningNoticesSent != 1 {
+		t.Fatalf("report = %+v, avisos gravados = %d; want 1 e 1", report, stored.DunningNoticesSent)
+	}
+
+	// A próxima execução não reenvia o aviso já gravado
+	if report, _ := svc.Run(ctx); report.Notices != 0 {
+		t.Errorf("segunda execução: report = %+v, want nenhum aviso", report)
+	}
+	if sent := len(f.notifier.Sent()) - failed; sent != 1 {
+		t.Errorf("avisos enviados = %d, want 1", sent)
+	}
+
+	// Gravação que nunca passa não envia o aviso
+	f = newDunningFixture(t, nil)
+	svc = f.dunning()
+	failRenewal(t, svc, f.sub, f.payments, f.clock.Now())
+	failed = len(f.notifier.Sent())
+	svc.subscriptions = &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: maxConflictRetries + 1}
+	if report, _ := svc.Run(ctx); report.Notices != 0 || len(f.notifier.Sent()) != failed {
+		t.Errorf("report = %+v, notificações = %d; want nenhum aviso", report, len(f.notifier.Sent())-failed)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// ResourceUsage é o uso de um recurso limitado frente ao limite do plano
+type ResourceUsage struct {
+	Used      int  `json:"used"`
+	Limit     *int `json:"limit"`     // nil = ilimitado
+	Remaining *int `json:"remaining"` // nil = ilimitado
+}
+
+// Entitlements é o que a academia pode usar com a assinatura atual
+type Entitlements struct {
+	AcademyID string                            `json:"academy_id"`
+	PlanSlug  string                            `json:"plan"`
+	Status    domain.SubscriptionStatus         `json:"status"`
+	Access    bool                              `json:"access"`   // false = assinatura não libera o plano
+	Features  []domain.Feature                  `json:"features"` // Vazio sem acesso
+	Limits    map[domain.Resource]ResourceUsage `json:"limits"`
+}
+
+// EntitlementsService responde o que cada academia pode usar,
+// combinando status da assinatura, features e limites do plano
+type EntitlementsService struct {
+	subscriptions ports.SubscriptionService
+	plans         ports.PlanService
+	usage         ports.UsageCounter
+}
+
+// NewEntitlementsService cria o serviço de entitlements
+func NewEntitlementsService(subscriptions ports.SubscriptionService, plans ports.PlanService, usage ports.UsageCounter) *EntitlementsService {
+	return &EntitlementsService{
+		subscriptions: subscriptions,
+		plans:         plans,
+		usage:         usage,
+	}
+}
+
+// Get retorna os entitlements completos da academia (features e uso dos limites)
+func (s *EntitlementsService) Get(ctx context.Context, academyID string) (*Entitlements, error) {
+	sub, plan, err := s.load(ctx, academyID)
+	if err != nil {
+		return nil, err
+	}
+
+	ent := &Entitlements{
+		AcademyID: academyID,
+		PlanSlug:  plan.Slug,
+		Status:    sub.Status,
+		Access:    sub.HasAccess(),
+		Features:  []domain.Feature{},
+		Limits:    make(map[domain.Resource]ResourceUsage, len(domain.ValidResources)),
+	}
+	if ent.Access {
+		ent.Features = append(ent.Features, plan.Features...)
+	}
+
+	for _, resource := range domain.ValidResources {
+		used, err := s.usage.CountUsage(ctx, academyID, resource)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao contar %s: %w", resource, err)
+		}
+		usage := ResourceUsage{Used: used, Limit: plan.Limit(resource)}
+		if usage.Limit != nil {
+			remaining := *usage.Limit - used
+			if remaining < 0 || !ent.Access {
+				remaining = 0
+			}
+			usage.Remaining = &remaining
+		}
+		ent.Limits[resource] = usage
+	}
+	return ent, nil
+}
+
+// CheckFeature retorna nil se a academia pode usar a feature
+func (s *EntitlementsService) CheckFeature(ctx context.Context, academyID string, feature domain.Feature) error {
+	sub, plan, err := s.load(ctx, academyID)
+	if err != nil {
+		return err
+	}
+	if !sub.HasAccess() {
+		return fmt.Errorf("%w: %s", domain.ErrSubscriptionInactive, sub.Status)
+	}
+	if !plan.HasFeature(feature) {
+		return fmt.Errorf("%w: %s (plano %s)", domain.ErrFeatureUnavailable, feature, plan.Slug)
+	}
+	return nil
+}
+
+// CheckCapacity retorna nil se a academia pode adicionar n itens do recurso
+func (s *EntitlementsService) CheckCapacity(ctx context.Context, academyID string, resource domain.Resource, n int) error {
+	sub, plan, err := s.load(ctx, academyID)
+	if err != nil {
+		return err
+	}
+	if !sub.HasAccess() {
+		return fmt.Errorf("%w: %s", domain.ErrSubscriptionInactive, sub.Status)
+	}
+
+	limit := plan.Limit(resource)
+	if limit == nil {
+		return nil
+	}
+
+	used, err := s.usage.CountUsage(ctx, academyID, resource)
+	if err != nil {
+		return fmt.Errorf("erro ao contar %s: %w", resource, err)
+	}
+	if used+n > *limit {
+		return fmt.Errorf("%w: %s %d/%d (plano %s)", domain.ErrLimitReached, resource, used, *limit, plan.Slug)
+	}
+	return nil
+}
+
+// load busca a assinatura e o plano atual da academia
+func (s *EntitlementsService) load(ctx context.Context, academyID string) (*domain.Subscription, *domain.SubscriptionPlan, error) {
+	sub, err := s.subscriptions.GetByAcademy(ctx, academyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+	plan, err := s.plans.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+	return sub, plan, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+// fakeUsage é um UsageCounter com contagens fixas
+type fakeUsage map[domain.Resource]int
+
+func (f fakeUsage) CountUsage(ctx context.Context, academyID string, resource domain.Resource) (int, error) {
+	return f[resource], nil
+}
+
+func TestEntitlementsService(t *testing.T) {
+	f := newTrialFixture(t, "plan-starter", time.Now())
+	maxStudents, maxProfessors := 50, 2
+	starter := f.plans.plans["plan-starter"]
+	starter.MaxStudents = &maxStudents
+	starter.MaxProfessors = &maxProfessors
+	starter.Features = domain.FeatureSet{domain.FeatureCheckin, domain.FeatureSchedule, domain.FeatureProfiles}
+
+	sub := f.sub
+	usage := fakeUsage{domain.ResourceStudents: 50, domain.ResourceProfessors: 1, domain.ResourceLocations: 1}
+	svc := NewEntitlementsService(f.subs, f.plans, usage)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		status  domain.SubscriptionStatus
+		check   func() error
+		wantErr error
+	}{
+		{"feature in plan", domain.SubscriptionStatusActive, func() error { return svc.CheckFeature(ctx, "academy-1", domain.FeatureCheckin) }, nil},
+		{"feature not in plan", domain.SubscriptionStatusActive, func() error { return svc.CheckFeature(ctx, "academy-1", domain.FeatureAnalytics) }, domain.ErrFeatureUnavailable},
+		{"past due keeps access", domain.SubscriptionStatusPastDue, func() error { return svc.CheckFeature(ctx, "academy-1", domain.FeatureCheckin) }, nil},
+		{"suspended blocks access", domain.SubscriptionStatusSuspended, func() error { return svc.CheckFeature(ctx, "academy-1", domain.FeatureCheckin) }, domain.ErrSubscriptionInactive},
+		{"student limit reached", domain.SubscriptionStatusActive, func() error { return svc.CheckCapacity(ctx, "academy-1", domain.ResourceStudents, 1) }, domain.ErrLimitReached},
+		{"professor below limit", domain.SubscriptionStatusActive, func() error { return svc.CheckCapacity(ctx, "academy-1", domain.ResourceProfessors, 1) }, nil},
+		{"second location needs multi_location", domain.SubscriptionStatusActive, func() error { return svc.CheckCapacity(ctx, "academy-1", domain.ResourceLocations, 1) }, domain.ErrLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub.Status = tt.status
+			err := tt.check()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	sub.Status = domain.SubscriptionStatusExpired
+	ent, err := svc.Get(ctx, "academy-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if ent.Access || len(ent.Features) != 0 {
+		t.Errorf("Get() = %+v, want sem acesso e sem features", ent)
+	}
+	if students := ent.Limits[domain.ResourceStudents]; students.Used != 50 || students.Limit == nil || *students.Limit != 50 {
+		t.Errorf("Limits[students] = %+v, want 50/50", students)
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Backoff das retentativas de entrega de eventos
+const (
+	eventRetryBase = 30 * time.Second
+	eventRetryMax  = time.Hour
+)
+
+// EventHandler recebe um evento de domínio. A entrega é at-least-once por
+// subscriber: o dispatcher registra quem já recebeu cada evento e só reentrega
+// aos que falharam, mas uma falha ao gravar esse registro causa reentrega.
+type EventHandler func(ctx context.Context, event domain.DomainEvent) error
+
+// DispatchReport resume uma execução do dispatcher
+type DispatchReport struct {
+	Dispatched int
+	Failed     int
+	Deferred   int // Aguardando retentativa ou um evento anterior do mesmo agregado
+}
+
+type eventSubscriber struct {
+	name    string
+	types   map[domain.EventType]bool // Vazio = todos os tipos
+	handler EventHandler
+}
+
+// EventDispatcher entrega os eventos do outbox aos subscribers em processo.
+// Eventos de um mesmo agregado são entregues na ordem em que foram gravados:
+// se um falhar, os seguintes do agregado esperam a retentativa dele.
+type EventDispatcher struct {
+	outbox      ports.Outbox
+	subscribers []eventSubscriber
+
+	clocked
+}
+
+// NewEventDispatcher cria o dispatcher
+func NewEventDispatcher(outbox ports.Outbox) *EventDispatcher {
+	return &EventDispatcher{outbox: outbox}
+}
+
+// Subscribe registra um handler para os tipos informados (nenhum = todos)
+func (d *EventDispatcher) Subscribe(name string, handler EventHandler, types ...domain.EventType) {
+	sub := eventSubscriber{name: name, handler: handler, types: map[domain.EventType]bool{}}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	d.subscribers = append(d.subscribers, sub)
+}
+
+// DispatchPending entrega até limit eventos pendentes. Uma mensagem só é
+// marcada como entregue depois que todos os subscribers tiveram sucesso;
+// em caso de falha ela volta apenas para os subscribers que falharam.
+func (d *EventDispatcher) DispatchPending(ctx context.Context, limit int) (*DispatchReport, error) {
+	messages, err := d.outbox.ListPending(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar outbox: %w", err)
+	}
+
+	report := &DispatchReport{}
+	blocked := map[string]bool{}
+	for _, msg := range messages {
+		key := msg.Event.AggregateType + ":" + msg.Event.AggregateID
+		now := d.now()
+		if blocked[key] || msg.NextAttemptAt.After(now) {
+			blocked[key] = true
+			report.Deferred++
+			continue
+		}
+
+		if err := d.deliver(ctx, msg); err != nil {
+			blocked[key] = true
+			report.Failed++
+			next := now.Add(eventBackoff(msg.Attempts))
+			log.Printf("[Events] Falha ao entregar %s (%s), nova tentativa em %s: %v",
+				msg.Event.Type, msg.Event.ID, next.Format(time.RFC3339), err)
+			if err := d.outbox.MarkFailed(ctx, msg.Sequence, err.Error(), next); err != nil {
+				log.Printf("[Events] Erro ao registrar falha do evento %s: %v", msg.Event.ID, err)
+			}
+			continue
+		}
+
+		if err := d.outbox.MarkDispatched(ctx, msg.Sequence, now); err != nil {
+			// O evento será reentregue; os subscribers deduplicam pelo ID
+			blocked[key] = true
+			log.Printf("[Events] Erro ao marcar evento %s como entregue: %v", msg.Event.ID, err)
+			continue
+		}
+		report.Dispatched++
+	}
+	return report, nil
+}
+
+// deliver chama os subscribers interessados no evento que ainda não o
+// receberam, registrando cada entrega bem-sucedida
+func (d *EventDispatcher) deliver(ctx context.Context, msg *ports.OutboxMessage) error {
+	delivered := map[string]bool{}
+	for _, name := range msg.DeliveredTo {
+		delivered[name] = true
+	}
+
+	event := msg.Event
+	for _, sub := range d.subscribers {
+		if delivered[sub.name] || (len(sub.types) > 0 && !sub.types[event.Type]) {
+			continue
+		}
+		if err := sub.handler(ctx, event); err != nil {
+			return fmt.Errorf("subscriber %s: %w", sub.name, err)
+		}
+		if err := d.outbox.MarkDelivered(ctx, msg.Sequence, sub.name); err != nil {
+			// Sem o registro o subscriber recebe o evento de novo na retentativa
+			log.Printf("[Events] Erro ao registrar entrega de %s para %s: %v", event.ID, sub.name, err)
+		}
+	}
+	return nil
+}
+
+// Run entrega eventos periodicamente até o contexto ser cancelado
+func (d *EventDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if report, err := d.DispatchPending(ctx, 100); err != nil {
+				log.Printf("[Events] Erro ao processar outbox: %v", err)
+			} else if report.Dispatched > 0 || report.Failed > 0 {
+				log.Printf("[Events] %d entregue(s), %d falha(s)", report.Dispatched, report.Failed)
+			}
+		}
+	}
+}
+
+// eventBackoff dobra o intervalo a cada tentativa, até eventRetryMax
+func eventBackoff(attempts int) time.Duration {
+	delay := eventRetryBase
+	for i := 0; i < attempts && delay < eventRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > eventRetryMax {
+		delay = eventRetryMax
+	}
+	return delay
+}
+
+// ──────────────────────────────────────────────
+// Subscribers
+// ──────────────────────────────────────────────
+
+// EventMetrics conta os eventos entregues por tipo
+type EventMetrics struct {
+	mu     sync.Mutex
+	counts map[domain.EventType]int
+}
+
+// NewEventMetrics cria o contador
+func NewEventMetrics() *EventMetrics {
+	return &EventMetrics{counts: map[domain.EventType]int{}}
+}
+
+// Handle incrementa o contador do tipo do evento (EventHandler)
+func (m *EventMetrics) Handle(ctx context.Context, event domain.DomainEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[event.Type]++
+	return nil
+}
+
+// Snapshot devolve uma cópia dos contadores
+func (m *EventMetrics) Snapshot() map[domain.EventType]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[domain.EventType]int, len(m.counts))
+	for k, v := range m.counts {
+		out[k] = v
+	}
+	return out
+}
+
+// eventNotifications mapeia eventos para as notificações enviadas ao dono da academia
+var eventNotifications = map[domain.EventType]ports.NotificationType{
+	domain.EventTrialStarted:         ports.NotificationTrialStarted,
+	domain.EventSubscriptionCanceled: ports.NotificationSubscriptionCanceled,
+	domain.EventPaymentRefunded:      ports.NotificationPaymentRefunded,
+}
+
+// NotifyOnEvent cria um EventHandler que notifica a academia dos eventos
+// em eventNotifications (os de cobrança já são notificados pelo dunning).
+// Retentativas de outros subscribers não reenviam a notificação: o
+// dispatcher só reentrega o evento a quem falhou.
+func NotifyOnEvent(notifier ports.Notifier) EventHandler {
+	return func(ctx context.Context, event domain.DomainEvent) error {
+		notificationType, ok := eventNotifications[event.Type]
+		if !ok {
+			return nil
+		}
+		data := map[string]string{"event_id": event.ID}
+		for k, v := range event.Data {
+			data[k] = v
+		}
+		return notifier.Notify(ctx, &ports.Notification{
+			AcademyID: event.AcademyID,
+			Type:      notificationType,
+			Data:      data,
+		})
+	}
+}
+
+// NotifiedEventTypes lista os tipos tratados por NotifyOnEvent (para Subscribe)
+func NotifiedEventTypes() []domain.EventType {
+	return []domain.EventType{
+		domain.EventTrialStarted,
+		domain.EventSubscriptionCanceled,
+		domain.EventPaymentRefunded,
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+func TestEventDispatcher_DeliversInOrder(t *testing.T) {
+	ctx := context.Background()
+	outbox := memory.NewOutbox()
+
+	sub := domain.NewTrialSubscription("academy-1", domain.TrialTerms{PlanID: "plan-1", Days: 7}, time.Now())
+	sub.ID = "sub-1"
+	now := time.Now()
+	if err := sub.Activate(domain.PaymentGatewayPixAuto, now, now.AddDate(0, 1, 0), domain.ActorWebhook, now); err != nil {
+		t.Fatal(err)
+	}
+	outbox.Append(ctx, sub.PullEvents()...)
+
+	var got []domain.EventType
+	d := NewEventDispatcher(outbox)
+	d.Subscribe("recorder", func(ctx context.Context, e domain.DomainEvent) error {
+		got = append(got, e.Type)
+		return nil
+	})
+	metrics := NewEventMetrics()
+	d.Subscribe("metrics", metrics.Handle, domain.EventSubscriptionActivated)
+
+	report, err := d.DispatchPending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Dispatched != 2 {
+		t.Errorf("Dispatched = %d, want 2", report.Dispatched)
+	}
+	if len(got) != 2 || got[0] != domain.EventTrialStarted || got[1] != domain.EventSubscriptionActivated {
+		t.Errorf("ordem = %v", got)
+	}
+	if counts := metrics.Snapshot(); counts[domain.EventSubscriptionActivated] != 1 || counts[domain.EventTrialStarted] != 0 {
+		t.Errorf("métricas = %v", counts)
+	}
+
+	if pending, _ := outbox.ListPending(ctx, 10); len(pending) != 0 {
+		t.Errorf("pendentes = %d, want 0", len(pending))
+	}
+}
+
+func TestEventDispatcher_RetryKeepsAggregateOrder(t *testing.T) {
+	ctx := context.Background()
+	outbox := memory.NewOutbox()
+	now := time.Now()
+
+	event := func(id, aggregate string) domain.DomainEvent {
+		return domain.DomainEvent{ID: id, Type: domain.EventPaymentSucceeded, AggregateType: domain.AggregatePayment, AggregateID: aggregate, OccurredAt: now}
+	}
+	outbox.Append(ctx, event("e1", "payment-1"), event("e2", "payment-2"), event("e3", "payment-1"))
+
+	failing := true
+	var delivered []string
+	d := NewEventDispatcher(outbox)
+	clock := domain.NewFakeClock(now)
+	d.SetClock(clock)
+	d.Subscribe("webhooks", func(ctx context.Context, e domain.DomainEvent) error {
+		if e.ID == "e1" && failing {
+			return errors.New("endpoint fora do ar")
+		}
+		delivered = append(delivered, e.ID)
+		return nil
+	})
+
+	report, err := d.DispatchPending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// e3 espera e1 (mesmo agregado); e2 segue
+	if report.Dispatched != 1 || report.Failed != 1 || report.Deferred != 1 {
+		t.Errorf("report = %+v, want 1 entregue, 1 falha, 1 adiado", report)
+	}
+	msgs := outbox.Messages()
+	if msgs[0].Attempts != 1 || msgs[0].LastError == nil || !msgs[0].NextAttemptAt.Equal(now.Add(eventRetryBase)) {
+		t.Errorf("e1 = %+v, falha não registrada", msgs[0])
+	}
+
+	// Antes do backoff nada é reenviado
+	if report, _ := d.DispatchPending(ctx, 10); report.Dispatched != 0 || report.Deferred != 2 {
+		t.Errorf("report antes do backoff = %+v", report)
+	}
+
+	failing = false
+	clock.Advance(time.Minute)
+	if report, _ := d.DispatchPending(ctx, 10); report.Dispatched != 2 {
+		t.Errorf("report após o backoff = %+v, want 2 entregues", report)
+	}
+	want := []string{"e2", "e1", "e3"}
+	if len(delivered) != len(want) {
+		t.Fatalf("entregues = %v, want %v", delivered, want)
+	}
+	for i := range want {
+		if delivered[i] != want[i] {
+			t.Errorf("entregues = %v, want %v", delivered, want)
+			break
+		}
+	}
+}
+
+func TestEventDispatcher_RetryOnlyFailedSubscribers(t *testing.T) {
+	ctx := context.Background()
+	outbox := memory.NewOutbox()
+	now := time.Now()
+	outbox.Append(ctx, domain.DomainEvent{
+		ID: "e1", Type: domain.EventSubscriptionCanceled, AggregateType: domain.AggregateSubscription,
+		AggregateID: "sub-1", AcademyID: "academy-1", OccurredAt: now,
+	})
+
+	notifier := memory.NewNotifier()
+	failing := true
+	webhooks := 0
+	d := NewEventDispatcher(outbox)
+	clock := domain.NewFakeClock(now)
+	d.SetClock(clock)
+	d.Subscribe("notificações", NotifyOnEvent(notifier), NotifiedEventTypes()...)
+	d.Subscribe("webhooks", func(ctx context.Context, e domain.DomainEvent) error {
+		webhooks++
+		if failing {
+			return errors.New("endpoint fora do ar")
+		}
+		return nil
+	})
+
+	if report, _ := d.DispatchPending(ctx, 10); report.Failed != 1 {
+		t.Fatalf("report = %+v, want 1 falha", report)
+	}
+	if got := outbox.Messages()[0].DeliveredTo; len(got) != 1 || got[0] != "notificações" {
+		t.Errorf("DeliveredTo = %v, want [notificações]", got)
+	}
+
+	failing = false
+	clock.Advance(time.Minute)
+	if report, _ := d.DispatchPending(ctx, 10); report.Dispatched != 1 {
+		t.Fatalf("report após o backoff = %+v, want 1 entregue", report)
+	}
+	if sent := notifier.Sent(); len(sent) != 1 {
+		t.Errorf("notificações = %d, want 1 (a retentativa não deve renotificar)", len(sent))
+	}
+	if webhooks != 2 {
+		t.Errorf("chamadas ao webhook = %d, want 2", webhooks)
+	}
+}
+
+func TestEventBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{3, 4 * time.Minute},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := eventBackoff(tt.attempts); got != tt.want {
+			t.Errorf("eventBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNotifyOnEvent(t *testing.T) {
+	notifier := memory.NewNotifier()
+	handle := NotifyOnEvent(notifier)
+
+	ctx := context.Background()
+	handle(ctx, domain.DomainEvent{ID: "e1", Type: domain.EventSubscriptionCanceled, AcademyID: "academy-1", Data: map[string]string{"reason": "preço"}})
+	handle(ctx, domain.DomainEvent{ID: "e2", Type: domain.EventSubscriptionActivated, AcademyID: "academy-1"})
+
+	sent := notifier.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("notificações = %d, want 1", len(sent))
+	}
+	if sent[0].Type != ports.NotificationSubscriptionCanceled || sent[0].Data["reason"] != "preço" || sent[0].Data["event_id"] != "e1" {
+		t.Errorf("notificação = %+v", sent[0])
+	}
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// serviceFixture reúne os fakes dos testes de serviço em torno de uma
+// assinatura (sub-1 da academy-1) cobrada via PIX Automático, com autorização
+// e recorrência rec-1, os planos Starter (9900) e Pro (19900) e o relógio
+// parado em start. Os serviços são montados pelos métodos, já com o relógio.
+type serviceFixture struct {
+	start    time.Time
+	sub      *domain.Subscription
+	subs     *fakeSubscriptions
+	plans    *fakePlans
+	payments *fakePayments
+	coupons  *fakeCoupons
+	pix      *fakePix
+	notifier *memory.Notifier
+	clock    *domain.FakeClock
+}
+
+// newTrialFixture cria a assinatura em trial de 14 dias no plano planID
+func newTrialFixture(t *testing.T, planID string, start time.Time) *serviceFixture {
+	t.Helper()
+
+	starter := domain.NewSubscriptionPlan("Starter", "starter", 9900, start)
+	starter.ID = "plan-starter"
+	pro := domain.NewSubscriptionPlan("Pro", "pro", 19900, start)
+	pro.ID = "plan-pro"
+
+	sub := domain.NewTrialSubscription("academy-1", domain.TrialTerms{PlanID: planID, Days: 14}, start)
+	sub.ID = "sub-1"
+	authorizationID, recurrenceID := "rec-1", "rec-1"
+	sub.PixAuthorizationID = &authorizationID
+	sub.PixRecurrenceID = &recurrenceID
+
+	return &serviceFixture{
+		start:    start,
+		sub:      sub,
+		subs:     newFakeSubscriptions(sub),
+		plans:    newFakePlans(starter, pro),
+		payments: &fakePayments{},
+		coupons:  newFakeCoupons(),
+		pix:      &fakePix{},
+		notifier: memory.NewNotifier(),
+		clock:    domain.NewFakeClock(start),
+	}
+}
+
+// newServiceFixture cria a assinatura ativa no plano planID, com período
+// mensal de start até o mesmo dia do mês seguinte
+func newServiceFixture(t *testing.T, planID string, start time.Time) *serviceFixture {
+	t.Helper()
+
+	f := newTrialFixture(t, planID, start)
+	end := domain.NextBillingDate(start, domain.BillingIntervalMonthly, start.In(domain.BillingLocation).Day())
+	if err := f.sub.Activate(domain.PaymentGatewayPixAuto, start, end, domain.ActorWebhook, start); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *serviceFixture) dunning() *DunningService {
+	svc := NewDunningService(f.subs, f.payments, f.plans, f.pix, f.notifier)
+	svc.SetClock(f.clock)
+	return svc
+}
+
+func (f *serviceFixture) planChanges() *PlanChangeService {
+	svc := NewPlanChangeService(f.subs, f.plans, f.payments, f.pix, nil)
+	svc.SetClock(f.clock)
+	return svc
+}
+
+func (f *serviceFixture) discounts() *DiscountService {
+	svc := NewDiscountService(f.subs, f.plans, f.coupons, &couponsUnitOfWork{coupons: f.coupons}, f.pix, nil)
+	svc.SetClock(f.clock)
+	return svc
+}
+
+func (f *serviceFixture) subscriptionCheckout() *SubscriptionCheckoutService {
+	svc := NewSubscriptionCheckoutService(f.subs, f.plans, f.payments,
+		NewCheckoutService(f.pix, memory.NewChargeQueue(), CheckoutOptions{}), nil)
+	svc.SetClock(f.clock)
+	return svc
+}
+
+func (f *serviceFixture) billing() *BillingService {
+	svc := NewBillingService(f.subs, f.payments, f.planChanges(), f.dunning())
+	svc.SetClock(f.clock)
+	return svc
+}
+
+// fakeSubscriptions é um SubscriptionService em memória para testes
+type fakeSubscriptions struct {
+	ports.SubscriptionService
+	subs map[string]*domain.Subscription
+}
+
+func newFakeSubscriptions(subs ...*domain.Subscription) *fakeSubscriptions {
+	f := &fakeSubscriptions{subs: make(map[string]*domain.Subscription)}
+	for _, sub := range subs {
+		f.subs[sub.ID] = sub
+	}
+	return f
+}
+
+func (f *fakeSubscriptions) Create(ctx context.Context, sub *domain.Subscription) error {
+	if sub.ID == "" {
+		sub.ID = fmt.Sprintf("sub-%d", len(f.subs)+1)
+	}
+	f.subs[sub.ID] = sub
+	return nil
+}
+
+func (f *fakeSubscriptions) GetByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	sub, ok := f.subs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return sub, nil
+}
+
+func (f *fakeSubscriptions) GetByAcademy(ctx context.Context, academyID string) (*domain.Subscription, error) {
+	for _, sub := range f.subs {
+		if sub.AcademyID == academyID {
+			return sub, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeSubscriptions) GetByPixRecurrenceID(ctx context.Context, recurrenceID string) (*domain.Subscription, error) {
+	for _, sub := range f.subs {
+		if sub.PixRecurrenceID != nil && *sub.PixRecurrenceID == recurrenceID {
+			return sub, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeSubscriptions) ListByStatus(ctx context.Context, status domain.SubscriptionStatus, limit int) ([]*domain.Subscription, error) {
+	var result []*domain.Subscription
+	for _, sub := range f.subs {
+		if sub.Status == status {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeSubscriptions) ListCancellationsDue(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error) {
+	var result []*domain.Subscription
+	for _, sub := range f.subs {
+		if sub.GatewayCancelPending || sub.CancelAtPeriodEnd && sub.CurrentPeriodEnd != nil && !now.Before(*sub.CurrentPeriodEnd) {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeSubscriptions) Save(ctx context.Context, sub *domain.Subscription) error {
+	f.subs[sub.ID] = sub
+	return nil
+}
+
+// detachedSubscriptions devolve cópias na leitura, como os repositórios reais:
+// alterações só valem depois do Save. As primeiras conflicts gravações falham
+// com ErrConcurrentModification, como se outra escrita tivesse vindo antes.
+type detachedSubscriptions struct {
+	*fakeSubscriptions
+	conflicts int
+}
+
+func (f *detachedSubscriptions) GetByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	sub, err := f.fakeSubscriptions.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	loaded := *sub
+	return &loaded, nil
+}
+
+func (f *detachedSubscriptions) GetByAcademy(ctx context.Context, academyID string) (*domain.Subscription, error) {
+	sub, err := f.fakeSubscriptions.GetByAcademy(ctx, academyID)
+	if err != nil {
+		return nil, err
+	}
+	loaded := *sub
+	return &loaded, nil
+}
+
+func (f *detachedSubscriptions) Save(ctx context.Context, sub *domain.Subscription) error {
+	if f.conflicts > 0 {
+		f.conflicts--
+		return domain.ErrConcurrentModification
+	}
+	stored := *sub
+	return f.fakeSubscriptions.Save(ctx, &stored)
+}
+
+// conflictingSubscriptions simula outra escrita na assinatura a cada gravação
+type conflictingSubscriptions struct {
+	*fakeSubscriptions
+}
+
+func (f conflictingSubscriptions) Save(ctx context.Context, sub *domain.Subscription) error {
+	return domain.ErrConcurrentModification
+}
+
+// fakePayments é um PaymentService em memória para testes
+type fakePayments struct {
+	ports.PaymentService
+	payments []*domain.PaymentHistory
+}
+
+func (f *fakePayments) RecordPayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	if payment.ID == "" {
+		payment.ID = fmt.Sprintf("payment-%d", len(f.payments)+1)
+	}
+	f.payments = append(f.payments, payment)
+	return nil
+}
+
+func (f *fakePayments) UpdatePayment(ctx context.Context, payment *domain.PaymentHistory) error {
+	for i, p := range f.payments {
+		if p.ID == payment.ID {
+			f.payments[i] = payment
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (f *fakePayments) GetByID(ctx context.Context, id string) (*domain.PaymentHistory, error) {
+	for _, p := range f.payments {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakePayments) GetByGatewayPaymentID(ctx context.Context, gatewayPaymentID string) (*domain.PaymentHistory, error) {
+	for _, p := range f.payments {
+		if p.GatewayPaymentID != nil && *p.GatewayPaymentID == gatewayPaymentID {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakePayments) ListBySubscription(ctx context.Context, subscriptionID string) ([]*domain.PaymentHistory, error) {
+	var result []*domain.PaymentHistory
+	for _, p := range f.payments {
+		if p.SubscriptionID == subscriptionID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakePayments) ListWithPendingRefunds(ctx context.Context, requestedBefore time.Time) ([]*domain.PaymentHistory, error) {
+	var result []*domain.PaymentHistory
+	for _, p := range f.payments {
+		for _, refund := range p.Refunds {
+			if refund.Status == domain.RefundStatusPending && !refund.RequestedAt.After(requestedBefore) {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// fakePlans é um PlanService em memória para testes
+type fakePlans struct {
+	ports.PlanService
+	plans map[string]*domain.SubscriptionPlan
+}
+
+func newFakePlans(plans ...*domain.SubscriptionPlan) *fakePlans {
+	f := &fakePlans{plans: make(map[string]*domain.SubscriptionPlan)}
+	for _, plan := range plans {
+		f.plans[plan.ID] = plan
+	}
+	return f
+}
+
+func (f *fakePlans) GetByID(ctx context.Context, id string) (*domain.SubscriptionPlan, error) {
+	plan, ok := f.plans[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return plan, nil
+}
+
+func (f *fakePlans) GetBySlug(ctx context.Context, slug string) (*domain.SubscriptionPlan, error) {
+	for _, plan := range f.plans {
+		if plan.Slug == slug {
+			return plan, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// couponsUnitOfWork imita o rollback da transação do Postgres para os cupons:
+// se fn falhar, os resgates feitos dentro dela são desfeitos
+type couponsUnitOfWork struct {
+	coupons *fakeCoupons
+}
+
+func (u *couponsUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	redeemed := make(map[string]int, len(u.coupons.coupons))
+	for code, c := range u.coupons.coupons {
+		redeemed[code] = c.TimesRedeemed
+	}
+	if err := fn(ctx); err != nil {
+		for code, n := range redeemed {
+			u.coupons.coupons[code].TimesRedeemed = n
+		}
+		return err
+	}
+	return nil
+}
+
+// fakeCoupons é um CouponService em memória para testes
+type fakeCoupons struct {
+	coupons map[string]*domain.Coupon
+}
+
+func newFakeCoupons(coupons ...*domain.Coupon) *fakeCoupons {
+	f := &fakeCoupons{coupons: make(map[string]*domain.Coupon)}
+	for _, c := range coupons {
+		f.coupons[c.Code] = c
+	}
+	return f
+}
+
+func (f *fakeCoupons) Create(ctx context.Context, coupon *domain.Coupon) error {
+	f.coupons[coupon.Code] = coupon
+	return nil
+}
+
+func (f *fakeCoupons) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	c, ok := f.coupons[code]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (f *fakeCoupons) Redeem(ctx context.Context, couponID string) error {
+	for _, c := range f.coupons {
+		if c.ID == couponID {
+			if c.MaxRedemptions != nil && c.TimesRedeemed >= *c.MaxRedemptions {
+				return domain.ErrCouponInvalid
+			}
+			c.TimesRedeemed++
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (f *fakeCoupons) Release(ctx context.Context, couponID string) error {
+	for _, c := range f.coupons {
+		if c.ID == couponID {
+			c.TimesRedeemed--
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// fakeInvoices é um InvoiceService em memória com numeração por academia
+type fakeInvoices struct {
+	ports.InvoiceService
+	invoices []*domain.Invoice
+	counters map[string]int64
+}
+
+func (f *fakeInvoices) Create(ctx context.Context, invoice *domain.Invoice) error {
+	if f.counters == nil {
+		f.counters = make(map[string]int64)
+	}
+	f.counters[invoice.AcademyID]++
+	invoice.Number = f.counters[invoice.AcademyID]
+	invoice.ID = fmt.Sprintf("inv-%d", len(f.invoices)+1)
+	f.invoices = append(f.invoices, invoice)
+	return nil
+}
+
+func (f *fakeInvoices) GetByID(ctx context.Context, id string) (*domain.Invoice, error) {
+	for _, inv := range f.invoices {
+		if inv.ID == id {
+			return inv, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeInvoices) GetByPaymentID(ctx context.Context, paymentID string) (*domain.Invoice, error) {
+	for _, inv := range f.invoices {
+		if inv.PaymentID == paymentID {
+			return inv, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeInvoices) Save(ctx context.Context, invoice *domain.Invoice) error {
+	return nil
+}
+
+// fakeNFSe é um NFSeService em memória com numeração de RPS sequencial
+type fakeNFSe struct {
+	ports.NFSeService
+	notes []*domain.NFSe
+}
+
+func (f *fakeNFSe) Create(ctx context.Context, nfse *domain.NFSe) error {
+	nfse.ID = fmt.Sprintf("nfse-%d", len(f.notes)+1)
+	nfse.RPSNumber = int64(len(f.notes) + 1)
+	f.notes = append(f.notes, nfse)
+	return nil
+}
+
+func (f *fakeNFSe) GetByPaymentID(ctx context.Context, paymentID string) (*domain.NFSe, error) {
+	for _, n := range f.notes {
+		if n.PaymentID == paymentID {
+			return n, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeNFSe) ListByStatus(ctx context.Context, status domain.NFSeStatus, limit int) ([]*domain.NFSe, error) {
+	var result []*domain.NFSe
+	for _, n := range f.notes {
+		if n.Status == status {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeNFSe) Save(ctx context.Context, nfse *domain.NFSe) error {
+	return nil
+}
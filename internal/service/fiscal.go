@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// DefaultRPSSeries é a série dos RPS emitidos pela BlackBelt
+const DefaultRPSSeries = "A"
+
+// FiscalReport resume uma execução do job de retentativa de NFS-e
+type FiscalReport struct {
+	Issued int
+	Failed int
+}
+
+// FiscalService emite a NFS-e de cada pagamento confirmado e a cancela no estorno
+type FiscalService struct {
+	nfse       ports.NFSeService
+	provider   ports.FiscalProvider
+	fiscalData ports.FiscalDataService
+	series     string
+
+	clocked
+}
+
+// NewFiscalService cria o serviço fiscal (series vazia = DefaultRPSSeries)
+func NewFiscalService(nfse ports.NFSeService, provider ports.FiscalProvider, fiscalData ports.FiscalDataService, series string) *FiscalService {
+	if series == "" {
+		series = DefaultRPSSeries
+	}
+	return &FiscalService{
+		nfse:       nfse,
+		provider:   provider,
+		fiscalData: fiscalData,
+		series:     series,
+	}
+}
+
+// HandlePaymentSucceeded emite a NFS-e do pagamento (PaymentSucceededHandler)
+func (s *FiscalService) HandlePaymentSucceeded(ctx context.Context, payment *domain.PaymentHistory) error {
+	_, err := s.Issue(ctx, payment)
+	return err
+}
+
+// Issue emite a NFS-e de um pagamento confirmado. O RPS é numerado e gravado
+// antes do envio: se a prefeitura falhar, a nota fica failed e é reenviada
+// com o mesmo RPS pelo job Run.
+func (s *FiscalService) Issue(ctx context.Context, payment *domain.PaymentHistory) (*domain.NFSe, error) {
+	nfse, err := s.nfse.GetByPaymentID(ctx, payment.ID)
+	switch {
+	case err == nil:
+		if nfse.Status == domain.NFSeStatusIssued || nfse.Status == domain.NFSeStatusCanceled {
+			return nfse, nil
+		}
+	case errors.Is(err, domain.ErrNotFound):
+		nfse, err = domain.NewNFSe(payment, s.series, serviceDescription(payment), s.now())
+		if err != nil {
+			return nil, err
+		}
+		err = s.nfse.Create(ctx, nfse)
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			// Outra entrega do mesmo pagamento numerou o RPS e está enviando
+			return s.nfse.GetByPaymentID(ctx, payment.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao numerar RPS: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("erro ao buscar NFS-e do pagamento: %w", err)
+	}
+
+	return nfse, s.send(ctx, nfse)
+}
+
+// HandlePaymentRefunded cancela a NFS-e do pagamento estornado (PaymentRefundedHandler)
+func (s *FiscalService) HandlePaymentRefunded(ctx context.Context, payment *domain.PaymentHistory) error {
+	nfse, err := s.nfse.GetByPaymentID(ctx, payment.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar NFS-e do pagamento: %w", err)
+	}
+	if nfse.Status == domain.NFSeStatusCanceled {
+		return nil
+	}
+
+	// Nota ainda não emitida: basta parar de reenviar
+	if nfse.Status == domain.NFSeStatusIssued {
+		if err := s.provider.CancelNFSe(ctx, &ports.NFSeCancelRequest{
+			Number: *nfse.Number,
+			Code:   domain.NFSeCancelServiceNotDone,
+		}); err != nil {
+			return fmt.Errorf("erro ao cancelar NFS-e %s: %w", *nfse.Number, err)
+		}
+	}
+
+	if err := nfse.MarkCanceled(s.now()); err != nil {
+		return err
+	}
+	if err := s.nfse.Save(ctx, nfse); err != nil {
+		return fmt.Errorf("erro ao salvar NFS-e: %w", err)
+	}
+	return nil
+}
+
+// Run reenvia as NFS-e que falharam (job periódico)
+func (s *FiscalService) Run(ctx context.Context) (*FiscalReport, error) {
+	failed, err := s.nfse.ListByStatus(ctx, domain.NFSeStatusFailed, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar NFS-e com falha: %w", err)
+	}
+
+	report := &FiscalReport{}
+	for _, nfse := range failed {
+		if err := s.send(ctx, nfse); err != nil {
+			log.Printf("[Fiscal] Erro ao reenviar RPS %s-%d: %v", nfse.RPSSeries, nfse.RPSNumber, err)
+			report.Failed++
+			continue
+		}
+		report.Issued++
+	}
+	return report, nil
+}
+
+// send envia o RPS à prefeitura e registra o resultado
+func (s *FiscalService) send(ctx context.Context, nfse *domain.NFSe) error {
+	sendErr := s.issue(ctx, nfse)
+	if sendErr != nil {
+		nfse.MarkFailed(sendErr, s.now())
+	}
+	if err := s.nfse.Save(ctx, nfse); err != nil {
+		return fmt.Errorf("erro ao salvar NFS-e: %w", err)
+	}
+	return sendErr
+}
+
+// issue monta o RPS com os dados fiscais da academia e chama o provider
+func (s *FiscalService) issue(ctx context.Context, nfse *domain.NFSe) error {
+	nfse.Attempts++
+
+	tomador, err := s.fiscalData.GetFiscalParty(ctx, nfse.AcademyID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar dados fiscais da academia: %w", err)
+	}
+
+	now := s.now()
+	result, err := s.provider.IssueNFSe(ctx, &ports.NFSeIssueRequest{
+		RPSNumber:   nfse.RPSNumber,
+		RPSSeries:   nfse.RPSSeries,
+		IssueDate:   now,
+		Competence:  nfse.Competence,
+		Amount:      nfse.Amount,
+		Description: nfse.Description,
+		Tomador:     *tomador,
+	})
+	if err != nil {
+		return err
+	}
+	nfse.MarkIssued(result.Number, result.VerificationCode, string(result.XML), now)
+	return nil
+}
+
+// serviceDescription monta a discriminação do serviço da nota
+func serviceDescription(payment *domain.PaymentHistory) string {
+	desc := "Licença de uso do software BlackBelt (assinatura)"
+	if payment.PeriodStart != nil && payment.PeriodEnd != nil {
+		desc += fmt.Sprintf(" - período de %s a %s",
+			payment.PeriodStart.In(domain.BillingLocation).Format("02/01/2006"),
+			payment.PeriodEnd.In(domain.BillingLocation).Format("02/01/2006"))
+	}
+	return desc
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// fakeFiscalProvider simula a prefeitura
+type fakeFiscalProvider struct {
+	err      error
+	issued   []int64 // RPS recebidos
+	canceled []string
+}
+
+func (f *fakeFiscalProvider) IssueNFSe(ctx context.Context, req *ports.NFSeIssueRequest) (*ports.NFSeIssueResult, error) {
+	f.issued = append(f.issued, req.RPSNumber)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ports.NFSeIssueResult{Number: strconv.FormatInt(1000+req.RPSNumber, 10), VerificationCode: "ABCD1234", XML: []byte("<Nfse/>")}, nil
+}
+
+func (f *fakeFiscalProvider) CancelNFSe(ctx context.Context, req *ports.NFSeCancelRequest) error {
+	f.canceled = append(f.canceled, req.Number)
+	return f.err
+}
+
+type fakeFiscalData struct{}
+
+func (fakeFiscalData) GetFiscalParty(ctx context.Context, academyID string) (*domain.FiscalParty, error) {
+	return &domain.FiscalParty{Document: "98765432000155", Name: "Academia " + academyID}, nil
+}
+
+func TestFiscalService_IssueOnPaymentAndCancelOnRefund(t *testing.T) {
+	ctx := context.Background()
+
+	sub := &domain.Subscription{ID: "sub-1", AcademyID: "academy-1", Status: domain.SubscriptionStatusActive}
+	payments := &fakePayments{}
+	payment := domain.NewPaymentHistory(sub.ID, sub.AcademyID, 9900, domain.PaymentGatewayPixAuto, time.Now())
+	payments.RecordPayment(ctx, payment)
+
+	notes := &fakeNFSe{}
+	provider := &fakeFiscalProvider{}
+	fiscal := NewFiscalService(notes, provider, fakeFiscalData{}, "")
+
+	dunning := NewDunningService(newFakeSubscriptions(sub), payments, newFakePlans(), nil, nil)
+	dunning.OnPaymentSucceeded(fiscal)
+	refunds := NewRefundService(payments, nil, nil)
+	refunds.OnPaymentRefunded(fiscal)
+
+	if err := dunning.HandlePaymentSucceeded(ctx, payment); err != nil {
+		t.Fatalf("HandlePaymentSucceeded() error = %v", err)
+	}
+	if len(notes.notes) != 1 {
+		t.Fatalf("NFS-e emitidas = %d, want 1", len(notes.notes))
+	}
+	nfse := notes.notes[0]
+	if nfse.Status != domain.NFSeStatusIssued || *nfse.Number != "1001" || nfse.RPSSeries != DefaultRPSSeries || nfse.Amount != 9900 {
+		t.Errorf("NFS-e = %+v", nfse)
+	}
+
+	txid := "txid-1"
+	payment.GatewayPaymentID = &txid
+	if err := refunds.HandleRefundNotification(ctx, &ports.RefundNotification{
+		GatewayPaymentID: txid, RefundID: "dev1", Amount: 9900, Status: domain.RefundStatusSucceeded,
+	}); err != nil {
+		t.Fatalf("HandleRefundNotification() error = %v", err)
+	}
+	if payment.Status != domain.PaymentStatusRefunded || nfse.Status != domain.NFSeStatusCanceled {
+		t.Errorf("payment = %s, nfse = %s, want refunded e canceled", payment.Status, nfse.Status)
+	}
+	if len(provider.canceled) != 1 || provider.canceled[0] != "1001" {
+		t.Errorf("cancelamentos = %v, want [1001]", provider.canceled)
+	}
+}
+
+func TestFiscalService_RetryKeepsRPS(t *testing.T) {
+	ctx := context.Background()
+
+	notes := &fakeNFSe{}
+	provider := &fakeFiscalProvider{err: fmt.Errorf("webservice da prefeitura indisponível")}
+	fiscal := NewFiscalService(notes, provider, fakeFiscalData{}, "B")
+
+	payment := domain.NewPaymentHistory("sub-1", "academy-1", 9900, domain.PaymentGatewayPixAuto, time.Now())
+	payment.ID = "payment-1"
+	payment.Succeed(time.Now())
+
+	if _, err := fiscal.Issue(ctx, payment); err == nil {
+		t.Fatal("Issue() deveria propagar a falha da prefeitura")
+	}
+	nfse := notes.notes[0]
+	if nfse.Status != domain.NFSeStatusFailed || nfse.ErrorMessage == nil {
+		t.Fatalf("NFS-e = %+v, want failed com erro", nfse)
+	}
+
+	provider.err = nil
+	report, err := fiscal.Run(ctx)
+	if err != nil || report.Issued != 1 {
+		t.Fatalf("Run() = %+v, %v", report, err)
+	}
+	if nfse.Status != domain.NFSeStatusIssued || nfse.Attempts != 2 || len(notes.notes) != 1 {
+		t.Errorf("NFS-e = %+v (notas = %d)", nfse, len(notes.notes))
+	}
+	if provider.issued[0] != provider.issued[1] {
+		t.Errorf("RPS reenviados = %v, want o mesmo número", provider.issued)
+	}
+
+	// Webhook repetido não reemite
+	if _, err := fiscal.Issue(ctx, payment); err != nil || len(provider.issued) != 2 {
+		t.Errorf("reemissão: err = %v, envios = %d", err, len(provider.issued))
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// InvoiceFormat é o formato de download de uma fatura
+type InvoiceFormat string
+
+const (
+	InvoiceFormatPDF  InvoiceFormat = "pdf"
+	InvoiceFormatHTML InvoiceFormat = "html"
+)
+
+// InvoiceDocument é uma fatura renderizada para download
+type InvoiceDocument struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// InvoiceService emite as faturas dos ciclos pagos e gera os documentos
+type InvoiceService struct {
+	invoices      ports.InvoiceService
+	subscriptions ports.SubscriptionService
+	plans         ports.PlanService
+	branding      ports.BrandingService
+	renderer      ports.InvoiceRenderer
+	taxes         []domain.InvoiceTaxRate
+
+	clocked
+}
+
+// NewInvoiceService cria o serviço de faturas. taxes são os tributos
+// aproximados informados em cada fatura (pode ser vazio).
+func NewInvoiceService(
+	invoices ports.InvoiceService,
+	subscriptions ports.SubscriptionService,
+	plans ports.PlanService,
+	branding ports.BrandingService,
+	renderer ports.InvoiceRenderer,
+	taxes []domain.InvoiceTaxRate,
+) *InvoiceService {
+	return &InvoiceService{
+		invoices:      invoices,
+		subscriptions: subscriptions,
+		plans:         plans,
+		branding:      branding,
+		renderer:      renderer,
+		taxes:         taxes,
+	}
+}
+
+// HandlePaymentSucceeded emite a fatura do ciclo pago (PaymentSucceededHandler)
+func (s *InvoiceService) HandlePaymentSucceeded(ctx context.Context, payment *domain.PaymentHistory) error {
+	_, err := s.IssueForPayment(ctx, payment)
+	return err
+}
+
+// HandlePaymentRefunded anula a fatura do pagamento estornado (PaymentRefundedHandler).
+// O número não é reaproveitado: a numeração continua sem lacunas.
+func (s *InvoiceService) HandlePaymentRefunded(ctx context.Context, payment *domain.PaymentHistory) error {
+	invoice, err := s.invoices.GetByPaymentID(ctx, payment.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar fatura do pagamento: %w", err)
+	}
+	if invoice.Status == domain.InvoiceStatusVoid {
+		return nil
+	}
+
+	invoice.Void()
+	if err := s.invoices.Save(ctx, invoice); err != nil {
+		return fmt.Errorf("erro ao anular fatura: %w", err)
+	}
+	return nil
+}
+
+// IssueForPayment emite a fatura de um pagamento confirmado. É idempotente:
+// webhooks repetidos devolvem a fatura já emitida sem consumir outro número,
+// inclusive quando duas entregas simultâneas tentam criá-la.
+func (s *InvoiceService) IssueForPayment(ctx context.Context, payment *domain.PaymentHistory) (*domain.Invoice, error) {
+	existing, err := s.invoices.GetByPaymentID(ctx, payment.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("erro ao buscar fatura do pagamento: %w", err)
+	}
+
+	sub, err := s.subscriptions.GetByID(ctx, payment.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+	plan, err := s.plans.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+
+	interval := sub.BillingInterval
+	if interval == "" {
+		interval = domain.BillingIntervalMonthly
+	}
+	invoice, err := domain.NewInvoice(payment, plan, interval, s.taxes, s.now())
+	if err != nil {
+		return nil, err
+	}
+	err = s.invoices.Create(ctx, invoice)
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		return s.invoices.GetByPaymentID(ctx, payment.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar fatura: %w", err)
+	}
+	return invoice, nil
+}
+
+// List lista as faturas da academia
+func (s *InvoiceService) List(ctx context.Context, academyID string) ([]*domain.Invoice, error) {
+	return s.invoices.ListByAcademy(ctx, academyID)
+}
+
+// Download renderiza uma fatura da academia. Faturas de outra academia são
+// tratadas como inexistentes.
+func (s *InvoiceService) Download(ctx context.Context, academyID, invoiceID string, format InvoiceFormat) (*InvoiceDocument, error) {
+	invoice, err := s.invoices.GetByID(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.AcademyID != academyID {
+		return nil, domain.ErrNotFound
+	}
+
+	branding, err := s.branding.GetBranding(ctx, academyID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar dados da academia: %w", err)
+	}
+
+	doc := &InvoiceDocument{Filename: "fatura-" + invoice.DisplayNumber()}
+	switch format {
+	case InvoiceFormatHTML:
+		doc.ContentType = "text/html; charset=utf-8"
+		doc.Filename += ".html"
+		doc.Content, err = s.renderer.RenderHTML(ctx, invoice, branding)
+	case "", InvoiceFormatPDF:
+		doc.ContentType = "application/pdf"
+		doc.Filename += ".pdf"
+		doc.Content, err = s.renderer.RenderPDF(ctx, invoice, branding)
+	default:
+		return nil, fmt.Errorf("%w: formato inválido: %q", domain.ErrValidation, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
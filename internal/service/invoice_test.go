@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// fakeBranding devolve a mesma identidade visual para qualquer academia
+type fakeBranding struct{}
+
+func (fakeBranding) GetBranding(ctx context.Context, academyID string) (*domain.Branding, error) {
+	return &domain.Branding{AcademyName: "Academia " + academyID}, nil
+}
+
+// fakeRenderer devolve o número da fatura como documento
+type fakeRenderer struct {
+	ports.InvoiceRenderer
+}
+
+func (fakeRenderer) RenderPDF(ctx context.Context, invoice *domain.Invoice, branding *domain.Branding) ([]byte, error) {
+	return []byte(invoice.DisplayNumber()), nil
+}
+
+func TestInvoiceService_IssueForPayment(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, domain.BillingLocation)
+
+	plan := domain.NewSubscriptionPlan("Starter", "starter", 9900, now)
+	plan.ID = "plan-starter"
+	subA := &domain.Subscription{ID: "sub-a", AcademyID: "academy-a", PlanID: plan.ID, BillingInterval: domain.BillingIntervalMonthly}
+	subB := &domain.Subscription{ID: "sub-b", AcademyID: "academy-b", PlanID: plan.ID, BillingInterval: domain.BillingIntervalMonthly}
+
+	invoices := &fakeInvoices{}
+	svc := NewInvoiceService(invoices, newFakeSubscriptions(subA, subB), newFakePlans(plan), fakeBranding{}, fakeRenderer{}, nil)
+
+	paid := func(id string, sub *domain.Subscription) *domain.PaymentHistory {
+		p := domain.NewPaymentHistory(sub.ID, sub.AcademyID, 9900, domain.PaymentGatewayPixAuto, time.Now())
+		p.ID = id
+		p.Succeed(time.Now())
+		return p
+	}
+
+	a1, err := svc.IssueForPayment(ctx, paid("payment-1", subA))
+	if err != nil {
+		t.Fatalf("IssueForPayment() error = %v", err)
+	}
+	b1, _ := svc.IssueForPayment(ctx, paid("payment-2", subB))
+	a2, _ := svc.IssueForPayment(ctx, paid("payment-3", subA))
+
+	// Numeração independente por academia
+	if a1.Number != 1 || a2.Number != 2 || b1.Number != 1 {
+		t.Errorf("numbers = a%d a%d b%d, want a1 a2 b1", a1.Number, a2.Number, b1.Number)
+	}
+
+	// Webhook repetido não consome outro número
+	again, err := svc.IssueForPayment(ctx, paid("payment-1", subA))
+	if err != nil || again.ID != a1.ID || len(invoices.invoices) != 3 {
+		t.Errorf("reemissão = %+v, %v (faturas = %d), want a mesma fatura", again, err, len(invoices.invoices))
+	}
+
+	doc, err := svc.Download(ctx, "academy-a", a2.ID, InvoiceFormatPDF)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if doc.Filename != "fatura-000002.pdf" || doc.ContentType != "application/pdf" || string(doc.Content) != "000002" {
+		t.Errorf("doc = %s %s %q", doc.Filename, doc.ContentType, doc.Content)
+	}
+
+	// Fatura de outra academia é tratada como inexistente
+	if _, err := svc.Download(ctx, "academy-b", a2.ID, InvoiceFormatPDF); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Download() de outra academia error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// PauseRequest é o pedido de pausa de uma academia
+type PauseRequest struct {
+	StartAt  time.Time `json:"start_at"` // Zero = agora
+	ResumeAt time.Time `json:"resume_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// PauseReport resume uma execução do job de pausas
+type PauseReport struct {
+	Started int
+	Resumed int
+}
+
+// PauseService pausa e retoma assinaturas, suspendendo as cobranças no gateway
+type PauseService struct {
+	subscriptions ports.SubscriptionService
+	pix           ports.PixProvider
+	stripe        ports.StripeProvider
+
+	clocked
+	audited
+}
+
+// NewPauseService cria o serviço de pausas (stripe pode ser nil)
+func NewPauseService(subscriptions ports.SubscriptionService, pix ports.PixProvider, stripe ports.StripeProvider) *PauseService {
+	return &PauseService{
+		subscriptions: subscriptions,
+		pix:           pix,
+		stripe:        stripe,
+	}
+}
+
+// Pause agenda (ou inicia, se StartAt já chegou) a pausa da assinatura da academia
+func (s *PauseService) Pause(ctx context.Context, academyID string, req *PauseRequest, actor string) (*domain.Subscription, error) {
+	now := s.now()
+	sub, before, err := s.apply(ctx, "assinatura da academia "+academyID,
+		func() (*domain.Subscription, error) { return s.subscriptions.GetByAcademy(ctx, academyID) },
+		func(sub *domain.Subscription) error {
+			return sub.SchedulePause(req.StartAt, req.ResumeAt, req.Reason, actor, now)
+		})
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, domain.AuditSubscriptionPaused, before, sub, now)
+	return sub, nil
+}
+
+// Resume retoma a assinatura antes da data planejada (ou desiste de uma pausa agendada)
+func (s *PauseService) Resume(ctx context.Context, academyID, actor string) (*domain.Subscription, error) {
+	sub, before, err := s.apply(ctx, "assinatura da academia "+academyID,
+		func() (*domain.Subscription, error) { return s.subscriptions.GetByAcademy(ctx, academyID) },
+		func(sub *domain.Subscription) error {
+			if sub.Status != domain.SubscriptionStatusPaused {
+				return sub.CancelScheduledPause()
+			}
+			return sub.Resume(actor, s.now())
+		})
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, domain.AuditSubscriptionResumed, before, sub, s.now())
+	return sub, nil
+}
+
+// Run inicia pausas agendadas e retoma pausas vencidas (job periódico)
+func (s *PauseService) Run(ctx context.Context) (*PauseReport, error) {
+	now := s.now()
+	report := &PauseReport{}
+
+	active, err := s.subscriptions.ListByStatus(ctx, domain.SubscriptionStatusActive, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar assinaturas ativas: %w", err)
+	}
+	for _, listed := range active {
+		if pause := listed.CurrentPause(); pause == nil || pause.StartAt.After(now) {
+			continue
+		}
+		sub, before, err := s.apply(ctx, "assinatura "+listed.ID, s.loader(ctx, listed.ID),
+			func(sub *domain.Subscription) error {
+				if pause := sub.CurrentPause(); pause == nil || pause.StartAt.After(now) {
+					return fmt.Errorf("%w: pausa não está mais vencida", domain.ErrInvalidTransition)
+				}
+				return sub.StartPause(domain.ActorSystem, now)
+			})
+		if err != nil {
+			log.Printf("[Pause] Erro ao iniciar pausa da assinatura %s: %v", listed.ID, err)
+			continue
+		}
+		s.audit(ctx, domain.AuditSubscriptionPaused, before, sub, now)
+		report.Started++
+	}
+
+	paused, err := s.subscriptions.ListByStatus(ctx, domain.SubscriptionStatusPaused, 0)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar assinaturas pausadas: %w", err)
+	}
+	for _, listed := range paused {
+		if pause := listed.CurrentPause(); pause == nil || pause.ResumeAt.After(now) {
+			continue
+		}
+		sub, before, err := s.apply(ctx, "assinatura "+listed.ID, s.loader(ctx, listed.ID),
+			func(sub *domain.Subscription) error {
+				if pause := sub.CurrentPause(); pause == nil || pause.ResumeAt.After(now) {
+					return fmt.Errorf("%w: retomada não está mais vencida", domain.ErrInvalidTransition)
+				}
+				return sub.Resume(domain.ActorSystem, now)
+			})
+		if err != nil {
+			log.Printf("[Pause] Erro ao retomar assinatura %s: %v", listed.ID, err)
+			continue
+		}
+		s.audit(ctx, domain.AuditSubscriptionResumed, before, sub, now)
+		report.Resumed++
+	}
+	return report, nil
+}
+
+// apply grava a pausa ou retomada feita por mutate (recarregando a assinatura
+// com load a cada conflito de versão) e só então a replica no gateway. Se o
+// gateway falhar, a gravação é desfeita: a assinatura continua cobrando (ou
+// pausada) como antes e o job tenta de novo na próxima execução.
+func (s *PauseService) apply(
+	ctx context.Context,
+	what string,
+	load func() (*domain.Subscription, error),
+	mutate func(*domain.Subscription) error,
+) (*domain.Subscription, json.RawMessage, error) {
+	var (
+		sub, previous *domain.Subscription
+		before        json.RawMessage
+	)
+	err := retryOnConflict(ctx, what, func() error {
+		var err error
+		if sub, err = load(); err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		before = snapshot(sub)
+		loaded := *sub
+		// As janelas de pausa são alteradas no lugar: previous guarda uma cópia
+		loaded.Pauses = append([]domain.PauseWindow(nil), sub.Pauses...)
+		previous = &loaded
+
+		if err := mutate(sub); err != nil {
+			return err
+		}
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.syncGateway(ctx, previous, sub); err != nil {
+		s.revert(ctx, previous, sub)
+		return nil, nil, err
+	}
+	return sub, before, nil
+}
+
+// loader recarrega a assinatura pelo ID (usado pelo job)
+func (s *PauseService) loader(ctx context.Context, id string) func() (*domain.Subscription, error) {
+	return func() (*domain.Subscription, error) { return s.subscriptions.GetByID(ctx, id) }
+}
+
+// syncGateway suspende as cobranças de uma pausa que começou ou as retoma,
+// com o próximo vencimento já deslocado pelos dias pausados, numa retomada
+func (s *PauseService) syncGateway(ctx context.Context, previous, sub *domain.Subscription) error {
+	wasPaused := previous.Status == domain.SubscriptionStatusPaused
+	isPaused := sub.Status == domain.SubscriptionStatusPaused
+	switch {
+	case !wasPaused && isPaused:
+		return s.pauseGateway(ctx, sub)
+	case wasPaused && !isPaused:
+		var nextDueDate time.Time
+		if sub.CurrentPeriodEnd != nil {
+			nextDueDate = *sub.CurrentPeriodEnd
+		}
+		return s.resumeGateway(ctx, sub, nextDueDate)
+	}
+	return nil
+}
+
+// revert desfaz uma pausa ou retomada gravada que o gateway recusou, voltando
+// ao status, às janelas de pausa e ao período de previous. Se a assinatura
+// mudou nesse meio tempo, a mudança prevalece.
+func (s *PauseService) revert(ctx context.Context, previous, applied *domain.Subscription) {
+	err := retryOnConflict(ctx, "assinatura "+applied.ID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, applied.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != applied.Status || len(current.Pauses) != len(applied.Pauses) {
+			return nil
+		}
+		if err := current.TransitionTo(previous.Status, "gateway não confirmou a mudança", domain.ActorSystem, s.now()); err != nil {
+			return err
+		}
+		current.Pauses = previous.Pauses
+		current.CurrentPeriodEnd = previous.CurrentPeriodEnd
+		current.BillingAnchorDay = previous.BillingAnchorDay
+		return s.subscriptions.Save(ctx, current)
+	})
+	if err != nil {
+		log.Printf("[Pause] Erro ao desfazer mudança da assinatura %s: %v", applied.ID, err)
+	}
+}
+
+// pauseGateway suspende as cobranças da assinatura no gateway. Com
+// cancelamento agendado elas já estão suspensas.
+func (s *PauseService) pauseGateway(ctx context.Context, sub *domain.Subscription) error {
+	if sub.PaymentGateway == nil || sub.CancelAtPeriodEnd {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if sub.PixRecurrenceID != nil {
+			if s.pix == nil {
+				return fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+			}
+			if err := s.pix.PauseRecurrence(ctx, *sub.PixRecurrenceID); err != nil {
+				return fmt.Errorf("erro ao pausar PIX Automático: %w", err)
+			}
+		}
+	case domain.PaymentGatewayStripe:
+		if s.stripe != nil && sub.StripeSubscriptionID != nil {
+			if err := s.stripe.PauseSubscription(ctx, *sub.StripeSubscriptionID, sub.CurrentPause().ResumeAt); err != nil {
+				return fmt.Errorf("erro ao pausar subscription no Stripe: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// resumeGateway retoma as cobranças da assinatura no gateway a partir de
+// nextDueDate. Com cancelamento agendado elas continuam suspensas.
+func (s *PauseService) resumeGateway(ctx context.Context, sub *domain.Subscription, nextDueDate time.Time) error {
+	if sub.PaymentGateway == nil || sub.CancelAtPeriodEnd {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if sub.PixRecurrenceID != nil {
+			if s.pix == nil {
+				return fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+			}
+			if err := s.pix.ResumeRecurrence(ctx, *sub.PixRecurrenceID, nextDueDate); err != nil {
+				return fmt.Errorf("erro ao retomar PIX Automático: %w", err)
+			}
+		}
+	case domain.PaymentGatewayStripe:
+		if s.stripe != nil && sub.StripeSubscriptionID != nil {
+			if err := s.stripe.ResumeSubscription(ctx, *sub.StripeSubscriptionID); err != nil {
+				return fmt.Errorf("erro ao retomar subscription no Stripe: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newPauseFixture cria a assinatura Starter ativa de 1/jul a 1/ago
+func newPauseFixture(t *testing.T) (*PauseService, *serviceFixture) {
+	t.Helper()
+	f := newServiceFixture(t, "plan-starter", time.Date(2026, 7, 1, 9, 0, 0, 0, domain.BillingLocation))
+	svc := NewPauseService(f.subs, f.pix, nil)
+	svc.SetClock(f.clock)
+	return svc, f
+}
+
+func TestPauseService_ScheduledPauseLifecycle(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newPauseFixture(t)
+	sub, pix, clock, start := f.sub, f.pix, f.clock, f.start
+	clock.Set(start.AddDate(0, 0, 2))
+
+	_, err := svc.Pause(ctx, "academy-1", &PauseRequest{StartAt: start.AddDate(0, 0, 5), ResumeAt: start.AddDate(0, 0, 20), Reason: "reforma"}, "user-1")
+	if err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusActive || len(pix.paused) != 0 {
+		t.Fatal("pausa futura não deveria suspender a recorrência ainda")
+	}
+
+	// O job inicia a pausa na data agendada
+	clock.Set(start.AddDate(0, 0, 5))
+	report, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Started != 1 || sub.Status != domain.SubscriptionStatusPaused || len(pix.paused) != 1 {
+		t.Fatalf("report = %+v, status = %v, paused = %v", report, sub.Status, pix.paused)
+	}
+
+	// ...e retoma automaticamente, estendendo o período pelos 15 dias pausados
+	// e movendo o próximo vencimento da recorrência para o novo fim
+	clock.Set(start.AddDate(0, 0, 20))
+	report, err = svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Resumed != 1 || sub.Status != domain.SubscriptionStatusActive || len(pix.resumed) != 1 {
+		t.Fatalf("report = %+v, status = %v, resumed = %v", report, sub.Status, pix.resumed)
+	}
+	want := time.Date(2026, 8, 16, 9, 0, 0, 0, domain.BillingLocation)
+	if !sub.CurrentPeriodEnd.Equal(want) || !pix.nextDueDate.Equal(want) {
+		t.Errorf("CurrentPeriodEnd = %v, próximo vencimento = %v, want %v", sub.CurrentPeriodEnd, pix.nextDueDate, want)
+	}
+}
+
+func TestPauseService_ResumeEarly(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newPauseFixture(t)
+	sub, pix, start := f.sub, f.pix, f.start
+
+	if _, err := svc.Pause(ctx, "academy-1", &PauseRequest{ResumeAt: start.AddDate(0, 0, 30)}, "user-1"); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusPaused || len(pix.paused) != 1 {
+		t.Fatalf("pausa imediata: status = %v, paused = %v", sub.Status, pix.paused)
+	}
+
+	f.clock.Set(start.AddDate(0, 0, 3))
+	if _, err := svc.Resume(ctx, "academy-1", "user-1"); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if sub.Status != domain.SubscriptionStatusActive || len(pix.resumed) != 1 || sub.Pauses[0].ExtendedDays != 3 {
+		t.Errorf("status = %v, resumed = %v, pause = %+v", sub.Status, pix.resumed, sub.Pauses[0])
+	}
+	if want := time.Date(2026, 8, 4, 9, 0, 0, 0, domain.BillingLocation); !pix.nextDueDate.Equal(want) {
+		t.Errorf("próximo vencimento = %v, want %v", pix.nextDueDate, want)
+	}
+}
+
+func TestPauseService_SavedBeforeGateway(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t, "plan-starter", time.Date(2026, 7, 1, 9, 0, 0, 0, domain.BillingLocation))
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+	svc := NewPauseService(subs, f.pix, nil)
+	svc.SetClock(f.clock)
+	start, pix := f.start, f.pix
+	stored := func() *domain.Subscription {
+		sub, _ := subs.GetByAcademy(ctx, "academy-1")
+		return sub
+	}
+
+	// A Efí recusa a suspensão: a pausa gravada é desfeita
+	pix.err = ports.ErrGatewayRejected
+	req := &PauseRequest{ResumeAt: start.AddDate(0, 0, 30)}
+	if _, err := svc.Pause(ctx, "academy-1", req, "user-1"); !errors.Is(err, ports.ErrGatewayRejected) {
+		t.Fatalf("Pause() error = %v, want ErrGatewayRejected", err)
+	}
+	if sub := stored(); sub.Status != domain.SubscriptionStatusActive || sub.CurrentPause() != nil {
+		t.Fatalf("status = %s, pausa = %+v; want active sem pausa", sub.Status, sub.CurrentPause())
+	}
+
+	// Conflito na gravação: recarrega e pausa
+	pix.err = nil
+	subs.conflicts = 1
+	if _, err := svc.Pause(ctx, "academy-1", req, "user-1"); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if sub := stored(); sub.Status != domain.SubscriptionStatusPaused {
+		t.Fatalf("status = %s, want paused", sub.Status)
+	}
+
+	// A Efí recusa a retomada: a assinatura continua pausada, sem estender o período
+	f.clock.Set(start.AddDate(0, 0, 3))
+	pix.err = ports.ErrGatewayRejected
+	if _, err := svc.Resume(ctx, "academy-1", "user-1"); !errors.Is(err, ports.ErrGatewayRejected) {
+		t.Fatalf("Resume() error = %v, want ErrGatewayRejected", err)
+	}
+	sub := stored()
+	if pause := sub.CurrentPause(); sub.Status != domain.SubscriptionStatusPaused || pause == nil || pause.ResumedAt != nil {
+		t.Fatalf("status = %s, pausa = %+v; want paused com a pausa aberta", sub.Status, pause)
+	}
+	if want := time.Date(2026, 8, 1, 9, 0, 0, 0, domain.BillingLocation); !sub.CurrentPeriodEnd.Equal(want) {
+		t.Errorf("CurrentPeriodEnd = %v, want %v", sub.CurrentPeriodEnd, want)
+	}
+
+	// O job retoma na data planejada quando a Efí volta
+	pix.err = nil
+	f.clock.Set(start.AddDate(0, 0, 30))
+	report, err := svc.Run(ctx)
+	if err != nil || report.Resumed != 1 {
+		t.Fatalf("Run() = %+v, %v, want 1 retomada", report, err)
+	}
+	if sub := stored(); sub.Status != domain.SubscriptionStatusActive {
+		t.Errorf("status = %s, want active", sub.Status)
+	}
+}
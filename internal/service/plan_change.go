@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// ProrationBilling define como a diferença de um upgrade é cobrada
+type ProrationBilling string
+
+const (
+	ProrationBillingNow       ProrationBilling = "now"        // Cobrança PIX imediata
+	ProrationBillingNextCycle ProrationBilling = "next_cycle" // Somada à próxima cobrança do PIX Automático
+)
+
+// prorationChargeExpiry é a validade da cobrança PIX da diferença de um upgrade
+const prorationChargeExpiry = 24 * time.Hour
+
+// PlanChangeRequest é o pedido de troca de plano de uma academia
+type PlanChangeRequest struct {
+	PlanSlug string                 `json:"plan"`
+	Interval domain.BillingInterval `json:"interval,omitempty"` // Vazio = mantém o intervalo atual
+	Billing  ProrationBilling       `json:"billing,omitempty"`  // Vazio = ProrationBillingNow
+}
+
+// PlanChangeResult é o resultado de uma troca de plano confirmada
+type PlanChangeResult struct {
+	Quote        *domain.PlanChangeQuote  `json:"quote"`
+	Subscription *domain.Subscription     `json:"subscription"`
+	Charge       *ports.PixChargeResponse `json:"charge,omitempty"` // Cobrança da diferença (billing "now")
+}
+
+// PlanChangeService calcula e aplica trocas de plano com pró-rata
+type PlanChangeService struct {
+	subscriptions ports.SubscriptionService
+	plans         ports.PlanService
+	payments      ports.PaymentService
+	pix           ports.PixProvider
+	stripe        ports.StripeProvider
+
+	clocked
+	audited
+}
+
+// NewPlanChangeService cria o serviço de troca de plano (stripe pode ser nil)
+func NewPlanChangeService(
+	subscriptions ports.SubscriptionService,
+	plans ports.PlanService,
+	payments ports.PaymentService,
+	pix ports.PixProvider,
+	stripe ports.StripeProvider,
+) *PlanChangeService {
+	return &PlanChangeService{
+		subscriptions: subscriptions,
+		plans:         plans,
+		payments:      payments,
+		pix:           pix,
+		stripe:        stripe,
+	}
+}
+
+// Preview calcula os valores da troca sem alterar nada
+func (s *PlanChangeService) Preview(ctx context.Context, academyID string, req *PlanChangeRequest) (*domain.PlanChangeQuote, error) {
+	_, _, quote, err := s.quote(ctx, academyID, req)
+	return quote, err
+}
+
+// ChangePlan confirma a troca: downgrades são agendados para a próxima
+// renovação e upgrades valem agora. Com a diferença cobrada por PIX imediato,
+// o upgrade fica pendente até o pagamento (HandleDifferencePaid) e é
+// descartado se a cobrança falhar ou vencer. A troca é gravada antes de chegar
+// ao gateway (recarregando a assinatura a cada conflito de versão); se o
+// gateway recusar, a gravação é desfeita. No trial a troca vale na hora, sem
+// cobrança, e o valor de uma recorrência já autorizada passa ao do novo plano.
+func (s *PlanChangeService) ChangePlan(ctx context.Context, academyID string, req *PlanChangeRequest) (*PlanChangeResult, error) {
+	var (
+		sub, previous *domain.Subscription
+		plan          *domain.SubscriptionPlan
+		quote         *domain.PlanChangeQuote
+		before        json.RawMessage
+	)
+	now := s.now()
+	err := retryOnConflict(ctx, "assinatura da academia "+academyID, func() error {
+		var err error
+		sub, plan, quote, err = s.quote(ctx, academyID, req)
+		if err != nil {
+			return err
+		}
+		before = snapshot(sub)
+		loaded := *sub
+		previous = &loaded
+
+		if err := s.prepareGateway(sub, plan, quote, req.Billing); err != nil {
+			return err
+		}
+		switch {
+		case quote.Timing != domain.PlanChangeImmediate:
+			err = sub.SchedulePlanChange(quote, now)
+		case chargesDifference(sub, quote, req.Billing):
+			err = sub.HoldPlanChange(quote, newTxID(), now.Add(prorationChargeExpiry), now)
+		default:
+			err = sub.ApplyPlanChange(quote, now)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PlanChangeResult{Quote: quote, Subscription: sub}
+	if err := s.syncGateway(ctx, sub, plan, quote, req.Billing, result); err != nil {
+		s.revert(ctx, previous, sub)
+		return nil, err
+	}
+	s.audit(ctx, domain.AuditSubscriptionPlanChanged, before, sub, now)
+	return result, nil
+}
+
+// revert desfaz uma troca gravada que o gateway recusou, restaurando o plano
+// de previous. Se outra troca foi gravada nesse meio tempo, ela prevalece.
+func (s *PlanChangeService) revert(ctx context.Context, previous, applied *domain.Subscription) {
+	err := retryOnConflict(ctx, "assinatura "+applied.ID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, applied.ID)
+		if err != nil {
+			return err
+		}
+		if current.PlanID != applied.PlanID || current.BillingInterval != applied.BillingInterval ||
+			!sameScheduledPlanChange(current.ScheduledPlanChange, applied.ScheduledPlanChange) ||
+			!samePendingPlanChange(current.PendingPlanChange, applied.PendingPlanChange) {
+			return nil
+		}
+		current.PlanID = previous.PlanID
+		current.BillingInterval = previous.BillingInterval
+		current.CurrentPeriodStart = previous.CurrentPeriodStart
+		current.CurrentPeriodEnd = previous.CurrentPeriodEnd
+		current.BillingAnchorDay = previous.BillingAnchorDay
+		current.ScheduledPlanChange = previous.ScheduledPlanChange
+		current.PendingPlanChange = previous.PendingPlanChange
+		current.PendingProrationAmount = previous.PendingProrationAmount
+		current.StripePriceID = previous.StripePriceID
+		current.UpdatedAt = s.now()
+		return s.subscriptions.Save(ctx, current)
+	})
+	if err != nil {
+		log.Printf("[PlanChange] Erro ao desfazer troca de plano da assinatura %s: %v", applied.ID, err)
+	}
+}
+
+// sameScheduledPlanChange compara dois agendamentos de troca (nil = nenhum)
+func sameScheduledPlanChange(a, b *domain.ScheduledPlanChange) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.PlanID == b.PlanID && a.Interval == b.Interval && a.EffectiveAt.Equal(b.EffectiveAt)
+}
+
+// samePendingPlanChange compara dois upgrades pendentes (nil = nenhum)
+func samePendingPlanChange(a, b *domain.PendingPlanChange) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.TxID == b.TxID
+}
+
+// chargesDifference indica se o upgrade cobra a diferença por PIX imediato e,
+// portanto, só vale depois do pagamento
+func chargesDifference(sub *domain.Subscription, quote *domain.PlanChangeQuote, billing ProrationBilling) bool {
+	return sub.Status != domain.SubscriptionStatusTrialing && isPixAuto(sub) &&
+		quote.Timing == domain.PlanChangeImmediate && quote.AmountDue > 0 && billing != ProrationBillingNextCycle
+}
+
+// newTxID gera o txid da cobrança da diferença (a Efí aceita 26 a 35
+// caracteres alfanuméricos). Ele é gravado no upgrade pendente antes da
+// cobrança, para que o webhook do pagamento encontre o upgrade.
+func newTxID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// HandleDifferencePaid aplica o upgrade cuja diferença foi paga na cobrança
+// txid e leva o valor do novo plano ao PIX Automático. O valor é atualizado
+// antes de gravar (a chamada é idempotente): se o gateway falhar, o upgrade
+// continua pendente e a reentrega do webhook tenta de novo. Retorna false se a
+// cobrança não é a de um upgrade pendente (ou ele já foi aplicado).
+func (s *PlanChangeService) HandleDifferencePaid(ctx context.Context, subscriptionID, txid string) (bool, error) {
+	var (
+		sub     *domain.Subscription
+		before  json.RawMessage
+		applied bool
+	)
+	err := retryOnConflict(ctx, "assinatura "+subscriptionID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByID(ctx, subscriptionID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		before = snapshot(sub)
+		if applied, err = sub.CompletePendingPlanChange(txid, s.now()); err != nil || !applied {
+			return err
+		}
+
+		if sub.PixRecurrenceID != nil && s.pix != nil {
+			plan, err := s.plans.GetByID(ctx, sub.PlanID)
+			if err != nil {
+				return fmt.Errorf("erro ao buscar plano: %w", err)
+			}
+			gross, discount, err := sub.NextChargeAmount(plan)
+			if err != nil {
+				return err
+			}
+			if err := s.pix.UpdateRecurrenceAmount(ctx, *sub.PixRecurrenceID, int64(gross-discount)); err != nil {
+				return fmt.Errorf("erro ao atualizar valor da recorrência: %w", err)
+			}
+		}
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	if err != nil || !applied {
+		return false, err
+	}
+	s.audit(ctx, domain.AuditSubscriptionPlanChanged, before, sub, s.now())
+	return true, nil
+}
+
+// HandleDifferenceFailed descarta o upgrade cuja cobrança txid falhou ou
+// venceu: a assinatura continua no plano atual. Retorna false se a cobrança
+// não é a de um upgrade pendente.
+func (s *PlanChangeService) HandleDifferenceFailed(ctx context.Context, subscriptionID, txid string) (bool, error) {
+	dropped := false
+	err := retryOnConflict(ctx, "assinatura "+subscriptionID, func() error {
+		sub, err := s.subscriptions.GetByID(ctx, subscriptionID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		if dropped = sub.DropPendingPlanChange(txid, s.now()); !dropped {
+			return nil
+		}
+		if err := s.subscriptions.Save(ctx, sub); err != nil {
+			dropped = false
+			return fmt.Errorf("erro ao salvar assinatura: %w", err)
+		}
+		return nil
+	})
+	return dropped, err
+}
+
+// HandleRenewalPaid fecha o ciclo pago da assinatura (diferença pró-rata e
+// ciclos de cupom) e atualiza o valor do PIX Automático se ele mudou
+func (s *PlanChangeService) HandleRenewalPaid(ctx context.Context, sub *domain.Subscription) error {
+	if !sub.CompleteBillingCycle() {
+		return s.subscriptions.Save(ctx, sub)
+	}
+
+	if sub.PixRecurrenceID != nil {
+		plan, err := s.plans.GetByID(ctx, sub.PlanID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar plano: %w", err)
+		}
+		gross, discount, err := sub.NextChargeAmount(plan)
+		if err != nil {
+			return err
+		}
+		if err := s.pix.UpdateRecurrenceAmount(ctx, *sub.PixRecurrenceID, int64(gross-discount)); err != nil {
+			return fmt.Errorf("erro ao atualizar valor da recorrência: %w", err)
+		}
+	}
+	return s.subscriptions.Save(ctx, sub)
+}
+
+// quote carrega assinatura e planos e calcula a troca
+func (s *PlanChangeService) quote(ctx context.Context, academyID string, req *PlanChangeRequest) (*domain.Subscription, *domain.SubscriptionPlan, *domain.PlanChangeQuote, error) {
+	if req.PlanSlug == "" {
+		return nil, nil, nil, fmt.Errorf("%w: plano é obrigatório", domain.ErrValidation)
+	}
+	switch req.Billing {
+	case "", ProrationBillingNow, ProrationBillingNextCycle:
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: billing inválido: %q", domain.ErrValidation, req.Billing)
+	}
+
+	sub, err := s.subscriptions.GetByAcademy(ctx, academyID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+	current, err := s.plans.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao buscar plano atual: %w", err)
+	}
+	target, err := s.plans.GetBySlug(ctx, req.PlanSlug)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao buscar plano %s: %w", req.PlanSlug, err)
+	}
+
+	if sub.AwaitingPlanChange(s.now()) {
+		return nil, nil, nil, fmt.Errorf("%w: upgrade aguardando pagamento da diferença", domain.ErrPlanChangeNotAllowed)
+	}
+
+	// O PIX Automático não permite alterar a periodicidade de uma recorrência autorizada
+	if isPixAuto(sub) && req.Interval != "" && req.Interval != sub.BillingInterval {
+		return nil, nil, nil, fmt.Errorf("%w: troca de periodicidade no PIX Automático exige nova autorização", domain.ErrPlanChangeNotAllowed)
+	}
+
+	quote, err := domain.QuotePlanChange(sub, current, target, req.Interval, s.now())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sub, target, quote, nil
+}
+
+// prepareGateway valida a troca no gateway da assinatura e grava nela o que a
+// chamada vai produzir (price do Stripe, diferença somada ao próximo ciclo),
+// para que a assinatura seja salva antes de o gateway ser alterado
+func (s *PlanChangeService) prepareGateway(sub *domain.Subscription, plan *domain.SubscriptionPlan, quote *domain.PlanChangeQuote, billing ProrationBilling) error {
+	if sub.PaymentGateway == nil {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayStripe:
+		if s.stripe == nil || sub.StripeSubscriptionID == nil {
+			return fmt.Errorf("assinatura Stripe sem subscription configurada")
+		}
+		priceID := plan.StripePriceIDMonthly
+		if quote.ToInterval == domain.BillingIntervalYearly {
+			priceID = plan.StripePriceIDYearly
+		}
+		if priceID == nil {
+			return fmt.Errorf("%w: plano %s sem price no Stripe", domain.ErrIntervalUnavailable, plan.Slug)
+		}
+		sub.StripePriceID = priceID
+	case domain.PaymentGatewayPixAuto:
+		if s.pix == nil {
+			return fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+		}
+		if sub.PixRecurrenceID == nil {
+			return fmt.Errorf("assinatura PIX Automático sem recorrência configurada")
+		}
+		if quote.AmountDue > 0 && billing == ProrationBillingNextCycle {
+			sub.PendingProrationAmount = quote.AmountDue
+		}
+	}
+	return nil
+}
+
+// syncGateway replica no gateway a troca já gravada em sub
+func (s *PlanChangeService) syncGateway(ctx context.Context, sub *domain.Subscription, plan *domain.SubscriptionPlan, quote *domain.PlanChangeQuote, billing ProrationBilling, result *PlanChangeResult) error {
+	if sub.PaymentGateway == nil {
+		return nil
+	}
+
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayStripe:
+		prorate := quote.Timing == domain.PlanChangeImmediate
+		if err := s.stripe.UpdateSubscriptionPrice(ctx, *sub.StripeSubscriptionID, *sub.StripePriceID, prorate); err != nil {
+			return fmt.Errorf("erro ao atualizar subscription no Stripe: %w", err)
+		}
+	case domain.PaymentGatewayPixAuto:
+		return s.syncPix(ctx, sub, plan, quote, billing, result)
+	}
+	return nil
+}
+
+// syncPix cobra a diferença de um upgrade pendente ou, quando a troca já vale
+// (downgrade, diferença somada ao próximo ciclo), ajusta o valor do PIX Automático
+func (s *PlanChangeService) syncPix(ctx context.Context, sub *domain.Subscription, plan *domain.SubscriptionPlan, quote *domain.PlanChangeQuote, billing ProrationBilling, result *PlanChangeResult) error {
+	if chargesDifference(sub, quote, billing) {
+		charge, err := s.chargeDifference(ctx, sub, plan, quote)
+		if err != nil {
+			return err
+		}
+		result.Charge = charge
+		return nil
+	}
+
+	nextAmount := quote.NextAmount - sub.Discount.Amount(quote.NextAmount) + sub.PendingProrationAmount
+	if err := s.pix.UpdateRecurrenceAmount(ctx, *sub.PixRecurrenceID, int64(nextAmount)); err != nil {
+		return fmt.Errorf("erro ao atualizar valor da recorrência: %w", err)
+	}
+	return nil
+}
+
+// chargeDifference cria a cobrança PIX imediata da diferença com o txid do
+// upgrade pendente e registra no histórico
+func (s *PlanChangeService) chargeDifference(ctx context.Context, sub *domain.Subscription, plan *domain.SubscriptionPlan, quote *domain.PlanChangeQuote) (*ports.PixChargeResponse, error) {
+	req := &ports.PixChargeRequest{
+		TxID:        sub.PendingPlanChange.TxID,
+		Amount:      int64(quote.AmountDue),
+		Description: fmt.Sprintf("BlackBelt %s - diferença de upgrade", plan.Name),
+		ExpiresIn:   int(prorationChargeExpiry.Seconds()),
+	}
+	if sub.PixCustomerCPF != nil {
+		req.PayerDocument = *sub.PixCustomerCPF
+	}
+	if sub.PixCustomerName != nil {
+		req.PayerName = *sub.PixCustomerName
+	}
+
+	charge, err := s.pix.CreatePixCharge(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao cobrar diferença do upgrade: %w", err)
+	}
+
+	payment := domain.NewPaymentHistory(sub.ID, sub.AcademyID, quote.AmountDue, domain.PaymentGatewayPixAuto, s.now())
+	method := "pix"
+	payment.GatewayPaymentID = &charge.TxID
+	payment.PaymentMethod = &method
+	periodStart, periodEnd := quote.PeriodStart, quote.PeriodEnd
+	payment.PeriodStart = &periodStart
+	payment.PeriodEnd = &periodEnd
+	if err := s.payments.RecordPayment(ctx, payment); err != nil {
+		return nil, fmt.Errorf("erro ao registrar cobrança do upgrade: %w", err)
+	}
+	return charge, nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newPlanChangeFixture cria a assinatura Starter ativa de 1/abr a 1/mai, com o
+// relógio no meio do período
+func newPlanChangeFixture(t *testing.T) (*PlanChangeService, *serviceFixture) {
+	t.Helper()
+	f := newServiceFixture(t, "plan-starter", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.clock.Set(f.start.AddDate(0, 0, 15))
+	return f.planChanges(), f
+}
+
+func TestPlanChangeService_UpgradeChargesNow(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newPlanChangeFixture(t)
+	sub, payments, pix := f.sub, f.payments, f.pix
+
+	preview, err := svc.Preview(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"})
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	if sub.PlanID != "plan-starter" || pix.calls != 0 {
+		t.Fatal("Preview() não deveria alterar a assinatura nem chamar o gateway")
+	}
+
+	result, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"})
+	if err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+	if result.Quote.AmountDue != preview.AmountDue || result.Quote.AmountDue != 5000 {
+		t.Errorf("AmountDue = %d, want 5000 (igual ao preview %d)", result.Quote.AmountDue, preview.AmountDue)
+	}
+	if result.Charge == nil || len(payments.payments) != 1 || payments.payments[0].Amount != 5000 {
+		t.Fatalf("cobrança da diferença não registrada: charge = %+v, payments = %d", result.Charge, len(payments.payments))
+	}
+
+	// O upgrade só vale depois do pagamento da diferença
+	if sub.PlanID != "plan-starter" || !sub.IsPendingPlanChangeCharge(result.Charge.TxID) || pix.recurrenceAmount != 0 {
+		t.Fatalf("PlanID = %s, pendente = %+v, recorrência = %d; want starter com upgrade pendente",
+			sub.PlanID, sub.PendingPlanChange, pix.recurrenceAmount)
+	}
+	if _, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); !errors.Is(err, domain.ErrPlanChangeNotAllowed) {
+		t.Errorf("segunda troca com upgrade pendente: error = %v, want ErrPlanChangeNotAllowed", err)
+	}
+
+	paid := &ports.ChargeNotification{
+		Gateway:          domain.PaymentGatewayPixAuto,
+		GatewayPaymentID: result.Charge.TxID,
+		EndToEndID:       "E1",
+		Amount:           5000,
+		Paid:             true,
+	}
+	if err := f.billing().HandleCharge(ctx, paid); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	if sub.PlanID != "plan-pro" || sub.PendingPlanChange != nil || pix.recurrenceAmount != 19900 {
+		t.Errorf("PlanID = %s, pendente = %+v, recorrência = %d; want pro com recorrência 19900",
+			sub.PlanID, sub.PendingPlanChange, pix.recurrenceAmount)
+	}
+}
+
+func TestPlanChangeService_UnpaidDifferenceDropsUpgrade(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newPlanChangeFixture(t)
+	sub := f.sub
+
+	result, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"})
+	if err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+
+	// A cobrança da diferença vence sem pagamento: o upgrade é descartado e a
+	// assinatura continua em dia no plano atual
+	expired := &ports.ChargeNotification{
+		Gateway:          domain.PaymentGatewayPixAuto,
+		GatewayPaymentID: result.Charge.TxID,
+		Amount:           5000,
+		FailureReason:    "cobrança expirada",
+	}
+	if err := f.billing().HandleCharge(ctx, expired); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	if sub.PlanID != "plan-starter" || sub.PendingPlanChange != nil || sub.Status != domain.SubscriptionStatusActive {
+		t.Errorf("PlanID = %s, pendente = %+v, Status = %v; want starter ativa sem upgrade", sub.PlanID, sub.PendingPlanChange, sub.Status)
+	}
+	if p := f.payments.payments[0]; p.Status != domain.PaymentStatusFailed {
+		t.Errorf("pagamento da diferença = %v, want failed", p.Status)
+	}
+	if f.pix.recurrenceAmount != 0 {
+		t.Errorf("valor da recorrência = %d, want inalterado", f.pix.recurrenceAmount)
+	}
+
+	// Sem upgrade pendente, uma nova troca é aceita
+	if _, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); err != nil {
+		t.Errorf("ChangePlan() depois do upgrade descartado error = %v", err)
+	}
+}
+
+func TestPlanChangeService_UpgradeNextCycle(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newPlanChangeFixture(t)
+	sub, payments, pix := f.sub, f.payments, f.pix
+
+	if _, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro", Billing: ProrationBillingNextCycle}); err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+	if len(payments.payments) != 0 {
+		t.Errorf("payments = %d, want 0 (diferença vai na próxima cobrança)", len(payments.payments))
+	}
+	if pix.recurrenceAmount != 19900+5000 || sub.PendingProrationAmount != 5000 {
+		t.Errorf("recorrência = %d, pendente = %d, want 24900 e 5000", pix.recurrenceAmount, sub.PendingProrationAmount)
+	}
+
+	// Depois da renovação paga o valor volta ao preço do plano
+	if err := svc.HandleRenewalPaid(ctx, sub); err != nil {
+		t.Fatalf("HandleRenewalPaid() error = %v", err)
+	}
+	if pix.recurrenceAmount != 19900 || sub.PendingProrationAmount != 0 {
+		t.Errorf("recorrência = %d, pendente = %d, want 19900 e 0", pix.recurrenceAmount, sub.PendingProrationAmount)
+	}
+}
+
+func TestPlanChangeService_DowngradeScheduled(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newPlanChangeFixture(t)
+	sub, pix := f.sub, f.pix
+	sub.PlanID = "plan-pro"
+
+	result, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "starter"})
+	if err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+	if result.Quote.Timing != domain.PlanChangeAtPeriodEnd || result.Charge != nil {
+		t.Errorf("quote = %+v, want agendado sem cobrança", result.Quote)
+	}
+	if sub.PlanID != "plan-pro" || sub.ScheduledPlanChange == nil || sub.ScheduledPlanChange.PlanID != "plan-starter" {
+		t.Errorf("PlanID = %s, Scheduled = %+v, want pro com starter agendado", sub.PlanID, sub.ScheduledPlanChange)
+	}
+	if pix.recurrenceAmount != 9900 {
+		t.Errorf("valor da recorrência = %d, want 9900 a partir da renovação", pix.recurrenceAmount)
+	}
+}
+
+func TestPlanChangeService_PixIntervalChangeRejected(t *testing.T) {
+	svc, _ := newPlanChangeFixture(t)
+
+	_, err := svc.Preview(context.Background(), "academy-1", &PlanChangeRequest{PlanSlug: "pro", Interval: domain.BillingIntervalYearly})
+	if !errors.Is(err, domain.ErrPlanChangeNotAllowed) {
+		t.Errorf("Preview() error = %v, want ErrPlanChangeNotAllowed", err)
+	}
+}
+
+func TestPlanChangeService_SavedBeforeGateway(t *testing.T) {
+	ctx := context.Background()
+
+	// Conflito de versão: a assinatura é recarregada e a troca reaplicada
+	svc, f := newPlanChangeFixture(t)
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+	svc.subscriptions = subs
+	if _, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+	if stored, _ := subs.GetByID(ctx, "sub-1"); stored.PendingPlanChange == nil || len(f.payments.payments) != 1 {
+		t.Errorf("pendente = %+v, pagamentos = %d, want upgrade pendente e 1 cobrança", stored.PendingPlanChange, len(f.payments.payments))
+	}
+
+	// Gravação que nunca passa não chega ao gateway
+	svc, f = newPlanChangeFixture(t)
+	svc.subscriptions = &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: maxConflictRetries + 1}
+	if _, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); !errors.Is(err, domain.ErrConcurrentModification) {
+		t.Fatalf("ChangePlan() error = %v, want ErrConcurrentModification", err)
+	}
+	if f.pix.calls != 0 || f.pix.recurrenceAmount != 0 {
+		t.Errorf("gateway chamado sem a troca gravada: cobranças = %d, recorrência = %d", f.pix.calls, f.pix.recurrenceAmount)
+	}
+
+	// Gateway recusa: a troca gravada é desfeita
+	svc, f = newPlanChangeFixture(t)
+	f.pix.err = ports.ErrGatewayRejected
+	if _, err := svc.ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); !errors.Is(err, ports.ErrGatewayRejected) {
+		t.Fatalf("ChangePlan() error = %v, want ErrGatewayRejected", err)
+	}
+	if f.sub.PlanID != "plan-starter" || f.sub.PendingPlanChange != nil || len(f.payments.payments) != 0 {
+		t.Errorf("PlanID = %s, pendente = %+v, pagamentos = %d, want troca desfeita", f.sub.PlanID, f.sub.PendingPlanChange, len(f.payments.payments))
+	}
+}
+
+func TestPlanChangeService_TrialChange(t *testing.T) {
+	ctx := context.Background()
+
+	// Recorrência autorizada no trial: a troca vale agora, sem cobrança, e o
+	// PIX Automático passa a cobrar o novo plano no fim do trial
+	f := newTrialFixture(t, "plan-starter", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	gateway := domain.PaymentGatewayPixAuto
+	f.sub.PaymentGateway = &gateway
+	f.clock.Set(f.start.AddDate(0, 0, 5))
+	result, err := f.planChanges().ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"})
+	if err != nil {
+		t.Fatalf("ChangePlan() error = %v", err)
+	}
+	if f.sub.PlanID != "plan-pro" || result.Charge != nil || len(f.payments.payments) != 0 {
+		t.Errorf("PlanID = %s, charge = %+v, want pro sem cobrança", f.sub.PlanID, result.Charge)
+	}
+	if f.pix.recurrenceAmount != 19900 {
+		t.Errorf("valor da recorrência = %d, want 19900", f.pix.recurrenceAmount)
+	}
+
+	// Gateway recusa o novo valor: a troca é desfeita
+	f = newTrialFixture(t, "plan-starter", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.sub.PaymentGateway = &gateway
+	f.pix.err = ports.ErrGatewayRejected
+	if _, err := f.planChanges().ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); !errors.Is(err, ports.ErrGatewayRejected) {
+		t.Fatalf("ChangePlan() error = %v, want ErrGatewayRejected", err)
+	}
+	if f.sub.PlanID != "plan-starter" {
+		t.Errorf("PlanID = %s, want troca desfeita", f.sub.PlanID)
+	}
+
+	// Trial sem gateway escolhido: nada a atualizar
+	f = newTrialFixture(t, "plan-starter", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.sub.PixAuthorizationID, f.sub.PixRecurrenceID = nil, nil
+	if _, err := f.planChanges().ChangePlan(ctx, "academy-1", &PlanChangeRequest{PlanSlug: "pro"}); err != nil {
+		t.Fatalf("ChangePlan() sem gateway error = %v", err)
+	}
+	if f.sub.PlanID != "plan-pro" || f.pix.recurrenceAmount != 0 {
+		t.Errorf("PlanID = %s, recorrência = %d, want pro sem chamar o gateway", f.sub.PlanID, f.pix.recurrenceAmount)
+	}
+}
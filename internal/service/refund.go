@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// refundReconcileAge é a idade mínima de uma devolução pendente para ser reenviada
+// na conciliação: antes disso o webhook do gateway normalmente já chegou
+const refundReconcileAge = 15 * time.Minute
+
+// PaymentRefundedHandler reage ao estorno total de um pagamento (anula fatura, cancela nota fiscal).
+// Devoluções parciais não disparam os handlers: a fatura e a nota continuam válidas.
+type PaymentRefundedHandler interface {
+	HandlePaymentRefunded(ctx context.Context, payment *domain.PaymentHistory) error
+}
+
+// RefundService solicita devoluções (totais ou parciais, várias por pagamento)
+// e concilia o pagamento com o que o gateway informa por webhook.
+type RefundService struct {
+	payments   ports.PaymentService
+	pix        ports.PixProvider
+	stripe     ports.StripeProvider
+	onRefunded []PaymentRefundedHandler
+
+	clocked
+	audited
+}
+
+// NewRefundService cria o serviço de devoluções (stripe pode ser nil)
+func NewRefundService(payments ports.PaymentService, pix ports.PixProvider, stripe ports.StripeProvider) *RefundService {
+	return &RefundService{
+		payments: payments,
+		pix:      pix,
+		stripe:   stripe,
+	}
+}
+
+// OnPaymentRefunded registra handlers chamados quando um pagamento fica totalmente estornado.
+// Falhas deles são logadas e não desfazem a devolução.
+func (s *RefundService) OnPaymentRefunded(handlers ...PaymentRefundedHandler) {
+	s.onRefunded = append(s.onRefunded, handlers...)
+}
+
+// Request devolve amount centavos de um pagamento confirmado. A devolução é
+// gravada como pendente antes de chamar o gateway, para que o webhook sempre a
+// encontre. Só uma recusa definitiva (4xx) a marca como falha e libera o valor;
+// sem resposta definitiva (timeout, 5xx, circuito aberto) ela continua pendente
+// e é conciliada pelo webhook ou por ReconcilePending.
+// Se outra escrita mudou o pagamento, ele é recarregado e o valor devolvível
+// conferido de novo.
+func (s *RefundService) Request(ctx context.Context, payment *domain.PaymentHistory, amount int, reason, requestedBy string) (*domain.PaymentRefund, error) {
+	var (
+		before json.RawMessage
+		refund *domain.PaymentRefund
+	)
+	err := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+		before = snapshot(p)
+		var err error
+		refund, err = p.RequestRefund(amount, reason, requestedBy, s.now())
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrRefundNotAllowed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao registrar devolução: %w", err)
+	}
+	s.audit(ctx, domain.AuditRefundRequested, before, payment, s.now())
+
+	result, err := s.sendToGateway(ctx, payment, refund)
+	if err != nil {
+		if !isRefundRejected(err) {
+			log.Printf("[Refund] Devolução %s sem resposta definitiva do gateway, fica pendente: %v", refund.ID, err)
+			return refund, nil
+		}
+		if saveErr := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+			p.FailRefund(refund.ID, err.Error(), s.now())
+			return nil
+		}); saveErr != nil {
+			log.Printf("[Refund] Erro ao registrar falha da devolução %s: %v", refund.ID, saveErr)
+		}
+		return nil, err
+	}
+
+	if err := s.applyResult(ctx, payment, refund.ID, result); err != nil {
+		return nil, err
+	}
+	if current := findRefund(payment, refund.ID); current != nil {
+		refund = current
+	}
+	return refund, nil
+}
+
+// RequestByID devolve amount centavos do pagamento paymentID. Com academyID,
+// só pagamentos da academia são encontrados (o dono não estorna pagamentos de
+// outra academia); vazio é a equipe, pelo token administrativo.
+func (s *RefundService) RequestByID(ctx context.Context, academyID, paymentID string, amount int, reason, requestedBy string) (*domain.PaymentRefund, error) {
+	payment, err := s.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar pagamento: %w", err)
+	}
+	if academyID != "" && payment.AcademyID != academyID {
+		return nil, fmt.Errorf("pagamento %s: %w", paymentID, domain.ErrNotFound)
+	}
+	return s.Request(ctx, payment, amount, reason, requestedBy)
+}
+
+// ReconcilePending reenvia ao gateway as devoluções pendentes há mais de
+// refundReconcileAge. O reenvio usa o mesmo ID, então não gera uma segunda
+// devolução: o gateway devolve a situação da que já existe ou cria a que
+// nunca chegou. Devolve quantas devoluções foram resolvidas.
+func (s *RefundService) ReconcilePending(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-refundReconcileAge)
+	payments, err := s.payments.ListWithPendingRefunds(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar devoluções pendentes: %w", err)
+	}
+
+	resolved := 0
+	for _, payment := range payments {
+		for _, refund := range payment.Refunds {
+			if refund.Status != domain.RefundStatusPending || refund.RequestedAt.After(cutoff) {
+				continue
+			}
+			if err := s.reconcile(ctx, payment, refund); err != nil {
+				log.Printf("[Refund] Erro ao conciliar devolução %s: %v", refund.ID, err)
+				continue
+			}
+			if current := findRefund(payment, refund.ID); current != nil && current.Status != domain.RefundStatusPending {
+				resolved++
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// reconcile reenvia uma devolução pendente e aplica a resposta do gateway
+func (s *RefundService) reconcile(ctx context.Context, payment *domain.PaymentHistory, refund *domain.PaymentRefund) error {
+	result, err := s.sendToGateway(ctx, payment, refund)
+	if err == nil {
+		return s.applyResult(ctx, payment, refund.ID, result)
+	}
+	if !isRefundRejected(err) {
+		return err
+	}
+
+	before := snapshot(payment)
+	if err := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+		p.FailRefund(refund.ID, err.Error(), s.now())
+		return nil
+	}); err != nil {
+		return fmt.Errorf("erro ao atualizar pagamento: %w", err)
+	}
+	s.audit(ctx, domain.AuditRefundSettled, before, payment, s.now())
+	return nil
+}
+
+// applyResult grava no pagamento a resposta do gateway a uma solicitação de devolução
+func (s *RefundService) applyResult(ctx context.Context, payment *domain.PaymentHistory, refundID string, result *ports.RefundResult) error {
+	wasRefunded := payment.Status == domain.PaymentStatusRefunded
+	before := snapshot(payment)
+	if err := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+		if refund := findRefund(p, refundID); refund != nil && result.GatewayRefundID != "" {
+			refund.GatewayRefundID = &result.GatewayRefundID
+		}
+		if result.Status == domain.RefundStatusSucceeded {
+			return p.ConfirmRefund(refundID, result.GatewayRefundID, 0, s.now())
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("erro ao atualizar pagamento: %w", err)
+	}
+	if result.Status == domain.RefundStatusSucceeded {
+		s.audit(ctx, domain.AuditRefundSettled, before, payment, s.now())
+	}
+	s.afterRefund(ctx, payment, wasRefunded)
+	return nil
+}
+
+// HandleRefundNotification concilia a devolução informada pelo webhook do gateway.
+// É idempotente: notificações repetidas não mudam o pagamento. Se o pagamento
+// mudar entre a leitura e a gravação, recarrega e concilia de novo.
+func (s *RefundService) HandleRefundNotification(ctx context.Context, n *ports.RefundNotification) error {
+	if n.Status != domain.RefundStatusSucceeded && n.Status != domain.RefundStatusFailed {
+		return nil // Em processamento: aguarda a próxima notificação
+	}
+
+	var (
+		payment     *domain.PaymentHistory
+		before      json.RawMessage
+		wasRefunded bool
+	)
+	err := retryOnConflict(ctx, "pagamento "+n.GatewayPaymentID, func() error {
+		var err error
+		payment, err = s.payments.GetByGatewayPaymentID(ctx, n.GatewayPaymentID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar pagamento %s: %w", n.GatewayPaymentID, err)
+		}
+
+		before = snapshot(payment)
+		wasRefunded = payment.Status == domain.PaymentStatusRefunded
+		if n.Status == domain.RefundStatusSucceeded {
+			if err := payment.ConfirmRefund(n.RefundID, n.GatewayRefundID, n.Amount, s.now()); err != nil {
+				return err
+			}
+		} else {
+			reason := n.FailureReason
+			if reason == "" {
+				reason = "devolução não realizada pelo gateway"
+			}
+			payment.FailRefund(n.RefundID, reason, s.now())
+		}
+
+		if err := s.payments.UpdatePayment(ctx, payment); err != nil {
+			return fmt.Errorf("erro ao atualizar pagamento: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.audit(ctx, domain.AuditRefundSettled, before, payment, s.now())
+	s.afterRefund(ctx, payment, wasRefunded)
+	return nil
+}
+
+// sendToGateway solicita a devolução no gateway do pagamento
+func (s *RefundService) sendToGateway(ctx context.Context, payment *domain.PaymentHistory, refund *domain.PaymentRefund) (*ports.RefundResult, error) {
+	switch payment.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if payment.EndToEndID == nil {
+			return nil, fmt.Errorf("%w: pagamento %s sem e2eId: devolução PIX indisponível", domain.ErrRefundNotAllowed, payment.ID)
+		}
+		return s.pix.RefundPix(ctx, &ports.PixRefundRequest{
+			EndToEndID: *payment.EndToEndID,
+			RefundID:   refund.ID,
+			Amount:     int64(refund.Amount),
+			Reason:     refund.Reason,
+		})
+	case domain.PaymentGatewayStripe:
+		if s.stripe == nil || payment.GatewayPaymentID == nil {
+			return nil, fmt.Errorf("%w: pagamento %s: devolução Stripe indisponível", domain.ErrRefundNotAllowed, payment.ID)
+		}
+		return s.stripe.RefundPayment(ctx, *payment.GatewayPaymentID, refund.ID, int64(refund.Amount))
+	default:
+		return nil, fmt.Errorf("%w: gateway %q não suporta devolução", domain.ErrRefundNotAllowed, payment.PaymentGateway)
+	}
+}
+
+// isRefundRejected indica uma recusa definitiva: o gateway respondeu 4xx ou a
+// devolução nem pôde ser enviada. Só nesses casos a devolução é marcada como falha.
+func isRefundRejected(err error) bool {
+	return errors.Is(err, ports.ErrGatewayRejected) || errors.Is(err, domain.ErrRefundNotAllowed)
+}
+
+// findRefund busca uma devolução do pagamento pelo ID
+func findRefund(payment *domain.PaymentHistory, refundID string) *domain.PaymentRefund {
+	for _, refund := range payment.Refunds {
+		if refund.ID == refundID {
+			return refund
+		}
+	}
+	return nil
+}
+
+// afterRefund chama os handlers quando o pagamento acabou de ficar totalmente estornado
+func (s *RefundService) afterRefund(ctx context.Context, payment *domain.PaymentHistory, wasRefunded bool) {
+	if wasRefunded || payment.Status != domain.PaymentStatusRefunded {
+		return
+	}
+	for _, h := range s.onRefunded {
+		if err := h.HandlePaymentRefunded(ctx, payment); err != nil {
+			log.Printf("[Refund] Erro pós-estorno %s: %v", payment.ID, err)
+		}
+	}
+}
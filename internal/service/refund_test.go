@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// fakeRefundPix registra as devoluções pedidas à Efí
+type fakeRefundPix struct {
+	ports.PixProvider
+	requests []*ports.PixRefundRequest
+	status   domain.RefundStatus
+	err      error
+}
+
+func (f *fakeRefundPix) RefundPix(ctx context.Context, req *ports.PixRefundRequest) (*ports.RefundResult, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ports.RefundResult{GatewayRefundID: "D" + req.RefundID, Status: f.status}, nil
+}
+
+// refundedRecorder registra os pagamentos entregues aos handlers de estorno
+type refundedRecorder struct {
+	payments []string
+}
+
+func (r *refundedRecorder) HandlePaymentRefunded(ctx context.Context, payment *domain.PaymentHistory) error {
+	r.payments = append(r.payments, payment.ID)
+	return nil
+}
+
+func refundFixture(t *testing.T) (*RefundService, *fakeRefundPix, *refundedRecorder, *domain.PaymentHistory) {
+	t.Helper()
+	clock := domain.NewFakeClock(time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC))
+	payment := domain.NewPaymentHistory("sub-1", "academy-1", 9900, domain.PaymentGatewayPixAuto, clock.Now())
+	txid, e2e := "txid-1", "E123"
+	payment.GatewayPaymentID, payment.EndToEndID = &txid, &e2e
+	payment.Succeed(clock.Now())
+
+	payments := &fakePayments{}
+	payments.RecordPayment(context.Background(), payment)
+
+	pix := &fakeRefundPix{status: domain.RefundStatusPending}
+	recorder := &refundedRecorder{}
+	svc := NewRefundService(payments, pix, nil)
+	svc.SetClock(clock)
+	svc.OnPaymentRefunded(recorder)
+	return svc, pix, recorder, payment
+}
+
+func TestRefundService_PartialThenWebhook(t *testing.T) {
+	ctx := context.Background()
+	svc, pix, recorder, payment := refundFixture(t)
+
+	refund, err := svc.Request(ctx, payment, 4000, "Desconto retroativo", "user-1")
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if len(pix.requests) != 1 || pix.requests[0].RefundID != refund.ID || pix.requests[0].EndToEndID != "E123" || pix.requests[0].Amount != 4000 {
+		t.Fatalf("requests = %+v", pix.requests)
+	}
+	if refund.Status != domain.RefundStatusPending || payment.Status != domain.PaymentStatusSucceeded {
+		t.Errorf("refund = %s, payment = %s, want pending e succeeded", refund.Status, payment.Status)
+	}
+
+	// A Efí liquida a devolução e notifica duas vezes
+	notification := &ports.RefundNotification{
+		GatewayPaymentID: "txid-1", RefundID: refund.ID, GatewayRefundID: "D1", Amount: 4000, Status: domain.RefundStatusSucceeded,
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.HandleRefundNotification(ctx, notification); err != nil {
+			t.Fatalf("HandleRefundNotification() error = %v", err)
+		}
+	}
+	if payment.Status != domain.PaymentStatusPartiallyRefunded || payment.RefundedAmount != 4000 {
+		t.Errorf("payment = %s %d, want partially_refunded 4000", payment.Status, payment.RefundedAmount)
+	}
+	if len(recorder.payments) != 0 {
+		t.Errorf("handlers chamados em devolução parcial: %v", recorder.payments)
+	}
+
+	// Devolução do restante, liquidada na hora
+	pix.status = domain.RefundStatusSucceeded
+	if _, err := svc.Request(ctx, payment, 5900, "Cancelamento", "user-1"); err != nil {
+		t.Fatalf("Request(restante) error = %v", err)
+	}
+	if payment.Status != domain.PaymentStatusRefunded || len(recorder.payments) != 1 {
+		t.Errorf("payment = %s, handlers = %v, want refunded e 1 chamada", payment.Status, recorder.payments)
+	}
+	if _, err := svc.Request(ctx, payment, 1, "", "user-1"); !errors.Is(err, domain.ErrRefundNotAllowed) {
+		t.Errorf("Request(estornado) error = %v, want ErrRefundNotAllowed", err)
+	}
+}
+
+func TestRefundService_GatewayFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, pix, _, payment := refundFixture(t)
+
+	// Recusa definitiva (4xx): a devolução falha e o valor volta a ser reembolsável
+	pix.err = fmt.Errorf("erro ao solicitar devolução: %w", ports.ErrGatewayRejected)
+	if _, err := svc.Request(ctx, payment, 9900, "", "user-1"); !errors.Is(err, ports.ErrGatewayRejected) {
+		t.Fatalf("Request() error = %v, want ErrGatewayRejected", err)
+	}
+	if len(payment.Refunds) != 1 || payment.Refunds[0].Status != domain.RefundStatusFailed || payment.RefundableAmount() != 9900 {
+		t.Fatalf("refunds = %+v, refundable = %d", payment.Refunds, payment.RefundableAmount())
+	}
+
+	// Recusa notificada pela Efí depois de uma devolução pendente
+	pix.err = nil
+	refund, err := svc.Request(ctx, payment, 9900, "", "user-1")
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if err := svc.HandleRefundNotification(ctx, &ports.RefundNotification{
+		GatewayPaymentID: "txid-1", RefundID: refund.ID, Status: domain.RefundStatusFailed, FailureReason: "conta encerrada",
+	}); err != nil {
+		t.Fatalf("HandleRefundNotification() error = %v", err)
+	}
+	if refund.Status != domain.RefundStatusFailed || *refund.FailureReason != "conta encerrada" || payment.Status != domain.PaymentStatusSucceeded {
+		t.Errorf("refund = %+v, payment = %s", refund, payment.Status)
+	}
+}
+
+func TestRefundService_RequestByIDScopedToAcademy(t *testing.T) {
+	ctx := context.Background()
+	svc, pix, _, payment := refundFixture(t)
+
+	if _, err := svc.RequestByID(ctx, "academy-2", payment.ID, 9900, "", "user-2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("RequestByID() de outra academia error = %v, want ErrNotFound", err)
+	}
+	if len(pix.requests) != 0 {
+		t.Fatalf("devoluções pedidas = %d, want 0", len(pix.requests))
+	}
+
+	// Academia do pagamento (dono) e equipe (sem academia)
+	if _, err := svc.RequestByID(ctx, "academy-1", payment.ID, 4000, "", "user-1"); err != nil {
+		t.Fatalf("RequestByID() error = %v", err)
+	}
+	if _, err := svc.RequestByID(ctx, "", payment.ID, 5900, "", "admin"); err != nil {
+		t.Fatalf("RequestByID() admin error = %v", err)
+	}
+	if len(pix.requests) != 2 {
+		t.Errorf("devoluções pedidas = %d, want 2", len(pix.requests))
+	}
+}
+
+func TestRefundService_AmbiguousFailureStaysPending(t *testing.T) {
+	ctx := context.Background()
+	svc, pix, recorder, payment := refundFixture(t)
+
+	// Sem resposta definitiva: a Efí pode ter aceitado, então nada é liberado
+	pix.err = ports.ErrGatewayUnavailable
+	refund, err := svc.Request(ctx, payment, 9900, "", "user-1")
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if refund.Status != domain.RefundStatusPending || payment.RefundableAmount() != 0 {
+		t.Fatalf("refund = %s, refundable = %d, want pending e 0", refund.Status, payment.RefundableAmount())
+	}
+	if _, err := svc.Request(ctx, payment, 1, "", "user-1"); !errors.Is(err, domain.ErrRefundNotAllowed) {
+		t.Errorf("Request() com devolução pendente error = %v, want ErrRefundNotAllowed", err)
+	}
+
+	// Antes da idade mínima a conciliação não reenvia
+	pix.err = nil
+	pix.status = domain.RefundStatusSucceeded
+	if n, err := svc.ReconcilePending(ctx); err != nil || n != 0 || len(pix.requests) != 1 {
+		t.Fatalf("ReconcilePending() = %d, %v, requests = %d, want nada reenviado", n, err, len(pix.requests))
+	}
+
+	// Depois dela, reenvia com o mesmo ID e aplica a resposta
+	svc.SetClock(domain.NewFakeClock(refund.RequestedAt.Add(refundReconcileAge)))
+	n, err := svc.ReconcilePending(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("ReconcilePending() = %d, %v, want 1", n, err)
+	}
+	if len(pix.requests) != 2 || pix.requests[1].RefundID != refund.ID {
+		t.Fatalf("requests = %+v, want reenvio de %s", pix.requests, refund.ID)
+	}
+	if payment.Status != domain.PaymentStatusRefunded || len(recorder.payments) != 1 {
+		t.Errorf("payment = %s, handlers = %v, want refunded e 1 chamada", payment.Status, recorder.payments)
+	}
+}
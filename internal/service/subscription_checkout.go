@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// PixAutoCheckoutRequest inicia a assinatura via PIX Automático
+type PixAutoCheckoutRequest struct {
+	PlanID   string                 `json:"plan_id"`
+	Interval domain.BillingInterval `json:"interval,omitempty"` // Padrão: monthly
+	CPF      string                 `json:"cpf"`                // CPF ou CNPJ do pagador (com ou sem pontuação)
+	Name     string                 `json:"name"`               // Nome do pagador
+}
+
+// PixAutoCheckout é a autorização de PIX Automático aguardando o pagador.
+// O QR Code é o próprio copia e cola: o app renderiza PixCode.
+// Com a Efí indisponível (Status queued) a configuração fica na fila: o app
+// consulta PendingChargeID depois de RetryAfterSeconds ou oferece o Stripe.
+type PixAutoCheckout struct {
+	Status          CheckoutStatus       `json:"status"`
+	Subscription    *domain.Subscription `json:"subscription"`
+	AuthorizationID string               `json:"authorization_id,omitempty"`
+	PixCode         string               `json:"pix_copia_e_cola,omitempty"`
+	Location        string               `json:"location,omitempty"`
+	Amount          int                  `json:"amount"`          // Valor de cada cobrança (centavos)
+	FirstChargeAt   time.Time            `json:"first_charge_at"` // Fim do trial, ou agora se já expirou
+
+	PendingChargeID   string `json:"pending_charge_id,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+	StripeAvailable   bool   `json:"stripe_available,omitempty"`
+}
+
+// PendingCheckout é a situação de uma configuração de PIX Automático adiada
+type PendingCheckout struct {
+	ID              string                    `json:"id"`
+	Status          ports.PendingChargeStatus `json:"status"`
+	NextAttemptAt   *time.Time                `json:"next_attempt_at,omitempty"` // Enquanto queued
+	AuthorizationID string                    `json:"authorization_id,omitempty"`
+	PixCode         string                    `json:"pix_copia_e_cola,omitempty"` // Quando completed
+	Location        string                    `json:"location,omitempty"`
+	Error           string                    `json:"error,omitempty"` // Quando failed
+}
+
+// TrialConversion é o trial convertido antes do fim: a assinatura já ativa e
+// a cobrança PIX do primeiro ciclo
+type TrialConversion struct {
+	Subscription *domain.Subscription     `json:"subscription"`
+	Charge       *ports.PixChargeResponse `json:"charge"`
+	Amount       int                      `json:"amount"` // Valor do primeiro ciclo (centavos)
+}
+
+// conversionChargeExpiry é a validade da cobrança PIX do primeiro ciclo de
+// uma conversão antecipada
+const conversionChargeExpiry = 24 * time.Hour
+
+// StripeCheckoutRequest inicia a assinatura via Stripe (cartão)
+type StripeCheckoutRequest struct {
+	PlanID   string                 `json:"plan_id"`
+	Interval domain.BillingInterval `json:"interval,omitempty"` // Padrão: monthly
+	Email    string                 `json:"email"`
+	Name     string                 `json:"name"`
+}
+
+// StripeCheckout é a subscription criada no Stripe aguardando o primeiro
+// pagamento; o app confirma o cartão com ClientSecret
+type StripeCheckout struct {
+	Subscription *domain.Subscription `json:"subscription"`
+	ClientSecret string               `json:"client_secret"`
+}
+
+// SubscriptionCheckoutService escolhe o gateway de uma assinatura em trial
+// (ou com trial expirado) e cria a cobrança recorrente nele. A assinatura só é
+// ativada quando o webhook do gateway confirmar o pagamento. O PIX Automático
+// passa pelo CheckoutService: com a Efí fora, a configuração é enfileirada e
+// vinculada à assinatura quando o worker a cria (HandlePendingChargeCompleted).
+type SubscriptionCheckoutService struct {
+	subscriptions ports.SubscriptionService
+	plans         ports.PlanService
+	payments      ports.PaymentService
+	checkout      *CheckoutService
+	stripe        ports.StripeProvider
+
+	clocked
+	audited
+}
+
+// NewSubscriptionCheckoutService cria o serviço de checkout de assinaturas.
+// checkout e stripe podem ser nil: o gateway ausente responde indisponível.
+func NewSubscriptionCheckoutService(
+	subscriptions ports.SubscriptionService,
+	plans ports.PlanService,
+	payments ports.PaymentService,
+	checkout *CheckoutService,
+	stripe ports.StripeProvider,
+) *SubscriptionCheckoutService {
+	return &SubscriptionCheckoutService{
+		subscriptions: subscriptions,
+		plans:         plans,
+		payments:      payments,
+		checkout:      checkout,
+		stripe:        stripe,
+	}
+}
+
+// StartPixAuto cria a recorrência de PIX Automático da academia. A primeira
+// cobrança fica para o fim do trial; com o trial expirado, é imediata. Com a
+// Efí indisponível a assinatura não muda até a configuração adiada ser criada.
+func (s *SubscriptionCheckoutService) StartPixAuto(ctx context.Context, academyID string, req *PixAutoCheckoutRequest) (*PixAutoCheckout, error) {
+	if s.checkout == nil {
+		return nil, fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+	}
+	document, err := domain.NormalizeDocument(req.CPF)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, plan, amount, err := s.prepare(ctx, academyID, req.PlanID, req.Interval)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(sub)
+	now := s.now()
+	firstCharge := sub.FirstChargeDate(false, now)
+
+	previous := *sub
+	if err := sub.StartCheckout(plan.ID, intervalOrDefault(req.Interval), domain.PaymentGatewayPixAuto, now); err != nil {
+		return nil, err
+	}
+
+	result, err := s.checkout.SetupRecurrence(ctx, &ports.PixRecurrenceSetupRequest{
+		AcademyID:    academyID,
+		PlanID:       plan.ID,
+		CustomerCPF:  document,
+		CustomerName: req.Name,
+		Amount:       int64(amount),
+		Interval:     sub.BillingInterval,
+		StartDate:    firstCharge,
+		Description:  "Assinatura BlackBelt " + plan.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar PIX Automático: %w", err)
+	}
+	if result.Status == CheckoutStatusQueued {
+		return &PixAutoCheckout{
+			Status:            CheckoutStatusQueued,
+			Subscription:      &previous,
+			Amount:            amount,
+			FirstChargeAt:     firstCharge,
+			PendingChargeID:   result.PendingChargeID,
+			RetryAfterSeconds: int(result.RetryAfter.Seconds()),
+			StripeAvailable:   result.StripeAvailable && s.stripe != nil,
+		}, nil
+	}
+
+	setup := result.Recurrence
+	attachPixAuto(sub, setup, document, req.Name)
+	if err := s.subscriptions.Save(ctx, sub); err != nil {
+		s.abandon(ctx, sub)
+		return nil, fmt.Errorf("erro ao salvar assinatura: %w", err)
+	}
+	s.abandon(ctx, &previous)
+	s.audit(ctx, domain.AuditSubscriptionCheckout, before, sub, now)
+
+	return &PixAutoCheckout{
+		Status:          CheckoutStatusCreated,
+		Subscription:    sub,
+		AuthorizationID: setup.AuthorizationID,
+		PixCode:         setup.PixCode,
+		Location:        setup.Location,
+		Amount:          amount,
+		FirstChargeAt:   firstCharge,
+	}, nil
+}
+
+// HandlePendingChargeCompleted vincula à assinatura a recorrência de um
+// checkout adiado que o worker criou na Efí, como StartPixAuto faria. Se a
+// assinatura já não aceita checkout (ex: ativada pelo Stripe nesse meio
+// tempo), a recorrência nova é cancelada.
+func (s *SubscriptionCheckoutService) HandlePendingChargeCompleted(ctx context.Context, pending *ports.PendingCharge) error {
+	if pending.Kind != ports.PendingChargeKindPixRecurrence || pending.Recurrence == nil || pending.RecurrenceResult == nil {
+		return nil
+	}
+	req, setup := pending.Recurrence, pending.RecurrenceResult
+
+	var (
+		sub      *domain.Subscription
+		previous domain.Subscription
+		before   json.RawMessage
+	)
+	now := s.now()
+	err := retryOnConflict(ctx, "assinatura da academia "+pending.AcademyID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByAcademy(ctx, pending.AcademyID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		before = snapshot(sub)
+		previous = *sub
+		if err := sub.StartCheckout(req.PlanID, intervalOrDefault(req.Interval), domain.PaymentGatewayPixAuto, now); err != nil {
+			return err
+		}
+		attachPixAuto(sub, setup, req.CustomerCPF, req.CustomerName)
+		return s.subscriptions.Save(ctx, sub)
+	})
+	if errors.Is(err, domain.ErrInvalidTransition) {
+		if cancelErr := s.checkout.pix.CancelRecurrence(ctx, setup.AuthorizationID); cancelErr != nil {
+			log.Printf("[Checkout] Erro ao cancelar recorrência não usada %s: %v", setup.AuthorizationID, cancelErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao vincular checkout adiado %s: %w", pending.ID, err)
+	}
+	s.abandon(ctx, &previous)
+	s.audit(ctx, domain.AuditSubscriptionCheckout, before, sub, now)
+	return nil
+}
+
+// GetPendingCheckout consulta uma configuração de PIX Automático adiada da academia
+func (s *SubscriptionCheckoutService) GetPendingCheckout(ctx context.Context, academyID, id string) (*PendingCheckout, error) {
+	if s.checkout == nil {
+		return nil, fmt.Errorf("cobrança pendente %s: %w", id, domain.ErrNotFound)
+	}
+	pending, err := s.checkout.GetPendingCharge(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Outra academia não descobre se o ID existe
+	if pending.AcademyID != academyID || pending.Kind != ports.PendingChargeKindPixRecurrence {
+		return nil, fmt.Errorf("cobrança pendente %s: %w", id, domain.ErrNotFound)
+	}
+
+	result := &PendingCheckout{ID: pending.ID, Status: pending.Status}
+	switch pending.Status {
+	case ports.PendingChargeStatusQueued:
+		next := pending.NextAttemptAt
+		result.NextAttemptAt = &next
+	case ports.PendingChargeStatusCompleted:
+		if setup := pending.RecurrenceResult; setup != nil {
+			result.AuthorizationID = setup.AuthorizationID
+			result.PixCode = setup.PixCode
+			result.Location = setup.Location
+		}
+	case ports.PendingChargeStatusFailed:
+		result.Error = "não foi possível configurar o PIX Automático"
+	}
+	return result, nil
+}
+
+// attachPixAuto grava na assinatura a recorrência criada e os dados do pagador
+func attachPixAuto(sub *domain.Subscription, setup *ports.PixRecurrenceSetupResponse, document, name string) {
+	sub.PixAuthorizationID = &setup.AuthorizationID
+	sub.PixRecurrenceID = &setup.RecurrenceID
+	sub.PixCustomerCPF = &document
+	sub.PixCustomerName = &name
+}
+
+// StartStripe cria o customer e a subscription no Stripe para a academia
+func (s *SubscriptionCheckoutService) StartStripe(ctx context.Context, academyID string, req *StripeCheckoutRequest) (*StripeCheckout, error) {
+	if s.stripe == nil {
+		return nil, fmt.Errorf("%w: Stripe não configurado", ports.ErrGatewayUnavailable)
+	}
+
+	sub, plan, _, err := s.prepare(ctx, academyID, req.PlanID, req.Interval)
+	if err != nil {
+		return nil, err
+	}
+	interval := intervalOrDefault(req.Interval)
+	priceID := plan.StripePriceIDMonthly
+	if interval == domain.BillingIntervalYearly {
+		priceID = plan.StripePriceIDYearly
+	}
+	if priceID == nil {
+		return nil, fmt.Errorf("%w: plano %s sem price no Stripe", domain.ErrIntervalUnavailable, plan.Slug)
+	}
+	before := snapshot(sub)
+	now := s.now()
+
+	previous := *sub
+	if err := sub.StartCheckout(plan.ID, interval, domain.PaymentGatewayStripe, now); err != nil {
+		return nil, err
+	}
+
+	customerID := ""
+	if sub.StripeCustomerID != nil {
+		customerID = *sub.StripeCustomerID
+	} else if customerID, err = s.stripe.CreateCustomer(ctx, academyID, req.Email, req.Name); err != nil {
+		return nil, fmt.Errorf("erro ao criar customer no Stripe: %w", err)
+	}
+
+	subscriptionID, clientSecret, err := s.stripe.CreateSubscription(ctx, customerID, *priceID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar subscription no Stripe: %w", err)
+	}
+	sub.StripeCustomerID = &customerID
+	sub.StripeSubscriptionID = &subscriptionID
+	sub.StripePriceID = priceID
+
+	if err := s.subscriptions.Save(ctx, sub); err != nil {
+		s.abandon(ctx, sub)
+		return nil, fmt.Errorf("erro ao salvar assinatura: %w", err)
+	}
+	s.abandon(ctx, &previous)
+	s.audit(ctx, domain.AuditSubscriptionCheckout, before, sub, now)
+
+	return &StripeCheckout{Subscription: sub, ClientSecret: clientSecret}, nil
+}
+
+// ConvertTrialNow encerra o trial agora, a pedido do dono: a assinatura fica
+// ativa com o primeiro período começando na conversão, o primeiro ciclo é
+// cobrado por PIX imediato e o PIX Automático passa a vencer no fim desse
+// período. Exige a recorrência já criada por StartPixAuto. A conversão é
+// gravada antes de chegar ao gateway; se a Efí recusar, o trial é restaurado.
+// A cobrança conta como recorrente: sem pagamento, entra na régua de cobrança.
+func (s *SubscriptionCheckoutService) ConvertTrialNow(ctx context.Context, academyID, actor string) (*TrialConversion, error) {
+	if s.checkout == nil {
+		return nil, fmt.Errorf("%w: PIX Automático não configurado", ports.ErrGatewayUnavailable)
+	}
+
+	var (
+		sub, previous   *domain.Subscription
+		before          json.RawMessage
+		amount, nextAmt int
+		payment         *domain.PaymentHistory
+	)
+	now := s.now()
+	err := retryOnConflict(ctx, "assinatura da academia "+academyID, func() error {
+		var err error
+		sub, err = s.subscriptions.GetByAcademy(ctx, academyID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		if sub.Status != domain.SubscriptionStatusTrialing {
+			return &domain.TransitionError{From: sub.Status, To: domain.SubscriptionStatusActive}
+		}
+		if !isPixAuto(sub) || sub.PixRecurrenceID == nil {
+			return fmt.Errorf("%w: a conversão antecipada exige o PIX Automático configurado", domain.ErrValidation)
+		}
+		plan, err := s.plans.GetByID(ctx, sub.PlanID)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar plano: %w", err)
+		}
+		before = snapshot(sub)
+		loaded := *sub
+		previous = &loaded
+
+		// O primeiro ciclo é cobrado com o desconto atual e já consome um ciclo dele
+		gross, discount, err := sub.NextChargeAmount(plan)
+		if err != nil {
+			return err
+		}
+		payment = domain.NewPaymentHistory(sub.ID, sub.AcademyID, gross, domain.PaymentGatewayPixAuto, now)
+		if sub.Discount != nil {
+			payment.ApplyDiscount(discount, sub.Discount.CouponID)
+		}
+		amount = gross - discount
+
+		if err := sub.ConvertTrial(domain.PaymentGatewayPixAuto, sub.FirstChargeDate(true, now), actor); err != nil {
+			return err
+		}
+		sub.CompleteBillingCycle()
+		if gross, discount, err = sub.NextChargeAmount(plan); err != nil {
+			return err
+		}
+		nextAmt = gross - discount
+		return s.subscriptions.Save(ctx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	charge, err := s.chargeConversion(ctx, sub, previous, payment, amount, nextAmt)
+	if err != nil {
+		s.revertConversion(ctx, previous, sub)
+		return nil, err
+	}
+	s.audit(ctx, domain.AuditTrialConverted, before, sub, now)
+	return &TrialConversion{Subscription: sub, Charge: charge, Amount: amount}, nil
+}
+
+// chargeConversion leva a conversão antecipada à Efí: move o vencimento da
+// recorrência para o fim do primeiro período (e ajusta o valor, se o ciclo do
+// cupom mudou), registra o pagamento do primeiro ciclo e cria a cobrança PIX.
+// Em caso de falha, o calendário e o valor da recorrência são restaurados.
+func (s *SubscriptionCheckoutService) chargeConversion(ctx context.Context, sub, previous *domain.Subscription, payment *domain.PaymentHistory, amount, nextAmount int) (*ports.PixChargeResponse, error) {
+	pix := s.checkout.pix
+	recurrenceID := *sub.PixRecurrenceID
+	if err := pix.RescheduleRecurrence(ctx, recurrenceID, *sub.CurrentPeriodEnd); err != nil {
+		return nil, fmt.Errorf("erro ao mover vencimento da recorrência: %w", err)
+	}
+	restore := func() {
+		if previous.TrialEndDate != nil {
+			if err := pix.RescheduleRecurrence(ctx, recurrenceID, *previous.TrialEndDate); err != nil {
+				log.Printf("[Checkout] Erro ao restaurar vencimento da recorrência %s: %v", recurrenceID, err)
+			}
+		}
+		if nextAmount != amount {
+			if err := pix.UpdateRecurrenceAmount(ctx, recurrenceID, int64(amount)); err != nil {
+				log.Printf("[Checkout] Erro ao restaurar valor da recorrência %s: %v", recurrenceID, err)
+			}
+		}
+	}
+	if nextAmount != amount {
+		if err := pix.UpdateRecurrenceAmount(ctx, recurrenceID, int64(nextAmount)); err != nil {
+			restore()
+			return nil, fmt.Errorf("erro ao atualizar valor da recorrência: %w", err)
+		}
+	}
+
+	// O pagamento é gravado antes da cobrança para que o webhook o encontre
+	txid := newTxID()
+	method := "pix"
+	payment.GatewayPaymentID = &txid
+	payment.GatewayChargeID = &recurrenceID
+	payment.PaymentMethod = &method
+	payment.PeriodStart = sub.CurrentPeriodStart
+	payment.PeriodEnd = sub.CurrentPeriodEnd
+	if err := s.payments.RecordPayment(ctx, payment); err != nil {
+		restore()
+		return nil, fmt.Errorf("erro ao registrar cobrança do primeiro ciclo: %w", err)
+	}
+
+	req := &ports.PixChargeRequest{
+		TxID:        txid,
+		Amount:      int64(amount),
+		Description: "Assinatura BlackBelt - primeiro ciclo",
+		ExpiresIn:   int(conversionChargeExpiry.Seconds()),
+	}
+	if sub.PixCustomerCPF != nil {
+		req.PayerDocument = *sub.PixCustomerCPF
+	}
+	if sub.PixCustomerName != nil {
+		req.PayerName = *sub.PixCustomerName
+	}
+	charge, err := pix.CreatePixCharge(ctx, req)
+	if err != nil {
+		restore()
+		if saveErr := updatePayment(ctx, s.payments, payment, func(p *domain.PaymentHistory) error {
+			p.Fail("cobrança não criada no gateway", "", s.now())
+			return nil
+		}); saveErr != nil {
+			log.Printf("[Checkout] Erro ao registrar falha da cobrança %s: %v", txid, saveErr)
+		}
+		return nil, fmt.Errorf("erro ao cobrar primeiro ciclo: %w", err)
+	}
+	return charge, nil
+}
+
+// revertConversion devolve ao trial uma conversão antecipada que o gateway
+// recusou. Se a assinatura mudou nesse meio tempo (ex: o webhook de outra
+// cobrança), a mudança prevalece.
+func (s *SubscriptionCheckoutService) revertConversion(ctx context.Context, previous, converted *domain.Subscription) {
+	err := retryOnConflict(ctx, "assinatura "+converted.ID, func() error {
+		current, err := s.subscriptions.GetByID(ctx, converted.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.SubscriptionStatusActive || current.CurrentPeriodStart == nil ||
+			!current.CurrentPeriodStart.Equal(*converted.CurrentPeriodStart) {
+			return nil
+		}
+		restored := *previous
+		restored.Version = current.Version
+		restored.UpdatedAt = s.now()
+		return s.subscriptions.Save(ctx, &restored)
+	})
+	if err != nil {
+		log.Printf("[Checkout] Erro ao desfazer conversão antecipada da assinatura %s: %v", converted.ID, err)
+	}
+}
+
+// prepare carrega a assinatura e o plano e calcula o valor da cobrança,
+// já com o desconto de cupom
+func (s *SubscriptionCheckoutService) prepare(ctx context.Context, academyID, planID string, interval domain.BillingInterval) (*domain.Subscription, *domain.SubscriptionPlan, int, error) {
+	sub, err := s.subscriptions.GetByAcademy(ctx, academyID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+	plan, err := s.plans.GetByID(ctx, planID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+	if !plan.IsActive {
+		return nil, nil, 0, fmt.Errorf("%w: plano %s", domain.ErrNotFound, plan.Slug)
+	}
+	price, err := plan.PriceFor(intervalOrDefault(interval))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return sub, plan, price - sub.Discount.Amount(price), nil
+}
+
+// abandon cancela no gateway um checkout que o pagador não vai concluir: o
+// anterior (estado da assinatura antes de StartCheckout), depois que o novo foi
+// criado e gravado, ou o novo, se a gravação falhou. Falhas são só logadas: a
+// autorização pendente expira sozinha.
+func (s *SubscriptionCheckoutService) abandon(ctx context.Context, sub *domain.Subscription) {
+	if sub.PaymentGateway == nil {
+		return
+	}
+	var err error
+	switch *sub.PaymentGateway {
+	case domain.PaymentGatewayPixAuto:
+		if s.checkout != nil && sub.PixAuthorizationID != nil {
+			err = s.checkout.pix.CancelRecurrence(ctx, *sub.PixAuthorizationID)
+		}
+	case domain.PaymentGatewayStripe:
+		if s.stripe != nil && sub.StripeSubscriptionID != nil {
+			err = s.stripe.CancelSubscription(ctx, *sub.StripeSubscriptionID, false)
+		}
+	}
+	if err != nil {
+		log.Printf("[Checkout] Erro ao abandonar checkout anterior da assinatura %s: %v", sub.ID, err)
+	}
+}
+
+// intervalOrDefault aplica o intervalo mensal quando o pedido não informa
+func intervalOrDefault(interval domain.BillingInterval) domain.BillingInterval {
+	if interval == "" {
+		return domain.BillingIntervalMonthly
+	}
+	return interval
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// pendingPixCheckout cria uma assinatura em trial com um checkout de PIX
+// Automático anterior que o pagador não concluiu
+func pendingPixCheckout(t *testing.T) (*domain.Subscription, *fakePlans) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, domain.BillingLocation)
+	pro := domain.NewSubscriptionPlan("Pro", "pro", 19900, now)
+	pro.ID = "plan-pro"
+
+	sub := domain.NewTrialSubscription("academy-1", domain.TrialTerms{PlanID: pro.ID, Days: 14}, now)
+	sub.ID = "sub-1"
+	if err := sub.StartCheckout(pro.ID, domain.BillingIntervalMonthly, domain.PaymentGatewayPixAuto, now); err != nil {
+		t.Fatal(err)
+	}
+	previous := "rec-old"
+	sub.PixAuthorizationID = &previous
+	return sub, newFakePlans(pro)
+}
+
+func TestSubscriptionCheckoutService_AbandonsPreviousOnlyAfterSave(t *testing.T) {
+	ctx := context.Background()
+	req := &PixAutoCheckoutRequest{PlanID: "plan-pro", CPF: "529.982.247-25", Name: "Dono"}
+
+	tests := []struct {
+		name         string
+		subs         func(*domain.Subscription) ports.SubscriptionService
+		pixErr       error
+		wantErr      error
+		wantCanceled []string
+	}{
+		{
+			name:         "gateway recusa: checkout anterior continua válido",
+			subs:         func(sub *domain.Subscription) ports.SubscriptionService { return newFakeSubscriptions(sub) },
+			pixErr:       ports.ErrGatewayRejected,
+			wantErr:      ports.ErrGatewayRejected,
+			wantCanceled: nil,
+		},
+		{
+			name: "gravação falha: cancela o novo e mantém o anterior",
+			subs: func(sub *domain.Subscription) ports.SubscriptionService {
+				return conflictingSubscriptions{newFakeSubscriptions(sub)}
+			},
+			wantErr:      domain.ErrConcurrentModification,
+			wantCanceled: []string{"rec-1"},
+		},
+		{
+			name:         "sucesso: cancela o anterior",
+			subs:         func(sub *domain.Subscription) ports.SubscriptionService { return newFakeSubscriptions(sub) },
+			wantCanceled: []string{"rec-old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, plans := pendingPixCheckout(t)
+			pix := &fakePix{err: tt.pixErr}
+			checkout := NewCheckoutService(pix, memory.NewChargeQueue(), CheckoutOptions{})
+			svc := NewSubscriptionCheckoutService(tt.subs(sub), plans, &fakePayments{}, checkout, nil)
+
+			_, err := svc.StartPixAuto(ctx, "academy-1", req)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("StartPixAuto() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StartPixAuto() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(pix.canceled) != len(tt.wantCanceled) || (len(tt.wantCanceled) > 0 && pix.canceled[0] != tt.wantCanceled[0]) {
+				t.Errorf("CancelRecurrence chamado com %v, want %v", pix.canceled, tt.wantCanceled)
+			}
+		})
+	}
+}
+
+func TestSubscriptionCheckoutService_QueuedWhileGatewayUnavailable(t *testing.T) {
+	ctx := context.Background()
+	sub, plans := pendingPixCheckout(t)
+	subs := &detachedSubscriptions{fakeSubscriptions: newFakeSubscriptions(sub)}
+	pix := &fakePix{err: &unavailableError{wait: 45 * time.Second}}
+	checkout := NewCheckoutService(pix, memory.NewChargeQueue(), CheckoutOptions{})
+	clock := domain.NewFakeClock(time.Date(2026, 3, 2, 10, 0, 0, 0, domain.BillingLocation))
+	checkout.SetClock(clock)
+	svc := NewSubscriptionCheckoutService(subs, plans, &fakePayments{}, checkout, nil)
+	svc.SetClock(clock)
+	checkout.OnCompleted(svc)
+
+	result, err := svc.StartPixAuto(ctx, "academy-1", &PixAutoCheckoutRequest{PlanID: "plan-pro", CPF: "529.982.247-25", Name: "Dono"})
+	if err != nil {
+		t.Fatalf("StartPixAuto() error = %v", err)
+	}
+	if result.Status != CheckoutStatusQueued || result.PendingChargeID == "" || result.RetryAfterSeconds != 45 {
+		t.Fatalf("StartPixAuto() = %+v, want queued com retry de 45s", result)
+	}
+	if got := result.Subscription.PixAuthorizationID; got == nil || *got != "rec-old" {
+		t.Fatalf("assinatura mudou enquanto a configuração está na fila: %v", got)
+	}
+
+	// Outra academia não enxerga a cobrança pendente
+	if _, err := svc.GetPendingCheckout(ctx, "academy-2", result.PendingChargeID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetPendingCheckout() de outra academia error = %v, want ErrNotFound", err)
+	}
+
+	// A Efí volta: o worker cria a recorrência, que é vinculada à assinatura
+	pix.err = nil
+	clock.Advance(time.Minute)
+	if n, err := checkout.ProcessPending(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("ProcessPending() = %d, %v, want 1, nil", n, err)
+	}
+
+	stored, _ := subs.GetByAcademy(ctx, "academy-1")
+	if stored.PixAuthorizationID == nil || *stored.PixAuthorizationID != "rec-1" {
+		t.Errorf("PixAuthorizationID = %v, want rec-1", stored.PixAuthorizationID)
+	}
+	if len(pix.canceled) != 1 || pix.canceled[0] != "rec-old" {
+		t.Errorf("CancelRecurrence chamado com %v, want [rec-old]", pix.canceled)
+	}
+
+	pending, err := svc.GetPendingCheckout(ctx, "academy-1", result.PendingChargeID)
+	if err != nil {
+		t.Fatalf("GetPendingCheckout() error = %v", err)
+	}
+	if pending.Status != ports.PendingChargeStatusCompleted || pending.AuthorizationID != "rec-1" {
+		t.Errorf("GetPendingCheckout() = %+v, want completed com rec-1", pending)
+	}
+}
+
+func TestSubscriptionCheckoutService_ConvertTrialNow(t *testing.T) {
+	ctx := context.Background()
+	gateway := domain.PaymentGatewayPixAuto
+
+	// Conversão no 5º dia do trial: ativa agora, período a partir da
+	// conversão e recorrência movida para o fim dele
+	f := newTrialFixture(t, "plan-pro", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.sub.PaymentGateway = &gateway
+	now := f.start.AddDate(0, 0, 5)
+	f.clock.Set(now)
+	conversion, err := f.subscriptionCheckout().ConvertTrialNow(ctx, "academy-1", "user-1")
+	if err != nil {
+		t.Fatalf("ConvertTrialNow() error = %v", err)
+	}
+	sub := f.sub
+	if sub.Status != domain.SubscriptionStatusActive || !sub.CurrentPeriodStart.Equal(now) || !sub.TrialEndDate.Equal(now) {
+		t.Fatalf("status = %s, período = %v, trial até %v; want active a partir de %v",
+			sub.Status, sub.CurrentPeriodStart, sub.TrialEndDate, now)
+	}
+	if want := now.AddDate(0, 1, 0); !sub.CurrentPeriodEnd.Equal(want) {
+		t.Errorf("CurrentPeriodEnd = %v, want %v", sub.CurrentPeriodEnd, want)
+	}
+	if len(f.pix.rescheduled) != 1 || !f.pix.rescheduled[0].Equal(*sub.CurrentPeriodEnd) {
+		t.Errorf("recorrência reagendada para %v, want [%v]", f.pix.rescheduled, sub.CurrentPeriodEnd)
+	}
+	if conversion.Charge == nil || conversion.Amount != 19900 || len(f.payments.payments) != 1 {
+		t.Fatalf("cobrança do primeiro ciclo = %+v, pagamentos = %d; want 19900", conversion, len(f.payments.payments))
+	}
+
+	// O pagamento confirma o primeiro ciclo sem avançar o período
+	paid := &ports.ChargeNotification{
+		Gateway:          domain.PaymentGatewayPixAuto,
+		GatewayPaymentID: conversion.Charge.TxID,
+		EndToEndID:       "E1",
+		Amount:           19900,
+		Paid:             true,
+	}
+	if err := f.billing().HandleCharge(ctx, paid); err != nil {
+		t.Fatalf("HandleCharge() error = %v", err)
+	}
+	if payment := f.payments.payments[0]; payment.Status != domain.PaymentStatusSucceeded || !payment.PeriodStart.Equal(now) {
+		t.Errorf("pagamento = %s de %v, want succeeded do período da conversão", payment.Status, payment.PeriodStart)
+	}
+	if !sub.CurrentPeriodStart.Equal(now) {
+		t.Errorf("CurrentPeriodStart = %v, want %v", sub.CurrentPeriodStart, now)
+	}
+
+	// Efí recusa a cobrança: o trial é restaurado, com o vencimento original
+	f = newTrialFixture(t, "plan-pro", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	f.sub.PaymentGateway = &gateway
+	trialEnd := *f.sub.TrialEndDate
+	f.clock.Set(now)
+	f.pix.err = ports.ErrGatewayRejected
+	if _, err := f.subscriptionCheckout().ConvertTrialNow(ctx, "academy-1", "user-1"); !errors.Is(err, ports.ErrGatewayRejected) {
+		t.Fatalf("ConvertTrialNow() error = %v, want ErrGatewayRejected", err)
+	}
+	stored, _ := f.subs.GetByAcademy(ctx, "academy-1")
+	if stored.Status != domain.SubscriptionStatusTrialing || !stored.TrialEndDate.Equal(trialEnd) {
+		t.Errorf("status = %s, trial até %v; want trialing até %v", stored.Status, stored.TrialEndDate, trialEnd)
+	}
+	if n := len(f.pix.rescheduled); n != 2 || !f.pix.rescheduled[1].Equal(trialEnd) {
+		t.Errorf("vencimentos = %v, want restaurado para %v", f.pix.rescheduled, trialEnd)
+	}
+	if f.payments.payments[0].Status != domain.PaymentStatusFailed {
+		t.Errorf("pagamento = %s, want failed", f.payments.payments[0].Status)
+	}
+
+	// Sem PIX Automático configurado não há o que cobrar
+	f = newTrialFixture(t, "plan-pro", time.Date(2026, 4, 1, 9, 0, 0, 0, domain.BillingLocation))
+	if _, err := f.subscriptionCheckout().ConvertTrialNow(ctx, "academy-1", "user-1"); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("ConvertTrialNow() sem gateway error = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// MaxTestClockAdvance limita quanto um test clock avança por chamada
+const MaxTestClockAdvance = 2 * 366 * 24 * time.Hour
+
+// maxTestClockCycles limita os passos (fim de trial, renovações) de um único avanço
+const maxTestClockCycles = 36
+
+// TestClockResult descreve o estado da academia após avançar o test clock
+type TestClockResult struct {
+	AcademyID    string               `json:"academy_id"`
+	Now          time.Time            `json:"now"` // Hora simulada da academia
+	Renewals     int                  `json:"renewals"`
+	Subscription *domain.Subscription `json:"subscription"`
+}
+
+// TestClockService é o test clock do sandbox: cada academia tem um relógio
+// próprio que só anda para frente. Ao avançar, os mesmos serviços da produção
+// (conciliação de cobranças, régua, cancelamentos e expiração de trials) rodam
+// com o relógio da academia, restritos à assinatura dela. O que o gateway faria
+// sozinho é simulado: a cada vencimento da recorrência cadastrada chega uma
+// cobrança paga, que converte o trial ou renova o período.
+type TestClockService struct {
+	subscriptions ports.SubscriptionService
+	payments      ports.PaymentService
+	plans         ports.PlanService
+	pix           ports.PixProvider
+	notifier      ports.Notifier
+	onSucceeded   []PaymentSucceededHandler
+
+	mu        sync.Mutex
+	clocks    map[string]*domain.FakeClock
+	advancing sync.Mutex // Um avanço por vez: o próximo parte da hora gravada pelo anterior
+
+	clocked
+	audited
+}
+
+// NewTestClockService cria o serviço de test clock (só deve ser exposto no sandbox)
+func NewTestClockService(
+	subscriptions ports.SubscriptionService,
+	payments ports.PaymentService,
+	plans ports.PlanService,
+	pix ports.PixProvider,
+	notifier ports.Notifier,
+) *TestClockService {
+	return &TestClockService{
+		subscriptions: subscriptions,
+		payments:      payments,
+		plans:         plans,
+		pix:           pix,
+		notifier:      notifier,
+		clocks:        map[string]*domain.FakeClock{},
+	}
+}
+
+// OnPaymentSucceeded registra handlers chamados a cada pagamento simulado
+func (s *TestClockService) OnPaymentSucceeded(handlers ...PaymentSucceededHandler) {
+	s.onSucceeded = append(s.onSucceeded, handlers...)
+}
+
+// Now retorna a hora simulada da academia (a hora real se o relógio nunca avançou)
+func (s *TestClockService) Now(academyID string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if clock, ok := s.clocks[academyID]; ok {
+		return clock.Now()
+	}
+	return s.now()
+}
+
+// Advance avança o relógio da academia em d. Cada vencimento até lá é
+// processado na sua data (fim do trial ou do período), e os jobs rodam de novo
+// na hora final. O relógio só anda depois de cada passo gravado: um avanço que
+// falha deixa a academia no último passo concluído, nunca no futuro com a
+// assinatura no passado.
+func (s *TestClockService) Advance(ctx context.Context, academyID string, d time.Duration) (*TestClockResult, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("%w: o test clock só avança: duração deve ser positiva", domain.ErrValidation)
+	}
+	if d > MaxTestClockAdvance {
+		return nil, fmt.Errorf("%w: avanço máximo do test clock é de %d dias", domain.ErrValidation, int(MaxTestClockAdvance.Hours()/24))
+	}
+
+	s.advancing.Lock()
+	defer s.advancing.Unlock()
+
+	clock := s.clockFor(academyID)
+	target := clock.Now().Add(d)
+	sim := domain.NewFakeClock(clock.Now())
+	jobs := s.jobsFor(academyID, sim)
+	result := &TestClockResult{AcademyID: academyID, Now: target}
+
+	for i := 0; i < maxTestClockCycles; i++ {
+		sub, err := s.subscriptions.GetByAcademy(ctx, academyID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		at, due := nextTestClockStep(sub, target)
+		if !due {
+			break
+		}
+
+		status := sub.Status
+		sim.Set(at)
+		if err := jobs.step(ctx, sub); err != nil {
+			return nil, err
+		}
+		clock.Set(at)
+
+		after, err := s.subscriptions.GetByAcademy(ctx, academyID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+		}
+		if next, due := nextTestClockStep(after, target); due && next.Equal(at) && after.Status == status {
+			break // Nada mudou: o passo não se aplica (ex.: período vencido sem recorrência)
+		}
+		if status == domain.SubscriptionStatusActive && after.Status == domain.SubscriptionStatusActive {
+			result.Renewals++
+		}
+	}
+
+	sim.Set(target)
+	if err := jobs.run(ctx); err != nil {
+		return nil, err
+	}
+	clock.Set(target)
+
+	sub, err := s.subscriptions.GetByAcademy(ctx, academyID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+	result.Subscription = sub
+	return result, nil
+}
+
+// clockFor devolve o relógio da academia, criando-o na hora atual
+func (s *TestClockService) clockFor(academyID string) *domain.FakeClock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clock, ok := s.clocks[academyID]
+	if !ok {
+		clock = domain.NewFakeClock(s.now())
+		s.clocks[academyID] = clock
+	}
+	return clock
+}
+
+// testClockJobs são os serviços da produção ligados ao relógio simulado e à
+// assinatura de uma academia
+type testClockJobs struct {
+	clock         domain.Clock
+	plans         ports.PlanService
+	subscriptions *academySubscriptions
+	billing       *BillingService
+	dunning       *DunningService
+	cancellations *CancellationService
+}
+
+// jobsFor monta os serviços sobre a academia com o relógio clock
+func (s *TestClockService) jobsFor(academyID string, clock domain.Clock) *testClockJobs {
+	subs := &academySubscriptions{SubscriptionService: s.subscriptions, academyID: academyID, clock: clock}
+
+	planChanges := NewPlanChangeService(subs, s.plans, s.payments, s.pix, nil)
+	planChanges.SetClock(clock)
+	planChanges.SetAuditLog(s.auditLog)
+	dunning := NewDunningService(subs, s.payments, s.plans, s.pix, s.notifier)
+	dunning.SetClock(clock)
+	dunning.OnPaymentSucceeded(s.onSucceeded...)
+	billing := NewBillingService(subs, s.payments, planChanges, dunning)
+	billing.SetClock(clock)
+	billing.SetAuditLog(s.auditLog)
+	cancellations := NewCancellationService(subs, s.pix, nil)
+	cancellations.SetClock(clock)
+	cancellations.SetAuditLog(s.auditLog)
+
+	return &testClockJobs{clock: clock, plans: s.plans, subscriptions: subs, billing: billing, dunning: dunning, cancellations: cancellations}
+}
+
+// step processa o vencimento da assinatura na hora do relógio simulado
+func (j *testClockJobs) step(ctx context.Context, sub *domain.Subscription) error {
+	if sub.Status == domain.SubscriptionStatusActive && sub.CancelAtPeriodEnd {
+		_, err := j.cancellations.Run(ctx)
+		return err
+	}
+	n, ok, err := j.recurringCharge(ctx, sub)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return j.billing.HandleCharge(ctx, n)
+	}
+	if sub.Status == domain.SubscriptionStatusTrialing {
+		_, err := j.subscriptions.ExpireTrials(ctx)
+		return err
+	}
+	return nil
+}
+
+// run executa os jobs periódicos na hora do relógio simulado
+func (j *testClockJobs) run(ctx context.Context) error {
+	if _, err := j.subscriptions.ExpireTrials(ctx); err != nil {
+		return fmt.Errorf("erro ao expirar trial: %w", err)
+	}
+	if _, err := j.cancellations.Run(ctx); err != nil {
+		return err
+	}
+	if _, err := j.dunning.Run(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// recurringCharge monta a cobrança paga que o gateway enviaria no vencimento
+// da recorrência. Sem recorrência cadastrada não há cobrança (ok false). O ID é
+// derivado da data, então repetir o passo não duplica o pagamento.
+func (j *testClockJobs) recurringCharge(ctx context.Context, sub *domain.Subscription) (*ports.ChargeNotification, bool, error) {
+	n := &ports.ChargeNotification{Paid: true}
+	switch {
+	case sub.PixRecurrenceID != nil:
+		n.Gateway, n.SubscriptionRef = domain.PaymentGatewayPixAuto, *sub.PixRecurrenceID
+	case sub.StripeSubscriptionID != nil:
+		n.Gateway, n.SubscriptionRef = domain.PaymentGatewayStripe, *sub.StripeSubscriptionID
+	default:
+		return nil, false, nil
+	}
+
+	plan, err := j.plans.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, false, fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+	gross, discount, err := sub.NextChargeAmount(plan)
+	if err != nil {
+		return nil, false, err
+	}
+	n.Amount = gross - discount
+	n.GatewayPaymentID = fmt.Sprintf("testclock-%s-%d", sub.ID, j.clock.Now().Unix())
+	return n, true, nil
+}
+
+// nextTestClockStep devolve a data do próximo vencimento da assinatura até
+// target: fim do trial ou do período pago
+func nextTestClockStep(sub *domain.Subscription, target time.Time) (time.Time, bool) {
+	var at *time.Time
+	switch sub.Status {
+	case domain.SubscriptionStatusTrialing:
+		at = sub.TrialEndDate
+	case domain.SubscriptionStatusActive:
+		at = sub.CurrentPeriodEnd
+	}
+	if at == nil || at.After(target) {
+		return time.Time{}, false
+	}
+	return *at, true
+}
+
+// academySubscriptions restringe as listagens dos jobs à assinatura de uma
+// academia: os jobs do test clock rodam com o relógio dela sem alcançar as
+// assinaturas das outras. ExpireTrials usa o relógio simulado, não o do
+// repositório.
+type academySubscriptions struct {
+	ports.SubscriptionService
+	academyID string
+	clock     domain.Clock
+}
+
+// own devolve a assinatura da academia (nil se ela não tem assinatura)
+func (r *academySubscriptions) own(ctx context.Context) (*domain.Subscription, error) {
+	sub, err := r.SubscriptionService.GetByAcademy(ctx, r.academyID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// ListByStatus lista a assinatura da academia se ela estiver em status
+func (r *academySubscriptions) ListByStatus(ctx context.Context, status domain.SubscriptionStatus, limit int) ([]*domain.Subscription, error) {
+	sub, err := r.own(ctx)
+	if err != nil || sub == nil || sub.Status != status {
+		return nil, err
+	}
+	return []*domain.Subscription{sub}, nil
+}
+
+// ListCancellationsDue lista a assinatura da academia se o cancelamento venceu
+// ou o gateway ainda precisa encerrar a recorrência
+func (r *academySubscriptions) ListCancellationsDue(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error) {
+	sub, err := r.own(ctx)
+	if err != nil || sub == nil || !sub.CancellationDue(now) && !sub.GatewayCancelPending {
+		return nil, err
+	}
+	return []*domain.Subscription{sub}, nil
+}
+
+// ExpireTrials expira o trial da academia se já venceu no relógio simulado
+func (r *academySubscriptions) ExpireTrials(ctx context.Context) (int, error) {
+	expired := 0
+	err := retryOnConflict(ctx, "assinatura da academia "+r.academyID, func() error {
+		sub, err := r.own(ctx)
+		now := r.clock.Now()
+		if err != nil || sub == nil || sub.Status != domain.SubscriptionStatusTrialing ||
+			sub.TrialEndDate == nil || sub.TrialEndDate.After(now) {
+			return err
+		}
+		if err := sub.Expire(domain.ActorSystem, now); err != nil {
+			return err
+		}
+		if err := r.Save(ctx, sub); err != nil {
+			return err
+		}
+		expired = 1
+		return nil
+	})
+	return expired, err
+}
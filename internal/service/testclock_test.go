@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// newTestClockFixture cria o trial Starter de 10/jan com PIX Automático
+// cadastrado e o test clock sobre ele
+func newTestClockFixture(t *testing.T) (*TestClockService, *serviceFixture) {
+	t.Helper()
+	f := newTrialFixture(t, "plan-starter", time.Date(2026, 1, 10, 9, 0, 0, 0, domain.BillingLocation))
+	svc := NewTestClockService(f.subs, f.payments, f.plans, f.pix, f.notifier)
+	svc.SetClock(f.clock)
+	return svc, f
+}
+
+func TestTestClockService_TrialConversionAndRenewal(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newTestClockFixture(t)
+	start, payments := f.start, f.payments
+	invoices := &fakeInvoices{}
+	svc.OnPaymentSucceeded(NewInvoiceService(invoices, f.subs, f.plans, fakeBranding{}, fakeRenderer{}, nil))
+
+	// Dia 13: ainda em trial
+	result, err := svc.Advance(ctx, "academy-1", 13*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if result.Subscription.Status != domain.SubscriptionStatusTrialing || len(payments.payments) != 0 {
+		t.Fatalf("Status = %s, pagamentos = %d, want trialing sem cobrança", result.Subscription.Status, len(payments.payments))
+	}
+
+	// +75 dias (08/04): converte no fim do trial (24/01) e renova em 24/02 e 24/03
+	result, err = svc.Advance(ctx, "academy-1", 75*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if result.Subscription.Status != domain.SubscriptionStatusActive || result.Renewals != 2 {
+		t.Errorf("Status = %s, Renewals = %d, want active com 2 renovações", result.Subscription.Status, result.Renewals)
+	}
+	if len(payments.payments) != 3 {
+		t.Fatalf("pagamentos = %d, want 3", len(payments.payments))
+	}
+	if paidAt := *payments.payments[0].PaidAt; !paidAt.Equal(start.AddDate(0, 0, 14)) {
+		t.Errorf("primeiro pagamento em %v, want fim do trial", paidAt)
+	}
+	if want := time.Date(2026, 4, 24, 9, 0, 0, 0, domain.BillingLocation); !result.Subscription.CurrentPeriodEnd.Equal(want) {
+		t.Errorf("CurrentPeriodEnd = %v, want %v", result.Subscription.CurrentPeriodEnd, want)
+	}
+	if len(invoices.invoices) != 3 {
+		t.Errorf("faturas = %d, want 3", len(invoices.invoices))
+	}
+	if !svc.Now("academy-1").Equal(result.Now) || !svc.Now("academy-2").Equal(start) {
+		t.Error("o relógio deveria avançar só para a academia informada")
+	}
+}
+
+func TestTestClockService_TrialExpiresWithoutPaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newTestClockFixture(t)
+	f.sub.PixAuthorizationID, f.sub.PixRecurrenceID = nil, nil
+
+	result, err := svc.Advance(ctx, "academy-1", 15*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if result.Subscription.Status != domain.SubscriptionStatusExpired || len(f.payments.payments) != 0 {
+		t.Errorf("Status = %s, pagamentos = %d, want expired sem cobrança", result.Subscription.Status, len(f.payments.payments))
+	}
+
+	if _, err := svc.Advance(ctx, "academy-1", -time.Hour); err == nil {
+		t.Error("Advance() negativo deveria falhar")
+	}
+}
+
+func TestTestClockService_ConflictDoesNotDuplicatePayments(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newTestClockFixture(t)
+	svc.subscriptions = &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: 1}
+
+	// A conversão colide na primeira gravação: a conciliação recarrega e
+	// reaplica, e a cobrança simulada é registrada uma vez só
+	result, err := svc.Advance(ctx, "academy-1", 15*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if result.Subscription.Status != domain.SubscriptionStatusActive {
+		t.Fatalf("Status = %s, want active", result.Subscription.Status)
+	}
+	if len(f.payments.payments) != 1 {
+		t.Errorf("pagamentos = %d, want 1", len(f.payments.payments))
+	}
+}
+
+func TestTestClockService_FailedAdvanceKeepsClock(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newTestClockFixture(t)
+	subs := &detachedSubscriptions{fakeSubscriptions: f.subs, conflicts: maxConflictRetries + 1}
+	svc.subscriptions = subs
+
+	if _, err := svc.Advance(ctx, "academy-1", 15*24*time.Hour); !errors.Is(err, domain.ErrConcurrentModification) {
+		t.Fatalf("Advance() error = %v, want ErrConcurrentModification", err)
+	}
+	if now := svc.Now("academy-1"); !now.Equal(f.start) {
+		t.Errorf("Now() = %v após falha, want %v", now, f.start)
+	}
+	if stored, _ := subs.GetByID(ctx, "sub-1"); stored.Status != domain.SubscriptionStatusTrialing {
+		t.Errorf("Status gravado = %s, want trialing", stored.Status)
+	}
+}
+
+func TestTestClockService_ScheduledCancellation(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newTestClockFixture(t)
+	other := domain.NewTrialSubscription("academy-2", domain.TrialTerms{PlanID: "plan-starter", Days: 14}, f.start)
+	other.ID = "sub-2"
+	f.subs.subs[other.ID] = other
+
+	// Converte em 24/01 e agenda o cancelamento para o fim do período (24/02)
+	if _, err := svc.Advance(ctx, "academy-1", 15*24*time.Hour); err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if err := f.sub.Cancel("preço", true, "owner-1", svc.Now("academy-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := svc.Advance(ctx, "academy-1", 40*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if result.Subscription.Status != domain.SubscriptionStatusCanceled || result.Renewals != 0 {
+		t.Errorf("Status = %s, Renewals = %d, want canceled sem renovação", result.Subscription.Status, result.Renewals)
+	}
+	if want := time.Date(2026, 2, 24, 9, 0, 0, 0, domain.BillingLocation); result.Subscription.CanceledAt == nil || !result.Subscription.CanceledAt.Equal(want) {
+		t.Errorf("CanceledAt = %v, want fim do período %v", result.Subscription.CanceledAt, want)
+	}
+	if len(f.pix.canceled) != 1 || len(f.payments.payments) != 1 {
+		t.Errorf("recorrências encerradas = %v, pagamentos = %d, want [rec-1] e 1", f.pix.canceled, len(f.payments.payments))
+	}
+	// Os jobs rodaram só sobre a academia do test clock
+	if other.Status != domain.SubscriptionStatusTrialing {
+		t.Errorf("assinatura de outra academia = %s, want trialing", other.Status)
+	}
+}
+
+func TestTestClockService_DunningRunsOnSimulatedClock(t *testing.T) {
+	ctx := context.Background()
+	svc, f := newTestClockFixture(t)
+	if _, err := svc.Advance(ctx, "academy-1", 15*24*time.Hour); err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	// A renovação de 24/02 falha no gateway: sem recorrência, a régua assume
+	failRenewal(t, f.dunning(), f.sub, f.payments, *f.sub.CurrentPeriodEnd)
+	f.sub.PixRecurrenceID = nil
+
+	// 24/02 + 8 dias: fim do grace period da régua padrão
+	result, err := svc.Advance(ctx, "academy-1", 39*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if result.Subscription.Status != domain.SubscriptionStatusCanceled {
+		t.Errorf("Status = %s, want canceled pela régua", result.Subscription.Status)
+	}
+	sent := f.notifier.Sent()
+	if last := sent[len(sent)-1]; last.Type != ports.NotificationDunningFinal {
+		t.Errorf("última notificação = %s, want %s", last.Type, ports.NotificationDunningFinal)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// TrialService cria trials com a duração do plano ou da campanha e concede
+// extensões. A conversão na primeira cobrança paga é feita pelo BillingService.
+type TrialService struct {
+	subscriptions ports.SubscriptionService
+	plans         ports.PlanService
+	campaigns     ports.TrialCampaignService
+
+	clocked
+	audited
+}
+
+// NewTrialService cria o serviço de trials (campaigns pode ser nil)
+func NewTrialService(subscriptions ports.SubscriptionService, plans ports.PlanService, campaigns ports.TrialCampaignService) *TrialService {
+	return &TrialService{
+		subscriptions: subscriptions,
+		plans:         plans,
+		campaigns:     campaigns,
+	}
+}
+
+// StartTrial cria a assinatura em trial da academia.
+// campaignCode é opcional; um código inválido é rejeitado (não cai para o padrão).
+func (s *TrialService) StartTrial(ctx context.Context, academyID, planSlug, campaignCode string) (*domain.Subscription, error) {
+	plan, err := s.plans.GetBySlug(ctx, planSlug)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar plano %s: %w", planSlug, err)
+	}
+
+	var campaign *domain.TrialCampaign
+	if campaignCode != "" {
+		if s.campaigns == nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrTrialCampaignInvalid, campaignCode)
+		}
+		campaign, err = s.campaigns.GetByCode(ctx, domain.NormalizeCouponCode(campaignCode))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar campanha: %w", err)
+		}
+	}
+
+	terms, err := domain.ResolveTrialTerms(plan, campaign, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	sub := domain.NewTrialSubscription(academyID, terms, s.now())
+	if err := s.subscriptions.Create(ctx, sub); err != nil {
+		return nil, fmt.Errorf("erro ao criar assinatura: %w", err)
+	}
+	return sub, nil
+}
+
+// ExtendTrial estende o trial de uma assinatura (ação de admin, registrada na assinatura)
+func (s *TrialService) ExtendTrial(ctx context.Context, subscriptionID string, days int, reason, actor string) (*domain.Subscription, error) {
+	sub, err := s.subscriptions.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar assinatura: %w", err)
+	}
+	before := snapshot(sub)
+	if err := sub.ExtendTrial(days, reason, actor, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.subscriptions.Save(ctx, sub); err != nil {
+		return nil, fmt.Errorf("erro ao salvar assinatura: %w", err)
+	}
+	s.audit(ctx, domain.AuditTrialExtended, before, sub, s.now())
+	return sub, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+)
+
+func TestTrialService_StartTrial(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 11, 27, 10, 0, 0, 0, domain.BillingLocation)
+	f := newTrialFixture(t, "plan-pro", now)
+	campaigns := memory.NewTrialCampaignRepository(&domain.TrialCampaign{Code: "BLACKFRIDAY", TrialDays: 45, IsActive: true})
+
+	subs := f.subs
+	svc := NewTrialService(subs, f.plans, campaigns)
+	svc.SetClock(f.clock)
+
+	sub, err := svc.StartTrial(ctx, "academy-1", "pro", " blackfriday ")
+	if err != nil {
+		t.Fatalf("StartTrial() error = %v", err)
+	}
+	if !sub.TrialEndDate.Equal(now.AddDate(0, 0, 45)) || sub.TrialCampaignCode == nil {
+		t.Errorf("TrialEndDate = %v, campanha = %v, want 45 dias da BLACKFRIDAY", sub.TrialEndDate, sub.TrialCampaignCode)
+	}
+	if _, err := subs.GetByID(ctx, sub.ID); err != nil {
+		t.Errorf("assinatura não foi persistida: %v", err)
+	}
+
+	if _, err := svc.StartTrial(ctx, "academy-2", "pro", "NAOEXISTE"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("StartTrial() com campanha inexistente error = %v, want ErrNotFound", err)
+	}
+
+	other, err := svc.StartTrial(ctx, "academy-3", "pro", "")
+	if err != nil {
+		t.Fatalf("StartTrial() error = %v", err)
+	}
+	if !other.TrialEndDate.Equal(now.AddDate(0, 0, domain.DefaultTrialDays)) {
+		t.Errorf("TrialEndDate = %v, want %d dias", other.TrialEndDate, domain.DefaultTrialDays)
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+// Valores padrão do inbox de webhooks
+const (
+	defaultWebhookWorkers       = 4
+	defaultWebhookQueueSize     = 256
+	defaultWebhookSweepInterval = time.Minute
+	defaultWebhookRetryInterval = 30 * time.Second
+	defaultWebhookRetryBatch    = 50
+)
+
+// WebhookEventHandler processa um tipo de webhook já armazenado. Reentregas e
+// retentativas chegam com o mesmo evento: o handler deve ser idempotente.
+type WebhookEventHandler func(ctx context.Context, event *domain.WebhookEvent) error
+
+// WebhookRouter encaminha cada webhook armazenado ao handler do tipo dele.
+// Router.Process é o processador passado ao repositório de webhooks.
+type WebhookRouter struct {
+	mu       sync.RWMutex
+	handlers map[string]WebhookEventHandler
+}
+
+// NewWebhookRouter cria o router sem handlers
+func NewWebhookRouter() *WebhookRouter {
+	return &WebhookRouter{handlers: make(map[string]WebhookEventHandler)}
+}
+
+// Handle registra o handler de um tipo de evento
+func (r *WebhookRouter) Handle(eventType string, handler WebhookEventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = handler
+}
+
+// Process aplica o evento; tipos sem handler são ignorados (ports.ErrWebhookSkipped)
+func (r *WebhookRouter) Process(ctx context.Context, event *domain.WebhookEvent) error {
+	r.mu.RLock()
+	handler, ok := r.handlers[event.EventType]
+	r.mu.RUnlock()
+
+	if !ok {
+		log.Printf("[Webhook] Tipo de evento não tratado: %s/%s", event.Gateway, event.EventType)
+		return ports.ErrWebhookSkipped
+	}
+	ctx = domain.WithAuditActor(ctx, domain.AuditActor{Type: domain.AuditActorWebhook, ID: event.Gateway + "/" + event.EventID})
+	ctx = domain.WithRequestID(ctx, event.EventID)
+	return handler(ctx, event)
+}
+
+// WebhookInboxOptions configura o WebhookInbox
+type WebhookInboxOptions struct {
+	Workers       int           // Processamentos simultâneos
+	QueueSize     int           // Eventos aguardando worker; com a fila cheia ficam para a varredura
+	SweepInterval time.Duration // Intervalo da varredura de pendentes (entregas de antes de um restart)
+	RetryInterval time.Duration // Intervalo do worker de retentativas
+	RetryBatch    int           // Eventos reservados por rodada de retentativas
+}
+
+// webhookRef identifica um evento na fila
+type webhookRef struct {
+	gateway, eventID string
+}
+
+// WebhookInbox implementa ports.WebhookInbox: grava cada entrega antes de
+// confirmar ao gateway e processa em background, pelos workers de Run. Um
+// evento que não chegar a um worker (fila cheia, restart) continua pending
+// e é recolhido pela varredura. Falhas são retentadas com backoff até irem
+// para a dead-letter, de onde só saem por replay manual.
+type WebhookInbox struct {
+	webhooks ports.WebhookService
+	queue    chan webhookRef
+	opts     WebhookInboxOptions
+
+	clocked
+}
+
+// NewWebhookInbox cria o inbox sobre o repositório de webhooks
+func NewWebhookInbox(webhooks ports.WebhookService, opts WebhookInboxOptions) *WebhookInbox {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWebhookWorkers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultWebhookQueueSize
+	}
+	if opts.SweepInterval <= 0 {
+		opts.SweepInterval = defaultWebhookSweepInterval
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultWebhookRetryInterval
+	}
+	if opts.RetryBatch <= 0 {
+		opts.RetryBatch = defaultWebhookRetryBatch
+	}
+	return &WebhookInbox{
+		webhooks: webhooks,
+		queue:    make(chan webhookRef, opts.QueueSize),
+		opts:     opts,
+	}
+}
+
+// Receive armazena o evento e agenda o processamento. Reentregas de um evento
+// já processado (ou ignorado) não são processadas de novo.
+func (s *WebhookInbox) Receive(ctx context.Context, incoming *ports.IncomingWebhookEvent) (*domain.WebhookEvent, error) {
+	if incoming.EventID == "" {
+		return nil, fmt.Errorf("webhook %s/%s sem event_id", incoming.Gateway, incoming.EventType)
+	}
+
+	event := domain.NewWebhookEvent(incoming.Gateway, incoming.EventID, incoming.EventType,
+		incoming.Payload, incoming.Headers, s.now())
+	if err := s.webhooks.Store(ctx, event); err != nil {
+		return nil, fmt.Errorf("erro ao armazenar webhook: %w", err)
+	}
+
+	if event.Status != domain.WebhookStatusPending {
+		log.Printf("[Webhook] Reentrega de %s/%s ignorada (%s)", event.Gateway, event.EventID, event.Status)
+		return event, nil
+	}
+	s.enqueue(event)
+	return event, nil
+}
+
+// Run processa os eventos recebidos até ctx ser cancelado, varrendo os
+// pendentes a cada SweepInterval e retentando as falhas a cada RetryInterval
+func (s *WebhookInbox) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+
+	sweepTicker := time.NewTicker(s.opts.SweepInterval)
+	defer sweepTicker.Stop()
+	retryTicker := time.NewTicker(s.opts.RetryInterval)
+	defer retryTicker.Stop()
+
+	s.sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-sweepTicker.C:
+			s.sweep(ctx)
+		case <-retryTicker.C:
+			s.retry(ctx)
+		}
+	}
+}
+
+// DeadLetters lista os eventos que esgotaram as retentativas
+func (s *WebhookInbox) DeadLetters(ctx context.Context, limit, offset int) ([]*domain.WebhookEvent, error) {
+	return s.webhooks.ListByStatus(ctx, domain.WebhookStatusDeadLetter, limit, offset)
+}
+
+// Get busca um evento pelo ID interno
+func (s *WebhookInbox) Get(ctx context.Context, id string) (*domain.WebhookEvent, error) {
+	return s.webhooks.GetByID(ctx, id)
+}
+
+// EditPayload corrige o payload de um evento na dead-letter (ex: campo que o
+// gateway mandou errado) antes do replay
+func (s *WebhookInbox) EditPayload(ctx context.Context, id string, payload json.RawMessage) (*domain.WebhookEvent, error) {
+	event, err := s.webhooks.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.EditPayload(payload); err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.Save(ctx, event); err != nil {
+		return nil, err
+	}
+	log.Printf("[Webhook] Payload de %s/%s editado", event.Gateway, event.EventID)
+	return event, nil
+}
+
+// Replay devolve um evento da dead-letter (ou falho) à fila com as retentativas
+// zeradas. Sem workers rodando neste processo, a varredura de quem estiver
+// rodando Run o recolhe.
+func (s *WebhookInbox) Replay(ctx context.Context, id string) (*domain.WebhookEvent, error) {
+	event, err := s.webhooks.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.Replay(); err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.Save(ctx, event); err != nil {
+		return nil, err
+	}
+	log.Printf("[Webhook] Replay de %s/%s", event.Gateway, event.EventID)
+	s.enqueue(event)
+	return event, nil
+}
+
+// ProcessPending processa de forma síncrona até limit eventos pendentes recebidos
+// há mais de olderThan; devolve quantos foram processados sem erro
+func (s *WebhookInbox) ProcessPending(ctx context.Context, limit int, olderThan time.Duration) (int, error) {
+	pending, err := s.webhooks.ListPending(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := s.now().Add(-olderThan)
+	processed := 0
+	for _, event := range pending {
+		if event.ReceivedAt.After(cutoff) {
+			continue
+		}
+		if s.process(ctx, webhookRef{event.Gateway, event.EventID}) {
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+// enqueue coloca o evento na fila sem bloquear o request
+func (s *WebhookInbox) enqueue(event *domain.WebhookEvent) {
+	select {
+	case s.queue <- webhookRef{event.Gateway, event.EventID}:
+	default:
+		log.Printf("[Webhook] Fila cheia, %s/%s fica para a varredura", event.Gateway, event.EventID)
+	}
+}
+
+// work consome a fila até ctx ser cancelado
+func (s *WebhookInbox) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ref := <-s.queue:
+			s.process(ctx, ref)
+		}
+	}
+}
+
+// sweep processa os pendentes que não chegaram aos workers
+func (s *WebhookInbox) sweep(ctx context.Context) {
+	if n, err := s.ProcessPending(ctx, 100, s.opts.SweepInterval); err != nil {
+		log.Printf("[Webhook] Erro na varredura de pendentes: %v", err)
+	} else if n > 0 {
+		log.Printf("[Webhook] %d evento(s) pendente(s) processado(s)", n)
+	}
+}
+
+// retry reprocessa as falhas com retry vencido
+func (s *WebhookInbox) retry(ctx context.Context) {
+	if n, err := s.webhooks.RetryFailed(ctx, s.opts.RetryBatch); err != nil {
+		log.Printf("[Webhook] Erro ao retentar falhas: %v", err)
+	} else if n > 0 {
+		log.Printf("[Webhook] %d evento(s) reprocessado(s) com sucesso", n)
+	}
+}
+
+// process processa um evento; a falha fica registrada no evento para retry
+func (s *WebhookInbox) process(ctx context.Context, ref webhookRef) bool {
+	if err := s.webhooks.Process(ctx, ref.gateway, ref.eventID); err != nil {
+		log.Printf("[Webhook] Erro ao processar %s/%s: %v", ref.gateway, ref.eventID, err)
+		return false
+	}
+	return true
+}
+
+// Garante que WebhookInbox implementa ports.WebhookInbox
+var _ ports.WebhookInbox = (*WebhookInbox)(nil)
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/magnani/black-belt-app/backend/internal/adapters/memory"
+	"github.com/magnani/black-belt-app/backend/internal/domain"
+	"github.com/magnani/black-belt-app/backend/internal/ports"
+)
+
+func TestWebhookInbox_ReceiveAndRun(t *testing.T) {
+	var processed atomic.Int32
+	router := NewWebhookRouter()
+	router.Handle("pix", func(ctx context.Context, event *domain.WebhookEvent) error {
+		processed.Add(1)
+		return nil
+	})
+	webhooks := memory.NewWebhookRepository(memory.WebhookProcessor(router.Process))
+	inbox := NewWebhookInbox(webhooks, WebhookInboxOptions{Workers: 2})
+
+	ctx := context.Background()
+	incoming := &ports.IncomingWebhookEvent{Gateway: "pix_auto", EventID: "E1", EventType: "pix", Payload: json.RawMessage(`{}`)}
+	first, err := inbox.Receive(ctx, incoming)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	// Reentrega antes do processamento: mesmo registro
+	again, err := inbox.Receive(ctx, incoming)
+	if err != nil || again.ID != first.ID {
+		t.Fatalf("reentrega = %v, ID %s; want ID %s", err, again.ID, first.ID)
+	}
+	if processed.Load() != 0 {
+		t.Fatal("Receive não deveria processar durante o request")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		inbox.Run(runCtx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, _ := webhooks.GetByEventID(ctx, "pix_auto", "E1")
+		if got.Status == domain.WebhookStatusProcessed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("evento não processado: %s", got.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if n := processed.Load(); n != 1 {
+		t.Errorf("handler chamado %d vezes, want 1", n)
+	}
+
+	// Reentrega de evento processado não agenda de novo
+	if again, err := inbox.Receive(ctx, incoming); err != nil || again.Status != domain.WebhookStatusProcessed {
+		t.Errorf("reentrega após processar = %v, %v", again, err)
+	}
+}
+
+func TestWebhookInbox_ProcessPending(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(now)
+
+	router := NewWebhookRouter()
+	router.Handle("pix", func(ctx context.Context, event *domain.WebhookEvent) error {
+		if event.EventID == "falha" {
+			return errors.New("pagamento não encontrado")
+		}
+		return nil
+	})
+	webhooks := memory.NewWebhookRepository(memory.WebhookProcessor(router.Process))
+	webhooks.SetClock(clock)
+	inbox := NewWebhookInbox(webhooks, WebhookInboxOptions{QueueSize: 1})
+	inbox.SetClock(clock)
+
+	ctx := context.Background()
+	for _, e := range []struct{ id, typ string }{{"ok", "pix"}, {"falha", "pix"}, {"outro", "rec"}} {
+		if _, err := inbox.Receive(ctx, &ports.IncomingWebhookEvent{Gateway: "pix_auto", EventID: e.id, EventType: e.typ}); err != nil {
+			t.Fatalf("Receive %s: %v", e.id, err)
+		}
+	}
+
+	// Recém-recebidos ainda podem estar na fila dos workers
+	if n, err := inbox.ProcessPending(ctx, 10, time.Minute); err != nil || n != 0 {
+		t.Fatalf("ProcessPending recente = %d, %v; want 0", n, err)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if n, err := inbox.ProcessPending(ctx, 10, time.Minute); err != nil || n != 2 {
+		t.Fatalf("ProcessPending = %d, %v; want 2", n, err)
+	}
+
+	tests := []struct {
+		eventID string
+		want    domain.WebhookStatus
+	}{
+		{"ok", domain.WebhookStatusProcessed},
+		{"falha", domain.WebhookStatusFailed},
+		{"outro", domain.WebhookStatusSkipped},
+	}
+	for _, tt := range tests {
+		got, _ := webhooks.GetByEventID(ctx, "pix_auto", tt.eventID)
+		if got.Status != tt.want {
+			t.Errorf("%s: status = %s, want %s", tt.eventID, got.Status, tt.want)
+		}
+	}
+}
+
+func TestWebhookInbox_DeadLetterReplay(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(now)
+
+	// Falha enquanto o payload não trouxer o campo corrigido
+	router := NewWebhookRouter()
+	router.Handle("pix", func(ctx context.Context, event *domain.WebhookEvent) error {
+		if string(event.Payload) != `{"corrigido":true}` {
+			return errors.New("payload inválido")
+		}
+		return nil
+	})
+	webhooks := memory.NewWebhookRepository(memory.WebhookProcessor(router.Process))
+	webhooks.SetClock(clock)
+	webhooks.SetBackoff(domain.WebhookBackoff{Base: time.Minute, Max: time.Hour, MaxRetries: 1})
+	inbox := NewWebhookInbox(webhooks, WebhookInboxOptions{})
+	inbox.SetClock(clock)
+
+	ctx := context.Background()
+	event, err := inbox.Receive(ctx, &ports.IncomingWebhookEvent{Gateway: "pix_auto", EventID: "E1", EventType: "pix", Payload: json.RawMessage(`{}`)})
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+	if _, err := inbox.ProcessPending(ctx, 10, time.Minute); err != nil {
+		t.Fatalf("ProcessPending: %v", err)
+	}
+
+	// Retry antes do backoff não pega o evento; depois, esgota e vai para a dead-letter
+	if n, err := webhooks.RetryFailed(ctx, 10); err != nil || n != 0 {
+		t.Fatalf("RetryFailed antes do backoff = %d, %v", n, err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := webhooks.RetryFailed(ctx, 10); err != nil {
+		t.Fatalf("RetryFailed: %v", err)
+	}
+	dead, err := inbox.DeadLetters(ctx, 10, 0)
+	if err != nil || len(dead) != 1 || dead[0].ID != event.ID {
+		t.Fatalf("DeadLetters = %v, %v; want [%s]", dead, err, event.ID)
+	}
+
+	// Corrige o payload e devolve o evento à fila com as retentativas zeradas
+	if _, err := inbox.EditPayload(ctx, event.ID, json.RawMessage(`{"corrigido":true}`)); err != nil {
+		t.Fatalf("EditPayload: %v", err)
+	}
+	replayed, err := inbox.Replay(ctx, event.ID)
+	if err != nil || replayed.Status != domain.WebhookStatusPending || replayed.RetryCount != 0 {
+		t.Fatalf("Replay = %+v, %v", replayed, err)
+	}
+	if _, err := inbox.Replay(ctx, event.ID); !errors.Is(err, domain.ErrInvalidTransition) {
+		t.Errorf("Replay de pendente error = %v, want ErrInvalidTransition", err)
+	}
+
+	// O replay entra na fila dos workers; aqui a varredura o processa
+	if n, err := inbox.ProcessPending(ctx, 10, 0); err != nil || n != 1 {
+		t.Fatalf("ProcessPending após replay = %d, %v; want 1", n, err)
+	}
+	got, _ := inbox.Get(ctx, event.ID)
+	if got.Status != domain.WebhookStatusProcessed {
+		t.Errorf("status após replay = %s, want processed", got.Status)
+	}
+	if dead, _ := inbox.DeadLetters(ctx, 10, 0); len(dead) != 0 {
+		t.Errorf("dead-letter após replay = %d eventos, want 0", len(dead))
+	}
+}
+
+func TestWebhookInbox_AbandonedProcessingIsReclaimed(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(now)
+
+	// A primeira chamada trava como um worker que caiu no meio; as seguintes processam
+	var calls atomic.Int32
+	stuck, release := make(chan struct{}), make(chan struct{})
+	router := NewWebhookRouter()
+	router.Handle("pix", func(ctx context.Context, event *domain.WebhookEvent) error {
+		if calls.Add(1) == 1 {
+			close(stuck)
+			<-release
+		}
+		return nil
+	})
+	webhooks := memory.NewWebhookRepository(memory.WebhookProcessor(router.Process))
+	webhooks.SetClock(clock)
+	webhooks.SetBackoff(domain.WebhookBackoff{Base: time.Minute, Max: time.Hour, MaxRetries: 5, Lease: 5 * time.Minute})
+	inbox := NewWebhookInbox(webhooks, WebhookInboxOptions{})
+	inbox.SetClock(clock)
+
+	ctx := context.Background()
+	if _, err := inbox.Receive(ctx, &ports.IncomingWebhookEvent{Gateway: "pix_auto", EventID: "E1", EventType: "pix"}); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	abandoned := make(chan struct{})
+	go func() {
+		defer close(abandoned)
+		_ = webhooks.Process(ctx, "pix_auto", "E1")
+	}()
+	<-stuck
+	defer func() {
+		close(release)
+		<-abandoned
+	}()
+
+	// Dentro do lease ninguém mexe no evento; a varredura de pendentes não o vê
+	clock.Advance(4 * time.Minute)
+	if n, err := webhooks.RetryFailed(ctx, 10); err != nil || n != 0 {
+		t.Fatalf("RetryFailed dentro do lease = %d, %v; want 0", n, err)
+	}
+	if got, _ := webhooks.GetByEventID(ctx, "pix_auto", "E1"); got.Status != domain.WebhookStatusProcessing {
+		t.Fatalf("status dentro do lease = %s, want processing", got.Status)
+	}
+
+	// Lease vencido: volta às retentativas como falha e é reprocessado após o backoff
+	clock.Advance(time.Minute)
+	if _, err := webhooks.RetryFailed(ctx, 10); err != nil {
+		t.Fatalf("RetryFailed: %v", err)
+	}
+	got, _ := webhooks.GetByEventID(ctx, "pix_auto", "E1")
+	if got.Status != domain.WebhookStatusFailed || got.RetryCount != 1 {
+		t.Fatalf("após o lease: status = %s, retry_count = %d; want failed, 1", got.Status, got.RetryCount)
+	}
+	clock.Advance(time.Minute)
+	if n, err := webhooks.RetryFailed(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("RetryFailed após o backoff = %d, %v; want 1", n, err)
+	}
+	if got, _ := webhooks.GetByEventID(ctx, "pix_auto", "E1"); got.Status != domain.WebhookStatusProcessed {
+		t.Errorf("status final = %s, want processed", got.Status)
+	}
+}